@@ -17,47 +17,19 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
 
-	"github.com/pkg/errors"
-	"golang.org/x/tools/go/packages"
 	"gopkg.in/alecthomas/kingpin.v2"
 
-	"github.com/crossplane/crossplane-tools/internal/comments"
-	"github.com/crossplane/crossplane-tools/internal/generate"
-	"github.com/crossplane/crossplane-tools/internal/match"
-	"github.com/crossplane/crossplane-tools/internal/method"
-	"github.com/crossplane/crossplane-tools/internal/types"
-)
-
-const (
-	// LoadMode used to load all packages.
-	LoadMode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedSyntax
-
-	// DisableMarker used to disable generation of managed resource methods for
-	// a type that otherwise appears to be a managed resource that is missing a
-	// subnet of its methods.
-	DisableMarker = "crossplane:generate:methods"
-)
-
-// Imports used in generated code.
-const (
-	CoreAlias  = "corev1"
-	CoreImport = "k8s.io/api/core/v1"
-
-	ClientAlias  = "client"
-	ClientImport = "sigs.k8s.io/controller-runtime/pkg/client"
-
-	RuntimeAlias  = "xpv1"
-	RuntimeImport = "github.com/crossplane/crossplane-runtime/apis/common/v1"
-
-	ResourceAlias  = "resource"
-	ResourceImport = "github.com/crossplane/crossplane-runtime/pkg/resource"
-
-	ReferenceAlias  = "reference"
-	ReferenceImport = "github.com/crossplane/crossplane-runtime/pkg/reference"
+	"github.com/crossplane/crossplane-tools/pkg/angryjet"
 )
 
 func main() {
@@ -66,18 +38,94 @@ func main() {
 
 		methodsets          = app.Command("generate-methodsets", "Generate a Crossplane method sets.")
 		headerFile          = methodsets.Flag("header-file", "The contents of this file will be added to the top of all generated files.").ExistingFile()
-		filenameManaged     = methodsets.Flag("filename-managed", "The filename of generated managed resource files.").Default("zz_generated.managed.go").String()
-		filenameResolvers   = methodsets.Flag("filename-resolvers", "The filename of generated reference resolver files.").Default("zz_generated.resolvers.go").String()
-		filenameManagedList = methodsets.Flag("filename-managed-list", "The filename of generated managed list resource files.").Default("zz_generated.managedlist.go").String()
-		filenamePC          = methodsets.Flag("filename-pc", "The filename of generated provider config files.").Default("zz_generated.pc.go").String()
-		filenamePCU         = methodsets.Flag("filename-pcu", "The filename of generated provider config usage files.").Default("zz_generated.pcu.go").String()
-		filenamePCUList     = methodsets.Flag("filename-pcu-list", "The filename of generated provider config usage files.").Default("zz_generated.pculist.go").String()
-		pattern             = methodsets.Arg("packages", "Package(s) for which to generate methods, for example github.com/crossplane/crossplane/apis/...").String()
+		outputDir           = methodsets.Flag("output-dir", "Write generated files to a directory tree rooted here that mirrors the packages of their module, rather than alongside them.").String()
+		filenameManaged     = methodsets.Flag("filename-managed", "The filename of generated managed resource files.").Default(angryjet.DefaultFilenameManaged).String()
+		filenameResolvers   = methodsets.Flag("filename-resolvers", "The filename of generated reference resolver files.").Default(angryjet.DefaultFilenameResolvers).String()
+		filenameManagedList = methodsets.Flag("filename-managed-list", "The filename of generated managed list resource files.").Default(angryjet.DefaultFilenameManagedList).String()
+		filenamePC          = methodsets.Flag("filename-pc", "The filename of generated provider config files.").Default(angryjet.DefaultFilenamePC).String()
+		filenamePCU         = methodsets.Flag("filename-pcu", "The filename of generated provider config usage files.").Default(angryjet.DefaultFilenamePCU).String()
+		filenamePCUList     = methodsets.Flag("filename-pcu-list", "The filename of generated provider config usage files.").Default(angryjet.DefaultFilenamePCUList).String()
+		filenameResolvable  = methodsets.Flag("filename-resolvable-fields", "The filename of generated resolvable field table files.").Default(angryjet.DefaultFilenameResolvableFields).String()
+		filenameIndex       = methodsets.Flag("filename-resolvers-index", "The filename of generated reference resolver index files.").Default(angryjet.DefaultFilenameResolversIndex).String()
+		filenameRefError    = methodsets.Flag("filename-reference-error", "The filename of generated ReferenceError type files.").Default(angryjet.DefaultFilenameReferenceError).String()
+		filenameFixtures    = methodsets.Flag("filename-fixtures", "The filename of generated fixture files, which should end with _test.go.").Default(angryjet.DefaultFilenameFixtures).String()
+		deprecationRecorder = methodsets.Flag("deprecation-recorder", "A function called by generated reference resolvers when a deprecated reference is used, for example example.org/pkg/deprecation.Record.").String()
+		pcValidator         = methodsets.Flag("provider-config-validator", "A function called by generated reference resolvers to check that a referenced resource uses the same provider config, for example example.org/pkg/providerconfig.Validate.").String()
+		tenant              = methodsets.Flag("tenant", "A function called by generated reference resolvers to get the namespace of the tenant from their context, for example example.org/pkg/tenancy.Namespace.").String()
+		resolver            = methodsets.Flag("resolver", "A function called by generated reference resolvers to construct the resolver they resolve references with, rather than the API resolver, for example example.org/pkg/webhook.NewResolver.").String()
+		hubOnly             = methodsets.Flag("hub-only", "Only generate reference resolvers, and reference resolver indexes, for managed resources marked as the storage version of their kind with +kubebuilder:storageversion, which other versions are converted to.").Bool()
+		disableSelectors    = methodsets.Flag("disable-selectors", "Generate reference resolvers that only resolve references by name, and return an error if a selector is set.").Bool()
+		checksums           = methodsets.Flag("checksums", "Record a checksum of each generated file in a comment after its generated code header, and refuse to overwrite generated files that no longer match their checksum because they were edited. Without it, edited generated files are overwritten.").Bool()
+		force               = methodsets.Flag("force", "Overwrite generated files even if --checksums finds they were edited.").Bool()
+		check               = methodsets.Flag("check", "Write no files, and instead fail if any generated file is out of date, or, with --checksums, was edited after it was generated.").Bool()
+		banners             = methodsets.Flag("banners", "Group the generated declarations of each type in a file, preceded by a banner comment such as // ===== VPC =====.").Bool()
+		updateResolvers     = methodsets.Flag("update-resolvers", "Only generate reference resolvers, and replace only the ResolveReferences methods of existing reference resolver files, keeping their other declarations.").Bool()
+		clearSelectors      = methodsets.Flag("clear-selectors", "Generate reference resolvers that clear the selector of a reference that was resolved by name.").Bool()
+		skipUnchanged       = methodsets.Flag("skip-unchanged", "An annotation in which generated reference resolvers store a hash of the references and selectors of a managed resource, and skip resolution while it is unchanged, for example example.org/resolved-inputs.").String()
+		dependencyAnno      = methodsets.Flag("dependency-annotation", "An annotation in which generated reference resolvers record the kind and name of each resource they resolved a reference to, for example example.org/dependencies.").String()
+		runtimeLevel        = methodsets.Flag("runtime-level", "The crossplane-runtime API level that generated code targets: latest, auto to detect it from the loaded crossplane-runtime packages, or a version such as v0.19.").Default(angryjet.RuntimeLevelLatest).String()
+		runtimeModule       = methodsets.Flag("runtime-module", "The path of the crossplane-runtime module whose packages generated code imports, for example example.org/provider/internal/thirdparty/crossplane-runtime for a provider that forks it. It is discovered for each package from the crossplane-runtime types that its spec and status types embed if it is not set.").String()
+		wrapWithMessage     = methodsets.Flag("wrap-with-message", "Generate reference resolvers that add the path of a field to errors using errors.WithMessage rather than errors.Wrap, so that errors that already have a stack trace don't get another.").Bool()
+		errorWrapper        = methodsets.Flag("error-wrapper", "A function that generated reference resolvers wrap errors returned while resolving a field with its path by, rather than errors.Wrap, for example example.org/pkg/errors.Reference. It must have the signature func(err error, field string) error.").String()
+		referenceError      = methodsets.Flag("reference-error", "Generate reference resolvers that return errors as a ReferenceError of the path of the field and the kind it references, which is generated once for each package, so that controllers may tell them apart using errors.As. It takes precedence over --error-wrapper and --wrap-with-message.").Bool()
+		failureCondition    = methodsets.Flag("failure-condition", "The type of a condition, for example ReferencesResolved, that generated reference resolvers set on a managed resource with a status of False and a message of the error before they return an error.").String()
+		failureReason       = methodsets.Flag("failure-condition-reason", "The reason of the condition set by --failure-condition.").Default("ReferenceResolutionFailed").String()
+		clock               = methodsets.Flag("clock", "A package-level value whose Now method generated reference resolvers call to tell the time, rather than the time package, for example example.org/pkg/clock.Clock, so that tests may replace it. Its Now method must have the signature func() time.Time.").String()
+		transactional       = methodsets.Flag("transactional", "Generate reference resolvers that resolve references in a deep copy of a managed resource, and replace the managed resource with it only if every reference is resolved. Managed resources must have a DeepCopy method.").Bool()
+		retryPkg            = methodsets.Flag("retry-pkg", "A package whose OnError function generated reference resolvers wrap each resolution in, so that resolutions that fail transiently are retried, for example example.org/pkg/retry. It must have the signature func(ctx context.Context, b Backoff, fn func() error) error. Resolutions are retried with its DefaultBackoff unless --retry-duration, --retry-factor, or --retry-steps is set.").String()
+		retryDuration       = methodsets.Flag("retry-duration", "How long the first retry of a resolution waits, if --retry-pkg is set.").Duration()
+		retryFactor         = methodsets.Flag("retry-factor", "What each retry of a resolution after the first multiplies how long it waits by, if --retry-pkg is set.").Float64()
+		retrySteps          = methodsets.Flag("retry-steps", "How many times a resolution is tried at most, if --retry-pkg is set.").Int()
+		skipEmpty           = methodsets.Flag("skip-empty", "Generate reference resolvers that skip fields whose reference and selector are both unset, rather than resolving them to their current values.").Bool()
+		resolverLogging     = methodsets.Flag("resolver-logging-pkg", "A package whose FromContext function generated reference resolvers call to get a logger from their context, and log each field they resolve at debug level, for example example.org/pkg/logging.").String()
+		provenance          = methodsets.Flag("provenance-pkg", "A package whose Record and RecordMultiple functions generated reference resolvers call after resolving each field, to record where its value came from, for example example.org/pkg/provenance.").String()
+		assertions          = methodsets.Flag("resolver-assertions", "Precede each generated ResolveReferences method by an assertion that its receiver implements the crossplane-runtime Managed interface.").Bool()
+		controllerRuntime   = methodsets.Flag("controller-runtime", "Generate reference resolvers that get and list referenced resources using the controller-runtime client directly, rather than the crossplane-runtime reference package. Only references of single values and slices are supported.").Bool()
+		verbose             = methodsets.Flag("verbose", "Also warn about fields with references that are not resolved because they are shadowed by less deeply embedded fields.").Bool()
+		maxDepth            = methodsets.Flag("max-depth", "The maximum number of fields deep that the types of managed resources are traversed to find references. Deeper references are not resolved, with a warning. There is no limit if it is zero.").Int()
+		resolvedValues      = methodsets.Flag("resolved-values", "Also generate a ResolveReferencesWithValues method that returns a map of field path to resolved value.").Bool()
+		resolvableFields    = methodsets.Flag("resolvable-fields", "Also generate a table of the JSON paths of the fields of each managed resource that may be resolved from a reference or a selector.").Bool()
+		resolversIndex      = methodsets.Flag("resolvers-index", "Also generate a ResolveReferences function for each package that resolves the references of any of its managed resources that have them.").Bool()
+		fixtures            = methodsets.Flag("fixtures", "Also generate a New<Kind>Fixture function for each managed resource, for use in tests, that returns one whose required spec fields are set to valid values, and an option for each field of its spec.forProvider.").Bool()
+		fixturesExternal    = methodsets.Flag("fixtures-external", "Write fixtures to the external test package of each package, for example v1alpha1_test, rather than to the package itself.").Bool()
+		include             = methodsets.Flag("include", "Only generate methods for types whose names match this regular expression.").Regexp()
+		exclude             = methodsets.Flag("exclude", "Don't generate methods for types whose names match this regular expression.").Regexp()
+		allowlistFile       = methodsets.Flag("types-allowlist-file", "A file of the types to generate methods for, one per line, for example example.org/provider/apis/ec2/v1beta1.VPC, such as a list of the types generated by another tool. Either part of a line may be a glob, and # starts a comment. Entries that match no type are an error.").ExistingFile()
+		methodSetsOf        = methodsets.Flag("method-sets", "The comma separated method sets to generate for packages matching a pattern, for example example.org/provider/apis/legacy/...=managed,managedlist. May be repeated; the longest matching pattern wins.").StringMap()
+		only                = methodsets.Flag("only", "Only generate this method set. May be repeated.").Strings()
+		skip                = methodsets.Flag("skip", "Don't generate this method set. May be repeated.").Strings()
+		accessorVariants    = methodsets.Flag("accessor-variant", "Also generate the alternate variant of this accessor of managed resources, for example GetDeletionPolicy, which takes or returns a pointer if it takes or returns a value and vice versa. May be repeated.").Strings()
+		overlay             = methodsets.Flag("overlay", "A JSON file of an object that maps the paths of files to the contents to load them with, rather than those on disk, for example to generate methods from the unsaved buffers of an editor. Relative paths are relative to the current directory.").ExistingFile()
+		referencesFile      = methodsets.Flag("references-file", "A file of JSON descriptions of references of managed resources whose fields have no reference markers, as printed by the describe command. Reference resolvers are generated as if the fields had the described markers.").ExistingFile()
+		crdFiles            = methodsets.Flag("crd-file", "A file of CustomResourceDefinitions whose crossplane.io/references annotations describe references of managed resources, as a JSON array in the format printed by the describe command. Fields that have reference markers keep them. May be repeated.").ExistingFiles()
+		stdin               = methodsets.Flag("stdin", "Read JSON descriptions of references from standard input, as with --references-file.").Bool()
+		pattern             = methodsets.Arg("packages", "Package(s) for which to generate methods, for example github.com/crossplane/crossplane/apis/... The packages of the described references are used if it is empty.").String()
+
+		lint        = app.Command("lint", "Report references to kinds that don't exist, whose list type doesn't exist or has no Items of the kind, or that are in deprecated packages.")
+		lintJSON    = lint.Flag("json", "Print findings as a JSON array.").Bool()
+		lintStrict  = lint.Flag("strict-deprecations", "Also fail if references refer to kinds in packages deprecated by the +crossplane:deprecated marker, rather than only reporting them.").Bool()
+		lintOverlay = lint.Flag("overlay", "A JSON file of an object that maps the paths of files to the contents to load them with, rather than those on disk, for example to lint the unsaved buffers of an editor. Relative paths are relative to the current directory.").ExistingFile()
+		lintPattern = lint.Arg("packages", "Package(s) to lint, for example github.com/crossplane/crossplane/apis/...").String()
+
+		describe        = app.Command("describe", "Print JSON descriptions of the references of managed resources, from the reference markers of their fields.")
+		describeOverlay = describe.Flag("overlay", "A JSON file of an object that maps the paths of files to the contents to load them with, rather than those on disk, for example to describe the unsaved buffers of an editor. Relative paths are relative to the current directory.").ExistingFile()
+		describePattern = describe.Arg("packages", "Package(s) to describe, for example github.com/crossplane/crossplane/apis/...").String()
+
+		migrate        = app.Command("migrate-markers", "Rewrite legacy spellings of reference markers, and ad hoc comments such as 'Ref: ec2.VPC', to the canonical crossplane:generate:reference markers in place.")
+		migrateDryRun  = migrate.Flag("dry-run", "Print the rewritten lines as a diff instead of writing them.").Bool()
+		migratePattern = migrate.Arg("packages", "Package(s) whose markers to migrate, for example ./apis/...").String()
 	)
-	kingpin.MustParse(app.Parse(os.Args[1:]))
-
-	pkgs, err := packages.Load(&packages.Config{Mode: LoadMode}, *pattern)
-	kingpin.FatalIfError(err, "cannot load packages %s", *pattern)
+	switch kingpin.MustParse(app.Parse(os.Args[1:])) {
+	case lint.FullCommand():
+		runLint(*lintPattern, readOverlay(*lintOverlay), *lintJSON, *lintStrict)
+		return
+	case describe.FullCommand():
+		runDescribe(*describePattern, readOverlay(*describeOverlay))
+		return
+	case migrate.FullCommand():
+		runMigrate(*migratePattern, *migrateDryRun)
+		return
+	}
 
 	header := ""
 	if *headerFile != "" {
@@ -86,162 +134,296 @@ func main() {
 		header = string(h)
 	}
 
-	for _, p := range pkgs {
-		for _, err := range p.Errors {
-			kingpin.FatalIfError(err, "error loading packages using pattern %s", *pattern)
-		}
-		kingpin.FatalIfError(GenerateManaged(*filenameManaged, header, p), "cannot write managed resource method set for package %s", p.PkgPath)
-		kingpin.FatalIfError(GenerateManagedList(*filenameManagedList, header, p), "cannot write managed resource list method set for package %s", p.PkgPath)
-		kingpin.FatalIfError(GenerateProviderConfig(*filenamePC, header, p), "cannot write provider config method set for package %s", p.PkgPath)
-		kingpin.FatalIfError(GenerateProviderConfigUsage(*filenamePCU, header, p), "cannot write provider config usage method set for package %s", p.PkgPath)
-		kingpin.FatalIfError(GenerateProviderConfigUsageList(*filenamePCUList, header, p), "cannot write provider config usage list method set for package %s", p.PkgPath)
-		kingpin.FatalIfError(GenerateReferences(*filenameResolvers, header, p), "cannot write reference resolvers for package %s", p.PkgPath)
+	descriptions := readDescriptions(*referencesFile, *stdin)
+	patterns := []string{*pattern}
+	if *pattern == "" && len(descriptions) > 0 {
+		patterns = describedPackages(descriptions)
 	}
-}
 
-// GenerateManaged generates the resource.Managed method set.
-func GenerateManaged(filename, header string, p *packages.Package) error {
-	receiver := "mg"
-
-	methods := method.Set{
-		"SetConditions":                       method.NewSetConditions(receiver, RuntimeImport),
-		"GetCondition":                        method.NewGetCondition(receiver, RuntimeImport),
-		"GetProviderReference":                method.NewGetProviderReference(receiver, RuntimeImport),
-		"SetProviderReference":                method.NewSetProviderReference(receiver, RuntimeImport),
-		"GetProviderConfigReference":          method.NewGetProviderConfigReference(receiver, RuntimeImport),
-		"SetProviderConfigReference":          method.NewSetProviderConfigReference(receiver, RuntimeImport),
-		"SetWriteConnectionSecretToReference": method.NewSetWriteConnectionSecretToReference(receiver, RuntimeImport),
-		"GetWriteConnectionSecretToReference": method.NewGetWriteConnectionSecretToReference(receiver, RuntimeImport),
-		"SetPublishConnectionDetailsTo":       method.NewSetPublishConnectionDetailsTo(receiver, RuntimeImport),
-		"GetPublishConnectionDetailsTo":       method.NewGetPublishConnectionDetailsTo(receiver, RuntimeImport),
-		"SetDeletionPolicy":                   method.NewSetDeletionPolicy(receiver, RuntimeImport),
-		"GetDeletionPolicy":                   method.NewGetDeletionPolicy(receiver, RuntimeImport),
-	}
-
-	err := generate.WriteMethods(p, methods, filepath.Join(filepath.Dir(p.GoFiles[0]), filename),
-		generate.WithHeaders(header),
-		generate.WithImportAliases(map[string]string{
-			CoreImport:    CoreAlias,
-			RuntimeImport: RuntimeAlias,
-		}),
-		generate.WithMatcher(match.AllOf(
-			match.Managed(),
-			match.DoesNotHaveMarker(comments.In(p), DisableMarker, "false")),
-		),
-	)
+	cfg := angryjet.Config{
+		Patterns:                 patterns,
+		Overlay:                  readOverlay(*overlay),
+		Descriptions:             descriptions,
+		CRDReferences:            readCRDReferences(*crdFiles),
+		Header:                   header,
+		OutputDir:                *outputDir,
+		FilenameManaged:          *filenameManaged,
+		FilenameManagedList:      *filenameManagedList,
+		FilenamePC:               *filenamePC,
+		FilenamePCU:              *filenamePCU,
+		FilenamePCUList:          *filenamePCUList,
+		FilenameResolvers:        *filenameResolvers,
+		FilenameResolvableFields: *filenameResolvable,
+		FilenameResolversIndex:   *filenameIndex,
+		FilenameReferenceError:   *filenameRefError,
+		FilenameFixtures:         *filenameFixtures,
+		DeprecationRecorder:      *deprecationRecorder,
+		ProviderConfigValidator:  *pcValidator,
+		Tenant:                   *tenant,
+		Resolver:                 *resolver,
+		Banners:                  *banners,
+		Checksums:                *checksums,
+		Force:                    *force,
+		HubOnly:                  *hubOnly,
+		DisableSelectors:         *disableSelectors,
+		ClearSelectors:           *clearSelectors,
+		UpdateResolvers:          *updateResolvers,
+		ResolvedValues:           *resolvedValues,
+		SkipUnchanged:            *skipUnchanged,
+		DependencyAnnotation:     *dependencyAnno,
+		WrapWithMessage:          *wrapWithMessage,
+		ErrorWrapper:             *errorWrapper,
+		ReferenceError:           *referenceError,
+		SkipEmpty:                *skipEmpty,
+		FailureConditionType:     *failureCondition,
+		FailureConditionReason:   *failureReason,
+		Clock:                    *clock,
+		Transactional:            *transactional,
+		Retry:                    *retryPkg,
+		RetryDuration:            *retryDuration,
+		RetryFactor:              *retryFactor,
+		RetrySteps:               *retrySteps,
+		ResolvableFields:         *resolvableFields,
+		ResolversIndex:           *resolversIndex,
+		Fixtures:                 *fixtures,
+		FixturesExternal:         *fixturesExternal,
+		RuntimeLevel:             *runtimeLevel,
+		RuntimeModule:            *runtimeModule,
+		ResolverLogging:          *resolverLogging,
+		Provenance:               *provenance,
+		ResolverAssertions:       *assertions,
+		ControllerRuntime:        *controllerRuntime,
+		MaxDepth:                 *maxDepth,
+		Verbose:                  *verbose,
+		Include:                  *include,
+		Exclude:                  *exclude,
+		Allowlist:                readAllowlist(*allowlistFile),
+		MethodSets:               splitMethodSets(*methodSetsOf),
+		Only:                     *only,
+		Skip:                     *skip,
+		AccessorVariants:         *accessorVariants,
+		Logf: func(format string, args ...interface{}) {
+			fmt.Fprintf(os.Stderr, format+"\n", args...)
+		},
+	}
+	stale := &staleFiles{}
+	if *check {
+		cfg.Write, cfg.Remove = stale.write, stale.remove
+	}
 
-	return errors.Wrap(err, "cannot write managed resource methods")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	r, err := angryjet.Run(ctx, cfg)
+	stop()
+	kingpin.FatalIfError(err, "cannot generate methods")
+	for _, w := range r.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	for _, e := range r.Errors {
+		if e.Stack == "" {
+			fmt.Fprintf(os.Stderr, "%s\n", e)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s\n%s\n", e, e.Stack)
+	}
+	for _, m := range r.Modified {
+		if *check {
+			fmt.Fprintf(os.Stderr, "%s: locally modified\n", m)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s: locally modified, not overwritten\n", m)
+	}
+	for _, f := range stale.files {
+		fmt.Fprintf(os.Stderr, "%s: out of date\n", f)
+	}
+	if len(r.Errors) > 0 {
+		kingpin.Fatalf("cannot generate methods for %d types", len(r.Errors))
+	}
+	if *check && len(r.Modified)+len(stale.files) > 0 {
+		kingpin.Fatalf("%d generated files were edited, and %d are out of date", len(r.Modified), len(stale.files))
+	}
+	if len(r.Modified) > 0 {
+		kingpin.Fatalf("refusing to overwrite %d generated files that were edited; use --force to overwrite them", len(r.Modified))
+	}
 }
 
-// GenerateManagedList generates the resource.ManagedList method set.
-func GenerateManagedList(filename, header string, p *packages.Package) error {
-	receiver := "l"
+// staleFiles records the generated files that differ from those on disk,
+// rather than writing them.
+type staleFiles struct {
+	files []string
+}
 
-	methods := method.Set{
-		"GetItems": method.NewManagedGetItems(receiver, ResourceImport),
+// write records the supplied file if the supplied contents differ from those
+// on disk.
+func (s *staleFiles) write(filename string, data []byte) error {
+	existing, err := ioutil.ReadFile(filename) // nolint:gosec
+	if err != nil && !os.IsNotExist(err) {
+		return err
 	}
-
-	err := generate.WriteMethods(p, methods, filepath.Join(filepath.Dir(p.GoFiles[0]), filename),
-		generate.WithHeaders(header),
-		generate.WithImportAliases(map[string]string{
-			ResourceImport: ResourceAlias,
-		}),
-		generate.WithMatcher(match.AllOf(
-			match.ManagedList(),
-			match.DoesNotHaveMarker(comments.In(p), DisableMarker, "false")),
-		),
-	)
-
-	return errors.Wrap(err, "cannot write managed resource list methods")
+	if err != nil || !bytes.Equal(existing, data) {
+		s.files = append(s.files, filename)
+	}
+	return nil
 }
 
-// GenerateProviderConfig generates the resource.ProviderConfig method set.
-func GenerateProviderConfig(filename, header string, p *packages.Package) error {
-	receiver := "p"
+// remove records the supplied file, which would be removed.
+func (s *staleFiles) remove(filename string) error {
+	s.files = append(s.files, filename)
+	return nil
+}
 
-	methods := method.Set{
-		"SetUsers":      method.NewSetUsers(receiver),
-		"GetUsers":      method.NewGetUsers(receiver),
-		"SetConditions": method.NewSetConditions(receiver, RuntimeImport),
-		"GetCondition":  method.NewGetCondition(receiver, RuntimeImport),
+// splitMethodSets returns the supplied comma separated method sets of each
+// package pattern as slices.
+func splitMethodSets(in map[string]string) map[string][]string {
+	out := make(map[string][]string, len(in))
+	for pattern, names := range in {
+		out[pattern] = strings.Split(names, ",")
 	}
-
-	err := generate.WriteMethods(p, methods, filepath.Join(filepath.Dir(p.GoFiles[0]), filename),
-		generate.WithHeaders(header),
-		generate.WithImportAliases(map[string]string{RuntimeImport: RuntimeAlias}),
-		generate.WithMatcher(match.AllOf(
-			match.ProviderConfig(),
-			match.DoesNotHaveMarker(comments.In(p), DisableMarker, "false")),
-		),
-	)
-
-	return errors.Wrap(err, "cannot write provider config methods")
+	return out
 }
 
-// GenerateProviderConfigUsage generates the resource.ProviderConfigUsage method set.
-func GenerateProviderConfigUsage(filename, header string, p *packages.Package) error {
-	receiver := "p"
-
-	methods := method.Set{
-		"SetProviderConfigReference": method.NewSetRootProviderConfigReference(receiver, RuntimeImport),
-		"GetProviderConfigReference": method.NewGetRootProviderConfigReference(receiver, RuntimeImport),
-		"SetResourceReference":       method.NewSetRootResourceReference(receiver, RuntimeImport),
-		"GetResourceReference":       method.NewGetRootResourceReference(receiver, RuntimeImport),
+// runLint prints the findings of linting the supplied packages, as text or as
+// a JSON array, and exits with an error if there are any. Findings of
+// deprecated referenced types are only errors if strict is true. Suppressed
+// findings are only printed in the JSON array, and are never errors, nor are
+// warnings of unknown suppressed finding IDs.
+func runLint(pattern string, overlay map[string][]byte, asJSON, strict bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	findings, err := angryjet.Lint(ctx, angryjet.Config{Patterns: []string{pattern}, Overlay: overlay})
+	stop()
+	kingpin.FatalIfError(err, "cannot lint packages")
+	if asJSON {
+		out, err := json.MarshalIndent(findings, "", "  ")
+		kingpin.FatalIfError(err, "cannot marshal findings")
+		fmt.Println(string(out))
+	} else {
+		for _, f := range findings {
+			if !f.Suppressed {
+				fmt.Println(f)
+			}
+		}
 	}
+	problems := 0
+	for _, f := range findings {
+		if !f.Suppressed && !f.Warning && (strict || !f.Deprecated) {
+			problems++
+		}
+	}
+	if problems > 0 {
+		kingpin.Fatalf("found %d problems with references", problems)
+	}
+}
 
-	err := generate.WriteMethods(p, methods, filepath.Join(filepath.Dir(p.GoFiles[0]), filename),
-		generate.WithHeaders(header),
-		generate.WithImportAliases(map[string]string{RuntimeImport: RuntimeAlias}),
-		generate.WithMatcher(match.AllOf(
-			match.ProviderConfigUsage(),
-			match.DoesNotHaveMarker(comments.In(p), DisableMarker, "false")),
-		),
-	)
-
-	return errors.Wrap(err, "cannot write provider config usage methods")
+// runDescribe prints the descriptions of the references of the supplied
+// packages as a JSON array.
+func runDescribe(pattern string, overlay map[string][]byte) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	descriptions, err := angryjet.Describe(ctx, angryjet.Config{Patterns: []string{pattern}, Overlay: overlay})
+	stop()
+	kingpin.FatalIfError(err, "cannot describe references")
+	out, err := json.MarshalIndent(descriptions, "", "  ")
+	kingpin.FatalIfError(err, "cannot marshal descriptions")
+	fmt.Println(string(out))
 }
 
-// GenerateProviderConfigUsageList generates the
-// resource.ProviderConfigUsageList method set.
-func GenerateProviderConfigUsageList(filename, header string, p *packages.Package) error {
-	receiver := "p"
+// runMigrate rewrites the legacy reference markers of the supplied packages,
+// or prints the rewritten lines as a diff if this is a dry run. Nothing is
+// written if any file cannot be parsed.
+func runMigrate(pattern string, dryRun bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	migrations, err := angryjet.MigrateMarkers(ctx, angryjet.Config{Patterns: []string{pattern}}, angryjet.DefaultMarkerRules)
+	stop()
+	kingpin.FatalIfError(err, "cannot migrate markers")
+	wd, _ := os.Getwd()
+	for _, m := range migrations {
+		if rel, err := filepath.Rel(wd, m.Filename); err == nil {
+			m.Filename = filepath.ToSlash(rel)
+		}
+		if dryRun {
+			fmt.Print(m.Diff())
+			continue
+		}
+		fi, err := os.Stat(m.Filename)
+		kingpin.FatalIfError(err, "cannot stat %s", m.Filename)
+		kingpin.FatalIfError(ioutil.WriteFile(m.Filename, m.Migrated, fi.Mode()), "cannot write %s", m.Filename)
+		fmt.Println(m.Filename)
+	}
+}
 
-	methods := method.Set{
-		"GetItems": method.NewProviderConfigUsageGetItems(receiver, ResourceImport),
+// readDescriptions returns the descriptions of references in the supplied
+// file, or on standard input, if either is supplied.
+func readDescriptions(filename string, stdin bool) []angryjet.Description {
+	var b []byte
+	var err error
+	switch {
+	case stdin:
+		b, err = ioutil.ReadAll(os.Stdin)
+		kingpin.FatalIfError(err, "cannot read descriptions of references from standard input")
+	case filename != "":
+		b, err = ioutil.ReadFile(filename)
+		kingpin.FatalIfError(err, "cannot read descriptions of references from %s", filename)
+	default:
+		return nil
 	}
+	descriptions := make([]angryjet.Description, 0)
+	kingpin.FatalIfError(json.Unmarshal(b, &descriptions), "cannot unmarshal descriptions of references")
+	return descriptions
+}
 
-	err := generate.WriteMethods(p, methods, filepath.Join(filepath.Dir(p.GoFiles[0]), filename),
-		generate.WithHeaders(header),
-		generate.WithImportAliases(map[string]string{RuntimeImport: RuntimeAlias}),
-		generate.WithMatcher(match.AllOf(
-			match.ProviderConfigUsageList(),
-			match.DoesNotHaveMarker(comments.In(p), DisableMarker, "false")),
-		),
-	)
+// readCRDReferences returns the references described by the annotations of
+// the CustomResourceDefinitions in the supplied files.
+func readCRDReferences(filenames []string) []angryjet.CRDReferences {
+	var refs []angryjet.CRDReferences
+	for _, filename := range filenames {
+		b, err := ioutil.ReadFile(filename)
+		kingpin.FatalIfError(err, "cannot read CustomResourceDefinitions from %s", filename)
+		r, err := angryjet.ReadCRDReferences(b)
+		kingpin.FatalIfError(err, "cannot read references from CustomResourceDefinitions in %s", filename)
+		refs = append(refs, r...)
+	}
+	return refs
+}
 
-	return errors.Wrap(err, "cannot write provider config usage list methods")
+// readAllowlist returns the entries of the supplied types allowlist file, if
+// it is supplied.
+func readAllowlist(filename string) []string {
+	if filename == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(filename)
+	kingpin.FatalIfError(err, "cannot read types allowlist from %s", filename)
+	entries, err := angryjet.ParseAllowlist(b)
+	kingpin.FatalIfError(err, "cannot parse types allowlist %s", filename)
+	return entries
 }
 
-// GenerateReferences generates reference resolver calls.
-func GenerateReferences(filename, header string, p *packages.Package) error {
-	receiver := "mg"
-	comm := comments.In(p)
-
-	methods := method.Set{
-		"ResolveReferences": method.NewResolveReferences(types.NewTraverser(comm), receiver, ClientImport, ReferenceImport),
-	}
-
-	err := generate.WriteMethods(p, methods, filepath.Join(filepath.Dir(p.GoFiles[0]), filename),
-		generate.WithHeaders(header),
-		generate.WithImportAliases(map[string]string{
-			ClientImport:    ClientAlias,
-			ReferenceImport: ReferenceAlias,
-		}),
-		generate.WithMatcher(match.AllOf(
-			match.Managed(),
-			match.DoesNotHaveMarker(comm, DisableMarker, "false")),
-		),
-	)
+// readOverlay returns the contents of files by their absolute paths from the
+// supplied JSON file, if it is supplied.
+func readOverlay(filename string) map[string][]byte {
+	if filename == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(filename)
+	kingpin.FatalIfError(err, "cannot read overlay from %s", filename)
+	files := map[string]string{}
+	kingpin.FatalIfError(json.Unmarshal(b, &files), "cannot unmarshal overlay")
+	overlay := make(map[string][]byte, len(files))
+	for path, contents := range files {
+		abs, err := filepath.Abs(path)
+		kingpin.FatalIfError(err, "cannot get absolute path of overlaid file %s", path)
+		overlay[abs] = []byte(contents)
+	}
+	return overlay
+}
 
-	return errors.Wrap(err, "cannot write reference resolver methods")
+// describedPackages returns the packages of the supplied descriptions.
+func describedPackages(descriptions []angryjet.Description) []string {
+	seen := map[string]bool{}
+	pkgs := make([]string, 0)
+	for _, d := range descriptions {
+		if !seen[d.Package] {
+			seen[d.Package] = true
+			pkgs = append(pkgs, d.Package)
+		}
+	}
+	return pkgs
 }
@@ -0,0 +1,150 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fieldpath formats field paths in the syntax of crossplane-runtime's
+// fieldpath package, for example spec.forProvider.tags[example.org/name] or
+// spec.forProvider.subnets[*].id. All field paths that angryjet emits, in
+// generated code and in reports, are formatted by this package.
+package fieldpath
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// Wildcard is the index of a segment that selects every element of a slice.
+const Wildcard = "*"
+
+// A Segment of a field path.
+type Segment struct {
+	// Field is the name of the field selected by this segment. It is empty
+	// if this is an index segment.
+	Field string
+
+	// Index is the index, or Wildcard, of the element selected by this
+	// segment. It is empty if this is a field segment.
+	Index string
+}
+
+// Field returns a segment that selects the named field.
+func Field(name string) Segment {
+	return Segment{Field: name}
+}
+
+// Index returns a segment that selects the element at the supplied index.
+func Index(i int) Segment {
+	return Segment{Index: strconv.Itoa(i)}
+}
+
+// All returns a segment that selects every element.
+func All() Segment {
+	return Segment{Index: Wildcard}
+}
+
+// A Path is a sequence of segments.
+type Path []Segment
+
+// Fields returns a path of the named fields.
+func Fields(names ...string) Path {
+	p := make(Path, len(names))
+	for i, n := range names {
+		p[i] = Field(n)
+	}
+	return p
+}
+
+// Parse parses a field path in the syntax that String formats. Segments that
+// are enclosed in brackets are indices if they are integers or Wildcard, and
+// fields otherwise.
+func Parse(s string) (Path, error) {
+	p := Path{}
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, errors.Errorf("unterminated [ at position %d of field path %q", i, s)
+			}
+			seg := s[i+1 : i+end]
+			if _, err := strconv.Atoi(seg); err == nil || seg == Wildcard {
+				p = append(p, Segment{Index: seg})
+			} else {
+				p = append(p, Field(seg))
+			}
+			i += end + 1
+		case s[i] == '.' && i > 0:
+			i++
+			fallthrough
+		default:
+			end := strings.IndexAny(s[i:], ".[")
+			if end < 0 {
+				end = len(s) - i
+			}
+			if end == 0 {
+				return nil, errors.Errorf("empty field at position %d of field path %q", i, s)
+			}
+			p = append(p, Field(s[i:i+end]))
+			i += end
+		}
+	}
+	return p, nil
+}
+
+// WithSuffix returns a copy of the path whose last segment, which must be a
+// field, has the supplied suffix.
+func (p Path) WithSuffix(suffix string) Path {
+	out := append(Path{}, p...)
+	out[len(out)-1].Field += suffix
+	return out
+}
+
+// String formats the path. Fields whose names contain only letters, digits,
+// underscores, and hyphens are separated by dots. Other fields, for example
+// those whose names contain dots or slashes, are enclosed in brackets, as are
+// indices.
+func (p Path) String() string {
+	var sb strings.Builder
+	for _, s := range p {
+		switch {
+		case s.Index != "":
+			sb.WriteString("[" + s.Index + "]")
+		case plain(s.Field):
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(s.Field)
+		default:
+			sb.WriteString("[" + s.Field + "]")
+		}
+	}
+	return sb.String()
+}
+
+// plain returns true if the supplied field name can be written after a dot.
+func plain(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fieldpath
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+func TestPathString(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		path   Path
+		want   string
+	}{
+		"Empty": {
+			reason: "An empty path should be an empty string.",
+			path:   Path{},
+			want:   "",
+		},
+		"Fields": {
+			reason: "Plain fields should be separated by dots.",
+			path:   Fields("spec", "forProvider", "subnet_id", "max-size"),
+			want:   "spec.forProvider.subnet_id.max-size",
+		},
+		"Indices": {
+			reason: "Indices and wildcards should be enclosed in brackets.",
+			path:   Path{Field("spec"), Field("subnets"), Index(0), Field("routes"), All(), Field("id")},
+			want:   "spec.subnets[0].routes[*].id",
+		},
+		"Dots": {
+			reason: "Fields containing dots should be enclosed in brackets.",
+			path:   Fields("metadata", "annotations", "example.org", "name"),
+			want:   "metadata.annotations[example.org].name",
+		},
+		"Slashes": {
+			reason: "Fields containing slashes should be enclosed in brackets.",
+			path:   Fields("metadata", "labels", "example.org/name", "value"),
+			want:   "metadata.labels[example.org/name].value",
+		},
+		"JSONPointerCharacters": {
+			reason: "Fields containing characters that JSON pointers escape should be enclosed in brackets rather than escaped.",
+			path:   Fields("spec", "a~b/c"),
+			want:   "spec[a~b/c]",
+		},
+		"FirstFieldNotPlain": {
+			reason: "A first field that is not plain should be enclosed in brackets.",
+			path:   Fields("a.b", "c"),
+			want:   "[a.b].c",
+		},
+		"EmptyField": {
+			reason: "An empty field should be enclosed in brackets.",
+			path:   Fields("spec", "", "id"),
+			want:   "spec[].id",
+		},
+		"Unicode": {
+			reason: "Fields of unicode letters and digits should be plain.",
+			path:   Fields("spec", "größe", "名前", "٣"),
+			want:   "spec.größe.名前.٣",
+		},
+		"UnicodeNotPlain": {
+			reason: "Fields of unicode letters containing dots or spaces should be enclosed in brackets.",
+			path:   Fields("spec", "名前.größe", "a b"),
+			want:   "spec[名前.größe][a b]",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.path.String()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nString(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	type want struct {
+		path Path
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		s      string
+		want   want
+	}{
+		"Empty": {
+			reason: "An empty string should be an empty path.",
+			s:      "",
+			want:   want{path: Path{}},
+		},
+		"Fields": {
+			reason: "Fields separated by dots should be parsed.",
+			s:      "spec.forProvider.max-size",
+			want:   want{path: Fields("spec", "forProvider", "max-size")},
+		},
+		"Indices": {
+			reason: "Integers and wildcards enclosed in brackets should be parsed as indices.",
+			s:      "subnets[0].routes[*].id",
+			want:   want{path: Path{Field("subnets"), Index(0), Field("routes"), All(), Field("id")}},
+		},
+		"BracketedFields": {
+			reason: "Other segments enclosed in brackets should be parsed as fields.",
+			s:      "[a.b].labels[example.org/name].value",
+			want:   want{path: Fields("a.b", "labels", "example.org/name", "value")},
+		},
+		"Unterminated": {
+			reason: "A bracket that is not closed should be an error.",
+			s:      "tags[example.org",
+			want:   want{err: errors.New(`unterminated [ at position 4 of field path "tags[example.org"`)},
+		},
+		"EmptyField": {
+			reason: "An empty field that is not enclosed in brackets should be an error.",
+			s:      "spec..id",
+			want:   want{err: errors.New(`empty field at position 5 of field path "spec..id"`)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Parse(tc.s)
+			if diff := cmp.Diff(tc.want.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nParse(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.path, got); diff != "" {
+				t.Errorf("\n%s\nParse(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// cmpErrors compares errors by their messages.
+func cmpErrors() cmp.Option {
+	return cmp.Comparer(func(a, b error) bool {
+		if a == nil || b == nil {
+			return a == nil && b == nil
+		}
+		return a.Error() == b.Error()
+	})
+}
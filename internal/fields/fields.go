@@ -38,7 +38,9 @@ const (
 	NameItems                = "Items"
 )
 
-// Field type suffixes.
+// Field type suffixes. Those of crossplane-runtime types omit the module, so that
+// they match the types of a fork of crossplane-runtime with another path, for
+// example example.org/provider/internal/thirdparty/crossplane-runtime.
 const (
 	TypeSuffixTypeMeta             = "k8s.io/apimachinery/pkg/apis/meta/v1.TypeMeta"
 	TypeSuffixObjectMeta           = "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"
@@ -46,11 +48,11 @@ const (
 	TypeSuffixSpec                 = NameSpec
 	TypeSuffixSpecTemplate         = NameSpecTemplate
 	TypeSuffixStatus               = NameStatus
-	TypeSuffixResourceSpec         = "github.com/crossplane/crossplane-runtime/apis/common/v1.ResourceSpec"
-	TypeSuffixResourceStatus       = "github.com/crossplane/crossplane-runtime/apis/common/v1.ResourceStatus"
-	TypeSuffixProviderConfigSpec   = "github.com/crossplane/crossplane-runtime/apis/common/v1.ProviderConfigSpec"
-	TypeSuffixProviderConfigStatus = "github.com/crossplane/crossplane-runtime/apis/common/v1.ProviderConfigStatus"
-	TypeSuffixProviderConfigUsage  = "github.com/crossplane/crossplane-runtime/apis/common/v1.ProviderConfigUsage"
+	TypeSuffixResourceSpec         = "/apis/common/v1.ResourceSpec"
+	TypeSuffixResourceStatus       = "/apis/common/v1.ResourceStatus"
+	TypeSuffixProviderConfigSpec   = "/apis/common/v1.ProviderConfigSpec"
+	TypeSuffixProviderConfigStatus = "/apis/common/v1.ProviderConfigStatus"
+	TypeSuffixProviderConfigUsage  = "/apis/common/v1.ProviderConfigUsage"
 )
 
 func matches(s *types.Struct, m Matcher) bool {
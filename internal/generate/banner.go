@@ -0,0 +1,166 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// regexBanner matches the banner comment of a type, as written by Banner.
+var regexBanner = regexp.MustCompile(`^// ===== \S+ =====$`)
+
+// Banner returns the comment that precedes the declarations of the named type
+// in a file whose declarations are grouped by GroupByType.
+func Banner(name string) string {
+	return "// ===== " + name + " ====="
+}
+
+// GroupByType returns the supplied Go file with the declarations of each type,
+// its methods, and assertions that it implements an interface, moved to where
+// the first of them is, and preceded by the Banner of the type. Other
+// declarations are kept where they are. Banners that the file already has are
+// replaced, so grouping a grouped file doesn't change it.
+func GroupByType(data []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "grouped.go", data, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse Go file")
+	}
+
+	// The header is the package clause and imports. Each declaration after
+	// it is kept with the comments that precede it, back to the end of the
+	// declaration before it.
+	last := fset.Position(f.Name.End()).Offset
+	decls := make([]ast.Decl, 0, len(f.Decls))
+	for _, d := range f.Decls {
+		if gd, ok := d.(*ast.GenDecl); ok && gd.Tok == token.IMPORT {
+			last = fset.Position(gd.End()).Offset
+			continue
+		}
+		decls = append(decls, d)
+	}
+
+	type group struct {
+		owner  string
+		chunks []string
+	}
+	groups := make([]*group, 0, len(decls))
+	byOwner := map[string]*group{}
+	start := last
+	for _, d := range decls {
+		end := fset.Position(d.End()).Offset
+		chunk := withoutBanners(string(data[start:end]))
+		start = end
+
+		o := owner(d)
+		if g, ok := byOwner[o]; ok && o != "" {
+			g.chunks = append(g.chunks, chunk)
+			continue
+		}
+		g := &group{owner: o, chunks: []string{chunk}}
+		groups = append(groups, g)
+		if o != "" {
+			byOwner[o] = g
+		}
+	}
+
+	b := &bytes.Buffer{}
+	b.Write(data[:last])
+	for _, g := range groups {
+		if g.owner != "" {
+			b.WriteString("\n\n" + Banner(g.owner) + "\n")
+		}
+		for _, c := range g.chunks {
+			b.WriteString("\n\n" + strings.TrimLeft(c, "\n"))
+		}
+	}
+	b.Write(data[start:])
+
+	out, err := format.Source(b.Bytes())
+	return out, errors.Wrap(err, "cannot format grouped Go file")
+}
+
+// withoutBanners returns the supplied source without the lines that are
+// banners.
+func withoutBanners(src string) string {
+	lines := strings.Split(src, "\n")
+	kept := lines[:0]
+	for _, l := range lines {
+		if regexBanner.MatchString(strings.TrimSpace(l)) {
+			continue
+		}
+		kept = append(kept, l)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// owner returns the name of the type that the supplied declaration belongs to:
+// the receiver type of a method, the type declared by a type declaration of a
+// single type, or the type of an assertion such as var _ I = &T{}. It returns
+// an empty string if the declaration belongs to no type.
+func owner(d ast.Decl) string {
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil || len(d.Recv.List) != 1 {
+			return ""
+		}
+		return typeName(d.Recv.List[0].Type)
+	case *ast.GenDecl:
+		if len(d.Specs) != 1 {
+			return ""
+		}
+		switch s := d.Specs[0].(type) {
+		case *ast.TypeSpec:
+			return s.Name.Name
+		case *ast.ValueSpec:
+			if len(s.Names) != 1 || s.Names[0].Name != "_" || len(s.Values) != 1 {
+				return ""
+			}
+			if u, ok := s.Values[0].(*ast.UnaryExpr); ok && u.Op == token.AND {
+				if cl, ok := u.X.(*ast.CompositeLit); ok {
+					return typeName(cl.Type)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// typeName returns the name of the supplied receiver or composite literal
+// type, without a pointer or type parameters, or an empty string if it is not
+// a type of the package.
+func typeName(e ast.Expr) string {
+	switch t := e.(type) {
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.IndexExpr:
+		return typeName(t.X)
+	case *ast.IndexListExpr:
+		return typeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGroupByType(t *testing.T) {
+	grouped := `// Code generated by angryjet. DO NOT EDIT.
+
+package v1alpha1
+
+import xpv1 "example.org/xpv1"
+
+// ===== Model =====
+
+var _ Managed = &Model{}
+
+// GetCondition of this Model.
+func (mg *Model) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences() error {
+	return nil
+}
+
+// ===== Widget =====
+
+// GetCondition of this Widget.
+func (mg *Widget) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+// Resolve resolves the references of any managed resource.
+func Resolve() error {
+	return nil
+}
+`
+
+	cases := map[string]struct {
+		reason string
+		data   string
+		want   string
+	}{
+		"Interleaved": {
+			reason: "The declarations of each type should be moved to where the first of them is, and preceded by its banner. Other declarations should be kept where they are.",
+			data: `// Code generated by angryjet. DO NOT EDIT.
+
+package v1alpha1
+
+import xpv1 "example.org/xpv1"
+
+var _ Managed = &Model{}
+
+// GetCondition of this Model.
+func (mg *Model) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+// GetCondition of this Widget.
+func (mg *Widget) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+// Resolve resolves the references of any managed resource.
+func Resolve() error {
+	return nil
+}
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences() error {
+	return nil
+}
+`,
+			want: grouped,
+		},
+		"Grouped": {
+			reason: "Grouping a file that is already grouped should not change it.",
+			data:   grouped,
+			want:   grouped,
+		},
+		"StaleBanner": {
+			reason: "A banner of a type that no longer has declarations should be removed.",
+			data: `// Code generated by angryjet. DO NOT EDIT.
+
+package v1alpha1
+
+// ===== Gizmo =====
+
+// ===== Model =====
+
+// GetItems of this ModelList.
+func (l *ModelList) GetItems() []Model {
+	return l.Items
+}
+`,
+			want: `// Code generated by angryjet. DO NOT EDIT.
+
+package v1alpha1
+
+// ===== ModelList =====
+
+// GetItems of this ModelList.
+func (l *ModelList) GetItems() []Model {
+	return l.Items
+}
+`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := GroupByType([]byte(tc.data))
+			if err != nil {
+				t.Fatalf("\n%s\nGroupByType(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("\n%s\nGroupByType(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/format"
+
+	"github.com/pkg/errors"
+)
+
+// ChecksumPrefix precedes the checksum of a generated file in the comment that
+// records it, on the line after HeaderGenerated.
+const ChecksumPrefix = "Checksum: sha256:"
+
+// Checksum returns the checksum of the supplied generated Go file: the hex
+// encoded SHA-256 of its contents without the comment that records its
+// checksum. The contents are formatted first, if they can be, so that the
+// checksum doesn't change if the file is only reformatted.
+func Checksum(data []byte) string {
+	body := withoutChecksum(data)
+	if formatted, err := format.Source(body); err == nil {
+		body = formatted
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// AddChecksum returns the supplied generated Go file with a comment that
+// records its Checksum on the line after HeaderGenerated, replacing any it
+// already has. A file without HeaderGenerated, such as a handwritten file whose
+// methods were updated by UpdateMethods, is returned without a checksum.
+func AddChecksum(data []byte) []byte {
+	body := withoutChecksum(data)
+	header := []byte("// " + HeaderGenerated + "\n")
+	i := bytes.Index(body, header)
+	if i < 0 {
+		return body
+	}
+	i += len(header)
+	out := make([]byte, 0, len(body)+len(ChecksumPrefix)+sha256.Size*2+4)
+	out = append(out, body[:i]...)
+	out = append(out, "// "+ChecksumPrefix+Checksum(body)+"\n"...)
+	return append(out, body[i:]...)
+}
+
+// Modified returns true if the supplied generated Go file records a checksum
+// that isn't its Checksum, because it was edited after it was generated. A
+// file that records no checksum is not considered modified.
+func Modified(data []byte) bool {
+	recorded, ok := recordedChecksum(data)
+	return ok && recorded != Checksum(data)
+}
+
+// An errModified is returned when a generated file is not written because the
+// existing file was modified.
+type errModified struct {
+	file string
+}
+
+func (e errModified) Error() string {
+	return fmt.Sprintf("refusing to overwrite %s, which was modified after it was generated", e.file)
+}
+
+// IsModified returns true if the supplied error was returned because a file
+// was not written, as the existing file was modified after it was generated.
+func IsModified(err error) bool {
+	_, ok := errors.Cause(err).(errModified)
+	return ok
+}
+
+// recordedChecksum returns the checksum recorded by the supplied generated Go
+// file, if it records one.
+func recordedChecksum(data []byte) (string, bool) {
+	for _, l := range bytes.Split(data, []byte("\n")) {
+		if c, ok := checksumLine(l); ok {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// withoutChecksum returns the supplied generated Go file without the lines of
+// the comments that record its checksum.
+func withoutChecksum(data []byte) []byte {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	out := make([]byte, 0, len(data))
+	for _, l := range lines {
+		if _, ok := checksumLine(l); ok {
+			continue
+		}
+		out = append(out, l...)
+	}
+	return out
+}
+
+// checksumLine returns the checksum recorded by the supplied line, if it is a
+// comment that records one.
+func checksumLine(l []byte) (string, bool) {
+	prefix := []byte("// " + ChecksumPrefix)
+	l = bytes.TrimRight(l, "\r\n")
+	if !bytes.HasPrefix(l, prefix) {
+		return "", false
+	}
+	return string(l[len(prefix):]), true
+}
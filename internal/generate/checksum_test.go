@@ -0,0 +1,207 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-tools/internal/method"
+)
+
+const generated = `// Code generated by angryjet. DO NOT EDIT.
+
+package v1alpha1
+
+// Hello of this Model.
+func (m *Model) Hello() {}
+`
+
+func TestChecksum(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		data   string
+		same   bool
+	}{
+		"Reformatted": {
+			reason: "A file that is only reformatted should have the same checksum.",
+			data:   strings.Replace(generated, "func (m *Model) Hello() {}", "func (m    *Model)   Hello() {}", 1),
+			same:   true,
+		},
+		"WithChecksum": {
+			reason: "The comment that records the checksum of a file should not change its checksum.",
+			data:   strings.Replace(generated, "DO NOT EDIT.\n", "DO NOT EDIT.\n// "+ChecksumPrefix+"0123\n", 1),
+			same:   true,
+		},
+		"Edited": {
+			reason: "A file that is edited should have a different checksum.",
+			data:   strings.Replace(generated, "Hello() {}", "Hello() { panic(\"hi\") }", 1),
+			same:   false,
+		},
+	}
+
+	want := Checksum([]byte(generated))
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Checksum([]byte(tc.data))
+			if (got == want) != tc.same {
+				t.Errorf("\n%s\nChecksum(...): want same %t, got %s and %s", tc.reason, tc.same, want, got)
+			}
+		})
+	}
+}
+
+func TestAddChecksum(t *testing.T) {
+	withChecksum := strings.Replace(generated, "DO NOT EDIT.\n", "DO NOT EDIT.\n// "+ChecksumPrefix+Checksum([]byte(generated))+"\n", 1)
+	handwritten := strings.Replace(generated, "// Code generated by angryjet. DO NOT EDIT.\n\n", "", 1)
+
+	cases := map[string]struct {
+		reason string
+		data   string
+		want   string
+	}{
+		"Generated": {
+			reason: "The checksum should be recorded on the line after the generated code header.",
+			data:   generated,
+			want:   withChecksum,
+		},
+		"Replaced": {
+			reason: "A recorded checksum should be replaced, rather than recorded again.",
+			data:   strings.Replace(generated, "DO NOT EDIT.\n", "DO NOT EDIT.\n// "+ChecksumPrefix+"0123\n", 1),
+			want:   withChecksum,
+		},
+		"Handwritten": {
+			reason: "A file without a generated code header should not have a checksum recorded.",
+			data:   handwritten,
+			want:   handwritten,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := AddChecksum([]byte(tc.data))
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("\n%s\nAddChecksum(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestModified(t *testing.T) {
+	recorded := string(AddChecksum([]byte(generated)))
+
+	cases := map[string]struct {
+		reason string
+		data   string
+		want   bool
+	}{
+		"Unmodified": {
+			reason: "A file whose recorded checksum matches its contents should not be modified.",
+			data:   recorded,
+			want:   false,
+		},
+		"Reformatted": {
+			reason: "A file that was only reformatted should not be modified.",
+			data:   strings.Replace(recorded, "(m *Model)", "(m\t*Model)", 1),
+			want:   false,
+		},
+		"Edited": {
+			reason: "A file that was edited after its checksum was recorded should be modified.",
+			data:   strings.Replace(recorded, "Hello() {}", "Hello() { panic(\"hi\") }", 1),
+			want:   true,
+		},
+		"NoChecksum": {
+			reason: "A file that records no checksum should not be modified.",
+			data:   strings.Replace(generated, "Hello() {}", "Hello() { panic(\"hi\") }", 1),
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Modified([]byte(tc.data))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nModified(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWriteMethodsWithChecksums(t *testing.T) {
+	ms := method.Set{
+		"Hello": func(f *jen.File, o types.Object) {
+			f.Commentf("Hello of this %s.", o.Name())
+			f.Func().Params(jen.Id("m").Op("*").Id(o.Name())).Id("Hello").Params().Block()
+		},
+	}
+	file := filepath.Join(t.TempDir(), "zz_generated.hello.go")
+	write := func(t *testing.T, wo ...WriteOption) error {
+		t.Helper()
+		return WriteMethods(loadPackage(t, source), ms, file, append(wo, WithChecksums())...)
+	}
+	read := func(t *testing.T) string {
+		t.Helper()
+		data, err := os.ReadFile(file) // nolint:gosec
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	if err := write(t); err != nil {
+		t.Fatal(err)
+	}
+	want := string(AddChecksum([]byte(generated)))
+	if diff := cmp.Diff(want, read(t)); diff != "" {
+		t.Errorf("\nThe checksum of the generated file should be recorded.\nWriteMethods(...): -want, +got:\n%s", diff)
+	}
+
+	if err := write(t); err != nil {
+		t.Errorf("\nAn unmodified file should be overwritten.\nWriteMethods(...): %v", err)
+	}
+
+	edited := strings.Replace(want, "Hello() {}", "Hello() { panic(\"hi\") }", 1)
+	if err := os.WriteFile(file, []byte(edited), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := write(t); !IsModified(err) {
+		t.Errorf("\nA modified file should not be overwritten.\nWriteMethods(...): want modified error, got %v", err)
+	}
+	modified := ""
+	if err := write(t, WithModified(func(file string) { modified = file })); err != nil {
+		t.Errorf("\nA modified file should be reported, rather than returning an error.\nWriteMethods(...): %v", err)
+	}
+	if diff := cmp.Diff(file, modified); diff != "" {
+		t.Errorf("\nA modified file should be reported.\nWriteMethods(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(edited, read(t)); diff != "" {
+		t.Errorf("\nA modified file should not be overwritten.\nWriteMethods(...): -want, +got:\n%s", diff)
+	}
+
+	if err := write(t, WithOverwriteModified()); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, read(t)); diff != "" {
+		t.Errorf("\nA modified file should be overwritten if forced.\nWriteMethods(...): -want, +got:\n%s", diff)
+	}
+}
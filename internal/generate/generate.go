@@ -19,10 +19,15 @@ package generate
 
 import (
 	"bytes"
+	"context"
+	"go/ast"
 	"go/parser"
 	"go/token"
 	"go/types"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
 
 	"github.com/dave/jennifer/jen"
 	"github.com/pkg/errors"
@@ -37,9 +42,22 @@ import (
 const HeaderGenerated = "Code generated by angryjet. DO NOT EDIT."
 
 type options struct {
-	Matches       match.Object
+	Matches       match.Matcher
 	ImportAliases map[string]string
 	Headers       []string
+	Transforms    []func(file string, data []byte) ([]byte, error)
+	Write         func(file string, data []byte) error
+	Remove        func(file string) error
+	Context       context.Context
+	Recover       func(file string, o types.Object, recovered interface{}, stack []byte)
+	Generated     func(file string, o types.Object)
+	Methods       bool
+	Update        []string
+	Banners       bool
+	Checksums     bool
+	Force         bool
+	Modified      func(file string)
+	ExternalTest  bool
 }
 
 // A WriteOption configures method generation behaviour.
@@ -54,9 +72,9 @@ func WithHeaders(h ...string) WriteOption {
 	}
 }
 
-// WithMatcher specifies an Object matcher that is used to filter the Objects
-// within the package down to the set that need the generated methods.
-func WithMatcher(m match.Object) WriteOption {
+// WithMatcher specifies a Matcher that is used to filter the Objects within the
+// package down to the set that need the generated methods.
+func WithMatcher(m match.Matcher) WriteOption {
 	return func(o *options) {
 		o.Matches = m
 	}
@@ -72,17 +90,324 @@ func WithImportAliases(ia map[string]string) WriteOption {
 	}
 }
 
+// WithWriter specifies a function that is used to write the generated file,
+// instead of writing it to disk.
+func WithWriter(fn func(file string, data []byte) error) WriteOption {
+	return func(o *options) {
+		o.Write = fn
+	}
+}
+
+// WithRemover specifies a function that is used to remove a previously
+// generated file that would now contain no declarations, instead of removing it
+// from disk.
+func WithRemover(fn func(file string) error) WriteOption {
+	return func(o *options) {
+		o.Remove = fn
+	}
+}
+
+// WithTransform specifies a function that is called with the rendered contents
+// of the generated file before it is written, and returns the contents to be
+// written instead. It may be used to add comments, or to run a formatter.
+// Transforms are called in the order they are supplied.
+func WithTransform(fn func(file string, data []byte) ([]byte, error)) WriteOption {
+	return func(o *options) {
+		o.Transforms = append(o.Transforms, fn)
+	}
+}
+
+// WithContext specifies a context that is checked for cancellation before
+// the file is generated, and before each object is generated on its own if
+// WithRecover is supplied and generating all objects panics.
+func WithContext(ctx context.Context) WriteOption {
+	return func(o *options) {
+		o.Context = ctx
+	}
+}
+
+// WithRecover specifies a function that is called with the value and stack
+// trace of any panic that occurs while generating code for an object. The
+// object is omitted from the generated file. Objects are only generated on
+// their own, to find those for which generation panics, if generating all of
+// them panics. Panics are not recovered unless this option is supplied.
+func WithRecover(fn func(file string, o types.Object, recovered interface{}, stack []byte)) WriteOption {
+	return func(o *options) {
+		o.Recover = fn
+	}
+}
+
+// WithGenerated specifies a function that is called with each object whose
+// declarations were written to the generated file, once it is written. It is
+// not called if the file is not written. WriteMethods only calls it with the
+// objects that the written file declares methods of.
+func WithGenerated(fn func(file string, o types.Object)) WriteOption {
+	return func(o *options) {
+		o.Generated = fn
+	}
+}
+
+// WithUpdateMethods specifies that only the methods of the supplied names are
+// replaced in the generated file if it already exists, using UpdateMethods,
+// rather than the whole file. The file is written as usual if it doesn't
+// exist.
+func WithUpdateMethods(names ...string) WriteOption {
+	return func(o *options) {
+		o.Update = append(o.Update, names...)
+	}
+}
+
+// WithBanners specifies that the declarations of each type are grouped, and
+// preceded by a banner comment, using GroupByType. They are grouped after
+// methods are updated, so that updated methods are grouped with the other
+// methods of their type.
+func WithBanners() WriteOption {
+	return func(o *options) {
+		o.Banners = true
+	}
+}
+
+// WithChecksums specifies that the Checksum of the generated file is recorded
+// in a comment on the line after HeaderGenerated, and that an existing file
+// whose recorded checksum doesn't match its contents, because it was modified
+// after it was generated, is not overwritten. An error satisfying IsModified is
+// returned instead, unless WithModified is supplied. Files whose methods are
+// updated by WithUpdateMethods are always written, as their other
+// declarations are expected to be modified.
+func WithChecksums() WriteOption {
+	return func(o *options) {
+		o.Checksums = true
+	}
+}
+
+// WithOverwriteModified specifies that an existing file is overwritten even if
+// WithChecksums finds that it was modified after it was generated.
+func WithOverwriteModified() WriteOption {
+	return func(o *options) {
+		o.Force = true
+	}
+}
+
+// WithModified specifies a function that is called with the name of an existing
+// file that WithChecksums finds was modified after it was generated, instead
+// of returning an error. The file is not written, or removed.
+func WithModified(fn func(file string)) WriteOption {
+	return func(o *options) {
+		o.Modified = fn
+	}
+}
+
+// WithExternalTestPackage specifies that the generated file belongs to the
+// external test package of the package, for example v1alpha1_test, which
+// imports the package to refer to its declarations. The file should be named
+// with a _test.go suffix.
+func WithExternalTestPackage() WriteOption {
+	return func(o *options) {
+		o.ExternalTest = true
+	}
+}
+
 // WriteMethods writes the supplied methods for each object in the supplied
 // package to the supplied file. Use WithMatcher to limit the objects for which
 // methods will be written. Methods will not be generated if a method with the
 // same name is already defined for the object outside of the supplied filename.
 // Files will not be written if they would contain no methods.
 func WriteMethods(p *packages.Package, ms method.Set, file string, wo ...WriteOption) error {
-	opts := &options{Matches: func(o types.Object) bool { return true }}
+	return WriteFile(p, file, func(f *jen.File, objects []types.Object) {
+		for _, o := range objects {
+			ms.Write(f, o, method.DefinedOutside(p.Fset, file))
+		}
+	}, append(wo, func(o *options) { o.Methods = true })...)
+}
+
+// WriteFile writes the declarations added by the supplied function to the
+// supplied file. The function is called with all objects within the supplied
+// package, in order of name. Use WithMatcher to limit the objects it is called
+// with. Files will not be written if they would contain no declarations. An
+// existing file that would contain no declarations is removed if it carries
+// HeaderGenerated, unless WithChecksums finds that it was modified after it was
+// generated, so that it doesn't refer to declarations that no longer exist.
+func WriteFile(p *packages.Package, file string, fn func(f *jen.File, objects []types.Object), wo ...WriteOption) error {
+	opts := &options{
+		Matches: match.Func("any object", func(_ types.Object) bool { return true }),
+		Write:   writeFile,
+		Remove:  removeFile,
+		Context: context.Background(),
+	}
 	for _, fn := range wo {
 		fn(opts)
 	}
+	if err := opts.Context.Err(); err != nil {
+		return errors.Wrap(err, "generation was cancelled")
+	}
+
+	objects := make([]types.Object, 0)
+	for _, n := range p.Types.Scope().Names() {
+		o := p.Types.Scope().Lookup(n)
+		if !opts.Matches.Match(o) {
+			continue
+		}
+		objects = append(objects, o)
+	}
+	f, generated := opts.newFile(p), objects
+	if opts.Recover == nil {
+		fn(f, objects)
+	} else {
+		var err error
+		if f, generated, err = recovering(p, file, fn, objects, opts); err != nil {
+			return err
+		}
+	}
+
+	b := &bytes.Buffer{}
+	if err := f.Render(b); err != nil {
+		return errors.Wrap(err, "cannot render Go file")
+	}
+
+	existing, err := opts.existing(file)
+	if err != nil {
+		return err
+	}
+	if ProducedNothing(b.Bytes()) && existing == nil {
+		if len(generated) < len(objects) {
+			// Don't remove the file because the declarations it
+			// would contain could not be generated.
+			return nil
+		}
+		return opts.orphaned(file)
+	}
+
+	data := b.Bytes()
+	for _, fn := range opts.Transforms {
+		var err error
+		if data, err = fn(file, data); err != nil {
+			return errors.Wrap(err, "cannot transform Go file")
+		}
+	}
+	if existing != nil {
+		if data, err = UpdateMethods(existing, data, opts.Update...); err != nil {
+			return errors.Wrap(err, "cannot update Go file")
+		}
+	}
+	if opts.Banners {
+		if data, err = GroupByType(data); err != nil {
+			return errors.Wrap(err, "cannot group Go file by type")
+		}
+	}
+	if opts.Checksums {
+		modified, err := opts.modified(file)
+		if err != nil {
+			return err
+		}
+		if modified {
+			if opts.Modified == nil {
+				return errModified{file: file}
+			}
+			opts.Modified(file)
+			return nil
+		}
+		data = AddChecksum(data)
+	}
 
+	if err := opts.Write(file, data); err != nil {
+		return errors.Wrap(err, "cannot write Go file")
+	}
+	if opts.Generated == nil {
+		return nil
+	}
+	var receivers map[string]bool
+	if opts.Methods {
+		receivers = receiversOf(data)
+	}
+	for _, o := range generated {
+		if receivers == nil || receivers[o.Name()] {
+			opts.Generated(file, o)
+		}
+	}
+	return nil
+}
+
+// receiversOf returns the names of the types of the receivers of the methods
+// declared by the supplied Go file.
+func receiversOf(data []byte) map[string]bool {
+	names := map[string]bool{}
+	f, err := parser.ParseFile(token.NewFileSet(), "f.go", data, parser.SkipObjectResolution)
+	if err != nil {
+		return names
+	}
+	for _, d := range f.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if !ok || fd.Recv == nil || len(fd.Recv.List) == 0 {
+			continue
+		}
+		t := fd.Recv.List[0].Type
+		if star, ok := t.(*ast.StarExpr); ok {
+			t = star.X
+		}
+		if id, ok := t.(*ast.Ident); ok {
+			names[id.Name] = true
+		}
+	}
+	return names
+}
+
+// existing returns the contents of the supplied file if methods are to be
+// updated in it, or nil if they are not or it doesn't exist.
+func (o *options) existing(file string) ([]byte, error) {
+	if len(o.Update) == 0 {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(file) // nolint:gosec
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, errors.Wrap(err, "cannot read existing Go file")
+}
+
+// modified returns true if the supplied file exists, and was modified after it
+// was generated, unless modified files are to be overwritten or methods are to
+// be updated in it.
+func (o *options) modified(file string) (bool, error) {
+	if o.Force || len(o.Update) > 0 {
+		return false, nil
+	}
+	data, err := ioutil.ReadFile(file) // nolint:gosec
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "cannot read existing Go file")
+	}
+	return Modified(data), nil
+}
+
+// orphaned removes the supplied file, which would contain no declarations, if
+// it exists and was generated. A file that was modified after it was generated
+// is reported as such instead, unless modified files are to be overwritten.
+func (o *options) orphaned(file string) error {
+	data, err := ioutil.ReadFile(file) // nolint:gosec
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "cannot read existing Go file")
+	}
+	if !bytes.Contains(data, []byte("// "+HeaderGenerated+"\n")) {
+		return nil
+	}
+	if o.Checksums && !o.Force && Modified(data) {
+		if o.Modified == nil {
+			return errModified{file: file}
+		}
+		o.Modified(file)
+		return nil
+	}
+	return errors.Wrap(o.Remove(file), "cannot remove Go file")
+}
+
+// newFile returns a new file of the supplied package, with the configured
+// import aliases and headers.
+func (o *options) newFile(p *packages.Package) *jen.File {
 	// NewFilePath creates a new File object by taking the full package path such as:
 	// 'github.com/org/repo/apis/resource/v1alpha1'
 	// File object created using the function ('NewFile') that takes only the package
@@ -91,37 +416,87 @@ func WriteMethods(p *packages.Package, ms method.Set, file string, wo ...WriteOp
 	// We need to create the File object by using NewFilePath (passing package path)
 	// so that we can communicate correctly with the library (jennifer).
 	f := jen.NewFilePath(p.PkgPath)
-	for path, alias := range opts.ImportAliases {
+	if o.ExternalTest {
+		f = jen.NewFilePathName(p.PkgPath+"_test", p.Name+"_test")
+	}
+	for path, alias := range o.ImportAliases {
 		f.ImportAlias(path, alias)
 	}
-	for _, hc := range opts.Headers {
+	for _, hc := range o.Headers {
 		if hc != "" {
 			f.HeaderComment(hc)
 		}
 	}
 	f.HeaderComment(HeaderGenerated)
+	return f
+}
 
-	for _, n := range p.Types.Scope().Names() {
-		o := p.Types.Scope().Lookup(n)
-		if !opts.Matches(o) {
+// recovering returns a file to which the supplied function added the
+// declarations of the supplied objects, recovering any panic that occurs, and
+// the objects whose declarations it added. The function is called once with
+// all objects. If it panics, the objects for which it panics when it is called
+// with only that object are omitted, and it is called once more with the
+// others. The recover function of the supplied options is called for each
+// omitted object, or for every object if the function still panics, in which
+// case the returned file is empty.
+func recovering(p *packages.Package, file string, fn func(f *jen.File, objects []types.Object), objects []types.Object, opts *options) (*jen.File, []types.Object, error) {
+	f := opts.newFile(p)
+	if recovered, _ := try(func() { fn(f, objects) }); recovered == nil {
+		return f, objects, nil
+	}
+
+	ok := make([]types.Object, 0, len(objects))
+	for _, o := range objects {
+		if err := opts.Context.Err(); err != nil {
+			return nil, nil, errors.Wrap(err, "generation was cancelled")
+		}
+		recovered, stack := try(func() { fn(opts.newFile(p), []types.Object{o}) })
+		if recovered != nil {
+			opts.Recover(file, o, recovered, stack)
 			continue
 		}
-		ms.Write(f, o, method.DefinedOutside(p.Fset, file))
+		ok = append(ok, o)
 	}
 
-	b := &bytes.Buffer{}
-	if err := f.Render(b); err != nil {
-		return errors.Wrap(err, "cannot render Go file")
+	// The function may panic only when it is called with several objects,
+	// for example because their declarations collide.
+	f = opts.newFile(p)
+	if recovered, stack := try(func() { fn(f, ok) }); recovered != nil {
+		for _, o := range ok {
+			opts.Recover(file, o, recovered, stack)
+		}
+		return opts.newFile(p), nil, nil
 	}
+	return f, ok, nil
+}
 
-	if ProducedNothing(b.Bytes()) {
-		return nil
-	}
+// try calls the supplied function, returning the value and stack trace of any
+// panic that occurs.
+func try(fn func()) (recovered interface{}, stack []byte) {
+	defer func() {
+		if recovered = recover(); recovered != nil {
+			stack = debug.Stack()
+		}
+	}()
+	fn()
+	return nil, nil
+}
 
+func writeFile(file string, data []byte) error {
+	// The file may be written to an output directory that doesn't exist yet.
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil { // nolint:gosec
+		return errors.Wrap(err, "cannot create directory")
+	}
 	// gosec would prefer this to be written as 0600, but we're comfortable with
 	// it being world readable.
-	return errors.Wrap(ioutil.WriteFile(file, b.Bytes(), 0644), "cannot write Go file") // nolint:gosec
+	return ioutil.WriteFile(file, data, 0644) // nolint:gosec
+}
 
+func removeFile(file string) error {
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
 
 // ProducedNothing returns true if the supplied data is either not a valid Go
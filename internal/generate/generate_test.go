@@ -0,0 +1,442 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/crossplane/crossplane-tools/internal/match"
+	"github.com/crossplane/crossplane-tools/internal/method"
+)
+
+const source = `
+package v1alpha1
+
+type Model struct {}
+`
+
+func loadPackage(t *testing.T, src string) *packages.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "model.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tp, err := (&types.Config{}).Check("example.org/v1alpha1", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &packages.Package{PkgPath: tp.Path(), Fset: fset, Types: tp, Syntax: []*ast.File{f}}
+}
+
+func TestWriteMethodsWithTransform(t *testing.T) {
+	errBoom := errors.New("boom")
+	upper := func(_ string, data []byte) ([]byte, error) {
+		return bytes.Replace(data, []byte(HeaderGenerated), bytes.ToUpper([]byte(HeaderGenerated)), 1), nil
+	}
+
+	type want struct {
+		data string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason     string
+		transforms []func(file string, data []byte) ([]byte, error)
+		want       want
+	}{
+		"NoTransforms": {
+			reason: "The rendered file should be written unchanged if no transforms are supplied.",
+			want: want{
+				data: `// Code generated by angryjet. DO NOT EDIT.
+
+package v1alpha1
+
+// Hello of this Model.
+func (m *Model) Hello() {}
+`,
+			},
+		},
+		"UppercaseMarker": {
+			reason:     "The transformed file should be written.",
+			transforms: []func(file string, data []byte) ([]byte, error){upper},
+			want: want{
+				data: `// CODE GENERATED BY ANGRYJET. DO NOT EDIT.
+
+package v1alpha1
+
+// Hello of this Model.
+func (m *Model) Hello() {}
+`,
+			},
+		},
+		"TransformError": {
+			reason: "Errors returned by a transform should be returned, and nothing should be written.",
+			transforms: []func(file string, data []byte) ([]byte, error){
+				func(_ string, _ []byte) ([]byte, error) { return nil, errBoom },
+				upper,
+			},
+			want: want{
+				err: errors.Wrap(errBoom, "cannot transform Go file"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ms := method.Set{
+				"Hello": func(f *jen.File, o types.Object) {
+					f.Commentf("Hello of this %s.", o.Name())
+					f.Func().Params(jen.Id("m").Op("*").Id(o.Name())).Id("Hello").Params().Block()
+				},
+			}
+
+			got := ""
+			wo := []WriteOption{WithWriter(func(_ string, data []byte) error {
+				got = string(data)
+				return nil
+			})}
+			for _, fn := range tc.transforms {
+				wo = append(wo, WithTransform(fn))
+			}
+
+			err := WriteMethods(loadPackage(t, source), ms, "zz_generated.hello.go", wo...)
+			if diff := cmp.Diff(tc.want.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nWriteMethods(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, got); diff != "" {
+				t.Errorf("\n%s\nWriteMethods(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWriteMethodsWithRecover(t *testing.T) {
+	const source = `
+package v1alpha1
+
+type Broken struct {}
+
+type Model struct {}
+`
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	type want struct {
+		data      string
+		recovered []string
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason string
+		ctx    context.Context
+		want   want
+	}{
+		"Recovered": {
+			reason: "Methods should be written for all objects other than those for which generation panics.",
+			ctx:    context.Background(),
+			want: want{
+				data: `// Code generated by angryjet. DO NOT EDIT.
+
+package v1alpha1
+
+// Hello of this Model.
+func (m *Model) Hello() {}
+`,
+				recovered: []string{"zz_generated.hello.go: Broken: boom"},
+			},
+		},
+		"Cancelled": {
+			reason: "Nothing should be written if the context is cancelled.",
+			ctx:    cancelled,
+			want: want{
+				err: errors.Wrap(context.Canceled, "generation was cancelled"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ms := method.Set{
+				"Hello": func(f *jen.File, o types.Object) {
+					if o.Name() == "Broken" {
+						panic("boom")
+					}
+					f.Commentf("Hello of this %s.", o.Name())
+					f.Func().Params(jen.Id("m").Op("*").Id(o.Name())).Id("Hello").Params().Block()
+				},
+			}
+
+			got := ""
+			var recovered []string
+			err := WriteMethods(loadPackage(t, source), ms, "zz_generated.hello.go",
+				WithContext(tc.ctx),
+				WithRecover(func(file string, o types.Object, r interface{}, stack []byte) {
+					if len(stack) == 0 {
+						t.Errorf("\n%s\nWriteMethods(...): recovered panic without a stack trace", tc.reason)
+					}
+					recovered = append(recovered, fmt.Sprintf("%s: %s: %v", file, o.Name(), r))
+				}),
+				WithWriter(func(_ string, data []byte) error {
+					got = string(data)
+					return nil
+				}),
+			)
+			if diff := cmp.Diff(tc.want.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nWriteMethods(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, got); diff != "" {
+				t.Errorf("\n%s\nWriteMethods(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.recovered, recovered); diff != "" {
+				t.Errorf("\n%s\nWriteMethods(...): -want recovered, +got recovered:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWriteFileWithRecover(t *testing.T) {
+	const source = `
+package v1alpha1
+
+type Gadget struct {}
+
+type Widget struct {}
+`
+	hello := func(f *jen.File, o types.Object) {
+		f.Func().Params(jen.Id("m").Op("*").Id(o.Name())).Id("Hello").Params().Block()
+	}
+
+	type want struct {
+		data      string
+		calls     int
+		recovered []string
+		generated []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		fn     func(f *jen.File, objects []types.Object)
+		want   want
+	}{
+		"NoPanic": {
+			reason: "Declarations should be generated once for all objects if generation doesn't panic.",
+			fn: func(f *jen.File, objects []types.Object) {
+				for _, o := range objects {
+					hello(f, o)
+				}
+			},
+			want: want{
+				data: `// Code generated by angryjet. DO NOT EDIT.
+
+package v1alpha1
+
+func (m *Gadget) Hello() {}
+func (m *Widget) Hello() {}
+`,
+				calls:     1,
+				generated: []string{"Gadget", "Widget"},
+			},
+		},
+		"PanicOfObject": {
+			reason: "Declarations should be generated for all objects other than those for which generation panics on their own.",
+			fn: func(f *jen.File, objects []types.Object) {
+				for _, o := range objects {
+					if o.Name() == "Gadget" {
+						panic("boom")
+					}
+					hello(f, o)
+				}
+			},
+			want: want{
+				data: `// Code generated by angryjet. DO NOT EDIT.
+
+package v1alpha1
+
+func (m *Widget) Hello() {}
+`,
+				calls:     4,
+				recovered: []string{"Gadget: boom"},
+				generated: []string{"Widget"},
+			},
+		},
+		"PanicOfObjects": {
+			reason: "All objects should be omitted if generation only panics when they are generated together.",
+			fn: func(f *jen.File, objects []types.Object) {
+				if len(objects) > 1 {
+					panic("collision")
+				}
+				for _, o := range objects {
+					hello(f, o)
+				}
+			},
+			want: want{
+				calls:     4,
+				recovered: []string{"Gadget: collision", "Widget: collision"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, calls := "", 0
+			var recovered, generated []string
+			err := WriteFile(loadPackage(t, source), "zz_generated.hello.go",
+				func(f *jen.File, objects []types.Object) {
+					calls++
+					tc.fn(f, objects)
+				},
+				WithRecover(func(_ string, o types.Object, r interface{}, _ []byte) {
+					recovered = append(recovered, fmt.Sprintf("%s: %v", o.Name(), r))
+				}),
+				WithGenerated(func(_ string, o types.Object) {
+					generated = append(generated, o.Name())
+				}),
+				WithWriter(func(_ string, data []byte) error {
+					got = string(data)
+					return nil
+				}),
+			)
+			if err != nil {
+				t.Errorf("\n%s\nWriteFile(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.data, got); diff != "" {
+				t.Errorf("\n%s\nWriteFile(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("\n%s\nWriteFile(...): -want calls, +got calls:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.recovered, recovered); diff != "" {
+				t.Errorf("\n%s\nWriteFile(...): -want recovered, +got recovered:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.generated, generated); diff != "" {
+				t.Errorf("\n%s\nWriteFile(...): -want generated, +got generated:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWriteMethodsOrphaned(t *testing.T) {
+	handwritten := "package v1alpha1\n\nfunc (m *Model) Hello() {}\n"
+	modified := strings.Replace(string(AddChecksum([]byte(generated))), "Hello() {}", "Hello() { panic(\"hi\") }", 1)
+
+	type want struct {
+		exists   bool
+		modified string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason   string
+		existing string
+		wo       []WriteOption
+		want     want
+	}{
+		"NoExistingFile": {
+			reason: "Nothing should be written or removed if no file exists.",
+		},
+		"Generated": {
+			reason:   "A generated file that would now contain no declarations should be removed.",
+			existing: generated,
+		},
+		"Handwritten": {
+			reason:   "A file that was not generated should never be removed.",
+			existing: handwritten,
+			want: want{
+				exists: true,
+			},
+		},
+		"Modified": {
+			reason:   "A generated file that was modified after it was generated should be reported rather than removed.",
+			existing: modified,
+			wo:       []WriteOption{WithChecksums()},
+			want: want{
+				exists:   true,
+				modified: "zz_generated.hello.go",
+			},
+		},
+		"ModifiedWithoutChecksums": {
+			reason:   "A modified generated file should be removed if checksums are not verified.",
+			existing: modified,
+		},
+		"ModifiedForced": {
+			reason:   "A modified generated file should be removed if modified files are to be overwritten.",
+			existing: modified,
+			wo:       []WriteOption{WithChecksums(), WithOverwriteModified()},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "zz_generated.hello.go")
+			if tc.existing != "" {
+				if err := os.WriteFile(file, []byte(tc.existing), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got := ""
+			wo := append([]WriteOption{
+				WithMatcher(match.Func("no object", func(_ types.Object) bool { return false })),
+				WithModified(func(f string) { got = filepath.Base(f) }),
+				WithWriter(func(_ string, _ []byte) error {
+					t.Errorf("\n%s\nWriteMethods(...): a file that would contain no declarations should not be written", tc.reason)
+					return nil
+				}),
+			}, tc.wo...)
+			ms := method.Set{"Hello": func(_ *jen.File, _ types.Object) {}}
+			err := WriteMethods(loadPackage(t, source), ms, file, wo...)
+			if diff := cmp.Diff(tc.want.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nWriteMethods(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			_, statErr := os.Stat(file)
+			if diff := cmp.Diff(tc.want.exists, statErr == nil); diff != "" {
+				t.Errorf("\n%s\nWriteMethods(...): -want file exists, +got file exists:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.modified, got); diff != "" {
+				t.Errorf("\n%s\nWriteMethods(...): -want modified, +got modified:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// cmpErrors compares errors by their messages.
+func cmpErrors() cmp.Option {
+	return cmp.Comparer(func(a, b error) bool {
+		if a == nil || b == nil {
+			return a == nil && b == nil
+		}
+		return a.Error() == b.Error()
+	})
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// UpdateMethods returns the supplied existing Go file with the methods of the
+// supplied names replaced by those of the supplied generated Go file, which
+// have the same receiver type. Existing methods of those names that were not
+// generated are removed, and generated methods that did not exist are added
+// to the end of the file. All other declarations of the existing file are
+// kept as they are. Imports that the generated methods use are added, and
+// imports that are no longer used are removed.
+func UpdateMethods(existing, generated []byte, names ...string) ([]byte, error) {
+	update := make(map[string]bool, len(names))
+	for _, n := range names {
+		update[n] = true
+	}
+
+	gfset := token.NewFileSet()
+	gf, err := parser.ParseFile(gfset, "generated.go", generated, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse generated Go file")
+	}
+	efset := token.NewFileSet()
+	ef, err := parser.ParseFile(efset, "existing.go", existing, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse existing Go file")
+	}
+
+	// The generated methods, by receiver type and name, in the order they
+	// were generated, and the generated variables the updated methods may
+	// refer to that don't exist yet.
+	keys := make([]string, 0)
+	methods := map[string][]byte{}
+	vars := make([][]byte, 0)
+	existingVars := declaredVars(ef)
+	for _, d := range gf.Decls {
+		if gd, ok := d.(*ast.GenDecl); ok && gd.Tok == token.VAR && !declares(gd, existingVars) {
+			start, end := span(gfset, d)
+			vars = append(vars, generated[start:end])
+			continue
+		}
+		if k, ok := methodKey(d, update); ok {
+			start, end := span(gfset, d)
+			keys = append(keys, k)
+			methods[k] = generated[start:end]
+		}
+	}
+
+	b := &bytes.Buffer{}
+	last := 0
+	for _, d := range ef.Decls {
+		k, ok := methodKey(d, update)
+		if !ok {
+			continue
+		}
+		start, end := span(efset, d)
+		b.Write(existing[last:start])
+		b.Write(methods[k])
+		delete(methods, k)
+		last = end
+	}
+	b.Write(existing[last:])
+	for _, v := range vars {
+		b.WriteString("\n\n")
+		b.Write(v)
+		b.WriteString("\n")
+	}
+	for _, k := range keys {
+		if m, ok := methods[k]; ok {
+			b.WriteString("\n\n")
+			b.Write(m)
+			b.WriteString("\n")
+		}
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "updated.go", b.Bytes(), parser.ParseComments)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse updated Go file")
+	}
+	for _, i := range gf.Imports {
+		name, path := importName(i)
+		astutil.AddNamedImport(fset, f, name, path)
+	}
+	for _, i := range append([]*ast.ImportSpec{}, f.Imports...) {
+		name, path := importName(i)
+		if name == "_" || name == "." || uses(f, name, path) {
+			continue
+		}
+		astutil.DeleteNamedImport(fset, f, name, path)
+	}
+
+	out := &bytes.Buffer{}
+	if err := format.Node(out, fset, f); err != nil {
+		return nil, errors.Wrap(err, "cannot format updated Go file")
+	}
+	return out.Bytes(), nil
+}
+
+// methodKey returns the receiver type and name of the supplied declaration, if
+// it is a method with one of the supplied names.
+func methodKey(d ast.Decl, names map[string]bool) (string, bool) {
+	fd, ok := d.(*ast.FuncDecl)
+	if !ok || fd.Recv == nil || len(fd.Recv.List) != 1 || !names[fd.Name.Name] {
+		return "", false
+	}
+	return types.ExprString(fd.Recv.List[0].Type) + "." + fd.Name.Name, true
+}
+
+// declaredVars returns the names of the package-level variables the supplied
+// file declares, other than the blank identifier.
+func declaredVars(f *ast.File) map[string]bool {
+	names := map[string]bool{}
+	for _, d := range f.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok || gd.Tok != token.VAR {
+			continue
+		}
+		for _, s := range gd.Specs {
+			for _, n := range s.(*ast.ValueSpec).Names {
+				if n.Name != "_" {
+					names[n.Name] = true
+				}
+			}
+		}
+	}
+	return names
+}
+
+// declares returns true if the supplied variable declaration declares any of
+// the supplied names, or nothing but the blank identifier.
+func declares(gd *ast.GenDecl, names map[string]bool) bool {
+	blank := true
+	for _, s := range gd.Specs {
+		for _, n := range s.(*ast.ValueSpec).Names {
+			if n.Name == "_" {
+				continue
+			}
+			if names[n.Name] {
+				return true
+			}
+			blank = false
+		}
+	}
+	return blank
+}
+
+// span returns the offsets of the start and end of the supplied declaration,
+// including its doc comment.
+func span(fset *token.FileSet, d ast.Decl) (int, int) {
+	start := d.Pos()
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		if d.Doc != nil {
+			start = d.Doc.Pos()
+		}
+	case *ast.GenDecl:
+		if d.Doc != nil {
+			start = d.Doc.Pos()
+		}
+	}
+	return fset.Position(start).Offset, fset.Position(d.End()).Offset
+}
+
+// uses returns true if the supplied file refers to the package imported with
+// the supplied name and path. The name of a package imported without one is
+// assumed to be the last element of its path.
+func uses(f *ast.File, name, path string) bool {
+	if name == "" {
+		name = path[strings.LastIndex(path, "/")+1:]
+	}
+	used := false
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Name == name && id.Obj == nil {
+				used = true
+			}
+		}
+		return !used
+	})
+	return used
+}
+
+// importName returns the name and path of the supplied import. The name is
+// empty if the import is not named.
+func importName(i *ast.ImportSpec) (string, string) {
+	path, _ := strconv.Unquote(i.Path.Value)
+	if i.Name == nil {
+		return "", path
+	}
+	return i.Name.Name, path
+}
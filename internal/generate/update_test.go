@@ -0,0 +1,271 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-tools/internal/method"
+)
+
+func TestUpdateMethods(t *testing.T) {
+	type args struct {
+		existing  string
+		generated string
+	}
+	type want struct {
+		data string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ReplaceMethod": {
+			reason: "Only the updated method should be replaced, and the imports it no longer uses should be replaced by those it does.",
+			args: args{
+				existing: `// Code generated by angryjet. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	old "example.org/old"
+	xpv1 "example.org/xpv1"
+)
+
+// GetCondition of this Model.
+func (mg *Model) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context) error {
+	return old.Resolve(ctx)
+}
+
+// SetConditions of this Model.
+func (mg *Model) SetConditions(c ...xpv1.Condition) {
+	mg.Status.SetConditions(c...)
+}
+`,
+				generated: `// Code generated by angryjet. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	reference "example.org/reference"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context) error {
+	return reference.Resolve(ctx)
+}
+`,
+			},
+			want: want{
+				data: `// Code generated by angryjet. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	reference "example.org/reference"
+	xpv1 "example.org/xpv1"
+)
+
+// GetCondition of this Model.
+func (mg *Model) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context) error {
+	return reference.Resolve(ctx)
+}
+
+// SetConditions of this Model.
+func (mg *Model) SetConditions(c ...xpv1.Condition) {
+	mg.Status.SetConditions(c...)
+}
+`,
+			},
+		},
+		"AddAndRemoveMethods": {
+			reason: "Generated methods that did not exist should be added to the end of the file, and existing methods that were not generated should be removed.",
+			args: args{
+				existing: `package v1alpha1
+
+import "context"
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context) error {
+	return nil
+}
+
+// Hello of this Model.
+func (mg *Model) Hello() {}
+`,
+				generated: `package v1alpha1
+
+import "context"
+
+// ResolveReferences of this Other.
+func (mg *Other) ResolveReferences(ctx context.Context) error {
+	return nil
+}
+`,
+			},
+			want: want{
+				data: `package v1alpha1
+
+import "context"
+
+// Hello of this Model.
+func (mg *Model) Hello() {}
+
+// ResolveReferences of this Other.
+func (mg *Other) ResolveReferences(ctx context.Context) error {
+	return nil
+}
+`,
+			},
+		},
+		"AddVariables": {
+			reason: "Generated variables that the existing file does not declare should be added before the generated methods, and those it does should be kept as they are.",
+			args: args{
+				existing: `package v1alpha1
+
+import "regexp"
+
+var kept = regexp.MustCompile("^a$")
+`,
+				generated: `package v1alpha1
+
+import "regexp"
+
+var kept = regexp.MustCompile("^b$")
+
+var added = regexp.MustCompile("^c$")
+
+var _ = added
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences() bool {
+	return added.MatchString("c")
+}
+`,
+			},
+			want: want{
+				data: `package v1alpha1
+
+import "regexp"
+
+var kept = regexp.MustCompile("^a$")
+
+var added = regexp.MustCompile("^c$")
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences() bool {
+	return added.MatchString("c")
+}
+`,
+			},
+		},
+		"InvalidExisting": {
+			reason: "An existing file that isn't valid Go should return an error.",
+			args: args{
+				existing:  "package",
+				generated: "package v1alpha1\n",
+			},
+			want: want{
+				err: errors.Wrap(errors.New("existing.go:1:8: expected 'IDENT', found 'EOF'"), "cannot parse existing Go file"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := UpdateMethods([]byte(tc.args.existing), []byte(tc.args.generated), "ResolveReferences")
+			if diff := cmp.Diff(tc.want.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nUpdateMethods(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, string(got)); diff != "" {
+				t.Errorf("\n%s\nUpdateMethods(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWriteMethodsWithUpdate(t *testing.T) {
+	ms := func(name string) method.Set {
+		return method.Set{
+			name: func(f *jen.File, o types.Object) {
+				f.Commentf("%s of this %s.", name, o.Name())
+				f.Func().Params(jen.Id("m").Op("*").Id(o.Name())).Id(name).Params().Block()
+			},
+		}
+	}
+	write := func(t *testing.T, file string, ms method.Set, wo ...WriteOption) string {
+		t.Helper()
+		got := ""
+		wo = append(wo, WithWriter(func(_ string, data []byte) error {
+			got = string(data)
+			return os.WriteFile(file, data, 0600)
+		}))
+		if err := WriteMethods(loadPackage(t, source), ms, file, wo...); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	file := filepath.Join(t.TempDir(), "zz_generated.hello.go")
+	want := `// Code generated by angryjet. DO NOT EDIT.
+
+package v1alpha1
+
+// Hello of this Model.
+func (m *Model) Hello() {}
+`
+	if diff := cmp.Diff(want, write(t, file, ms("Hello"), WithUpdateMethods("Goodbye"))); diff != "" {
+		t.Errorf("\nThe whole file should be written if it doesn't exist.\nWriteMethods(...): -want, +got:\n%s", diff)
+	}
+
+	want = `// Code generated by angryjet. DO NOT EDIT.
+
+package v1alpha1
+
+// Hello of this Model.
+func (m *Model) Hello() {}
+
+// Goodbye of this Model.
+func (m *Model) Goodbye() {}
+`
+	if diff := cmp.Diff(want, write(t, file, ms("Goodbye"), WithUpdateMethods("Goodbye"))); diff != "" {
+		t.Errorf("\nOnly the updated methods of an existing file should be written.\nWriteMethods(...): -want, +got:\n%s", diff)
+	}
+}
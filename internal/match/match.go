@@ -18,20 +18,45 @@ limitations under the License.
 package match
 
 import (
+	"fmt"
 	"go/types"
+	"regexp"
+	"strings"
 
-	"github.com/crossplane/crossplane-tools/internal/comments"
 	"github.com/crossplane/crossplane-tools/internal/fields"
+	"github.com/crossplane/crossplane-tools/pkg/comments"
 )
 
-// An Object matcher is a function that returns true if the supplied object
-// matches.
-type Object func(o types.Object) bool
+// A Matcher determines whether an Object matches, for example whether methods
+// should be generated for it.
+type Matcher interface {
+	// Match returns true if the supplied Object matches.
+	Match(o types.Object) bool
 
-// Managed returns an Object matcher that returns true if the supplied Object is
-// a Crossplane managed resource.
-func Managed() Object {
-	return func(o types.Object) bool {
+	// Describe returns a human readable description of the Objects that
+	// match, for use in diagnostics.
+	Describe() string
+}
+
+type matcher struct {
+	match       func(o types.Object) bool
+	description string
+}
+
+func (m matcher) Match(o types.Object) bool { return m.match(o) }
+
+func (m matcher) Describe() string { return m.description }
+
+// Func returns a Matcher that returns true if the supplied function returns
+// true. The Matcher is described by the supplied description.
+func Func(description string, fn func(o types.Object) bool) Matcher {
+	return matcher{match: fn, description: description}
+}
+
+// Managed returns a Matcher that returns true if the supplied Object is a
+// Crossplane managed resource.
+func Managed() Matcher {
+	return Func("managed resource", func(o types.Object) bool {
 		return fields.Has(o,
 			fields.IsTypeMeta().And(fields.IsEmbedded()),
 			fields.IsObjectMeta().And(fields.IsEmbedded()),
@@ -42,33 +67,55 @@ func Managed() Object {
 				fields.IsResourceStatus().And(fields.IsEmbedded()),
 			)),
 		)
-	}
+	})
 }
 
-// ManagedList returns an Object matcher that returns true if the supplied
-// Object is a list of Crossplane managed resource.
-func ManagedList() Object {
-	return func(o types.Object) bool {
+// ManagedList returns a Matcher that returns true if the supplied Object is a
+// list of Crossplane managed resource.
+func ManagedList() Matcher {
+	return Func("managed resource list", func(o types.Object) bool {
 		return fields.Has(o,
 			fields.IsTypeMeta().And(fields.IsEmbedded()),
-			fields.IsItems().And(fields.IsSlice()).And(fields.HasFieldThat(
-				fields.IsTypeMeta().And(fields.IsEmbedded()),
-				fields.IsObjectMeta().And(fields.IsEmbedded()),
-				fields.IsSpec().And(fields.HasFieldThat(
-					fields.IsResourceSpec().And(fields.IsEmbedded()),
-				)),
-				fields.IsStatus().And(fields.HasFieldThat(
-					fields.IsResourceStatus().And(fields.IsEmbedded()),
-				)),
-			)),
+			isManagedItems(),
 		)
-	}
+	})
+}
+
+// RootManagedList returns a Matcher that returns true if the supplied Object is
+// marked as a Kubernetes object root using KubebuilderObjectRootMarker and has
+// a slice of managed resource items, but no object metadata of its own. Unlike
+// ManagedList it matches lists that embed their type metadata in a struct they
+// share with other lists, while root managed resources don't match. Comment
+// markers are read from the supplied Comments.
+func RootManagedList(c comments.Comments) Matcher {
+	root := ObjectRoot(c)
+	return Func("root managed resource list", func(o types.Object) bool {
+		if !root.Match(o) || fields.Has(o, fields.IsObjectMeta()) {
+			return false
+		}
+		return fields.Has(o, isManagedItems())
+	})
 }
 
-// ProviderConfig returns an Object matcher that returns true if the supplied
-// Object is a Crossplane ProviderConfig.
-func ProviderConfig() Object {
-	return func(o types.Object) bool {
+// isManagedItems returns a field Matcher that returns true if the supplied
+// field is the slice of managed resource items of a list.
+func isManagedItems() fields.Matcher {
+	return fields.IsItems().And(fields.IsSlice()).And(fields.HasFieldThat(
+		fields.IsTypeMeta().And(fields.IsEmbedded()),
+		fields.IsObjectMeta().And(fields.IsEmbedded()),
+		fields.IsSpec().And(fields.HasFieldThat(
+			fields.IsResourceSpec().And(fields.IsEmbedded()),
+		)),
+		fields.IsStatus().And(fields.HasFieldThat(
+			fields.IsResourceStatus().And(fields.IsEmbedded()),
+		)),
+	))
+}
+
+// ProviderConfig returns a Matcher that returns true if the supplied Object is
+// a Crossplane ProviderConfig.
+func ProviderConfig() Matcher {
+	return Func("provider config", func(o types.Object) bool {
 		return fields.Has(o,
 			fields.IsTypeMeta().And(fields.IsEmbedded()),
 			fields.IsObjectMeta().And(fields.IsEmbedded()),
@@ -77,25 +124,25 @@ func ProviderConfig() Object {
 				fields.IsProviderConfigStatus().And(fields.IsEmbedded()),
 			)),
 		)
-	}
+	})
 }
 
-// ProviderConfigUsage returns an Object matcher that returns true if the supplied
+// ProviderConfigUsage returns a Matcher that returns true if the supplied
 // Object is a Crossplane ProviderConfigUsage.
-func ProviderConfigUsage() Object {
-	return func(o types.Object) bool {
+func ProviderConfigUsage() Matcher {
+	return Func("provider config usage", func(o types.Object) bool {
 		return fields.Has(o,
 			fields.IsTypeMeta().And(fields.IsEmbedded()),
 			fields.IsObjectMeta().And(fields.IsEmbedded()),
 			fields.IsProviderConfigUsage().And(fields.IsEmbedded()),
 		)
-	}
+	})
 }
 
-// ProviderConfigUsageList returns an Object matcher that returns true if the
-// supplied Object is a list of Crossplane provider config usages.
-func ProviderConfigUsageList() Object {
-	return func(o types.Object) bool {
+// ProviderConfigUsageList returns a Matcher that returns true if the supplied
+// Object is a list of Crossplane provider config usages.
+func ProviderConfigUsageList() Matcher {
+	return Func("provider config usage list", func(o types.Object) bool {
 		return fields.Has(o,
 			fields.IsTypeMeta().And(fields.IsEmbedded()),
 			fields.IsItems().And(fields.IsSlice()).And(fields.HasFieldThat(
@@ -104,14 +151,76 @@ func ProviderConfigUsageList() Object {
 				fields.IsProviderConfigUsage().And(fields.IsEmbedded()),
 			)),
 		)
-	}
+	})
 }
 
-// HasMarker returns an Object matcher that returns true if the supplied Object
-// has a comment marker k with the value v. Comment markers are read from the
-// supplied Comments.
-func HasMarker(c comments.Comments, k, v string) Object {
-	return func(o types.Object) bool {
+// KubebuilderScopeMarker is the kubebuilder comment marker that specifies
+// whether a resource is cluster or namespace scoped, for example
+// +kubebuilder:resource:scope=Namespaced,categories=crossplane.
+const KubebuilderScopeMarker = "kubebuilder:resource:scope"
+
+// Namespaced returns a Matcher that returns true if the supplied Object is
+// marked as namespace scoped using KubebuilderScopeMarker. Comment markers are
+// read from the supplied Comments.
+func Namespaced(c comments.Comments) Matcher {
+	return Func("namespace scoped", func(o types.Object) bool {
+		for _, comment := range []string{c.For(o), c.Before(o)} {
+			for _, val := range comments.ParseMarkers(comment)[KubebuilderScopeMarker] {
+				if strings.SplitN(val, ",", 2)[0] == "Namespaced" {
+					return true
+				}
+			}
+		}
+		return false
+	})
+}
+
+// KubebuilderObjectRootMarker is the kubebuilder comment marker of the types
+// that are Kubernetes objects, including lists of them, for example
+// +kubebuilder:object:root=true.
+const KubebuilderObjectRootMarker = "kubebuilder:object:root"
+
+// ObjectRoot returns a Matcher that returns true if the supplied Object is
+// marked as a Kubernetes object root using KubebuilderObjectRootMarker, with
+// the value true or none. Comment markers are read from the supplied Comments.
+func ObjectRoot(c comments.Comments) Matcher {
+	return Func("object root", func(o types.Object) bool {
+		for _, comment := range []string{c.For(o), c.Before(o)} {
+			for _, val := range comments.ParseMarkers(comment)[KubebuilderObjectRootMarker] {
+				if val == "true" || val == "" {
+					return true
+				}
+			}
+		}
+		return false
+	})
+}
+
+// KubebuilderStorageVersionMarker is the kubebuilder comment marker of the
+// version of a kind that is stored, which is the hub that other versions are
+// converted to.
+const KubebuilderStorageVersionMarker = "kubebuilder:storageversion"
+
+// StorageVersion returns a Matcher that returns true if the supplied Object is
+// marked as the storage version of its kind using
+// KubebuilderStorageVersionMarker. Comment markers are read from the supplied
+// Comments.
+func StorageVersion(c comments.Comments) Matcher {
+	return Func("storage version", func(o types.Object) bool {
+		for _, comment := range []string{c.For(o), c.Before(o)} {
+			if _, ok := comments.ParseMarkers(comment)[KubebuilderStorageVersionMarker]; ok {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// HasMarker returns a Matcher that returns true if the supplied Object has a
+// comment marker k with the value v. Comment markers are read from the supplied
+// Comments.
+func HasMarker(c comments.Comments, k, v string) Matcher {
+	return Func(fmt.Sprintf("has marker +%s=%s", k, v), func(o types.Object) bool {
 		for _, val := range comments.ParseMarkers(c.For(o))[k] {
 			if val == v {
 				return true
@@ -125,40 +234,66 @@ func HasMarker(c comments.Comments, k, v string) Object {
 		}
 
 		return false
-	}
+	})
 }
 
-// DoesNotHaveMarker returns and Object matcher that returns true if the
-// supplied Object does not have a comment marker k with the value v. Comment
-// marker are read from the supplied Comments.
-func DoesNotHaveMarker(c comments.Comments, k, v string) Object {
-	return func(o types.Object) bool {
-		return !HasMarker(c, k, v)(o)
-	}
+// DoesNotHaveMarker returns a Matcher that returns true if the supplied Object
+// does not have a comment marker k with the value v. Comment markers are read
+// from the supplied Comments.
+func DoesNotHaveMarker(c comments.Comments, k, v string) Matcher {
+	return Not(HasMarker(c, k, v))
+}
+
+// NameMatches returns a Matcher that returns true if the name of the supplied
+// Object matches the supplied regular expression.
+func NameMatches(re *regexp.Regexp) Matcher {
+	return Func(fmt.Sprintf("name matches %q", re.String()), func(o types.Object) bool {
+		return re.MatchString(o.Name())
+	})
 }
 
-// AllOf returns an Object matcher that returns true if all of the supplied
-// Object matchers return true.
-func AllOf(match ...Object) Object {
-	return func(o types.Object) bool {
-		for _, fn := range match {
-			if !fn(o) {
+// And returns a Matcher that returns true if all of the supplied Matchers
+// return true.
+func And(m ...Matcher) Matcher {
+	return Func(describe("and", m), func(o types.Object) bool {
+		for _, mm := range m {
+			if !mm.Match(o) {
 				return false
 			}
 		}
 		return true
-	}
+	})
 }
 
-// AnyOf returns an Object matcher that returns true if any of the supplied
-// Object matchers return true.
-func AnyOf(match ...Object) Object {
-	return func(o types.Object) bool {
-		for _, fn := range match {
-			if fn(o) {
+// Or returns a Matcher that returns true if any of the supplied Matchers
+// return true.
+func Or(m ...Matcher) Matcher {
+	return Func(describe("or", m), func(o types.Object) bool {
+		for _, mm := range m {
+			if mm.Match(o) {
 				return true
 			}
 		}
 		return false
+	})
+}
+
+// Not returns a Matcher that returns true if the supplied Matcher returns
+// false.
+func Not(m Matcher) Matcher {
+	return Func("not "+m.Describe(), func(o types.Object) bool {
+		return !m.Match(o)
+	})
+}
+
+// describe the supplied Matchers joined by the supplied operator.
+func describe(op string, m []Matcher) string {
+	if len(m) == 1 {
+		return m[0].Describe()
+	}
+	d := make([]string, len(m))
+	for i := range m {
+		d[i] = m[i].Describe()
 	}
+	return "(" + strings.Join(d, " "+op+" ") + ")"
 }
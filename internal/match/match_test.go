@@ -0,0 +1,297 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package match
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/crossplane/crossplane-tools/pkg/comments"
+)
+
+const (
+	metaSource = `
+package v1
+
+type TypeMeta struct {}
+type ObjectMeta struct {}
+type ListMeta struct {}
+`
+
+	commonSource = `
+package v1
+
+type ResourceSpec struct {}
+type ResourceStatus struct {}
+type ProviderConfigStatus struct {}
+type ProviderConfigUsage struct {}
+`
+
+	source = `
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	forkv1 "example.org/internal/thirdparty/crossplane-runtime/apis/common/v1"
+)
+
+type ModelSpec struct {
+	xpv1.ResourceSpec
+}
+
+type ModelStatus struct {
+	xpv1.ResourceStatus
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,categories=crossplane
+// +kubebuilder:storageversion
+type Model struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   ModelSpec
+	Status ModelStatus
+}
+
+type ForkedModelSpec struct {
+	forkv1.ResourceSpec
+}
+
+type ForkedModelStatus struct {
+	forkv1.ResourceStatus
+}
+
+type ForkedModel struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   ForkedModelSpec
+	Status ForkedModelStatus
+}
+
+type ModelList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+
+	Items []Model
+}
+
+type ListMetadata struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+}
+
+// +kubebuilder:object:root=true
+type SharedModelList struct {
+	ListMetadata
+
+	Items []Model
+}
+
+type ModelBatch struct {
+	Items []Model
+}
+
+// +crossplane:generate:methods=false
+type LegacyModel struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   ModelSpec
+	Status ModelStatus
+}
+
+type ProviderConfigSpec struct {}
+
+type ProviderConfigStatus struct {
+	xpv1.ProviderConfigStatus
+}
+
+type ProviderConfig struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   ProviderConfigSpec
+	Status ProviderConfigStatus
+}
+
+type ProviderConfigUsage struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	xpv1.ProviderConfigUsage
+}
+
+type ProviderConfigUsageList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+
+	Items []ProviderConfigUsage
+}
+
+type Other struct {}
+`
+)
+
+type importer map[string]*types.Package
+
+func (i importer) Import(path string) (*types.Package, error) { return i[path], nil }
+
+// loadPackage type-checks the fixture types, returning them with their
+// comments.
+func loadPackage(t *testing.T) (*types.Package, comments.Comments) {
+	t.Helper()
+	fset := token.NewFileSet()
+	check := func(path, src string, imports importer) (*types.Package, *ast.File) {
+		f, err := parser.ParseFile(fset, path+"/types.go", src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tp, err := (&types.Config{Importer: imports}).Check(path, fset, []*ast.File{f}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tp, f
+	}
+	meta, _ := check("k8s.io/apimachinery/pkg/apis/meta/v1", metaSource, nil)
+	common, _ := check("github.com/crossplane/crossplane-runtime/apis/common/v1", commonSource, nil)
+	fork, _ := check("example.org/internal/thirdparty/crossplane-runtime/apis/common/v1", commonSource, nil)
+	tp, f := check("example.org/v1alpha1", source, importer{meta.Path(): meta, common.Path(): common, fork.Path(): fork})
+	return tp, comments.In(&packages.Package{Fset: fset, Syntax: []*ast.File{f}})
+}
+
+func TestMatchers(t *testing.T) {
+	tp, c := loadPackage(t)
+
+	cases := map[string]struct {
+		reason      string
+		m           Matcher
+		want        []string
+		description string
+	}{
+		"Managed": {
+			reason:      "Types that embed a resource spec and status should match, including those of a fork of crossplane-runtime with another path.",
+			m:           Managed(),
+			want:        []string{"ForkedModel", "LegacyModel", "Model"},
+			description: "managed resource",
+		},
+		"ManagedList": {
+			reason:      "Types with a slice of managed resource items should match.",
+			m:           ManagedList(),
+			want:        []string{"ModelList"},
+			description: "managed resource list",
+		},
+		"RootManagedList": {
+			reason:      "Types marked as object roots with a slice of managed resource items should match, but not the managed resources or other types with items.",
+			m:           RootManagedList(c),
+			want:        []string{"SharedModelList"},
+			description: "root managed resource list",
+		},
+		"ObjectRoot": {
+			reason:      "Types with a kubebuilder object root marker should match.",
+			m:           ObjectRoot(c),
+			want:        []string{"Model", "SharedModelList"},
+			description: "object root",
+		},
+		"ProviderConfig": {
+			reason:      "Types that embed a provider config status should match.",
+			m:           ProviderConfig(),
+			want:        []string{"ProviderConfig"},
+			description: "provider config",
+		},
+		"ProviderConfigUsage": {
+			reason:      "Types that embed a provider config usage should match.",
+			m:           ProviderConfigUsage(),
+			want:        []string{"ProviderConfigUsage"},
+			description: "provider config usage",
+		},
+		"ProviderConfigUsageList": {
+			reason:      "Types with a slice of provider config usage items should match.",
+			m:           ProviderConfigUsageList(),
+			want:        []string{"ProviderConfigUsageList"},
+			description: "provider config usage list",
+		},
+		"Namespaced": {
+			reason:      "Types with a namespaced kubebuilder scope marker should match.",
+			m:           Namespaced(c),
+			want:        []string{"Model"},
+			description: "namespace scoped",
+		},
+		"StorageVersion": {
+			reason:      "Types with a kubebuilder storage version marker should match.",
+			m:           StorageVersion(c),
+			want:        []string{"Model"},
+			description: "storage version",
+		},
+		"HasMarker": {
+			reason:      "Types with the marker should match.",
+			m:           HasMarker(c, "crossplane:generate:methods", "false"),
+			want:        []string{"LegacyModel"},
+			description: "has marker +crossplane:generate:methods=false",
+		},
+		"NameMatches": {
+			reason:      "Types whose names match the regular expression should match.",
+			m:           NameMatches(regexp.MustCompile("^Provider.*List$")),
+			want:        []string{"ProviderConfigUsageList"},
+			description: `name matches "^Provider.*List$"`,
+		},
+		"And": {
+			reason:      "Types that match all matchers should match.",
+			m:           And(Managed(), DoesNotHaveMarker(c, "crossplane:generate:methods", "false")),
+			want:        []string{"ForkedModel", "Model"},
+			description: "(managed resource and not has marker +crossplane:generate:methods=false)",
+		},
+		"Or": {
+			reason:      "Types that match any matcher should match.",
+			m:           Or(ManagedList(), ProviderConfigUsageList()),
+			want:        []string{"ModelList", "ProviderConfigUsageList"},
+			description: "(managed resource list or provider config usage list)",
+		},
+		"Not": {
+			reason:      "Types that don't match the matcher should match.",
+			m:           And(Not(NameMatches(regexp.MustCompile("(Spec|Status)$"))), Not(Or(Managed(), ManagedList(), ProviderConfig(), ProviderConfigUsage(), ProviderConfigUsageList()))),
+			want:        []string{"ListMetadata", "ModelBatch", "Other", "SharedModelList"},
+			description: `(not name matches "(Spec|Status)$" and not (managed resource or managed resource list or provider config or provider config usage or provider config usage list))`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, n := range tp.Scope().Names() {
+				if tc.m.Match(tp.Scope().Lookup(n)) {
+					got = append(got, n)
+				}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nMatch(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.description, tc.m.Describe()); diff != "" {
+				t.Errorf("\n%s\nDescribe(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
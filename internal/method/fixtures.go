@@ -0,0 +1,474 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package method
+
+import (
+	"fmt"
+	"go/types"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/pkg/errors"
+
+	xptypes "github.com/crossplane/crossplane-tools/internal/types"
+	"github.com/crossplane/crossplane-tools/pkg/comments"
+)
+
+// Kubebuilder comment markers that constrain the value of a field, which
+// fixtures set required fields to satisfy.
+const (
+	KubebuilderEnumMarker      = "kubebuilder:validation:Enum"
+	KubebuilderMinimumMarker   = "kubebuilder:validation:Minimum"
+	KubebuilderMinLengthMarker = "kubebuilder:validation:MinLength"
+	KubebuilderPatternMarker   = "kubebuilder:validation:Pattern"
+	KubebuilderFormatMarker    = "kubebuilder:validation:Format"
+)
+
+// FixtureValue is the value that fixtures set required strings, and the keys of
+// required maps, to unless their markers constrain them otherwise.
+const FixtureValue = "fixture"
+
+// A FixturesOption configures the fixtures written by the function returned by
+// NewFixtures.
+type FixturesOption func(o *fixturesOptions)
+
+type fixturesOptions struct {
+	External bool
+}
+
+// WithExternalFixtures specifies that fixtures are written to the external test
+// package of the managed resources, for example v1alpha1_test, which can only
+// refer to their exported types and fields.
+func WithExternalFixtures() FixturesOption {
+	return func(o *fixturesOptions) {
+		o.External = true
+	}
+}
+
+// NewFixtures returns a function that writes, for each of the supplied managed
+// resources, a New<Kind>Fixture function that returns one whose required spec
+// fields are set to valid values, and a With<Kind><Field> option for each field
+// of its spec.forProvider, or of its spec if it has none, that the fixture
+// function may be supplied to set it. Fields whose types can't be referred to
+// have no option. Required fields are those found by traversing the managed
+// resource that are marked as required, or that are not marked as optional
+// and not omitted from JSON when empty. Fixtures only refer to packages that
+// the managed resources already import, so they may be written to a test file
+// of their package without causing an import cycle. A managed resource with a
+// required field that can't be set to a valid value, for example one of an
+// interface type, causes a panic that names the field.
+func NewFixtures(traverser *xptypes.Traverser, o ...FixturesOption) func(f *jen.File, objects []types.Object) {
+	opts := &fixturesOptions{}
+	for _, fn := range o {
+		fn(opts)
+	}
+	return func(f *jen.File, objects []types.Object) {
+		for _, o := range objects {
+			n, ok := o.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			fx, err := newFixture(traverser, n, opts.External)
+			if err != nil {
+				panic(err)
+			}
+			spec := getField(n, "Spec")
+			if spec == nil {
+				continue
+			}
+			if err := fx.set(jen.Id("mg").Dot("Spec"), spec.Type(), nil, false, "Spec"); err != nil {
+				panic(errors.Wrapf(err, "cannot generate a fixture of %s", o.Name()))
+			}
+			writeFixture(f, n, fx)
+		}
+	}
+}
+
+// writeFixture writes the fixture function, option type, and options of the
+// supplied managed resource, which the supplied fixture has set the required
+// fields of.
+func writeFixture(f *jen.File, n *types.Named, fx *fixture) {
+	name := n.Obj().Name()
+	mg := typeCode(n)
+	option := name + "FixtureOption"
+
+	f.Commentf("A %s modifies the %s returned by New%sFixture.", option, name, name)
+	f.Type().Id(option).Func().Params(jen.Id("mg").Op("*").Add(mg.Clone()))
+	f.Line()
+	f.Commentf("New%sFixture returns a %s whose required spec fields are set to valid", name, name)
+	f.Comment("values, modified by the supplied options.")
+	f.Func().Id("New"+name+"Fixture").Params(jen.Id("o").Op("...").Id(option)).Op("*").Add(mg.Clone()).Block(
+		jen.Id("mg").Op(":=").Op("&").Add(mg.Clone()).Values(),
+		&fx.stmts,
+		jen.For(jen.List(jen.Id("_"), jen.Id("fn")).Op(":=").Range().Id("o")).Block(
+			jen.Id("fn").Call(jen.Id("mg")),
+		),
+		jen.Return(jen.Id("mg")),
+	)
+
+	parent, path := optionFields(n)
+	if parent == nil {
+		return
+	}
+	for i := 0; i < parent.NumFields(); i++ {
+		field := parent.Field(i)
+		if !field.Exported() || fx.element(field.Type(), field.Name()) != nil {
+			continue
+		}
+		f.Line()
+		f.Commentf("With%s%s sets the %s of the %s returned by New%sFixture.", name, field.Name(), field.Name(), name, name)
+		f.Func().Id("With"+name+field.Name()).Params(jen.Id("v").Add(typeCode(field.Type()))).Id(option).Block(
+			jen.Return(jen.Func().Params(jen.Id("mg").Op("*").Add(mg.Clone())).Block(
+				path.Clone().Dot(field.Name()).Op("=").Id("v"),
+			)),
+		)
+	}
+}
+
+// optionFields returns the struct whose fields the supplied managed resource's
+// fixture has an option for, and the path to it: its spec.forProvider if it has
+// one, and otherwise its spec.
+func optionFields(n *types.Named) (*types.Struct, *jen.Statement) {
+	spec := getField(n, "Spec")
+	if spec == nil {
+		return nil, nil
+	}
+	st, ok := spec.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil, nil
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if fp := st.Field(i); fp.Name() == "ForProvider" {
+			if fst, ok := fp.Type().Underlying().(*types.Struct); ok {
+				return fst, jen.Id("mg").Dot("Spec").Dot("ForProvider")
+			}
+		}
+	}
+	return st, jen.Id("mg").Dot("Spec")
+}
+
+// A fixture accumulates the statements that set the required fields of a
+// managed resource.
+type fixture struct {
+	pkg      *types.Package
+	external bool
+
+	// fields are the fields found by traversing the managed resource, and
+	// named are the markers of the named types found.
+	fields map[*types.Var]fixtureField
+	named  map[*types.TypeName]comments.Markers
+
+	// traverse finds the fields of a type that the managed resource
+	// doesn't traverse to, like that of the values of a map.
+	traverse func(n *types.Named) error
+
+	visiting map[types.Type]bool
+	stmts    jen.Statement
+}
+
+// A fixtureField is a field found by traversing a managed resource.
+type fixtureField struct {
+	markers  comments.Markers
+	required bool
+	omitted  bool
+}
+
+// newFixture returns a fixture of the supplied managed resource, with the
+// fields and named types found by traversing it.
+func newFixture(traverser *xptypes.Traverser, n *types.Named, external bool) (*fixture, error) {
+	fx := &fixture{
+		pkg:      n.Obj().Pkg(),
+		external: external,
+		fields:   map[*types.Var]fixtureField{},
+		named:    map[*types.TypeName]comments.Markers{},
+		visiting: map[types.Type]bool{},
+	}
+	cfg := &xptypes.ProcessorConfig{
+		Named: xptypes.NamedProcessorFn(func(n *types.Named, comment string) error {
+			fx.named[n.Obj()] = comments.ParseMarkers(comment)
+			return nil
+		}),
+		Field: xptypes.FieldProcessorFn(func(_ *types.Named, f *types.Var, tag, comment string, _ ...string) error {
+			markers := comments.ParseMarkers(comment)
+			fx.fields[f] = fixtureField{markers: markers, required: isRequired(markers, tag), omitted: omitEmpty(tag)}
+			return nil
+		}),
+	}
+	fx.traverse = func(n *types.Named) error {
+		return errors.Wrapf(traverser.Traverse(n, cfg), "cannot traverse the type tree of %s", n.Obj().Name())
+	}
+	return fx, fx.traverse(n)
+}
+
+// omitEmpty returns true if a field with the supplied tag is omitted from its
+// JSON representation when empty.
+func omitEmpty(tag string) bool {
+	json, ok := reflect.StructTag(tag).Lookup("json")
+	if !ok {
+		return false
+	}
+	for _, o := range strings.Split(json, ",")[1:] {
+		if o == "omitempty" {
+			return true
+		}
+	}
+	return false
+}
+
+// set accumulates statements that set the value at the supplied path, which is
+// of the supplied type and described by the supplied field for errors, to a
+// valid value of a required field with the supplied markers. Values that are
+// valid when empty are left as they are, unless they are omitted from JSON
+// when empty.
+func (fx *fixture) set(path *jen.Statement, t types.Type, markers comments.Markers, omitted bool, field string) error {
+	if err := fx.expressible(t, field); err != nil {
+		return err
+	}
+	if nt, ok := t.(*types.Named); ok {
+		markers = merge(fx.named[nt.Obj()], markers)
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		v, err := basicValue(u, markers, omitted, field)
+		if err != nil || v == nil {
+			return err
+		}
+		fx.assign(path, v)
+	case *types.Struct:
+		return fx.setFields(path, t, u, field)
+	case *types.Pointer:
+		if _, ok := u.Elem().Underlying().(*types.Struct); ok {
+			fx.assign(path, jen.Op("&").Add(typeCode(u.Elem())).Values())
+			return fx.set(path, u.Elem(), markers, false, field)
+		}
+		fx.assign(path, jen.New(typeCode(u.Elem())))
+		return fx.set(jen.Parens(jen.Op("*").Add(path.Clone())), u.Elem(), markers, false, field)
+	case *types.Slice:
+		fx.assign(path, jen.Make(typeCode(t), jen.Lit(1)))
+		return fx.set(path.Clone().Index(jen.Lit(0)), u.Elem(), nil, false, field+"[0]")
+	case *types.Map:
+		return fx.setMap(path, t, u, field)
+	case *types.Array:
+		// Arrays are never empty.
+	default:
+		return errors.Errorf("cannot set required field %s of type %s to a valid value", field, types.TypeString(t, types.RelativeTo(fx.pkg)))
+	}
+	return nil
+}
+
+// setFields accumulates statements that set the required fields of the struct
+// at the supplied path.
+func (fx *fixture) setFields(path *jen.Statement, t types.Type, st *types.Struct, field string) error {
+	if fx.visiting[t] {
+		return errors.Errorf("cannot set required field %s, because it refers to its own type", field)
+	}
+	fx.visiting[t] = true
+	defer delete(fx.visiting, t)
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		ff, ok := fx.fields[f]
+		if !ok || !ff.required {
+			continue
+		}
+		name := field + "." + f.Name()
+		if fx.external && !f.Exported() {
+			// The field may be left as it is if it is valid when empty.
+			unexported := fx.sub()
+			if err := unexported.set(path.Clone().Dot(f.Name()), f.Type(), ff.markers, ff.omitted, name); err != nil {
+				return err
+			}
+			if len(unexported.stmts) > 0 {
+				return errors.Errorf("cannot set required field %s from an external test package, because it is not exported", name)
+			}
+			continue
+		}
+		if err := fx.set(path.Clone().Dot(f.Name()), f.Type(), ff.markers, ff.omitted, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setMap accumulates statements that set the map at the supplied path to one
+// with a single key, whose value is valid. The fields of struct values of a map
+// can't be set, so maps of structs with required fields are not supported.
+func (fx *fixture) setMap(path *jen.Statement, t types.Type, m *types.Map, field string) error {
+	if k, ok := m.Key().Underlying().(*types.Basic); !ok || k.Info()&types.IsString == 0 {
+		return errors.Errorf("cannot set required field %s, because its keys are not strings", field)
+	}
+	fx.assign(path, typeCode(t).Values())
+	key := path.Clone().Index(jen.Lit(FixtureValue))
+	name := fmt.Sprintf("%s[%q]", field, FixtureValue)
+	switch e := m.Elem().Underlying().(type) {
+	case *types.Struct:
+		if nt, ok := m.Elem().(*types.Named); ok {
+			if err := fx.traverse(nt); err != nil {
+				return err
+			}
+		}
+		values := fx.sub()
+		if err := values.setFields(jen.Id("v"), m.Elem(), e, name); err != nil {
+			return err
+		}
+		if len(values.stmts) > 0 {
+			return errors.Errorf("cannot set required fields of the values of field %s", field)
+		}
+		fx.assign(key, typeCode(m.Elem()).Values())
+	case *types.Basic:
+		// The key must be set even if its value is empty.
+		markers := comments.Markers{}
+		if nt, ok := m.Elem().(*types.Named); ok {
+			markers = fx.named[nt.Obj()]
+		}
+		v, err := basicValue(e, markers, true, name)
+		if err != nil {
+			return err
+		}
+		fx.assign(key, v)
+	default:
+		return fx.set(key, m.Elem(), nil, true, name)
+	}
+	return nil
+}
+
+// sub returns a fixture of the same managed resource that accumulates its own
+// statements, so that the caller may tell whether any are needed.
+func (fx *fixture) sub() *fixture {
+	return &fixture{pkg: fx.pkg, external: fx.external, fields: fx.fields, named: fx.named, traverse: fx.traverse, visiting: fx.visiting}
+}
+
+// assign accumulates a statement that assigns the supplied value to the
+// supplied path.
+func (fx *fixture) assign(path *jen.Statement, v jen.Code) {
+	fx.stmts = append(fx.stmts, path.Clone().Op("=").Add(v), jen.Line())
+}
+
+// expressible returns an error if the supplied type of the supplied field can't
+// be referred to by the fixture: an anonymous struct, except as the type of a
+// field, or a type that isn't exported from another package.
+func (fx *fixture) expressible(t types.Type, field string) error {
+	switch u := t.(type) {
+	case *types.Named:
+		obj := u.Obj()
+		if obj.Pkg() != nil && !obj.Exported() && (fx.external || obj.Pkg() != fx.pkg) {
+			return errors.Errorf("cannot refer to type %s of field %s, because it is not exported", obj.Name(), field)
+		}
+	case *types.Pointer:
+		return fx.element(u.Elem(), field)
+	case *types.Slice:
+		return fx.element(u.Elem(), field)
+	case *types.Map:
+		if err := fx.element(u.Key(), field); err != nil {
+			return err
+		}
+		return fx.element(u.Elem(), field)
+	case *types.Array:
+		return fx.element(u.Elem(), field)
+	}
+	return nil
+}
+
+// element returns an error if the supplied type of an element of the supplied
+// field can't be referred to by the fixture.
+func (fx *fixture) element(t types.Type, field string) error {
+	if _, ok := t.(*types.Struct); ok {
+		return errors.Errorf("cannot refer to the anonymous struct type of the elements of field %s", field)
+	}
+	return fx.expressible(t, field)
+}
+
+// merge returns the supplied markers, with those of b replacing those of a.
+func merge(a, b comments.Markers) comments.Markers {
+	m := comments.Markers{}
+	for k, v := range a {
+		m[k] = v
+	}
+	for k, v := range b {
+		m[k] = v
+	}
+	return m
+}
+
+// basicValue returns a valid value of the supplied basic type for a required
+// field with the supplied markers, or nil if its empty value is valid and it is
+// not omitted from JSON when empty.
+func basicValue(b *types.Basic, markers comments.Markers, omitted bool, field string) (jen.Code, error) {
+	for _, m := range []string{KubebuilderPatternMarker, KubebuilderFormatMarker} {
+		if _, ok := markers[m]; ok {
+			return nil, errors.Errorf("cannot set required field %s to a value that satisfies its %s marker", field, m)
+		}
+	}
+	if v, ok := markers[KubebuilderEnumMarker]; ok && len(v) > 0 {
+		first := strings.Split(v[0], ";")[0]
+		if b.Info()&types.IsString != 0 {
+			if s, err := strconv.Unquote(first); err == nil {
+				first = s
+			}
+			return jen.Lit(first), nil
+		}
+		return jen.Id(first), nil
+	}
+
+	info := b.Info()
+	switch {
+	case info&types.IsString != 0:
+		length := 0
+		if v, ok := markers[KubebuilderMinLengthMarker]; ok && len(v) > 0 {
+			n, err := strconv.Atoi(v[0])
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot parse %s marker of field %s", KubebuilderMinLengthMarker, field)
+			}
+			length = n
+		}
+		if !omitted && length == 0 {
+			return nil, nil
+		}
+		s := FixtureValue
+		if length > len(s) {
+			s = strings.Repeat(s, length/len(s)+1)[:length]
+		}
+		return jen.Lit(s), nil
+	case info&(types.IsInteger|types.IsFloat) != 0:
+		min := 0.0
+		if v, ok := markers[KubebuilderMinimumMarker]; ok && len(v) > 0 {
+			f, err := strconv.ParseFloat(v[0], 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot parse %s marker of field %s", KubebuilderMinimumMarker, field)
+			}
+			min = f
+		}
+		if !omitted && min <= 0 {
+			return nil, nil
+		}
+		if min <= 0 {
+			min = 1
+		}
+		if info&types.IsInteger != 0 {
+			min = math.Ceil(min)
+		}
+		return jen.Id(strconv.FormatFloat(min, 'f', -1, 64)), nil
+	case info&types.IsBoolean != 0:
+		if !omitted {
+			return nil, nil
+		}
+		return jen.True(), nil
+	}
+	return nil, errors.Errorf("cannot set required field %s of type %s to a valid value", field, b.Name())
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package method
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/google/go-cmp/cmp"
+
+	xptypes "github.com/crossplane/crossplane-tools/internal/types"
+	"github.com/crossplane/crossplane-tools/pkg/comments"
+)
+
+// fixtures returns the fixtures generated for the Model type of the supplied
+// source, written to the package at the supplied path.
+func fixtures(t *testing.T, source, path string, o ...FixturesOption) string {
+	t.Helper()
+	p := loadPackage(t, source)
+	f := jen.NewFilePathName(path, path[strings.LastIndex(path, "/")+1:])
+	NewFixtures(xptypes.NewTraverser(comments.In(p)), o...)(f, []types.Object{p.Types.Scope().Lookup("Model")})
+	return fmt.Sprintf("%#v", f)
+}
+
+func TestNewFixtures(t *testing.T) {
+	// Required fields are set to valid values that are as empty as their
+	// markers and JSON tags allow, and each field of the parameters has an
+	// option.
+	source := `
+package v1alpha1
+
+// +kubebuilder:validation:Enum=Fast;Slow
+type Mode string
+
+type Rule struct {
+	// +kubebuilder:validation:Required
+	Name string ` + "`" + `json:"name,omitempty"` + "`" + `
+
+	Port int ` + "`" + `json:"port,omitempty"` + "`" + `
+}
+
+type Settings struct {
+	Enabled bool ` + "`" + `json:"enabled"` + "`" + `
+}
+
+type ModelParameters struct {
+	// +kubebuilder:validation:Required
+	Region *string ` + "`" + `json:"region,omitempty"` + "`" + `
+
+	Mode Mode ` + "`" + `json:"mode"` + "`" + `
+
+	// +kubebuilder:validation:Minimum=3
+	Replicas int32 ` + "`" + `json:"replicas"` + "`" + `
+
+	Rules []Rule ` + "`" + `json:"rules"` + "`" + `
+
+	Settings *Settings ` + "`" + `json:"settings"` + "`" + `
+
+	Tags map[string]string ` + "`" + `json:"tags"` + "`" + `
+
+	// +kubebuilder:validation:MinLength=10
+	Description string ` + "`" + `json:"description"` + "`" + `
+
+	Comment *string ` + "`" + `json:"comment,omitempty"` + "`" + `
+
+	internal string
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters ` + "`" + `json:"forProvider"` + "`" + `
+}
+
+type Model struct {
+	Spec ModelSpec ` + "`" + `json:"spec"` + "`" + `
+}
+`
+	want := `package v1alpha1
+
+// A ModelFixtureOption modifies the Model returned by NewModelFixture.
+type ModelFixtureOption func(mg *Model)
+
+// NewModelFixture returns a Model whose required spec fields are set to valid
+// values, modified by the supplied options.
+func NewModelFixture(o ...ModelFixtureOption) *Model {
+	mg := &Model{}
+	mg.Spec.ForProvider.Region = new(string)
+	mg.Spec.ForProvider.Mode = "Fast"
+	mg.Spec.ForProvider.Replicas = 3
+	mg.Spec.ForProvider.Rules = make([]Rule, 1)
+	mg.Spec.ForProvider.Rules[0].Name = "fixture"
+	mg.Spec.ForProvider.Settings = &Settings{}
+	mg.Spec.ForProvider.Tags = map[string]string{}
+	mg.Spec.ForProvider.Tags["fixture"] = "fixture"
+	mg.Spec.ForProvider.Description = "fixturefix"
+
+	for _, fn := range o {
+		fn(mg)
+	}
+	return mg
+}
+
+// WithModelRegion sets the Region of the Model returned by NewModelFixture.
+func WithModelRegion(v *string) ModelFixtureOption {
+	return func(mg *Model) {
+		mg.Spec.ForProvider.Region = v
+	}
+}
+
+// WithModelMode sets the Mode of the Model returned by NewModelFixture.
+func WithModelMode(v Mode) ModelFixtureOption {
+	return func(mg *Model) {
+		mg.Spec.ForProvider.Mode = v
+	}
+}
+
+// WithModelReplicas sets the Replicas of the Model returned by NewModelFixture.
+func WithModelReplicas(v int32) ModelFixtureOption {
+	return func(mg *Model) {
+		mg.Spec.ForProvider.Replicas = v
+	}
+}
+
+// WithModelRules sets the Rules of the Model returned by NewModelFixture.
+func WithModelRules(v []Rule) ModelFixtureOption {
+	return func(mg *Model) {
+		mg.Spec.ForProvider.Rules = v
+	}
+}
+
+// WithModelSettings sets the Settings of the Model returned by NewModelFixture.
+func WithModelSettings(v *Settings) ModelFixtureOption {
+	return func(mg *Model) {
+		mg.Spec.ForProvider.Settings = v
+	}
+}
+
+// WithModelTags sets the Tags of the Model returned by NewModelFixture.
+func WithModelTags(v map[string]string) ModelFixtureOption {
+	return func(mg *Model) {
+		mg.Spec.ForProvider.Tags = v
+	}
+}
+
+// WithModelDescription sets the Description of the Model returned by NewModelFixture.
+func WithModelDescription(v string) ModelFixtureOption {
+	return func(mg *Model) {
+		mg.Spec.ForProvider.Description = v
+	}
+}
+
+// WithModelComment sets the Comment of the Model returned by NewModelFixture.
+func WithModelComment(v *string) ModelFixtureOption {
+	return func(mg *Model) {
+		mg.Spec.ForProvider.Comment = v
+	}
+}
+`
+	got := fixtures(t, source, "golang.org/fake/v1alpha1")
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nNewFixtures(...): -want, +got:\n%s", diff)
+	}
+
+	t.Run("External", func(t *testing.T) {
+		got := fixtures(t, source, "golang.org/fake/v1alpha1_test", WithExternalFixtures())
+		for _, want := range []string{
+			"func NewModelFixture(o ...ModelFixtureOption) *v1alpha1.Model {",
+			"mg.Spec.ForProvider.Rules = make([]v1alpha1.Rule, 1)",
+			"func WithModelMode(v v1alpha1.Mode) ModelFixtureOption {",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("\nFixtures written to an external test package should refer to the types of the package it tests.\nNewFixtures(...): want %q in:\n%s", want, got)
+			}
+		}
+	})
+}
+
+func TestNewFixturesErrors(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Rule struct {
+	// +kubebuilder:validation:Required
+	Name string ` + "`json:\"name,omitempty\"`" + `
+}
+
+type Node struct {
+	Next *Node ` + "`json:\"next\"`" + `
+}
+
+type ModelParameters struct {
+%s
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters ` + "`json:\"forProvider\"`" + `
+}
+
+type Model struct {
+	Spec ModelSpec ` + "`json:\"spec\"`" + `
+}
+`
+	cases := map[string]struct {
+		reason string
+		fields string
+		opts   []FixturesOption
+		want   string
+	}{
+		"Interface": {
+			reason: "A required field of an interface type can't be set to a valid value.",
+			fields: "Source interface{} `json:\"source\"`",
+			want:   "cannot generate a fixture of Model: cannot set required field Spec.ForProvider.Source of type interface{} to a valid value",
+		},
+		"Pattern": {
+			reason: "A required field can't be set to a value that matches a pattern.",
+			fields: "// +kubebuilder:validation:Required\n// +kubebuilder:validation:Pattern=^a$\nName string `json:\"name,omitempty\"`",
+			want:   "cannot generate a fixture of Model: cannot set required field Spec.ForProvider.Name to a value that satisfies its kubebuilder:validation:Pattern marker",
+		},
+		"MapOfStructs": {
+			reason: "The required fields of the values of a map can't be set.",
+			fields: "Rules map[string]Rule `json:\"rules\"`",
+			want:   "cannot generate a fixture of Model: cannot set required fields of the values of field Spec.ForProvider.Rules",
+		},
+		"Recursive": {
+			reason: "A required field that refers to its own type can't be set.",
+			fields: "Root Node `json:\"root\"`",
+			want:   "cannot generate a fixture of Model: cannot set required field Spec.ForProvider.Root.Next, because it refers to its own type",
+		},
+		"ExternalUnexported": {
+			reason: "A required field that isn't exported can't be set from an external test package.",
+			fields: "internal *string `json:\"internal\"`",
+			opts:   []FixturesOption{WithExternalFixtures()},
+			want:   "cannot generate a fixture of Model: cannot set required field Spec.ForProvider.internal from an external test package, because it is not exported",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if diff := cmp.Diff(tc.want, fmt.Sprint(recover())); diff != "" {
+					t.Errorf("\n%s\nNewFixtures(...): -want, +got:\n%s", tc.reason, diff)
+				}
+			}()
+			fixtures(t, fmt.Sprintf(source, tc.fields), "golang.org/fake/v1alpha1", tc.opts...)
+		})
+	}
+}
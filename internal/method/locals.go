@@ -0,0 +1,101 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package method
+
+import (
+	"go/types"
+	"regexp"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// reservedLocals are the variables that are declared in the body of a
+// generated ResolveReferences method, and so shadow any identifier of the same
+// name that the method refers to.
+var reservedLocals = []string{
+	"r", "rsp", "mrsp", "err", "resolved", "dependencies", "tenant", "resolvedBy",
+	"hashInputs", "hash", "inputs", "annotations", "deps", "extracted", "cancel",
+	"original", "backoff", "cacheKey", "cached",
+}
+
+// regexIdent matches the identifiers of a field path, for example Rules and
+// SubnetID of mg.Spec.ForProvider.Rules[i0].SubnetID.
+var regexIdent = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// locals are the names of the variables of a generated ResolveReferences
+// method, by the name they are reserved as.
+type locals map[string]string
+
+// newLocals returns the names of the variables of the ResolveReferences method
+// of the supplied type, which has the supplied receiver and references. This
+// includes the variables that components of composite keys are kept in. A
+// variable is renamed, for example rsp to rsp_, if the package of the type
+// declares an identifier of its name, which the method might refer to, or if
+// it is the name of the receiver or of a field on the path of a reference.
+func newLocals(n *types.Named, receiver string, refs []Reference) locals {
+	taken := map[string]bool{receiver: true}
+	if p := n.Obj().Pkg(); p != nil {
+		for _, name := range p.Scope().Names() {
+			taken[name] = true
+		}
+	}
+	temps := make([]string, 0)
+	for _, ref := range refs {
+		for _, f := range valueFields(ref) {
+			for _, id := range regexIdent.FindAllString(f, -1) {
+				taken[id] = true
+			}
+		}
+		for _, s := range append(append([]PathSegment{}, ref.GoRefFieldParents...), ref.GoSelectorFieldParents...) {
+			taken[s.Name] = true
+		}
+		taken[ref.GoRefFieldName] = true
+		taken[ref.GoSelectorFieldName] = true
+		if ref.Composite != nil {
+			for _, t := range ref.Composite.Into {
+				temps = append(temps, componentVar(t))
+			}
+		}
+	}
+
+	l := locals{}
+	for _, name := range append(append([]string{}, reservedLocals...), temps...) {
+		if _, ok := l[name]; ok {
+			continue
+		}
+		renamed := name
+		for taken[renamed] {
+			renamed += "_"
+		}
+		taken[renamed] = true
+		l[name] = renamed
+	}
+	return l
+}
+
+// Id returns the identifier of the supplied variable.
+func (l locals) Id(name string) *jen.Statement {
+	if renamed, ok := l[name]; ok {
+		return jen.Id(renamed)
+	}
+	return jen.Id(name)
+}
+
+// Err returns the identifier of the err variable.
+func (l locals) Err() *jen.Statement {
+	return l.Id("err")
+}
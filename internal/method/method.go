@@ -23,6 +23,7 @@ import (
 	"sort"
 
 	"github.com/dave/jennifer/jen"
+	"github.com/pkg/errors"
 
 	"github.com/crossplane/crossplane-tools/internal/fields"
 )
@@ -359,3 +360,128 @@ func NewProviderConfigUsageGetItems(receiver, resource string) New {
 		)
 	}
 }
+
+// An Accessor is a standard method of a managed resource that gets or sets a
+// field of its spec, for which an alternate variant may be generated.
+type Accessor struct {
+	// Name of the method, for example GetDeletionPolicy.
+	Name string
+
+	// Field of the spec that the method gets or sets.
+	Field string
+
+	// Type of the field, which is defined by the runtime package.
+	Type string
+
+	// Pointer is true if the method takes or returns a pointer to the
+	// field's type, rather than a value.
+	Pointer bool
+
+	// Set is true if the method sets the field, rather than getting it.
+	Set bool
+}
+
+// Variant returns the name of the alternate variant of the accessor: its name
+// suffixed with Value if it takes or returns a pointer, or with Ptr if it takes
+// or returns a value.
+func (a Accessor) Variant() string {
+	if a.Pointer {
+		return a.Name + "Value"
+	}
+	return a.Name + "Ptr"
+}
+
+// NewAccessorVariant returns a New that writes the alternate variant of the
+// supplied accessor for the supplied Object to the supplied file. The variant
+// takes or returns a value if the accessor takes or returns a pointer, and vice
+// versa, whether the field of the object's spec is a pointer or not. Nothing is
+// written if the spec has no such field of the accessor's type or of a pointer
+// to it. It panics if the object has a field named as the variant.
+func NewAccessorVariant(receiver, runtime string, a Accessor) New {
+	return func(f *jen.File, o types.Object) {
+		fieldPointer, ok := specField(o, runtime, a)
+		if !ok {
+			return
+		}
+		if v, _, _ := types.LookupFieldOrMethod(o.Type(), true, o.Pkg(), a.Variant()); v != nil {
+			if _, field := v.(*types.Var); field {
+				panic(errors.Errorf("cannot write %s of %s, which has a field with the same name", a.Variant(), o.Name()))
+			}
+		}
+
+		field := jen.Id(receiver).Dot(fields.NameSpec).Dot(a.Field)
+		typ := jen.Qual(runtime, a.Type)
+		variant := typ.Clone()
+		if !a.Pointer {
+			variant = jen.Op("*").Add(typ.Clone())
+		}
+		var body []jen.Code
+		switch {
+		case a.Set && fieldPointer == !a.Pointer:
+			body = []jen.Code{field.Clone().Op("=").Id("r")}
+		case a.Set && fieldPointer:
+			body = []jen.Code{field.Clone().Op("=").Op("&").Id("r")}
+		case a.Set:
+			body = []jen.Code{
+				jen.Var().Id("v").Add(typ.Clone()),
+				jen.If(jen.Id("r").Op("!=").Nil()).Block(jen.Id("v").Op("=").Op("*").Id("r")),
+				field.Clone().Op("=").Id("v"),
+			}
+		case fieldPointer == !a.Pointer:
+			body = []jen.Code{jen.Return(field.Clone())}
+		case fieldPointer:
+			body = []jen.Code{
+				jen.Var().Id("v").Add(typ.Clone()),
+				jen.If(field.Clone().Op("!=").Nil()).Block(jen.Id("v").Op("=").Op("*").Add(field.Clone())),
+				jen.Return(jen.Id("v")),
+			}
+		default:
+			body = []jen.Code{jen.Return(jen.Op("&").Add(field.Clone()))}
+		}
+
+		f.Commentf("%s of this %s. It is a transitional variant of %s.", a.Variant(), o.Name(), a.Name)
+		fn := f.Func().Params(jen.Id(receiver).Op("*").Id(o.Name())).Id(a.Variant())
+		if a.Set {
+			fn.Params(jen.Id("r").Add(variant)).Block(body...)
+			return
+		}
+		fn.Params().Add(variant).Block(body...)
+	}
+}
+
+// NewAccessor returns a New that writes the supplied standard accessor using the
+// supplied New, unless the field of the object's spec that it accesses has the
+// shape of its alternate variant, for example a DeletionPolicy field that is a
+// pointer. The standard accessor could not be compiled; the variant is written
+// instead.
+func NewAccessor(standard New, runtime string, a Accessor) New {
+	return func(f *jen.File, o types.Object) {
+		if fieldPointer, ok := specField(o, runtime, a); ok && fieldPointer != a.Pointer {
+			return
+		}
+		standard(f, o)
+	}
+}
+
+// specField returns whether the field of the spec of the supplied Object that
+// the supplied accessor gets or sets is a pointer. It returns false if the spec
+// has no such field of the accessor's type or of a pointer to it.
+func specField(o types.Object, runtime string, a Accessor) (pointer, ok bool) {
+	spec, _, _ := types.LookupFieldOrMethod(o.Type(), true, o.Pkg(), fields.NameSpec)
+	if _, ok := spec.(*types.Var); !ok {
+		return false, false
+	}
+	fv, _, _ := types.LookupFieldOrMethod(spec.Type(), true, o.Pkg(), a.Field)
+	if _, ok := fv.(*types.Var); !ok {
+		return false, false
+	}
+	ft := fv.Type()
+	if p, ok := ft.(*types.Pointer); ok {
+		pointer, ft = true, p.Elem()
+	}
+	n, ok := ft.(*types.Named)
+	if !ok || n.Obj().Pkg() == nil || n.Obj().Pkg().Path() != runtime || n.Obj().Name() != a.Type {
+		return false, false
+	}
+	return pointer, true
+}
@@ -452,3 +452,190 @@ func (t *Type) GetItems() []resource.ProviderConfigUsage {
 		t.Errorf("NewProviderConfigUsageGetItems(): -want, +got\n%s", diff)
 	}
 }
+
+const accessorSource = `
+package v1alpha1
+
+type DeletionPolicy string
+
+type Reference struct {
+	Name string
+}
+
+type ModelSpec struct {
+	DeletionPolicy DeletionPolicy
+	ProviderConfigReference *Reference
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+
+type MigratedSpec struct {
+	DeletionPolicy *DeletionPolicy
+}
+
+type Migrated struct {
+	Spec MigratedSpec
+}
+
+type CollidingSpec struct {
+	DeletionPolicy DeletionPolicy
+}
+
+type Colliding struct {
+	Spec CollidingSpec
+
+	GetDeletionPolicyPtr string
+}
+`
+
+func TestNewAccessorVariant(t *testing.T) {
+	getDeletionPolicy := Accessor{Name: "GetDeletionPolicy", Field: "DeletionPolicy", Type: "DeletionPolicy"}
+	setDeletionPolicy := Accessor{Name: "SetDeletionPolicy", Field: "DeletionPolicy", Type: "DeletionPolicy", Set: true}
+	getProviderConfigReference := Accessor{Name: "GetProviderConfigReference", Field: "ProviderConfigReference", Type: "Reference", Pointer: true}
+	setProviderConfigReference := Accessor{Name: "SetProviderConfigReference", Field: "ProviderConfigReference", Type: "Reference", Pointer: true, Set: true}
+
+	cases := map[string]struct {
+		reason   string
+		typ      string
+		accessor Accessor
+		want     string
+	}{
+		"GetValueAsPointer": {
+			reason:   "The variant of a getter of a value should return a pointer to the field.",
+			typ:      "Model",
+			accessor: getDeletionPolicy,
+			want: `package v1alpha1
+
+// GetDeletionPolicyPtr of this Model. It is a transitional variant of GetDeletionPolicy.
+func (mg *Model) GetDeletionPolicyPtr() *DeletionPolicy {
+	return &mg.Spec.DeletionPolicy
+}
+`,
+		},
+		"SetValueFromPointer": {
+			reason:   "The variant of a setter of a value should set the field to the value a pointer points to, or the zero value.",
+			typ:      "Model",
+			accessor: setDeletionPolicy,
+			want: `package v1alpha1
+
+// SetDeletionPolicyPtr of this Model. It is a transitional variant of SetDeletionPolicy.
+func (mg *Model) SetDeletionPolicyPtr(r *DeletionPolicy) {
+	var v DeletionPolicy
+	if r != nil {
+		v = *r
+	}
+	mg.Spec.DeletionPolicy = v
+}
+`,
+		},
+		"GetPointerAsValue": {
+			reason:   "The variant of a getter of a pointer should return the value the field points to, or the zero value.",
+			typ:      "Model",
+			accessor: getProviderConfigReference,
+			want: `package v1alpha1
+
+// GetProviderConfigReferenceValue of this Model. It is a transitional variant of GetProviderConfigReference.
+func (mg *Model) GetProviderConfigReferenceValue() Reference {
+	var v Reference
+	if mg.Spec.ProviderConfigReference != nil {
+		v = *mg.Spec.ProviderConfigReference
+	}
+	return v
+}
+`,
+		},
+		"SetPointerFromValue": {
+			reason:   "The variant of a setter of a pointer should set the field to a pointer to a value.",
+			typ:      "Model",
+			accessor: setProviderConfigReference,
+			want: `package v1alpha1
+
+// SetProviderConfigReferenceValue of this Model. It is a transitional variant of SetProviderConfigReference.
+func (mg *Model) SetProviderConfigReferenceValue(r Reference) {
+	mg.Spec.ProviderConfigReference = &r
+}
+`,
+		},
+		"MigratedField": {
+			reason:   "The variant of an accessor of a field that has the shape of the variant should access the field directly.",
+			typ:      "Migrated",
+			accessor: getDeletionPolicy,
+			want: `package v1alpha1
+
+// GetDeletionPolicyPtr of this Migrated. It is a transitional variant of GetDeletionPolicy.
+func (mg *Migrated) GetDeletionPolicyPtr() *DeletionPolicy {
+	return mg.Spec.DeletionPolicy
+}
+`,
+		},
+		"MissingField": {
+			reason:   "No variant should be written for a managed resource whose spec has no field of the accessed type.",
+			typ:      "Migrated",
+			accessor: getProviderConfigReference,
+			want: `package v1alpha1
+`,
+		},
+	}
+	p := loadPackage(t, accessorSource)
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := jen.NewFilePath("golang.org/fake/v1alpha1")
+			NewAccessorVariant("mg", "golang.org/fake/v1alpha1", tc.accessor)(f, p.Types.Scope().Lookup(tc.typ))
+			if diff := cmp.Diff(tc.want, fmt.Sprintf("%#v", f)); diff != "" {
+				t.Errorf("\n%s\nNewAccessorVariant(...): -want, +got\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNewAccessorVariantCollision(t *testing.T) {
+	p := loadPackage(t, accessorSource)
+	defer func() {
+		want := "cannot write GetDeletionPolicyPtr of Colliding, which has a field with the same name"
+		if got := fmt.Sprint(recover()); got != want {
+			t.Errorf("NewAccessorVariant(...): want panic %q, got %q", want, got)
+		}
+	}()
+	f := jen.NewFilePath("golang.org/fake/v1alpha1")
+	NewAccessorVariant("mg", "golang.org/fake/v1alpha1", Accessor{Name: "GetDeletionPolicy", Field: "DeletionPolicy", Type: "DeletionPolicy"})(f, p.Types.Scope().Lookup("Colliding"))
+}
+
+func TestNewAccessor(t *testing.T) {
+	a := Accessor{Name: "GetDeletionPolicy", Field: "DeletionPolicy", Type: "DeletionPolicy"}
+
+	cases := map[string]struct {
+		reason string
+		typ    string
+		want   string
+	}{
+		"StandardShape": {
+			reason: "The standard accessor should be written if its field has the shape of its signature.",
+			typ:    "Model",
+			want: `package v1alpha1
+
+// GetDeletionPolicy of this Model.
+func (mg *Model) GetDeletionPolicy() DeletionPolicy {
+	return mg.Spec.DeletionPolicy
+}
+`,
+		},
+		"VariantShape": {
+			reason: "The standard accessor should not be written once its field has the shape of its variant.",
+			typ:    "Migrated",
+			want: `package v1alpha1
+`,
+		},
+	}
+	p := loadPackage(t, accessorSource)
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := jen.NewFilePath("golang.org/fake/v1alpha1")
+			NewAccessor(NewGetDeletionPolicy("mg", "golang.org/fake/v1alpha1"), "golang.org/fake/v1alpha1", a)(f, p.Types.Scope().Lookup(tc.typ))
+			if diff := cmp.Diff(tc.want, fmt.Sprintf("%#v", f)); diff != "" {
+				t.Errorf("\n%s\nNewAccessor(...): -want, +got\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
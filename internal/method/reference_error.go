@@ -0,0 +1,74 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package method
+
+import (
+	"go/types"
+
+	"github.com/dave/jennifer/jen"
+
+	xptypes "github.com/crossplane/crossplane-tools/internal/types"
+)
+
+// NewReferenceError returns a function that writes the ReferenceError type that
+// resolvers generated using WithReferenceError return, if any of the supplied
+// managed resources has references. It is written once for each package, so
+// that the resolvers of all of its managed resources return the same type.
+func NewReferenceError(traverser *xptypes.Traverser, runtimePackagePath string) func(f *jen.File, objects []types.Object) {
+	return func(f *jen.File, objects []types.Object) {
+		found := false
+		for _, o := range objects {
+			n, ok := o.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			refs, err := References(traverser, runtimePackagePath, n)
+			if err != nil {
+				panic(err)
+			}
+			if len(refs) > 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return
+		}
+
+		f.Comment("A ReferenceError is returned by ResolveReferences when the reference of a")
+		f.Comment("field can't be resolved.")
+		f.Type().Id("ReferenceError").Struct(
+			jen.Comment("FieldPath is the path of the field that was being resolved."),
+			jen.Id("FieldPath").String(),
+			jen.Line(),
+			jen.Comment("TargetKind is the kind of the managed resource the field references."),
+			jen.Id("TargetKind").String(),
+			jen.Line(),
+			jen.Comment("Err is the error the reference couldn't be resolved with."),
+			jen.Id("Err").Error(),
+		)
+		f.Line()
+		f.Func().Params(jen.Id("e").Op("*").Id("ReferenceError")).Id("Error").Params().String().Block(
+			jen.Return(jen.Id("e").Dot("FieldPath").Op("+").Lit(": ").Op("+").Id("e").Dot("Err").Dot("Error").Call()),
+		)
+		f.Line()
+		f.Comment("Unwrap returns the error the reference couldn't be resolved with.")
+		f.Func().Params(jen.Id("e").Op("*").Id("ReferenceError")).Id("Unwrap").Params().Error().Block(
+			jen.Return(jen.Id("e").Dot("Err")),
+		)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package method
+
+import (
+	"fmt"
+	"go/types"
+	"testing"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/google/go-cmp/cmp"
+
+	xptypes "github.com/crossplane/crossplane-tools/internal/types"
+	"github.com/crossplane/crossplane-tools/pkg/comments"
+)
+
+func TestNewReferenceError(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+
+type NoReferences struct {
+	Spec string
+}
+`
+	cases := map[string]struct {
+		reason  string
+		objects []string
+		want    string
+	}{
+		"References": {
+			reason:  "The ReferenceError type should be written once if any managed resource has references.",
+			objects: []string{"Model", "NoReferences"},
+			want: `package v1alpha1
+
+// A ReferenceError is returned by ResolveReferences when the reference of a
+// field can't be resolved.
+type ReferenceError struct {
+	// FieldPath is the path of the field that was being resolved.
+	FieldPath string
+
+	// TargetKind is the kind of the managed resource the field references.
+	TargetKind string
+
+	// Err is the error the reference couldn't be resolved with.
+	Err error
+}
+
+func (e *ReferenceError) Error() string {
+	return e.FieldPath + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error the reference couldn't be resolved with.
+func (e *ReferenceError) Unwrap() error {
+	return e.Err
+}
+`,
+		},
+		"NoReferences": {
+			reason:  "Nothing should be written if no managed resource has references.",
+			objects: []string{"NoReferences"},
+			want:    "package v1alpha1\n",
+		},
+	}
+
+	p := loadPackage(t, source)
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := jen.NewFilePath("golang.org/fake/v1alpha1")
+			objects := make([]types.Object, len(tc.objects))
+			for i, n := range tc.objects {
+				objects[i] = p.Types.Scope().Lookup(n)
+			}
+			NewReferenceError(xptypes.NewTraverser(comments.In(p)), "")(f, objects)
+			if diff := cmp.Diff(tc.want, fmt.Sprintf("%#v", f)); diff != "" {
+				t.Errorf("\n%s\nNewReferenceError(...): -want, +got\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
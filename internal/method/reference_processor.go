@@ -17,32 +17,95 @@ limitations under the License.
 package method
 
 import (
+	"go/token"
 	"go/types"
+	"reflect"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/dave/jennifer/jen"
 	"github.com/pkg/errors"
 
-	"github.com/crossplane/crossplane-tools/internal/comments"
+	"github.com/crossplane/crossplane-tools/internal/fieldpath"
+	xptypes "github.com/crossplane/crossplane-tools/internal/types"
+	"github.com/crossplane/crossplane-tools/pkg/comments"
 )
 
 // Comment markers used by ReferenceProcessor
 const (
 	ReferenceTypeMarker               = "crossplane:generate:reference:type"
+	ReferenceListTypeMarker           = "crossplane:generate:reference:listType"
 	ReferenceExtractorMarker          = "crossplane:generate:reference:extractor"
+	ReferenceDefaultExtractorMarker   = "crossplane:generate:reference:defaultExtractor"
 	ReferenceReferenceFieldNameMarker = "crossplane:generate:reference:refFieldName"
 	ReferenceSelectorFieldNameMarker  = "crossplane:generate:reference:selectorFieldName"
+	ReferenceReferenceFieldPathMarker = "crossplane:generate:reference:refFieldPath"
+	ReferenceSelectorFieldPathMarker  = "crossplane:generate:reference:selectorFieldPath"
+	ReferenceDeprecatedMarker         = "crossplane:generate:reference:deprecated"
+	ReferenceFormatMarker             = "crossplane:generate:reference:format"
+	ReferenceClusterScopedMarker      = "crossplane:generate:reference:clusterScoped"
+	ReferenceSpreadIntoMarker         = "crossplane:generate:reference:spreadInto"
+	ReferenceOneOfMarker              = "crossplane:generate:reference:oneOf"
+	ReferenceValidatePatternMarker    = "crossplane:generate:reference:validatePattern"
+	ReferenceValidatorMarker          = "crossplane:generate:reference:validator"
+	ReferenceSameProviderConfigMarker = "crossplane:generate:reference:sameProviderConfig"
+	ReferenceFromAnnotationMarker     = "crossplane:generate:reference:fromAnnotation"
+	ReferenceSliceKeyMarker           = "crossplane:generate:reference:sliceKey"
+	ReferenceWhenMarker               = "crossplane:generate:reference:when"
+	ReferencePavedMarker              = "crossplane:generate:reference:paved"
+	ReferencePavedPathsMarker         = "crossplane:generate:reference:pavedPaths"
+	ReferencePavedRefSuffixMarker     = "crossplane:generate:reference:pavedRefSuffix"
+	ReferencePavedRefsMarker          = "crossplane:generate:reference:pavedRefs"
+	ReferenceCompositeMarker          = "crossplane:generate:reference:composite"
+	ReferenceCompositeIntoMarker      = "crossplane:generate:reference:compositeInto"
+	ReferenceConstructorMarker        = "crossplane:generate:reference:constructor"
+	ReferenceNoRefWriteBackMarker     = "crossplane:generate:reference:noRefWriteBack"
+	ReferenceNormalizeMarker          = "crossplane:generate:reference:normalize"
+	ReferenceTimeoutMarker            = "crossplane:generate:reference:timeout"
+	ReferenceImmutableMarker          = "crossplane:generate:reference:immutable"
+	ReferenceAlsoSetMarker            = "crossplane:generate:reference:alsoSet"
+	ReferenceFieldSelectorMarker      = "crossplane:generate:reference:fieldSelector"
+	ReferenceExtractorArgMarker       = "crossplane:generate:reference:extractorArg"
 )
 
+// ReferenceExtractorTag is the key of a struct tag that supplies the extractor
+// of a field, as an alternative to ReferenceExtractorMarker, for example
+// `extractor:"example.org/pkg/resource.ExtractParamPath(\"arn\",true)"`.
+const ReferenceExtractorTag = "extractor"
+
+// Kubebuilder comment markers that tell whether a field is required.
+const (
+	KubebuilderRequiredMarker = "kubebuilder:validation:Required"
+	KubebuilderOptionalMarker = "kubebuilder:validation:Optional"
+)
+
+// Default suffixes of the keys of a paved map that hold the reference and the
+// selector of one of its keys.
+const (
+	DefaultPavedRefSuffix      = "Ref"
+	DefaultPavedSelectorSuffix = "Selector"
+)
+
+// FormatPlaceholder is replaced by the resolved value in the template supplied
+// using ReferenceFormatMarker.
+const FormatPlaceholder = "{name}"
+
 var (
 	regexFunctionCall = regexp.MustCompile(`((.+)\.)?([^.]+\(.*\))`)
+
+	// regexFieldSelectorRequirement matches a requirement of a field
+	// selector, for example spec.forProvider.region=us-east-1.
+	regexFieldSelectorRequirement = regexp.MustCompile(`^[A-Za-z0-9_.\-\[\]]+(=|==|!=)[^=!,]*$`)
 )
 
 // Reference is the internal representation that has enough information to let
 // us generate the resolver.
 type Reference struct {
-	// RemoteType represents the type whose reference we're holding.
+	// RemoteType represents the type whose reference we're holding. It is a
+	// call of its constructor, if it has one, and otherwise a pointer to its
+	// zero value.
 	RemoteType *jen.Statement
 
 	// Extractor is the function call of the function that will take referenced
@@ -52,6 +115,15 @@ type Reference struct {
 	// RemoteListType is the list type of the type whose reference we're holding.
 	RemoteListType *jen.Statement
 
+	// RemoteTypePath and RemoteListTypePath are the paths of the referenced
+	// type and its list type, either as <package path>.<name> or as a bare
+	// name in the package of the managed resource.
+	RemoteTypePath     string
+	RemoteListTypePath string
+
+	// Pos is the position of the current value field.
+	Pos token.Pos
+
 	// GoValueFieldPath is the list of fields that needs to be traveled to access
 	// the current value field. It may include prefixes like [] for array fields,
 	// * for pointer fields or []* for array of pointer fields.
@@ -64,11 +136,279 @@ type Reference struct {
 	// GoSelectorFieldName is the name of the field whose type is *xpv1.Selector
 	GoSelectorFieldName string
 
+	// GoRefFieldParents and GoSelectorFieldParents are the fields on the path
+	// from the struct that holds the current value field to the structs that
+	// hold its reference and selector fields, if they are not held by the
+	// same struct.
+	GoRefFieldParents      []PathSegment
+	GoSelectorFieldParents []PathSegment
+
+	// GoRefFieldType and GoSelectorFieldType are the types of the reference
+	// and selector fields.
+	GoRefFieldType      *jen.Statement
+	GoSelectorFieldType *jen.Statement
+
+	// GoReferenceType is the type of a single reference, i.e. the type of
+	// the reference field without any pointer or slice.
+	GoReferenceType *jen.Statement
+
+	// IsRefValue tells whether the reference field of a single value is an
+	// xpv1.Reference rather than a *xpv1.Reference, and IsRefPointers whether
+	// that of a slice is a []*xpv1.Reference rather than a []xpv1.Reference.
+	IsRefValue    bool
+	IsRefPointers bool
+
 	// IsSlice tells whether the current value type is a slice kind.
 	IsSlice bool
 
 	// IsPointer tells whether the current value type is a pointer kind.
 	IsPointer bool
+
+	// Format is the template the resolved value is embedded in, if any.
+	Format *ValueFormat
+
+	// DeprecationMessage explains why the reference is deprecated, and what
+	// should be used instead. It is empty unless the reference is deprecated.
+	DeprecationMessage string
+
+	// ClusterScoped tells whether the type whose reference we're holding is
+	// cluster scoped, in which case it is never resolved in the namespace of
+	// the referencing resource.
+	ClusterScoped bool
+
+	// Spread is set if the current value field is a slice of structs, and each
+	// resolved value is written to a field of the corresponding element.
+	Spread *Spread
+
+	// OneOf is set if the current value field is a member of a union struct,
+	// of which at most one member may be set.
+	OneOf *OneOf
+
+	// Required tells whether the current value field is required, either
+	// because it is marked as such or because it is not omitted when empty.
+	Required bool
+
+	// Validation is set if the resolved value must be validated before it is
+	// written to the value field.
+	Validation *Validation
+
+	// Normalizer is a function with the signature func(string) string that
+	// normalizes the current value in the resolution request, for example
+	// strings.ToLower, if it should be. The current value is only replaced
+	// by a resolved value that differs from it once normalized.
+	Normalizer *jen.Statement
+
+	// SameProviderConfig tells whether the referenced resource must use the
+	// same provider config as the referencing resource.
+	SameProviderConfig bool
+
+	// FromAnnotation is the key of the annotation of the referenced resource
+	// that the value is extracted from, if any. Extractor is not used if it
+	// is set.
+	FromAnnotation string
+
+	// SliceKey is set if the current value field is a field of an element of
+	// a slice of structs, and its reference is kept in a slice of keyed
+	// references next to that slice rather than in the element. The
+	// GoRefFieldName is then the name of the field of keyed references.
+	SliceKey *SliceKey
+
+	// When is the function that is called with the managed resource to tell
+	// whether the reference should be resolved, if it is only resolved under
+	// some condition.
+	When *jen.Statement
+
+	// Paved is set if the value field is a key of a free-form map, rather
+	// than a field. GoValueFieldPath is then the path of the map.
+	Paved *Paved
+
+	// Composite is set if the referenced resource is identified by a
+	// composite key, whose components are written to the current value field
+	// and to other fields of the struct that holds it. Extractor is not used
+	// if it is set.
+	Composite *Composite
+
+	// NoRefWriteBack tells whether the resolved reference is not written
+	// back to the reference field, so that the value is resolved from the
+	// selector each time. The reference field may then be absent, in which
+	// case GoRefFieldName is empty.
+	NoRefWriteBack bool
+
+	// Timeout is the time that resolving the reference may take, if it is
+	// limited. Each request to resolve it is made with a context that is
+	// cancelled when it elapses.
+	Timeout time.Duration
+
+	// Immutable tells whether the current value field is immutable once the
+	// managed resource is created, so that it is only resolved while the
+	// managed resource has no external name or the field is empty.
+	Immutable bool
+
+	// Mirrors are the other fields that the resolved value is also written
+	// to once it is written to the current value field, if any.
+	Mirrors []Mirror
+
+	// FieldSelector is a field selector expression, for example
+	// spec.forProvider.region=us-east-1, that resources selected by the
+	// selector must also match, if any. It is passed as the FieldSelector of
+	// resolution requests, which the reference package must support.
+	FieldSelector string
+
+	// ExtractorArg is the name of a field of the struct that holds the
+	// current value field, whose value is passed to the function returned by
+	// Extractor along with the referenced resource, if any, so that
+	// extraction may depend on it, for example on a region.
+	ExtractorArg string
+}
+
+// A PathSegment is a field on the path from the struct that holds a current
+// value field to the struct that holds its reference or selector field.
+type PathSegment struct {
+	// Name is the name of the field.
+	Name string
+
+	// Pointer is the type of the struct the field points to, or nil if the
+	// field is not a pointer.
+	Pointer *jen.Statement
+}
+
+// SliceKey describes how the reference of an element of a slice of structs is
+// found in a slice of keyed references, each of which has a Name and a
+// Reference field.
+type SliceKey struct {
+	// FieldName is the name of the string field of the element whose value
+	// is the Name of its keyed reference.
+	FieldName string
+
+	// RefsElementType is the type of the keyed references.
+	RefsElementType *jen.Statement
+
+	// ReferenceType is the type of the Reference field of the keyed
+	// references.
+	ReferenceType *jen.Statement
+}
+
+// Validation describes how a resolved value is validated. Either Pattern or
+// Validator is set.
+type Validation struct {
+	// Pattern is a regular expression that the resolved value must match.
+	Pattern string
+
+	// Validator is a function with the signature func(string) error that
+	// returns an error if the resolved value is invalid.
+	Validator *jen.Statement
+}
+
+// OneOf describes the union struct that a reference is a member of.
+type OneOf struct {
+	// Siblings are the names of the other members of the union. The
+	// reference is only resolved if none of them are set.
+	Siblings []string
+
+	// Members are the references of the union. They are set only for the
+	// first of them, which checks that the reference or selector of at most
+	// one of them is set.
+	Members []Reference
+}
+
+// Paved describes a key of a free-form map[string]interface{} field whose value
+// is resolved from a reference. The map is accessed using crossplane-runtime's
+// fieldpath.Paved.
+type Paved struct {
+	// Key of the map that holds the value.
+	Key string
+
+	// Nested is true if Key, RefKey, and SelectorKey are field paths within
+	// the nested maps of the map, rather than keys of the map.
+	Nested bool
+
+	// RefKey is the key of the map that holds the reference, if references
+	// are held by the map.
+	RefKey string
+
+	// SelectorKey is the key of the map that holds the selector.
+	SelectorKey string
+
+	// RefsFieldName is the name of the map[string]Reference field of the
+	// struct that holds the map that holds the reference by Key, if
+	// references are held by a typed field rather than by the map.
+	RefsFieldName string
+}
+
+// Spread describes how resolved values are distributed to the elements of a
+// slice of structs.
+type Spread struct {
+	// ElementFieldName is the name of the field of each element that a
+	// resolved value is written to. It may be a string or *string.
+	ElementFieldName string
+
+	// ElementType is the type of the elements of the slice.
+	ElementType *jen.Statement
+}
+
+// Composite describes how the components of a composite key, for example the
+// region and name of a referenced resource, are extracted from it at once and
+// distributed into several fields of the struct that holds the current value
+// field.
+type Composite struct {
+	// Extractor is the function call of the function that returns a function
+	// that takes the referenced resource and returns a struct of its
+	// components, each of which is a string field.
+	Extractor *jen.Statement
+
+	// Value is the component that is the resolved value of the current value
+	// field.
+	Value string
+
+	// Into are the other fields that components are written to.
+	Into []CompositeTarget
+}
+
+// A CompositeTarget is a field that a component of a composite key is written
+// to.
+type CompositeTarget struct {
+	// Component is the name of the field of the struct of components.
+	Component string
+
+	// FieldName is the name of the field it is written to, which is held by
+	// the same struct as the current value field.
+	FieldName string
+
+	// IsPointer tells whether the field is a *string rather than a string.
+	IsPointer bool
+}
+
+// A Mirror is a field that a resolved value is also written to.
+type Mirror struct {
+	// Parents are the fields on the path from the struct that holds the
+	// current value field to the struct that holds the mirror field, if it
+	// is not held by the same struct.
+	Parents []PathSegment
+
+	// FieldName is the name of the mirror field.
+	FieldName string
+
+	// Key is the key of the map that the resolved value is written to, if
+	// the mirror field is a map.
+	Key string
+
+	// MapType is the type of the mirror field if it is a map, so that it can
+	// be allocated.
+	MapType *jen.Statement
+
+	// IsPointer tells whether the mirror field, or the values of its map,
+	// are pointers.
+	IsPointer bool
+}
+
+// ValueFormat is a template that a resolved value is embedded in before it is
+// written to the value field, for example arn:aws:iam::role/{name}.
+type ValueFormat struct {
+	// Prefix precedes the resolved value.
+	Prefix string
+
+	// Suffix follows the resolved value.
+	Suffix string
 }
 
 // ReferenceProcessorOption is used to configure ReferenceProcessor.
@@ -82,6 +422,14 @@ func WithDefaultExtractor(ext *jen.Statement) ReferenceProcessorOption {
 	}
 }
 
+// WithRuntimePackagePath returns an option that sets the path of the
+// crossplane-runtime package that defines the Reference and Selector types.
+func WithRuntimePackagePath(path string) ReferenceProcessorOption {
+	return func(rp *ReferenceProcessor) {
+		rp.RuntimePackagePath = path
+	}
+}
+
 // NewReferenceProcessor returns a new *ReferenceProcessor .
 func NewReferenceProcessor(receiver string, opts ...ReferenceProcessorOption) *ReferenceProcessor {
 	rp := &ReferenceProcessor{
@@ -94,7 +442,10 @@ func NewReferenceProcessor(receiver string, opts ...ReferenceProcessorOption) *R
 }
 
 // ReferenceProcessor detects whether the field is marked as referencer and
-// composes the internal representation of that reference.
+// composes the internal representation of that reference. It may be reused to
+// process the type trees of several types, each using the TypeProcessor
+// returned by For, and accumulates their references separately. The type
+// trees of different types may be processed concurrently.
 type ReferenceProcessor struct {
 	// DefaultExtractor is used when the extractor is not overridden.
 	DefaultExtractor *jen.Statement
@@ -102,17 +453,286 @@ type ReferenceProcessor struct {
 	// Receiver is prepended to all field paths.
 	Receiver string
 
-	refs []Reference
+	// RuntimePackagePath is the path of the crossplane-runtime package that
+	// defines the Reference and Selector types. Reference and selector fields
+	// are required to be of these types if the package is imported by the
+	// package being processed.
+	RuntimePackagePath string
+
+	// mu guards the fields below, which are shared by the processors of all
+	// types.
+	mu sync.Mutex
+
+	// roots are the processors of the type trees that have been processed,
+	// by the type at their root.
+	roots map[*types.Named]*TypeProcessor
+
+	oneOf map[*types.Named]bool
+
+	// cache of the references of fields that have already been processed.
+	// The same field is processed once for each time its struct appears in a
+	// type tree, and once for each type tree it appears in.
+	cache map[referenceKey]Reference
+}
+
+// A referenceKey identifies a cached Reference. A field may produce a
+// different Reference each time its struct appears in a type tree, depending
+// on the default extractor it inherits, so the fingerprint of its markers and
+// inherited default extractor is part of the key.
+type referenceKey struct {
+	field       *types.Var
+	parent      *types.Named
+	fingerprint string
+}
+
+// For returns a TypeProcessor that accumulates the references of the type tree
+// of the supplied type, which is the root of the field paths it is supplied.
+// It replaces any that was returned before for the same type, so that its
+// references are not accumulated twice if it is processed again.
+func (rp *ReferenceProcessor) For(root *types.Named) *TypeProcessor {
+	tp := &TypeProcessor{rp: rp, root: root}
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	if rp.roots == nil {
+		rp.roots = map[*types.Named]*TypeProcessor{}
+	}
+	rp.roots[root] = tp
+	return tp
+}
+
+// ReferencesFor returns the references accumulated from processing the type
+// tree of the supplied type, except those of fields that are shadowed; see
+// DuplicatesFor. It returns none if the type has not been processed.
+func (rp *ReferenceProcessor) ReferencesFor(root *types.Named) []Reference {
+	return rp.typeProcessor(root).references()
+}
+
+// DuplicatesFor returns the fields of the type tree of the supplied type that
+// were processed, but that are not returned by ReferencesFor because they are
+// shadowed.
+func (rp *ReferenceProcessor) DuplicatesFor(root *types.Named) []Duplicate {
+	_, dups := rp.typeProcessor(root).dedupe()
+	return dups
 }
 
-// Process stores the reference information of the given field, if any.
-func (rp *ReferenceProcessor) Process(_ *types.Named, f *types.Var, _, comment string, parentFields ...string) error {
+// typeProcessor returns the processor of the type tree of the supplied type, or
+// an empty one if it has not been processed.
+func (rp *ReferenceProcessor) typeProcessor(root *types.Named) *TypeProcessor {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	if tp, ok := rp.roots[root]; ok {
+		return tp
+	}
+	return &TypeProcessor{rp: rp}
+}
+
+// isUnion returns true if the supplied type has been processed and is a union
+// struct.
+func (rp *ReferenceProcessor) isUnion(n *types.Named) bool {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	return rp.oneOf[n]
+}
+
+// cached returns the cached reference of the supplied key, if any.
+func (rp *ReferenceProcessor) cached(key referenceKey) (Reference, bool) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	ref, ok := rp.cache[key]
+	return ref, ok
+}
+
+// store caches the supplied reference by the supplied key.
+func (rp *ReferenceProcessor) store(key referenceKey, ref Reference) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	if rp.cache == nil {
+		rp.cache = map[referenceKey]Reference{}
+	}
+	rp.cache[key] = ref
+}
+
+// A TypeProcessor processes the type tree of one type for a ReferenceProcessor,
+// and accumulates its references. It may only process one type tree at a time.
+type TypeProcessor struct {
+	rp *ReferenceProcessor
+
+	// root is the type whose type tree is processed, from which the JSON
+	// paths of the fields that are processed are named.
+	root *types.Named
+
+	refs   []Reference
+	unions map[string]*types.Named
+
+	// promoted are the keys of the promoted paths of refs, by index.
+	promoted []string
+
+	// embedded are the paths of the embedded fields that have been
+	// processed.
+	embedded map[string]bool
+
+	// structs are the types of the structs that have been processed, by
+	// field path.
+	structs map[string]*types.Named
+
+	// defaults are the default extractors of the fields marked with
+	// ReferenceDefaultExtractorMarker, by field path.
+	defaults map[string]string
+}
+
+// ProcessNamed records whether the supplied type is a union struct, i.e. has
+// the ReferenceOneOfMarker.
+func (tp *TypeProcessor) ProcessNamed(n *types.Named, comment string) error {
+	if _, ok := comments.ParseMarkers(comment)[ReferenceOneOfMarker]; !ok {
+		return nil
+	}
+	if _, ok := n.Underlying().(*types.Struct); !ok {
+		return errors.Errorf("%s must be a struct to be a union", n.Obj().Name())
+	}
+	tp.rp.mu.Lock()
+	defer tp.rp.mu.Unlock()
+	if tp.rp.oneOf == nil {
+		tp.rp.oneOf = map[*types.Named]bool{}
+	}
+	tp.rp.oneOf[n] = true
+	return nil
+}
+
+// Process stores the reference information of the given field, if any. Errors
+// are prefixed by the JSON path of the field.
+func (tp *TypeProcessor) Process(n *types.Named, f *types.Var, tag, comment string, parentFields ...string) error {
+	if err := tp.process(n, f, tag, comment, parentFields...); err != nil {
+		return errors.Wrap(err, JSONPath(tp.root, parentFields, f.Name()))
+	}
+	return nil
+}
+
+// process stores the reference information of the given field, if any.
+func (tp *TypeProcessor) process(n *types.Named, f *types.Var, tag, comment string, parentFields ...string) error {
+	rp := tp.rp
+	if tp.structs == nil {
+		tp.structs = map[string]*types.Named{}
+	}
+	tp.structs[fieldKey(parentFields)] = n
+	if f.Embedded() {
+		if tp.embedded == nil {
+			tp.embedded = map[string]bool{}
+		}
+		tp.embedded[fieldKey(append(append([]string{}, parentFields...), f.Name()))] = true
+	}
 	markers := comments.ParseMarkers(comment)
-	refTypeValues := markers[ReferenceTypeMarker]
-	if len(refTypeValues) == 0 {
+	if values, ok := markers[ReferenceDefaultExtractorMarker]; ok {
+		if _, err := getFuncCodeFromPath(values[0]); err != nil {
+			return errors.Wrapf(err, "cannot get default extractor function of field %s", f.Name())
+		}
+		if tp.defaults == nil {
+			tp.defaults = map[string]string{}
+		}
+		tp.defaults[fieldKey(append(append([]string{}, parentFields...), f.Name()))] = values[0]
+	}
+	_, paved := markers[ReferencePavedMarker]
+	_, pavedPaths := markers[ReferencePavedPathsMarker]
+	if paved || pavedPaths {
+		return tp.processPaved(n, f, tag, markers, parentFields...)
+	}
+	if len(markers[ReferenceTypeMarker]) == 0 {
 		return nil
 	}
-	refType := refTypeValues[0]
+
+	var parent *types.Named
+	if _, ok := markers[ReferenceSliceKeyMarker]; ok {
+		if len(parentFields) == 0 || !strings.HasPrefix(parentFields[len(parentFields)-1], "[]") {
+			return errors.Errorf("field %s has a slice key but %s is not an element of a slice", f.Name(), n.Obj().Name())
+		}
+		parent = tp.structs[fieldKey(parentFields[:len(parentFields)-1])]
+	}
+
+	defaultExtractor := tp.inheritedExtractor(parentFields)
+	key := referenceKey{field: f, parent: parent, fingerprint: comment + "\x00" + defaultExtractor}
+	ref, ok := rp.cached(key)
+	if !ok {
+		var err error
+		if ref, err = rp.newReference(n, parent, f, tag, markers, defaultExtractor); err != nil {
+			return err
+		}
+		rp.store(key, ref)
+	}
+
+	path := append([]string{rp.Receiver}, parentFields...)
+	if rp.isUnion(n) {
+		if tp.unions == nil {
+			tp.unions = map[string]*types.Named{}
+		}
+		tp.unions[strings.Join(path, ".")] = n
+	}
+	ref.GoValueFieldPath = append(path, f.Name())
+	tp.refs = append(tp.refs, ref)
+	tp.promoted = append(tp.promoted, tp.promotedKey(parentFields, f.Name()))
+	return nil
+}
+
+// processPaved stores a reference for each key listed by the
+// ReferencePavedMarker, or each path listed by the ReferencePavedPathsMarker,
+// of the supplied map field.
+func (tp *TypeProcessor) processPaved(n *types.Named, f *types.Var, tag string, markers comments.Markers, parentFields ...string) error {
+	refs, err := tp.rp.newPavedReferences(n, f, tag, markers, tp.inheritedExtractor(parentFields))
+	if err != nil {
+		return errors.Wrapf(err, "cannot get paved references of field %s", f.Name())
+	}
+	path := append([]string{tp.rp.Receiver}, parentFields...)
+	for _, ref := range refs {
+		ref.GoValueFieldPath = append(append([]string{}, path...), f.Name())
+		tp.refs = append(tp.refs, ref)
+		tp.promoted = append(tp.promoted, tp.promotedKey(parentFields, f.Name()+"."+ref.Paved.Key))
+	}
+	return nil
+}
+
+// promotedKey returns a key for the path that selects the supplied field of
+// the struct reached through the supplied parent fields once the fields of
+// embedded structs are promoted, i.e. without any embedded fields.
+func (tp *TypeProcessor) promotedKey(parentFields []string, name string) string {
+	path := make([]string, 0, len(parentFields)+1)
+	for i, f := range parentFields {
+		if !tp.embedded[fieldKey(parentFields[:i+1])] {
+			path = append(path, f)
+		}
+	}
+	return strings.Join(append(path, name), ".")
+}
+
+// inheritedExtractor returns the default extractor of the nearest of the
+// supplied parent fields that has one, or an empty string.
+func (tp *TypeProcessor) inheritedExtractor(parentFields []string) string {
+	for i := len(parentFields); i > 0; i-- {
+		if e, ok := tp.defaults[fieldKey(parentFields[:i])]; ok {
+			return e
+		}
+	}
+	return ""
+}
+
+// fieldKey returns a key for the supplied field path, without the prefixes
+// that denote the kind of each field.
+func fieldKey(fields []string) string {
+	cleaned := make([]string, len(fields))
+	for i, f := range fields {
+		cleaned[i] = clean(f)
+	}
+	return strings.Join(cleaned, ".")
+}
+
+// newReference returns the Reference of the supplied field, which has the
+// supplied markers, without its field path. The supplied default extractor is
+// used unless the field specifies its own. The supplied parent is the struct
+// that holds the slice of structs the field is an element of, if the field has
+// a slice key.
+func (rp *ReferenceProcessor) newReference(n, parent *types.Named, f *types.Var, tag string, markers comments.Markers, defaultExtractor string) (Reference, error) {
+	refType := markers[ReferenceTypeMarker][0]
+	listType := refType + "List"
+	if values, ok := markers[ReferenceListTypeMarker]; ok {
+		listType = values[0]
+	}
 	isPointer := false
 	isList := false
 	// We don't support *[]string.
@@ -129,17 +749,22 @@ func (rp *ReferenceProcessor) Process(_ *types.Named, f *types.Var, _, comment s
 		}
 	}
 
-	extractorPath := rp.DefaultExtractor
-	if values, ok := markers[ReferenceExtractorMarker]; ok {
-		var err error
-		extractorPath, err = getFuncCodeFromPath(values[0])
-		if err != nil {
-			return errors.Wrapf(err, "cannot get extractor function")
-		}
+	remoteType, err := getConstructor(n, f, markers, refType)
+	if err != nil {
+		return Reference{}, err
+	}
+	extractorPath, err := rp.getExtractor(markers, tag, defaultExtractor)
+	if err != nil {
+		return Reference{}, err
+	}
+	fromAnnotation, err := getFromAnnotation(markers)
+	if err != nil {
+		return Reference{}, errors.Wrapf(err, "cannot get annotation to extract field %s from", f.Name())
 	}
 
+	_, keyed := markers[ReferenceSliceKeyMarker]
 	refFieldName := f.Name() + "Ref"
-	if isList {
+	if isList || keyed {
 		refFieldName = f.Name() + "Refs"
 	}
 	if values, ok := markers[ReferenceReferenceFieldNameMarker]; ok {
@@ -150,33 +775,997 @@ func (rp *ReferenceProcessor) Process(_ *types.Named, f *types.Var, _, comment s
 	if values, ok := markers[ReferenceSelectorFieldNameMarker]; ok {
 		selectorFieldName = values[0]
 	}
-	path := append([]string{rp.Receiver}, parentFields...)
-	rp.refs = append(rp.refs, Reference{
-		RemoteType:          getTypeCodeFromPath(refType),
-		RemoteListType:      getTypeCodeFromPath(refType, "List"),
-		Extractor:           extractorPath,
-		GoValueFieldPath:    append(path, f.Name()),
-		GoRefFieldName:      refFieldName,
-		GoSelectorFieldName: selectorFieldName,
-		IsPointer:           isPointer,
-		IsSlice:             isList,
-	})
+
+	refOwner, refParents, refFieldName, err := getFieldPath(n, markers, ReferenceReferenceFieldPathMarker, ReferenceReferenceFieldNameMarker, refFieldName)
+	if err != nil {
+		return Reference{}, errors.Wrapf(err, "cannot get reference field of field %s", f.Name())
+	}
+	selectorOwner, selectorParents, selectorFieldName, err := getFieldPath(n, markers, ReferenceSelectorFieldPathMarker, ReferenceSelectorFieldNameMarker, selectorFieldName)
+	if err != nil {
+		return Reference{}, errors.Wrapf(err, "cannot get selector field of field %s", f.Name())
+	}
+	_, noRefWriteBack := markers[ReferenceNoRefWriteBackMarker]
+	if noRefWriteBack && keyed {
+		return Reference{}, errors.Errorf("field %s cannot both use %s and %s", f.Name(), ReferenceNoRefWriteBackMarker, ReferenceSliceKeyMarker)
+	}
+	refless := noRefWriteBack && getField(refOwner, refFieldName) == nil
+	if _, ok := markers[ReferenceSpreadIntoMarker]; ok && refless {
+		return Reference{}, errors.Errorf("field %s has no %s field, so its resolved values cannot be spread", f.Name(), refFieldName)
+	}
+	if len(refParents)+len(selectorParents) > 0 {
+		for _, m := range []string{ReferenceSpreadIntoMarker, ReferenceSliceKeyMarker} {
+			if _, ok := markers[m]; ok {
+				return Reference{}, errors.Errorf("field %s cannot both have a reference or selector field path and use %s", f.Name(), m)
+			}
+		}
+	}
+	var sliceKey *SliceKey
+	if keyed {
+		var err error
+		if sliceKey, err = rp.getSliceKey(n, parent, f, markers[ReferenceSliceKeyMarker][0], refFieldName, isList); err != nil {
+			return Reference{}, errors.Wrapf(err, "cannot get slice key of field %s", f.Name())
+		}
+	}
+	if err := rp.validateFields(refOwner, selectorOwner, f, refFieldName, selectorFieldName, isList, keyed || refless); err != nil {
+		return Reference{}, err
+	}
+	if refless {
+		refFieldName, refParents = "", nil
+	}
+	_, immutable := markers[ReferenceImmutableMarker]
+	var spread *Spread
+	if values, ok := markers[ReferenceSpreadIntoMarker]; ok {
+		if immutable {
+			return Reference{}, errors.Errorf("field %s cannot both use %s and %s", f.Name(), ReferenceImmutableMarker, ReferenceSpreadIntoMarker)
+		}
+		var err error
+		if spread, isPointer, err = getSpread(f, values[0]); err != nil {
+			return Reference{}, errors.Wrapf(err, "cannot spread resolved values of field %s", f.Name())
+		}
+	}
+	composite, err := getComposite(n, f, tag, markers, isList)
+	if err != nil {
+		return Reference{}, errors.Wrapf(err, "cannot get composite key of field %s", f.Name())
+	}
+	mirrors, err := getMirrors(n, f, markers, isList || keyed)
+	if err != nil {
+		return Reference{}, errors.Wrapf(err, "cannot get mirrors of field %s", f.Name())
+	}
+	var format *ValueFormat
+	if values, ok := markers[ReferenceFormatMarker]; ok {
+		var err error
+		if format, err = getValueFormat(values[0], isList); err != nil {
+			return Reference{}, errors.Wrapf(err, "cannot get value format of field %s", f.Name())
+		}
+	}
+
+	validation, err := getValidation(markers, isList)
+	if err != nil {
+		return Reference{}, errors.Wrapf(err, "cannot get validation of field %s", f.Name())
+	}
+
+	normalizer, err := getNormalizer(markers, isList)
+	if err != nil {
+		return Reference{}, errors.Wrapf(err, "cannot get normalizer of field %s", f.Name())
+	}
+
+	when, err := getWhen(n, f, markers)
+	if err != nil {
+		return Reference{}, err
+	}
+
+	timeout, err := getTimeout(markers)
+	if err != nil {
+		return Reference{}, errors.Wrapf(err, "cannot get timeout of field %s", f.Name())
+	}
+
+	fieldSelector, err := getFieldSelector(markers)
+	if err != nil {
+		return Reference{}, errors.Wrapf(err, "cannot get field selector of field %s", f.Name())
+	}
+
+	extractorArg, err := getExtractorArg(n, f, tag, markers)
+	if err != nil {
+		return Reference{}, errors.Wrapf(err, "cannot get extractor argument of field %s", f.Name())
+	}
+
+	isRefValue, isRefPointers := false, false
+	if refField := getField(refOwner, refFieldName); refField != nil && !keyed {
+		switch t := refField.Type().(type) {
+		case *types.Slice:
+			_, isRefPointers = t.Elem().(*types.Pointer)
+		case *types.Pointer:
+		default:
+			isRefValue = !isList
+		}
+	}
+
+	deprecationMessage := getDeprecationMessage(f, markers)
+	_, clusterScoped := markers[ReferenceClusterScopedMarker]
+	_, sameProviderConfig := markers[ReferenceSameProviderConfigMarker]
+	return Reference{
+		RemoteType:             remoteType,
+		RemoteListType:         getTypeCodeFromPath(listType),
+		RemoteTypePath:         refType,
+		RemoteListTypePath:     listType,
+		Pos:                    f.Pos(),
+		Extractor:              extractorPath,
+		GoRefFieldName:         refFieldName,
+		GoSelectorFieldName:    selectorFieldName,
+		GoRefFieldParents:      refParents,
+		GoSelectorFieldParents: selectorParents,
+		GoRefFieldType:         fieldType(refOwner, refFieldName),
+		GoSelectorFieldType:    fieldType(selectorOwner, selectorFieldName),
+		GoReferenceType:        elemType(refOwner, refFieldName),
+		IsRefValue:             isRefValue,
+		IsRefPointers:          isRefPointers,
+		IsPointer:              isPointer,
+		IsSlice:                isList,
+		Format:                 format,
+		DeprecationMessage:     deprecationMessage,
+		ClusterScoped:          clusterScoped,
+		Spread:                 spread,
+		Required:               isRequired(markers, tag),
+		Validation:             validation,
+		Normalizer:             normalizer,
+		SameProviderConfig:     sameProviderConfig,
+		FromAnnotation:         fromAnnotation,
+		SliceKey:               sliceKey,
+		When:                   when,
+		Composite:              composite,
+		NoRefWriteBack:         noRefWriteBack,
+		Timeout:                timeout,
+		Immutable:              immutable,
+		Mirrors:                mirrors,
+		FieldSelector:          fieldSelector,
+		ExtractorArg:           extractorArg,
+	}, nil
+}
+
+// newPavedReferences returns a Reference, without its field path, for each key
+// of the supplied map field that is listed by its ReferencePavedMarker as
+// <key>=<referenced type>, separated by semicolons, or for each field path
+// within its nested maps that is listed by its ReferencePavedPathsMarker as
+// <path>=<referenced type>. The supplied default extractor is used unless the
+// field specifies its own.
+func (rp *ReferenceProcessor) newPavedReferences(n *types.Named, f *types.Var, tag string, markers comments.Markers, defaultExtractor string) ([]Reference, error) {
+	mappings, nested := markers[ReferencePavedMarker], false
+	if values, ok := markers[ReferencePavedPathsMarker]; ok {
+		if mappings != nil {
+			return nil, errors.Errorf("cannot both use %s and %s", ReferencePavedMarker, ReferencePavedPathsMarker)
+		}
+		mappings, nested = values, true
+	}
+	for _, m := range []string{ReferenceTypeMarker, ReferenceListTypeMarker, ReferenceReferenceFieldNameMarker, ReferenceSelectorFieldNameMarker, ReferenceReferenceFieldPathMarker, ReferenceSelectorFieldPathMarker, ReferenceSpreadIntoMarker, ReferenceSliceKeyMarker, ReferenceFormatMarker, ReferenceConstructorMarker, ReferenceNoRefWriteBackMarker, ReferenceNormalizeMarker, ReferenceTimeoutMarker, ReferenceImmutableMarker, ReferenceAlsoSetMarker, ReferenceExtractorArgMarker} {
+		if _, ok := markers[m]; ok {
+			return nil, errors.Errorf("cannot both be paved and use %s", m)
+		}
+	}
+	if rp.isUnion(n) {
+		return nil, errors.Errorf("%s is a union, so its fields cannot be paved", n.Obj().Name())
+	}
+	if m, ok := f.Type().Underlying().(*types.Map); !ok || !types.Identical(m.Key(), types.Typ[types.String]) || !types.IsInterface(m.Elem()) {
+		return nil, errors.New("paved fields must be of type map[string]interface{}")
+	}
+	refSuffix := DefaultPavedRefSuffix
+	if values, ok := markers[ReferencePavedRefSuffixMarker]; ok {
+		if values[0] == "" {
+			return nil, errors.New("suffix of the keys of references must not be empty")
+		}
+		refSuffix = values[0]
+	}
+	// References and selectors held by the map are of the types of the
+	// runtime package, if it is known.
+	var refType, referenceType, selectorType *jen.Statement
+	if rp.RuntimePackagePath != "" {
+		refType = jen.Op("*").Qual(rp.RuntimePackagePath, "Reference")
+		referenceType = jen.Qual(rp.RuntimePackagePath, "Reference")
+		selectorType = jen.Op("*").Qual(rp.RuntimePackagePath, "Selector")
+	}
+	refFieldName := f.Name()
+	refsFieldName := ""
+	if values, ok := markers[ReferencePavedRefsMarker]; ok {
+		if _, ok := markers[ReferencePavedRefSuffixMarker]; ok {
+			return nil, errors.Errorf("cannot both use %s and %s", ReferencePavedRefsMarker, ReferencePavedRefSuffixMarker)
+		}
+		refsFieldName = values[0]
+		refs := getField(n, refsFieldName)
+		if refs == nil {
+			return nil, errors.Errorf("%s has no %s field", n.Obj().Name(), refsFieldName)
+		}
+		m, ok := refs.Type().(*types.Map)
+		if !ok || !types.Identical(m.Key(), types.Typ[types.String]) {
+			return nil, errors.Errorf("field %s of %s must be of type map[string]Reference", refsFieldName, n.Obj().Name())
+		}
+		if rt := xptypes.FindPackage(n.Obj().Pkg(), rp.RuntimePackagePath); rt != nil {
+			if want := rt.Scope().Lookup("Reference"); want != nil && !types.Identical(m.Elem(), want.Type()) {
+				return nil, errors.Errorf("field %s of %s must be of type map[string]%s, not %s", refsFieldName, n.Obj().Name(), types.TypeString(want.Type(), nil), types.TypeString(m, nil))
+			}
+		}
+		refFieldName = refsFieldName
+		refType = jen.Op("*").Add(typeCode(m.Elem()))
+		referenceType = typeCode(m.Elem())
+	}
+
+	extractorPath, err := rp.getExtractor(markers, tag, defaultExtractor)
+	if err != nil {
+		return nil, err
+	}
+	fromAnnotation, err := getFromAnnotation(markers)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get annotation to extract field %s from", f.Name())
+	}
+	validation, err := getValidation(markers, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get validation of field %s", f.Name())
+	}
+	when, err := getWhen(n, f, markers)
+	if err != nil {
+		return nil, err
+	}
+	fieldSelector, err := getFieldSelector(markers)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get field selector of field %s", f.Name())
+	}
+	_, clusterScoped := markers[ReferenceClusterScopedMarker]
+	_, sameProviderConfig := markers[ReferenceSameProviderConfigMarker]
+
+	refs := make([]Reference, 0)
+	seen := map[string]bool{}
+	for _, mapping := range strings.Split(mappings[0], ";") {
+		kv := strings.SplitN(strings.TrimSpace(mapping), "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			if nested {
+				return nil, errors.Errorf("paved path %q must be of the form <path>=<referenced type>", mapping)
+			}
+			return nil, errors.Errorf("paved key %q must be of the form <key>=<referenced type>", mapping)
+		}
+		key, target := kv[0], kv[1]
+		refKey, selectorKey := key+refSuffix, key+DefaultPavedSelectorSuffix
+		if nested {
+			// Paths are keyed as they are formatted, so that the same
+			// path is not listed twice in different ways.
+			p, err := fieldpath.Parse(key)
+			if err != nil {
+				return nil, errors.Wrap(err, "cannot parse paved path")
+			}
+			if len(p) == 0 || p[len(p)-1].Field == "" {
+				return nil, errors.Errorf("paved path %s must end in a field", key)
+			}
+			for _, seg := range p {
+				if seg.Index == fieldpath.Wildcard {
+					return nil, errors.Errorf("paved path %s must not select every element of a slice", key)
+				}
+			}
+			key, refKey, selectorKey = p.String(), p.WithSuffix(refSuffix).String(), p.WithSuffix(DefaultPavedSelectorSuffix).String()
+		}
+		if seen[key] {
+			return nil, errors.Errorf("paved key %s is listed more than once", key)
+		}
+		seen[key] = true
+		paved := &Paved{Key: key, Nested: nested, SelectorKey: selectorKey, RefsFieldName: refsFieldName}
+		if refsFieldName == "" {
+			paved.RefKey = refKey
+		}
+		refs = append(refs, Reference{
+			RemoteType:          getTypeCodeFromPath(target),
+			RemoteListType:      getTypeCodeFromPath(target + "List"),
+			RemoteTypePath:      target,
+			RemoteListTypePath:  target + "List",
+			Pos:                 f.Pos(),
+			Extractor:           extractorPath,
+			GoRefFieldName:      refFieldName,
+			GoSelectorFieldName: f.Name(),
+			GoRefFieldType:      refType,
+			GoSelectorFieldType: selectorType,
+			GoReferenceType:     referenceType,
+			DeprecationMessage:  getDeprecationMessage(f, markers),
+			ClusterScoped:       clusterScoped,
+			Validation:          validation,
+			SameProviderConfig:  sameProviderConfig,
+			FromAnnotation:      fromAnnotation,
+			When:                when,
+			Paved:               paved,
+			FieldSelector:       fieldSelector,
+		})
+	}
+	return refs, nil
+}
+
+// getExtractor returns the extractor of a field with the supplied markers and
+// tag, which is the supplied default extractor, if any, unless the field
+// specifies its own using ReferenceExtractorMarker or ReferenceExtractorTag.
+func (rp *ReferenceProcessor) getExtractor(markers comments.Markers, tag, defaultExtractor string) (*jen.Statement, error) {
+	extractorPath := rp.DefaultExtractor
+	if defaultExtractor != "" {
+		extractorPath, _ = getFuncCodeFromPath(defaultExtractor)
+	}
+	if values, ok := markers[ReferenceExtractorMarker]; ok {
+		if _, ok := reflect.StructTag(tag).Lookup(ReferenceExtractorTag); ok {
+			return nil, errors.Errorf("cannot both use the %s marker and the %s tag", ReferenceExtractorMarker, ReferenceExtractorTag)
+		}
+		var err error
+		extractorPath, err = getFuncCodeFromPath(values[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot get extractor function")
+		}
+	}
+	if value, ok := reflect.StructTag(tag).Lookup(ReferenceExtractorTag); ok {
+		if _, ok := markers[ReferenceFromAnnotationMarker]; ok {
+			return nil, errors.New("cannot both extract from an annotation and use an extractor")
+		}
+		var err error
+		extractorPath, err = getFuncCodeFromPath(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot get extractor function from the %s tag", ReferenceExtractorTag)
+		}
+	}
+	return extractorPath, nil
+}
+
+// getWhen returns the condition supplied by the ReferenceWhenMarker of the
+// supplied field of the supplied struct, if any.
+func getWhen(n *types.Named, f *types.Var, markers comments.Markers) (*jen.Statement, error) {
+	values, ok := markers[ReferenceWhenMarker]
+	if !ok {
+		return nil, nil
+	}
+	if values[0] == "" {
+		return nil, errors.Errorf("condition of field %s must be a function, supplied as <package path>.<name> or as the name of a function in the package of %s", f.Name(), n.Obj().Name())
+	}
+	return getQualifiedFromPath(values[0]), nil
+}
+
+// getConstructor returns a call of the function supplied by the
+// ReferenceConstructorMarker of the supplied field of the supplied struct,
+// which returns a new referenced resource of the supplied type, or a pointer
+// to the zero value of that type if the field has no such marker. A
+// constructor lets the referenced resource be initialized, for example with
+// its GroupVersionKind, before it is read into.
+func getConstructor(n *types.Named, f *types.Var, markers comments.Markers, refType string) (*jen.Statement, error) {
+	values, ok := markers[ReferenceConstructorMarker]
+	if !ok {
+		return getTypeCodeFromPath(refType), nil
+	}
+	if values[0] == "" {
+		return nil, errors.Errorf("constructor of field %s must be a function, supplied as <package path>.<name> or as the name of a function in the package of %s", f.Name(), n.Obj().Name())
+	}
+	return getQualifiedFromPath(values[0]).Call(), nil
+}
+
+// getDeprecationMessage returns the message supplied by the
+// ReferenceDeprecatedMarker of the supplied field, or a default message if the
+// marker has none. It returns an empty string if the field is not deprecated.
+func getDeprecationMessage(f *types.Var, markers comments.Markers) string {
+	values, ok := markers[ReferenceDeprecatedMarker]
+	if !ok {
+		return ""
+	}
+	if values[0] == "" {
+		return f.Name() + " is deprecated."
+	}
+	return values[0]
+}
+
+// getSliceKey returns how the reference of the supplied field of an element of
+// a slice of structs is found by the supplied key field, in the supplied field
+// of keyed references of the supplied parent struct that holds the slice.
+func (rp *ReferenceProcessor) getSliceKey(n, parent *types.Named, f *types.Var, keyFieldName, refFieldName string, isList bool) (*SliceKey, error) {
+	if isList {
+		return nil, errors.New("resolved values of slice fields cannot be found by slice key")
+	}
+	keyField := getField(n, keyFieldName)
+	if keyField == nil {
+		return nil, errors.Errorf("%s has no %s field", n.Obj().Name(), keyFieldName)
+	}
+	if !types.Identical(keyField.Type(), types.Typ[types.String]) {
+		return nil, errors.Errorf("field %s of %s must be a string", keyFieldName, n.Obj().Name())
+	}
+	if parent == nil {
+		return nil, errors.Errorf("cannot find the struct that holds the slice of %s", n.Obj().Name())
+	}
+	refsField := getField(parent, refFieldName)
+	if refsField == nil {
+		return nil, errors.Errorf("field %s is a reference but %s has no %s field", f.Name(), parent.Obj().Name(), refFieldName)
+	}
+	refs, ok := refsField.Type().(*types.Slice)
+	if !ok {
+		return nil, errors.Errorf("field %s of %s must be a slice of keyed references", refFieldName, parent.Obj().Name())
+	}
+	elem, ok := refs.Elem().(*types.Named)
+	if !ok {
+		return nil, errors.Errorf("field %s of %s must be a slice of keyed references", refFieldName, parent.Obj().Name())
+	}
+	nameField, refField := getField(elem, "Name"), getField(elem, "Reference")
+	if nameField == nil || refField == nil || !types.Identical(nameField.Type(), types.Typ[types.String]) {
+		return nil, errors.Errorf("keyed reference %s must have a string Name field and a Reference field", elem.Obj().Name())
+	}
+	refType, ok := refField.Type().(*types.Pointer)
+	if !ok {
+		return nil, errors.Errorf("field Reference of %s must be a pointer", elem.Obj().Name())
+	}
+	if rt := xptypes.FindPackage(n.Obj().Pkg(), rp.RuntimePackagePath); rt != nil {
+		if want := rt.Scope().Lookup("Reference"); want != nil && !types.Identical(refType, types.NewPointer(want.Type())) {
+			return nil, errors.Errorf("field Reference of %s must be of type %s, not %s", elem.Obj().Name(), types.TypeString(types.NewPointer(want.Type()), nil), types.TypeString(refType, nil))
+		}
+	}
+	return &SliceKey{
+		FieldName:       keyFieldName,
+		RefsElementType: typeCode(elem),
+		ReferenceType:   typeCode(refType),
+	}, nil
+}
+
+// typeCode returns the code of the supplied type, which must be a named type
+// or an alias of one, or a pointer to, slice, array, or map of one. Named
+// types and aliases are qualified by the path of their package, so that it is
+// imported if it is not that of the generated file. An alias is referred to by
+// its own name, rather than by that of the type it aliases, which may be of a
+// package that the generated file could not import.
+func typeCode(t types.Type) *jen.Statement {
+	switch t := t.(type) {
+	case *types.Pointer:
+		return jen.Op("*").Add(typeCode(t.Elem()))
+	case *types.Slice:
+		return jen.Index().Add(typeCode(t.Elem()))
+	case *types.Array:
+		return jen.Index(jen.Lit(int(t.Len()))).Add(typeCode(t.Elem()))
+	case *types.Map:
+		return jen.Map(typeCode(t.Key())).Add(typeCode(t.Elem()))
+	case interface{ Obj() *types.TypeName }:
+		// Both *types.Named and *types.Alias, which is not named here so
+		// that this builds with versions of Go that lack it.
+		if t.Obj().Pkg() != nil {
+			return jen.Qual(t.Obj().Pkg().Path(), t.Obj().Name())
+		}
+		return jen.Id(t.Obj().Name())
+	}
+	return jen.Id(types.TypeString(t, nil))
+}
+
+// fieldType returns the code of the type of the named field of the supplied
+// struct, or nil if it has no such field.
+func fieldType(n *types.Named, name string) *jen.Statement {
+	f := getField(n, name)
+	if f == nil {
+		return nil
+	}
+	return typeCode(f.Type())
+}
+
+// elemType returns the type of the named field of the supplied struct, without
+// any pointers or slices, or nil if it has no such field.
+func elemType(n *types.Named, name string) *jen.Statement {
+	f := getField(n, name)
+	if f == nil {
+		return nil
+	}
+	return typeCode(elem(f.Type()))
+}
+
+// getFieldPath returns the struct that holds a reference or selector field,
+// the fields on the path to it from the supplied struct that holds the value
+// field, and its name. The path is supplied by the supplied path marker as the
+// names of the fields separated by dots, for example Refs.SubnetIDRef. The
+// supplied name is returned if the marker isn't set. Fields on the path must be
+// structs or pointers to structs; pointers are nil-guarded when the reference
+// or selector is read, and allocated when it's written.
+func getFieldPath(n *types.Named, markers comments.Markers, pathMarker, nameMarker, name string) (*types.Named, []PathSegment, string, error) {
+	values, ok := markers[pathMarker]
+	if !ok {
+		return n, nil, name, nil
+	}
+	if _, ok := markers[nameMarker]; ok {
+		return nil, nil, "", errors.Errorf("cannot both use %s and %s", pathMarker, nameMarker)
+	}
+	return followFieldPath(n, values[0])
+}
+
+// followFieldPath returns the struct that holds the last field of the supplied
+// path, the fields on the path to it from the supplied struct, and its name.
+// The path is the names of the fields separated by dots. Fields on the path
+// must be structs or pointers to structs.
+func followFieldPath(n *types.Named, path string) (*types.Named, []PathSegment, string, error) {
+	names := strings.Split(path, ".")
+	segments := make([]PathSegment, 0, len(names)-1)
+	owner := n
+	for _, fn := range names[:len(names)-1] {
+		f := getField(owner, fn)
+		if f == nil {
+			return nil, nil, "", errors.Errorf("%s has no %s field", owner.Obj().Name(), fn)
+		}
+		seg := PathSegment{Name: fn}
+		t := f.Type()
+		if p, ok := t.(*types.Pointer); ok {
+			t = p.Elem()
+			seg.Pointer = typeCode(t)
+		}
+		next, ok := t.(*types.Named)
+		if !ok {
+			return nil, nil, "", errors.Errorf("field %s of %s must be a struct or a pointer to a struct", fn, owner.Obj().Name())
+		}
+		if _, ok := next.Underlying().(*types.Struct); !ok {
+			return nil, nil, "", errors.Errorf("field %s of %s must be a struct or a pointer to a struct", fn, owner.Obj().Name())
+		}
+		segments = append(segments, seg)
+		owner = next
+	}
+	return owner, segments, names[len(names)-1], nil
+}
+
+// getMirrors returns the fields that the resolved value of the supplied field of
+// the supplied struct is also written to, as supplied by its
+// ReferenceAlsoSetMarkers. Each is the path of a field from the struct, as
+// supplied to ReferenceReferenceFieldPathMarker, optionally followed by the key
+// of a map in brackets, for example Tags[cluster]. Each field, or the values of
+// its map, must be of the type of the resolved field, or a pointer to it.
+func getMirrors(n *types.Named, f *types.Var, markers comments.Markers, multiple bool) ([]Mirror, error) {
+	values, ok := markers[ReferenceAlsoSetMarker]
+	if !ok {
+		return nil, nil
+	}
+	if multiple {
+		return nil, errors.New("only fields that are resolved to a single value can be mirrored")
+	}
+	want := f.Type()
+	if p, ok := want.(*types.Pointer); ok {
+		want = p.Elem()
+	}
+	mirrors := make([]Mirror, 0, len(values))
+	seen := map[string]bool{}
+	for _, v := range values {
+		path, key := v, ""
+		if i := strings.Index(v, "["); i >= 0 {
+			if !strings.HasSuffix(v, "]") || i == len(v)-2 {
+				return nil, errors.Errorf("mirror %q must be the path of a field, optionally followed by [<key>]", v)
+			}
+			path, key = v[:i], v[i+1:len(v)-1]
+		}
+		if seen[v] {
+			return nil, errors.Errorf("mirror %s is listed more than once", v)
+		}
+		seen[v] = true
+		owner, parents, name, err := followFieldPath(n, path)
+		if err != nil {
+			return nil, err
+		}
+		mf := getField(owner, name)
+		if mf == nil {
+			return nil, errors.Errorf("%s has no %s field", owner.Obj().Name(), name)
+		}
+		if mf == f && key == "" {
+			return nil, errors.Errorf("field %s cannot mirror itself", f.Name())
+		}
+		m := Mirror{Parents: parents, FieldName: name, Key: key}
+		t := mf.Type()
+		if key != "" {
+			mt, ok := t.Underlying().(*types.Map)
+			if !ok || !types.Identical(mt.Key(), types.Typ[types.String]) {
+				return nil, errors.Errorf("field %s of %s must be a map with string keys to set its key %s", name, owner.Obj().Name(), key)
+			}
+			m.MapType = typeCode(t)
+			t = mt.Elem()
+		}
+		if p, ok := t.(*types.Pointer); ok {
+			m.IsPointer, t = true, p.Elem()
+		}
+		if !types.Identical(t, want) {
+			q := types.RelativeTo(n.Obj().Pkg())
+			return nil, errors.Errorf("mirror %s is of type %s, but field %s is resolved to a value of type %s", v, types.TypeString(t, q), f.Name(), types.TypeString(want, q))
+		}
+		mirrors = append(mirrors, m)
+	}
+	return mirrors, nil
+}
+
+// getExtractorArg returns the name of the field supplied by the
+// ReferenceExtractorArgMarker of the supplied field of the supplied struct,
+// whose value is passed to its extractor, if any. The field must be a sibling
+// of the supplied field, and the supplied field must specify the extractor
+// that it is passed to.
+func getExtractorArg(n *types.Named, f *types.Var, tag string, markers comments.Markers) (string, error) {
+	values, ok := markers[ReferenceExtractorArgMarker]
+	if !ok {
+		return "", nil
+	}
+	name := values[0]
+	if name == "" || strings.Contains(name, ".") {
+		return "", errors.Errorf("extractor argument %q must be the name of a field of %s", name, n.Obj().Name())
+	}
+	if _, ok := markers[ReferenceExtractorMarker]; !ok {
+		if _, ok := reflect.StructTag(tag).Lookup(ReferenceExtractorTag); !ok {
+			return "", errors.Errorf("an extractor must be specified using the %s marker or the %s tag to pass it an argument", ReferenceExtractorMarker, ReferenceExtractorTag)
+		}
+	}
+	sibling := getField(n, name)
+	if sibling == nil {
+		return "", errors.Errorf("%s has no %s field", n.Obj().Name(), name)
+	}
+	if sibling == f {
+		return "", errors.New("the extractor cannot be passed the field that it extracts the value of")
+	}
+	return name, nil
+}
+
+// getFromAnnotation returns the annotation key supplied by the
+// ReferenceFromAnnotationMarker, if any.
+func getFromAnnotation(markers comments.Markers) (string, error) {
+	values, ok := markers[ReferenceFromAnnotationMarker]
+	if !ok {
+		return "", nil
+	}
+	if _, ok := markers[ReferenceExtractorMarker]; ok {
+		return "", errors.New("cannot both extract from an annotation and use an extractor")
+	}
+	if values[0] == "" {
+		return "", errors.New("annotation key must not be empty")
+	}
+	return values[0], nil
+}
+
+// isRequired returns true if a field with the supplied markers and tag is
+// marked as required, or is not marked as optional and is not omitted from its
+// JSON representation when empty.
+func isRequired(markers comments.Markers, tag string) bool {
+	if _, ok := markers[KubebuilderRequiredMarker]; ok {
+		return true
+	}
+	if _, ok := markers[KubebuilderOptionalMarker]; ok {
+		return false
+	}
+	json, ok := reflect.StructTag(tag).Lookup("json")
+	if !ok {
+		return false
+	}
+	for _, o := range strings.Split(json, ",")[1:] {
+		if o == "omitempty" {
+			return false
+		}
+	}
+	return true
+}
+
+// A Duplicate is a field that may be resolved from a reference, but that is
+// shadowed by a less deeply embedded field with the same promoted path. Only
+// the shallower field is selected by that path, and serialized to JSON if the
+// embedded structs are inlined, so only it is resolved.
+type Duplicate struct {
+	// Field is the Go path of the field that is not resolved, for example
+	// Spec.ForProvider.Common.Network.SubnetID.
+	Field string
+
+	// ShadowedBy is the Go path of the field that is resolved instead, for
+	// example Spec.ForProvider.Network.SubnetID.
+	ShadowedBy string
+}
+
+// dedupe returns the references accumulated so far, without the duplicates of
+// fields that are reachable through more than one route of embedded structs.
+// Of the references whose fields have the same promoted path only those with
+// the shortest path are kept. More than one is kept if they are equally
+// short, because neither is promoted.
+func (tp *TypeProcessor) dedupe() ([]Reference, []Duplicate) {
+	shortest := map[string]int{}
+	for i, ref := range tp.refs {
+		if j, ok := shortest[tp.promoted[i]]; !ok || len(ref.GoValueFieldPath) < len(tp.refs[j].GoValueFieldPath) {
+			shortest[tp.promoted[i]] = i
+		}
+	}
+	refs := make([]Reference, 0, len(tp.refs))
+	dups := make([]Duplicate, 0)
+	for i, ref := range tp.refs {
+		kept := tp.refs[shortest[tp.promoted[i]]]
+		if len(ref.GoValueFieldPath) > len(kept.GoValueFieldPath) {
+			dups = append(dups, Duplicate{Field: GoPath(ref.GoValueFieldPath[1:]...), ShadowedBy: GoPath(kept.GoValueFieldPath[1:]...)})
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs, dups
+}
+
+// references returns the references accumulated so far, except those of fields
+// that are shadowed, with the members of each union.
+func (tp *TypeProcessor) references() []Reference {
+	refs, _ := tp.dedupe()
+	members := map[string][]int{}
+	keys := make([]string, 0)
+	for i, ref := range refs {
+		key := strings.Join(ref.GoValueFieldPath[:len(ref.GoValueFieldPath)-1], ".")
+		if _, ok := tp.unions[key]; !ok {
+			continue
+		}
+		if _, ok := members[key]; !ok {
+			keys = append(keys, key)
+		}
+		members[key] = append(members[key], i)
+	}
+	for _, key := range keys {
+		union := make([]Reference, len(members[key]))
+		for j, i := range members[key] {
+			union[j] = refs[i]
+		}
+		for j, i := range members[key] {
+			oo := &OneOf{Siblings: getSiblings(tp.unions[key], union, refs[i].GoValueFieldPath[len(refs[i].GoValueFieldPath)-1])}
+			if j == 0 {
+				oo.Members = union
+			}
+			refs[i].OneOf = oo
+		}
+	}
+	return refs
+}
+
+// getSiblings returns the names of the fields of the supplied union that may
+// be nil, other than the supplied member and the reference and selector
+// fields of the supplied references.
+func getSiblings(union *types.Named, refs []Reference, member string) []string {
+	exclude := map[string]bool{member: true}
+	for _, ref := range refs {
+		exclude[ref.GoRefFieldName] = true
+		exclude[ref.GoSelectorFieldName] = true
+	}
+	st := union.Underlying().(*types.Struct)
+	siblings := make([]string, 0)
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if exclude[f.Name()] {
+			continue
+		}
+		switch f.Type().Underlying().(type) {
+		case *types.Pointer, *types.Slice, *types.Map, *types.Interface:
+			siblings = append(siblings, f.Name())
+		}
+	}
+	return siblings
+}
+
+// validateFields returns an error if the reference and selector fields of the
+// supplied reference field are missing, or are not of the types defined by the
+// runtime package. Types are compared by identity, so the runtime package may
+// be imported using any alias. The reference field is not validated if the
+// references are keyed, or if it may be absent.
+func (rp *ReferenceProcessor) validateFields(refOwner, selectorOwner *types.Named, f *types.Var, refFieldName, selectorFieldName string, isList, keyed bool) error {
+	refField := getField(refOwner, refFieldName)
+	if refField == nil && !keyed {
+		return errors.Errorf("field %s is a reference but %s has no %s field", f.Name(), refOwner.Obj().Name(), refFieldName)
+	}
+	selectorField := getField(selectorOwner, selectorFieldName)
+	if selectorField == nil {
+		return errors.Errorf("field %s is a reference but %s has no %s field", f.Name(), selectorOwner.Obj().Name(), selectorFieldName)
+	}
+
+	rt := xptypes.FindPackage(f.Pkg(), rp.RuntimePackagePath)
+	if rt == nil {
+		return nil
+	}
+	refType, selectorType := rt.Scope().Lookup("Reference"), rt.Scope().Lookup("Selector")
+	if refType == nil || selectorType == nil {
+		return nil
+	}
+	// References of single values may also be held by value, and those of
+	// slices by pointer.
+	var wantRef, alternative types.Type = types.NewPointer(refType.Type()), refType.Type()
+	if isList {
+		wantRef, alternative = types.NewSlice(refType.Type()), types.NewSlice(types.NewPointer(refType.Type()))
+	}
+	if !keyed && types.Identical(refField.Type(), alternative) {
+		wantRef = alternative
+	}
+	if !keyed && !types.Identical(refField.Type(), wantRef) {
+		return errors.Errorf("field %s of %s must be of type %s, not %s", refFieldName, refOwner.Obj().Name(), types.TypeString(wantRef, nil), types.TypeString(refField.Type(), nil))
+	}
+	wantSelector := types.NewPointer(selectorType.Type())
+	if !types.Identical(selectorField.Type(), wantSelector) {
+		return errors.Errorf("field %s of %s must be of type %s, not %s", selectorFieldName, selectorOwner.Obj().Name(), types.TypeString(wantSelector, nil), types.TypeString(selectorField.Type(), nil))
+	}
+	return nil
+}
+
+// getField returns the field with the supplied name of the struct underlying
+// the supplied type, including fields promoted from embedded structs, or nil
+// if there is no such field.
+func getField(n *types.Named, name string) *types.Var {
+	if _, ok := n.Underlying().(*types.Struct); !ok {
+		return nil
+	}
+	o, _, _ := types.LookupFieldOrMethod(n, false, n.Obj().Pkg(), name)
+	if f, ok := o.(*types.Var); ok && f.IsField() {
+		return f
+	}
 	return nil
 }
 
-// GetReferences returns all the references accumulated so far from processing.
-func (rp *ReferenceProcessor) GetReferences() []Reference {
-	return rp.refs
+// getSpread returns how resolved values are spread into the supplied field of
+// the elements of the supplied slice of structs, and whether that field is a
+// pointer.
+func getSpread(f *types.Var, elementFieldName string) (*Spread, bool, error) {
+	st, ok := f.Type().(*types.Slice)
+	if !ok {
+		return nil, false, errors.New("resolved values can only be spread into the elements of a slice")
+	}
+	et, ok := st.Elem().(*types.Named)
+	if !ok {
+		return nil, false, errors.New("resolved values can only be spread into a slice of named structs")
+	}
+	ef := getField(et, elementFieldName)
+	if ef == nil {
+		return nil, false, errors.Errorf("%s has no %s field", et.Obj().Name(), elementFieldName)
+	}
+	isPointer := false
+	t := ef.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		isPointer, t = true, p.Elem()
+	}
+	if b, ok := t.(*types.Basic); !ok || b.Kind() != types.String {
+		return nil, false, errors.Errorf("field %s of %s must be of type string or *string", elementFieldName, et.Obj().Name())
+	}
+	return &Spread{ElementFieldName: elementFieldName, ElementType: jen.Qual(et.Obj().Pkg().Path(), et.Obj().Name())}, isPointer, nil
+}
+
+// getComposite returns how the components of a composite key are distributed
+// into the supplied field of the supplied struct and its siblings, as specified
+// by its ReferenceCompositeMarker and its ReferenceCompositeIntoMarker, which
+// lists them as <component>:<field>, separated by commas. It returns nil if the
+// field has no ReferenceCompositeMarker.
+func getComposite(n *types.Named, f *types.Var, tag string, markers comments.Markers, isList bool) (*Composite, error) {
+	values, ok := markers[ReferenceCompositeMarker]
+	if !ok {
+		if _, ok := markers[ReferenceCompositeIntoMarker]; ok {
+			return nil, errors.Errorf("%s requires %s", ReferenceCompositeIntoMarker, ReferenceCompositeMarker)
+		}
+		return nil, nil
+	}
+	if isList {
+		return nil, errors.New("resolved values of slice fields cannot be components of a composite key")
+	}
+	for _, m := range []string{ReferenceExtractorMarker, ReferenceFromAnnotationMarker, ReferenceSpreadIntoMarker, ReferenceSliceKeyMarker, ReferenceFormatMarker} {
+		if _, ok := markers[m]; ok {
+			return nil, errors.Errorf("cannot both resolve a composite key and use %s", m)
+		}
+	}
+	if _, ok := reflect.StructTag(tag).Lookup(ReferenceExtractorTag); ok {
+		return nil, errors.Errorf("cannot both resolve a composite key and use the %s tag", ReferenceExtractorTag)
+	}
+	extractor, err := getFuncCodeFromPath(values[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get extractor function")
+	}
+
+	c := &Composite{Extractor: extractor}
+	seen := map[string]bool{}
+	for _, v := range markers[ReferenceCompositeIntoMarker] {
+		for _, pair := range strings.Split(v, ",") {
+			component, fieldName, ok := strings.Cut(strings.TrimSpace(pair), ":")
+			if !ok || component == "" || fieldName == "" {
+				return nil, errors.Errorf("component %q must be supplied as <component>:<field>", pair)
+			}
+			if seen[fieldName] {
+				return nil, errors.Errorf("field %s is written by more than one component", fieldName)
+			}
+			seen[fieldName] = true
+			if fieldName == f.Name() {
+				c.Value = component
+				continue
+			}
+			tf := getField(n, fieldName)
+			if tf == nil {
+				return nil, errors.Errorf("%s has no %s field", n.Obj().Name(), fieldName)
+			}
+			isPointer := false
+			t := tf.Type()
+			if p, ok := t.(*types.Pointer); ok {
+				isPointer, t = true, p.Elem()
+			}
+			if b, ok := t.(*types.Basic); !ok || b.Kind() != types.String {
+				return nil, errors.Errorf("field %s of %s must be of type string or *string", fieldName, n.Obj().Name())
+			}
+			c.Into = append(c.Into, CompositeTarget{Component: component, FieldName: fieldName, IsPointer: isPointer})
+		}
+	}
+	if c.Value == "" {
+		return nil, errors.Errorf("%s must list the component of field %s", ReferenceCompositeIntoMarker, f.Name())
+	}
+	return c, nil
+}
+
+// getValidation returns the validation of the resolved value specified by the
+// supplied markers, if any.
+func getValidation(markers comments.Markers, isList bool) (*Validation, error) {
+	pattern, hasPattern := markers[ReferenceValidatePatternMarker]
+	validator, hasValidator := markers[ReferenceValidatorMarker]
+	switch {
+	case !hasPattern && !hasValidator:
+		return nil, nil
+	case isList:
+		return nil, errors.New("resolved values of slice fields cannot be validated")
+	case hasPattern && hasValidator:
+		return nil, errors.Errorf("only one of %s and %s may be specified", ReferenceValidatePatternMarker, ReferenceValidatorMarker)
+	case hasPattern:
+		if _, err := regexp.Compile(pattern[0]); err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern %q", pattern[0])
+		}
+		return &Validation{Pattern: pattern[0]}, nil
+	}
+	if validator[0] == "" {
+		return nil, errors.New("validator must be a function, supplied as <package path>.<name>")
+	}
+	return &Validation{Validator: getQualifiedFromPath(validator[0])}, nil
+}
+
+// getNormalizer returns the normalizer of the current value specified by the
+// supplied markers, if any.
+func getNormalizer(markers comments.Markers, isList bool) (*jen.Statement, error) {
+	values, ok := markers[ReferenceNormalizeMarker]
+	switch {
+	case !ok:
+		return nil, nil
+	case isList:
+		return nil, errors.New("current values of slice fields cannot be normalized")
+	case values[0] == "":
+		return nil, errors.New("normalizer must be a function, supplied as <package path>.<name>")
+	}
+	return getQualifiedFromPath(values[0]), nil
+}
+
+// getTimeout returns the timeout of the resolution of a reference specified by
+// the supplied markers as a duration, for example 5s, if any.
+func getTimeout(markers comments.Markers) (time.Duration, error) {
+	values, ok := markers[ReferenceTimeoutMarker]
+	if !ok {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(values[0])
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid timeout %q", values[0])
+	}
+	if d <= 0 {
+		return 0, errors.Errorf("timeout %q must be positive", values[0])
+	}
+	return d, nil
+}
+
+// getFieldSelector returns the field selector expression specified by the
+// supplied markers, if any. It must be a comma separated list of requirements
+// of the form <field>=<value>, <field>==<value>, or <field>!=<value>.
+func getFieldSelector(markers comments.Markers) (string, error) {
+	values, ok := markers[ReferenceFieldSelectorMarker]
+	if !ok {
+		return "", nil
+	}
+	for _, r := range strings.Split(values[0], ",") {
+		if !regexFieldSelectorRequirement.MatchString(strings.TrimSpace(r)) {
+			return "", errors.Errorf("field selector %q must be a comma separated list of <field>=<value>, <field>==<value>, or <field>!=<value> requirements", values[0])
+		}
+	}
+	return values[0], nil
+}
+
+func getValueFormat(template string, isList bool) (*ValueFormat, error) {
+	if isList {
+		return nil, errors.New("formatted values are not supported for slice fields")
+	}
+	if strings.Count(template, FormatPlaceholder) != 1 {
+		return nil, errors.Errorf("template %q must contain %s exactly once", template, FormatPlaceholder)
+	}
+	parts := strings.SplitN(template, FormatPlaceholder, 2)
+	return &ValueFormat{Prefix: parts[0], Suffix: parts[1]}, nil
 }
 
 func getTypeCodeFromPath(path string, nameSuffix ...string) *jen.Statement {
+	return jen.Op("&").Add(getQualifiedFromPath(path, nameSuffix...)).Values()
+}
+
+// getQualifiedFromPath returns the identifier for the supplied path, which may
+// either be a bare name or <package path>.<name>.
+func getQualifiedFromPath(path string, nameSuffix ...string) *jen.Statement {
 	words := strings.Split(path, ".")
 	if len(words) == 1 {
-		return jen.Op("&").Id(path + strings.Join(nameSuffix, "")).Values()
+		return jen.Id(path + strings.Join(nameSuffix, ""))
 	}
 	name := words[len(words)-1] + strings.Join(nameSuffix, "")
 	pkg := strings.TrimSuffix(path, "."+words[len(words)-1])
-	return jen.Op("&").Qual(pkg, name).Values()
+	return jen.Qual(pkg, name)
 }
 
 func getFuncCodeFromPath(path string) (*jen.Statement, error) {
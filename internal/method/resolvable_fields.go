@@ -0,0 +1,351 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package method
+
+import (
+	"go/types"
+	"reflect"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-tools/internal/fieldpath"
+	xptypes "github.com/crossplane/crossplane-tools/internal/types"
+)
+
+// NewResolvableFields returns a function that writes a table of the fields of
+// each of the supplied managed resources that may be resolved from a reference
+// or a selector, and a table of the fields of all of them. Fields are
+// identified by their JSON paths, for example spec.forProvider.subnetId, for
+// use in CEL validation rules.
+func NewResolvableFields(traverser *xptypes.Traverser, runtimePackagePath string) func(f *jen.File, objects []types.Object) {
+	return func(f *jen.File, objects []types.Object) {
+		tables := make([]jen.Code, 0, len(objects))
+		all := make([]jen.Code, 0)
+		for _, o := range objects {
+			n, ok := o.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			refs, err := References(traverser, runtimePackagePath, n)
+			if err != nil {
+				panic(err)
+			}
+			if len(refs) == 0 {
+				continue
+			}
+			rows := make([]jen.Code, len(refs))
+			for i, ref := range refs {
+				rows[i] = resolvableField(n, ref)
+			}
+			all = append(all, rows...)
+			tables = append(tables,
+				jen.Commentf("%sResolvableFields are the fields of %s that may be resolved from a reference or a selector.", o.Name(), o.Name()).Line().
+					Var().Id(o.Name()+"ResolvableFields").Op("=").Index().Id("ResolvableField").ValuesFunc(func(g *jen.Group) {
+					for _, r := range rows {
+						g.Line().Add(r)
+					}
+					g.Line()
+				}),
+			)
+		}
+		if len(all) == 0 {
+			return
+		}
+
+		f.Comment("A ResolvableField is a field of a managed resource that may be resolved")
+		f.Comment("from a reference or a selector.")
+		f.Type().Id("ResolvableField").Struct(
+			jen.Comment("Kind of the managed resource."),
+			jen.Id("Kind").String(),
+			jen.Line(),
+			jen.Comment("Value is the JSON path of the field."),
+			jen.Id("Value").String(),
+			jen.Line(),
+			jen.Comment("Ref is the JSON path of the reference to resolve the field from."),
+			jen.Id("Ref").String(),
+			jen.Line(),
+			jen.Comment("Selector is the JSON path of the selector to resolve the field from."),
+			jen.Id("Selector").String(),
+			jen.Line(),
+			jen.Comment("Required tells whether the field is required."),
+			jen.Id("Required").Bool(),
+		)
+		for _, t := range tables {
+			f.Add(t)
+		}
+		f.Comment("ResolvableFields are the fields of all managed resources in this package")
+		f.Comment("that may be resolved from a reference or a selector.")
+		f.Var().Id("ResolvableFields").Op("=").Index().Id("ResolvableField").ValuesFunc(func(g *jen.Group) {
+			for _, r := range all {
+				g.Line().Add(r)
+			}
+			g.Line()
+		})
+	}
+}
+
+// References returns the references of the supplied managed resource.
+func References(traverser *xptypes.Traverser, runtimePackagePath string, n *types.Named) ([]Reference, error) {
+	rp, err := processReferences(traverser, runtimePackagePath, n)
+	if err != nil {
+		return nil, err
+	}
+	return rp.ReferencesFor(n), nil
+}
+
+// Duplicates returns the fields of the supplied managed resource that have
+// references, but that are not resolved because they are shadowed by less
+// deeply embedded fields.
+func Duplicates(traverser *xptypes.Traverser, runtimePackagePath string, n *types.Named) ([]Duplicate, error) {
+	rp, err := processReferences(traverser, runtimePackagePath, n)
+	if err != nil {
+		return nil, err
+	}
+	return rp.DuplicatesFor(n), nil
+}
+
+// processReferences returns a ReferenceProcessor that has processed the type
+// tree of the supplied managed resource.
+func processReferences(traverser *xptypes.Traverser, runtimePackagePath string, n *types.Named) (*ReferenceProcessor, error) {
+	rp := NewReferenceProcessor("", WithRuntimePackagePath(runtimePackagePath))
+	tp := rp.For(n)
+	cfg := &xptypes.ProcessorConfig{
+		Field: tp,
+		Named: xptypes.NamedProcessorFn(tp.ProcessNamed),
+	}
+	if err := traverser.Traverse(n, cfg); err != nil {
+		return nil, errors.Wrapf(err, "cannot traverse the type tree of %s", n.Obj().Name())
+	}
+	return rp, nil
+}
+
+// UnserializedFields returns the Go paths of the fields of the supplied managed
+// resource that may be resolved from a reference or a selector, or that hold
+// its reference or selector, but that are not serialized to JSON because they
+// or one of their parents are tagged json:"-". Their JSON paths use their Go
+// names instead.
+func UnserializedFields(traverser *xptypes.Traverser, runtimePackagePath string, n *types.Named) ([]string, error) {
+	refs, err := References(traverser, runtimePackagePath, n)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]string, 0)
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		parents := ref.GoValueFieldPath[1 : len(ref.GoValueFieldPath)-1]
+		for _, f := range []struct {
+			parents []string
+			name    string
+		}{
+			{parents: parents, name: ref.GoValueFieldPath[len(ref.GoValueFieldPath)-1]},
+			{parents: refParents(ref, parents), name: ref.GoRefFieldName},
+			{parents: selectorParents(ref, parents), name: ref.GoSelectorFieldName},
+		} {
+			if f.name == "" {
+				// The reference has no reference field.
+				continue
+			}
+			_, unserialized := jsonPath(n, f.parents, f.name)
+			for _, u := range unserialized {
+				if !seen[u] {
+					seen[u] = true
+					fields = append(fields, u)
+				}
+			}
+		}
+	}
+	return fields, nil
+}
+
+// resolvableField returns a ResolvableField literal for the supplied reference
+// of the supplied managed resource.
+func resolvableField(n *types.Named, ref Reference) *jen.Statement {
+	parents, value := ref.GoValueFieldPath[1:len(ref.GoValueFieldPath)-1], ref.GoValueFieldPath[len(ref.GoValueFieldPath)-1]
+	if ref.Paved != nil {
+		return pavedResolvableField(n, ref, parents, value)
+	}
+	refPath := ""
+	if ref.GoRefFieldName != "" {
+		refPath = JSONPath(n, refParents(ref, parents), ref.GoRefFieldName)
+	}
+	return jen.Values(
+		jen.Id("Kind").Op(":").Lit(n.Obj().Name()),
+		jen.Id("Value").Op(":").Lit(valueJSONPath(n, ref)),
+		jen.Id("Ref").Op(":").Lit(refPath),
+		jen.Id("Selector").Op(":").Lit(JSONPath(n, selectorParents(ref, parents), ref.GoSelectorFieldName)),
+		jen.Id("Required").Op(":").Lit(ref.Required),
+	)
+}
+
+// pavedResolvableField returns a ResolvableField literal for the supplied
+// reference of a key of the supplied paved map field. Keys of the map are
+// named as fields of it.
+func pavedResolvableField(n *types.Named, ref Reference, parents []string, value string) *jen.Statement {
+	mapParents := append(append([]string{}, parents...), value)
+	refPath := pavedJSONPath(n, mapParents, ref.Paved, ref.Paved.RefKey)
+	if ref.Paved.RefsFieldName != "" {
+		refPath = JSONPath(n, append(append([]string{}, parents...), ref.Paved.RefsFieldName), ref.Paved.Key)
+	}
+	return jen.Values(
+		jen.Id("Kind").Op(":").Lit(n.Obj().Name()),
+		jen.Id("Value").Op(":").Lit(valueJSONPath(n, ref)),
+		jen.Id("Ref").Op(":").Lit(refPath),
+		jen.Id("Selector").Op(":").Lit(pavedJSONPath(n, mapParents, ref.Paved, ref.Paved.SelectorKey)),
+		jen.Id("Required").Op(":").Lit(ref.Required),
+	)
+}
+
+// valueJSONPath returns the JSON path of the field of the supplied reference of
+// the supplied managed resource, or of its key if it is a key of a paved map.
+func valueJSONPath(n *types.Named, ref Reference) string {
+	parents, value := ref.GoValueFieldPath[1:len(ref.GoValueFieldPath)-1], ref.GoValueFieldPath[len(ref.GoValueFieldPath)-1]
+	if ref.Paved != nil {
+		return pavedJSONPath(n, append(append([]string{}, parents...), value), ref.Paved, ref.Paved.Key)
+	}
+	return JSONPath(n, parents, value)
+}
+
+// pavedJSONPath returns the JSON path of the supplied key of the supplied paved
+// map field, which is reached by traversing the supplied fields of the supplied
+// type. The keys of nested paved references are paths within the map.
+func pavedJSONPath(n *types.Named, mapFields []string, paved *Paved, key string) string {
+	if !paved.Nested {
+		return JSONPath(n, mapFields, key)
+	}
+	return appendPath(JSONPath(n, mapFields[:len(mapFields)-1], mapFields[len(mapFields)-1]), key)
+}
+
+// refParents returns the parent fields of the reference field of the supplied
+// reference, which has the supplied parent value fields. The keyed references
+// of a field with a slice key are held by the struct that holds its slice.
+func refParents(ref Reference, parents []string) []string {
+	if ref.SliceKey != nil {
+		return parents[:len(parents)-1]
+	}
+	return withSegments(parents, ref.GoRefFieldParents)
+}
+
+// selectorParents returns the parent fields of the selector field of the
+// supplied reference, which has the supplied parent value fields.
+func selectorParents(ref Reference, parents []string) []string {
+	return withSegments(parents, ref.GoSelectorFieldParents)
+}
+
+// withSegments returns the supplied parent fields followed by the supplied
+// path segments.
+func withSegments(parents []string, segments []PathSegment) []string {
+	if len(segments) == 0 {
+		return parents
+	}
+	out := append([]string{}, parents...)
+	for _, s := range segments {
+		out = append(out, s.Name)
+	}
+	return out
+}
+
+// JSONPath returns the JSON path of the supplied field of the struct that is
+// reached by traversing the supplied parent fields of the supplied type, for
+// example spec.forProvider.items[*].subnetId. Parent fields are named as they
+// are by the Traverser. Embedded structs that are inlined, either explicitly
+// with json:",inline" or because they have no JSON name, are omitted. Fields
+// tagged json:"-" aren't serialized, so they are named by their Go names; see
+// UnserializedFields. JSON names that contain dots or slashes are enclosed in
+// brackets, as in crossplane-runtime's field paths.
+func JSONPath(n *types.Named, parentFields []string, field string) string {
+	path, _ := jsonPath(n, parentFields, field)
+	return path
+}
+
+// jsonPath returns the JSON path of the supplied field, and the Go paths of
+// the fields along it that are tagged json:"-".
+func jsonPath(n *types.Named, parentFields []string, field string) (string, []string) {
+	path := make(fieldpath.Path, 0, len(parentFields)+1)
+	goPath := make([]string, 0, len(parentFields)+1)
+	unserialized := make([]string, 0)
+	t := types.Type(n)
+	for _, pf := range append(append([]string{}, parentFields...), field) {
+		name := clean(pf)
+		goPath = append(goPath, pf)
+		seg, skip, ft := jsonField(t, name)
+		if skip {
+			unserialized = append(unserialized, GoPath(goPath...))
+		}
+		if seg != "" {
+			path = append(path, fieldpath.Field(seg))
+			if strings.HasPrefix(pf, "[]") {
+				path = append(path, fieldpath.All())
+			}
+		}
+		if strings.HasPrefix(pf, "(") {
+			impl := pf[1:strings.Index(pf, ")")]
+			if o := n.Obj().Pkg().Scope().Lookup(impl); o != nil {
+				ft = o.Type()
+			}
+		}
+		t = elem(ft)
+	}
+	return path.String(), unserialized
+}
+
+// jsonField returns the JSON name and type of the supplied field of the
+// supplied struct type, and whether the field is tagged json:"-". The name is
+// empty if the field is inlined, and is the Go name of the field if it is not
+// serialized.
+func jsonField(t types.Type, name string) (string, bool, types.Type) {
+	if t == nil {
+		return name, false, nil
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return name, false, nil
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if f.Name() != name {
+			continue
+		}
+		tag := reflect.StructTag(st.Tag(i)).Get("json")
+		json := strings.Split(tag, ",")[0]
+		switch {
+		case tag == "-":
+			return name, true, f.Type()
+		case json == "" && f.Embedded():
+			return "", false, f.Type()
+		case json == "":
+			return name, false, f.Type()
+		}
+		return json, false, f.Type()
+	}
+	return name, false, nil
+}
+
+// elem returns the supplied type, without any pointers or slices.
+func elem(t types.Type) types.Type {
+	for {
+		switch tt := t.(type) {
+		case *types.Pointer:
+			t = tt.Elem()
+		case *types.Slice:
+			t = tt.Elem()
+		default:
+			return t
+		}
+	}
+}
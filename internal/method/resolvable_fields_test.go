@@ -0,0 +1,321 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package method
+
+import (
+	"fmt"
+	"go/types"
+	"testing"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/google/go-cmp/cmp"
+
+	xptypes "github.com/crossplane/crossplane-tools/internal/types"
+	"github.com/crossplane/crossplane-tools/pkg/comments"
+)
+
+func TestNewResolvableFields(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ResourceSpec struct {
+	// +crossplane:generate:reference:type=Config
+	ConfigName *string ` + "`json:\"configName,omitempty\"`" + `
+
+	ConfigNameRef *Reference ` + "`json:\"configNameRef,omitempty\"`" + `
+
+	ConfigNameSelector *Selector ` + "`json:\"configNameSelector,omitempty\"`" + `
+}
+
+type Item struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +kubebuilder:validation:Required
+	SubnetID *string ` + "`json:\"subnetId,omitempty\"`" + `
+
+	SubnetIDRef *Reference ` + "`json:\"subnetIdRef,omitempty\"`" + `
+
+	SubnetIDSelector *Selector ` + "`json:\"subnetIdSelector,omitempty\"`" + `
+}
+
+type ModelParameters struct {
+	Items []Item ` + "`json:\"items\"`" + `
+
+	// +crossplane:generate:reference:type=SecurityGroup
+	SecurityGroupIDs []string ` + "`json:\"securityGroupIds\"`" + `
+
+	SecurityGroupIDsRefs []Reference ` + "`json:\"securityGroupIdRefs,omitempty\"`" + `
+
+	SecurityGroupIDsSelector *Selector ` + "`json:\"securityGroupIdSelector,omitempty\"`" + `
+
+	// +crossplane:generate:reference:paved=vpcId=VPC
+	Parameters map[string]interface{} ` + "`json:\"parameters,omitempty\"`" + `
+
+	// +crossplane:generate:reference:paved=roleArn=Role
+	// +crossplane:generate:reference:pavedRefs=SettingsRefs
+	Settings map[string]interface{} ` + "`json:\"settings,omitempty\"`" + `
+
+	SettingsRefs map[string]Reference ` + "`json:\"settingsRefs,omitempty\"`" + `
+
+	// +crossplane:generate:reference:pavedPaths=network.subnets[0].id=Subnet
+	Properties map[string]interface{} ` + "`json:\"properties,omitempty\"`" + `
+}
+
+type ModelSpec struct {
+	ResourceSpec ` + "`json:\",inline\"`" + `
+	ForProvider ModelParameters ` + "`json:\"forProvider\"`" + `
+}
+
+type Model struct {
+	Spec ModelSpec ` + "`json:\"spec\"`" + `
+}
+
+type OtherSpec struct {
+	// +crossplane:generate:reference:type=VPC
+	VPCID *string ` + "`json:\"vpcId,omitempty\"`" + `
+
+	VPCIDRef *Reference ` + "`json:\"vpcIdRef,omitempty\"`" + `
+
+	VPCIDSelector *Selector ` + "`json:\"vpcIdSelector,omitempty\"`" + `
+}
+
+type Other struct {
+	Spec *OtherSpec ` + "`json:\"spec\"`" + `
+}
+
+type NoReferences struct {
+	Spec string ` + "`json:\"spec\"`" + `
+}
+`
+	p := loadPackage(t, source)
+	f := jen.NewFilePath("golang.org/fake/v1alpha1")
+	objects := []types.Object{p.Types.Scope().Lookup("Model"), p.Types.Scope().Lookup("NoReferences"), p.Types.Scope().Lookup("Other")}
+	NewResolvableFields(xptypes.NewTraverser(comments.In(p)), "")(f, objects)
+
+	want := `package v1alpha1
+
+// A ResolvableField is a field of a managed resource that may be resolved
+// from a reference or a selector.
+type ResolvableField struct {
+	// Kind of the managed resource.
+	Kind string
+
+	// Value is the JSON path of the field.
+	Value string
+
+	// Ref is the JSON path of the reference to resolve the field from.
+	Ref string
+
+	// Selector is the JSON path of the selector to resolve the field from.
+	Selector string
+
+	// Required tells whether the field is required.
+	Required bool
+}
+
+// ModelResolvableFields are the fields of Model that may be resolved from a reference or a selector.
+var ModelResolvableFields = []ResolvableField{
+	{Kind: "Model", Value: "spec.configName", Ref: "spec.configNameRef", Selector: "spec.configNameSelector", Required: false},
+	{Kind: "Model", Value: "spec.forProvider.items[*].subnetId", Ref: "spec.forProvider.items[*].subnetIdRef", Selector: "spec.forProvider.items[*].subnetIdSelector", Required: true},
+	{Kind: "Model", Value: "spec.forProvider.securityGroupIds", Ref: "spec.forProvider.securityGroupIdRefs", Selector: "spec.forProvider.securityGroupIdSelector", Required: true},
+	{Kind: "Model", Value: "spec.forProvider.parameters.vpcId", Ref: "spec.forProvider.parameters.vpcIdRef", Selector: "spec.forProvider.parameters.vpcIdSelector", Required: false},
+	{Kind: "Model", Value: "spec.forProvider.settings.roleArn", Ref: "spec.forProvider.settingsRefs.roleArn", Selector: "spec.forProvider.settings.roleArnSelector", Required: false},
+	{Kind: "Model", Value: "spec.forProvider.properties.network.subnets[0].id", Ref: "spec.forProvider.properties.network.subnets[0].idRef", Selector: "spec.forProvider.properties.network.subnets[0].idSelector", Required: false},
+}
+
+// OtherResolvableFields are the fields of Other that may be resolved from a reference or a selector.
+var OtherResolvableFields = []ResolvableField{
+	{Kind: "Other", Value: "spec.vpcId", Ref: "spec.vpcIdRef", Selector: "spec.vpcIdSelector", Required: false},
+}
+
+// ResolvableFields are the fields of all managed resources in this package
+// that may be resolved from a reference or a selector.
+var ResolvableFields = []ResolvableField{
+	{Kind: "Model", Value: "spec.configName", Ref: "spec.configNameRef", Selector: "spec.configNameSelector", Required: false},
+	{Kind: "Model", Value: "spec.forProvider.items[*].subnetId", Ref: "spec.forProvider.items[*].subnetIdRef", Selector: "spec.forProvider.items[*].subnetIdSelector", Required: true},
+	{Kind: "Model", Value: "spec.forProvider.securityGroupIds", Ref: "spec.forProvider.securityGroupIdRefs", Selector: "spec.forProvider.securityGroupIdSelector", Required: true},
+	{Kind: "Model", Value: "spec.forProvider.parameters.vpcId", Ref: "spec.forProvider.parameters.vpcIdRef", Selector: "spec.forProvider.parameters.vpcIdSelector", Required: false},
+	{Kind: "Model", Value: "spec.forProvider.settings.roleArn", Ref: "spec.forProvider.settingsRefs.roleArn", Selector: "spec.forProvider.settings.roleArnSelector", Required: false},
+	{Kind: "Model", Value: "spec.forProvider.properties.network.subnets[0].id", Ref: "spec.forProvider.properties.network.subnets[0].idRef", Selector: "spec.forProvider.properties.network.subnets[0].idSelector", Required: false},
+	{Kind: "Other", Value: "spec.vpcId", Ref: "spec.vpcIdRef", Selector: "spec.vpcIdSelector", Required: false},
+}
+`
+	if diff := cmp.Diff(want, fmt.Sprintf("%#v", f)); diff != "" {
+		t.Errorf("NewResolvableFields(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	source := `
+package v1alpha1
+
+type CommonParameters struct {
+	Region string ` + "`json:\"region\"`" + `
+}
+
+type Tags struct {
+	Key string ` + "`json:\"key\"`" + `
+}
+
+type ModelParameters struct {
+	CommonParameters ` + "`json:\",inline\"`" + `
+	*Tags
+
+	Hidden   string ` + "`json:\"-\"`" + `
+	Dash     string ` + "`json:\"-,\"`" + `
+	Untagged string
+
+	Dotted  string ` + "`json:\"example.org/name\"`" + `
+	Rules   []Rule ` + "`json:\"rules\"`" + `
+}
+
+type Rule struct {
+	Größe string ` + "`json:\"größe\"`" + `
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters ` + "`json:\"forProvider\"`" + `
+}
+
+type Model struct {
+	Spec ModelSpec ` + "`json:\"spec\"`" + `
+}
+`
+	n := loadPackage(t, source).Types.Scope().Lookup("Model").Type().(*types.Named)
+
+	cases := map[string]struct {
+		reason  string
+		parents []string
+		field   string
+		want    string
+	}{
+		"InlineEmbedded": {
+			reason:  "A struct embedded with json:\",inline\" should not contribute a path segment.",
+			parents: []string{"Spec", "ForProvider", "CommonParameters"},
+			field:   "Region",
+			want:    "spec.forProvider.region",
+		},
+		"UntaggedEmbeddedPointer": {
+			reason:  "A struct pointer embedded without a JSON name should not contribute a path segment.",
+			parents: []string{"Spec", "ForProvider", "*Tags"},
+			field:   "Key",
+			want:    "spec.forProvider.key",
+		},
+		"NotSerialized": {
+			reason:  "A field tagged json:\"-\" should be named by its Go name.",
+			parents: []string{"Spec", "ForProvider"},
+			field:   "Hidden",
+			want:    "spec.forProvider.Hidden",
+		},
+		"NamedDash": {
+			reason:  "A field tagged json:\"-,\" should be named -.",
+			parents: []string{"Spec", "ForProvider"},
+			field:   "Dash",
+			want:    "spec.forProvider.-",
+		},
+		"Untagged": {
+			reason:  "A field without a JSON name should be named by its Go name.",
+			parents: []string{"Spec", "ForProvider"},
+			field:   "Untagged",
+			want:    "spec.forProvider.Untagged",
+		},
+		"Dotted": {
+			reason:  "A field whose JSON name contains dots and slashes should be enclosed in brackets.",
+			parents: []string{"Spec", "ForProvider"},
+			field:   "Dotted",
+			want:    "spec.forProvider[example.org/name]",
+		},
+		"Slice": {
+			reason:  "A field of the elements of a slice should select every element.",
+			parents: []string{"Spec", "ForProvider", "[]Rules"},
+			field:   "Größe",
+			want:    "spec.forProvider.rules[*].größe",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := JSONPath(n, tc.parents, tc.field)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nJSONPath(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUnserializedFields(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type CommonParameters struct {
+	// +crossplane:generate:reference:type=VPC
+	VPCID *string ` + "`json:\"vpcId,omitempty\"`" + `
+
+	VPCIDRef *Reference ` + "`json:\"vpcIdRef,omitempty\"`" + `
+
+	VPCIDSelector *Selector ` + "`json:\"vpcIdSelector,omitempty\"`" + `
+}
+
+type HiddenParameters struct {
+	// +crossplane:generate:reference:type=Role
+	RoleARN *string ` + "`json:\"roleArn,omitempty\"`" + `
+
+	RoleARNRef *Reference ` + "`json:\"roleArnRef,omitempty\"`" + `
+
+	RoleARNSelector *Selector ` + "`json:\"roleArnSelector,omitempty\"`" + `
+}
+
+type ModelParameters struct {
+	CommonParameters ` + "`json:\",inline\"`" + `
+
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string ` + "`json:\"subnetId,omitempty\"`" + `
+
+	SubnetIDRef *Reference ` + "`json:\"-\"`" + `
+
+	SubnetIDSelector *Selector ` + "`json:\"subnetIdSelector,omitempty\"`" + `
+
+	Hidden HiddenParameters ` + "`json:\"-\"`" + `
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters ` + "`json:\"forProvider\"`" + `
+}
+
+type Model struct {
+	Spec ModelSpec ` + "`json:\"spec\"`" + `
+}
+`
+	p := loadPackage(t, source)
+	n := p.Types.Scope().Lookup("Model").Type().(*types.Named)
+
+	want := []string{"Spec.ForProvider.SubnetIDRef", "Spec.ForProvider.Hidden"}
+	got, err := UnserializedFields(xptypes.NewTraverser(comments.In(p)), "", n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("UnserializedFields(...): -want, +got\n%s", diff)
+	}
+}
@@ -17,190 +17,2604 @@ limitations under the License.
 package method
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"go/types"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
+	"github.com/crossplane/crossplane-tools/internal/fieldpath"
 	xptypes "github.com/crossplane/crossplane-tools/internal/types"
 
 	"github.com/dave/jennifer/jen"
 )
 
+type resolveReferencesOptions struct {
+	DeprecationRecorder     *jen.Statement
+	ProviderConfigValidator *jen.Statement
+	Tenant                  *jen.Statement
+	Resolver                *jen.Statement
+	RuntimePackagePath      string
+	ResourcePackagePath     string
+	Namespaced              func(o types.Object) bool
+	SelectorsDisabled       func(o types.Object) bool
+	ResolvedValues          bool
+	ClearSelectors          bool
+	SkipUnchanged           string
+	Cache                   func(o types.Object) string
+	WrapWithMessage         bool
+	ErrorWrapper            *jen.Statement
+	ReferenceError          bool
+	LoggingPackagePath      string
+	DependencyAnnotation    string
+	MetaPackagePath         string
+	ControllerRuntime       bool
+	FieldPathPackagePath    string
+	ProvenancePackagePath   string
+	Assertions              bool
+	SkipEmpty               bool
+	FailureCondition        *failureCondition
+	Clock                   *jen.Statement
+	Transactional           bool
+	RetryPackagePath        string
+	Backoff                 *Backoff
+}
+
+// A Backoff configures how a generated method retries a resolution that fails.
+// It is generated as a Backoff of the retry package.
+type Backoff struct {
+	// Duration is how long the first retry waits.
+	Duration time.Duration
+
+	// Factor multiplies the duration that each retry waits after the first.
+	Factor float64
+
+	// Steps is how many times the resolution is tried at most.
+	Steps int
+}
+
+// A failureCondition is set on a managed resource when resolving its
+// references fails.
+type failureCondition struct {
+	Type   string
+	Reason string
+}
+
+// managedOptions configures the resolution calls generated for a particular
+// managed resource.
+type managedOptions struct {
+	// Type is the type of the managed resource.
+	Type *types.Named
+
+	// Namespaced tells whether the managed resource is namespace scoped.
+	Namespaced bool
+
+	// SelectorsDisabled tells whether references of the managed resource may
+	// only be resolved by name.
+	SelectorsDisabled bool
+
+	// ResolvedValues tells whether resolved values are recorded in a map
+	// that is returned by the generated method.
+	ResolvedValues bool
+
+	// ClearSelectors tells whether selectors are cleared when a reference is
+	// resolved by name.
+	ClearSelectors bool
+
+	// Tenant tells whether references are resolved in the namespace of the
+	// tenant returned by the tenant function.
+	Tenant bool
+
+	// SkipUnchanged is the annotation that a hash of the references and
+	// selectors of the managed resource is stored in, if resolution should
+	// be skipped while they are unchanged.
+	SkipUnchanged string
+
+	// Cache is the status map that a hash of the reference, selector, and
+	// value of each field is cached in once it is resolved, if resolution of
+	// the field should be skipped while they are unchanged.
+	Cache *jen.Statement
+
+	// WrapWithMessage tells whether errors returned while resolving a field
+	// are wrapped with its path using errors.WithMessage, rather than
+	// errors.Wrap.
+	WrapWithMessage bool
+
+	// ErrorWrapper is the function that errors returned while resolving a
+	// field are wrapped with its path by, if it is not errors.Wrap or
+	// errors.WithMessage.
+	ErrorWrapper *jen.Statement
+
+	// ReferenceError tells whether errors returned while resolving a field
+	// are returned as a ReferenceError of the package of the managed
+	// resource, rather than wrapped.
+	ReferenceError bool
+
+	// DependencyAnnotation is the annotation that the resolved references
+	// of the managed resource are recorded in, if they should be.
+	DependencyAnnotation string
+
+	// SkipEmpty tells whether fields whose reference and selector are both
+	// unset are skipped, rather than resolved to their current values.
+	SkipEmpty bool
+
+	// FailureCondition sets a condition of the managed resource, with a
+	// message of err, before an error is returned, if it should be.
+	FailureCondition *jen.Statement
+
+	// Clock is the value whose Now method tells the current time, if it is
+	// not told by the time package.
+	Clock *jen.Statement
+
+	// Retry is the function that resolutions are retried by while they
+	// fail, if they should be.
+	Retry *jen.Statement
+
+	// Backoff is the backoff that Retry is called with.
+	Backoff *jen.Statement
+
+	// Locals are the names of the variables of the generated method.
+	Locals locals
+}
+
+// A ResolveReferencesOption configures the generated ResolveReferences method.
+type ResolveReferencesOption func(o *resolveReferencesOptions)
+
+// WithDeprecationRecorder specifies a function that the generated method will
+// call when the reference or selector of a deprecated reference is set. The
+// function is supplied as <package path>.<name>, and must have the signature
+// func(ctx context.Context, mg resource.Managed, field, message string).
+func WithDeprecationRecorder(path string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.DeprecationRecorder = getQualifiedFromPath(path)
+	}
+}
+
+// WithProviderConfigValidator specifies a function that the generated method
+// will call with each resource that is resolved by a reference that requires
+// the same provider config as the referencing resource. The function is
+// supplied as <package path>.<name>, and must have the signature
+// func(ctx context.Context, c client.Reader, mg resource.Managed, ref *xpv1.Reference, to resource.Managed) error.
+// It should get the referenced resource into to, and return an error if its
+// provider config differs from that of mg.
+func WithProviderConfigValidator(path string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.ProviderConfigValidator = getQualifiedFromPath(path)
+	}
+}
+
+// WithTenant specifies a function that the generated method will call to get
+// the namespace of the tenant from its context. The function is supplied as
+// <package path>.<name>, and must have the signature
+// func(ctx context.Context) string. References that are not to cluster scoped
+// types are resolved in the namespace it returns, even if the managed resource
+// is namespace scoped.
+func WithTenant(path string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.Tenant = getQualifiedFromPath(path)
+	}
+}
+
+// WithResolver specifies a function that constructs the resolver that the
+// generated method resolves references with, rather than the APIResolver of
+// the reference package, for example to resolve references with an external
+// service. The function is supplied as <package path>.<name>, and must have
+// the signature func(c client.Reader, mg resource.Managed) R, where R has the
+// Resolve and ResolveMultiple methods of APIResolver. It is supplied the
+// references and selectors of the fields that are resolved in their
+// resolution requests. It cannot be combined with WithControllerRuntime.
+func WithResolver(path string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.Resolver = getQualifiedFromPath(path)
+	}
+}
+
+// WithRuntime specifies the path of the crossplane-runtime package that
+// defines the Reference and Selector types, for example
+// github.com/crossplane/crossplane-runtime/apis/common/v1. Reference and
+// selector fields must be of these types if the package is imported.
+func WithRuntime(path string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.RuntimePackagePath = path
+	}
+}
+
+// WithResource specifies the path of the crossplane-runtime package that defines
+// the Managed interface, for example
+// github.com/crossplane/crossplane-runtime/pkg/resource. It is required by
+// references whose values are extracted from an annotation of the referenced
+// resource.
+func WithResource(path string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.ResourcePackagePath = path
+	}
+}
+
+// WithFieldPath specifies the path of the crossplane-runtime package that
+// defines Pave, for example
+// github.com/crossplane/crossplane-runtime/pkg/fieldpath. It is required by
+// references of keys of paved map fields.
+func WithFieldPath(path string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.FieldPathPackagePath = path
+	}
+}
+
+// WithMeta specifies the path of the crossplane-runtime package that defines
+// GetExternalName, for example
+// github.com/crossplane/crossplane-runtime/pkg/meta. It is required by
+// references of immutable fields.
+func WithMeta(path string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.MetaPackagePath = path
+	}
+}
+
+// WithNamespaced specifies a function that returns true if the supplied managed
+// resource is namespace scoped. References from a namespace scoped managed
+// resource are resolved in its namespace, unless the referenced type is cluster
+// scoped. This requires resolution requests to have a Namespace field.
+func WithNamespaced(fn func(o types.Object) bool) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.Namespaced = fn
+	}
+}
+
+// WithSelectorsDisabled specifies a function that returns true if the
+// references of the supplied managed resource may only be resolved by name.
+// Resolution requests for such a managed resource have no selector, and the
+// generated method returns an error if a selector is set.
+func WithSelectorsDisabled(fn func(o types.Object) bool) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.SelectorsDisabled = fn
+	}
+}
+
+// WithResolvedValues specifies that the generated method should be named
+// ResolveReferencesWithValues, and return a map of the path of each resolved
+// field to its resolved value, for example Spec.ForProvider.SubnetIDs[0].
+func WithResolvedValues() ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.ResolvedValues = true
+	}
+}
+
+// WithClearSelectors specifies that the generated method should clear the
+// selector of a reference that was resolved by name, i.e. whose reference was
+// already set. This prevents the selector from selecting a different resource
+// if the reference is later removed.
+func WithClearSelectors() ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.ClearSelectors = true
+	}
+}
+
+// WithSkipUnchanged specifies that the generated method should store a hash of
+// the references and selectors of the managed resource in the supplied
+// annotation once they are resolved, and skip resolution while they are
+// unchanged. This saves API calls, but values are not resolved again if the
+// referenced resources change. It does not apply to the method generated with
+// WithResolvedValues, which always resolves values so that it can return them.
+func WithSkipUnchanged(annotation string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.SkipUnchanged = annotation
+	}
+}
+
+// WithCache specifies a function that returns the field of the status of the
+// supplied managed resource that the generated method caches resolutions in, or
+// an empty string if it doesn't cache them. The field must be a
+// map[string]string. Once a field is resolved, a hash of its reference,
+// selector, and resolved value is cached under its path, and the field is not
+// resolved again while they hash the same. Unlike WithSkipUnchanged, only the
+// fields whose references or selectors change are resolved again. It does not
+// apply to the method generated with WithResolvedValues, which always resolves
+// values so that it can return them.
+func WithCache(fn func(o types.Object) string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.Cache = fn
+	}
+}
+
+// WithSkipEmpty specifies that the generated method should not resolve fields
+// whose reference and selector are both unset, which would only resolve them to
+// their current values. Their current values are then not recorded by
+// ResolveReferencesWithValues either. References resolved using the
+// controller-runtime client are always skipped when both are unset.
+func WithSkipEmpty() ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.SkipEmpty = true
+	}
+}
+
+// WithErrorWrapper specifies a function that the generated method will wrap
+// errors returned while resolving a field with its path by, rather than
+// errors.Wrap, for example to return an error type of the provider. The
+// function is supplied as <package path>.<name>, and must have the signature
+// func(err error, field string) error. It takes precedence over
+// WithWrapWithMessage.
+func WithErrorWrapper(path string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.ErrorWrapper = getQualifiedFromPath(path)
+	}
+}
+
+// WithReferenceError specifies that the generated method should return errors
+// returned while resolving a field as a ReferenceError of the path of the field
+// and the kind it references, so that callers may tell them apart using
+// errors.As. The ReferenceError type must be declared in the package of the
+// managed resource, for example by the function returned by NewReferenceError.
+// It takes precedence over WithErrorWrapper and WithWrapWithMessage.
+func WithReferenceError() ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.ReferenceError = true
+	}
+}
+
+// WithWrapWithMessage specifies that the generated method should add the path
+// of a field to errors returned while resolving it using errors.WithMessage,
+// rather than errors.Wrap. The errors returned by a resolver are usually
+// already wrapped with a stack trace, so wrapping them again only repeats it.
+func WithWrapWithMessage() ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.WrapWithMessage = true
+	}
+}
+
+// WithLogging specifies the path of a package whose FromContext function the
+// generated method calls to get a logger from its context, for example
+// example.org/pkg/logging. FromContext must have the signature
+// func(ctx context.Context) L, where L has the method
+// Debug(msg string, keysAndValues ...interface{}). The generated method logs the
+// path and referenced kind of each field it resolves, whether it was resolved
+// by its reference, its selector, or its current value, and any error.
+func WithLogging(path string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.LoggingPackagePath = path
+	}
+}
+
+// WithDependencyAnnotation specifies that the generated method should record
+// the resources that it resolved references to in the supplied annotation, for
+// example example.org/dependencies, so that a controller can tell what the
+// managed resource depends on when it is deleted. The annotation is a JSON
+// array of objects with the kind and name of each resolved reference, for
+// example [{"kind":"Subnet","name":"my-subnet"}]. The kind is the name of the
+// referenced Go type.
+func WithDependencyAnnotation(annotation string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.DependencyAnnotation = annotation
+	}
+}
+
+// WithProvenance specifies the path of a package whose Record and
+// RecordMultiple functions the generated method calls after resolving each
+// field, for example example.org/pkg/provenance, so that where the value of the
+// field came from may be recorded in an annotation of the managed resource.
+// They must have the signatures
+//
+//	func Record(ctx context.Context, c client.Reader, mg resource.Managed, key string, to resource.Managed, namespace string, ref *xpv1.Reference) error
+//	func RecordMultiple(ctx context.Context, c client.Reader, mg resource.Managed, key string, to resource.Managed, namespace string, refs []xpv1.Reference) error
+//
+// Record is called for fields of single values, and RecordMultiple for slices.
+// The key is the JSON path of the field, as returned by JSONPath, with the
+// index of each slice element it is resolved within, for example
+// spec.forProvider.rules[0].subnetId. To is a new object of the referenced
+// type, which the references may be read into from the supplied namespace to
+// tell their resource versions. No references means that the field was not
+// resolved from a reference or selector, but has a literal value, so any
+// provenance recorded for it should be cleared.
+func WithProvenance(path string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.ProvenancePackagePath = path
+	}
+}
+
+// WithAssertions specifies that the generated method should be preceded by an
+// assertion that its receiver implements the Managed interface of the package
+// specified by WithResource, so that a change to the interface fails to
+// compile where the method is generated. Only ResolveReferences is preceded by
+// one, not ResolveReferencesWithValues.
+func WithAssertions() ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.Assertions = true
+	}
+}
+
+// WithFailureCondition specifies that the generated method should set a
+// condition of the supplied type and reason on the managed resource before it
+// returns an error, using its SetConditions method, so that why its references
+// couldn't be resolved is observable in its status. The condition's status is
+// False, and its message is the returned error. The runtime package must be
+// specified by WithRuntime.
+func WithFailureCondition(conditionType, reason string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.FailureCondition = &failureCondition{Type: conditionType, Reason: reason}
+	}
+}
+
+// WithClock specifies a package-level value whose Now method the generated
+// method calls to tell the current time, rather than calling the time package,
+// so that tests may replace it. The value is supplied as
+// <package path>.<name>, for example example.org/pkg/clock.Clock, and its Now
+// method must have the signature func() time.Time. Timeouts of references are
+// deadlines relative to it, and failure conditions are set at it.
+func WithClock(path string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.Clock = getQualifiedFromPath(path)
+	}
+}
+
+// WithTransactional specifies that the generated method should resolve
+// references in a deep copy of the managed resource, and replace the managed
+// resource with the copy only if every reference is resolved, so that no
+// resolved value is written if any reference cannot be resolved. The failure
+// condition is set on the managed resource itself. The managed resource must
+// have a DeepCopy method, as generated by controller-gen.
+func WithTransactional() ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.Transactional = true
+	}
+}
+
+// WithRetry specifies the path of a package whose OnError function the
+// generated method wraps each resolution in, for example example.org/pkg/retry,
+// so that a resolution that fails transiently, for example because the API
+// server is unavailable, is retried rather than failing the reconcile. OnError
+// must have the signature
+//
+//	func OnError(ctx context.Context, b Backoff, fn func() error) error
+//
+// and return the last error returned by fn if it never returns nil. The package
+// must define the Backoff type, with the fields of the supplied Backoff. If no
+// Backoff is supplied the package's DefaultBackoff variable is used. Errors
+// are wrapped with the path of the field after retries are exhausted.
+func WithRetry(path string, b *Backoff) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.RetryPackagePath = path
+		o.Backoff = b
+	}
+}
+
+// WithControllerRuntime specifies that the generated method should resolve
+// references by getting and listing the referenced resources with its
+// controller-runtime client directly, rather than with the crossplane-runtime
+// reference resolver. The reference package is not imported. A reference that
+// is not set is selected from the resources whose labels match its selector,
+// and that have the same controller if the selector requires it. Values are
+// extracted by the extractor of the reference, or from the external name of
+// the referenced resource. The supplied path is that of the crossplane-runtime
+// package that defines GetExternalName and HaveSameController, for example
+// github.com/crossplane/crossplane-runtime/pkg/meta.
+//
+// Only references of single values and slices are supported. References that
+// spread, are keyed, are members of a union, validate, format or annotate
+// values, require the same provider config, or have reference or selector
+// field paths cause a panic, as does WithResolvedValues, WithLogging,
+// WithProvenance, or WithResolver.
+// Reference policies are not supported; references are always resolved.
+func WithControllerRuntime(metaPath string) ResolveReferencesOption {
+	return func(o *resolveReferencesOptions) {
+		o.MetaPackagePath = metaPath
+		o.ControllerRuntime = true
+	}
+}
+
 // NewResolveReferences returns a NewMethod that writes a ResolveReferences for
 // given managed resource, if needed.
-func NewResolveReferences(traverser *xptypes.Traverser, receiver, clientPath, referencePkgPath string) New {
+func NewResolveReferences(traverser *xptypes.Traverser, receiver, clientPath, referencePkgPath string, ro ...ResolveReferencesOption) New {
+	opts := &resolveReferencesOptions{}
+	for _, fn := range ro {
+		fn(opts)
+	}
+	// Resolvers that use the client directly extract the external name when
+	// a reference has no extractor.
+	var defaultExtractor *jen.Statement
+	if !opts.ControllerRuntime {
+		defaultExtractor = jen.Qual(referencePkgPath, "ExternalName").Call()
+	}
+	// The processor is shared by all managed resources, so that the
+	// references of the fields of structs that several of them have in
+	// common are only composed once.
+	refProcessor := NewReferenceProcessor(receiver,
+		WithDefaultExtractor(defaultExtractor),
+		WithRuntimePackagePath(opts.RuntimePackagePath),
+	)
+	// The validation patterns that were compiled in each file, so that each
+	// is compiled once, even if several managed resources validate with it.
+	compiled := map[*jen.File]map[string]bool{}
 	return func(f *jen.File, o types.Object) {
 		n, ok := o.Type().(*types.Named)
 		if !ok {
 			return
 		}
-		refProcessor := NewReferenceProcessor(receiver,
-			WithDefaultExtractor(jen.Qual(referencePkgPath, "ExternalName").Call()),
+		tp := refProcessor.For(n)
+		cfg := &xptypes.ProcessorConfig{
+			Field: tp,
+			Named: xptypes.NamedProcessorFn(tp.ProcessNamed),
+		}
+		if err := traverser.Traverse(n, cfg); err != nil {
+			panic(errors.Wrapf(err, "cannot traverse the type tree of %s", n.Obj().Name()))
+		}
+		refs := refProcessor.ReferencesFor(n)
+		if len(refs) == 0 {
+			return
+		}
+		mo := managedOptions{
+			Type:              n,
+			Namespaced:        opts.Namespaced != nil && opts.Namespaced(o),
+			SelectorsDisabled: opts.SelectorsDisabled != nil && opts.SelectorsDisabled(o),
+			ResolvedValues:    opts.ResolvedValues,
+			ClearSelectors:    opts.ClearSelectors && !(opts.SelectorsDisabled != nil && opts.SelectorsDisabled(o)),
+			Tenant:            opts.Tenant != nil,
+			WrapWithMessage:   opts.WrapWithMessage,
+			ErrorWrapper:      opts.ErrorWrapper,
+			ReferenceError:    opts.ReferenceError,
+			SkipEmpty:         opts.SkipEmpty,
+			Clock:             opts.Clock,
+
+			DependencyAnnotation: opts.DependencyAnnotation,
+			Locals:               newLocals(n, receiver, refs),
+		}
+		if !mo.ResolvedValues {
+			mo.SkipUnchanged = opts.SkipUnchanged
+		}
+		if opts.Cache != nil && !mo.ResolvedValues {
+			if field := opts.Cache(o); field != "" {
+				if err := cacheField(n, field); err != nil {
+					panic(errors.Wrapf(err, "cannot cache the resolutions of %s", n.Obj().Name()))
+				}
+				mo.Cache = jen.Id(receiver).Dot("Status").Dot(field)
+			}
+		}
+		if opts.FailureCondition != nil {
+			if opts.RuntimePackagePath == "" {
+				panic(errors.Errorf("resolvers of %s set a failure condition, but no runtime package is configured", n.Obj().Name()))
+			}
+			// Transactional resolvers set the failure condition on the
+			// managed resource, rather than on the copy they discard.
+			conditioned := receiver
+			if opts.Transactional {
+				conditioned = mo.Locals["original"]
+			}
+			mo.FailureCondition = setFailureCondition(opts.FailureCondition, opts.RuntimePackagePath, conditioned, mo)
+		}
+		if opts.RetryPackagePath != "" {
+			mo.Retry = jen.Qual(opts.RetryPackagePath, "OnError")
+			mo.Backoff = jen.Qual(opts.RetryPackagePath, "DefaultBackoff")
+			if opts.Backoff != nil {
+				mo.Backoff = mo.Locals.Id("backoff")
+			}
+		}
+		if opts.Transactional && !lookupMethod(n, "DeepCopy") {
+			panic(errors.Errorf("references of %s cannot be resolved transactionally, because it has no DeepCopy method", n.Obj().Name()))
+		}
+		if opts.ControllerRuntime && opts.Resolver != nil {
+			panic(errors.Errorf("resolvers of %s that use the controller-runtime client cannot use a resolver", n.Obj().Name()))
+		}
+		if opts.ControllerRuntime && opts.RetryPackagePath != "" {
+			panic(errors.Errorf("resolvers of %s that use the controller-runtime client cannot retry resolutions", n.Obj().Name()))
+		}
+		if opts.ControllerRuntime && (mo.ResolvedValues || opts.LoggingPackagePath != "" || opts.ProvenancePackagePath != "") {
+			panic(errors.Errorf("resolvers of %s that use the controller-runtime client cannot return resolved values, log resolution, or record provenance", n.Obj().Name()))
+		}
+		hasMultiResolution := false
+		hasSingleResolution := false
+		hasTenantResolution := false
+		resolverCalls := make(jen.Statement, len(refs))
+		hashCalls := make(jen.Statement, len(refs))
+		for i, ref := range refs {
+			// encapsulate rewrites the fields it is supplied, so each
+			// call gets its own copy.
+			hashCall := encapsulate(0, hashInputsCall(ref, mo, opts), append([]string{}, ref.GoValueFieldPath...)...)
+			if encapsulated(ref.GoValueFieldPath) && i < len(refs)-1 {
+				// Calls wrapped in a block don't end with a line, so one
+				// is needed to separate them from the next call.
+				hashCall = hashCall.Line()
+			}
+			hashCalls[i] = hashCall
+			if ref.SameProviderConfig && opts.ProviderConfigValidator == nil {
+				panic(errors.Errorf("%s requires the same provider config as %s, but no provider config validator is configured", fieldPaths(n, ref), n.Obj().Name()))
+			}
+			if ref.FromAnnotation != "" {
+				if opts.ResourcePackagePath == "" {
+					panic(errors.Errorf("%s of %s is extracted from an annotation, but no resource package is configured", fieldPaths(n, ref), n.Obj().Name()))
+				}
+				ref.Extractor = annotationExtractor(ref.FromAnnotation, opts.ResourcePackagePath)
+			}
+			if ref.ExtractorArg != "" && opts.ResourcePackagePath == "" {
+				panic(errors.Errorf("%s of %s passes an argument to its extractor, but no resource package is configured", fieldPaths(n, ref), n.Obj().Name()))
+			}
+			if ref.Composite != nil && opts.ResourcePackagePath == "" {
+				panic(errors.Errorf("%s of %s is a component of a composite key, but no resource package is configured", fieldPaths(n, ref), n.Obj().Name()))
+			}
+			if ref.Paved != nil && (opts.FieldPathPackagePath == "" || opts.RuntimePackagePath == "") {
+				panic(errors.Errorf("%s of %s is a key of a paved map, but no fieldpath or runtime package is configured", fieldPaths(n, ref), n.Obj().Name()))
+			}
+			if ref.Immutable && opts.MetaPackagePath == "" {
+				panic(errors.Errorf("%s of %s is immutable, but no meta package is configured", fieldPaths(n, ref), n.Obj().Name()))
+			}
+			if ref.GoRefFieldName == "" && mo.SelectorsDisabled {
+				panic(errors.Errorf("%s of %s has no reference field and selectors are disabled, so it cannot be resolved", fieldPaths(n, ref), n.Obj().Name()))
+			}
+			if ref.GoRefFieldName == "" && ref.OneOf != nil {
+				panic(errors.Errorf("%s of %s has no reference field, so it cannot be a member of a union", fieldPaths(n, ref), n.Obj().Name()))
+			}
+			if ref.SliceKey != nil && ref.OneOf != nil {
+				panic(errors.Errorf("%s of %s has a slice key, so it cannot be a member of a union", fieldPaths(n, ref), n.Obj().Name()))
+			}
+			if len(ref.GoRefFieldParents)+len(ref.GoSelectorFieldParents) > 0 && ref.OneOf != nil {
+				panic(errors.Errorf("%s of %s has a reference or selector field path, so it cannot be a member of a union", fieldPaths(n, ref), n.Obj().Name()))
+			}
+			if mo.Cache != nil && (ref.Paved != nil || ref.Spread != nil || ref.SliceKey != nil || ref.Composite != nil) {
+				panic(errors.Errorf("%s of %s cannot be cached, because caching only supports references that resolve a field of their own", fieldPaths(n, ref), n.Obj().Name()))
+			}
+			if ref.FieldSelector != "" && mo.SelectorsDisabled {
+				panic(errors.Errorf("%s of %s has a field selector, but selectors are disabled", fieldPaths(n, ref), n.Obj().Name()))
+			}
+			hasTenantResolution = hasTenantResolution || (mo.Tenant && !ref.ClusterScoped)
+			var callFn resolutionCallFn
+			switch {
+			case opts.ControllerRuntime:
+				if err := clientSupports(ref); err != nil {
+					panic(errors.Wrapf(err, "%s of %s cannot be resolved using the controller-runtime client", fieldPaths(n, ref), n.Obj().Name()))
+				}
+				callFn = cached(ref, mo, opts, immutable(ref, mo, opts, clientResolutionCall(ref, clientPath, mo, opts)))
+			case ref.Paved != nil:
+				hasSingleResolution = true
+				callFn = pavedResolutionCall(ref, referencePkgPath, mo, opts)
+			case ref.Spread != nil:
+				hasMultiResolution = true
+				callFn = oneOf(ref, mo, spreadResolutionCall(ref, referencePkgPath, mo, opts))
+			case ref.IsSlice:
+				hasMultiResolution = true
+				callFn = cached(ref, mo, opts, immutable(ref, mo, opts, oneOf(ref, mo, multiResolutionCall(ref, referencePkgPath, mo, opts))))
+			case ref.SliceKey != nil:
+				hasSingleResolution = true
+				callFn = immutable(ref, mo, opts, keyedResolutionCall(ref, referencePkgPath, mo, opts))
+			default:
+				hasSingleResolution = true
+				callFn = cached(ref, mo, opts, immutable(ref, mo, opts, oneOf(ref, mo, singleResolutionCall(ref, referencePkgPath, mo, opts))))
+			}
+			// Calls are followed by an empty line, except those that
+			// encapsulate wraps in blocks, whose innermost block ends with
+			// one instead, as it always has.
+			wrapped := encapsulated(ref.GoValueFieldPath)
+			if wrapped {
+				innermost := callFn
+				callFn = func(fields ...string) *jen.Statement {
+					return innermost(fields...).Line()
+				}
+			}
+			call := encapsulate(0, callFn, ref.GoValueFieldPath...)
+			if ref.When != nil {
+				call = jen.If(ref.When.Clone().Call(jen.Id(receiver))).Block(call)
+			}
+			if ref.DeprecationMessage != "" {
+				call = jen.Comment("Deprecated: " + ref.DeprecationMessage).Line().Add(call)
+			}
+			call.Line()
+			if !wrapped || ref.When != nil {
+				call.Line()
+			}
+			resolverCalls[i] = call
+		}
+		if compiled[f] == nil {
+			compiled[f] = map[string]bool{}
+		}
+		for _, ref := range refs {
+			if ref.Validation == nil || ref.Validation.Validator != nil || compiled[f][ref.Validation.Pattern] {
+				continue
+			}
+			compiled[f][ref.Validation.Pattern] = true
+			f.Var().Id(patternVar(ref.Validation.Pattern)).Op("=").Qual("regexp", "MustCompile").Call(jen.Lit(ref.Validation.Pattern))
+			f.Line()
+		}
+		var initStatements jen.Statement
+		if hasTenantResolution {
+			initStatements = append(initStatements, mo.Locals.Id("tenant").Op(":=").Add(opts.Tenant.Clone().Call(jen.Id("ctx"))), jen.Line(), jen.Line())
+		}
+		if opts.LoggingPackagePath != "" {
+			initStatements = append(initStatements, resolvedBy(mo), jen.Line(), jen.Line())
+		}
+		// Responses and other locals are declared one per line, only if
+		// they are used, in a fixed order: the responses of single and
+		// multiple resolutions, the backoff of retries, the recorded
+		// dependencies and resolved values, and the locals of the cache.
+		// err is declared after all of them.
+		var locals []jen.Code
+		if hasSingleResolution {
+			locals = append(locals, jen.Var().Add(mo.Locals.Id("rsp")).Qual(referencePkgPath, "ResolutionResponse"))
+		}
+		if hasMultiResolution {
+			locals = append(locals, jen.Var().Add(mo.Locals.Id("mrsp")).Qual(referencePkgPath, "MultiResolutionResponse"))
+		}
+		if opts.RetryPackagePath != "" && opts.Backoff != nil {
+			locals = append(locals, mo.Locals.Id("backoff").Op(":=").Qual(opts.RetryPackagePath, "Backoff").Values(jen.Dict{
+				jen.Id("Duration"): duration(opts.Backoff.Duration),
+				jen.Id("Factor"):   jen.Lit(opts.Backoff.Factor),
+				jen.Id("Steps"):    jen.Lit(opts.Backoff.Steps),
+			}))
+		}
+		if mo.DependencyAnnotation != "" {
+			locals = append(locals, jen.Var().Add(mo.Locals.Id("dependencies")).Index().Map(jen.String()).String())
+		}
+		if mo.ResolvedValues {
+			locals = append(locals, mo.Locals.Id("resolved").Op(":=").Map(jen.String()).String().Values())
+		}
+		if mo.Cache != nil {
+			locals = append(locals, cacheLocals(mo)...)
+		}
+		for i, l := range locals {
+			if i > 0 {
+				initStatements = append(initStatements, jen.Line())
+			}
+			initStatements = append(initStatements, l)
+		}
+
+		if mo.ResolvedValues {
+			f.Commentf("ResolveReferencesWithValues of this %s. It returns resolved values by field path.", o.Name())
+			f.Func().Params(jen.Id(receiver).Op("*").Id(o.Name())).Id("ResolveReferencesWithValues").Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("c").Qual(clientPath, "Reader")).Params(jen.Map(jen.String()).String(), jen.Error()).Block(
+				beginTransaction(mo, opts, receiver),
+				mo.Locals.Id("r").Op(":=").Add(newResolver(opts, referencePkgPath)).Call(jen.Id("c"), jen.Id(receiver)),
+				jen.Line(),
+				&initStatements,
+				jen.Var().Add(mo.Locals.Err()).Error(),
+				jen.Line(),
+				&resolverCalls,
+				jen.Line(),
+				storeAnnotations(mo, receiver),
+				commitTransaction(mo, opts, receiver),
+				jen.Return(mo.Locals.Id("resolved"), jen.Nil()),
+			)
+			return
+		}
+
+		body := []jen.Code{beginTransaction(mo, opts, receiver)}
+		if !opts.ControllerRuntime {
+			body = append(body, mo.Locals.Id("r").Op(":=").Add(newResolver(opts, referencePkgPath)).Call(jen.Id("c"), jen.Id(receiver)), jen.Line())
+		}
+		if opts.Assertions {
+			if opts.ResourcePackagePath == "" {
+				panic(errors.Errorf("cannot assert that %s is a managed resource without the path of the resource package", o.Name()))
+			}
+			f.Var().Id("_").Qual(opts.ResourcePackagePath, "Managed").Op("=").Op("&").Id(o.Name()).Values()
+			f.Line()
+		}
+		f.Commentf("ResolveReferences of this %s.", o.Name())
+		f.Func().Params(jen.Id(receiver).Op("*").Id(o.Name())).Id("ResolveReferences").Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("c").Qual(clientPath, "Reader")).Error().Block(append(body,
+			&initStatements,
+			jen.Var().Add(mo.Locals.Err()).Error(),
+			jen.Line(),
+			skipUnchanged(mo, receiver, &hashCalls),
+			&resolverCalls,
+			jen.Line(),
+			storeAnnotations(mo, receiver),
+			commitTransaction(mo, opts, receiver),
+			jen.Return(jen.Nil()),
+		)...)
+	}
+}
+
+// beginTransaction returns statements that keep the receiver as the original
+// managed resource, and replace it with a deep copy that references are
+// resolved in, or nothing if references are not resolved transactionally.
+func beginTransaction(mo managedOptions, opts *resolveReferencesOptions, receiver string) *jen.Statement {
+	if !opts.Transactional {
+		return &jen.Statement{}
+	}
+	return &jen.Statement{
+		jen.Comment("References are resolved in a copy, which replaces the original only"),
+		jen.Line(),
+		jen.Comment("if every reference is resolved."),
+		jen.Line(),
+		mo.Locals.Id("original").Op(":=").Id(receiver),
+		jen.Line(),
+		jen.Id(receiver).Op("=").Id(receiver).Dot("DeepCopy").Call(),
+		jen.Line(),
+		jen.Line(),
+	}
+}
+
+// commitTransaction returns a statement that replaces the original managed
+// resource with the copy that references were resolved in, or nothing if
+// references are not resolved transactionally.
+func commitTransaction(mo managedOptions, opts *resolveReferencesOptions, receiver string) *jen.Statement {
+	if !opts.Transactional {
+		return &jen.Statement{}
+	}
+	return jen.Op("*").Add(mo.Locals.Id("original")).Op("=").Op("*").Id(receiver)
+}
+
+// withRetry returns the supplied assignment of the response of a resolution
+// and of err wrapped in a call of the retry function, so that the resolution is
+// retried while it fails, or the assignment itself if resolutions are not
+// retried.
+func withRetry(mo managedOptions, assign *jen.Statement) *jen.Statement {
+	if mo.Retry == nil {
+		return assign
+	}
+	return mo.Locals.Err().Op("=").Add(mo.Retry.Clone()).Call(jen.Id("ctx"), mo.Backoff.Clone(), jen.Func().Params().Error().Block(
+		assign,
+		jen.Return(mo.Locals.Err()),
+	))
+}
+
+// newResolver returns the function that constructs the resolver of the
+// generated method: the configured one, or NewAPIResolver of the reference
+// package.
+func newResolver(opts *resolveReferencesOptions, referencePkgPath string) *jen.Statement {
+	if opts.Resolver != nil {
+		return opts.Resolver.Clone()
+	}
+	return jen.Qual(referencePkgPath, "NewAPIResolver")
+}
+
+// hashInputsCall returns a call that appends the reference and selector of the
+// supplied reference to the inputs that are hashed to tell whether they have
+// changed since they were last resolved. The reference and selector of a
+// nested paved reference are read by paving its map. Unlike a resolution call,
+// the call ends with a line, as the inputs are appended one per line.
+func hashInputsCall(ref Reference, mo managedOptions, opts *resolveReferencesOptions) resolutionCallFn {
+	return func(fields ...string) *jen.Statement {
+		prefixPath := goExpr(fields[:len(fields)-1]...)
+		if ref.Paved != nil {
+			mapPath := prefixPath.Clone().Dot(fields[len(fields)-1])
+			referencePath := mapPath.Clone().Index(jen.Lit(ref.Paved.RefKey))
+			if ref.Paved.RefsFieldName != "" {
+				referencePath = prefixPath.Clone().Dot(ref.Paved.RefsFieldName).Index(jen.Lit(ref.Paved.Key))
+			}
+			if ref.Paved.Nested {
+				readReference := jen.Id("ref").Op(":=").Add(referencePath)
+				if ref.Paved.RefsFieldName == "" {
+					readReference = jen.List(jen.Id("ref"), jen.Id("_")).Op(":=").Id("p").Dot("GetValue").Call(jen.Lit(ref.Paved.RefKey))
+				}
+				return jen.Block(
+					jen.Id("p").Op(":=").Qual(opts.FieldPathPackagePath, "Pave").Call(mapPath),
+					readReference,
+					jen.List(jen.Id("selector"), jen.Id("_")).Op(":=").Id("p").Dot("GetValue").Call(jen.Lit(ref.Paved.SelectorKey)),
+					mo.Locals.Id("inputs").Op("=").Append(mo.Locals.Id("inputs"), jen.Id("ref"), jen.Id("selector")),
+				).Line()
+			}
+			return mo.Locals.Id("inputs").Op("=").Append(mo.Locals.Id("inputs"),
+				referencePath,
+				mapPath.Clone().Index(jen.Lit(ref.Paved.SelectorKey)),
+			).Line()
+		}
+		referencePath := goExpr(append([]string{fields[0]}, refParents(ref, fields[1:len(fields)-1])...)...)
+		if ref.GoRefFieldName == "" {
+			return hashInput(mo, prefixPath, ref.GoSelectorFieldParents, ref.GoSelectorFieldName)
+		}
+		if len(ref.GoRefFieldParents)+len(ref.GoSelectorFieldParents) == 0 {
+			return mo.Locals.Id("inputs").Op("=").Append(mo.Locals.Id("inputs"),
+				referencePath.Dot(ref.GoRefFieldName),
+				prefixPath.Clone().Dot(ref.GoSelectorFieldName),
+			).Line()
+		}
+		return &jen.Statement{
+			hashInput(mo, prefixPath, ref.GoRefFieldParents, ref.GoRefFieldName),
+			hashInput(mo, prefixPath, ref.GoSelectorFieldParents, ref.GoSelectorFieldName),
+		}
+	}
+}
+
+// hashInput returns a statement that appends the named field of the struct
+// reached through the supplied parents from the supplied path to the inputs
+// that are hashed, or nil if any of the parents are nil.
+func hashInput(mo managedOptions, path *jen.Statement, parents []PathSegment, name string) *jen.Statement {
+	field := parentsPath(path, parents).Dot(name)
+	guard := parentsGuard(path, parents)
+	if guard == nil {
+		return mo.Locals.Id("inputs").Op("=").Append(mo.Locals.Id("inputs"), field).Line()
+	}
+	return jen.If(guard).Block(
+		mo.Locals.Id("inputs").Op("=").Append(mo.Locals.Id("inputs"), field),
+	).Else().Block(
+		mo.Locals.Id("inputs").Op("=").Append(mo.Locals.Id("inputs"), jen.Nil()),
+	).Line()
+}
+
+// skipUnchanged returns a function that hashes the inputs appended by the
+// supplied calls, and a check that returns early if the hash is the one stored
+// in the annotation, or nothing if unchanged references are not skipped.
+func skipUnchanged(mo managedOptions, receiver string, hashCalls *jen.Statement) *jen.Statement {
+	if mo.SkipUnchanged == "" {
+		return &jen.Statement{}
+	}
+	return &jen.Statement{
+		mo.Locals.Id("hashInputs").Op(":=").Func().Params().Params(jen.String(), jen.Error()).Block(
+			jen.Var().Add(mo.Locals.Id("inputs")).Index().Interface(),
+			hashCalls,
+			jen.List(jen.Id("b"), mo.Locals.Err()).Op(":=").Qual("encoding/json", "Marshal").Call(mo.Locals.Id("inputs")),
+			jen.If(mo.Locals.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Lit(""), mo.Locals.Err()),
+			),
+			jen.Return(jen.Qual("fmt", "Sprintf").Call(jen.Lit("%x"), jen.Qual("crypto/sha256", "Sum256").Call(jen.Id("b"))), jen.Nil()),
+		),
+		jen.Line(),
+		jen.List(mo.Locals.Id("hash"), mo.Locals.Err()).Op(":=").Add(mo.Locals.Id("hashInputs")).Call(),
+		jen.Line(),
+		jen.If(mo.Locals.Err().Op("!=").Nil()).Block(
+			returnError(mo, wrapError(mo, "cannot hash references and selectors")),
+		),
+		jen.Line(),
+		jen.If(selectMethod(mo.Type, receiver, "GetAnnotations").Call().Index(jen.Lit(mo.SkipUnchanged)).Op("==").Add(mo.Locals.Id("hash"))).Block(
+			jen.Return(jen.Nil()),
+		),
+		jen.Line(),
+		jen.Line(),
+	}
+}
+
+// cacheField returns an error if the status of the supplied managed resource
+// has no field of the supplied name that resolutions can be cached in.
+func cacheField(n *types.Named, name string) error {
+	status, _, _ := types.LookupFieldOrMethod(n, true, n.Obj().Pkg(), "Status")
+	if _, ok := status.(*types.Var); !ok {
+		return errors.New("it has no status")
+	}
+	f, _, _ := types.LookupFieldOrMethod(status.Type(), true, n.Obj().Pkg(), name)
+	if _, ok := f.(*types.Var); !ok {
+		return errors.Errorf("its status has no field %s", name)
+	}
+	if !types.Identical(f.Type().Underlying(), types.NewMap(types.Typ[types.String], types.Typ[types.String])) {
+		return errors.Errorf("Status.%s is a %s, not a map[string]string", name, f.Type())
+	}
+	return nil
+}
+
+// cacheLocals returns the declarations of a function that hashes the inputs of
+// a cached resolution, and of the inputs and their hash.
+func cacheLocals(mo managedOptions) []jen.Code {
+	return []jen.Code{
+		mo.Locals.Id("cacheKey").Op(":=").Func().Params(mo.Locals.Id("inputs").Index().Interface()).Params(jen.String(), jen.Error()).Block(
+			jen.List(jen.Id("b"), mo.Locals.Err()).Op(":=").Qual("encoding/json", "Marshal").Call(mo.Locals.Id("inputs")),
+			jen.If(mo.Locals.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Lit(""), mo.Locals.Err()),
+			),
+			jen.Return(jen.Qual("fmt", "Sprintf").Call(jen.Lit("%x"), jen.Qual("crypto/sha256", "Sum256").Call(jen.Id("b"))), jen.Nil()),
+		),
+		jen.Var().Add(mo.Locals.Id("inputs")).Index().Interface(),
+		jen.Var().Add(mo.Locals.Id("cached")).String(),
+	}
+}
+
+// cached returns a resolution call that is made only if the hash of the
+// reference, selector, and value of the supplied reference differs from the one
+// cached under the path of its field, and then caches their new hash, or the
+// supplied call itself if resolutions are not cached.
+func cached(ref Reference, mo managedOptions, opts *resolveReferencesOptions, callFn resolutionCallFn) resolutionCallFn {
+	if mo.Cache == nil {
+		return callFn
+	}
+	return func(fields ...string) *jen.Statement {
+		hash := func() *jen.Statement {
+			return &jen.Statement{
+				mo.Locals.Id("inputs").Op("=").Index().Interface().Values(goExpr(fields...)),
+				jen.Line(),
+				hashInputsCall(ref, mo, opts)(fields...),
+				jen.If(jen.List(mo.Locals.Id("cached"), mo.Locals.Err()).Op("=").Add(mo.Locals.Id("cacheKey")).Call(mo.Locals.Id("inputs")), mo.Locals.Err().Op("!=").Nil()).Block(
+					returnError(mo, jen.Qual("github.com/pkg/errors", "Wrap").Call(mo.Locals.Err(), jen.Lit(GoPath(ref.GoValueFieldPath...)+": cannot hash reference, selector, and value"))),
+				),
+			}
+		}
+		return hash().Line().If(mo.Cache.Clone().Index(resolvedKey(fields...)).Op("!=").Add(mo.Locals.Id("cached"))).Block(
+			callFn(fields...),
+			jen.Line(),
+			hash(),
+			jen.If(mo.Cache.Clone().Op("==").Nil()).Block(
+				mo.Cache.Clone().Op("=").Map(jen.String()).String().Values(),
+			),
+			mo.Cache.Clone().Index(resolvedKey(fields...)).Op("=").Add(mo.Locals.Id("cached")),
+		)
+	}
+}
+
+// storeAnnotations returns statements that store the hash of the resolved
+// references and selectors, and the resolved dependencies, in their
+// annotations, or nothing if neither is stored.
+func storeAnnotations(mo managedOptions, receiver string) *jen.Statement {
+	if mo.SkipUnchanged == "" && mo.DependencyAnnotation == "" {
+		return &jen.Statement{}
+	}
+	s := &jen.Statement{}
+	if mo.SkipUnchanged != "" {
+		s.Add(jen.If(jen.List(mo.Locals.Id("hash"), mo.Locals.Err()).Op("=").Add(mo.Locals.Id("hashInputs")).Call(), mo.Locals.Err().Op("!=").Nil()).Block(
+			returnError(mo, wrapError(mo, "cannot hash references and selectors")),
+		), jen.Line())
+	}
+	if mo.DependencyAnnotation != "" {
+		s.Add(jen.List(mo.Locals.Id("deps"), mo.Locals.Err()).Op(":=").Qual("encoding/json", "Marshal").Call(mo.Locals.Id("dependencies")), jen.Line())
+		s.Add(jen.If(mo.Locals.Err().Op("!=").Nil()).Block(
+			returnError(mo, jen.Qual("github.com/pkg/errors", "Wrap").Call(mo.Locals.Err(), jen.Lit("cannot marshal resolved dependencies"))),
+		), jen.Line())
+	}
+	s.Add(
+		mo.Locals.Id("annotations").Op(":=").Add(selectMethod(mo.Type, receiver, "GetAnnotations")).Call(),
+		jen.Line(),
+		jen.If(mo.Locals.Id("annotations").Op("==").Nil()).Block(
+			mo.Locals.Id("annotations").Op("=").Map(jen.String()).String().Values(),
+		),
+		jen.Line(),
+	)
+	if mo.SkipUnchanged != "" {
+		s.Add(mo.Locals.Id("annotations").Index(jen.Lit(mo.SkipUnchanged)).Op("=").Add(mo.Locals.Id("hash")), jen.Line())
+	}
+	if mo.DependencyAnnotation != "" {
+		s.Add(mo.Locals.Id("annotations").Index(jen.Lit(mo.DependencyAnnotation)).Op("=").String().Call(mo.Locals.Id("deps")), jen.Line())
+	}
+	return s.Add(
+		selectMethod(mo.Type, receiver, "SetAnnotations").Call(mo.Locals.Id("annotations")),
+		jen.Line(),
+		jen.Line(),
+	)
+}
+
+// recordDependencies returns a statement that records each of the supplied
+// resolved references as a dependency, or nothing if dependencies are not
+// recorded. The references are a *Reference if single is true, and a slice of
+// Reference otherwise.
+func recordDependencies(ref Reference, mo managedOptions, resolved *jen.Statement, single bool) *jen.Statement {
+	if mo.DependencyAnnotation == "" {
+		return &jen.Statement{}
+	}
+	if single {
+		return jen.If(resolved.Clone().Op("!=").Nil()).Block(dependency(ref, mo, resolved))
+	}
+	return jen.For(jen.List(jen.Id("_"), jen.Id("dep")).Op(":=").Range().Add(resolved)).Block(dependency(ref, mo, jen.Id("dep")))
+}
+
+// dependency returns a statement that records the supplied resolved reference
+// of the supplied Reference as a dependency.
+func dependency(ref Reference, mo managedOptions, resolved *jen.Statement) *jen.Statement {
+	kind := ref.RemoteTypePath[strings.LastIndex(ref.RemoteTypePath, ".")+1:]
+	return mo.Locals.Id("dependencies").Op("=").Append(mo.Locals.Id("dependencies"), jen.Map(jen.String()).String().Values(
+		jen.Lit("kind").Op(":").Lit(kind),
+		jen.Lit("name").Op(":").Add(resolved.Clone().Dot("Name")),
+	))
+}
+
+// recordProvenance returns a call to the Record function of the provenance
+// package with the supplied resolved reference of the supplied reference, or to
+// RecordMultiple with its resolved references if it is not single. The fields
+// are those of its value as rewritten by encapsulate. It returns nothing if
+// provenance is not recorded.
+func recordProvenance(ref Reference, mo managedOptions, opts *resolveReferencesOptions, fields []string, resolved *jen.Statement, single bool) *jen.Statement {
+	if opts.ProvenancePackagePath == "" {
+		return &jen.Statement{}
+	}
+	fn := "RecordMultiple"
+	if single {
+		fn = "Record"
+	}
+	ns := namespace(ref, mo, fields[0])
+	if ns == nil {
+		ns = jen.Lit("")
+	}
+	return jen.If(
+		mo.Locals.Err().Op("=").Qual(opts.ProvenancePackagePath, fn).Call(jen.Id("ctx"), jen.Id("c"), jen.Id(fields[0]), provenanceKey(ref, mo, fields), ref.RemoteType.Clone(), ns, resolved.Clone()),
+		mo.Locals.Err().Op("!=").Nil(),
+	).Block(returnWrapped(mo, ref, valuePath(ref, 0)))
+}
+
+// provenanceKey returns the key that the provenance of the supplied reference
+// is recorded by: the JSON path of its value, with the index of each slice
+// element that it is resolved within. The supplied fields are those of its
+// value as rewritten by encapsulate, so they are named as they are by the
+// Traverser again to get the JSON path.
+func provenanceKey(ref Reference, mo managedOptions, fields []string) *jen.Statement {
+	goFields := make([]string, 0, len(fields)-1)
+	indices := make([]jen.Code, 0)
+	for _, f := range fields[1:] {
+		if m := regexLoopSuffix.FindStringSubmatch(f); m != nil {
+			if strings.HasPrefix(m[2], "[") {
+				indices = append(indices, jen.Id(strings.Trim(m[2], "[]")))
+				f = "[]" + m[1]
+			} else {
+				f = "(" + strings.TrimSuffix(strings.TrimPrefix(m[2], ".(*"), ")") + ")" + m[1]
+			}
+		}
+		goFields = append(goFields, f)
+	}
+	path := JSONPath(mo.Type, goFields[:len(goFields)-1], goFields[len(goFields)-1])
+	if ref.Paved != nil {
+		path = pavedJSONPath(mo.Type, goFields, ref.Paved, ref.Paved.Key)
+	}
+	if len(indices) == 0 {
+		return jen.Lit(path)
+	}
+	format := strings.ReplaceAll(strings.ReplaceAll(path, "%", "%%"), "[*]", "[%d]")
+	return jen.Qual("fmt", "Sprintf").Call(append([]jen.Code{jen.Lit(format)}, indices...)...)
+}
+
+// resolvedBy returns a function that describes how a reference was resolved,
+// given whether its reference and its selector were set.
+func resolvedBy(mo managedOptions) *jen.Statement {
+	return mo.Locals.Id("resolvedBy").Op(":=").Func().Params(jen.Id("refSet"), jen.Id("selectorSet").Bool()).String().Block(
+		jen.Switch().Block(
+			jen.Case(jen.Id("refSet")).Block(jen.Return(jen.Lit("reference"))),
+			jen.Case(jen.Id("selectorSet")).Block(jen.Return(jen.Lit("selector"))),
+		),
+		jen.Return(jen.Lit("value")),
+	)
+}
+
+// logResolution returns a debug log of the outcome of resolving the supplied
+// reference, given whether its reference and its selector were set, or nothing
+// if logging is not configured.
+func logResolution(ref Reference, mo managedOptions, opts *resolveReferencesOptions, isRef, isSelected *jen.Statement) *jen.Statement {
+	if opts.LoggingPackagePath == "" {
+		return &jen.Statement{}
+	}
+	if isRef == nil {
+		isRef = jen.False()
+	}
+	kind := ref.RemoteTypePath[strings.LastIndex(ref.RemoteTypePath, ".")+1:]
+	return jen.Qual(opts.LoggingPackagePath, "FromContext").Call(jen.Id("ctx")).Dot("Debug").Call(
+		jen.Lit("Resolved reference"),
+		jen.Lit("field"), jen.Lit(valuePath(ref, 0)),
+		jen.Lit("kind"), jen.Lit(kind),
+		jen.Lit("by"), mo.Locals.Id("resolvedBy").Call(isRef, isSelected),
+		jen.Lit("error"), mo.Locals.Err(),
+	).Line()
+}
+
+// setFailureCondition returns a statement that sets the supplied failure
+// condition on the receiver, with a message of err, at the current time.
+func setFailureCondition(fc *failureCondition, runtimePkgPath, receiver string, mo managedOptions) *jen.Statement {
+	now := jen.Qual("k8s.io/apimachinery/pkg/apis/meta/v1", "Now").Call()
+	if mo.Clock != nil {
+		now = jen.Qual("k8s.io/apimachinery/pkg/apis/meta/v1", "NewTime").Call(mo.Clock.Clone().Dot("Now").Call())
+	}
+	return jen.Id(receiver).Dot("SetConditions").Call(jen.Qual(runtimePkgPath, "Condition").Values(jen.Dict{
+		jen.Id("Type"):               jen.Lit(fc.Type),
+		jen.Id("Status"):             jen.Qual("k8s.io/api/core/v1", "ConditionFalse"),
+		jen.Id("LastTransitionTime"): now,
+		jen.Id("Reason"):             jen.Lit(fc.Reason),
+		jen.Id("Message"):            mo.Locals.Err().Dot("Error").Call(),
+	}))
+}
+
+// returnError returns the supplied error, along with a nil map of resolved
+// values if they are recorded. The failure condition is set first, if it
+// should be, with a message of the error.
+func returnError(mo managedOptions, err *jen.Statement) *jen.Statement {
+	if mo.FailureCondition != nil {
+		fc := mo.FailureCondition
+		mo.FailureCondition = nil
+		return mo.Locals.Err().Op("=").Add(err).Line().Add(fc.Clone()).Line().Add(returnError(mo, mo.Locals.Err()))
+	}
+	if mo.ResolvedValues {
+		return jen.Return(jen.Nil(), err)
+	}
+	return jen.Return(err)
+}
+
+// returnWrapped returns err, wrapped with the supplied path of the field that
+// was being resolved when it occurred, and with the kind that the supplied
+// reference of the field targets if it is returned as a ReferenceError.
+func returnWrapped(mo managedOptions, ref Reference, path string) *jen.Statement {
+	if mo.ReferenceError {
+		kind := ref.RemoteTypePath[strings.LastIndex(ref.RemoteTypePath, ".")+1:]
+		return returnError(mo, jen.Op("&").Id("ReferenceError").Values(jen.Dict{
+			jen.Id("FieldPath"):  jen.Lit(path),
+			jen.Id("TargetKind"): jen.Lit(kind),
+			jen.Id("Err"):        mo.Locals.Err(),
+		}))
+	}
+	if mo.ErrorWrapper != nil {
+		return returnError(mo, mo.ErrorWrapper.Clone().Call(mo.Locals.Err(), jen.Lit(path)))
+	}
+	return returnError(mo, wrapError(mo, path))
+}
+
+// wrapError returns err, wrapped with the supplied message using errors.Wrap,
+// or errors.WithMessage if errors are wrapped with messages.
+func wrapError(mo managedOptions, message string) *jen.Statement {
+	wrap := "Wrap"
+	if mo.WrapWithMessage {
+		wrap = "WithMessage"
+	}
+	return jen.Qual("github.com/pkg/errors", wrap).Call(mo.Locals.Err(), jen.Lit(message))
+}
+
+var regexLoopIndex = regexp.MustCompile(`\[(i\d*)\]`)
+
+// resolvedKey returns the key a value resolved for the supplied fields is
+// recorded under, which is their path without the receiver. Loop indices
+// within the path, for example [i0], are formatted into the key.
+func resolvedKey(fields ...string) *jen.Statement {
+	names := make([]string, 0, len(fields)-1)
+	for _, f := range fields[1:] {
+		names = append(names, unhoisted(f))
+	}
+	path := strings.Join(names, ".")
+	var args []jen.Code
+	for _, m := range regexLoopIndex.FindAllStringSubmatch(path, -1) {
+		args = append(args, jen.Id(m[1]))
+	}
+	format := regexLoopIndex.ReplaceAllString(path, "[%d]")
+	if len(args) == 0 {
+		return jen.Lit(format)
+	}
+	return jen.Qual("fmt", "Sprintf").Call(append([]jen.Code{jen.Lit(format)}, args...)...)
+}
+
+// recordResolved returns a statement that records the supplied resolved value
+// under the supplied key, or nothing if resolved values are not recorded.
+func recordResolved(mo managedOptions, key, value *jen.Statement) *jen.Statement {
+	if !mo.ResolvedValues {
+		return &jen.Statement{}
+	}
+	return mo.Locals.Id("resolved").Index(key).Op("=").Add(value)
+}
+
+// recordResolvedValues returns a loop that records each of the values resolved
+// for the supplied fields, or nothing if resolved values are not recorded.
+func recordResolvedValues(mo managedOptions, fields ...string) *jen.Statement {
+	if !mo.ResolvedValues {
+		return &jen.Statement{}
+	}
+	indexed := append(append([]string{}, fields[:len(fields)-1]...), fields[len(fields)-1]+"[i]")
+	return jen.For(jen.List(jen.Id("i"), jen.Id("v")).Op(":=").Range().Add(mo.Locals.Id("mrsp")).Dot("ResolvedValues")).Block(
+		mo.Locals.Id("resolved").Index(resolvedKey(indexed...)).Op("=").Id("v"),
+	)
+}
+
+// clean returns the name of the supplied field, without any of the prefixes
+// that denote its kind. Only prefixes are removed, so the index or type
+// assertion that encapsulate appends to a field it rewrites, for example
+// Subnets[i2], is kept.
+func clean(field string) string {
+	field = strings.TrimLeft(field, "[]*")
+	if strings.HasPrefix(field, "(") {
+		field = field[strings.Index(field, ")")+1:]
+	}
+	return field
+}
+
+// encapsulated returns true if encapsulate wraps the calls it is supplied for
+// the supplied fields in a block.
+func encapsulated(fields []string) bool {
+	for _, f := range fields {
+		if clean(f) != f {
+			return true
+		}
+	}
+	return false
+}
+
+// regexLoopSuffix matches a field of a slice that encapsulate iterates over,
+// for example Subnets[i2] or Subnets[i2]@e2, or that it asserts the type of,
+// for example Config.(*Custom).
+var regexLoopSuffix = regexp.MustCompile(`^(.*?)(\[i\d*\]|\.\(\*\w+\))(?:` + hoisted + `\w+)?$`)
+
+// valueFields returns the Go fields of the value field of the supplied
+// reference, including the key of a paved map.
+func valueFields(ref Reference) []string {
+	if ref.Paved == nil {
+		return ref.GoValueFieldPath
+	}
+	return append(append([]string{}, ref.GoValueFieldPath...), ref.Paved.Key)
+}
+
+// valuePath returns the field path of the value field of the supplied
+// reference without its first skipped fields, including the key of a paved map
+// or, for a nested paved reference, the path within it.
+func valuePath(ref Reference, skip int) string {
+	if ref.Paved == nil || !ref.Paved.Nested {
+		return GoPath(valueFields(ref)[skip:]...)
+	}
+	return appendPath(GoPath(ref.GoValueFieldPath[skip:]...), ref.Paved.Key)
+}
+
+// fieldPaths returns the Go path of the field of the supplied reference of the
+// supplied managed resource, followed by its JSON path, for example
+// Spec.ForProvider.SubnetID (spec.forProvider.subnetId), as errors name it.
+// The JSON path is omitted if it is the same as the Go path.
+func fieldPaths(n *types.Named, ref Reference) string {
+	goPath, jsonPath := valuePath(ref, 1), valueJSONPath(n, ref)
+	if goPath == jsonPath {
+		return goPath
+	}
+	return fmt.Sprintf("%s (%s)", goPath, jsonPath)
+}
+
+// appendPath returns the supplied field path followed by the segments of the
+// other supplied field path. Both must have been formatted by the fieldpath
+// package.
+func appendPath(path, other string) string {
+	p, _ := fieldpath.Parse(path)
+	o, _ := fieldpath.Parse(other)
+	return append(p, o...).String()
+}
+
+// GoPath returns the field path of the supplied Go fields, as named by the
+// Traverser or as rewritten by encapsulate, for example
+// Spec.ForProvider.Subnets[*].ID. Slices that are iterated over select every
+// element.
+func GoPath(fields ...string) string {
+	p := make(fieldpath.Path, 0, len(fields))
+	for _, f := range fields {
+		all := strings.HasPrefix(f, "[]")
+		if m := regexLoopSuffix.FindStringSubmatch(f); m != nil {
+			f, all = m[1], strings.HasPrefix(m[2], "[")
+		}
+		p = append(p, fieldpath.Field(clean(f)))
+		if all {
+			p = append(p, fieldpath.All())
+		}
+	}
+	return p.String()
+}
+
+// A resolutionCallFn returns the statements that resolve a reference of the
+// supplied fields, as rewritten by encapsulate. They don't end with a line, so
+// that they can be wrapped in a block as they are; callers that follow them
+// with other statements add one.
+type resolutionCallFn func(parentFields ...string) *jen.Statement
+
+// hoisted separates a field of a slice that encapsulate iterates over from the
+// local that it hoists the element into, for example Subnets[i2]@e2.
+const hoisted = "@"
+
+// unhoisted returns the supplied field without the local that its element was
+// hoisted into, if any.
+func unhoisted(field string) string {
+	if i := strings.Index(field, hoisted); i >= 0 {
+		return field[:i]
+	}
+	return field
+}
+
+// goExpr returns the Go expression of the supplied fields, as rewritten by
+// encapsulate. The expression of the fields after an element that was hoisted
+// into a local starts from the local.
+func goExpr(fields ...string) *jen.Statement {
+	expr := jen.Id(fields[0])
+	for _, f := range fields[1:] {
+		if i := strings.Index(f, hoisted); i >= 0 {
+			expr = jen.Id(f[i+len(hoisted):])
+			continue
+		}
+		expr = expr.Dot(f)
+	}
+	return expr
+}
+
+// encapsulate goes through the fields and encapsulates the final call with nil
+// guard, for loops and/or type switches.
+func encapsulate(index int, callFn resolutionCallFn, fields ...string) *jen.Statement {
+	if len(fields) <= index {
+		return callFn(fields...)
+	}
+	field := fields[index]
+	fieldPath := goExpr(append(append([]string{}, fields[:index]...), clean(field))...)
+	switch {
+	case strings.HasPrefix(field, "*"):
+		fields[index] = clean(fields[index])
+		return jen.If(fieldPath.Op("!=").Nil()).Block(encapsulate(index+1, callFn, fields...))
+	case strings.HasPrefix(field, "("):
+		impl := field[1:strings.Index(field, ")")]
+		fields[index] = clean(fields[index]) + ".(*" + impl + ")"
+		return jen.Switch(fieldPath.Assert(jen.Id("type"))).Block(
+			jen.Case(jen.Op("*").Id(impl)).Block(encapsulate(index+1, callFn, fields...)),
+		)
+	case strings.HasPrefix(field, "[]*"):
+		// Elements that are pointers are hoisted into a local, which aliases
+		// the element, so that nested expressions don't index the slice
+		// again.
+		i, e := fmt.Sprintf("i%d", index), fmt.Sprintf("e%d", index)
+		fields[index] = clean(fields[index]) + "[" + i + "]" + hoisted + e
+		body := jen.If(jen.Id(e).Op("!=").Nil()).Block(encapsulate(index+1, callFn, fields...))
+		return jen.For(jen.Id(i).Op(":=").Range().Add(fieldPath.Clone())).Block(
+			jen.Id(e).Op(":=").Add(fieldPath).Index(jen.Id(i)),
+			body,
+		)
+	case strings.HasPrefix(field, "[]"):
+		// Elements that are values are indexed directly, because a copy of
+		// one in a local would not write back what is resolved into it.
+		i := fmt.Sprintf("i%d", index)
+		fields[index] = clean(fields[index]) + "[" + i + "]"
+		body := encapsulate(index+1, callFn, fields...)
+		// Ranging evaluates the length of the slice once, so elements that
+		// resolution appends to it, if any, are not resolved again.
+		return jen.For(jen.Id(i).Op(":=").Range().Add(fieldPath)).Block(body)
+	default:
+		return encapsulate(index+1, callFn, fields...)
+	}
+}
+
+// recordDeprecation returns a call to the deprecation recorder that is made if
+// the supplied reference or selector is set, or nothing if the reference is not
+// deprecated or no recorder is configured.
+func recordDeprecation(ref Reference, opts *resolveReferencesOptions, isSet *jen.Statement) *jen.Statement {
+	if ref.DeprecationMessage == "" || opts.DeprecationRecorder == nil {
+		return &jen.Statement{}
+	}
+	return jen.If(isSet).Block(
+		opts.DeprecationRecorder.Clone().Call(jen.Id("ctx"), jen.Id(ref.GoValueFieldPath[0]), jen.Lit(GoPath(ref.GoValueFieldPath...)), jen.Lit(ref.DeprecationMessage)),
+	).Line()
+}
+
+// parseFormatted returns the supplied value with the literal parts of the
+// supplied format removed.
+func parseFormatted(vf *ValueFormat, value *jen.Statement) *jen.Statement {
+	if vf.Prefix != "" {
+		value = jen.Qual("strings", "TrimPrefix").Call(value, jen.Lit(vf.Prefix))
+	}
+	if vf.Suffix != "" {
+		value = jen.Qual("strings", "TrimSuffix").Call(value, jen.Lit(vf.Suffix))
+	}
+	return value
+}
+
+// formatResolved embeds a non-empty resolved value in the supplied format.
+func formatResolved(vf *ValueFormat, mo managedOptions) *jen.Statement {
+	formatted := mo.Locals.Id("rsp").Dot("ResolvedValue")
+	if vf.Prefix != "" {
+		formatted = jen.Lit(vf.Prefix).Op("+").Add(formatted)
+	}
+	if vf.Suffix != "" {
+		formatted = formatted.Op("+").Lit(vf.Suffix)
+	}
+	return jen.If(mo.Locals.Id("rsp").Dot("ResolvedValue").Op("!=").Lit("")).Block(
+		mo.Locals.Id("rsp").Dot("ResolvedValue").Op("=").Add(formatted),
+	)
+}
+
+// normalize returns the supplied current value normalized by the normalizer of
+// the supplied reference, and a statement that sets the resolved value only if
+// it is not the normalized current value, so that a current value that only
+// differs from it in normalization is kept as it is. It returns the supplied
+// current value and statement if the reference has no normalizer.
+func normalize(ref Reference, mo managedOptions, currentValue, setResolvedValue *jen.Statement) (*jen.Statement, *jen.Statement) {
+	if ref.Normalizer == nil {
+		return currentValue, setResolvedValue
+	}
+	normalized := ref.Normalizer.Clone().Call(currentValue)
+	return normalized, jen.If(mo.Locals.Id("rsp").Dot("ResolvedValue").Op("!=").Add(normalized.Clone())).Block(setResolvedValue)
+}
+
+// withScope adds the namespace of the supplied receiver to the supplied
+// resolution request if the receiver is namespace scoped and the referenced
+// type is not cluster scoped. The supplied selector, and the field selector of
+// the reference if it has one, are added to the request unless selectors are
+// disabled.
+func withScope(request jen.Dict, ref Reference, mo managedOptions, receiver string, selectorFieldPath *jen.Statement) jen.Dict {
+	if ns := namespace(ref, mo, receiver); ns != nil {
+		request[jen.Id("Namespace")] = ns
+	}
+	if !mo.SelectorsDisabled {
+		request[jen.Id("Selector")] = selectorFieldPath
+		if ref.FieldSelector != "" {
+			request[jen.Id("FieldSelector")] = jen.Lit(ref.FieldSelector)
+		}
+	}
+	return request
+}
+
+// namespace returns the namespace in which the supplied reference of the
+// supplied receiver is resolved, or nil if it is not resolved in a namespace.
+func namespace(ref Reference, mo managedOptions, receiver string) *jen.Statement {
+	switch {
+	case ref.ClusterScoped:
+		return nil
+	case mo.Tenant:
+		return mo.Locals.Id("tenant")
+	case mo.Namespaced:
+		return selectMethod(mo.Type, receiver, "GetNamespace").Call()
+	}
+	return nil
+}
+
+// immutable returns a resolution call that only resolves the supplied
+// reference of an immutable field if the managed resource has no external name,
+// and so wasn't created yet, or if the field is empty. A resolved value that
+// differed from that of the field would otherwise be an impossible update.
+func immutable(ref Reference, mo managedOptions, opts *resolveReferencesOptions, callFn resolutionCallFn) resolutionCallFn {
+	if !ref.Immutable {
+		return callFn
+	}
+	return func(fields ...string) *jen.Statement {
+		currentValuePath := goExpr(fields...)
+		isEmpty := currentValuePath.Clone().Op("==").Lit("")
+		switch {
+		case ref.IsSlice:
+			isEmpty = jen.Len(currentValuePath.Clone()).Op("==").Lit(0)
+		case ref.IsPointer:
+			isEmpty = currentValuePath.Clone().Op("==").Nil().Op("||").Op("*").Add(currentValuePath.Clone()).Op("==").Lit("")
+		}
+		return jen.If(jen.Qual(opts.MetaPackagePath, "GetExternalName").Call(jen.Id(fields[0])).Op("==").Lit("").Op("||").Add(isEmpty)).Block(callFn(fields...))
+	}
+}
+
+// withTimeout returns the supplied assignment of err, which makes a request to
+// resolve the supplied reference, in a block whose context is cancelled once
+// the timeout of the reference elapses, or as soon as the request returns. The
+// context is shadowed, so the request needn't know of the timeout. Its
+// deadline is relative to the configured clock, if there is one. It returns
+// the assignment itself if the reference has no timeout.
+func withTimeout(ref Reference, mo managedOptions, assign *jen.Statement) *jen.Statement {
+	if ref.Timeout <= 0 {
+		return assign
+	}
+	withTimeout := jen.Qual("context", "WithTimeout").Call(jen.Id("ctx"), duration(ref.Timeout))
+	if mo.Clock != nil {
+		withTimeout = jen.Qual("context", "WithDeadline").Call(jen.Id("ctx"), mo.Clock.Clone().Dot("Now").Call().Dot("Add").Call(duration(ref.Timeout)))
+	}
+	return jen.Block(
+		jen.List(jen.Id("ctx"), mo.Locals.Id("cancel")).Op(":=").Add(withTimeout),
+		assign,
+		mo.Locals.Id("cancel").Call(),
+	)
+}
+
+// duration returns the supplied duration as a multiple of the largest unit of
+// the time package that it is a whole number of, for example 5 * time.Second.
+func duration(d time.Duration) *jen.Statement {
+	for _, u := range []struct {
+		unit time.Duration
+		name string
+	}{
+		{unit: time.Hour, name: "Hour"},
+		{unit: time.Minute, name: "Minute"},
+		{unit: time.Second, name: "Second"},
+		{unit: time.Millisecond, name: "Millisecond"},
+		{unit: time.Microsecond, name: "Microsecond"},
+	} {
+		switch {
+		case d == u.unit:
+			return jen.Qual("time", u.name)
+		case d%u.unit == 0:
+			return jen.Lit(int(d/u.unit)).Op("*").Qual("time", u.name)
+		}
+	}
+	return jen.Qual("time", "Duration").Call(jen.Lit(int(d)))
+}
+
+// selectMethod returns a selector of the named method of the supplied receiver,
+// which is of the supplied type. Fields are always selected by their full path
+// from the receiver, but methods like GetNamespace are usually promoted from an
+// embedded struct, and may be shadowed by a field or method of the same name
+// that is less deeply embedded. Such methods are selected through the embedded
+// fields that promote them, for example mg.ObjectMeta.GetNamespace.
+func selectMethod(n *types.Named, receiver, name string) *jen.Statement {
+	s := jen.Id(receiver)
+	if n == nil {
+		return s.Dot(name)
+	}
+	if lookupMethod(n, name) {
+		return s.Dot(name)
+	}
+	for _, f := range promotedBy(n, name, map[types.Type]bool{}) {
+		s = s.Dot(f)
+	}
+	return s.Dot(name)
+}
+
+// lookupMethod returns true if the supplied name selects a method of a pointer
+// to the supplied type.
+func lookupMethod(t types.Type, name string) bool {
+	var pkg *types.Package
+	if n, ok := t.(*types.Named); ok {
+		pkg = n.Obj().Pkg()
+	}
+	if _, ok := t.(*types.Pointer); !ok {
+		t = types.NewPointer(t)
+	}
+	obj, _, _ := types.LookupFieldOrMethod(t, true, pkg, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// promotedBy returns the names of the embedded fields of the supplied type
+// through which the named method can be selected, or nil if there are none.
+// Less deeply embedded fields are preferred.
+func promotedBy(t types.Type, name string, seen map[types.Type]bool) []string {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if !f.Embedded() {
+			continue
+		}
+		if lookupMethod(f.Type(), name) {
+			return []string{f.Name()}
+		}
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if !f.Embedded() {
+			continue
+		}
+		if path := promotedBy(f.Type(), name, seen); path != nil {
+			return append([]string{f.Name()}, path...)
+		}
+	}
+	return nil
+}
+
+// rejectSelector returns a check that the selector of the supplied reference
+// is not set, or nothing if selectors are not disabled.
+func rejectSelector(ref Reference, mo managedOptions, selectorFieldPath *jen.Statement) *jen.Statement {
+	if !mo.SelectorsDisabled {
+		return &jen.Statement{}
+	}
+	msg := fmt.Sprintf("%s: cannot use %s, selectors are disabled", GoPath(ref.GoValueFieldPath...), ref.GoSelectorFieldName)
+	return jen.If(selectorFieldPath.Clone().Op("!=").Nil()).Block(
+		returnError(mo, jen.Qual("github.com/pkg/errors", "New").Call(jen.Lit(msg))),
+	).Line()
+}
+
+// annotationExtractor returns a function that extracts the value of the
+// supplied annotation from a resolved managed resource.
+func annotationExtractor(key, resourcePkgPath string) *jen.Statement {
+	return jen.Func().Params(jen.Id("res").Qual(resourcePkgPath, "Managed")).String().Block(
+		jen.Return(jen.Id("res").Dot("GetAnnotations").Call().Index(jen.Lit(key))),
+	)
+}
+
+// clearSelector returns a statement that clears the named selector field of the
+// struct reached through the supplied parents if the reference was resolved by
+// name, or nothing if selectors are not cleared or the reference can't have
+// been resolved by name, in which case resolvedByName is nil. It must be
+// generated before the resolved reference is set.
+func clearSelector(mo managedOptions, resolvedByName, path *jen.Statement, parents []PathSegment, name string) *jen.Statement {
+	if !mo.ClearSelectors || resolvedByName == nil {
+		return &jen.Statement{}
+	}
+	if guard := parentsGuard(path, parents); guard != nil {
+		resolvedByName = resolvedByName.Clone().Op("&&").Add(guard)
+	}
+	return jen.If(resolvedByName).Block(
+		parentsPath(path, parents).Dot(name).Op("=").Nil(),
+	)
+}
+
+// parentsPath returns the path of the struct reached through the supplied
+// parents from the supplied path.
+func parentsPath(path *jen.Statement, parents []PathSegment) *jen.Statement {
+	p := path.Clone()
+	for _, s := range parents {
+		p = p.Dot(s.Name)
+	}
+	return p
+}
+
+// parentsGuard returns a condition that is true if none of the supplied parents
+// reached from the supplied path are nil pointers, or nil if none of them are
+// pointers.
+func parentsGuard(path *jen.Statement, parents []PathSegment) *jen.Statement {
+	var guard *jen.Statement
+	p := path.Clone()
+	for _, s := range parents {
+		p = p.Clone().Dot(s.Name)
+		if s.Pointer == nil {
+			continue
+		}
+		if guard == nil {
+			guard = p.Clone().Op("!=").Nil()
+			continue
+		}
+		guard = guard.Op("&&").Add(p.Clone()).Op("!=").Nil()
+	}
+	return guard
+}
+
+// readThrough returns an expression that reads the named field of the struct
+// reached through the supplied parents from the supplied path, and statements
+// that must precede it. If any of the parents are pointers, the field is read
+// into a variable with the supplied name and type only if none of them are nil,
+// independently of the guards of the value field.
+func readThrough(id string, t, path *jen.Statement, parents []PathSegment, name string) (*jen.Statement, *jen.Statement) {
+	field := parentsPath(path, parents).Dot(name)
+	guard := parentsGuard(path, parents)
+	if guard == nil {
+		return field, &jen.Statement{}
+	}
+	return jen.Id(id), &jen.Statement{
+		jen.Var().Id(id).Add(t.Clone()),
+		jen.Line(),
+		jen.If(guard).Block(jen.Id(id).Op("=").Add(field)),
+		jen.Line(),
+	}
+}
+
+// writeThrough returns statements that write the supplied value to the named
+// field of the struct reached through the supplied parents from the supplied
+// path. Parents that are nil pointers are allocated if the supplied condition
+// is true, so that a resolved reference is never lost, and the value is only
+// written if none of them are nil. Parents are always allocated, and the value
+// always written, if the condition is nil.
+func writeThrough(path *jen.Statement, parents []PathSegment, name string, value, allocate *jen.Statement) *jen.Statement {
+	field := parentsPath(path, parents).Dot(name)
+	guard := parentsGuard(path, parents)
+	if guard == nil {
+		return field.Op("=").Add(value)
+	}
+	allocations := allocateParents(path, parents)
+	if allocate == nil {
+		written := &jen.Statement{}
+		for _, a := range allocations {
+			written.Add(a, jen.Line())
+		}
+		return written.Add(field.Op("=").Add(value))
+	}
+	return &jen.Statement{
+		jen.If(allocate).Block(allocations...),
+		jen.Line(),
+		jen.If(guard).Block(field.Op("=").Add(value)),
+	}
+}
+
+// allocateParents returns statements that allocate each of the supplied parents
+// reached from the supplied path that is a nil pointer.
+func allocateParents(path *jen.Statement, parents []PathSegment) []jen.Code {
+	allocations := make([]jen.Code, 0, len(parents))
+	p := path.Clone()
+	for _, s := range parents {
+		p = p.Clone().Dot(s.Name)
+		if s.Pointer == nil {
+			continue
+		}
+		allocations = append(allocations, jen.If(p.Clone().Op("==").Nil()).Block(
+			p.Clone().Op("=").Op("&").Add(s.Pointer.Clone()).Values(),
+		))
+	}
+	return allocations
+}
+
+// setMirrors returns statements that write the resolved value to each mirror of
+// the supplied reference, whose current value field is held by the struct at
+// the supplied path. Parents of a mirror that are nil pointers, and a map that
+// is nil, are allocated so that the value is always written.
+func setMirrors(ref Reference, referencePkgPath string, mo managedOptions, path *jen.Statement) *jen.Statement {
+	s := make([]*jen.Statement, 0, len(ref.Mirrors))
+	for _, m := range ref.Mirrors {
+		value := mo.Locals.Id("rsp").Dot("ResolvedValue")
+		if m.IsPointer {
+			value = jen.Qual(referencePkgPath, "ToPtrValue").Call(value)
+		}
+		if m.Key == "" {
+			s = append(s, writeThrough(path, m.Parents, m.FieldName, value, nil))
+			continue
+		}
+		field := parentsPath(path, m.Parents).Dot(m.FieldName)
+		for _, a := range allocateParents(path, m.Parents) {
+			s = append(s, jen.Add(a))
+		}
+		s = append(s,
+			jen.If(field.Clone().Op("==").Nil()).Block(field.Clone().Op("=").Add(m.MapType.Clone()).Values()),
+			field.Clone().Index(jen.Lit(m.Key)).Op("=").Add(value),
 		)
-		cfg := &xptypes.ProcessorConfig{
-			Field: refProcessor,
-			Named: xptypes.NamedProcessorChain{},
+	}
+	return lines(s...)
+}
+
+// readReferences returns an expression that reads the reference field of the
+// supplied reference, and statements that must precede it; see readThrough.
+// The reference package takes the reference of a single value by pointer and
+// those of a slice by value, so a reference field that holds them otherwise is
+// read into a variable with the supplied name. A single reference that is held
+// by value is read only if it names a referenced resource. A reference without
+// a reference field reads as nil.
+func readReferences(id string, ref Reference, path *jen.Statement) (*jen.Statement, *jen.Statement) {
+	if ref.GoRefFieldName == "" {
+		return jen.Nil(), &jen.Statement{}
+	}
+	if !ref.IsRefValue && !ref.IsRefPointers {
+		return readThrough(id, ref.GoRefFieldType, path, ref.GoRefFieldParents, ref.GoRefFieldName)
+	}
+	field := parentsPath(path, ref.GoRefFieldParents).Dot(ref.GoRefFieldName)
+	declare := jen.Var().Id(id).Index().Add(ref.GoReferenceType.Clone())
+	read := jen.For(jen.List(jen.Id("_"), jen.Id("rr")).Op(":=").Range().Add(field)).Block(
+		jen.If(jen.Id("rr").Op("!=").Nil()).Block(
+			jen.Id(id).Op("=").Append(jen.Id(id), jen.Op("*").Id("rr")),
+		),
+	)
+	if ref.IsRefValue {
+		declare = jen.Var().Id(id).Op("*").Add(ref.GoReferenceType.Clone())
+		read = jen.If(field.Clone().Dot("Name").Op("!=").Lit("")).Block(
+			jen.Id(id).Op("=").Op("&").Add(field),
+		)
+	}
+	if guard := parentsGuard(path, ref.GoRefFieldParents); guard != nil {
+		read = jen.If(guard).Block(read)
+	}
+	return jen.Id(id), &jen.Statement{declare, jen.Line(), read, jen.Line()}
+}
+
+// writeReferences returns statements that write the supplied resolved
+// references to the reference field of the supplied reference, which holds
+// them by pointer or by value as the reference package does unless the
+// reference says otherwise; see readReferences. Nothing is written if the
+// reference says so.
+func writeReferences(ref Reference, path, resolved *jen.Statement) *jen.Statement {
+	switch {
+	case ref.NoRefWriteBack:
+		return &jen.Statement{}
+	case ref.IsRefValue:
+		return jen.If(resolved.Clone().Op("!=").Nil()).Block(
+			writeThrough(path, ref.GoRefFieldParents, ref.GoRefFieldName, jen.Op("*").Add(resolved), nil),
+		)
+	case ref.IsRefPointers:
+		return jen.If(jen.Len(resolved.Clone()).Op(">").Lit(0)).Block(
+			jen.Id("resolvedRefs").Op(":=").Make(jen.Index().Op("*").Add(ref.GoReferenceType.Clone()), jen.Len(resolved.Clone())),
+			jen.For(jen.Id("i").Op(":=").Range().Add(resolved.Clone())).Block(
+				jen.Id("resolvedRefs").Index(jen.Id("i")).Op("=").Op("&").Add(resolved.Clone()).Index(jen.Id("i")),
+			),
+			writeThrough(path, ref.GoRefFieldParents, ref.GoRefFieldName, jen.Id("resolvedRefs"), nil),
+		)
+	case ref.IsSlice:
+		return writeThrough(path, ref.GoRefFieldParents, ref.GoRefFieldName, resolved, jen.Len(resolved.Clone()).Op(">").Lit(0))
+	}
+	return writeThrough(path, ref.GoRefFieldParents, ref.GoRefFieldName, resolved, resolved.Clone().Op("!=").Nil())
+}
+
+// referencesSet returns an expression that is true if the supplied reference
+// field path, read by readReferences, holds a reference, or references if the
+// reference is of a slice. It is nil if the reference has no reference field.
+func referencesSet(ref Reference, path *jen.Statement) *jen.Statement {
+	switch {
+	case ref.GoRefFieldName == "":
+		return nil
+	case ref.IsSlice:
+		return jen.Len(path.Clone()).Op(">").Lit(0)
+	}
+	return path.Clone().Op("!=").Nil()
+}
+
+// eitherSet returns an expression that is true if the supplied reference or
+// selector is set; see referencesSet.
+func eitherSet(ref Reference, refPath, selectorPath *jen.Statement) *jen.Statement {
+	isRef := referencesSet(ref, refPath)
+	if isRef == nil {
+		return selectorPath.Clone().Op("!=").Nil()
+	}
+	return isRef.Op("||").Add(selectorPath.Clone()).Op("!=").Nil()
+}
+
+// scoped returns the supplied declarations followed by the supplied statements,
+// in a block if any of the declarations aren't empty so that the variables they
+// declare don't clash with those of other resolution calls.
+func scoped(declarations, statements jen.Statement) *jen.Statement {
+	all := append(declarations, statements...)
+	for _, c := range declarations {
+		if s, ok := c.(*jen.Statement); ok && len(*s) > 0 {
+			return jen.Block(&all)
 		}
-		if err := traverser.Traverse(n, cfg); err != nil {
-			panic(errors.Wrapf(err, "cannot traverse the type tree of %s", n.Obj().Name()))
+	}
+	return &all
+}
+
+// skipEmpty returns the supplied statements of a resolution call, or, if
+// resolution of fields whose reference and selector are both unset is skipped,
+// a statement that runs them only if the supplied condition that either is set
+// is true.
+func skipEmpty(mo managedOptions, isSet *jen.Statement, statements ...jen.Code) []jen.Code {
+	if !mo.SkipEmpty {
+		return statements
+	}
+	return []jen.Code{jen.If(isSet).Block(statements...)}
+}
+
+// lines returns the supplied statements that aren't empty, each on a line of
+// its own. The last doesn't end with a line, so that they can end a block.
+func lines(statements ...*jen.Statement) *jen.Statement {
+	s := &jen.Statement{}
+	for _, st := range statements {
+		if len(*st) == 0 {
+			continue
 		}
-		refs := refProcessor.GetReferences()
-		if len(refs) == 0 {
-			return
+		if len(*s) > 0 {
+			s.Line()
 		}
-		hasMultiResolution := false
-		hasSingleResolution := false
-		resolverCalls := make(jen.Statement, len(refs))
-		for i, ref := range refs {
-			if ref.IsSlice {
-				hasMultiResolution = true
-				resolverCalls[i] = encapsulate(0, multiResolutionCall(ref, referencePkgPath), ref.GoValueFieldPath...).Line()
-			} else {
-				hasSingleResolution = true
-				resolverCalls[i] = encapsulate(0, singleResolutionCall(ref, referencePkgPath), ref.GoValueFieldPath...).Line()
+		s.Add(st)
+	}
+	return s
+}
+
+// oneOf returns a resolution call that is made only if none of the siblings
+// of the supplied reference are set, if it is a member of a union struct. The
+// first member of the union also checks that the reference or selector of at
+// most one member is set.
+func oneOf(ref Reference, mo managedOptions, callFn resolutionCallFn) resolutionCallFn {
+	if ref.OneOf == nil {
+		return callFn
+	}
+	return func(fields ...string) *jen.Statement {
+		prefixPath := goExpr(fields[:len(fields)-1]...)
+		check := &jen.Statement{}
+		if len(ref.OneOf.Members) > 1 {
+			names := make([]string, len(ref.OneOf.Members))
+			counts := make([]jen.Code, len(ref.OneOf.Members))
+			for i, m := range ref.OneOf.Members {
+				names[i] = m.GoValueFieldPath[len(m.GoValueFieldPath)-1]
+				isSet := prefixPath.Clone().Dot(m.GoRefFieldName).Op("!=").Nil()
+				if m.IsRefValue {
+					isSet = prefixPath.Clone().Dot(m.GoRefFieldName).Dot("Name").Op("!=").Lit("")
+				}
+				if m.IsSlice {
+					isSet = jen.Len(prefixPath.Clone().Dot(m.GoRefFieldName)).Op(">").Lit(0)
+				}
+				counts[i] = jen.If(isSet.Op("||").Add(prefixPath.Clone().Dot(m.GoSelectorFieldName)).Op("!=").Nil()).Block(
+					jen.Id("set").Op("++"),
+				)
 			}
+			msg := fmt.Sprintf("%s: cannot set the reference or selector of more than one of %s", GoPath(ref.GoValueFieldPath[:len(ref.GoValueFieldPath)-1]...), strings.Join(names, ", "))
+			check = jen.Block(append(append([]jen.Code{jen.Id("set").Op(":=").Lit(0)}, counts...),
+				jen.If(jen.Id("set").Op(">").Lit(1)).Block(
+					returnError(mo, jen.Qual("github.com/pkg/errors", "New").Call(jen.Lit(msg))),
+				),
+			)...).Line()
 		}
-		var initStatements jen.Statement
-		if hasSingleResolution {
-			initStatements = append(initStatements, jen.Var().Id("rsp").Qual(referencePkgPath, "ResolutionResponse"))
+		if len(ref.OneOf.Siblings) == 0 {
+			return check.Add(callFn(fields...))
 		}
-		if hasMultiResolution {
-			initStatements = append(initStatements, jen.Line().Var().Id("mrsp").Qual(referencePkgPath, "MultiResolutionResponse"))
+		unset := &jen.Statement{}
+		for i, s := range ref.OneOf.Siblings {
+			if i > 0 {
+				unset.Op("&&")
+			}
+			unset.Add(prefixPath.Clone().Dot(s)).Op("==").Nil()
 		}
+		return check.If(unset).Block(callFn(fields...))
+	}
+}
 
-		f.Commentf("ResolveReferences of this %s.", o.Name())
-		f.Func().Params(jen.Id(receiver).Op("*").Id(o.Name())).Id("ResolveReferences").Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("c").Qual(clientPath, "Reader")).Error().Block(
-			jen.Id("r").Op(":=").Qual(referencePkgPath, "NewAPIResolver").Call(jen.Id("c"), jen.Id(receiver)),
-			jen.Line(),
-			&initStatements,
-			jen.Var().Err().Error(),
-			jen.Line(),
-			&resolverCalls,
-			jen.Line(),
-			jen.Return(jen.Nil()),
+// patternVar returns the name of the package-level variable that holds the
+// supplied validation pattern, once compiled. The name is derived from the
+// pattern, so that it doesn't change between runs.
+func patternVar(pattern string) string {
+	sum := sha256.Sum256([]byte(pattern))
+	return fmt.Sprintf("validationPattern%x", sum[:4])
+}
+
+// validate returns a check that the resolved value of the supplied reference is
+// valid, or nothing if it is not validated.
+func validate(ref Reference, mo managedOptions) *jen.Statement {
+	if ref.Validation == nil {
+		return &jen.Statement{}
+	}
+	path := GoPath(ref.GoValueFieldPath...)
+	if ref.Validation.Validator != nil {
+		return jen.If(mo.Locals.Err().Op(":=").Add(ref.Validation.Validator.Clone()).Call(mo.Locals.Id("rsp").Dot("ResolvedValue")), mo.Locals.Err().Op("!=").Nil()).Block(
+			returnWrapped(mo, ref, path),
+		).Line()
+	}
+	return jen.If(jen.Op("!").Id(patternVar(ref.Validation.Pattern)).Dot("MatchString").Call(mo.Locals.Id("rsp").Dot("ResolvedValue"))).Block(
+		returnError(mo, jen.Qual("github.com/pkg/errors", "Errorf").Call(jen.Lit(path+": resolved value %q does not match %s"), mo.Locals.Id("rsp").Dot("ResolvedValue"), jen.Lit(ref.Validation.Pattern))),
+	).Line()
+}
+
+// validateProviderConfig returns a call to the provider config validator for
+// each resolved reference, or nothing if the supplied reference does not
+// require the same provider config.
+func validateProviderConfig(ref Reference, mo managedOptions, opts *resolveReferencesOptions, receiver string, multi bool) *jen.Statement {
+	if !ref.SameProviderConfig {
+		return &jen.Statement{}
+	}
+	path := GoPath(ref.GoValueFieldPath...)
+	call := func(resolved *jen.Statement) *jen.Statement {
+		return jen.If(mo.Locals.Err().Op(":=").Add(opts.ProviderConfigValidator.Clone()).Call(jen.Id("ctx"), jen.Id("c"), jen.Id(receiver), resolved, ref.RemoteType), mo.Locals.Err().Op("!=").Nil()).Block(
+			returnWrapped(mo, ref, path),
 		)
 	}
+	if multi {
+		return jen.For(jen.Id("i").Op(":=").Range().Add(mo.Locals.Id("mrsp")).Dot("ResolvedReferences")).Block(
+			call(jen.Op("&").Add(mo.Locals.Id("mrsp")).Dot("ResolvedReferences").Index(jen.Id("i"))),
+		).Line()
+	}
+	return jen.If(mo.Locals.Id("rsp").Dot("ResolvedReference").Op("!=").Nil()).Block(
+		call(mo.Locals.Id("rsp").Dot("ResolvedReference")),
+	).Line()
 }
 
-var cleaner = strings.NewReplacer(
-	"[]", "",
-	"*", "",
-)
+func singleResolutionCall(ref Reference, referencePkgPath string, mo managedOptions, opts *resolveReferencesOptions) resolutionCallFn {
+	return func(fields ...string) *jen.Statement {
+		prefixPath := goExpr(fields[:len(fields)-1]...)
+		currentValuePath := prefixPath.Clone().Dot(fields[len(fields)-1])
+		referenceFieldPath, readReference := readReferences("ref", ref, prefixPath)
+		selectorFieldPath, readSelector := readThrough("selector", ref.GoSelectorFieldType, prefixPath, ref.GoSelectorFieldParents, ref.GoSelectorFieldName)
 
-type resolutionCallFn func(parentFields ...string) *jen.Statement
+		setResolvedValue := currentValuePath.Clone().Op("=").Add(mo.Locals.Id("rsp")).Dot("ResolvedValue")
+		if ref.IsPointer {
+			setResolvedValue = currentValuePath.Clone().Op("=").Qual(referencePkgPath, "ToPtrValue").Call(mo.Locals.Id("rsp").Dot("ResolvedValue"))
+			currentValuePath = jen.Qual(referencePkgPath, "FromPtrValue").Call(currentValuePath)
+		}
+		if ref.Format != nil {
+			currentValuePath = parseFormatted(ref.Format, currentValuePath)
+			setResolvedValue = formatResolved(ref.Format, mo).Line().Add(setResolvedValue)
+		}
+		currentValuePath, setResolvedValue = normalize(ref, mo, currentValuePath, setResolvedValue)
+		extract, declareComponents, setComponents := extractor(ref, opts, prefixPath), &jen.Statement{}, &jen.Statement{}
+		if ref.Composite != nil {
+			extract = compositeExtractor(ref.Composite, mo, opts.ResourcePackagePath)
+			declareComponents, setComponents = distributeComponents(ref.Composite, referencePkgPath, mo, prefixPath, fields)
+		}
+		isSet := eitherSet(ref, referenceFieldPath, selectorFieldPath)
+		return scoped(jen.Statement{readReference, readSelector, declareComponents}, jen.Statement(skipEmpty(mo, isSet, &jen.Statement{
+			recordDeprecation(ref, opts, isSet.Clone()),
+			rejectSelector(ref, mo, selectorFieldPath),
+			withTimeout(ref, mo, withRetry(mo, jen.List(mo.Locals.Id("rsp"), mo.Locals.Err()).Op("=").Add(mo.Locals.Id("r")).Dot("Resolve").Call(
+				jen.Id("ctx"),
+				jen.Qual(referencePkgPath, "ResolutionRequest").Values(withScope(jen.Dict{
+					jen.Id("CurrentValue"): currentValuePath,
+					jen.Id("Reference"):    referenceFieldPath,
+					jen.Id("To"): jen.Qual(referencePkgPath, "To").Values(jen.Dict{
+						jen.Id("Managed"): ref.RemoteType,
+						jen.Id("List"):    ref.RemoteListType,
+					}),
+					jen.Id("Extract"): extract,
+				}, ref, mo, fields[0], selectorFieldPath.Clone()),
+				),
+			))),
+			jen.Line(),
+			logResolution(ref, mo, opts, referencesSet(ref, referenceFieldPath), selectorFieldPath.Clone().Op("!=").Nil()),
+			jen.If(mo.Locals.Err().Op("!=").Nil()).Block(
+				returnWrapped(mo, ref, GoPath(ref.GoValueFieldPath...)),
+			),
+			jen.Line(),
+			validate(ref, mo),
+			validateProviderConfig(ref, mo, opts, fields[0], false),
+			lines(
+				setResolvedValue,
+				setMirrors(ref, referencePkgPath, mo, prefixPath),
+				setComponents,
+				recordResolved(mo, resolvedKey(fields...), mo.Locals.Id("rsp").Dot("ResolvedValue")),
+				clearSelector(mo, referencesSet(ref, referenceFieldPath), prefixPath, ref.GoSelectorFieldParents, ref.GoSelectorFieldName),
+				writeReferences(ref, prefixPath, mo.Locals.Id("rsp").Dot("ResolvedReference")),
+				recordProvenance(ref, mo, opts, fields, mo.Locals.Id("rsp").Dot("ResolvedReference"), true),
+				recordDependencies(ref, mo, mo.Locals.Id("rsp").Dot("ResolvedReference"), true),
+			),
+		})))
+	}
+}
 
-// encapsulate goes through the fields and encapsulates the final call with nil
-// guard and/or for loops.
-func encapsulate(index int, callFn resolutionCallFn, fields ...string) *jen.Statement {
-	if len(fields) <= index {
-		return callFn(fields...)
+// extractor returns the extractor of the supplied reference, whose current
+// value field is held by the struct at the supplied path. The extractor of a
+// reference with an extractor argument is called by a closure that passes it
+// the referenced resource and the value of the argument field, so that it is
+// read when the value is extracted.
+func extractor(ref Reference, opts *resolveReferencesOptions, path *jen.Statement) *jen.Statement {
+	if ref.ExtractorArg == "" {
+		return ref.Extractor
 	}
-	field := fields[index]
-	fieldPath := jen.Id(cleaner.Replace(fields[0]))
-	for i := 1; i <= index; i++ {
-		fieldPath = fieldPath.Dot(cleaner.Replace(fields[i]))
+	return jen.Func().Params(jen.Id("o").Qual(opts.ResourcePackagePath, "Managed")).String().Block(
+		jen.Return(ref.Extractor.Clone().Call(jen.Id("o"), path.Clone().Dot(ref.ExtractorArg))),
+	)
+}
+
+// compositeExtractor returns an extractor that extracts the components of the
+// supplied composite key from the referenced resource, keeps those that are
+// written to other fields in their variables, and returns the component that
+// is the resolved value.
+func compositeExtractor(c *Composite, mo managedOptions, resourcePkgPath string) *jen.Statement {
+	body := []jen.Code{jen.Id("k").Op(":=").Add(c.Extractor.Clone()).Call(jen.Id("o"))}
+	for _, t := range c.Into {
+		body = append(body, mo.Locals.Id(componentVar(t)).Op("=").Id("k").Dot(t.Component))
 	}
-	switch {
-	case strings.HasPrefix(field, "*"):
-		fields[index] = cleaner.Replace(fields[index])
-		return jen.If(fieldPath.Op("!=").Nil()).Block(encapsulate(index+1, callFn, fields...))
-	case strings.HasPrefix(field, "[]"):
-		fields[index] = cleaner.Replace(fields[index]) + fmt.Sprintf("[i%d]", index)
-		return jen.For(
-			jen.Id(fmt.Sprintf("i%d", index)).Op(":=").Lit(0),
-			jen.Id(fmt.Sprintf("i%d", index)).Op("<").Len(fieldPath),
-			jen.Id(fmt.Sprintf("i%d", index)).Op("++"),
-		).Block(encapsulate(index+1, callFn, fields...))
-	default:
-		return encapsulate(index+1, callFn, fields...)
+	body = append(body, mo.Locals.Id("extracted").Op("=").True(), jen.Return(jen.Id("k").Dot(c.Value)))
+	return jen.Func().Params(jen.Id("o").Qual(resourcePkgPath, "Managed")).String().Block(body...)
+}
+
+// distributeComponents returns the declarations of the variables that the
+// components of the supplied composite key are kept in while it is resolved,
+// and the statements that write them to their fields of the struct at the
+// supplied prefix path once it was resolved. They are not written if the key
+// was not extracted, because the current value field was not resolved from a
+// reference or a selector.
+func distributeComponents(c *Composite, referencePkgPath string, mo managedOptions, prefixPath *jen.Statement, fields []string) (declarations, statements *jen.Statement) {
+	declarations = &jen.Statement{jen.Var().Add(mo.Locals.Id("extracted")).Bool()}
+	var set []jen.Code
+	for _, t := range c.Into {
+		declarations.Add(jen.Line().Var().Id(componentVar(t)).String())
+		value := mo.Locals.Id(componentVar(t))
+		if t.IsPointer {
+			value = jen.Qual(referencePkgPath, "ToPtrValue").Call(value)
+		}
+		key := append(append([]string{}, fields[:len(fields)-1]...), t.FieldName)
+		set = append(set,
+			prefixPath.Clone().Dot(t.FieldName).Op("=").Add(value),
+			recordResolved(mo, resolvedKey(key...), mo.Locals.Id(componentVar(t))),
+		)
 	}
+	declarations.Line()
+	return declarations, jen.If(mo.Locals.Id("extracted")).Block(set...)
 }
 
-func singleResolutionCall(ref Reference, referencePkgPath string) resolutionCallFn {
+// componentVar returns the name of the variable that the supplied component of
+// a composite key is kept in while it is resolved.
+func componentVar(t CompositeTarget) string {
+	return "composite" + t.FieldName
+}
+
+// keyedResolutionCall returns a resolution call for a field of an element of a
+// slice of structs whose reference is kept in a slice of keyed references next
+// to that slice. The reference of the element is found by the value of its key
+// field rather than by its index, so that reordering either slice does not
+// resolve the element using the reference of another.
+func keyedResolutionCall(ref Reference, referencePkgPath string, mo managedOptions, opts *resolveReferencesOptions) resolutionCallFn {
 	return func(fields ...string) *jen.Statement {
-		prefixPath := jen.Id(fields[0])
-		for i := 1; i < len(fields)-1; i++ {
-			prefixPath = prefixPath.Dot(fields[i])
-		}
+		prefixPath := goExpr(fields[:len(fields)-1]...)
+		parentPath := goExpr(fields[:len(fields)-2]...)
 		currentValuePath := prefixPath.Clone().Dot(fields[len(fields)-1])
-		referenceFieldPath := prefixPath.Clone().Dot(ref.GoRefFieldName)
+		keyPath := prefixPath.Clone().Dot(ref.SliceKey.FieldName)
+		refsPath := parentPath.Clone().Dot(ref.GoRefFieldName)
 		selectorFieldPath := prefixPath.Clone().Dot(ref.GoSelectorFieldName)
 
-		setResolvedValue := currentValuePath.Clone().Op("=").Id("rsp").Dot("ResolvedValue")
+		setResolvedValue := currentValuePath.Clone().Op("=").Add(mo.Locals.Id("rsp")).Dot("ResolvedValue")
 		if ref.IsPointer {
-			setResolvedValue = currentValuePath.Clone().Op("=").Qual(referencePkgPath, "ToPtrValue").Call(jen.Id("rsp").Dot("ResolvedValue"))
+			setResolvedValue = currentValuePath.Clone().Op("=").Qual(referencePkgPath, "ToPtrValue").Call(mo.Locals.Id("rsp").Dot("ResolvedValue"))
 			currentValuePath = jen.Qual(referencePkgPath, "FromPtrValue").Call(currentValuePath)
 		}
-		return &jen.Statement{
-			jen.List(jen.Id("rsp"), jen.Err()).Op("=").Id("r").Dot("Resolve").Call(
+		if ref.Format != nil {
+			currentValuePath = parseFormatted(ref.Format, currentValuePath)
+			setResolvedValue = formatResolved(ref.Format, mo).Line().Add(setResolvedValue)
+		}
+		currentValuePath, setResolvedValue = normalize(ref, mo, currentValuePath, setResolvedValue)
+		// The call is always made in the loop over the slice, so its
+		// variables are scoped to the element.
+		isSet := jen.Id("ref").Op("!=").Nil().Op("||").Add(selectorFieldPath.Clone()).Op("!=").Nil()
+		return (&jen.Statement{
+			jen.Var().Id("ref").Add(ref.SliceKey.ReferenceType.Clone()),
+			jen.Line(),
+			jen.For(jen.List(jen.Id("_"), jen.Id("kr")).Op(":=").Range().Add(refsPath.Clone())).Block(
+				jen.If(jen.Id("kr").Dot("Name").Op("==").Add(keyPath.Clone())).Block(
+					jen.Id("ref").Op("=").Id("kr").Dot("Reference"),
+				),
+			),
+			jen.Line(),
+		}).Add(skipEmpty(mo, isSet, &jen.Statement{
+			recordDeprecation(ref, opts, isSet.Clone()),
+			rejectSelector(ref, mo, selectorFieldPath),
+			withTimeout(ref, mo, withRetry(mo, jen.List(mo.Locals.Id("rsp"), mo.Locals.Err()).Op("=").Add(mo.Locals.Id("r")).Dot("Resolve").Call(
 				jen.Id("ctx"),
-				jen.Qual(referencePkgPath, "ResolutionRequest").Values(jen.Dict{
+				jen.Qual(referencePkgPath, "ResolutionRequest").Values(withScope(jen.Dict{
 					jen.Id("CurrentValue"): currentValuePath,
-					jen.Id("Reference"):    referenceFieldPath,
-					jen.Id("Selector"):     selectorFieldPath,
+					jen.Id("Reference"):    jen.Id("ref"),
 					jen.Id("To"): jen.Qual(referencePkgPath, "To").Values(jen.Dict{
 						jen.Id("Managed"): ref.RemoteType,
 						jen.Id("List"):    ref.RemoteListType,
 					}),
-					jen.Id("Extract"): ref.Extractor,
-				},
+					jen.Id("Extract"): extractor(ref, opts, prefixPath),
+				}, ref, mo, fields[0], selectorFieldPath.Clone()),
 				),
+			))),
+			jen.Line(),
+			logResolution(ref, mo, opts, jen.Id("ref").Op("!=").Nil(), selectorFieldPath.Clone().Op("!=").Nil()),
+			jen.If(mo.Locals.Err().Op("!=").Nil()).Block(
+				returnWrapped(mo, ref, GoPath(ref.GoValueFieldPath...)),
 			),
 			jen.Line(),
-			jen.If(jen.Err().Op("!=").Nil()).Block(
-				jen.Return(jen.Qual("github.com/pkg/errors", "Wrap").Call(jen.Err(), jen.Lit(strings.Join(ref.GoValueFieldPath, ".")))),
+			validate(ref, mo),
+			validateProviderConfig(ref, mo, opts, fields[0], false),
+			lines(
+				setResolvedValue,
+				recordResolved(mo, resolvedKey(fields...), mo.Locals.Id("rsp").Dot("ResolvedValue")),
+				clearSelector(mo, jen.Id("ref").Op("!=").Nil(), prefixPath, nil, ref.GoSelectorFieldName),
 			),
 			jen.Line(),
-			setResolvedValue,
 			jen.Line(),
-			referenceFieldPath.Clone().Op("=").Id("rsp").Dot("ResolvedReference"),
+			jen.Id("found").Op(":=").False(),
 			jen.Line(),
-		}
+			jen.For(jen.Id("j").Op(":=").Range().Add(refsPath.Clone())).Block(
+				jen.If(refsPath.Clone().Index(jen.Id("j")).Dot("Name").Op("==").Add(keyPath.Clone())).Block(
+					refsPath.Clone().Index(jen.Id("j")).Dot("Reference").Op("=").Add(mo.Locals.Id("rsp")).Dot("ResolvedReference"),
+					jen.Id("found").Op("=").True(),
+				),
+			),
+			jen.Line(),
+			lines(
+				jen.If(jen.Op("!").Id("found").Op("&&").Add(mo.Locals.Id("rsp")).Dot("ResolvedReference").Op("!=").Nil()).Block(
+					refsPath.Clone().Op("=").Append(refsPath.Clone(), ref.SliceKey.RefsElementType.Clone().Values(jen.Dict{
+						jen.Id("Name"):      keyPath.Clone(),
+						jen.Id("Reference"): mo.Locals.Id("rsp").Dot("ResolvedReference"),
+					})),
+				),
+				recordProvenance(ref, mo, opts, fields, mo.Locals.Id("rsp").Dot("ResolvedReference"), true),
+				recordDependencies(ref, mo, mo.Locals.Id("rsp").Dot("ResolvedReference"), true),
+			),
+		})...)
 	}
 }
 
-func multiResolutionCall(ref Reference, referencePkgPath string) resolutionCallFn {
+func multiResolutionCall(ref Reference, referencePkgPath string, mo managedOptions, opts *resolveReferencesOptions) resolutionCallFn {
 	return func(fields ...string) *jen.Statement {
-		prefixPath := jen.Id(fields[0])
-		for i := 1; i < len(fields)-1; i++ {
-			prefixPath = prefixPath.Dot(fields[i])
-		}
+		prefixPath := goExpr(fields[:len(fields)-1]...)
 		currentValuePath := prefixPath.Clone().Dot(fields[len(fields)-1])
-		referenceFieldPath := prefixPath.Clone().Dot(ref.GoRefFieldName)
-		selectorFieldPath := prefixPath.Clone().Dot(ref.GoSelectorFieldName)
+		referenceFieldPath, readRefs := readReferences("refs", ref, prefixPath)
+		selectorFieldPath, readSelector := readThrough("selector", ref.GoSelectorFieldType, prefixPath, ref.GoSelectorFieldParents, ref.GoSelectorFieldName)
 
-		setResolvedValues := currentValuePath.Clone().Op("=").Id("mrsp").Dot("ResolvedValues")
+		setResolvedValues := currentValuePath.Clone().Op("=").Add(mo.Locals.Id("mrsp")).Dot("ResolvedValues")
 		if ref.IsPointer {
-			setResolvedValues = currentValuePath.Clone().Op("=").Qual(referencePkgPath, "ToPtrValues").Call(jen.Id("mrsp").Dot("ResolvedValues"))
+			setResolvedValues = currentValuePath.Clone().Op("=").Qual(referencePkgPath, "ToPtrValues").Call(mo.Locals.Id("mrsp").Dot("ResolvedValues"))
 			currentValuePath = jen.Qual(referencePkgPath, "FromPtrValues").Call(currentValuePath)
 		}
 
-		return &jen.Statement{
-			jen.List(jen.Id("mrsp"), jen.Err()).Op("=").Id("r").Dot("ResolveMultiple").Call(
+		isSet := eitherSet(ref, referenceFieldPath, selectorFieldPath)
+		return scoped(jen.Statement{readRefs, readSelector}, jen.Statement(skipEmpty(mo, isSet, &jen.Statement{
+			recordDeprecation(ref, opts, isSet.Clone()),
+			rejectSelector(ref, mo, selectorFieldPath),
+			withTimeout(ref, mo, withRetry(mo, jen.List(mo.Locals.Id("mrsp"), mo.Locals.Err()).Op("=").Add(mo.Locals.Id("r")).Dot("ResolveMultiple").Call(
 				jen.Id("ctx"),
-				jen.Qual(referencePkgPath, "MultiResolutionRequest").Values(jen.Dict{
+				jen.Qual(referencePkgPath, "MultiResolutionRequest").Values(withScope(jen.Dict{
 					jen.Id("CurrentValues"): currentValuePath,
 					jen.Id("References"):    referenceFieldPath,
-					jen.Id("Selector"):      selectorFieldPath,
 					jen.Id("To"): jen.Qual(referencePkgPath, "To").Values(jen.Dict{
 						jen.Id("Managed"): ref.RemoteType,
 						jen.Id("List"):    ref.RemoteListType,
 					}),
-					jen.Id("Extract"): ref.Extractor,
-				},
+					jen.Id("Extract"): extractor(ref, opts, prefixPath),
+				}, ref, mo, fields[0], selectorFieldPath.Clone()),
 				),
+			))),
+			jen.Line(),
+			logResolution(ref, mo, opts, referencesSet(ref, referenceFieldPath), selectorFieldPath.Clone().Op("!=").Nil()),
+			jen.If(mo.Locals.Err().Op("!=").Nil()).Block(
+				returnWrapped(mo, ref, GoPath(ref.GoValueFieldPath...)),
 			),
 			jen.Line(),
-			jen.If(jen.Err().Op("!=").Nil()).Block(
-				jen.Return(jen.Qual("github.com/pkg/errors", "Wrap").Call(jen.Err(), jen.Lit(strings.Join(ref.GoValueFieldPath, ".")))),
+			validateProviderConfig(ref, mo, opts, fields[0], true),
+			lines(
+				setResolvedValues,
+				recordResolvedValues(mo, fields...),
+				clearSelector(mo, referencesSet(ref, referenceFieldPath), prefixPath, ref.GoSelectorFieldParents, ref.GoSelectorFieldName),
+				writeReferences(ref, prefixPath, mo.Locals.Id("mrsp").Dot("ResolvedReferences")),
+				recordProvenance(ref, mo, opts, fields, mo.Locals.Id("mrsp").Dot("ResolvedReferences"), false),
+				recordDependencies(ref, mo, mo.Locals.Id("mrsp").Dot("ResolvedReferences"), false),
+			),
+		})))
+	}
+}
+
+// spreadResolutionCall resolves the values of a field of each element of a
+// slice of structs, growing the slice if more values are resolved than it has
+// elements.
+func spreadResolutionCall(ref Reference, referencePkgPath string, mo managedOptions, opts *resolveReferencesOptions) resolutionCallFn {
+	return func(fields ...string) *jen.Statement {
+		prefixPath := goExpr(fields[:len(fields)-1]...)
+		slicePath := prefixPath.Clone().Dot(fields[len(fields)-1])
+		referenceFieldPath, readRefs := readReferences("refs", ref, prefixPath)
+		selectorFieldPath := prefixPath.Clone().Dot(ref.GoSelectorFieldName)
+		writeResolvedRefs := prefixPath.Clone().Dot(ref.GoRefFieldName).Op("=").Add(mo.Locals.Id("mrsp")).Dot("ResolvedReferences")
+		if ref.IsRefPointers || ref.NoRefWriteBack {
+			writeResolvedRefs = writeReferences(ref, prefixPath, mo.Locals.Id("mrsp").Dot("ResolvedReferences"))
+		}
+		elementFieldPath := slicePath.Clone().Index(jen.Id("i")).Dot(ref.Spread.ElementFieldName)
+
+		currentValue := elementFieldPath.Clone()
+		resolvedValue := jen.Id("v")
+		if ref.IsPointer {
+			currentValue = jen.Qual(referencePkgPath, "FromPtrValue").Call(currentValue)
+			resolvedValue = jen.Qual(referencePkgPath, "ToPtrValue").Call(resolvedValue)
+		}
+
+		isSet := jen.Len(referenceFieldPath.Clone()).Op(">").Lit(0).Op("||").Add(selectorFieldPath.Clone()).Op("!=").Nil()
+		return jen.Block(append([]jen.Code{readRefs}, skipEmpty(mo, isSet,
+			recordDeprecation(ref, opts, isSet.Clone()),
+			rejectSelector(ref, mo, selectorFieldPath),
+			jen.Id("values").Op(":=").Make(jen.Index().String(), jen.Len(slicePath.Clone())),
+			jen.For(jen.Id("i").Op(":=").Range().Add(slicePath.Clone())).Block(
+				jen.Id("values").Index(jen.Id("i")).Op("=").Add(currentValue),
+			),
+			withTimeout(ref, mo, withRetry(mo, jen.List(mo.Locals.Id("mrsp"), mo.Locals.Err()).Op("=").Add(mo.Locals.Id("r")).Dot("ResolveMultiple").Call(
+				jen.Id("ctx"),
+				jen.Qual(referencePkgPath, "MultiResolutionRequest").Values(withScope(jen.Dict{
+					jen.Id("CurrentValues"): jen.Id("values"),
+					jen.Id("References"):    referenceFieldPath,
+					jen.Id("To"): jen.Qual(referencePkgPath, "To").Values(jen.Dict{
+						jen.Id("Managed"): ref.RemoteType,
+						jen.Id("List"):    ref.RemoteListType,
+					}),
+					jen.Id("Extract"): extractor(ref, opts, prefixPath),
+				}, ref, mo, fields[0], selectorFieldPath.Clone()),
+				),
+			))),
+			logResolution(ref, mo, opts, jen.Len(referenceFieldPath.Clone()).Op(">").Lit(0), selectorFieldPath.Clone().Op("!=").Nil()),
+			jen.If(mo.Locals.Err().Op("!=").Nil()).Block(
+				returnWrapped(mo, ref, GoPath(ref.GoValueFieldPath...)),
 			),
+			validateProviderConfig(ref, mo, opts, fields[0], true),
+			jen.If(jen.Id("n").Op(":=").Len(mo.Locals.Id("mrsp").Dot("ResolvedValues")).Op("-").Len(slicePath.Clone()), jen.Id("n").Op(">").Lit(0)).Block(
+				slicePath.Clone().Op("=").Append(slicePath.Clone(), jen.Make(jen.Index().Add(ref.Spread.ElementType), jen.Id("n")).Op("...")),
+			),
+			jen.For(jen.List(jen.Id("i"), jen.Id("v")).Op(":=").Range().Add(mo.Locals.Id("mrsp")).Dot("ResolvedValues")).Block(
+				elementFieldPath.Clone().Op("=").Add(resolvedValue),
+				recordResolved(mo, resolvedKey(append(append([]string{}, fields[:len(fields)-1]...), fields[len(fields)-1]+"[i]", ref.Spread.ElementFieldName)...), jen.Id("v")),
+			),
+			clearSelector(mo, jen.Len(referenceFieldPath.Clone()).Op(">").Lit(0), prefixPath, nil, ref.GoSelectorFieldName),
+			writeResolvedRefs,
+			recordProvenance(ref, mo, opts, fields, mo.Locals.Id("mrsp").Dot("ResolvedReferences"), false),
+			recordDependencies(ref, mo, mo.Locals.Id("mrsp").Dot("ResolvedReferences"), false),
+		)...)...,
+		)
+	}
+}
+
+// clientSupports returns an error if the supplied reference cannot be resolved
+// using the controller-runtime client directly.
+func clientSupports(ref Reference) error {
+	switch {
+	case ref.Paved != nil:
+		return errors.New("keys of paved maps are not supported")
+	case ref.Spread != nil:
+		return errors.New("spreading values is not supported")
+	case ref.SliceKey != nil:
+		return errors.New("slice keys are not supported")
+	case ref.OneOf != nil:
+		return errors.New("unions are not supported")
+	case ref.Validation != nil:
+		return errors.New("validation is not supported")
+	case ref.Format != nil:
+		return errors.New("value formats are not supported")
+	case ref.Normalizer != nil:
+		return errors.New("normalizers are not supported")
+	case ref.SameProviderConfig:
+		return errors.New("requiring the same provider config is not supported")
+	case ref.Composite != nil:
+		return errors.New("composite keys are not supported")
+	case ref.NoRefWriteBack:
+		return errors.New("disabling the reference write-back is not supported")
+	case ref.IsRefValue:
+		return errors.New("references that are not pointers are not supported")
+	case ref.IsRefPointers:
+		return errors.New("slices of pointers to references are not supported")
+	case len(ref.GoRefFieldParents)+len(ref.GoSelectorFieldParents) > 0:
+		return errors.New("reference and selector field paths are not supported")
+	case ref.FieldSelector != "":
+		return errors.New("field selectors are not supported")
+	case ref.ExtractorArg != "":
+		return errors.New("extractor arguments are not supported")
+	}
+	return nil
+}
+
+// clientResolutionCall returns a resolution call that gets the referenced
+// resources, or selects them by listing those whose labels match the selector,
+// using the controller-runtime client directly. Values are extracted by the
+// extractor of the reference, or from the external name of the referenced
+// resource if it has none.
+func clientResolutionCall(ref Reference, clientPath string, mo managedOptions, opts *resolveReferencesOptions) resolutionCallFn {
+	return func(fields ...string) *jen.Statement {
+		prefixPath := goExpr(fields[:len(fields)-1]...)
+		path := GoPath(ref.GoValueFieldPath...)
+		currentValuePath := prefixPath.Clone().Dot(fields[len(fields)-1])
+		referenceFieldPath := prefixPath.Clone().Dot(ref.GoRefFieldName)
+		selectorFieldPath := prefixPath.Clone().Dot(ref.GoSelectorFieldName)
+
+		ns := namespace(ref, mo, fields[0])
+		listOptions := []jen.Code{jen.Id("ctx"), jen.Id("l"), jen.Qual(clientPath, "MatchingLabels").Call(selectorFieldPath.Clone().Dot("MatchLabels"))}
+		key := []jen.Code{jen.Id("Name").Op(":").Id("ref").Dot("Name")}
+		if ns != nil {
+			listOptions = append(listOptions, jen.Qual(clientPath, "InNamespace").Call(ns))
+			key = append(key, jen.Id("Namespace").Op(":").Add(ns.Clone()))
+		}
+		extract := jen.Qual(opts.MetaPackagePath, "GetExternalName").Call(jen.Id("to"))
+		if ref.Extractor != nil {
+			extract = ref.Extractor.Clone().Call(jen.Id("to"))
+		}
+		value := jen.Id("v")
+		if ref.IsPointer {
+			value = jen.Op("&").Id("v")
+		}
+
+		// The item is skipped if the selector requires the same controller
+		// as the managed resource, but it has another.
+		skip := jen.If(
+			selectorFieldPath.Clone().Dot("MatchControllerRef").Op("!=").Nil().Op("&&").
+				Op("*").Add(selectorFieldPath.Clone()).Dot("MatchControllerRef").Op("&&").
+				Op("!").Qual(opts.MetaPackagePath, "HaveSameController").Call(jen.Id(fields[0]), jen.Op("&").Id("l").Dot("Items").Index(jen.Id("i"))),
+		).Block(jen.Continue())
+		// Requests are made and their errors checked in one statement,
+		// unless they time out, in which case they are made in a block.
+		request := func(call *jen.Statement) *jen.Statement {
+			assign := mo.Locals.Err().Op("=").Add(call)
+			if ref.Timeout <= 0 {
+				return jen.If(assign, mo.Locals.Err().Op("!=").Nil()).Block(returnWrapped(mo, ref, path))
+			}
+			return withTimeout(ref, mo, assign).Line().If(mo.Locals.Err().Op("!=").Nil()).Block(returnWrapped(mo, ref, path))
+		}
+		list := &jen.Statement{
+			jen.Id("l").Op(":=").Add(ref.RemoteListType.Clone()),
+			jen.Line(),
+			request(jen.Id("c").Dot("List").Call(listOptions...)),
+			jen.Line(),
+		}
+		get := &jen.Statement{
+			jen.Id("to").Op(":=").Add(ref.RemoteType.Clone()),
+			jen.Line(),
+			request(jen.Id("c").Dot("Get").Call(jen.Id("ctx"), jen.Qual(clientPath, "ObjectKey").Values(key...), jen.Id("to"))),
 			jen.Line(),
-			setResolvedValues,
+			jen.Id("v").Op(":=").Add(extract),
 			jen.Line(),
-			referenceFieldPath.Clone().Op("=").Id("mrsp").Dot("ResolvedReferences"),
+			jen.If(jen.Id("v").Op("==").Lit("")).Block(
+				returnError(mo, jen.Qual("github.com/pkg/errors", "New").Call(jen.Lit(path+": referenced field was empty (referenced resource may not yet be ready)"))),
+			),
+		}
+		recordDependency := &jen.Statement{}
+		switch {
+		case mo.DependencyAnnotation == "":
+		case ref.IsSlice:
+			recordDependency = jen.Line().For(jen.List(jen.Id("_"), jen.Id("dep")).Op(":=").Range().Id("refs")).Block(dependency(ref, mo, jen.Id("dep")))
+		default:
+			recordDependency = jen.Line().Add(dependency(ref, mo, jen.Id("ref")))
+		}
+		noneMatched := returnError(mo, jen.Qual("github.com/pkg/errors", "New").Call(jen.Lit(path+": no resources matched selector")))
+
+		if ref.IsSlice {
+			valueType := jen.String()
+			if ref.IsPointer {
+				valueType = jen.Op("*").String()
+			}
+			return jen.Block(
+				recordDeprecation(ref, opts, jen.Len(referenceFieldPath.Clone()).Op(">").Lit(0).Op("||").Add(selectorFieldPath.Clone()).Op("!=").Nil()),
+				rejectSelector(ref, mo, selectorFieldPath),
+				jen.Id("refs").Op(":=").Add(referenceFieldPath.Clone()),
+				jen.If(jen.Len(jen.Id("refs")).Op("==").Lit(0).Op("&&").Add(selectorFieldPath.Clone()).Op("!=").Nil()).Block(
+					list,
+					jen.For(jen.Id("i").Op(":=").Range().Id("l").Dot("Items")).Block(
+						skip,
+						jen.Id("refs").Op("=").Append(jen.Id("refs"), ref.GoReferenceType.Clone().Values(jen.Dict{
+							jen.Id("Name"): jen.Id("l").Dot("Items").Index(jen.Id("i")).Dot("GetName").Call(),
+						})),
+					),
+					jen.Line(),
+					jen.If(jen.Len(jen.Id("refs")).Op("==").Lit(0)).Block(noneMatched),
+				),
+				jen.Line(),
+				jen.If(jen.Len(jen.Id("refs")).Op(">").Lit(0)).Block(
+					jen.Id("values").Op(":=").Make(jen.Index().Add(valueType), jen.Lit(0), jen.Len(jen.Id("refs"))),
+					jen.For(jen.List(jen.Id("_"), jen.Id("ref")).Op(":=").Range().Id("refs")).Block(
+						get,
+						jen.Id("values").Op("=").Append(jen.Id("values"), value),
+					),
+					jen.Line(),
+					currentValuePath.Clone().Op("=").Id("values"),
+					clearSelector(mo, jen.Len(referenceFieldPath.Clone()).Op(">").Lit(0), prefixPath, nil, ref.GoSelectorFieldName),
+					referenceFieldPath.Clone().Op("=").Id("refs"),
+					recordDependency,
+				),
+			)
+		}
+
+		return jen.Block(
+			recordDeprecation(ref, opts, referenceFieldPath.Clone().Op("!=").Nil().Op("||").Add(selectorFieldPath.Clone()).Op("!=").Nil()),
+			rejectSelector(ref, mo, selectorFieldPath),
+			jen.Id("ref").Op(":=").Add(referenceFieldPath.Clone()),
+			jen.If(jen.Id("ref").Op("==").Nil().Op("&&").Add(selectorFieldPath.Clone()).Op("!=").Nil()).Block(
+				list,
+				jen.For(jen.Id("i").Op(":=").Range().Id("l").Dot("Items")).Block(
+					skip,
+					jen.Id("ref").Op("=").Op("&").Add(ref.GoReferenceType.Clone()).Values(jen.Dict{
+						jen.Id("Name"): jen.Id("l").Dot("Items").Index(jen.Id("i")).Dot("GetName").Call(),
+					}),
+					jen.Break(),
+				),
+				jen.Line(),
+				jen.If(jen.Id("ref").Op("==").Nil()).Block(noneMatched),
+			),
 			jen.Line(),
+			jen.If(jen.Id("ref").Op("!=").Nil()).Block(
+				get,
+				jen.Line(),
+				currentValuePath.Clone().Op("=").Add(value),
+				clearSelector(mo, referenceFieldPath.Clone().Op("!=").Nil(), prefixPath, nil, ref.GoSelectorFieldName),
+				referenceFieldPath.Clone().Op("=").Id("ref"),
+				recordDependency,
+			),
+		)
+	}
+}
+
+// pavedResolutionCall returns a resolution call for a key of a free-form map
+// field. The map is paved to read the current value, the reference, and the
+// selector of the key, and to write back the resolved value. The resolved
+// reference is written to the map, or to a typed field of references if the
+// key has one.
+func pavedResolutionCall(ref Reference, referencePkgPath string, mo managedOptions, opts *resolveReferencesOptions) resolutionCallFn {
+	return func(fields ...string) *jen.Statement {
+		prefixPath := goExpr(fields[:len(fields)-1]...)
+		mapPath := prefixPath.Clone().Dot(fields[len(fields)-1])
+		path := valuePath(ref, 0)
+		fieldPath := func(name string) *jen.Statement { return jen.Qual(opts.FieldPathPackagePath, name) }
+
+		// A key that is not set is not an error.
+		getInto := func(key, id string) *jen.Statement {
+			return jen.If(
+				mo.Locals.Err().Op("=").Id("p").Dot("GetValueInto").Call(jen.Lit(key), jen.Op("&").Id(id)),
+				mo.Locals.Err().Op("!=").Nil().Op("&&").Op("!").Add(fieldPath("IsNotFound")).Call(mo.Locals.Err()),
+			).Block(returnWrapped(mo, ref, path)).Line()
+		}
+		setValue := func(key string, value *jen.Statement) *jen.Statement {
+			return jen.If(
+				mo.Locals.Err().Op("=").Id("p").Dot("SetValue").Call(jen.Lit(key), value),
+				mo.Locals.Err().Op("!=").Nil(),
+			).Block(returnWrapped(mo, ref, path))
+		}
+
+		readReference := getInto(ref.Paved.RefKey, "ref")
+		writeReference := jen.If(mo.Locals.Id("rsp").Dot("ResolvedReference").Op("!=").Nil()).Block(
+			setValue(ref.Paved.RefKey, mo.Locals.Id("rsp").Dot("ResolvedReference")),
+		).Line()
+		if ref.Paved.RefsFieldName != "" {
+			refsPath := prefixPath.Clone().Dot(ref.Paved.RefsFieldName)
+			readReference = jen.If(jen.List(jen.Id("rr"), jen.Id("ok")).Op(":=").Add(refsPath.Clone()).Index(jen.Lit(ref.Paved.Key)), jen.Id("ok")).Block(
+				jen.Id("ref").Op("=").Op("&").Id("rr"),
+			).Line()
+			writeReference = jen.If(mo.Locals.Id("rsp").Dot("ResolvedReference").Op("!=").Nil()).Block(
+				jen.If(refsPath.Clone().Op("==").Nil()).Block(
+					refsPath.Clone().Op("=").Map(jen.String()).Add(ref.GoReferenceType.Clone()).Values(),
+				),
+				refsPath.Clone().Index(jen.Lit(ref.Paved.Key)).Op("=").Op("*").Add(mo.Locals.Id("rsp")).Dot("ResolvedReference"),
+			).Line()
+		}
+		clearSelector := &jen.Statement{}
+		if mo.ClearSelectors {
+			clearSelector = jen.If(jen.Id("ref").Op("!=").Nil()).Block(
+				jen.If(
+					mo.Locals.Err().Op("=").Id("p").Dot("DeleteField").Call(jen.Lit(ref.Paved.SelectorKey)),
+					mo.Locals.Err().Op("!=").Nil(),
+				).Block(returnWrapped(mo, ref, path)),
+			).Line()
 		}
+
+		isSet := jen.Id("ref").Op("!=").Nil().Op("||").Id("selector").Op("!=").Nil()
+		return jen.Block(append([]jen.Code{
+			jen.Id("p").Op(":=").Add(fieldPath("Pave")).Call(mapPath.Clone()),
+			jen.List(jen.Id("current"), jen.Id("_")).Op(":=").Id("p").Dot("GetString").Call(jen.Lit(ref.Paved.Key)),
+			jen.Var().Id("ref").Add(ref.GoRefFieldType.Clone()),
+			readReference,
+			jen.Var().Id("selector").Add(ref.GoSelectorFieldType.Clone()),
+			getInto(ref.Paved.SelectorKey, "selector"),
+		}, skipEmpty(mo, isSet,
+			recordDeprecation(ref, opts, isSet.Clone()),
+			rejectSelector(ref, mo, jen.Id("selector")),
+			withTimeout(ref, mo, withRetry(mo, jen.List(mo.Locals.Id("rsp"), mo.Locals.Err()).Op("=").Add(mo.Locals.Id("r")).Dot("Resolve").Call(
+				jen.Id("ctx"),
+				jen.Qual(referencePkgPath, "ResolutionRequest").Values(withScope(jen.Dict{
+					jen.Id("CurrentValue"): jen.Id("current"),
+					jen.Id("Reference"):    jen.Id("ref"),
+					jen.Id("To"): jen.Qual(referencePkgPath, "To").Values(jen.Dict{
+						jen.Id("Managed"): ref.RemoteType,
+						jen.Id("List"):    ref.RemoteListType,
+					}),
+					jen.Id("Extract"): ref.Extractor,
+				}, ref, mo, fields[0], jen.Id("selector")),
+				),
+			))),
+			jen.Line(),
+			logResolution(ref, mo, opts, jen.Id("ref").Op("!=").Nil(), jen.Id("selector").Op("!=").Nil()),
+			jen.If(mo.Locals.Err().Op("!=").Nil()).Block(
+				returnWrapped(mo, ref, path),
+			),
+			jen.Line(),
+			validate(ref, mo),
+			validateProviderConfig(ref, mo, opts, fields[0], false),
+			jen.If(mo.Locals.Id("rsp").Dot("ResolvedValue").Op("!=").Id("current")).Block(
+				setValue(ref.Paved.Key, mo.Locals.Id("rsp").Dot("ResolvedValue")),
+			),
+			jen.Line(),
+			recordResolved(mo, resolvedKey(append(append([]string{}, fields...), ref.Paved.Key)...), mo.Locals.Id("rsp").Dot("ResolvedValue")),
+			clearSelector,
+			writeReference,
+			mapPath.Clone().Op("=").Id("p").Dot("UnstructuredContent").Call(),
+			recordProvenance(ref, mo, opts, fields, mo.Locals.Id("rsp").Dot("ResolvedReference"), true),
+			recordDependencies(ref, mo, mo.Locals.Id("rsp").Dot("ResolvedReference"), true),
+		)...)...,
+		)
 	}
 }
@@ -18,7 +18,12 @@ package method
 
 import (
 	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	xptypes "github.com/crossplane/crossplane-tools/internal/types"
 
@@ -27,36 +32,71 @@ import (
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/packages/packagestest"
 
-	"github.com/crossplane/crossplane-tools/internal/comments"
+	"github.com/crossplane/crossplane-tools/pkg/comments"
+	"github.com/crossplane/crossplane-tools/pkg/testing/golden"
 )
 
 const (
 	source = `
 package v1alpha1
 
+type Reference struct {
+	Name string
+}
+
+type Selector struct {
+	MatchLabels map[string]string
+}
+
+type Configuration struct {}
+
 type ModelParameters struct {
 	// +crossplane:generate:reference:type=Apigatewayv2Api
 	APIID string
 
+	APIIDRef *Reference
+
+	APIIDSelector *Selector
+
 	// +crossplane:generate:reference:type=SecurityGroup
 	SecurityGroupID *string
 
+	SecurityGroupIDRef *Reference
+
+	SecurityGroupIDSelector *Selector
+
 	// +crossplane:generate:reference:type=github.com/crossplane/provider-aws/apis/identity/v1beta1.IAM
 	// +crossplane:generate:reference:extractor=github.com/crossplane/provider-aws/apis/identity/v1beta1.IAMRoleARN()
 	IAMRoleARN *string
 
+	IAMRoleARNRef *Reference
+
+	IAMRoleARNSelector *Selector
+
 	// +crossplane:generate:reference:type=github.com/crossplane/provider-aws/apis/identity/v1beta1.IAM
 	// +crossplane:generate:reference:extractor=github.com/crossplane/provider-aws/apis/identity/v1beta1.IAMRoleARN("a.b.c")
 	NestedTargetWithPath *string
 
+	NestedTargetWithPathRef *Reference
+
+	NestedTargetWithPathSelector *Selector
+
 	// +crossplane:generate:reference:type=github.com/crossplane/provider-aws/apis/identity/v1beta1.IAM
 	// +crossplane:generate:reference:extractor=IAMRoleARN("a.b.c")
 	NestedTargetNoPath *string
 
+	NestedTargetNoPathRef *Reference
+
+	NestedTargetNoPathSelector *Selector
+
 	// +crossplane:generate:reference:type=github.com/crossplane/provider-aws/apis/identity/v1beta1.IAM
 	// +crossplane:generate:reference:extractor=IAMRoleARN()
 	NoArgNoPath *string
 
+	NoArgNoPathRef *Reference
+
+	NoArgNoPathSelector *Selector
+
 	Network *NetworkSpec
 
 	OtherSetting []OtherSpec
@@ -66,24 +106,44 @@ type ModelParameters struct {
 	// +crossplane:generate:reference:selectorFieldName=SubnetIDSelector
 	SubnetIDs []string
 
+	SubnetIDRefs []Reference
+
+	SubnetIDSelector *Selector
+
 	// +crossplane:generate:reference:type=RouteTable
 	RouteTableIDs []*string
 
+	RouteTableIDsRefs []Reference
+
+	RouteTableIDsSelector *Selector
+
 	UnrelatedField string
 
 	// +crossplane:generate:reference:type=golang.org/fake/v1alpha1.Configuration
 	// +crossplane:generate:reference:extractor=golang.org/fake/v1alpha1.Configuration()
 	CustomConfiguration *Configuration
+
+	CustomConfigurationRef *Reference
+
+	CustomConfigurationSelector *Selector
 }
 
 type NetworkSpec struct {
 	// +crossplane:generate:reference:type=github.com/crossplane/provider-aws/apis/ec2/v1beta1.VPC
 	VPCID string
+
+	VPCIDRef *Reference
+
+	VPCIDSelector *Selector
 }
 
 type OtherSpec struct {
 	// +crossplane:generate:reference:type=Cluster
 	OtherID string
+
+	OtherIDRef *Reference
+
+	OtherIDSelector *Selector
 }
 
 type ModelSpec struct {
@@ -101,226 +161,8735 @@ type Model struct {
 	Status            ModelStatus
 }
 `
-	generated = `package v1alpha1
-
-import (
-	"context"
-	client "example.org/client"
-	reference "example.org/reference"
-	v1beta11 "github.com/crossplane/provider-aws/apis/ec2/v1beta1"
-	v1beta1 "github.com/crossplane/provider-aws/apis/identity/v1beta1"
-	errors "github.com/pkg/errors"
 )
 
-// ResolveReferences of this Model.
-func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
-	r := reference.NewAPIResolver(c, mg)
+// runtimeModule is a fake crossplane-runtime module that defines the types
+// reference fields are validated against.
+var runtimeModule = packagestest.Module{
+	Name: "github.com/crossplane/crossplane-runtime",
+	Files: map[string]any{
+		"apis/common/v1/resource.go": `
+package v1
 
-	var rsp reference.ResolutionResponse
-	var mrsp reference.MultiResolutionResponse
-	var err error
+type Reference struct {
+	Name string
+}
 
-	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
-		CurrentValue: mg.Spec.ForProvider.APIID,
-		Extract:      reference.ExternalName(),
-		Reference:    mg.Spec.ForProvider.APIIDRef,
-		Selector:     mg.Spec.ForProvider.APIIDSelector,
-		To: reference.To{
-			List:    &Apigatewayv2ApiList{},
-			Managed: &Apigatewayv2Api{},
+type Selector struct {
+	MatchLabels map[string]string
+}
+`,
+	},
+}
+
+func loadPackage(t *testing.T, source string, modules ...packagestest.Module) *packages.Package {
+	t.Helper()
+	exported := packagestest.Export(t, packagestest.Modules, append([]packagestest.Module{{
+		Name: "golang.org/fake",
+		Files: map[string]any{
+			"v1alpha1/model.go": source,
 		},
-	})
+	}}, modules...))
+	t.Cleanup(exported.Cleanup)
+	exported.Config.Mode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedSyntax
+	pkgs, err := packages.Load(exported.Config, fmt.Sprintf("file=%s", exported.File("golang.org/fake", "v1alpha1/model.go")))
 	if err != nil {
-		return errors.Wrap(err, "mg.Spec.ForProvider.APIID")
+		t.Fatal(err)
 	}
-	mg.Spec.ForProvider.APIID = rsp.ResolvedValue
-	mg.Spec.ForProvider.APIIDRef = rsp.ResolvedReference
+	return pkgs[0]
+}
 
-	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
-		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.SecurityGroupID),
-		Extract:      reference.ExternalName(),
-		Reference:    mg.Spec.ForProvider.SecurityGroupIDRef,
-		Selector:     mg.Spec.ForProvider.SecurityGroupIDSelector,
-		To: reference.To{
-			List:    &SecurityGroupList{},
-			Managed: &SecurityGroup{},
+// resolveReferences returns the ResolveReferences method generated for the
+// Model type of the supplied source.
+func resolveReferences(t *testing.T, source string, opts ...ResolveReferencesOption) string {
+	t.Helper()
+	p := loadPackage(t, source)
+	f := jen.NewFilePath("golang.org/fake/v1alpha1")
+	NewResolveReferences(xptypes.NewTraverser(comments.In(p)), "mg", "example.org/client", "example.org/reference", opts...)(f, p.Types.Scope().Lookup("Model"))
+	return fmt.Sprintf("%#v", f)
+}
+
+func TestNewResolveReferences(t *testing.T) {
+	p := loadPackage(t, source)
+	f := jen.NewFilePath("golang.org/fake/v1alpha1")
+	NewResolveReferences(xptypes.NewTraverser(comments.In(p)), "mg", "example.org/client", "example.org/reference")(f, p.Types.Scope().Lookup("Model"))
+	golden.Assert(t, []byte(fmt.Sprintf("%#v", f)), "testdata/resolve_references.golden")
+}
+
+func TestReferenceProcessorFieldTypes(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		source string
+		want   string
+	}{
+		"AliasedImport": {
+			reason: "Reference and selector fields should be compared by type identity, regardless of import alias.",
+			source: `
+package v1alpha1
+
+import commonv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=VPC
+	VPCID *string
+
+	VPCIDRef *commonv1.Reference
+
+	VPCIDSelector *commonv1.Selector
+
+	// +crossplane:generate:reference:type=Subnet
+	SubnetIDs []string
+
+	SubnetIDsRefs []commonv1.Reference
+
+	SubnetIDsSelector *commonv1.Selector
+}
+`,
 		},
-	})
-	if err != nil {
-		return errors.Wrap(err, "mg.Spec.ForProvider.SecurityGroupID")
-	}
-	mg.Spec.ForProvider.SecurityGroupID = reference.ToPtrValue(rsp.ResolvedValue)
-	mg.Spec.ForProvider.SecurityGroupIDRef = rsp.ResolvedReference
+		"IndirectRefTypes": {
+			reason: "A reference field of a single value may hold its reference by value, and that of a slice by pointer.",
+			source: `
+package v1alpha1
 
-	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
-		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.IAMRoleARN),
-		Extract:      v1beta1.IAMRoleARN(),
-		Reference:    mg.Spec.ForProvider.IAMRoleARNRef,
-		Selector:     mg.Spec.ForProvider.IAMRoleARNSelector,
-		To: reference.To{
-			List:    &v1beta1.IAMList{},
-			Managed: &v1beta1.IAM{},
+import commonv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=VPC
+	VPCID *string
+
+	VPCIDRef commonv1.Reference
+
+	VPCIDSelector *commonv1.Selector
+
+	// +crossplane:generate:reference:type=Subnet
+	SubnetIDs []string
+
+	SubnetIDsRefs []*commonv1.Reference
+
+	SubnetIDsSelector *commonv1.Selector
+}
+`,
 		},
-	})
-	if err != nil {
-		return errors.Wrap(err, "mg.Spec.ForProvider.IAMRoleARN")
-	}
-	mg.Spec.ForProvider.IAMRoleARN = reference.ToPtrValue(rsp.ResolvedValue)
-	mg.Spec.ForProvider.IAMRoleARNRef = rsp.ResolvedReference
+		"WrongRefSliceType": {
+			reason: "A reference field of a slice that is not a slice of runtime References should return an error.",
+			source: `
+package v1alpha1
 
-	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
-		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.NestedTargetWithPath),
-		Extract:      v1beta1.IAMRoleARN("a.b.c"),
-		Reference:    mg.Spec.ForProvider.NestedTargetWithPathRef,
-		Selector:     mg.Spec.ForProvider.NestedTargetWithPathSelector,
-		To: reference.To{
-			List:    &v1beta1.IAMList{},
-			Managed: &v1beta1.IAM{},
+import commonv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetIDs []string
+
+	SubnetIDsRefs *commonv1.Reference
+
+	SubnetIDsSelector *commonv1.Selector
+}
+`,
+			want: "field SubnetIDsRefs of ModelParameters must be of type []github.com/crossplane/crossplane-runtime/apis/common/v1.Reference, not *github.com/crossplane/crossplane-runtime/apis/common/v1.Reference",
 		},
-	})
-	if err != nil {
-		return errors.Wrap(err, "mg.Spec.ForProvider.NestedTargetWithPath")
-	}
-	mg.Spec.ForProvider.NestedTargetWithPath = reference.ToPtrValue(rsp.ResolvedValue)
-	mg.Spec.ForProvider.NestedTargetWithPathRef = rsp.ResolvedReference
+		"WrongRefType": {
+			reason: "A reference field that is not a runtime Reference should return an error.",
+			source: `
+package v1alpha1
 
-	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
-		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.NestedTargetNoPath),
-		Extract:      IAMRoleARN("a.b.c"),
-		Reference:    mg.Spec.ForProvider.NestedTargetNoPathRef,
-		Selector:     mg.Spec.ForProvider.NestedTargetNoPathSelector,
-		To: reference.To{
-			List:    &v1beta1.IAMList{},
-			Managed: &v1beta1.IAM{},
+import commonv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=VPC
+	VPCID *string
+
+	VPCIDRef *commonv1.Selector
+
+	VPCIDSelector *commonv1.Selector
+}
+`,
+			want: "field VPCIDRef of ModelParameters must be of type *github.com/crossplane/crossplane-runtime/apis/common/v1.Reference, not *github.com/crossplane/crossplane-runtime/apis/common/v1.Selector",
 		},
-	})
-	if err != nil {
-		return errors.Wrap(err, "mg.Spec.ForProvider.NestedTargetNoPath")
-	}
-	mg.Spec.ForProvider.NestedTargetNoPath = reference.ToPtrValue(rsp.ResolvedValue)
-	mg.Spec.ForProvider.NestedTargetNoPathRef = rsp.ResolvedReference
+		"LookalikeSelectorType": {
+			reason: "A selector field whose type is only named like a runtime Selector should return an error.",
+			source: `
+package v1alpha1
 
-	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
-		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.NoArgNoPath),
-		Extract:      IAMRoleARN(),
-		Reference:    mg.Spec.ForProvider.NoArgNoPathRef,
-		Selector:     mg.Spec.ForProvider.NoArgNoPathSelector,
-		To: reference.To{
-			List:    &v1beta1.IAMList{},
-			Managed: &v1beta1.IAM{},
+import commonv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetIDs []string
+
+	SubnetIDsRefs []commonv1.Reference
+
+	SubnetIDsSelector *Selector
+}
+`,
+			want: "field SubnetIDsSelector of ModelParameters must be of type *github.com/crossplane/crossplane-runtime/apis/common/v1.Selector, not *golang.org/fake/v1alpha1.Selector",
 		},
-	})
-	if err != nil {
-		return errors.Wrap(err, "mg.Spec.ForProvider.NoArgNoPath")
 	}
-	mg.Spec.ForProvider.NoArgNoPath = reference.ToPtrValue(rsp.ResolvedValue)
-	mg.Spec.ForProvider.NoArgNoPathRef = rsp.ResolvedReference
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := loadPackage(t, tc.source, runtimeModule)
+			n := p.Types.Scope().Lookup("ModelParameters").Type().(*types.Named)
+			rp := NewReferenceProcessor("mg", WithRuntimePackagePath("github.com/crossplane/crossplane-runtime/apis/common/v1"))
+			err := xptypes.NewTraverser(comments.In(p)).Traverse(n, &xptypes.ProcessorConfig{Field: rp.For(n), Named: xptypes.NamedProcessorChain{}})
+			if tc.want == "" {
+				if err != nil {
+					t.Errorf("\n%s\nTraverse(...): unexpected error: %v", tc.reason, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("\n%s\nTraverse(...): want error containing %q, got %v", tc.reason, tc.want, err)
+			}
+		})
+	}
+}
 
-	if mg.Spec.ForProvider.Network != nil {
+func TestNewResolveReferencesRefFieldTypes(t *testing.T) {
+	// References of elements of a slice of rule groups are held by pointer
+	// or by value, of current values that are pointers or values. Those that
+	// are not held as the reference package takes them should be converted
+	// when they are read and written back.
+	source := `
+package v1alpha1
+
+type Reference struct {
+	Name string
+}
+
+type Selector struct {}
+
+type RuleGroup struct {
+	// +crossplane:generate:reference:type=Target
+	TargetARN *string
+
+	TargetARNRef *Reference
+
+	TargetARNSelector *Selector
+
+	// +crossplane:generate:reference:type=Target
+	TargetKey string
+
+	TargetKeyRef *Reference
+
+	TargetKeySelector *Selector
+
+	// +crossplane:generate:reference:type=Target
+	TargetID *string
+
+	TargetIDRef Reference
+
+	TargetIDSelector *Selector
+
+	// +crossplane:generate:reference:type=Target
+	TargetName string
+
+	TargetNameRef Reference
+
+	TargetNameSelector *Selector
+
+	// +crossplane:generate:reference:type=Target
+	TargetIDs []*string
+
+	TargetIDsRefs []*Reference
+
+	TargetIDsSelector *Selector
+
+	// +crossplane:generate:reference:type=Target
+	TargetNames []string
+
+	TargetNamesRefs []*Reference
+
+	TargetNamesSelector *Selector
+}
+
+type ModelParameters struct {
+	RuleGroups []RuleGroup
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	for i3 := range mg.Spec.ForProvider.RuleGroups {
 		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
-			CurrentValue: mg.Spec.ForProvider.Network.VPCID,
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.RuleGroups[i3].TargetARN),
 			Extract:      reference.ExternalName(),
-			Reference:    mg.Spec.ForProvider.Network.VPCIDRef,
-			Selector:     mg.Spec.ForProvider.Network.VPCIDSelector,
+			Reference:    mg.Spec.ForProvider.RuleGroups[i3].TargetARNRef,
+			Selector:     mg.Spec.ForProvider.RuleGroups[i3].TargetARNSelector,
 			To: reference.To{
-				List:    &v1beta11.VPCList{},
-				Managed: &v1beta11.VPC{},
+				List:    &TargetList{},
+				Managed: &Target{},
 			},
 		})
 		if err != nil {
-			return errors.Wrap(err, "mg.Spec.ForProvider.Network.VPCID")
+			return errors.Wrap(err, "mg.Spec.ForProvider.RuleGroups[*].TargetARN")
 		}
-		mg.Spec.ForProvider.Network.VPCID = rsp.ResolvedValue
-		mg.Spec.ForProvider.Network.VPCIDRef = rsp.ResolvedReference
+		mg.Spec.ForProvider.RuleGroups[i3].TargetARN = reference.ToPtrValue(rsp.ResolvedValue)
+		mg.Spec.ForProvider.RuleGroups[i3].TargetARNRef = rsp.ResolvedReference
 
 	}
-	for i3 := 0; i3 < len(mg.Spec.ForProvider.OtherSetting); i3++ {
+	for i3 := range mg.Spec.ForProvider.RuleGroups {
 		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
-			CurrentValue: mg.Spec.ForProvider.OtherSetting[i3].OtherID,
+			CurrentValue: mg.Spec.ForProvider.RuleGroups[i3].TargetKey,
 			Extract:      reference.ExternalName(),
-			Reference:    mg.Spec.ForProvider.OtherSetting[i3].OtherIDRef,
-			Selector:     mg.Spec.ForProvider.OtherSetting[i3].OtherIDSelector,
+			Reference:    mg.Spec.ForProvider.RuleGroups[i3].TargetKeyRef,
+			Selector:     mg.Spec.ForProvider.RuleGroups[i3].TargetKeySelector,
 			To: reference.To{
-				List:    &ClusterList{},
-				Managed: &Cluster{},
+				List:    &TargetList{},
+				Managed: &Target{},
 			},
 		})
 		if err != nil {
-			return errors.Wrap(err, "mg.Spec.ForProvider.OtherSetting[i3].OtherID")
+			return errors.Wrap(err, "mg.Spec.ForProvider.RuleGroups[*].TargetKey")
 		}
-		mg.Spec.ForProvider.OtherSetting[i3].OtherID = rsp.ResolvedValue
-		mg.Spec.ForProvider.OtherSetting[i3].OtherIDRef = rsp.ResolvedReference
+		mg.Spec.ForProvider.RuleGroups[i3].TargetKey = rsp.ResolvedValue
+		mg.Spec.ForProvider.RuleGroups[i3].TargetKeyRef = rsp.ResolvedReference
 
 	}
-	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
-		CurrentValues: mg.Spec.ForProvider.SubnetIDs,
-		Extract:       reference.ExternalName(),
-		References:    mg.Spec.ForProvider.SubnetIDRefs,
-		Selector:      mg.Spec.ForProvider.SubnetIDSelector,
-		To: reference.To{
-			List:    &SubnetList{},
-			Managed: &Subnet{},
-		},
-	})
-	if err != nil {
-		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+	for i3 := range mg.Spec.ForProvider.RuleGroups {
+		{
+			var ref *Reference
+			if mg.Spec.ForProvider.RuleGroups[i3].TargetIDRef.Name != "" {
+				ref = &mg.Spec.ForProvider.RuleGroups[i3].TargetIDRef
+			}
+			rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+				CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.RuleGroups[i3].TargetID),
+				Extract:      reference.ExternalName(),
+				Reference:    ref,
+				Selector:     mg.Spec.ForProvider.RuleGroups[i3].TargetIDSelector,
+				To: reference.To{
+					List:    &TargetList{},
+					Managed: &Target{},
+				},
+			})
+			if err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.RuleGroups[*].TargetID")
+			}
+			mg.Spec.ForProvider.RuleGroups[i3].TargetID = reference.ToPtrValue(rsp.ResolvedValue)
+			if rsp.ResolvedReference != nil {
+				mg.Spec.ForProvider.RuleGroups[i3].TargetIDRef = *rsp.ResolvedReference
+			}
+		}
+
 	}
-	mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
-	mg.Spec.ForProvider.SubnetIDRefs = mrsp.ResolvedReferences
+	for i3 := range mg.Spec.ForProvider.RuleGroups {
+		{
+			var ref *Reference
+			if mg.Spec.ForProvider.RuleGroups[i3].TargetNameRef.Name != "" {
+				ref = &mg.Spec.ForProvider.RuleGroups[i3].TargetNameRef
+			}
+			rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+				CurrentValue: mg.Spec.ForProvider.RuleGroups[i3].TargetName,
+				Extract:      reference.ExternalName(),
+				Reference:    ref,
+				Selector:     mg.Spec.ForProvider.RuleGroups[i3].TargetNameSelector,
+				To: reference.To{
+					List:    &TargetList{},
+					Managed: &Target{},
+				},
+			})
+			if err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.RuleGroups[*].TargetName")
+			}
+			mg.Spec.ForProvider.RuleGroups[i3].TargetName = rsp.ResolvedValue
+			if rsp.ResolvedReference != nil {
+				mg.Spec.ForProvider.RuleGroups[i3].TargetNameRef = *rsp.ResolvedReference
+			}
+		}
 
-	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
-		CurrentValues: reference.FromPtrValues(mg.Spec.ForProvider.RouteTableIDs),
-		Extract:       reference.ExternalName(),
-		References:    mg.Spec.ForProvider.RouteTableIDsRefs,
-		Selector:      mg.Spec.ForProvider.RouteTableIDsSelector,
-		To: reference.To{
-			List:    &RouteTableList{},
-			Managed: &RouteTable{},
-		},
-	})
-	if err != nil {
-		return errors.Wrap(err, "mg.Spec.ForProvider.RouteTableIDs")
 	}
-	mg.Spec.ForProvider.RouteTableIDs = reference.ToPtrValues(mrsp.ResolvedValues)
-	mg.Spec.ForProvider.RouteTableIDsRefs = mrsp.ResolvedReferences
+	for i3 := range mg.Spec.ForProvider.RuleGroups {
+		{
+			var refs []Reference
+			for _, rr := range mg.Spec.ForProvider.RuleGroups[i3].TargetIDsRefs {
+				if rr != nil {
+					refs = append(refs, *rr)
+				}
+			}
+			mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+				CurrentValues: reference.FromPtrValues(mg.Spec.ForProvider.RuleGroups[i3].TargetIDs),
+				Extract:       reference.ExternalName(),
+				References:    refs,
+				Selector:      mg.Spec.ForProvider.RuleGroups[i3].TargetIDsSelector,
+				To: reference.To{
+					List:    &TargetList{},
+					Managed: &Target{},
+				},
+			})
+			if err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.RuleGroups[*].TargetIDs")
+			}
+			mg.Spec.ForProvider.RuleGroups[i3].TargetIDs = reference.ToPtrValues(mrsp.ResolvedValues)
+			if len(mrsp.ResolvedReferences) > 0 {
+				resolvedRefs := make([]*Reference, len(mrsp.ResolvedReferences))
+				for i := range mrsp.ResolvedReferences {
+					resolvedRefs[i] = &mrsp.ResolvedReferences[i]
+				}
+				mg.Spec.ForProvider.RuleGroups[i3].TargetIDsRefs = resolvedRefs
+			}
+		}
+
+	}
+	for i3 := range mg.Spec.ForProvider.RuleGroups {
+		{
+			var refs []Reference
+			for _, rr := range mg.Spec.ForProvider.RuleGroups[i3].TargetNamesRefs {
+				if rr != nil {
+					refs = append(refs, *rr)
+				}
+			}
+			mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+				CurrentValues: mg.Spec.ForProvider.RuleGroups[i3].TargetNames,
+				Extract:       reference.ExternalName(),
+				References:    refs,
+				Selector:      mg.Spec.ForProvider.RuleGroups[i3].TargetNamesSelector,
+				To: reference.To{
+					List:    &TargetList{},
+					Managed: &Target{},
+				},
+			})
+			if err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.RuleGroups[*].TargetNames")
+			}
+			mg.Spec.ForProvider.RuleGroups[i3].TargetNames = mrsp.ResolvedValues
+			if len(mrsp.ResolvedReferences) > 0 {
+				resolvedRefs := make([]*Reference, len(mrsp.ResolvedReferences))
+				for i := range mrsp.ResolvedReferences {
+					resolvedRefs[i] = &mrsp.ResolvedReferences[i]
+				}
+				mg.Spec.ForProvider.RuleGroups[i3].TargetNamesRefs = resolvedRefs
+			}
+		}
 
-	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
-		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.CustomConfiguration),
-		Extract:      Configuration(),
-		Reference:    mg.Spec.ForProvider.CustomConfigurationRef,
-		Selector:     mg.Spec.ForProvider.CustomConfigurationSelector,
-		To: reference.To{
-			List:    &ConfigurationList{},
-			Managed: &Configuration{},
-		},
-	})
-	if err != nil {
-		return errors.Wrap(err, "mg.Spec.ForProvider.CustomConfiguration")
 	}
-	mg.Spec.ForProvider.CustomConfiguration = reference.ToPtrValue(rsp.ResolvedValue)
-	mg.Spec.ForProvider.CustomConfigurationRef = rsp.ResolvedReference
 
 	return nil
 }
 `
-)
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
 
-func TestNewResolveReferences(t *testing.T) {
-	exported := packagestest.Export(t, packagestest.Modules, []packagestest.Module{{
-		Name: "golang.org/fake",
-		Files: map[string]any{
-			"v1alpha1/model.go": source,
+func TestReferenceProcessorSkippedTypes(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		marker string
+		want   []string
+	}{
+		"Skipped": {
+			reason: "References declared by a skipped embedded type should not be found.",
+			want:   []string{"Spec.ForProvider.VPCID"},
+		},
+		"Marked": {
+			reason: "References declared by a skipped embedded type should be found if the embedded field is marked.",
+			marker: "// +crossplane:generate:traverse",
+			want:   []string{"Spec.ForProvider.VPCID", "Spec.ResourceSpec.ProviderConfigID"},
 		},
-	}})
-	defer exported.Cleanup()
-	exported.Config.Mode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedSyntax
-	pkgs, err := packages.Load(exported.Config, fmt.Sprintf("file=%s", exported.File("golang.org/fake", "v1alpha1/model.go")))
-	if err != nil {
-		t.Error(err)
 	}
-	f := jen.NewFilePath("golang.org/fake/v1alpha1")
-	NewResolveReferences(xptypes.NewTraverser(comments.In(pkgs[0])), "mg", "example.org/client", "example.org/reference")(f, pkgs[0].Types.Scope().Lookup("Model"))
-	if diff := cmp.Diff(generated, fmt.Sprintf("%#v", f)); diff != "" {
-		t.Errorf("NewResolveReferences(): -want, +got\n%s", diff)
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			source := `
+package v1alpha1
+
+import xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+type ResourceSpec struct {
+	// +crossplane:generate:reference:type=ProviderConfig
+	ProviderConfigID *string
+
+	ProviderConfigIDRef *xpv1.Reference
+
+	ProviderConfigIDSelector *xpv1.Selector
+}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=VPC
+	VPCID *string
+
+	VPCIDRef *xpv1.Reference
+
+	VPCIDSelector *xpv1.Selector
+}
+
+type ModelSpec struct {
+	` + tc.marker + `
+	ResourceSpec
+
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+			p := loadPackage(t, source)
+			n := p.Types.Scope().Lookup("Model").Type().(*types.Named)
+			rp := NewReferenceProcessor("mg")
+			tr := xptypes.NewTraverser(comments.In(p), xptypes.WithSkippedTypes("golang.org/fake/v1alpha1.ResourceSpec"))
+			if err := tr.Traverse(n, &xptypes.ProcessorConfig{Field: rp.For(n), Named: xptypes.NamedProcessorChain{}}); err != nil {
+				t.Fatalf("\n%s\nTraverse(...): unexpected error: %v", tc.reason, err)
+			}
+			got := []string{}
+			for _, ref := range rp.ReferencesFor(n) {
+				got = append(got, GoPath(valueFields(ref)[1:]...))
+			}
+			sort.Strings(got)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nTraverse(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReferenceProcessorReuse(t *testing.T) {
+	// Model and Widget share the struct of their parameters, but Widget has
+	// another reference of its own.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Parameters struct {
+	// +crossplane:generate:reference:type=VPC
+	VPCID *string
+
+	VPCIDRef *Reference
+
+	VPCIDSelector *Selector
+}
+
+type Model struct {
+	ForProvider Parameters
+}
+
+type Widget struct {
+	ForProvider Parameters
+
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+`
+	want := map[string][]string{
+		"Model":  {"ForProvider.VPCID"},
+		"Widget": {"ForProvider.VPCID", "SubnetID"},
+	}
+
+	p := loadPackage(t, source)
+	rp := NewReferenceProcessor("mg")
+	process := func(name string) {
+		n := p.Types.Scope().Lookup(name).Type().(*types.Named)
+		tp := rp.For(n)
+		if err := xptypes.NewTraverser(comments.In(p)).Traverse(n, &xptypes.ProcessorConfig{Field: tp, Named: xptypes.NamedProcessorFn(tp.ProcessNamed)}); err != nil {
+			t.Errorf("Traverse(%s): unexpected error: %v", name, err)
+		}
+	}
+	check := func(reason string) {
+		for name, w := range want {
+			got := []string{}
+			for _, ref := range rp.ReferencesFor(p.Types.Scope().Lookup(name).Type().(*types.Named)) {
+				got = append(got, GoPath(valueFields(ref)[1:]...))
+			}
+			if diff := cmp.Diff(w, got); diff != "" {
+				t.Errorf("\n%s\nReferencesFor(%s): -want, +got:\n%s", reason, name, diff)
+			}
+		}
+	}
+
+	// Each type tree is traversed by its own Traverser, which is not safe
+	// for concurrent use.
+	var wg sync.WaitGroup
+	for name := range want {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			process(name)
+		}(name)
+	}
+	wg.Wait()
+	check("The references of types processed concurrently by one processor should not be mixed.")
+
+	process("Model")
+	check("The references of a type that is processed again should not be accumulated twice.")
+}
+
+func TestReferenceProcessorMissingFields(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		source string
+		root   string
+		want   string
+	}{
+		"MissingRefField": {
+			reason: "A reference without a Ref field should return an error naming the Ref field.",
+			source: `
+package v1alpha1
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=VPC
+	VPCID string
+
+	VPCIDSelector *Selector
+}
+`,
+			want: "field VPCID is a reference but ModelParameters has no VPCIDRef field",
+		},
+		"MissingSelectorField": {
+			reason: "A reference without a Selector field should return an error naming the Selector field.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:selectorFieldName=SubnetSelector
+	SubnetIDs []string
+
+	SubnetIDsRefs []Reference
+}
+`,
+			want: "field SubnetIDs is a reference but ModelParameters has no SubnetSelector field",
+		},
+		"JSONPath": {
+			reason: "An error should be prefixed by the JSON path of the field from the root type.",
+			source: `
+package v1alpha1
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=VPC
+	VPCID string `+"`json:\"vpcId\"`"+`
+
+	VPCIDSelector *Selector `+"`json:\"vpcIdSelector,omitempty\"`"+`
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters `+"`json:\"forProvider\"`"+`
+}
+
+type Model struct {
+	Spec ModelSpec `+"`json:\"spec\"`"+`
+}
+`,
+			root: "Model",
+			want: "spec.forProvider.vpcId: field VPCID is a reference but ModelParameters has no VPCIDRef field",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := loadPackage(t, tc.source)
+			root := "ModelParameters"
+			if tc.root != "" {
+				root = tc.root
+			}
+			n := p.Types.Scope().Lookup(root).Type().(*types.Named)
+			rp := NewReferenceProcessor("mg")
+			err := xptypes.NewTraverser(comments.In(p)).Traverse(n, &xptypes.ProcessorConfig{Field: rp.For(n), Named: xptypes.NamedProcessorChain{}})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("\n%s\nTraverse(...): want error containing %q, got %v", tc.reason, tc.want, err)
+			}
+		})
+	}
+}
+
+const deprecatedSource = `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=VPC
+	// +crossplane:generate:reference:deprecated=Use NetworkID instead.
+	VPCID *string
+
+	VPCIDRef *Reference
+
+	VPCIDSelector *Selector
+
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:deprecated
+	SubnetIDs []string
+
+	SubnetIDsRefs []Reference
+
+	SubnetIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+
+func TestNewResolveReferencesDeprecated(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		opts   []ResolveReferencesOption
+		want   string
+	}{
+		"NoRecorder": {
+			reason: "Deprecated references should be annotated with a Deprecated comment.",
+			want: `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	// Deprecated: Use NetworkID instead.
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.VPCID),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.VPCIDRef,
+		Selector:     mg.Spec.ForProvider.VPCIDSelector,
+		To: reference.To{
+			List:    &VPCList{},
+			Managed: &VPC{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.VPCID")
+	}
+	mg.Spec.ForProvider.VPCID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.VPCIDRef = rsp.ResolvedReference
+
+	// Deprecated: SubnetIDs is deprecated.
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SubnetIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SubnetIDsRefs,
+		Selector:      mg.Spec.ForProvider.SubnetIDsSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+	}
+	mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SubnetIDsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`,
+		},
+		"WithRecorder": {
+			reason: "Deprecated references should be recorded when their reference or selector is set.",
+			opts:   []ResolveReferencesOption{WithDeprecationRecorder("example.org/deprecation.Record")},
+			want: `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	deprecation "example.org/deprecation"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	// Deprecated: Use NetworkID instead.
+	if mg.Spec.ForProvider.VPCIDRef != nil || mg.Spec.ForProvider.VPCIDSelector != nil {
+		deprecation.Record(ctx, mg, "mg.Spec.ForProvider.VPCID", "Use NetworkID instead.")
+	}
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.VPCID),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.VPCIDRef,
+		Selector:     mg.Spec.ForProvider.VPCIDSelector,
+		To: reference.To{
+			List:    &VPCList{},
+			Managed: &VPC{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.VPCID")
+	}
+	mg.Spec.ForProvider.VPCID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.VPCIDRef = rsp.ResolvedReference
+
+	// Deprecated: SubnetIDs is deprecated.
+	if len(mg.Spec.ForProvider.SubnetIDsRefs) > 0 || mg.Spec.ForProvider.SubnetIDsSelector != nil {
+		deprecation.Record(ctx, mg, "mg.Spec.ForProvider.SubnetIDs", "SubnetIDs is deprecated.")
+	}
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SubnetIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SubnetIDsRefs,
+		Selector:      mg.Spec.ForProvider.SubnetIDsSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+	}
+	mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SubnetIDsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := resolveReferences(t, deprecatedSource, tc.opts...)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nNewResolveReferences(...): -want, +got\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNewResolveReferencesFormat(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	// +crossplane:generate:reference:format=arn:aws:iam::role/{name}
+	RoleARN *string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+
+	// +crossplane:generate:reference:type=Queue
+	// +crossplane:generate:reference:format=queues/{name}/messages
+	QueueURL string
+
+	QueueURLRef *Reference
+
+	QueueURLSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+	"strings"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: strings.TrimPrefix(reference.FromPtrValue(mg.Spec.ForProvider.RoleARN), "arn:aws:iam::role/"),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.RoleARNRef,
+		Selector:     mg.Spec.ForProvider.RoleARNSelector,
+		To: reference.To{
+			List:    &RoleList{},
+			Managed: &Role{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+	}
+	if rsp.ResolvedValue != "" {
+		rsp.ResolvedValue = "arn:aws:iam::role/" + rsp.ResolvedValue
+	}
+	mg.Spec.ForProvider.RoleARN = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.RoleARNRef = rsp.ResolvedReference
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: strings.TrimSuffix(strings.TrimPrefix(mg.Spec.ForProvider.QueueURL, "queues/"), "/messages"),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.QueueURLRef,
+		Selector:     mg.Spec.ForProvider.QueueURLSelector,
+		To: reference.To{
+			List:    &QueueList{},
+			Managed: &Queue{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.QueueURL")
+	}
+	if rsp.ResolvedValue != "" {
+		rsp.ResolvedValue = "queues/" + rsp.ResolvedValue + "/messages"
+	}
+	mg.Spec.ForProvider.QueueURL = rsp.ResolvedValue
+	mg.Spec.ForProvider.QueueURLRef = rsp.ResolvedReference
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesListType(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	// +crossplane:generate:reference:listType=Roles
+	RoleARN string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+
+	// +crossplane:generate:reference:type=example.org/iam/v1.Policy
+	// +crossplane:generate:reference:listType=example.org/iam/v1.PolicyCollection
+	PolicyARN string
+
+	PolicyARNRef *Reference
+
+	PolicyARNSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	v1 "example.org/iam/v1"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: mg.Spec.ForProvider.RoleARN,
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.RoleARNRef,
+		Selector:     mg.Spec.ForProvider.RoleARNSelector,
+		To: reference.To{
+			List:    &Roles{},
+			Managed: &Role{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+	}
+	mg.Spec.ForProvider.RoleARN = rsp.ResolvedValue
+	mg.Spec.ForProvider.RoleARNRef = rsp.ResolvedReference
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: mg.Spec.ForProvider.PolicyARN,
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.PolicyARNRef,
+		Selector:     mg.Spec.ForProvider.PolicyARNSelector,
+		To: reference.To{
+			List:    &v1.PolicyCollection{},
+			Managed: &v1.Policy{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.PolicyARN")
+	}
+	mg.Spec.ForProvider.PolicyARN = rsp.ResolvedValue
+	mg.Spec.ForProvider.PolicyARNRef = rsp.ResolvedReference
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesConstructor(t *testing.T) {
+	// Referenced resources are constructed by the functions named by their
+	// fields, for example to set their GroupVersionKind, rather than being
+	// the zero values of their types.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	// +crossplane:generate:reference:constructor=NewRole
+	RoleARN string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+
+	// +crossplane:generate:reference:type=example.org/iam/v1.Policy
+	// +crossplane:generate:reference:constructor=example.org/iam/v1.NewPolicy
+	PolicyARNs []string
+
+	PolicyARNsRefs []Reference
+
+	PolicyARNsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	v1 "example.org/iam/v1"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: mg.Spec.ForProvider.RoleARN,
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.RoleARNRef,
+		Selector:     mg.Spec.ForProvider.RoleARNSelector,
+		To: reference.To{
+			List:    &RoleList{},
+			Managed: NewRole(),
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+	}
+	mg.Spec.ForProvider.RoleARN = rsp.ResolvedValue
+	mg.Spec.ForProvider.RoleARNRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.PolicyARNs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.PolicyARNsRefs,
+		Selector:      mg.Spec.ForProvider.PolicyARNsSelector,
+		To: reference.To{
+			List:    &v1.PolicyList{},
+			Managed: v1.NewPolicy(),
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.PolicyARNs")
+	}
+	mg.Spec.ForProvider.PolicyARNs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.PolicyARNsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestReferenceProcessorConstructor(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		source string
+		want   string
+	}{
+		"Empty": {
+			reason: "A constructor should name a function.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Model struct {
+	// +crossplane:generate:reference:type=Role
+	// +crossplane:generate:reference:constructor=
+	RoleARN string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+}
+`,
+			want: "constructor of field RoleARN must be a function, supplied as <package path>.<name> or as the name of a function in the package of Model",
+		},
+		"Paved": {
+			reason: "The keys of a paved field should not share a constructor.",
+			source: `
+package v1alpha1
+
+type Model struct {
+	// +crossplane:generate:reference:paved=roleArn=Role
+	// +crossplane:generate:reference:constructor=NewRole
+	Parameters map[string]interface{}
+}
+`,
+			want: "cannot both be paved and use crossplane:generate:reference:constructor",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := loadPackage(t, tc.source)
+			n := p.Types.Scope().Lookup("Model").Type().(*types.Named)
+			rp := NewReferenceProcessor("mg")
+			err := xptypes.NewTraverser(comments.In(p)).Traverse(n, &xptypes.ProcessorConfig{Field: rp.For(n), Named: xptypes.NamedProcessorChain{}})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("\n%s\nTraverse(...): want error containing %q, got %v", tc.reason, tc.want, err)
+			}
+		})
+	}
+}
+
+func TestNewResolveReferencesSkipEmpty(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Endpoint struct {
+	Name *string
+}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	RoleARN string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+
+	// +crossplane:generate:reference:type=Subnet
+	SubnetIDs []string
+
+	SubnetIDsRefs []Reference
+
+	SubnetIDsSelector *Selector
+
+	// +crossplane:generate:reference:type=Service
+	// +crossplane:generate:reference:spreadInto=Name
+	Endpoints []Endpoint
+
+	EndpointsRefs []Reference
+
+	EndpointsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	if mg.Spec.ForProvider.RoleARNRef != nil || mg.Spec.ForProvider.RoleARNSelector != nil {
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: mg.Spec.ForProvider.RoleARN,
+			Extract:      reference.ExternalName(),
+			Reference:    mg.Spec.ForProvider.RoleARNRef,
+			Selector:     mg.Spec.ForProvider.RoleARNSelector,
+			To: reference.To{
+				List:    &RoleList{},
+				Managed: &Role{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+		}
+		mg.Spec.ForProvider.RoleARN = rsp.ResolvedValue
+		mg.Spec.ForProvider.RoleARNRef = rsp.ResolvedReference
+	}
+
+	if len(mg.Spec.ForProvider.SubnetIDsRefs) > 0 || mg.Spec.ForProvider.SubnetIDsSelector != nil {
+		mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+			CurrentValues: mg.Spec.ForProvider.SubnetIDs,
+			Extract:       reference.ExternalName(),
+			References:    mg.Spec.ForProvider.SubnetIDsRefs,
+			Selector:      mg.Spec.ForProvider.SubnetIDsSelector,
+			To: reference.To{
+				List:    &SubnetList{},
+				Managed: &Subnet{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+		}
+		mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
+		mg.Spec.ForProvider.SubnetIDsRefs = mrsp.ResolvedReferences
+	}
+
+	{
+		if len(mg.Spec.ForProvider.EndpointsRefs) > 0 || mg.Spec.ForProvider.EndpointsSelector != nil {
+			values := make([]string, len(mg.Spec.ForProvider.Endpoints))
+			for i := range mg.Spec.ForProvider.Endpoints {
+				values[i] = reference.FromPtrValue(mg.Spec.ForProvider.Endpoints[i].Name)
+			}
+			mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+				CurrentValues: values,
+				Extract:       reference.ExternalName(),
+				References:    mg.Spec.ForProvider.EndpointsRefs,
+				Selector:      mg.Spec.ForProvider.EndpointsSelector,
+				To: reference.To{
+					List:    &ServiceList{},
+					Managed: &Service{},
+				},
+			})
+			if err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Endpoints")
+			}
+			if n := len(mrsp.ResolvedValues) - len(mg.Spec.ForProvider.Endpoints); n > 0 {
+				mg.Spec.ForProvider.Endpoints = append(mg.Spec.ForProvider.Endpoints, make([]Endpoint, n)...)
+			}
+			for i, v := range mrsp.ResolvedValues {
+				mg.Spec.ForProvider.Endpoints[i].Name = reference.ToPtrValue(v)
+			}
+			mg.Spec.ForProvider.EndpointsRefs = mrsp.ResolvedReferences
+		}
+	}
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithSkipEmpty())); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+const noRefWriteBackSource = `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=VPC
+	// +crossplane:generate:reference:noRefWriteBack
+	VPCID *string
+
+	VPCIDRef *Reference
+
+	VPCIDSelector *Selector
+
+	// +crossplane:generate:reference:type=SecurityGroup
+	// +crossplane:generate:reference:noRefWriteBack
+	SecurityGroupIDs []string
+
+	SecurityGroupIDsRefs []Reference
+
+	SecurityGroupIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+
+const noRefFieldSource = `
+package v1alpha1
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=VPC
+	// +crossplane:generate:reference:noRefWriteBack
+	VPCID *string
+
+	VPCIDSelector *Selector
+
+	// +crossplane:generate:reference:type=SecurityGroup
+	// +crossplane:generate:reference:noRefWriteBack
+	SecurityGroupIDs []string
+
+	SecurityGroupIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+
+func TestNewResolveReferencesNoRefWriteBack(t *testing.T) {
+	disabled := WithSelectorsDisabled(func(_ types.Object) bool { return true })
+	cases := map[string]struct {
+		reason string
+		source string
+		opts   []ResolveReferencesOption
+		want   string
+	}{
+		"RefField": {
+			reason: "Resolved references should not be written back to reference fields.",
+			source: noRefWriteBackSource,
+			want: `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.VPCID),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.VPCIDRef,
+		Selector:     mg.Spec.ForProvider.VPCIDSelector,
+		To: reference.To{
+			List:    &VPCList{},
+			Managed: &VPC{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.VPCID")
+	}
+	mg.Spec.ForProvider.VPCID = reference.ToPtrValue(rsp.ResolvedValue)
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SecurityGroupIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SecurityGroupIDsRefs,
+		Selector:      mg.Spec.ForProvider.SecurityGroupIDsSelector,
+		To: reference.To{
+			List:    &SecurityGroupList{},
+			Managed: &SecurityGroup{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SecurityGroupIDs")
+	}
+	mg.Spec.ForProvider.SecurityGroupIDs = mrsp.ResolvedValues
+
+	return nil
+}
+`,
+		},
+		"NoRefField": {
+			reason: "Fields without reference fields should be resolved from their selectors only.",
+			source: noRefFieldSource,
+			want: `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.VPCID),
+		Extract:      reference.ExternalName(),
+		Reference:    nil,
+		Selector:     mg.Spec.ForProvider.VPCIDSelector,
+		To: reference.To{
+			List:    &VPCList{},
+			Managed: &VPC{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.VPCID")
+	}
+	mg.Spec.ForProvider.VPCID = reference.ToPtrValue(rsp.ResolvedValue)
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SecurityGroupIDs,
+		Extract:       reference.ExternalName(),
+		References:    nil,
+		Selector:      mg.Spec.ForProvider.SecurityGroupIDsSelector,
+		To: reference.To{
+			List:    &SecurityGroupList{},
+			Managed: &SecurityGroup{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SecurityGroupIDs")
+	}
+	mg.Spec.ForProvider.SecurityGroupIDs = mrsp.ResolvedValues
+
+	return nil
+}
+`,
+		},
+		"SelectorsDisabled": {
+			reason: "Fields whose selectors are disabled should be resolved from their references without writing them back.",
+			source: noRefWriteBackSource,
+			opts:   []ResolveReferencesOption{disabled},
+			want: `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	if mg.Spec.ForProvider.VPCIDSelector != nil {
+		return errors.New("mg.Spec.ForProvider.VPCID: cannot use VPCIDSelector, selectors are disabled")
+	}
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.VPCID),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.VPCIDRef,
+		To: reference.To{
+			List:    &VPCList{},
+			Managed: &VPC{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.VPCID")
+	}
+	mg.Spec.ForProvider.VPCID = reference.ToPtrValue(rsp.ResolvedValue)
+
+	if mg.Spec.ForProvider.SecurityGroupIDsSelector != nil {
+		return errors.New("mg.Spec.ForProvider.SecurityGroupIDs: cannot use SecurityGroupIDsSelector, selectors are disabled")
+	}
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SecurityGroupIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SecurityGroupIDsRefs,
+		To: reference.To{
+			List:    &SecurityGroupList{},
+			Managed: &SecurityGroup{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SecurityGroupIDs")
+	}
+	mg.Spec.ForProvider.SecurityGroupIDs = mrsp.ResolvedValues
+
+	return nil
+}
+`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, resolveReferences(t, tc.source, tc.opts...)); diff != "" {
+				t.Errorf("\n%s\nNewResolveReferences(...): -want, +got\n%s", tc.reason, diff)
+			}
+		})
+	}
+
+	t.Run("NoRefFieldSelectorsDisabled", func(t *testing.T) {
+		defer func() {
+			want := "Spec.ForProvider.VPCID of Model has no reference field and selectors are disabled, so it cannot be resolved"
+			if diff := cmp.Diff(want, fmt.Sprint(recover())); diff != "" {
+				t.Errorf("NewResolveReferences(...): -want panic, +got panic\n%s", diff)
+			}
+		}()
+		resolveReferences(t, noRefFieldSource, disabled)
+	})
+}
+
+func TestNewResolveReferencesWrapWithMessage(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	RoleARN string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+
+	// +crossplane:generate:reference:type=Subnet
+	SubnetIDs []string
+
+	SubnetIDsRefs []Reference
+
+	SubnetIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: mg.Spec.ForProvider.RoleARN,
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.RoleARNRef,
+		Selector:     mg.Spec.ForProvider.RoleARNSelector,
+		To: reference.To{
+			List:    &RoleList{},
+			Managed: &Role{},
+		},
+	})
+	if err != nil {
+		return errors.WithMessage(err, "mg.Spec.ForProvider.RoleARN")
+	}
+	mg.Spec.ForProvider.RoleARN = rsp.ResolvedValue
+	mg.Spec.ForProvider.RoleARNRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SubnetIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SubnetIDsRefs,
+		Selector:      mg.Spec.ForProvider.SubnetIDsSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.WithMessage(err, "mg.Spec.ForProvider.SubnetIDs")
+	}
+	mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SubnetIDsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithWrapWithMessage())); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesErrorWrapper(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	RoleARN string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+
+	// +crossplane:generate:reference:type=Subnet
+	SubnetIDs []string
+
+	SubnetIDsRefs []Reference
+
+	SubnetIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	providererrors "example.org/providererrors"
+	reference "example.org/reference"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: mg.Spec.ForProvider.RoleARN,
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.RoleARNRef,
+		Selector:     mg.Spec.ForProvider.RoleARNSelector,
+		To: reference.To{
+			List:    &RoleList{},
+			Managed: &Role{},
+		},
+	})
+	if err != nil {
+		return providererrors.Reference(err, "mg.Spec.ForProvider.RoleARN")
+	}
+	mg.Spec.ForProvider.RoleARN = rsp.ResolvedValue
+	mg.Spec.ForProvider.RoleARNRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SubnetIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SubnetIDsRefs,
+		Selector:      mg.Spec.ForProvider.SubnetIDsSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return providererrors.Reference(err, "mg.Spec.ForProvider.SubnetIDs")
+	}
+	mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SubnetIDsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithErrorWrapper("example.org/providererrors.Reference"), WithWrapWithMessage())); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesAssertions(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	RoleARN string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	resource "example.org/resource"
+	errors "github.com/pkg/errors"
+)
+
+var _ resource.Managed = &Model{}
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: mg.Spec.ForProvider.RoleARN,
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.RoleARNRef,
+		Selector:     mg.Spec.ForProvider.RoleARNSelector,
+		To: reference.To{
+			List:    &RoleList{},
+			Managed: &Role{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+	}
+	mg.Spec.ForProvider.RoleARN = rsp.ResolvedValue
+	mg.Spec.ForProvider.RoleARNRef = rsp.ResolvedReference
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithResource("example.org/resource"), WithAssertions())); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+	if got := resolveReferences(t, source, WithResource("example.org/resource")); strings.Contains(got, "var _") {
+		t.Errorf("NewResolveReferences(...): want no assertions without WithAssertions, got\n%s", got)
+	}
+}
+
+func TestNewResolveReferencesWhen(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	// +crossplane:generate:reference:when=UsesRole
+	RoleARN string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:when=example.org/conditions.InVPC
+	SubnetIDs []string
+
+	SubnetIDsRefs []Reference
+
+	SubnetIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+
+func UsesRole(mg *Model) bool { return true }
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	conditions "example.org/conditions"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	if UsesRole(mg) {
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: mg.Spec.ForProvider.RoleARN,
+			Extract:      reference.ExternalName(),
+			Reference:    mg.Spec.ForProvider.RoleARNRef,
+			Selector:     mg.Spec.ForProvider.RoleARNSelector,
+			To: reference.To{
+				List:    &RoleList{},
+				Managed: &Role{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+		}
+		mg.Spec.ForProvider.RoleARN = rsp.ResolvedValue
+		mg.Spec.ForProvider.RoleARNRef = rsp.ResolvedReference
+	}
+
+	if conditions.InVPC(mg) {
+		mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+			CurrentValues: mg.Spec.ForProvider.SubnetIDs,
+			Extract:       reference.ExternalName(),
+			References:    mg.Spec.ForProvider.SubnetIDsRefs,
+			Selector:      mg.Spec.ForProvider.SubnetIDsSelector,
+			To: reference.To{
+				List:    &SubnetList{},
+				Managed: &Subnet{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+		}
+		mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
+		mg.Spec.ForProvider.SubnetIDsRefs = mrsp.ResolvedReferences
+	}
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesLogging(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=example.org/iam/v1beta1.Role
+	RoleARN *string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+
+	// +crossplane:generate:reference:type=Subnet
+	SubnetIDs []string
+
+	SubnetIDsRefs []Reference
+
+	SubnetIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	v1beta1 "example.org/iam/v1beta1"
+	logging "example.org/logging"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	resolvedBy := func(refSet, selectorSet bool) string {
+		switch {
+		case refSet:
+			return "reference"
+		case selectorSet:
+			return "selector"
+		}
+		return "value"
+	}
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.RoleARN),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.RoleARNRef,
+		Selector:     mg.Spec.ForProvider.RoleARNSelector,
+		To: reference.To{
+			List:    &v1beta1.RoleList{},
+			Managed: &v1beta1.Role{},
+		},
+	})
+	logging.FromContext(ctx).Debug("Resolved reference", "field", "mg.Spec.ForProvider.RoleARN", "kind", "Role", "by", resolvedBy(mg.Spec.ForProvider.RoleARNRef != nil, mg.Spec.ForProvider.RoleARNSelector != nil), "error", err)
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+	}
+	mg.Spec.ForProvider.RoleARN = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.RoleARNRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SubnetIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SubnetIDsRefs,
+		Selector:      mg.Spec.ForProvider.SubnetIDsSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	logging.FromContext(ctx).Debug("Resolved reference", "field", "mg.Spec.ForProvider.SubnetIDs", "kind", "Subnet", "by", resolvedBy(len(mg.Spec.ForProvider.SubnetIDsRefs) > 0, mg.Spec.ForProvider.SubnetIDsSelector != nil), "error", err)
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+	}
+	mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SubnetIDsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithLogging("example.org/logging"))); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesEmbeddedTwice(t *testing.T) {
+	// Network is embedded by ModelParameters both directly and through
+	// Common. Its SubnetID is promoted from the shallower embedding, which
+	// shadows the deeper one, so only it is resolved.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Network struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+
+type Common struct {
+	Network
+}
+
+type ModelParameters struct {
+	Network
+	*Common
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: mg.Spec.ForProvider.Network.SubnetID,
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.Network.SubnetIDRef,
+		Selector:     mg.Spec.ForProvider.Network.SubnetIDSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.Network.SubnetID")
+	}
+	mg.Spec.ForProvider.Network.SubnetID = rsp.ResolvedValue
+	mg.Spec.ForProvider.Network.SubnetIDRef = rsp.ResolvedReference
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesAnonymousStructs(t *testing.T) {
+	// The fields of ModelParameters are of anonymous struct types declared
+	// inline, which are traversed like named struct types.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	Network struct {
+		// +crossplane:generate:reference:type=Subnet
+		SubnetID string
+
+		SubnetIDRef *Reference
+
+		SubnetIDSelector *Selector
+	}
+
+	Firewall *struct {
+		// +crossplane:generate:reference:type=SecurityGroup
+		SecurityGroupIDs []string
+
+		SecurityGroupIDsRefs []Reference
+
+		SecurityGroupIDsSelector *Selector
+	}
+
+	Rules []struct {
+		// +crossplane:generate:reference:type=Gateway
+		GatewayID *string
+
+		GatewayIDRef *Reference
+
+		GatewayIDSelector *Selector
+	}
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: mg.Spec.ForProvider.Network.SubnetID,
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.Network.SubnetIDRef,
+		Selector:     mg.Spec.ForProvider.Network.SubnetIDSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.Network.SubnetID")
+	}
+	mg.Spec.ForProvider.Network.SubnetID = rsp.ResolvedValue
+	mg.Spec.ForProvider.Network.SubnetIDRef = rsp.ResolvedReference
+
+	if mg.Spec.ForProvider.Firewall != nil {
+		mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+			CurrentValues: mg.Spec.ForProvider.Firewall.SecurityGroupIDs,
+			Extract:       reference.ExternalName(),
+			References:    mg.Spec.ForProvider.Firewall.SecurityGroupIDsRefs,
+			Selector:      mg.Spec.ForProvider.Firewall.SecurityGroupIDsSelector,
+			To: reference.To{
+				List:    &SecurityGroupList{},
+				Managed: &SecurityGroup{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Firewall.SecurityGroupIDs")
+		}
+		mg.Spec.ForProvider.Firewall.SecurityGroupIDs = mrsp.ResolvedValues
+		mg.Spec.ForProvider.Firewall.SecurityGroupIDsRefs = mrsp.ResolvedReferences
+
+	}
+	for i3 := range mg.Spec.ForProvider.Rules {
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Rules[i3].GatewayID),
+			Extract:      reference.ExternalName(),
+			Reference:    mg.Spec.ForProvider.Rules[i3].GatewayIDRef,
+			Selector:     mg.Spec.ForProvider.Rules[i3].GatewayIDSelector,
+			To: reference.To{
+				List:    &GatewayList{},
+				Managed: &Gateway{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Rules[*].GatewayID")
+		}
+		mg.Spec.ForProvider.Rules[i3].GatewayID = reference.ToPtrValue(rsp.ResolvedValue)
+		mg.Spec.ForProvider.Rules[i3].GatewayIDRef = rsp.ResolvedReference
+
+	}
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesDependencyAnnotation(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=example.org/iam/v1beta1.Role
+	RoleARN *string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+
+	// +crossplane:generate:reference:type=Subnet
+	SubnetIDs []string
+
+	SubnetIDsRefs []Reference
+
+	SubnetIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+
+func (mg *Model) GetAnnotations() map[string]string { return nil }
+
+func (mg *Model) SetAnnotations(map[string]string) {}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	client "example.org/client"
+	v1beta1 "example.org/iam/v1beta1"
+	reference "example.org/reference"
+	"fmt"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var dependencies []map[string]string
+	var err error
+
+	hashInputs := func() (string, error) {
+		var inputs []interface{}
+		inputs = append(inputs, mg.Spec.ForProvider.RoleARNRef, mg.Spec.ForProvider.RoleARNSelector)
+		inputs = append(inputs, mg.Spec.ForProvider.SubnetIDsRefs, mg.Spec.ForProvider.SubnetIDsSelector)
+
+		b, err := json.Marshal(inputs)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+	}
+	hash, err := hashInputs()
+	if err != nil {
+		return errors.Wrap(err, "cannot hash references and selectors")
+	}
+	if mg.GetAnnotations()["example.org/resolved-inputs"] == hash {
+		return nil
+	}
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.RoleARN),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.RoleARNRef,
+		Selector:     mg.Spec.ForProvider.RoleARNSelector,
+		To: reference.To{
+			List:    &v1beta1.RoleList{},
+			Managed: &v1beta1.Role{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+	}
+	mg.Spec.ForProvider.RoleARN = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.RoleARNRef = rsp.ResolvedReference
+	if rsp.ResolvedReference != nil {
+		dependencies = append(dependencies, map[string]string{"kind": "Role", "name": rsp.ResolvedReference.Name})
+	}
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SubnetIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SubnetIDsRefs,
+		Selector:      mg.Spec.ForProvider.SubnetIDsSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+	}
+	mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SubnetIDsRefs = mrsp.ResolvedReferences
+	for _, dep := range mrsp.ResolvedReferences {
+		dependencies = append(dependencies, map[string]string{"kind": "Subnet", "name": dep.Name})
+	}
+
+	if hash, err = hashInputs(); err != nil {
+		return errors.Wrap(err, "cannot hash references and selectors")
+	}
+	deps, err := json.Marshal(dependencies)
+	if err != nil {
+		return errors.Wrap(err, "cannot marshal resolved dependencies")
+	}
+	annotations := mg.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations["example.org/resolved-inputs"] = hash
+	annotations["example.org/dependencies"] = string(deps)
+	mg.SetAnnotations(annotations)
+
+	return nil
+}
+`
+	got := resolveReferences(t, source, WithDependencyAnnotation("example.org/dependencies"), WithSkipUnchanged("example.org/resolved-inputs"))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesProvenance(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Rule struct {
+	// +crossplane:generate:reference:type=Gateway
+	GatewayID *string ` + "`json:\"gatewayId,omitempty\"`" + `
+
+	GatewayIDRef *Reference ` + "`json:\"gatewayIdRef,omitempty\"`" + `
+
+	GatewayIDSelector *Selector ` + "`json:\"gatewayIdSelector,omitempty\"`" + `
+}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	RoleARN *string ` + "`json:\"roleArn,omitempty\"`" + `
+
+	RoleARNRef *Reference ` + "`json:\"roleArnRef,omitempty\"`" + `
+
+	RoleARNSelector *Selector ` + "`json:\"roleArnSelector,omitempty\"`" + `
+
+	// +crossplane:generate:reference:type=Subnet
+	SubnetIDs []string ` + "`json:\"subnetIds,omitempty\"`" + `
+
+	SubnetIDsRefs []Reference ` + "`json:\"subnetIdsRefs,omitempty\"`" + `
+
+	SubnetIDsSelector *Selector ` + "`json:\"subnetIdsSelector,omitempty\"`" + `
+
+	Rules []Rule ` + "`json:\"rules,omitempty\"`" + `
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters ` + "`json:\"forProvider\"`" + `
+}
+
+type Model struct {
+	Spec ModelSpec ` + "`json:\"spec\"`" + `
+}
+
+func (mg *Model) GetNamespace() string { return "" }
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	provenance "example.org/provenance"
+	reference "example.org/reference"
+	"fmt"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.RoleARN),
+		Extract:      reference.ExternalName(),
+		Namespace:    mg.GetNamespace(),
+		Reference:    mg.Spec.ForProvider.RoleARNRef,
+		Selector:     mg.Spec.ForProvider.RoleARNSelector,
+		To: reference.To{
+			List:    &RoleList{},
+			Managed: &Role{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+	}
+	mg.Spec.ForProvider.RoleARN = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.RoleARNRef = rsp.ResolvedReference
+	if err = provenance.Record(ctx, c, mg, "spec.forProvider.roleArn", &Role{}, mg.GetNamespace(), rsp.ResolvedReference); err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+	}
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SubnetIDs,
+		Extract:       reference.ExternalName(),
+		Namespace:     mg.GetNamespace(),
+		References:    mg.Spec.ForProvider.SubnetIDsRefs,
+		Selector:      mg.Spec.ForProvider.SubnetIDsSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+	}
+	mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SubnetIDsRefs = mrsp.ResolvedReferences
+	if err = provenance.RecordMultiple(ctx, c, mg, "spec.forProvider.subnetIds", &Subnet{}, mg.GetNamespace(), mrsp.ResolvedReferences); err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+	}
+
+	for i3 := range mg.Spec.ForProvider.Rules {
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Rules[i3].GatewayID),
+			Extract:      reference.ExternalName(),
+			Namespace:    mg.GetNamespace(),
+			Reference:    mg.Spec.ForProvider.Rules[i3].GatewayIDRef,
+			Selector:     mg.Spec.ForProvider.Rules[i3].GatewayIDSelector,
+			To: reference.To{
+				List:    &GatewayList{},
+				Managed: &Gateway{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Rules[*].GatewayID")
+		}
+		mg.Spec.ForProvider.Rules[i3].GatewayID = reference.ToPtrValue(rsp.ResolvedValue)
+		mg.Spec.ForProvider.Rules[i3].GatewayIDRef = rsp.ResolvedReference
+		if err = provenance.Record(ctx, c, mg, fmt.Sprintf("spec.forProvider.rules[%d].gatewayId", i3), &Gateway{}, mg.GetNamespace(), rsp.ResolvedReference); err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Rules[*].GatewayID")
+		}
+
+	}
+
+	return nil
+}
+`
+	namespaced := func(_ types.Object) bool { return true }
+	got := resolveReferences(t, source, WithProvenance("example.org/provenance"), WithNamespaced(namespaced))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+	if got := resolveReferences(t, source, WithNamespaced(namespaced)); strings.Contains(got, "provenance") {
+		t.Errorf("NewResolveReferences(...): provenance should not be recorded unless it is configured:\n%s", got)
+	}
+}
+
+func TestNewResolveReferencesControllerRuntime(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=example.org/iam/v1beta1.Role
+	RoleARN *string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:extractor=example.org/extractors.SubnetID()
+	SubnetIDs []string
+
+	SubnetIDsRefs []Reference
+
+	SubnetIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+
+func (mg *Model) GetNamespace() string { return "" }
+
+func (mg *Model) GetAnnotations() map[string]string { return nil }
+
+func (mg *Model) SetAnnotations(map[string]string) {}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	client "example.org/client"
+	extractors "example.org/extractors"
+	v1beta1 "example.org/iam/v1beta1"
+	meta "example.org/meta"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	var dependencies []map[string]string
+	var err error
+
+	{
+		ref := mg.Spec.ForProvider.RoleARNRef
+		if ref == nil && mg.Spec.ForProvider.RoleARNSelector != nil {
+			l := &v1beta1.RoleList{}
+			if err = c.List(ctx, l, client.MatchingLabels(mg.Spec.ForProvider.RoleARNSelector.MatchLabels), client.InNamespace(mg.GetNamespace())); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+			}
+
+			for i := range l.Items {
+				if mg.Spec.ForProvider.RoleARNSelector.MatchControllerRef != nil && *mg.Spec.ForProvider.RoleARNSelector.MatchControllerRef && !meta.HaveSameController(mg, &l.Items[i]) {
+					continue
+				}
+				ref = &Reference{Name: l.Items[i].GetName()}
+				break
+			}
+
+			if ref == nil {
+				return errors.New("mg.Spec.ForProvider.RoleARN: no resources matched selector")
+			}
+		}
+
+		if ref != nil {
+			to := &v1beta1.Role{}
+			if err = c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: mg.GetNamespace()}, to); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+			}
+			v := meta.GetExternalName(to)
+			if v == "" {
+				return errors.New("mg.Spec.ForProvider.RoleARN: referenced field was empty (referenced resource may not yet be ready)")
+			}
+
+			mg.Spec.ForProvider.RoleARN = &v
+			mg.Spec.ForProvider.RoleARNRef = ref
+
+			dependencies = append(dependencies, map[string]string{"kind": "Role", "name": ref.Name})
+		}
+	}
+
+	{
+		refs := mg.Spec.ForProvider.SubnetIDsRefs
+		if len(refs) == 0 && mg.Spec.ForProvider.SubnetIDsSelector != nil {
+			l := &SubnetList{}
+			if err = c.List(ctx, l, client.MatchingLabels(mg.Spec.ForProvider.SubnetIDsSelector.MatchLabels), client.InNamespace(mg.GetNamespace())); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+			}
+
+			for i := range l.Items {
+				if mg.Spec.ForProvider.SubnetIDsSelector.MatchControllerRef != nil && *mg.Spec.ForProvider.SubnetIDsSelector.MatchControllerRef && !meta.HaveSameController(mg, &l.Items[i]) {
+					continue
+				}
+				refs = append(refs, Reference{Name: l.Items[i].GetName()})
+			}
+
+			if len(refs) == 0 {
+				return errors.New("mg.Spec.ForProvider.SubnetIDs: no resources matched selector")
+			}
+		}
+
+		if len(refs) > 0 {
+			values := make([]string, 0, len(refs))
+			for _, ref := range refs {
+				to := &Subnet{}
+				if err = c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: mg.GetNamespace()}, to); err != nil {
+					return errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+				}
+				v := extractors.SubnetID()(to)
+				if v == "" {
+					return errors.New("mg.Spec.ForProvider.SubnetIDs: referenced field was empty (referenced resource may not yet be ready)")
+				}
+				values = append(values, v)
+			}
+
+			mg.Spec.ForProvider.SubnetIDs = values
+			mg.Spec.ForProvider.SubnetIDsRefs = refs
+
+			for _, dep := range refs {
+				dependencies = append(dependencies, map[string]string{"kind": "Subnet", "name": dep.Name})
+			}
+		}
+	}
+
+	deps, err := json.Marshal(dependencies)
+	if err != nil {
+		return errors.Wrap(err, "cannot marshal resolved dependencies")
+	}
+	annotations := mg.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations["example.org/dependencies"] = string(deps)
+	mg.SetAnnotations(annotations)
+
+	return nil
+}
+`
+	namespaced := func(_ types.Object) bool { return true }
+	got := resolveReferences(t, source, WithControllerRuntime("example.org/meta"), WithNamespaced(namespaced), WithDependencyAnnotation("example.org/dependencies"))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesPaved(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {
+	Name string
+}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:paved=vpcId=example.org/ec2/v1beta1.VPC;subnetId=Subnet
+	Parameters map[string]interface{}
+
+	// +crossplane:generate:reference:paved=roleArn=example.org/iam/v1beta1.Role
+	// +crossplane:generate:reference:pavedRefs=SettingsRefs
+	Settings map[string]interface{}
+
+	SettingsRefs map[string]Reference
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+
+func (mg *Model) GetAnnotations() map[string]string { return nil }
+
+func (mg *Model) SetAnnotations(map[string]string) {}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	client "example.org/client"
+	v1beta1 "example.org/ec2/v1beta1"
+	fieldpath "example.org/fieldpath"
+	v1beta11 "example.org/iam/v1beta1"
+	reference "example.org/reference"
+	"fmt"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	hashInputs := func() (string, error) {
+		var inputs []interface{}
+		inputs = append(inputs, mg.Spec.ForProvider.Parameters["vpcIdRef"], mg.Spec.ForProvider.Parameters["vpcIdSelector"])
+		inputs = append(inputs, mg.Spec.ForProvider.Parameters["subnetIdRef"], mg.Spec.ForProvider.Parameters["subnetIdSelector"])
+		inputs = append(inputs, mg.Spec.ForProvider.SettingsRefs["roleArn"], mg.Spec.ForProvider.Settings["roleArnSelector"])
+
+		b, err := json.Marshal(inputs)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+	}
+	hash, err := hashInputs()
+	if err != nil {
+		return errors.Wrap(err, "cannot hash references and selectors")
+	}
+	if mg.GetAnnotations()["example.org/resolved-inputs"] == hash {
+		return nil
+	}
+
+	{
+		p := fieldpath.Pave(mg.Spec.ForProvider.Parameters)
+		current, _ := p.GetString("vpcId")
+		var ref *Reference
+		if err = p.GetValueInto("vpcIdRef", &ref); err != nil && !fieldpath.IsNotFound(err) {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Parameters.vpcId")
+		}
+
+		var selector *Selector
+		if err = p.GetValueInto("vpcIdSelector", &selector); err != nil && !fieldpath.IsNotFound(err) {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Parameters.vpcId")
+		}
+
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: current,
+			Extract:      reference.ExternalName(),
+			Reference:    ref,
+			Selector:     selector,
+			To: reference.To{
+				List:    &v1beta1.VPCList{},
+				Managed: &v1beta1.VPC{},
+			},
+		})
+
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Parameters.vpcId")
+		}
+
+		if rsp.ResolvedValue != current {
+			if err = p.SetValue("vpcId", rsp.ResolvedValue); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Parameters.vpcId")
+			}
+		}
+
+		if ref != nil {
+			if err = p.DeleteField("vpcIdSelector"); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Parameters.vpcId")
+			}
+		}
+
+		if rsp.ResolvedReference != nil {
+			if err = p.SetValue("vpcIdRef", rsp.ResolvedReference); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Parameters.vpcId")
+			}
+		}
+
+		mg.Spec.ForProvider.Parameters = p.UnstructuredContent()
+	}
+
+	{
+		p := fieldpath.Pave(mg.Spec.ForProvider.Parameters)
+		current, _ := p.GetString("subnetId")
+		var ref *Reference
+		if err = p.GetValueInto("subnetIdRef", &ref); err != nil && !fieldpath.IsNotFound(err) {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Parameters.subnetId")
+		}
+
+		var selector *Selector
+		if err = p.GetValueInto("subnetIdSelector", &selector); err != nil && !fieldpath.IsNotFound(err) {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Parameters.subnetId")
+		}
+
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: current,
+			Extract:      reference.ExternalName(),
+			Reference:    ref,
+			Selector:     selector,
+			To: reference.To{
+				List:    &SubnetList{},
+				Managed: &Subnet{},
+			},
+		})
+
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Parameters.subnetId")
+		}
+
+		if rsp.ResolvedValue != current {
+			if err = p.SetValue("subnetId", rsp.ResolvedValue); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Parameters.subnetId")
+			}
+		}
+
+		if ref != nil {
+			if err = p.DeleteField("subnetIdSelector"); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Parameters.subnetId")
+			}
+		}
+
+		if rsp.ResolvedReference != nil {
+			if err = p.SetValue("subnetIdRef", rsp.ResolvedReference); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Parameters.subnetId")
+			}
+		}
+
+		mg.Spec.ForProvider.Parameters = p.UnstructuredContent()
+	}
+
+	{
+		p := fieldpath.Pave(mg.Spec.ForProvider.Settings)
+		current, _ := p.GetString("roleArn")
+		var ref *Reference
+		if rr, ok := mg.Spec.ForProvider.SettingsRefs["roleArn"]; ok {
+			ref = &rr
+		}
+
+		var selector *Selector
+		if err = p.GetValueInto("roleArnSelector", &selector); err != nil && !fieldpath.IsNotFound(err) {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Settings.roleArn")
+		}
+
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: current,
+			Extract:      reference.ExternalName(),
+			Reference:    ref,
+			Selector:     selector,
+			To: reference.To{
+				List:    &v1beta11.RoleList{},
+				Managed: &v1beta11.Role{},
+			},
+		})
+
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Settings.roleArn")
+		}
+
+		if rsp.ResolvedValue != current {
+			if err = p.SetValue("roleArn", rsp.ResolvedValue); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Settings.roleArn")
+			}
+		}
+
+		if ref != nil {
+			if err = p.DeleteField("roleArnSelector"); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Settings.roleArn")
+			}
+		}
+
+		if rsp.ResolvedReference != nil {
+			if mg.Spec.ForProvider.SettingsRefs == nil {
+				mg.Spec.ForProvider.SettingsRefs = map[string]Reference{}
+			}
+			mg.Spec.ForProvider.SettingsRefs["roleArn"] = *rsp.ResolvedReference
+		}
+
+		mg.Spec.ForProvider.Settings = p.UnstructuredContent()
+	}
+
+	if hash, err = hashInputs(); err != nil {
+		return errors.Wrap(err, "cannot hash references and selectors")
+	}
+	annotations := mg.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations["example.org/resolved-inputs"] = hash
+	mg.SetAnnotations(annotations)
+
+	return nil
+}
+`
+	got := resolveReferences(t, source, WithRuntime("golang.org/fake/v1alpha1"), WithFieldPath("example.org/fieldpath"), WithClearSelectors(), WithSkipUnchanged("example.org/resolved-inputs"))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesPavedPaths(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {
+	Name string
+}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:pavedPaths=network.vpcId=example.org/ec2/v1beta1.VPC;network.subnets[0].id=Subnet
+	Properties map[string]interface{}
+
+	// +crossplane:generate:reference:pavedPaths=[iam.example.org/role].arn=example.org/iam/v1beta1.Role
+	// +crossplane:generate:reference:pavedRefs=SettingsRefs
+	Settings map[string]interface{}
+
+	SettingsRefs map[string]Reference
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+
+func (mg *Model) GetAnnotations() map[string]string { return nil }
+
+func (mg *Model) SetAnnotations(map[string]string) {}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	client "example.org/client"
+	v1beta1 "example.org/ec2/v1beta1"
+	fieldpath "example.org/fieldpath"
+	v1beta11 "example.org/iam/v1beta1"
+	reference "example.org/reference"
+	"fmt"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	hashInputs := func() (string, error) {
+		var inputs []interface{}
+		{
+			p := fieldpath.Pave(mg.Spec.ForProvider.Properties)
+			ref, _ := p.GetValue("network.vpcIdRef")
+			selector, _ := p.GetValue("network.vpcIdSelector")
+			inputs = append(inputs, ref, selector)
+		}
+		{
+			p := fieldpath.Pave(mg.Spec.ForProvider.Properties)
+			ref, _ := p.GetValue("network.subnets[0].idRef")
+			selector, _ := p.GetValue("network.subnets[0].idSelector")
+			inputs = append(inputs, ref, selector)
+		}
+		{
+			p := fieldpath.Pave(mg.Spec.ForProvider.Settings)
+			ref := mg.Spec.ForProvider.SettingsRefs["[iam.example.org/role].arn"]
+			selector, _ := p.GetValue("[iam.example.org/role].arnSelector")
+			inputs = append(inputs, ref, selector)
+		}
+
+		b, err := json.Marshal(inputs)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+	}
+	hash, err := hashInputs()
+	if err != nil {
+		return errors.Wrap(err, "cannot hash references and selectors")
+	}
+	if mg.GetAnnotations()["example.org/resolved-inputs"] == hash {
+		return nil
+	}
+
+	{
+		p := fieldpath.Pave(mg.Spec.ForProvider.Properties)
+		current, _ := p.GetString("network.vpcId")
+		var ref *Reference
+		if err = p.GetValueInto("network.vpcIdRef", &ref); err != nil && !fieldpath.IsNotFound(err) {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Properties.network.vpcId")
+		}
+
+		var selector *Selector
+		if err = p.GetValueInto("network.vpcIdSelector", &selector); err != nil && !fieldpath.IsNotFound(err) {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Properties.network.vpcId")
+		}
+
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: current,
+			Extract:      reference.ExternalName(),
+			Reference:    ref,
+			Selector:     selector,
+			To: reference.To{
+				List:    &v1beta1.VPCList{},
+				Managed: &v1beta1.VPC{},
+			},
+		})
+
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Properties.network.vpcId")
+		}
+
+		if rsp.ResolvedValue != current {
+			if err = p.SetValue("network.vpcId", rsp.ResolvedValue); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Properties.network.vpcId")
+			}
+		}
+
+		if rsp.ResolvedReference != nil {
+			if err = p.SetValue("network.vpcIdRef", rsp.ResolvedReference); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Properties.network.vpcId")
+			}
+		}
+
+		mg.Spec.ForProvider.Properties = p.UnstructuredContent()
+	}
+
+	{
+		p := fieldpath.Pave(mg.Spec.ForProvider.Properties)
+		current, _ := p.GetString("network.subnets[0].id")
+		var ref *Reference
+		if err = p.GetValueInto("network.subnets[0].idRef", &ref); err != nil && !fieldpath.IsNotFound(err) {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Properties.network.subnets[0].id")
+		}
+
+		var selector *Selector
+		if err = p.GetValueInto("network.subnets[0].idSelector", &selector); err != nil && !fieldpath.IsNotFound(err) {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Properties.network.subnets[0].id")
+		}
+
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: current,
+			Extract:      reference.ExternalName(),
+			Reference:    ref,
+			Selector:     selector,
+			To: reference.To{
+				List:    &SubnetList{},
+				Managed: &Subnet{},
+			},
+		})
+
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Properties.network.subnets[0].id")
+		}
+
+		if rsp.ResolvedValue != current {
+			if err = p.SetValue("network.subnets[0].id", rsp.ResolvedValue); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Properties.network.subnets[0].id")
+			}
+		}
+
+		if rsp.ResolvedReference != nil {
+			if err = p.SetValue("network.subnets[0].idRef", rsp.ResolvedReference); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Properties.network.subnets[0].id")
+			}
+		}
+
+		mg.Spec.ForProvider.Properties = p.UnstructuredContent()
+	}
+
+	{
+		p := fieldpath.Pave(mg.Spec.ForProvider.Settings)
+		current, _ := p.GetString("[iam.example.org/role].arn")
+		var ref *Reference
+		if rr, ok := mg.Spec.ForProvider.SettingsRefs["[iam.example.org/role].arn"]; ok {
+			ref = &rr
+		}
+
+		var selector *Selector
+		if err = p.GetValueInto("[iam.example.org/role].arnSelector", &selector); err != nil && !fieldpath.IsNotFound(err) {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Settings[iam.example.org/role].arn")
+		}
+
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: current,
+			Extract:      reference.ExternalName(),
+			Reference:    ref,
+			Selector:     selector,
+			To: reference.To{
+				List:    &v1beta11.RoleList{},
+				Managed: &v1beta11.Role{},
+			},
+		})
+
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Settings[iam.example.org/role].arn")
+		}
+
+		if rsp.ResolvedValue != current {
+			if err = p.SetValue("[iam.example.org/role].arn", rsp.ResolvedValue); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Settings[iam.example.org/role].arn")
+			}
+		}
+
+		if rsp.ResolvedReference != nil {
+			if mg.Spec.ForProvider.SettingsRefs == nil {
+				mg.Spec.ForProvider.SettingsRefs = map[string]Reference{}
+			}
+			mg.Spec.ForProvider.SettingsRefs["[iam.example.org/role].arn"] = *rsp.ResolvedReference
+		}
+
+		mg.Spec.ForProvider.Settings = p.UnstructuredContent()
+	}
+
+	if hash, err = hashInputs(); err != nil {
+		return errors.Wrap(err, "cannot hash references and selectors")
+	}
+	annotations := mg.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations["example.org/resolved-inputs"] = hash
+	mg.SetAnnotations(annotations)
+
+	return nil
+}
+`
+	got := resolveReferences(t, source, WithRuntime("golang.org/fake/v1alpha1"), WithFieldPath("example.org/fieldpath"), WithSkipUnchanged("example.org/resolved-inputs"))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesMirrors(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {
+	Name string
+}
+
+type Selector struct {}
+
+type Labels struct {
+	Cluster *string
+}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:alsoSet=Tags[cluster]
+	// +crossplane:generate:reference:alsoSet=Labels.Cluster
+	// +crossplane:generate:reference:alsoSet=ClusterID
+	ClusterName *string
+
+	ClusterNameRef      *Reference
+	ClusterNameSelector *Selector
+
+	ClusterID string
+
+	Tags map[string]string
+
+	Labels *Labels
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.ClusterName),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.ClusterNameRef,
+		Selector:     mg.Spec.ForProvider.ClusterNameSelector,
+		To: reference.To{
+			List:    &ClusterList{},
+			Managed: &Cluster{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.ClusterName")
+	}
+	mg.Spec.ForProvider.ClusterName = reference.ToPtrValue(rsp.ResolvedValue)
+	if mg.Spec.ForProvider.Tags == nil {
+		mg.Spec.ForProvider.Tags = map[string]string{}
+	}
+	mg.Spec.ForProvider.Tags["cluster"] = rsp.ResolvedValue
+	if mg.Spec.ForProvider.Labels == nil {
+		mg.Spec.ForProvider.Labels = &Labels{}
+	}
+	mg.Spec.ForProvider.Labels.Cluster = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.ClusterID = rsp.ResolvedValue
+	mg.Spec.ForProvider.ClusterNameRef = rsp.ResolvedReference
+
+	return nil
+}
+`
+	got := resolveReferences(t, source)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesClusterScoped(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+
+	// +crossplane:generate:reference:type=Account
+	// +crossplane:generate:reference:clusterScoped
+	AccountIDs []string
+
+	AccountIDsRefs []Reference
+
+	AccountIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	cases := map[string]struct {
+		reason     string
+		namespaced bool
+		want       string
+	}{
+		"ClusterScopedSource": {
+			reason: "References from a cluster scoped resource should not be resolved in a namespace.",
+			want: `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: mg.Spec.ForProvider.SubnetID,
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.SubnetIDRef,
+		Selector:     mg.Spec.ForProvider.SubnetIDSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetID")
+	}
+	mg.Spec.ForProvider.SubnetID = rsp.ResolvedValue
+	mg.Spec.ForProvider.SubnetIDRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.AccountIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.AccountIDsRefs,
+		Selector:      mg.Spec.ForProvider.AccountIDsSelector,
+		To: reference.To{
+			List:    &AccountList{},
+			Managed: &Account{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.AccountIDs")
+	}
+	mg.Spec.ForProvider.AccountIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.AccountIDsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`,
+		},
+		"NamespacedSource": {
+			reason:     "References from a namespace scoped resource should be resolved in its namespace, unless the referenced type is cluster scoped.",
+			namespaced: true,
+			want: `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: mg.Spec.ForProvider.SubnetID,
+		Extract:      reference.ExternalName(),
+		Namespace:    mg.GetNamespace(),
+		Reference:    mg.Spec.ForProvider.SubnetIDRef,
+		Selector:     mg.Spec.ForProvider.SubnetIDSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetID")
+	}
+	mg.Spec.ForProvider.SubnetID = rsp.ResolvedValue
+	mg.Spec.ForProvider.SubnetIDRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.AccountIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.AccountIDsRefs,
+		Selector:      mg.Spec.ForProvider.AccountIDsSelector,
+		To: reference.To{
+			List:    &AccountList{},
+			Managed: &Account{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.AccountIDs")
+	}
+	mg.Spec.ForProvider.AccountIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.AccountIDsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			namespaced := WithNamespaced(func(_ types.Object) bool { return tc.namespaced })
+			if diff := cmp.Diff(tc.want, resolveReferences(t, source, namespaced)); diff != "" {
+				t.Errorf("\n%s\nNewResolveReferences(...): -want, +got\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNewResolveReferencesSelectorsDisabled(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+
+	// +crossplane:generate:reference:type=SecurityGroup
+	SecurityGroupIDs []string
+
+	SecurityGroupIDsRefs []Reference
+
+	SecurityGroupIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	if mg.Spec.ForProvider.SubnetIDSelector != nil {
+		return errors.New("mg.Spec.ForProvider.SubnetID: cannot use SubnetIDSelector, selectors are disabled")
+	}
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.SubnetID),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.SubnetIDRef,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetID")
+	}
+	mg.Spec.ForProvider.SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.SubnetIDRef = rsp.ResolvedReference
+
+	if mg.Spec.ForProvider.SecurityGroupIDsSelector != nil {
+		return errors.New("mg.Spec.ForProvider.SecurityGroupIDs: cannot use SecurityGroupIDsSelector, selectors are disabled")
+	}
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SecurityGroupIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SecurityGroupIDsRefs,
+		To: reference.To{
+			List:    &SecurityGroupList{},
+			Managed: &SecurityGroup{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SecurityGroupIDs")
+	}
+	mg.Spec.ForProvider.SecurityGroupIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SecurityGroupIDsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`
+	disabled := WithSelectorsDisabled(func(_ types.Object) bool { return true })
+	if diff := cmp.Diff(want, resolveReferences(t, source, disabled)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesImplementations(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Source interface {
+	isSource()
+}
+
+type S3Source struct {
+	// +crossplane:generate:reference:type=Bucket
+	BucketName string
+
+	BucketNameRef *Reference
+
+	BucketNameSelector *Selector
+}
+
+func (s *S3Source) isSource() {}
+
+type GCSSource struct {
+	// +crossplane:generate:reference:type=Bucket
+	BucketNames []string
+
+	BucketNamesRefs []Reference
+
+	BucketNamesSelector *Selector
+}
+
+func (s *GCSSource) isSource() {}
+
+type ModelParameters struct {
+	// +crossplane:generate:implementations=S3Source,GCSSource
+	Source Source
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	switch mg.Spec.ForProvider.Source.(type) {
+	case *S3Source:
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: mg.Spec.ForProvider.Source.(*S3Source).BucketName,
+			Extract:      reference.ExternalName(),
+			Reference:    mg.Spec.ForProvider.Source.(*S3Source).BucketNameRef,
+			Selector:     mg.Spec.ForProvider.Source.(*S3Source).BucketNameSelector,
+			To: reference.To{
+				List:    &BucketList{},
+				Managed: &Bucket{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Source.BucketName")
+		}
+		mg.Spec.ForProvider.Source.(*S3Source).BucketName = rsp.ResolvedValue
+		mg.Spec.ForProvider.Source.(*S3Source).BucketNameRef = rsp.ResolvedReference
+
+	}
+	switch mg.Spec.ForProvider.Source.(type) {
+	case *GCSSource:
+		mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+			CurrentValues: mg.Spec.ForProvider.Source.(*GCSSource).BucketNames,
+			Extract:       reference.ExternalName(),
+			References:    mg.Spec.ForProvider.Source.(*GCSSource).BucketNamesRefs,
+			Selector:      mg.Spec.ForProvider.Source.(*GCSSource).BucketNamesSelector,
+			To: reference.To{
+				List:    &BucketList{},
+				Managed: &Bucket{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Source.BucketNames")
+		}
+		mg.Spec.ForProvider.Source.(*GCSSource).BucketNames = mrsp.ResolvedValues
+		mg.Spec.ForProvider.Source.(*GCSSource).BucketNamesRefs = mrsp.ResolvedReferences
+
+	}
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesSpreadInto(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Endpoint struct {
+	Name *string
+
+	Port int
+}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Service
+	// +crossplane:generate:reference:spreadInto=Name
+	Endpoints []Endpoint
+
+	EndpointsRefs []Reference
+
+	EndpointsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	{
+		values := make([]string, len(mg.Spec.ForProvider.Endpoints))
+		for i := range mg.Spec.ForProvider.Endpoints {
+			values[i] = reference.FromPtrValue(mg.Spec.ForProvider.Endpoints[i].Name)
+		}
+		mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+			CurrentValues: values,
+			Extract:       reference.ExternalName(),
+			References:    mg.Spec.ForProvider.EndpointsRefs,
+			Selector:      mg.Spec.ForProvider.EndpointsSelector,
+			To: reference.To{
+				List:    &ServiceList{},
+				Managed: &Service{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Endpoints")
+		}
+		if n := len(mrsp.ResolvedValues) - len(mg.Spec.ForProvider.Endpoints); n > 0 {
+			mg.Spec.ForProvider.Endpoints = append(mg.Spec.ForProvider.Endpoints, make([]Endpoint, n)...)
+		}
+		for i, v := range mrsp.ResolvedValues {
+			mg.Spec.ForProvider.Endpoints[i].Name = reference.ToPtrValue(v)
+		}
+		mg.Spec.ForProvider.EndpointsRefs = mrsp.ResolvedReferences
+	}
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesOneOf(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+// +crossplane:generate:reference:oneOf
+type Target struct {
+	// +crossplane:generate:reference:type=Instance
+	InstanceID *string
+
+	InstanceIDRef *Reference
+
+	InstanceIDSelector *Selector
+
+	IPAddress *string
+
+	// +crossplane:generate:reference:type=Function
+	LambdaARN *string
+
+	LambdaARNRef *Reference
+
+	LambdaARNSelector *Selector
+}
+
+type ModelParameters struct {
+	Target *Target
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	if mg.Spec.ForProvider.Target != nil {
+		{
+			set := 0
+			if mg.Spec.ForProvider.Target.InstanceIDRef != nil || mg.Spec.ForProvider.Target.InstanceIDSelector != nil {
+				set++
+			}
+			if mg.Spec.ForProvider.Target.LambdaARNRef != nil || mg.Spec.ForProvider.Target.LambdaARNSelector != nil {
+				set++
+			}
+			if set > 1 {
+				return errors.New("mg.Spec.ForProvider.Target: cannot set the reference or selector of more than one of InstanceID, LambdaARN")
+			}
+		}
+		if mg.Spec.ForProvider.Target.IPAddress == nil && mg.Spec.ForProvider.Target.LambdaARN == nil {
+			rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+				CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Target.InstanceID),
+				Extract:      reference.ExternalName(),
+				Reference:    mg.Spec.ForProvider.Target.InstanceIDRef,
+				Selector:     mg.Spec.ForProvider.Target.InstanceIDSelector,
+				To: reference.To{
+					List:    &InstanceList{},
+					Managed: &Instance{},
+				},
+			})
+			if err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Target.InstanceID")
+			}
+			mg.Spec.ForProvider.Target.InstanceID = reference.ToPtrValue(rsp.ResolvedValue)
+			mg.Spec.ForProvider.Target.InstanceIDRef = rsp.ResolvedReference
+		}
+
+	}
+	if mg.Spec.ForProvider.Target != nil {
+		if mg.Spec.ForProvider.Target.InstanceID == nil && mg.Spec.ForProvider.Target.IPAddress == nil {
+			rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+				CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Target.LambdaARN),
+				Extract:      reference.ExternalName(),
+				Reference:    mg.Spec.ForProvider.Target.LambdaARNRef,
+				Selector:     mg.Spec.ForProvider.Target.LambdaARNSelector,
+				To: reference.To{
+					List:    &FunctionList{},
+					Managed: &Function{},
+				},
+			})
+			if err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Target.LambdaARN")
+			}
+			mg.Spec.ForProvider.Target.LambdaARN = reference.ToPtrValue(rsp.ResolvedValue)
+			mg.Spec.ForProvider.Target.LambdaARNRef = rsp.ResolvedReference
+		}
+
+	}
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesProtobuf(t *testing.T) {
+	// Protobuf generated structs have unexported internal state, oneof
+	// wrapper interfaces, recursive message types, and slices of pointers to
+	// messages.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type messageState struct {
+	atomicMessageInfo *messageInfo
+}
+
+type messageInfo struct {
+	Exporter func()
+	Desc     *messageInfo
+}
+
+type isTarget_Kind interface {
+	isTarget_Kind()
+}
+
+type Target_IPAddress struct {
+	IPAddress string
+}
+
+func (*Target_IPAddress) isTarget_Kind() {}
+
+type Target struct {
+	state         messageState
+	sizeCache     int32
+	unknownFields []byte
+
+	// +crossplane:generate:reference:type=Subnet
+	SubnetId *string
+
+	SubnetIdRef *Reference
+
+	SubnetIdSelector *Selector
+
+	Kind isTarget_Kind
+
+	Fallback *Target
+}
+
+type ModelParameters struct {
+	state messageState
+
+	Targets []*Target
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	for i3 := range mg.Spec.ForProvider.Targets {
+		e3 := mg.Spec.ForProvider.Targets[i3]
+		if e3 != nil {
+			rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+				CurrentValue: reference.FromPtrValue(e3.SubnetId),
+				Extract:      reference.ExternalName(),
+				Reference:    e3.SubnetIdRef,
+				Selector:     e3.SubnetIdSelector,
+				To: reference.To{
+					List:    &SubnetList{},
+					Managed: &Subnet{},
+				},
+			})
+			if err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Targets[*].SubnetId")
+			}
+			e3.SubnetId = reference.ToPtrValue(rsp.ResolvedValue)
+			e3.SubnetIdRef = rsp.ResolvedReference
+
+		}
+	}
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesValidation(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:validatePattern=^subnet-[0-9a-f]+$
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+
+	// +crossplane:generate:reference:type=Role
+	// +crossplane:generate:reference:validator=example.org/arn.Validate
+	RoleARN string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	arn "example.org/arn"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+	"regexp"
+)
+
+var validationPatternd0df3106 = regexp.MustCompile("^subnet-[0-9a-f]+$")
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.SubnetID),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.SubnetIDRef,
+		Selector:     mg.Spec.ForProvider.SubnetIDSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetID")
+	}
+	if !validationPatternd0df3106.MatchString(rsp.ResolvedValue) {
+		return errors.Errorf("mg.Spec.ForProvider.SubnetID: resolved value %q does not match %s", rsp.ResolvedValue, "^subnet-[0-9a-f]+$")
+	}
+	mg.Spec.ForProvider.SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.SubnetIDRef = rsp.ResolvedReference
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: mg.Spec.ForProvider.RoleARN,
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.RoleARNRef,
+		Selector:     mg.Spec.ForProvider.RoleARNSelector,
+		To: reference.To{
+			List:    &RoleList{},
+			Managed: &Role{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+	}
+	if err := arn.Validate(rsp.ResolvedValue); err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+	}
+	mg.Spec.ForProvider.RoleARN = rsp.ResolvedValue
+	mg.Spec.ForProvider.RoleARNRef = rsp.ResolvedReference
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesValidationCompiledOnce(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:validatePattern=^subnet-[0-9a-f]+$
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+
+type Other struct {
+	Spec ModelSpec
+}
+`
+	p := loadPackage(t, source)
+	f := jen.NewFilePath("golang.org/fake/v1alpha1")
+	fn := NewResolveReferences(xptypes.NewTraverser(comments.In(p)), "mg", "example.org/client", "example.org/reference")
+	fn(f, p.Types.Scope().Lookup("Model"))
+	fn(f, p.Types.Scope().Lookup("Other"))
+	got := fmt.Sprintf("%#v", f)
+	if n := strings.Count(got, "= regexp.MustCompile("); n != 1 {
+		t.Errorf("NewResolveReferences(...): a pattern shared by two managed resources of a file was compiled %d times, want once:\n%s", n, got)
+	}
+	if n := strings.Count(got, "validationPatternd0df3106.MatchString("); n != 2 {
+		t.Errorf("NewResolveReferences(...): the compiled pattern was used %d times, want twice:\n%s", n, got)
+	}
+}
+
+func TestNewResolveReferencesNormalize(t *testing.T) {
+	// The current values are normalized in the resolution requests, but are
+	// only replaced by resolved values that differ from them once normalized.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Bucket
+	// +crossplane:generate:reference:normalize=strings.ToLower
+	BucketName *string
+
+	BucketNameRef *Reference
+
+	BucketNameSelector *Selector
+
+	// +crossplane:generate:reference:type=Queue
+	// +crossplane:generate:reference:normalize=example.org/names.Normalize
+	QueueName string
+
+	QueueNameRef *Reference
+
+	QueueNameSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	names "example.org/names"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+	"strings"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: strings.ToLower(reference.FromPtrValue(mg.Spec.ForProvider.BucketName)),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.BucketNameRef,
+		Selector:     mg.Spec.ForProvider.BucketNameSelector,
+		To: reference.To{
+			List:    &BucketList{},
+			Managed: &Bucket{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.BucketName")
+	}
+	if rsp.ResolvedValue != strings.ToLower(reference.FromPtrValue(mg.Spec.ForProvider.BucketName)) {
+		mg.Spec.ForProvider.BucketName = reference.ToPtrValue(rsp.ResolvedValue)
+	}
+	mg.Spec.ForProvider.BucketNameRef = rsp.ResolvedReference
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: names.Normalize(mg.Spec.ForProvider.QueueName),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.QueueNameRef,
+		Selector:     mg.Spec.ForProvider.QueueNameSelector,
+		To: reference.To{
+			List:    &QueueList{},
+			Managed: &Queue{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.QueueName")
+	}
+	if rsp.ResolvedValue != names.Normalize(mg.Spec.ForProvider.QueueName) {
+		mg.Spec.ForProvider.QueueName = rsp.ResolvedValue
+	}
+	mg.Spec.ForProvider.QueueNameRef = rsp.ResolvedReference
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesTimeout(t *testing.T) {
+	// Only the resolution of fields with a timeout is done with a context
+	// that is cancelled once it elapses.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Bucket
+	// +crossplane:generate:reference:timeout=5s
+	BucketName *string
+
+	BucketNameRef *Reference
+
+	BucketNameSelector *Selector
+
+	// +crossplane:generate:reference:type=Queue
+	// +crossplane:generate:reference:timeout=1m30s
+	QueueNames []string
+
+	QueueNamesRefs []Reference
+
+	QueueNamesSelector *Selector
+
+	// +crossplane:generate:reference:type=Topic
+	TopicName string
+
+	TopicNameRef *Reference
+
+	TopicNameSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+	"time"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	{
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.BucketName),
+			Extract:      reference.ExternalName(),
+			Reference:    mg.Spec.ForProvider.BucketNameRef,
+			Selector:     mg.Spec.ForProvider.BucketNameSelector,
+			To: reference.To{
+				List:    &BucketList{},
+				Managed: &Bucket{},
+			},
+		})
+		cancel()
+	}
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.BucketName")
+	}
+	mg.Spec.ForProvider.BucketName = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.BucketNameRef = rsp.ResolvedReference
+
+	{
+		ctx, cancel := context.WithTimeout(ctx, 90*time.Second)
+		mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+			CurrentValues: mg.Spec.ForProvider.QueueNames,
+			Extract:       reference.ExternalName(),
+			References:    mg.Spec.ForProvider.QueueNamesRefs,
+			Selector:      mg.Spec.ForProvider.QueueNamesSelector,
+			To: reference.To{
+				List:    &QueueList{},
+				Managed: &Queue{},
+			},
+		})
+		cancel()
+	}
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.QueueNames")
+	}
+	mg.Spec.ForProvider.QueueNames = mrsp.ResolvedValues
+	mg.Spec.ForProvider.QueueNamesRefs = mrsp.ResolvedReferences
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: mg.Spec.ForProvider.TopicName,
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.TopicNameRef,
+		Selector:     mg.Spec.ForProvider.TopicNameSelector,
+		To: reference.To{
+			List:    &TopicList{},
+			Managed: &Topic{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.TopicName")
+	}
+	mg.Spec.ForProvider.TopicName = rsp.ResolvedValue
+	mg.Spec.ForProvider.TopicNameRef = rsp.ResolvedReference
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+
+	// Each request of the controller-runtime client times out on its own.
+	got := resolveReferences(t, source, WithControllerRuntime("example.org/meta"))
+	wantGet := `			{
+				ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+				err = c.Get(ctx, client.ObjectKey{Name: ref.Name}, to)
+				cancel()
+			}
+			if err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.BucketName")
+			}
+`
+	if !strings.Contains(got, wantGet) {
+		t.Errorf("NewResolveReferences(...): want the Get of BucketName to time out:\n%s", got)
+	}
+}
+
+func TestNewResolveReferencesFieldSelectors(t *testing.T) {
+	// Field selectors are passed to the resolution requests of the fields
+	// that have them.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Bucket
+	// +crossplane:generate:reference:fieldSelector=spec.forProvider.region=us-east-1
+	BucketName *string
+
+	BucketNameRef *Reference
+
+	BucketNameSelector *Selector
+
+	// +crossplane:generate:reference:type=Queue
+	// +crossplane:generate:reference:fieldSelector=metadata.namespace==default,status.phase!=Deleting
+	QueueNames []string
+
+	QueueNamesRefs []Reference
+
+	QueueNamesSelector *Selector
+
+	// +crossplane:generate:reference:type=Topic
+	TopicName string
+
+	TopicNameRef *Reference
+
+	TopicNameSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue:  reference.FromPtrValue(mg.Spec.ForProvider.BucketName),
+		Extract:       reference.ExternalName(),
+		FieldSelector: "spec.forProvider.region=us-east-1",
+		Reference:     mg.Spec.ForProvider.BucketNameRef,
+		Selector:      mg.Spec.ForProvider.BucketNameSelector,
+		To: reference.To{
+			List:    &BucketList{},
+			Managed: &Bucket{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.BucketName")
+	}
+	mg.Spec.ForProvider.BucketName = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.BucketNameRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.QueueNames,
+		Extract:       reference.ExternalName(),
+		FieldSelector: "metadata.namespace==default,status.phase!=Deleting",
+		References:    mg.Spec.ForProvider.QueueNamesRefs,
+		Selector:      mg.Spec.ForProvider.QueueNamesSelector,
+		To: reference.To{
+			List:    &QueueList{},
+			Managed: &Queue{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.QueueNames")
+	}
+	mg.Spec.ForProvider.QueueNames = mrsp.ResolvedValues
+	mg.Spec.ForProvider.QueueNamesRefs = mrsp.ResolvedReferences
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: mg.Spec.ForProvider.TopicName,
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.TopicNameRef,
+		Selector:     mg.Spec.ForProvider.TopicNameSelector,
+		To: reference.To{
+			List:    &TopicList{},
+			Managed: &Topic{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.TopicName")
+	}
+	mg.Spec.ForProvider.TopicName = rsp.ResolvedValue
+	mg.Spec.ForProvider.TopicNameRef = rsp.ResolvedReference
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+
+	t.Run("SelectorsDisabled", func(t *testing.T) {
+		defer func() {
+			want := "Spec.ForProvider.BucketName of Model has a field selector, but selectors are disabled"
+			if diff := cmp.Diff(want, fmt.Sprint(recover())); diff != "" {
+				t.Errorf("NewResolveReferences(...): -want panic, +got panic\n%s", diff)
+			}
+		}()
+		resolveReferences(t, source, WithSelectorsDisabled(func(_ types.Object) bool { return true }))
+	})
+
+	t.Run("ControllerRuntime", func(t *testing.T) {
+		defer func() {
+			want := "Spec.ForProvider.BucketName of Model cannot be resolved using the controller-runtime client: field selectors are not supported"
+			if diff := cmp.Diff(want, fmt.Sprint(recover())); diff != "" {
+				t.Errorf("NewResolveReferences(...): -want panic, +got panic\n%s", diff)
+			}
+		}()
+		resolveReferences(t, source, WithControllerRuntime("example.org/meta"))
+	})
+}
+
+func TestNewResolveReferencesOnlyMultiple(t *testing.T) {
+	// A resolver of only slices declares the response of multiple resolutions
+	// once, in place of the response of single resolutions that it doesn't
+	// use, and without a blank line before it.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetIDs []string
+
+	SubnetIDsRefs []Reference
+
+	SubnetIDsSelector *Selector
+
+	// +crossplane:generate:reference:type=SecurityGroup
+	SecurityGroupIDs []string
+
+	SecurityGroupIDsRefs []Reference
+
+	SecurityGroupIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SubnetIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SubnetIDsRefs,
+		Selector:      mg.Spec.ForProvider.SubnetIDsSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+	}
+	mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SubnetIDsRefs = mrsp.ResolvedReferences
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SecurityGroupIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SecurityGroupIDsRefs,
+		Selector:      mg.Spec.ForProvider.SecurityGroupIDsSelector,
+		To: reference.To{
+			List:    &SecurityGroupList{},
+			Managed: &SecurityGroup{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SecurityGroupIDs")
+	}
+	mg.Spec.ForProvider.SecurityGroupIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SecurityGroupIDsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+
+	t.Run("WithLocals", func(t *testing.T) {
+		got := resolveReferences(t, source, WithResolvedValues(), WithDependencyAnnotation("example.org/dependencies"))
+		want := "\tvar mrsp reference.MultiResolutionResponse\n\tvar dependencies []map[string]string\n\tresolved := map[string]string{}\n\tvar err error\n"
+		if !strings.Contains(got, want) {
+			t.Errorf("NewResolveReferences(...): locals should be declared once each, one per line:\n%s", got)
+		}
+		if strings.Count(got, "var mrsp ") != 1 || strings.Contains(got, "var rsp ") {
+			t.Errorf("NewResolveReferences(...): only the response of multiple resolutions should be declared:\n%s", got)
+		}
+	})
+}
+
+func TestNewResolveReferencesTransactional(t *testing.T) {
+	// A transactional resolver resolves references in a copy of the managed
+	// resource, which replaces it only if every reference is resolved, and
+	// sets the failure condition on the managed resource rather than the
+	// copy.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=VPC
+	VPCID *string
+
+	VPCIDRef *Reference
+
+	VPCIDSelector *Selector
+
+	// +crossplane:generate:reference:type=Subnet
+	SubnetIDs []string
+
+	SubnetIDsRefs []Reference
+
+	SubnetIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+
+func (in *Model) DeepCopy() *Model {
+	out := *in
+	return &out
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	runtime "example.org/runtime"
+	errors "github.com/pkg/errors"
+	v11 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	// References are resolved in a copy, which replaces the original only
+	// if every reference is resolved.
+	original := mg
+	mg = mg.DeepCopy()
+
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.VPCID),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.VPCIDRef,
+		Selector:     mg.Spec.ForProvider.VPCIDSelector,
+		To: reference.To{
+			List:    &VPCList{},
+			Managed: &VPC{},
+		},
+	})
+	if err != nil {
+		err = errors.Wrap(err, "mg.Spec.ForProvider.VPCID")
+		original.SetConditions(runtime.Condition{
+			LastTransitionTime: v1.Now(),
+			Message:            err.Error(),
+			Reason:             "ReferenceResolutionFailed",
+			Status:             v11.ConditionFalse,
+			Type:               "ReferencesResolved",
+		})
+		return err
+	}
+	mg.Spec.ForProvider.VPCID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.VPCIDRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SubnetIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SubnetIDsRefs,
+		Selector:      mg.Spec.ForProvider.SubnetIDsSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		err = errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+		original.SetConditions(runtime.Condition{
+			LastTransitionTime: v1.Now(),
+			Message:            err.Error(),
+			Reason:             "ReferenceResolutionFailed",
+			Status:             v11.ConditionFalse,
+			Type:               "ReferencesResolved",
+		})
+		return err
+	}
+	mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SubnetIDsRefs = mrsp.ResolvedReferences
+
+	*original = *mg
+	return nil
+}
+`
+	got := resolveReferences(t, source, WithTransactional(), WithRuntime("example.org/runtime"), WithFailureCondition("ReferencesResolved", "ReferenceResolutionFailed"))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nNewResolveReferences(...): -want, +got:\n%s", diff)
+	}
+
+	t.Run("NoDeepCopy", func(t *testing.T) {
+		defer func() {
+			want := "references of Model cannot be resolved transactionally, because it has no DeepCopy method"
+			if diff := cmp.Diff(want, fmt.Sprint(recover())); diff != "" {
+				t.Errorf("\nA managed resource without a DeepCopy method should cause a panic.\nNewResolveReferences(...): -want, +got:\n%s", diff)
+			}
+		}()
+		resolveReferences(t, strings.Replace(source, "DeepCopy", "Copy", 1), WithTransactional())
+	})
+}
+
+func TestNewResolveReferencesRetry(t *testing.T) {
+	// Each resolution is wrapped in OnError of the retry package, so that it
+	// is retried while it fails, and the error is only wrapped with the path
+	// of its field once retries are exhausted.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=VPC
+	VPCID *string
+
+	VPCIDRef *Reference
+
+	VPCIDSelector *Selector
+
+	// +crossplane:generate:reference:type=Subnet
+	SubnetIDs []string
+
+	SubnetIDsRefs []Reference
+
+	SubnetIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	retry "example.org/retry"
+	errors "github.com/pkg/errors"
+	"time"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	backoff := retry.Backoff{
+		Duration: 100 * time.Millisecond,
+		Factor:   2.0,
+		Steps:    5,
+	}
+	var err error
+
+	err = retry.OnError(ctx, backoff, func() error {
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.VPCID),
+			Extract:      reference.ExternalName(),
+			Reference:    mg.Spec.ForProvider.VPCIDRef,
+			Selector:     mg.Spec.ForProvider.VPCIDSelector,
+			To: reference.To{
+				List:    &VPCList{},
+				Managed: &VPC{},
+			},
+		})
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.VPCID")
+	}
+	mg.Spec.ForProvider.VPCID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.VPCIDRef = rsp.ResolvedReference
+
+	err = retry.OnError(ctx, backoff, func() error {
+		mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+			CurrentValues: mg.Spec.ForProvider.SubnetIDs,
+			Extract:       reference.ExternalName(),
+			References:    mg.Spec.ForProvider.SubnetIDsRefs,
+			Selector:      mg.Spec.ForProvider.SubnetIDsSelector,
+			To: reference.To{
+				List:    &SubnetList{},
+				Managed: &Subnet{},
+			},
+		})
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+	}
+	mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SubnetIDsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`
+	got := resolveReferences(t, source, WithRetry("example.org/retry", &Backoff{Duration: 100 * time.Millisecond, Factor: 2, Steps: 5}))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nNewResolveReferences(...): -want, +got:\n%s", diff)
+	}
+
+	t.Run("DefaultBackoff", func(t *testing.T) {
+		got := resolveReferences(t, source, WithRetry("example.org/retry", nil))
+		if n := strings.Count(got, "err = retry.OnError(ctx, retry.DefaultBackoff, func() error {"); n != 2 {
+			t.Errorf("\nWithout a backoff, each resolution should be retried with the DefaultBackoff of the retry package.\nNewResolveReferences(...): %d resolutions retried with DefaultBackoff, want 2:\n%s", n, got)
+		}
+		if strings.Contains(got, "backoff :=") {
+			t.Errorf("\nWithout a backoff, no backoff should be declared.\nNewResolveReferences(...):\n%s", got)
+		}
+	})
+
+	t.Run("ControllerRuntime", func(t *testing.T) {
+		defer func() {
+			want := "resolvers of Model that use the controller-runtime client cannot retry resolutions"
+			if diff := cmp.Diff(want, fmt.Sprint(recover())); diff != "" {
+				t.Errorf("\nRetrying resolutions that use the controller-runtime client should cause a panic.\nNewResolveReferences(...): -want, +got:\n%s", diff)
+			}
+		}()
+		resolveReferences(t, source, WithRetry("example.org/retry", nil), WithControllerRuntime("example.org/meta"))
+	})
+}
+
+func TestNewResolveReferencesExtractorArgs(t *testing.T) {
+	// The extractor of a field with an extractor argument is called by a
+	// closure that passes it the value of the sibling field, read from the
+	// same element of a slice as the field it extracts the value of.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Rule struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:extractor=example.org/v1.RegionalARN()
+	// +crossplane:generate:reference:extractorArg=Region
+	SubnetID string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+
+	Region *string
+}
+
+type ModelParameters struct {
+	Region string
+
+	// +crossplane:generate:reference:type=VPC
+	// +crossplane:generate:reference:extractor=example.org/v1.RegionalARN()
+	// +crossplane:generate:reference:extractorArg=Region
+	VPCID *string
+
+	VPCIDRef *Reference
+
+	VPCIDSelector *Selector
+
+	// +crossplane:generate:reference:type=SecurityGroup
+	// +crossplane:generate:reference:extractor=example.org/v1.RegionalARNs()
+	// +crossplane:generate:reference:extractorArg=Region
+	SecurityGroupIDs []string
+
+	SecurityGroupIDsRefs []Reference
+
+	SecurityGroupIDsSelector *Selector
+
+	Rules []Rule
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	resource "example.org/resource"
+	v1 "example.org/v1"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.VPCID),
+		Extract: func(o resource.Managed) string {
+			return v1.RegionalARN()(o, mg.Spec.ForProvider.Region)
+		},
+		Reference: mg.Spec.ForProvider.VPCIDRef,
+		Selector:  mg.Spec.ForProvider.VPCIDSelector,
+		To: reference.To{
+			List:    &VPCList{},
+			Managed: &VPC{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.VPCID")
+	}
+	mg.Spec.ForProvider.VPCID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.VPCIDRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SecurityGroupIDs,
+		Extract: func(o resource.Managed) string {
+			return v1.RegionalARNs()(o, mg.Spec.ForProvider.Region)
+		},
+		References: mg.Spec.ForProvider.SecurityGroupIDsRefs,
+		Selector:   mg.Spec.ForProvider.SecurityGroupIDsSelector,
+		To: reference.To{
+			List:    &SecurityGroupList{},
+			Managed: &SecurityGroup{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SecurityGroupIDs")
+	}
+	mg.Spec.ForProvider.SecurityGroupIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SecurityGroupIDsRefs = mrsp.ResolvedReferences
+
+	for i3 := range mg.Spec.ForProvider.Rules {
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: mg.Spec.ForProvider.Rules[i3].SubnetID,
+			Extract: func(o resource.Managed) string {
+				return v1.RegionalARN()(o, mg.Spec.ForProvider.Rules[i3].Region)
+			},
+			Reference: mg.Spec.ForProvider.Rules[i3].SubnetIDRef,
+			Selector:  mg.Spec.ForProvider.Rules[i3].SubnetIDSelector,
+			To: reference.To{
+				List:    &SubnetList{},
+				Managed: &Subnet{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Rules[*].SubnetID")
+		}
+		mg.Spec.ForProvider.Rules[i3].SubnetID = rsp.ResolvedValue
+		mg.Spec.ForProvider.Rules[i3].SubnetIDRef = rsp.ResolvedReference
+
+	}
+
+	return nil
+}
+`
+	got := resolveReferences(t, source, WithResource("example.org/resource"))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nNewResolveReferences(...): -want, +got:\n%s", diff)
+	}
+
+	t.Run("NoResourcePackage", func(t *testing.T) {
+		defer func() {
+			want := "Spec.ForProvider.VPCID of Model passes an argument to its extractor, but no resource package is configured"
+			if diff := cmp.Diff(want, fmt.Sprint(recover())); diff != "" {
+				t.Errorf("\nAn extractor argument without a resource package should cause a panic.\nNewResolveReferences(...): -want, +got:\n%s", diff)
+			}
+		}()
+		resolveReferences(t, source)
+	})
+}
+
+func TestNewResolveReferencesReferenceError(t *testing.T) {
+	// Errors are returned as a ReferenceError of the path of the field and the
+	// kind it references, which is declared once for the package.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=VPC
+	VPCID *string
+
+	VPCIDRef *Reference
+
+	VPCIDSelector *Selector
+
+	// +crossplane:generate:reference:type=example.org/network/v1.Subnet
+	SubnetIDs []string
+
+	SubnetIDsRefs []Reference
+
+	SubnetIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	v1 "example.org/network/v1"
+	reference "example.org/reference"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.VPCID),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.VPCIDRef,
+		Selector:     mg.Spec.ForProvider.VPCIDSelector,
+		To: reference.To{
+			List:    &VPCList{},
+			Managed: &VPC{},
+		},
+	})
+	if err != nil {
+		return &ReferenceError{
+			Err:        err,
+			FieldPath:  "mg.Spec.ForProvider.VPCID",
+			TargetKind: "VPC",
+		}
+	}
+	mg.Spec.ForProvider.VPCID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.VPCIDRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SubnetIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SubnetIDsRefs,
+		Selector:      mg.Spec.ForProvider.SubnetIDsSelector,
+		To: reference.To{
+			List:    &v1.SubnetList{},
+			Managed: &v1.Subnet{},
+		},
+	})
+	if err != nil {
+		return &ReferenceError{
+			Err:        err,
+			FieldPath:  "mg.Spec.ForProvider.SubnetIDs",
+			TargetKind: "Subnet",
+		}
+	}
+	mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SubnetIDsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`
+	got := resolveReferences(t, source, WithReferenceError())
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nNewResolveReferences(...): -want, +got:\n%s", diff)
+	}
+
+	t.Run("ErrorWrapper", func(t *testing.T) {
+		got := resolveReferences(t, source, WithReferenceError(), WithErrorWrapper("example.org/errors.Reference"))
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("\nA ReferenceError should take precedence over an error wrapper.\nNewResolveReferences(...): -want, +got:\n%s", diff)
+		}
+	})
+}
+
+func TestNewResolveReferencesMarkersOnOneLine(t *testing.T) {
+	// Markers written on one line should generate the same resolver as
+	// markers written on a line each.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+%s
+	VPCID *string
+
+	VPCIDRef *Reference
+
+	VPCIDSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	lines := "\t// +crossplane:generate:reference:type=VPC\n\t// +crossplane:generate:reference:extractor=example.org/v1.ARN()\n\t// +crossplane:generate:reference:refFieldName=VPCIDRef"
+	line := "\t// +crossplane:generate:reference:type=VPC +crossplane:generate:reference:extractor=example.org/v1.ARN() +crossplane:generate:reference:refFieldName=VPCIDRef"
+
+	want := resolveReferences(t, fmt.Sprintf(source, lines))
+	got := resolveReferences(t, fmt.Sprintf(source, line))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nNewResolveReferences(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesImmutable(t *testing.T) {
+	// Immutable fields are only resolved while the managed resource has no
+	// external name, or while they are empty.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=VPC
+	// +crossplane:generate:reference:immutable
+	VPCID *string
+
+	VPCIDRef *Reference
+
+	VPCIDSelector *Selector
+
+	// +crossplane:generate:reference:type=Zone
+	// +crossplane:generate:reference:immutable
+	ZoneName string
+
+	ZoneNameRef *Reference
+
+	ZoneNameSelector *Selector
+
+	// +crossplane:generate:reference:type=SecurityGroup
+	// +crossplane:generate:reference:immutable
+	SecurityGroupIDs []string
+
+	SecurityGroupIDsRefs []Reference
+
+	SecurityGroupIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	meta "example.org/meta"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	if meta.GetExternalName(mg) == "" || mg.Spec.ForProvider.VPCID == nil || *mg.Spec.ForProvider.VPCID == "" {
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.VPCID),
+			Extract:      reference.ExternalName(),
+			Reference:    mg.Spec.ForProvider.VPCIDRef,
+			Selector:     mg.Spec.ForProvider.VPCIDSelector,
+			To: reference.To{
+				List:    &VPCList{},
+				Managed: &VPC{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.VPCID")
+		}
+		mg.Spec.ForProvider.VPCID = reference.ToPtrValue(rsp.ResolvedValue)
+		mg.Spec.ForProvider.VPCIDRef = rsp.ResolvedReference
+	}
+
+	if meta.GetExternalName(mg) == "" || mg.Spec.ForProvider.ZoneName == "" {
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: mg.Spec.ForProvider.ZoneName,
+			Extract:      reference.ExternalName(),
+			Reference:    mg.Spec.ForProvider.ZoneNameRef,
+			Selector:     mg.Spec.ForProvider.ZoneNameSelector,
+			To: reference.To{
+				List:    &ZoneList{},
+				Managed: &Zone{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.ZoneName")
+		}
+		mg.Spec.ForProvider.ZoneName = rsp.ResolvedValue
+		mg.Spec.ForProvider.ZoneNameRef = rsp.ResolvedReference
+	}
+
+	if meta.GetExternalName(mg) == "" || len(mg.Spec.ForProvider.SecurityGroupIDs) == 0 {
+		mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+			CurrentValues: mg.Spec.ForProvider.SecurityGroupIDs,
+			Extract:       reference.ExternalName(),
+			References:    mg.Spec.ForProvider.SecurityGroupIDsRefs,
+			Selector:      mg.Spec.ForProvider.SecurityGroupIDsSelector,
+			To: reference.To{
+				List:    &SecurityGroupList{},
+				Managed: &SecurityGroup{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.SecurityGroupIDs")
+		}
+		mg.Spec.ForProvider.SecurityGroupIDs = mrsp.ResolvedValues
+		mg.Spec.ForProvider.SecurityGroupIDsRefs = mrsp.ResolvedReferences
+	}
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithMeta("example.org/meta"))); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+
+	t.Run("NoMeta", func(t *testing.T) {
+		defer func() {
+			want := "Spec.ForProvider.VPCID of Model is immutable, but no meta package is configured"
+			if diff := cmp.Diff(want, fmt.Sprint(recover())); diff != "" {
+				t.Errorf("NewResolveReferences(...): -want panic, +got panic\n%s", diff)
+			}
+		}()
+		resolveReferences(t, source)
+	})
+}
+
+func TestNewResolveReferencesTopLevelFields(t *testing.T) {
+	// Traversal starts at the resource type itself rather than at a field
+	// named Spec, so references are found wherever the resource keeps them.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+
+type ModelObservation struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+
+type Model struct {
+	ForProvider ModelParameters
+
+	AtProvider ModelObservation
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.ForProvider.SubnetID),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.ForProvider.SubnetIDRef,
+		Selector:     mg.ForProvider.SubnetIDSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.ForProvider.SubnetID")
+	}
+	mg.ForProvider.SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.ForProvider.SubnetIDRef = rsp.ResolvedReference
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.AtProvider.SubnetID),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.AtProvider.SubnetIDRef,
+		Selector:     mg.AtProvider.SubnetIDSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.AtProvider.SubnetID")
+	}
+	mg.AtProvider.SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.AtProvider.SubnetIDRef = rsp.ResolvedReference
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesFailureCondition(t *testing.T) {
+	// The failure condition is set with the wrapped error as its message
+	// before any error is returned.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+
+	// +crossplane:generate:reference:type=SecurityGroup
+	SecurityGroupIDs []string
+
+	SecurityGroupIDsRefs []Reference
+
+	SecurityGroupIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	runtime "example.org/runtime"
+	"fmt"
+	errors "github.com/pkg/errors"
+	v11 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResolveReferencesWithValues of this Model. It returns resolved values by field path.
+func (mg *Model) ResolveReferencesWithValues(ctx context.Context, c client.Reader) (map[string]string, error) {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	resolved := map[string]string{}
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.SubnetID),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.SubnetIDRef,
+		Selector:     mg.Spec.ForProvider.SubnetIDSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		err = errors.Wrap(err, "mg.Spec.ForProvider.SubnetID")
+		mg.SetConditions(runtime.Condition{
+			LastTransitionTime: v1.Now(),
+			Message:            err.Error(),
+			Reason:             "ReferenceResolutionFailed",
+			Status:             v11.ConditionFalse,
+			Type:               "ReferencesResolved",
+		})
+		return nil, err
+	}
+	mg.Spec.ForProvider.SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+	resolved["Spec.ForProvider.SubnetID"] = rsp.ResolvedValue
+	mg.Spec.ForProvider.SubnetIDRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SecurityGroupIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SecurityGroupIDsRefs,
+		Selector:      mg.Spec.ForProvider.SecurityGroupIDsSelector,
+		To: reference.To{
+			List:    &SecurityGroupList{},
+			Managed: &SecurityGroup{},
+		},
+	})
+	if err != nil {
+		err = errors.Wrap(err, "mg.Spec.ForProvider.SecurityGroupIDs")
+		mg.SetConditions(runtime.Condition{
+			LastTransitionTime: v1.Now(),
+			Message:            err.Error(),
+			Reason:             "ReferenceResolutionFailed",
+			Status:             v11.ConditionFalse,
+			Type:               "ReferencesResolved",
+		})
+		return nil, err
+	}
+	mg.Spec.ForProvider.SecurityGroupIDs = mrsp.ResolvedValues
+	for i, v := range mrsp.ResolvedValues {
+		resolved[fmt.Sprintf("Spec.ForProvider.SecurityGroupIDs[%d]", i)] = v
+	}
+	mg.Spec.ForProvider.SecurityGroupIDsRefs = mrsp.ResolvedReferences
+
+	return resolved, nil
+}
+`
+	got := resolveReferences(t, source, WithRuntime("example.org/runtime"), WithFailureCondition("ReferencesResolved", "ReferenceResolutionFailed"), WithResolvedValues())
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+
+	t.Run("NoRuntime", func(t *testing.T) {
+		defer func() {
+			want := "resolvers of Model set a failure condition, but no runtime package is configured"
+			if diff := cmp.Diff(want, fmt.Sprint(recover())); diff != "" {
+				t.Errorf("NewResolveReferences(...): -want panic, +got panic\n%s", diff)
+			}
+		}()
+		resolveReferences(t, source, WithFailureCondition("ReferencesResolved", "ReferenceResolutionFailed"))
+	})
+}
+
+func TestNewResolveReferencesClock(t *testing.T) {
+	// Timeouts and failure conditions tell the time using the clock, so that
+	// tests may replace it.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Bucket
+	// +crossplane:generate:reference:timeout=5s
+	BucketName *string
+
+	BucketNameRef *Reference
+
+	BucketNameSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	clock "example.org/clock"
+	reference "example.org/reference"
+	runtime "example.org/runtime"
+	errors "github.com/pkg/errors"
+	v11 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	{
+		ctx, cancel := context.WithDeadline(ctx, clock.Clock.Now().Add(5*time.Second))
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.BucketName),
+			Extract:      reference.ExternalName(),
+			Reference:    mg.Spec.ForProvider.BucketNameRef,
+			Selector:     mg.Spec.ForProvider.BucketNameSelector,
+			To: reference.To{
+				List:    &BucketList{},
+				Managed: &Bucket{},
+			},
+		})
+		cancel()
+	}
+	if err != nil {
+		err = errors.Wrap(err, "mg.Spec.ForProvider.BucketName")
+		mg.SetConditions(runtime.Condition{
+			LastTransitionTime: v1.NewTime(clock.Clock.Now()),
+			Message:            err.Error(),
+			Reason:             "ReferenceResolutionFailed",
+			Status:             v11.ConditionFalse,
+			Type:               "ReferencesResolved",
+		})
+		return err
+	}
+	mg.Spec.ForProvider.BucketName = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.BucketNameRef = rsp.ResolvedReference
+
+	return nil
+}
+`
+	got := resolveReferences(t, source, WithRuntime("example.org/runtime"), WithFailureCondition("ReferencesResolved", "ReferenceResolutionFailed"), WithClock("example.org/clock.Clock"))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestReferenceProcessorValidation(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		source string
+		want   string
+	}{
+		"InvalidPattern": {
+			reason: "A pattern that is not a valid regular expression should return an error.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:validatePattern=^subnet-[0-9a-f+$
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+`,
+			want: "cannot get validation of field SubnetID: invalid pattern",
+		},
+		"ImmutableSpread": {
+			reason: "Resolved values that are spread into a slice should not be immutable.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Rule struct {
+	SubnetID *string
+}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:spreadInto=SubnetID
+	// +crossplane:generate:reference:immutable
+	Rules []Rule
+
+	RulesRefs []Reference
+
+	RulesSelector *Selector
+}
+`,
+			want: "field Rules cannot both use crossplane:generate:reference:immutable and crossplane:generate:reference:spreadInto",
+		},
+		"InvalidTimeout": {
+			reason: "A timeout that is not a duration should return an error.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:timeout=5
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+`,
+			want: "cannot get timeout of field SubnetID: invalid timeout \"5\"",
+		},
+		"NegativeTimeout": {
+			reason: "A timeout that is not positive should return an error.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:timeout=-5s
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+`,
+			want: "cannot get timeout of field SubnetID: timeout \"-5s\" must be positive",
+		},
+		"SliceField": {
+			reason: "Resolved values of slice fields should not be validated.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:validatePattern=^subnet-
+	SubnetIDs []string
+
+	SubnetIDsRefs []Reference
+
+	SubnetIDsSelector *Selector
+}
+`,
+			want: "resolved values of slice fields cannot be validated",
+		},
+		"PatternAndValidator": {
+			reason: "Only one of a pattern and a validator should be allowed.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:validatePattern=^subnet-
+	// +crossplane:generate:reference:validator=example.org/subnet.Validate
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+`,
+			want: "only one of crossplane:generate:reference:validatePattern and crossplane:generate:reference:validator may be specified",
+		},
+		"NormalizedSliceField": {
+			reason: "Current values of slice fields should not be normalized.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:normalize=strings.ToLower
+	SubnetIDs []string
+
+	SubnetIDsRefs []Reference
+
+	SubnetIDsSelector *Selector
+}
+`,
+			want: "cannot get normalizer of field SubnetIDs: current values of slice fields cannot be normalized",
+		},
+		"EmptyCondition": {
+			reason: "A condition should be a function.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:when=
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+`,
+			want: "condition of field SubnetID must be a function",
+		},
+		"PavedNotAMap": {
+			reason: "Only free-form maps should be paved.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:paved=vpcId=VPC
+	Parameters map[string]string
+}
+`,
+			want: "paved fields must be of type map[string]interface{}",
+		},
+		"PavedKeyWithoutType": {
+			reason: "Each paved key should name the type it references.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:paved=vpcId=VPC;subnetId
+	Parameters map[string]interface{}
+}
+`,
+			want: `paved key "subnetId" must be of the form <key>=<referenced type>`,
+		},
+		"PavedAndTyped": {
+			reason: "A paved field should not also reference a type itself.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:paved=vpcId=VPC
+	// +crossplane:generate:reference:type=VPC
+	Parameters map[string]interface{}
+}
+`,
+			want: "cannot both be paved and use crossplane:generate:reference:type",
+		},
+		"PavedAndPavedPaths": {
+			reason: "A paved field should list either keys or paths.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:paved=vpcId=VPC
+	// +crossplane:generate:reference:pavedPaths=network.subnetId=Subnet
+	Parameters map[string]interface{}
+}
+`,
+			want: "cannot both use crossplane:generate:reference:paved and crossplane:generate:reference:pavedPaths",
+		},
+		"PavedPathNotParsed": {
+			reason: "Each paved path should be a field path.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:pavedPaths=network..vpcId=VPC
+	Parameters map[string]interface{}
+}
+`,
+			want: `cannot parse paved path: empty field at position 8 of field path "network..vpcId"`,
+		},
+		"PavedPathEndsInIndex": {
+			reason: "Each paved path should end in a field that can be suffixed to find its reference and selector.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:pavedPaths=subnetIds[0]=Subnet
+	Parameters map[string]interface{}
+}
+`,
+			want: "paved path subnetIds[0] must end in a field",
+		},
+		"PavedPathWildcard": {
+			reason: "A paved path should select a single value.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:pavedPaths=subnets[*].id=Subnet
+	Parameters map[string]interface{}
+}
+`,
+			want: "paved path subnets[*].id must not select every element of a slice",
+		},
+		"PavedPathListedTwice": {
+			reason: "A paved path should not be listed twice, however it is written.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:pavedPaths=network.vpcId=VPC;[network].vpcId=VPC
+	Parameters map[string]interface{}
+}
+`,
+			want: "paved key network.vpcId is listed more than once",
+		},
+		"MirrorOfSlice": {
+			reason: "Only fields resolved to a single value should be mirrored.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:alsoSet=Tags[subnets]
+	SubnetIDs []string
+
+	SubnetIDsRefs     []Reference
+	SubnetIDsSelector *Selector
+
+	Tags map[string]string
+}
+`,
+			want: "cannot get mirrors of field SubnetIDs: only fields that are resolved to a single value can be mirrored",
+		},
+		"MirrorOfConflictingType": {
+			reason: "A mirror should be of the type of the resolved field.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:alsoSet=Tags[cluster]
+	ClusterName *string
+
+	ClusterNameRef      *Reference
+	ClusterNameSelector *Selector
+
+	Tags map[string]int
+}
+`,
+			want: "cannot get mirrors of field ClusterName: mirror Tags[cluster] is of type int, but field ClusterName is resolved to a value of type string",
+		},
+		"MirrorKeyOfStruct": {
+			reason: "Only maps with string keys should have keys mirrored to.",
+			source: `
+package v1alpha1
+
+type Tags struct {
+	Cluster string
+}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:alsoSet=Tags[cluster]
+	ClusterName string
+
+	ClusterNameRef      *Reference
+	ClusterNameSelector *Selector
+
+	Tags Tags
+}
+`,
+			want: "cannot get mirrors of field ClusterName: field Tags of ModelParameters must be a map with string keys to set its key cluster",
+		},
+		"MirrorMissing": {
+			reason: "A mirror should be a field of the struct.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:alsoSet=ClusterID
+	ClusterName string
+
+	ClusterNameRef      *Reference
+	ClusterNameSelector *Selector
+}
+`,
+			want: "cannot get mirrors of field ClusterName: ModelParameters has no ClusterID field",
+		},
+		"MirrorWithoutKey": {
+			reason: "A mirrored key should not be empty.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:alsoSet=Tags[]
+	ClusterName string
+
+	ClusterNameRef      *Reference
+	ClusterNameSelector *Selector
+
+	Tags map[string]string
+}
+`,
+			want: `cannot get mirrors of field ClusterName: mirror "Tags[]" must be the path of a field, optionally followed by [<key>]`,
+		},
+		"FieldSelectorWithoutOperator": {
+			reason: "Each requirement of a field selector should compare a field to a value.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:fieldSelector=spec.forProvider.region
+	ClusterName string
+
+	ClusterNameRef      *Reference
+	ClusterNameSelector *Selector
+}
+`,
+			want: `cannot get field selector of field ClusterName: field selector "spec.forProvider.region" must be a comma separated list of <field>=<value>, <field>==<value>, or <field>!=<value> requirements`,
+		},
+		"FieldSelectorEmptyRequirement": {
+			reason: "A field selector should not have empty requirements.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:fieldSelector=metadata.name=a,,status.phase=Ready
+	ClusterName string
+
+	ClusterNameRef      *Reference
+	ClusterNameSelector *Selector
+}
+`,
+			want: `cannot get field selector of field ClusterName: field selector "metadata.name=a,,status.phase=Ready" must be a comma separated list`,
+		},
+		"PavedFieldSelectorWithoutField": {
+			reason: "Each requirement of the field selector of a paved field should name a field.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:paved=vpcId=VPC
+	// +crossplane:generate:reference:fieldSelector==us-east-1
+	Parameters map[string]interface{}
+}
+`,
+			want: `cannot get field selector of field Parameters: field selector "=us-east-1" must be a comma separated list`,
+		},
+		"ExtractorArgMissing": {
+			reason: "The extractor argument should be a field of the struct.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:extractor=example.org/v1.RegionalARN()
+	// +crossplane:generate:reference:extractorArg=Region
+	ClusterName string
+
+	ClusterNameRef      *Reference
+	ClusterNameSelector *Selector
+}
+`,
+			want: "cannot get extractor argument of field ClusterName: ModelParameters has no Region field",
+		},
+		"ExtractorArgPath": {
+			reason: "The extractor argument should be a sibling of the field, rather than the path of a field.",
+			source: `
+package v1alpha1
+
+type Location struct {
+	Region string
+}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:extractor=example.org/v1.RegionalARN()
+	// +crossplane:generate:reference:extractorArg=Location.Region
+	ClusterName string
+
+	ClusterNameRef      *Reference
+	ClusterNameSelector *Selector
+
+	Location Location
+}
+`,
+			want: `cannot get extractor argument of field ClusterName: extractor argument "Location.Region" must be the name of a field of ModelParameters`,
+		},
+		"ExtractorArgItself": {
+			reason: "The extractor should not be passed the field it extracts the value of.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:extractor=example.org/v1.RegionalARN()
+	// +crossplane:generate:reference:extractorArg=ClusterName
+	ClusterName string
+
+	ClusterNameRef      *Reference
+	ClusterNameSelector *Selector
+}
+`,
+			want: "cannot get extractor argument of field ClusterName: the extractor cannot be passed the field that it extracts the value of",
+		},
+		"ExtractorArgWithoutExtractor": {
+			reason: "A field with an extractor argument should specify the extractor it is passed to.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:extractorArg=Region
+	ClusterName string
+
+	ClusterNameRef      *Reference
+	ClusterNameSelector *Selector
+
+	Region string
+}
+`,
+			want: "cannot get extractor argument of field ClusterName: an extractor must be specified using the crossplane:generate:reference:extractor marker or the extractor tag to pass it an argument",
+		},
+		"ExtractorArgFromAnnotation": {
+			reason: "A value extracted from an annotation should not have an extractor to pass an argument to.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:fromAnnotation=example.org/arn
+	// +crossplane:generate:reference:extractor=example.org/v1.RegionalARN()
+	// +crossplane:generate:reference:extractorArg=Region
+	ClusterName string
+
+	ClusterNameRef      *Reference
+	ClusterNameSelector *Selector
+
+	Region string
+}
+`,
+			want: "cannot get annotation to extract field ClusterName from: cannot both extract from an annotation and use an extractor",
+		},
+		"ExtractorArgPaved": {
+			reason: "Keys of paved maps should not have extractor arguments.",
+			source: `
+package v1alpha1
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:paved=vpcId=VPC
+	// +crossplane:generate:reference:extractorArg=Region
+	Parameters map[string]interface{}
+
+	Region string
+}
+`,
+			want: "cannot both be paved and use crossplane:generate:reference:extractorArg",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := loadPackage(t, tc.source)
+			n := p.Types.Scope().Lookup("ModelParameters").Type().(*types.Named)
+			rp := NewReferenceProcessor("mg")
+			err := xptypes.NewTraverser(comments.In(p)).Traverse(n, &xptypes.ProcessorConfig{Field: rp.For(n), Named: xptypes.NamedProcessorChain{}})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("\n%s\nTraverse(...): want error containing %q, got %v", tc.reason, tc.want, err)
+			}
+		})
+	}
+}
+
+func TestNewResolveReferencesDefaultExtractor(t *testing.T) {
+	// NetworkConfig appears three times in the type tree of Model. Each of its
+	// reference fields must use the default extractor inherited by that
+	// appearance, unless it specifies its own, even though the references of
+	// its fields are cached.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type NetworkConfig struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+
+	// +crossplane:generate:reference:type=SecurityGroup
+	// +crossplane:generate:reference:extractor=example.org/extract.SecurityGroupID()
+	SecurityGroupID *string
+
+	SecurityGroupIDRef *Reference
+
+	SecurityGroupIDSelector *Selector
+}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:defaultExtractor=example.org/extract.SubnetARN()
+	Primary NetworkConfig
+
+	// +crossplane:generate:reference:defaultExtractor=example.org/extract.SubnetName()
+	Secondary *NetworkConfig
+
+	Fallback NetworkConfig
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	extract "example.org/extract"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Primary.SubnetID),
+		Extract:      extract.SubnetARN(),
+		Reference:    mg.Spec.ForProvider.Primary.SubnetIDRef,
+		Selector:     mg.Spec.ForProvider.Primary.SubnetIDSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.Primary.SubnetID")
+	}
+	mg.Spec.ForProvider.Primary.SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.Primary.SubnetIDRef = rsp.ResolvedReference
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Primary.SecurityGroupID),
+		Extract:      extract.SecurityGroupID(),
+		Reference:    mg.Spec.ForProvider.Primary.SecurityGroupIDRef,
+		Selector:     mg.Spec.ForProvider.Primary.SecurityGroupIDSelector,
+		To: reference.To{
+			List:    &SecurityGroupList{},
+			Managed: &SecurityGroup{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.Primary.SecurityGroupID")
+	}
+	mg.Spec.ForProvider.Primary.SecurityGroupID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.Primary.SecurityGroupIDRef = rsp.ResolvedReference
+
+	if mg.Spec.ForProvider.Secondary != nil {
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Secondary.SubnetID),
+			Extract:      extract.SubnetName(),
+			Reference:    mg.Spec.ForProvider.Secondary.SubnetIDRef,
+			Selector:     mg.Spec.ForProvider.Secondary.SubnetIDSelector,
+			To: reference.To{
+				List:    &SubnetList{},
+				Managed: &Subnet{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Secondary.SubnetID")
+		}
+		mg.Spec.ForProvider.Secondary.SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+		mg.Spec.ForProvider.Secondary.SubnetIDRef = rsp.ResolvedReference
+
+	}
+	if mg.Spec.ForProvider.Secondary != nil {
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Secondary.SecurityGroupID),
+			Extract:      extract.SecurityGroupID(),
+			Reference:    mg.Spec.ForProvider.Secondary.SecurityGroupIDRef,
+			Selector:     mg.Spec.ForProvider.Secondary.SecurityGroupIDSelector,
+			To: reference.To{
+				List:    &SecurityGroupList{},
+				Managed: &SecurityGroup{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Secondary.SecurityGroupID")
+		}
+		mg.Spec.ForProvider.Secondary.SecurityGroupID = reference.ToPtrValue(rsp.ResolvedValue)
+		mg.Spec.ForProvider.Secondary.SecurityGroupIDRef = rsp.ResolvedReference
+
+	}
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Fallback.SubnetID),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.Fallback.SubnetIDRef,
+		Selector:     mg.Spec.ForProvider.Fallback.SubnetIDSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.Fallback.SubnetID")
+	}
+	mg.Spec.ForProvider.Fallback.SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.Fallback.SubnetIDRef = rsp.ResolvedReference
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Fallback.SecurityGroupID),
+		Extract:      extract.SecurityGroupID(),
+		Reference:    mg.Spec.ForProvider.Fallback.SecurityGroupIDRef,
+		Selector:     mg.Spec.ForProvider.Fallback.SecurityGroupIDSelector,
+		To: reference.To{
+			List:    &SecurityGroupList{},
+			Managed: &SecurityGroup{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.Fallback.SecurityGroupID")
+	}
+	mg.Spec.ForProvider.Fallback.SecurityGroupID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.Fallback.SecurityGroupIDRef = rsp.ResolvedReference
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesSameProviderConfig(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:sameProviderConfig
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+
+	// +crossplane:generate:reference:type=SecurityGroup
+	// +crossplane:generate:reference:sameProviderConfig
+	SecurityGroupIDs []string
+
+	SecurityGroupIDsRefs []Reference
+
+	SecurityGroupIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	providerconfig "example.org/providerconfig"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.SubnetID),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.SubnetIDRef,
+		Selector:     mg.Spec.ForProvider.SubnetIDSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetID")
+	}
+	if rsp.ResolvedReference != nil {
+		if err := providerconfig.Validate(ctx, c, mg, rsp.ResolvedReference, &Subnet{}); err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.SubnetID")
+		}
+	}
+	mg.Spec.ForProvider.SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.SubnetIDRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SecurityGroupIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SecurityGroupIDsRefs,
+		Selector:      mg.Spec.ForProvider.SecurityGroupIDsSelector,
+		To: reference.To{
+			List:    &SecurityGroupList{},
+			Managed: &SecurityGroup{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SecurityGroupIDs")
+	}
+	for i := range mrsp.ResolvedReferences {
+		if err := providerconfig.Validate(ctx, c, mg, &mrsp.ResolvedReferences[i], &SecurityGroup{}); err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.SecurityGroupIDs")
+		}
+	}
+	mg.Spec.ForProvider.SecurityGroupIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SecurityGroupIDsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithProviderConfigValidator("example.org/providerconfig.Validate"))); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+
+	t.Run("NoValidator", func(t *testing.T) {
+		defer func() {
+			want := "Spec.ForProvider.SubnetID requires the same provider config as Model, but no provider config validator is configured"
+			if diff := cmp.Diff(want, fmt.Sprint(recover())); diff != "" {
+				t.Errorf("NewResolveReferences(...): -want panic, +got panic\n%s", diff)
+			}
+		}()
+		resolveReferences(t, source)
+	})
+
+	t.Run("NoValidatorJSONPath", func(t *testing.T) {
+		tagged := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:sameProviderConfig
+	SubnetID *string `+"`json:\"subnetId,omitempty\"`"+`
+
+	SubnetIDRef *Reference `+"`json:\"subnetIdRef,omitempty\"`"+`
+
+	SubnetIDSelector *Selector `+"`json:\"subnetIdSelector,omitempty\"`"+`
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters `+"`json:\"forProvider\"`"+`
+}
+
+type Model struct {
+	Spec ModelSpec `+"`json:\"spec\"`"+`
+}
+`
+		defer func() {
+			want := "Spec.ForProvider.SubnetID (spec.forProvider.subnetId) requires the same provider config as Model, but no provider config validator is configured"
+			if diff := cmp.Diff(want, fmt.Sprint(recover())); diff != "" {
+				t.Errorf("NewResolveReferences(...): -want panic, +got panic\n%s", diff)
+			}
+		}()
+		resolveReferences(t, tagged)
+	})
+}
+
+func TestNewResolveReferencesFromAnnotation(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	// +crossplane:generate:reference:fromAnnotation=example.org/role-arn
+	RoleARN *string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:fromAnnotation=example.org/subnet-name
+	SubnetNames []string
+
+	SubnetNamesRefs []Reference
+
+	SubnetNamesSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	resource "example.org/resource"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.RoleARN),
+		Extract: func(res resource.Managed) string {
+			return res.GetAnnotations()["example.org/role-arn"]
+		},
+		Reference: mg.Spec.ForProvider.RoleARNRef,
+		Selector:  mg.Spec.ForProvider.RoleARNSelector,
+		To: reference.To{
+			List:    &RoleList{},
+			Managed: &Role{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+	}
+	mg.Spec.ForProvider.RoleARN = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.RoleARNRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SubnetNames,
+		Extract: func(res resource.Managed) string {
+			return res.GetAnnotations()["example.org/subnet-name"]
+		},
+		References: mg.Spec.ForProvider.SubnetNamesRefs,
+		Selector:   mg.Spec.ForProvider.SubnetNamesSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetNames")
+	}
+	mg.Spec.ForProvider.SubnetNames = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SubnetNamesRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithResource("example.org/resource"))); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+
+	t.Run("NoResource", func(t *testing.T) {
+		defer func() {
+			want := "Spec.ForProvider.RoleARN of Model is extracted from an annotation, but no resource package is configured"
+			if diff := cmp.Diff(want, fmt.Sprint(recover())); diff != "" {
+				t.Errorf("NewResolveReferences(...): -want panic, +got panic\n%s", diff)
+			}
+		}()
+		resolveReferences(t, source)
+	})
+}
+
+func TestReferenceProcessorFromAnnotation(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		source string
+		want   string
+	}{
+		"EmptyKey": {
+			reason: "An empty annotation key should return an error.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	// +crossplane:generate:reference:fromAnnotation=
+	RoleARN *string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+}
+`,
+			want: "cannot get annotation to extract field RoleARN from: annotation key must not be empty",
+		},
+		"AnnotationAndExtractor": {
+			reason: "Only one of an annotation and an extractor should be allowed.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	// +crossplane:generate:reference:fromAnnotation=example.org/role-arn
+	// +crossplane:generate:reference:extractor=example.org/extract.RoleARN()
+	RoleARN *string
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+}
+`,
+			want: "cannot both extract from an annotation and use an extractor",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := loadPackage(t, tc.source)
+			n := p.Types.Scope().Lookup("ModelParameters").Type().(*types.Named)
+			rp := NewReferenceProcessor("mg")
+			err := xptypes.NewTraverser(comments.In(p)).Traverse(n, &xptypes.ProcessorConfig{Field: rp.For(n), Named: xptypes.NamedProcessorChain{}})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("\n%s\nTraverse(...): want error containing %q, got %v", tc.reason, tc.want, err)
+			}
+		})
+	}
+}
+
+func TestNewResolveReferencesExtractorTag(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	RoleARN *string ` + "`json:\"roleArn,omitempty\" extractor:\"example.org/extract.RoleARN()\"`" + `
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+
+	// +crossplane:generate:reference:type=Subnet
+	SubnetIDs []string ` + "`extractor:\"example.org/extract.ParamPath(\\\"status.atProvider.id\\\",true)\"`" + `
+
+	SubnetIDsRefs []Reference
+
+	SubnetIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	extract "example.org/extract"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.RoleARN),
+		Extract:      extract.RoleARN(),
+		Reference:    mg.Spec.ForProvider.RoleARNRef,
+		Selector:     mg.Spec.ForProvider.RoleARNSelector,
+		To: reference.To{
+			List:    &RoleList{},
+			Managed: &Role{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARN")
+	}
+	mg.Spec.ForProvider.RoleARN = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.RoleARNRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SubnetIDs,
+		Extract:       extract.ParamPath("status.atProvider.id", true),
+		References:    mg.Spec.ForProvider.SubnetIDsRefs,
+		Selector:      mg.Spec.ForProvider.SubnetIDsSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+	}
+	mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.SubnetIDsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestReferenceProcessorExtractorTag(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		source string
+		want   string
+	}{
+		"MarkerAndTag": {
+			reason: "Only one of an extractor marker and an extractor tag should be allowed.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	// +crossplane:generate:reference:extractor=example.org/extract.RoleARN()
+	RoleARN *string ` + "`extractor:\"example.org/extract.RoleName()\"`" + `
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+}
+`,
+			want: "cannot both use the crossplane:generate:reference:extractor marker and the extractor tag",
+		},
+		"AnnotationAndTag": {
+			reason: "Only one of an annotation and an extractor tag should be allowed.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	// +crossplane:generate:reference:fromAnnotation=example.org/role-arn
+	RoleARN *string ` + "`extractor:\"example.org/extract.RoleARN()\"`" + `
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+}
+`,
+			want: "cannot both extract from an annotation and use an extractor",
+		},
+		"NotAFunctionCall": {
+			reason: "An extractor tag that is not a function call should return an error.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	RoleARN *string ` + "`extractor:\"example.org/extract.RoleARN\"`" + `
+
+	RoleARNRef *Reference
+
+	RoleARNSelector *Selector
+}
+`,
+			want: "cannot get extractor function from the extractor tag: path \"example.org/extract.RoleARN\" is not a valid function code",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := loadPackage(t, tc.source)
+			n := p.Types.Scope().Lookup("ModelParameters").Type().(*types.Named)
+			rp := NewReferenceProcessor("mg")
+			err := xptypes.NewTraverser(comments.In(p)).Traverse(n, &xptypes.ProcessorConfig{Field: rp.For(n), Named: xptypes.NamedProcessorChain{}})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("\n%s\nTraverse(...): want error containing %q, got %v", tc.reason, tc.want, err)
+			}
+		})
+	}
+}
+
+func TestNewResolveReferencesTenant(t *testing.T) {
+	// Model is namespace scoped, but references that are not to cluster scoped
+	// types should be resolved in the namespace of the tenant.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+
+	// +crossplane:generate:reference:type=Role
+	// +crossplane:generate:reference:clusterScoped
+	RoleARNs []string
+
+	RoleARNsRefs []Reference
+
+	RoleARNsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	tenancy "example.org/tenancy"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	tenant := tenancy.Namespace(ctx)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.SubnetID),
+		Extract:      reference.ExternalName(),
+		Namespace:    tenant,
+		Reference:    mg.Spec.ForProvider.SubnetIDRef,
+		Selector:     mg.Spec.ForProvider.SubnetIDSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetID")
+	}
+	mg.Spec.ForProvider.SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.SubnetIDRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.RoleARNs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.RoleARNsRefs,
+		Selector:      mg.Spec.ForProvider.RoleARNsSelector,
+		To: reference.To{
+			List:    &RoleList{},
+			Managed: &Role{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARNs")
+	}
+	mg.Spec.ForProvider.RoleARNs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.RoleARNsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`
+	namespaced := func(_ types.Object) bool { return true }
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithTenant("example.org/tenancy.Namespace"), WithNamespaced(namespaced))); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesAliasedTypes(t *testing.T) {
+	// The reference and selector fields are of aliases of the runtime types
+	// declared by another package, so variables that hold them should be
+	// declared with the qualified names of the aliases.
+	shared := packagestest.Module{
+		Name: "example.org/shared",
+		Files: map[string]any{
+			"common/common.go": `
+package common
+
+import v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+type Reference = v1.Reference
+
+type Selector = v1.Selector
+`,
+		},
+	}
+	source := `
+package v1alpha1
+
+import "example.org/shared/common"
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=VPC
+	// +crossplane:generate:reference:refFieldPath=Refs.VPCIDRef
+	// +crossplane:generate:reference:selectorFieldPath=Selectors.VPCIDSelector
+	VPCID *string
+
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:refFieldPath=Refs.SubnetIDsRefs
+	// +crossplane:generate:reference:selectorFieldPath=Selectors.SubnetIDsSelector
+	SubnetIDs []string
+
+	Refs *ModelRefs
+
+	Selectors *ModelSelectors
+}
+
+type ModelRefs struct {
+	VPCIDRef *common.Reference
+
+	SubnetIDsRefs []common.Reference
+}
+
+type ModelSelectors struct {
+	VPCIDSelector *common.Selector
+
+	SubnetIDsSelector *common.Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	common "example.org/shared/common"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	{
+		var ref *common.Reference
+		if mg.Spec.ForProvider.Refs != nil {
+			ref = mg.Spec.ForProvider.Refs.VPCIDRef
+		}
+		var selector *common.Selector
+		if mg.Spec.ForProvider.Selectors != nil {
+			selector = mg.Spec.ForProvider.Selectors.VPCIDSelector
+		}
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.VPCID),
+			Extract:      reference.ExternalName(),
+			Reference:    ref,
+			Selector:     selector,
+			To: reference.To{
+				List:    &VPCList{},
+				Managed: &VPC{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.VPCID")
+		}
+		mg.Spec.ForProvider.VPCID = reference.ToPtrValue(rsp.ResolvedValue)
+		if rsp.ResolvedReference != nil {
+			if mg.Spec.ForProvider.Refs == nil {
+				mg.Spec.ForProvider.Refs = &ModelRefs{}
+			}
+		}
+		if mg.Spec.ForProvider.Refs != nil {
+			mg.Spec.ForProvider.Refs.VPCIDRef = rsp.ResolvedReference
+		}
+	}
+
+	{
+		var refs []common.Reference
+		if mg.Spec.ForProvider.Refs != nil {
+			refs = mg.Spec.ForProvider.Refs.SubnetIDsRefs
+		}
+		var selector *common.Selector
+		if mg.Spec.ForProvider.Selectors != nil {
+			selector = mg.Spec.ForProvider.Selectors.SubnetIDsSelector
+		}
+		mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+			CurrentValues: mg.Spec.ForProvider.SubnetIDs,
+			Extract:       reference.ExternalName(),
+			References:    refs,
+			Selector:      selector,
+			To: reference.To{
+				List:    &SubnetList{},
+				Managed: &Subnet{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.SubnetIDs")
+		}
+		mg.Spec.ForProvider.SubnetIDs = mrsp.ResolvedValues
+		if len(mrsp.ResolvedReferences) > 0 {
+			if mg.Spec.ForProvider.Refs == nil {
+				mg.Spec.ForProvider.Refs = &ModelRefs{}
+			}
+		}
+		if mg.Spec.ForProvider.Refs != nil {
+			mg.Spec.ForProvider.Refs.SubnetIDsRefs = mrsp.ResolvedReferences
+		}
+	}
+
+	return nil
+}
+`
+	p := loadPackage(t, source, runtimeModule, shared)
+	f := jen.NewFilePath("golang.org/fake/v1alpha1")
+	NewResolveReferences(xptypes.NewTraverser(comments.In(p)), "mg", "example.org/client", "example.org/reference", WithRuntime("github.com/crossplane/crossplane-runtime/apis/common/v1"))(f, p.Types.Scope().Lookup("Model"))
+	if diff := cmp.Diff(want, fmt.Sprintf("%#v", f)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesResolver(t *testing.T) {
+	// References should be resolved by the configured resolver, rather than
+	// by the APIResolver of the reference package.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+
+	// +crossplane:generate:reference:type=Role
+	RoleARNs []string
+
+	RoleARNsRefs []Reference
+
+	RoleARNsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	webhook "example.org/webhook"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := webhook.NewResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.SubnetID),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.SubnetIDRef,
+		Selector:     mg.Spec.ForProvider.SubnetIDSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetID")
+	}
+	mg.Spec.ForProvider.SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.SubnetIDRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.RoleARNs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.RoleARNsRefs,
+		Selector:      mg.Spec.ForProvider.RoleARNsSelector,
+		To: reference.To{
+			List:    &RoleList{},
+			Managed: &Role{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARNs")
+	}
+	mg.Spec.ForProvider.RoleARNs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.RoleARNsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithResolver("example.org/webhook.NewResolver"))); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+
+	t.Run("ControllerRuntime", func(t *testing.T) {
+		defer func() {
+			want := "resolvers of Model that use the controller-runtime client cannot use a resolver"
+			if diff := cmp.Diff(want, fmt.Sprint(recover())); diff != "" {
+				t.Errorf("NewResolveReferences(...): -want panic, +got panic\n%s", diff)
+			}
+		}()
+		resolveReferences(t, source, WithResolver("example.org/webhook.NewResolver"), WithControllerRuntime("example.org/meta"))
+	})
+}
+
+func TestNewResolveReferencesSliceKey(t *testing.T) {
+	// The references of the rules are kept in a slice of keyed references of
+	// their parameters, so they should be found by the names of the rules
+	// rather than by their indices.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type NamedReference struct {
+	Name string
+
+	Reference *Reference
+}
+
+type Rule struct {
+	Name string
+
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:sliceKey=Name
+	SubnetID *string
+
+	SubnetIDSelector *Selector
+}
+
+type ModelParameters struct {
+	Rules []Rule
+
+	SubnetIDRefs []NamedReference
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	for i3 := range mg.Spec.ForProvider.Rules {
+		var ref *Reference
+		for _, kr := range mg.Spec.ForProvider.SubnetIDRefs {
+			if kr.Name == mg.Spec.ForProvider.Rules[i3].Name {
+				ref = kr.Reference
+			}
+		}
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Rules[i3].SubnetID),
+			Extract:      reference.ExternalName(),
+			Reference:    ref,
+			Selector:     mg.Spec.ForProvider.Rules[i3].SubnetIDSelector,
+			To: reference.To{
+				List:    &SubnetList{},
+				Managed: &Subnet{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Rules[*].SubnetID")
+		}
+		mg.Spec.ForProvider.Rules[i3].SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+
+		found := false
+		for j := range mg.Spec.ForProvider.SubnetIDRefs {
+			if mg.Spec.ForProvider.SubnetIDRefs[j].Name == mg.Spec.ForProvider.Rules[i3].Name {
+				mg.Spec.ForProvider.SubnetIDRefs[j].Reference = rsp.ResolvedReference
+				found = true
+			}
+		}
+		if !found && rsp.ResolvedReference != nil {
+			mg.Spec.ForProvider.SubnetIDRefs = append(mg.Spec.ForProvider.SubnetIDRefs, NamedReference{
+				Name:      mg.Spec.ForProvider.Rules[i3].Name,
+				Reference: rsp.ResolvedReference,
+			})
+		}
+
+	}
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestReferenceProcessorSliceKey(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		source string
+		want   string
+	}{
+		"NotInSlice": {
+			reason: "A slice key should only be allowed on a field of an element of a slice.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Rule struct {
+	Name string
+
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:sliceKey=Name
+	SubnetID *string
+
+	SubnetIDSelector *Selector
+}
+
+type Model struct {
+	Rule Rule
+}
+`,
+			want: "field SubnetID has a slice key but Rule is not an element of a slice",
+		},
+		"NoRefWriteBack": {
+			reason: "Keyed references should always be written back.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type NamedReference struct {
+	Name string
+
+	Reference *Reference
+}
+
+type Rule struct {
+	Name string
+
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:sliceKey=Name
+	// +crossplane:generate:reference:noRefWriteBack
+	SubnetID *string
+
+	SubnetIDSelector *Selector
+}
+
+type Model struct {
+	Rules []Rule
+
+	SubnetIDRefs []NamedReference
+}
+`,
+			want: "field SubnetID cannot both use crossplane:generate:reference:noRefWriteBack and crossplane:generate:reference:sliceKey",
+		},
+		"MissingKey": {
+			reason: "The key field should exist on the element.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type NamedReference struct {
+	Name string
+
+	Reference *Reference
+}
+
+type Rule struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:sliceKey=Name
+	SubnetID *string
+
+	SubnetIDSelector *Selector
+}
+
+type Model struct {
+	Rules []Rule
+
+	SubnetIDRefs []NamedReference
+}
+`,
+			want: "cannot get slice key of field SubnetID: Rule has no Name field",
+		},
+		"MissingRefs": {
+			reason: "The keyed references should exist on the struct that holds the slice.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Rule struct {
+	Name string
+
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:sliceKey=Name
+	SubnetID *string
+
+	SubnetIDSelector *Selector
+}
+
+type Model struct {
+	Rules []Rule
+}
+`,
+			want: "cannot get slice key of field SubnetID: field SubnetID is a reference but Model has no SubnetIDRefs field",
+		},
+		"NotKeyed": {
+			reason: "The keyed references should have a Name and a Reference field.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Rule struct {
+	Name string
+
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:sliceKey=Name
+	SubnetID *string
+
+	SubnetIDSelector *Selector
+}
+
+type Model struct {
+	Rules []Rule
+
+	SubnetIDRefs []Reference
+}
+`,
+			want: "cannot get slice key of field SubnetID: keyed reference Reference must have a string Name field and a Reference field",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := loadPackage(t, tc.source)
+			n := p.Types.Scope().Lookup("Model").Type().(*types.Named)
+			rp := NewReferenceProcessor("mg")
+			err := xptypes.NewTraverser(comments.In(p)).Traverse(n, &xptypes.ProcessorConfig{Field: rp.For(n), Named: xptypes.NamedProcessorChain{}})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("\n%s\nTraverse(...): want error containing %q, got %v", tc.reason, tc.want, err)
+			}
+		})
+	}
+}
+
+func TestNewResolveReferencesComposite(t *testing.T) {
+	// The cluster is referenced by a composite key, whose region and project
+	// should be written to their own fields once the key was extracted.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:composite=example.org/extract.ClusterKey()
+	// +crossplane:generate:reference:compositeInto=Name:ClusterName,Region:Region,Project:ProjectID
+	ClusterName *string
+
+	ClusterNameRef *Reference
+
+	ClusterNameSelector *Selector
+
+	Region *string
+
+	ProjectID string
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	extract "example.org/extract"
+	reference "example.org/reference"
+	resource "example.org/resource"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	{
+		var extracted bool
+		var compositeRegion string
+		var compositeProjectID string
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.ClusterName),
+			Extract: func(o resource.Managed) string {
+				k := extract.ClusterKey()(o)
+				compositeRegion = k.Region
+				compositeProjectID = k.Project
+				extracted = true
+				return k.Name
+			},
+			Reference: mg.Spec.ForProvider.ClusterNameRef,
+			Selector:  mg.Spec.ForProvider.ClusterNameSelector,
+			To: reference.To{
+				List:    &ClusterList{},
+				Managed: &Cluster{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.ClusterName")
+		}
+		mg.Spec.ForProvider.ClusterName = reference.ToPtrValue(rsp.ResolvedValue)
+		if extracted {
+			mg.Spec.ForProvider.Region = reference.ToPtrValue(compositeRegion)
+			mg.Spec.ForProvider.ProjectID = compositeProjectID
+		}
+		mg.Spec.ForProvider.ClusterNameRef = rsp.ResolvedReference
+	}
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithResource("example.org/resource"))); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestReferenceProcessorComposite(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		source string
+		want   string
+	}{
+		"IntoWithoutComposite": {
+			reason: "The fields of a composite key should only be listed with its extractor.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Model struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:compositeInto=Name:ClusterName
+	ClusterName *string
+
+	ClusterNameRef *Reference
+
+	ClusterNameSelector *Selector
+}
+`,
+			want: "cannot get composite key of field ClusterName: crossplane:generate:reference:compositeInto requires crossplane:generate:reference:composite",
+		},
+		"WithExtractor": {
+			reason: "A composite key should not be combined with another extractor.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Model struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:extractor=example.org/extract.Name()
+	// +crossplane:generate:reference:composite=example.org/extract.ClusterKey()
+	// +crossplane:generate:reference:compositeInto=Name:ClusterName
+	ClusterName *string
+
+	ClusterNameRef *Reference
+
+	ClusterNameSelector *Selector
+}
+`,
+			want: "cannot both resolve a composite key and use crossplane:generate:reference:extractor",
+		},
+		"ValueNotListed": {
+			reason: "The component that is the resolved value should be listed.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Model struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:composite=example.org/extract.ClusterKey()
+	// +crossplane:generate:reference:compositeInto=Region:Region
+	ClusterName *string
+
+	ClusterNameRef *Reference
+
+	ClusterNameSelector *Selector
+
+	Region *string
+}
+`,
+			want: "crossplane:generate:reference:compositeInto must list the component of field ClusterName",
+		},
+		"NotString": {
+			reason: "The fields that components are written to should be strings.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Model struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:composite=example.org/extract.ClusterKey()
+	// +crossplane:generate:reference:compositeInto=Name:ClusterName,Zone:Zone
+	ClusterName *string
+
+	ClusterNameRef *Reference
+
+	ClusterNameSelector *Selector
+
+	Zone int
+}
+`,
+			want: "field Zone of Model must be of type string or *string",
+		},
+		"WrittenTwice": {
+			reason: "A field should be written by only one component.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Model struct {
+	// +crossplane:generate:reference:type=Cluster
+	// +crossplane:generate:reference:composite=example.org/extract.ClusterKey()
+	// +crossplane:generate:reference:compositeInto=Name:ClusterName,Region:Region,Zone:Region
+	ClusterName *string
+
+	ClusterNameRef *Reference
+
+	ClusterNameSelector *Selector
+
+	Region *string
+}
+`,
+			want: "field Region is written by more than one component",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := loadPackage(t, tc.source)
+			n := p.Types.Scope().Lookup("Model").Type().(*types.Named)
+			rp := NewReferenceProcessor("mg")
+			err := xptypes.NewTraverser(comments.In(p)).Traverse(n, &xptypes.ProcessorConfig{Field: rp.For(n), Named: xptypes.NamedProcessorChain{}})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("\n%s\nTraverse(...): want error containing %q, got %v", tc.reason, tc.want, err)
+			}
+		})
+	}
+}
+
+func TestNewResolveReferencesSkipUnchanged(t *testing.T) {
+	// References and selectors, including those of the elements of slices,
+	// should be hashed before and after they are resolved, and resolution
+	// should be skipped if they are unchanged since the hash was stored.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Rule struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	RoleARNs []string
+
+	RoleARNsRefs []Reference
+
+	RoleARNsSelector *Selector
+
+	Rules []Rule
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	client "example.org/client"
+	reference "example.org/reference"
+	"fmt"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	hashInputs := func() (string, error) {
+		var inputs []interface{}
+		inputs = append(inputs, mg.Spec.ForProvider.RoleARNsRefs, mg.Spec.ForProvider.RoleARNsSelector)
+		for i3 := range mg.Spec.ForProvider.Rules {
+			inputs = append(inputs, mg.Spec.ForProvider.Rules[i3].SubnetIDRef, mg.Spec.ForProvider.Rules[i3].SubnetIDSelector)
+
+		}
+		b, err := json.Marshal(inputs)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+	}
+	hash, err := hashInputs()
+	if err != nil {
+		return errors.Wrap(err, "cannot hash references and selectors")
+	}
+	if mg.GetAnnotations()["example.org/resolved-inputs"] == hash {
+		return nil
+	}
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.RoleARNs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.RoleARNsRefs,
+		Selector:      mg.Spec.ForProvider.RoleARNsSelector,
+		To: reference.To{
+			List:    &RoleList{},
+			Managed: &Role{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARNs")
+	}
+	mg.Spec.ForProvider.RoleARNs = mrsp.ResolvedValues
+	mg.Spec.ForProvider.RoleARNsRefs = mrsp.ResolvedReferences
+
+	for i3 := range mg.Spec.ForProvider.Rules {
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Rules[i3].SubnetID),
+			Extract:      reference.ExternalName(),
+			Reference:    mg.Spec.ForProvider.Rules[i3].SubnetIDRef,
+			Selector:     mg.Spec.ForProvider.Rules[i3].SubnetIDSelector,
+			To: reference.To{
+				List:    &SubnetList{},
+				Managed: &Subnet{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Rules[*].SubnetID")
+		}
+		mg.Spec.ForProvider.Rules[i3].SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+		mg.Spec.ForProvider.Rules[i3].SubnetIDRef = rsp.ResolvedReference
+
+	}
+
+	if hash, err = hashInputs(); err != nil {
+		return errors.Wrap(err, "cannot hash references and selectors")
+	}
+	annotations := mg.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations["example.org/resolved-inputs"] = hash
+	mg.SetAnnotations(annotations)
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithSkipUnchanged("example.org/resolved-inputs"))); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+
+	// Values should always be resolved when they are returned.
+	if got := resolveReferences(t, source, WithSkipUnchanged("example.org/resolved-inputs"), WithResolvedValues()); strings.Contains(got, "hashInputs") {
+		t.Errorf("NewResolveReferences(...): ResolveReferencesWithValues should not skip unchanged references:\n%s", got)
+	}
+
+	// Errors hashing the references and selectors should be returned like
+	// those resolving them, setting the failure condition.
+	got := resolveReferences(t, source, WithSkipUnchanged("example.org/resolved-inputs"), WithRuntime("example.org/runtime"), WithFailureCondition("ReferencesResolved", "ReferenceResolutionFailed"), WithWrapWithMessage())
+	wantReturn := `
+		err = errors.WithMessage(err, "cannot hash references and selectors")
+		mg.SetConditions(runtime.Condition{`
+	if n := strings.Count(got, wantReturn); n != 2 {
+		t.Errorf("NewResolveReferences(...): hash errors were returned with the failure condition %d times, want twice:\n%s", n, got)
+	}
+}
+
+func TestNewResolveReferencesCache(t *testing.T) {
+	// Each field, including those of the elements of slices, should be
+	// resolved only if the hash of its reference, selector, and value differs
+	// from the one cached under its path in the status, which is updated once
+	// it is resolved.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Rule struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Role
+	RoleARNs []string
+
+	RoleARNsRefs []Reference
+
+	RoleARNsSelector *Selector
+
+	Rules []Rule
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type ModelStatus struct {
+	ResolvedReferences map[string]string
+}
+
+type Model struct {
+	Spec   ModelSpec
+	Status ModelStatus
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	client "example.org/client"
+	reference "example.org/reference"
+	"fmt"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	cacheKey := func(inputs []interface{}) (string, error) {
+		b, err := json.Marshal(inputs)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+	}
+	var inputs []interface{}
+	var cached string
+	var err error
+
+	inputs = []interface{}{mg.Spec.ForProvider.RoleARNs}
+	inputs = append(inputs, mg.Spec.ForProvider.RoleARNsRefs, mg.Spec.ForProvider.RoleARNsSelector)
+	if cached, err = cacheKey(inputs); err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.RoleARNs: cannot hash reference, selector, and value")
+	}
+	if mg.Status.ResolvedReferences["Spec.ForProvider.RoleARNs"] != cached {
+		mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+			CurrentValues: mg.Spec.ForProvider.RoleARNs,
+			Extract:       reference.ExternalName(),
+			References:    mg.Spec.ForProvider.RoleARNsRefs,
+			Selector:      mg.Spec.ForProvider.RoleARNsSelector,
+			To: reference.To{
+				List:    &RoleList{},
+				Managed: &Role{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.RoleARNs")
+		}
+		mg.Spec.ForProvider.RoleARNs = mrsp.ResolvedValues
+		mg.Spec.ForProvider.RoleARNsRefs = mrsp.ResolvedReferences
+
+		inputs = []interface{}{mg.Spec.ForProvider.RoleARNs}
+		inputs = append(inputs, mg.Spec.ForProvider.RoleARNsRefs, mg.Spec.ForProvider.RoleARNsSelector)
+		if cached, err = cacheKey(inputs); err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.RoleARNs: cannot hash reference, selector, and value")
+		}
+		if mg.Status.ResolvedReferences == nil {
+			mg.Status.ResolvedReferences = map[string]string{}
+		}
+		mg.Status.ResolvedReferences["Spec.ForProvider.RoleARNs"] = cached
+	}
+
+	for i3 := range mg.Spec.ForProvider.Rules {
+		inputs = []interface{}{mg.Spec.ForProvider.Rules[i3].SubnetID}
+		inputs = append(inputs, mg.Spec.ForProvider.Rules[i3].SubnetIDRef, mg.Spec.ForProvider.Rules[i3].SubnetIDSelector)
+		if cached, err = cacheKey(inputs); err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Rules[*].SubnetID: cannot hash reference, selector, and value")
+		}
+		if mg.Status.ResolvedReferences[fmt.Sprintf("Spec.ForProvider.Rules[%d].SubnetID", i3)] != cached {
+			rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+				CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Rules[i3].SubnetID),
+				Extract:      reference.ExternalName(),
+				Reference:    mg.Spec.ForProvider.Rules[i3].SubnetIDRef,
+				Selector:     mg.Spec.ForProvider.Rules[i3].SubnetIDSelector,
+				To: reference.To{
+					List:    &SubnetList{},
+					Managed: &Subnet{},
+				},
+			})
+			if err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Rules[*].SubnetID")
+			}
+			mg.Spec.ForProvider.Rules[i3].SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+			mg.Spec.ForProvider.Rules[i3].SubnetIDRef = rsp.ResolvedReference
+
+			inputs = []interface{}{mg.Spec.ForProvider.Rules[i3].SubnetID}
+			inputs = append(inputs, mg.Spec.ForProvider.Rules[i3].SubnetIDRef, mg.Spec.ForProvider.Rules[i3].SubnetIDSelector)
+			if cached, err = cacheKey(inputs); err != nil {
+				return errors.Wrap(err, "mg.Spec.ForProvider.Rules[*].SubnetID: cannot hash reference, selector, and value")
+			}
+			if mg.Status.ResolvedReferences == nil {
+				mg.Status.ResolvedReferences = map[string]string{}
+			}
+			mg.Status.ResolvedReferences[fmt.Sprintf("Spec.ForProvider.Rules[%d].SubnetID", i3)] = cached
+		}
+
+	}
+
+	return nil
+}
+`
+	cache := WithCache(func(_ types.Object) string { return "ResolvedReferences" })
+	if diff := cmp.Diff(want, resolveReferences(t, source, cache)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+
+	// Values should always be resolved when they are returned.
+	if got := resolveReferences(t, source, cache, WithResolvedValues()); strings.Contains(got, "cacheKey") {
+		t.Errorf("NewResolveReferences(...): ResolveReferencesWithValues should not skip cached references:\n%s", got)
+	}
+
+	// Types whose cache isn't named should not be cached.
+	if got := resolveReferences(t, source, WithCache(func(_ types.Object) string { return "" })); strings.Contains(got, "cacheKey") {
+		t.Errorf("NewResolveReferences(...): resolutions should not be cached without a cache field:\n%s", got)
+	}
+
+	t.Run("NoSuchField", func(t *testing.T) {
+		defer func() {
+			want := "cannot cache the resolutions of Model: its status has no field Cache"
+			if diff := cmp.Diff(want, fmt.Sprint(recover())); diff != "" {
+				t.Errorf("NewResolveReferences(...): -want panic, +got panic\n%s", diff)
+			}
+		}()
+		resolveReferences(t, source, WithCache(func(_ types.Object) string { return "Cache" }))
+	})
+
+	t.Run("WrongType", func(t *testing.T) {
+		defer func() {
+			want := "cannot cache the resolutions of Model: Status.ResolvedReferences is a map[string]int, not a map[string]string"
+			if diff := cmp.Diff(want, fmt.Sprint(recover())); diff != "" {
+				t.Errorf("NewResolveReferences(...): -want panic, +got panic\n%s", diff)
+			}
+		}()
+		resolveReferences(t, strings.Replace(source, "map[string]string", "map[string]int", 1), cache)
+	})
+}
+
+func TestNewResolveReferencesCachePaved(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:paved=subnet=Subnet
+	Config map[string]interface{}
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type ModelStatus struct {
+	ResolvedReferences map[string]string
+}
+
+type Model struct {
+	Spec   ModelSpec
+	Status ModelStatus
+}
+`
+	defer func() {
+		want := "Spec.ForProvider.Config.subnet of Model cannot be cached, because caching only supports references that resolve a field of their own"
+		if diff := cmp.Diff(want, fmt.Sprint(recover())); diff != "" {
+			t.Errorf("NewResolveReferences(...): -want panic, +got panic\n%s", diff)
+		}
+	}()
+	resolveReferences(t, source, WithFieldPath("example.org/fieldpath"), WithRuntime("example.org/runtime"), WithCache(func(_ types.Object) string { return "ResolvedReferences" }))
+}
+
+func TestNewResolveReferencesShadowedMethod(t *testing.T) {
+	// The GetNamespace method promoted from ObjectMeta is shadowed by a field
+	// of the same name promoted from Status, so it should be selected through
+	// ObjectMeta.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ObjectMeta struct {
+	Namespace string
+}
+
+func (m *ObjectMeta) GetNamespace() string { return m.Namespace }
+
+type Status struct {
+	GetNamespace string
+}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	ObjectMeta
+	Status
+
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.SubnetID),
+		Extract:      reference.ExternalName(),
+		Namespace:    mg.ObjectMeta.GetNamespace(),
+		Reference:    mg.Spec.ForProvider.SubnetIDRef,
+		Selector:     mg.Spec.ForProvider.SubnetIDSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetID")
+	}
+	mg.Spec.ForProvider.SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.SubnetIDRef = rsp.ResolvedReference
+
+	return nil
+}
+`
+	namespaced := func(_ types.Object) bool { return true }
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithNamespaced(namespaced))); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesFieldPaths(t *testing.T) {
+	// The references and selectors of SubnetID and RoleARNs are held by
+	// structs that the parameters point to, which may be nil even though the
+	// value fields are set. They should be read only if those structs are not
+	// nil, and allocated when resolved references are written. The selector
+	// of VPCID is held by a struct that isn't a pointer, but the struct that
+	// holds VPCID is, so it should be guarded by the value's guard only.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type References struct {
+	SubnetIDRef *Reference
+
+	RoleARNsRefs []Reference
+}
+
+type Selectors struct {
+	RoleARNsSelector *Selector
+
+	VPCIDSelector *Selector
+}
+
+type Network struct {
+	// +crossplane:generate:reference:type=VPC
+	// +crossplane:generate:reference:selectorFieldPath=Selectors.VPCIDSelector
+	VPCID *string
+
+	VPCIDRef *Reference
+
+	Selectors Selectors
+}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:refFieldPath=Refs.SubnetIDRef
+	SubnetID *string
+
+	SubnetIDSelector *Selector
+
+	// +crossplane:generate:reference:type=Role
+	// +crossplane:generate:reference:refFieldPath=Refs.RoleARNsRefs
+	// +crossplane:generate:reference:selectorFieldPath=Selectors.RoleARNsSelector
+	RoleARNs []string
+
+	Refs *References
+
+	Selectors *Selectors
+
+	Network *Network
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	{
+		var ref *Reference
+		if mg.Spec.ForProvider.Refs != nil {
+			ref = mg.Spec.ForProvider.Refs.SubnetIDRef
+		}
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.SubnetID),
+			Extract:      reference.ExternalName(),
+			Reference:    ref,
+			Selector:     mg.Spec.ForProvider.SubnetIDSelector,
+			To: reference.To{
+				List:    &SubnetList{},
+				Managed: &Subnet{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.SubnetID")
+		}
+		mg.Spec.ForProvider.SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+		if ref != nil {
+			mg.Spec.ForProvider.SubnetIDSelector = nil
+		}
+		if rsp.ResolvedReference != nil {
+			if mg.Spec.ForProvider.Refs == nil {
+				mg.Spec.ForProvider.Refs = &References{}
+			}
+		}
+		if mg.Spec.ForProvider.Refs != nil {
+			mg.Spec.ForProvider.Refs.SubnetIDRef = rsp.ResolvedReference
+		}
+	}
+
+	{
+		var refs []Reference
+		if mg.Spec.ForProvider.Refs != nil {
+			refs = mg.Spec.ForProvider.Refs.RoleARNsRefs
+		}
+		var selector *Selector
+		if mg.Spec.ForProvider.Selectors != nil {
+			selector = mg.Spec.ForProvider.Selectors.RoleARNsSelector
+		}
+		mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+			CurrentValues: mg.Spec.ForProvider.RoleARNs,
+			Extract:       reference.ExternalName(),
+			References:    refs,
+			Selector:      selector,
+			To: reference.To{
+				List:    &RoleList{},
+				Managed: &Role{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.RoleARNs")
+		}
+		mg.Spec.ForProvider.RoleARNs = mrsp.ResolvedValues
+		if len(refs) > 0 && mg.Spec.ForProvider.Selectors != nil {
+			mg.Spec.ForProvider.Selectors.RoleARNsSelector = nil
+		}
+		if len(mrsp.ResolvedReferences) > 0 {
+			if mg.Spec.ForProvider.Refs == nil {
+				mg.Spec.ForProvider.Refs = &References{}
+			}
+		}
+		if mg.Spec.ForProvider.Refs != nil {
+			mg.Spec.ForProvider.Refs.RoleARNsRefs = mrsp.ResolvedReferences
+		}
+	}
+
+	if mg.Spec.ForProvider.Network != nil {
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Network.VPCID),
+			Extract:      reference.ExternalName(),
+			Reference:    mg.Spec.ForProvider.Network.VPCIDRef,
+			Selector:     mg.Spec.ForProvider.Network.Selectors.VPCIDSelector,
+			To: reference.To{
+				List:    &VPCList{},
+				Managed: &VPC{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Network.VPCID")
+		}
+		mg.Spec.ForProvider.Network.VPCID = reference.ToPtrValue(rsp.ResolvedValue)
+		if mg.Spec.ForProvider.Network.VPCIDRef != nil {
+			mg.Spec.ForProvider.Network.Selectors.VPCIDSelector = nil
+		}
+		mg.Spec.ForProvider.Network.VPCIDRef = rsp.ResolvedReference
+
+	}
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithClearSelectors())); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestReferenceProcessorFieldPaths(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		source string
+		want   string
+	}{
+		"MissingParent": {
+			reason: "Every field on a reference field path should exist.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:refFieldPath=Refs.SubnetIDRef
+	SubnetID *string
+
+	SubnetIDSelector *Selector
+}
+`,
+			want: "cannot get reference field of field SubnetID: ModelParameters has no Refs field",
+		},
+		"NotStruct": {
+			reason: "Fields on a selector field path should be structs or pointers to structs.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:selectorFieldPath=Selectors.SubnetIDSelector
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	Selectors []Selector
+}
+`,
+			want: "cannot get selector field of field SubnetID: field Selectors of ModelParameters must be a struct or a pointer to a struct",
+		},
+		"MissingField": {
+			reason: "The struct at the end of a reference field path should have the reference field.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type References struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:refFieldPath=Refs.SubnetIDRef
+	SubnetID *string
+
+	SubnetIDSelector *Selector
+
+	Refs *References
+}
+`,
+			want: "field SubnetID is a reference but References has no SubnetIDRef field",
+		},
+		"NameAndPath": {
+			reason: "Only one of a reference field name and path should be allowed.",
+			source: `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type References struct {
+	SubnetIDRef *Reference
+}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	// +crossplane:generate:reference:refFieldName=SubnetRef
+	// +crossplane:generate:reference:refFieldPath=Refs.SubnetIDRef
+	SubnetID *string
+
+	SubnetIDSelector *Selector
+
+	Refs *References
+}
+`,
+			want: "cannot both use crossplane:generate:reference:refFieldPath and crossplane:generate:reference:refFieldName",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := loadPackage(t, tc.source)
+			n := p.Types.Scope().Lookup("ModelParameters").Type().(*types.Named)
+			rp := NewReferenceProcessor("mg")
+			err := xptypes.NewTraverser(comments.In(p)).Traverse(n, &xptypes.ProcessorConfig{Field: rp.For(n), Named: xptypes.NamedProcessorChain{}})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("\n%s\nTraverse(...): want error containing %q, got %v", tc.reason, tc.want, err)
+			}
+		})
+	}
+}
+
+func TestNewResolveReferencesClearSelectors(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+
+	// +crossplane:generate:reference:type=SecurityGroup
+	SecurityGroupIDs []string
+
+	SecurityGroupIDsRefs []Reference
+
+	SecurityGroupIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.SubnetID),
+		Extract:      reference.ExternalName(),
+		Reference:    mg.Spec.ForProvider.SubnetIDRef,
+		Selector:     mg.Spec.ForProvider.SubnetIDSelector,
+		To: reference.To{
+			List:    &SubnetList{},
+			Managed: &Subnet{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SubnetID")
+	}
+	mg.Spec.ForProvider.SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+	if mg.Spec.ForProvider.SubnetIDRef != nil {
+		mg.Spec.ForProvider.SubnetIDSelector = nil
+	}
+	mg.Spec.ForProvider.SubnetIDRef = rsp.ResolvedReference
+
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SecurityGroupIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SecurityGroupIDsRefs,
+		Selector:      mg.Spec.ForProvider.SecurityGroupIDsSelector,
+		To: reference.To{
+			List:    &SecurityGroupList{},
+			Managed: &SecurityGroup{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.SecurityGroupIDs")
+	}
+	mg.Spec.ForProvider.SecurityGroupIDs = mrsp.ResolvedValues
+	if len(mg.Spec.ForProvider.SecurityGroupIDsRefs) > 0 {
+		mg.Spec.ForProvider.SecurityGroupIDsSelector = nil
+	}
+	mg.Spec.ForProvider.SecurityGroupIDsRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithClearSelectors())); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesReservedNames(t *testing.T) {
+	// The extractor is declared by the package of the managed resource with
+	// the name of a variable of the generated method, which must be renamed
+	// so that it doesn't shadow the extractor.
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type R struct {
+	// +crossplane:generate:reference:type=VPC
+	// +crossplane:generate:reference:extractor=rsp()
+	Err *string
+
+	ErrRef *Reference
+
+	ErrSelector *Selector
+}
+
+type ModelParameters struct {
+	R *R
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+
+func rsp() func() string { return nil }
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferences of this Model.
+func (mg *Model) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp_ reference.ResolutionResponse
+	var err error
+
+	if mg.Spec.ForProvider.R != nil {
+		rsp_, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.R.Err),
+			Extract:      rsp(),
+			Reference:    mg.Spec.ForProvider.R.ErrRef,
+			Selector:     mg.Spec.ForProvider.R.ErrSelector,
+			To: reference.To{
+				List:    &VPCList{},
+				Managed: &VPC{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.R.Err")
+		}
+		mg.Spec.ForProvider.R.Err = reference.ToPtrValue(rsp_.ResolvedValue)
+		mg.Spec.ForProvider.R.ErrRef = rsp_.ResolvedReference
+
+	}
+
+	return nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source)); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestNewResolveReferencesWithValues(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type Item struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+
+type ModelParameters struct {
+	Items []Item
+
+	// +crossplane:generate:reference:type=SecurityGroup
+	SecurityGroupIDs []string
+
+	SecurityGroupIDsRefs []Reference
+
+	SecurityGroupIDsSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+`
+	want := `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	reference "example.org/reference"
+	"fmt"
+	errors "github.com/pkg/errors"
+)
+
+// ResolveReferencesWithValues of this Model. It returns resolved values by field path.
+func (mg *Model) ResolveReferencesWithValues(ctx context.Context, c client.Reader) (map[string]string, error) {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var mrsp reference.MultiResolutionResponse
+	resolved := map[string]string{}
+	var err error
+
+	for i3 := range mg.Spec.ForProvider.Items {
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Items[i3].SubnetID),
+			Extract:      reference.ExternalName(),
+			Reference:    mg.Spec.ForProvider.Items[i3].SubnetIDRef,
+			Selector:     mg.Spec.ForProvider.Items[i3].SubnetIDSelector,
+			To: reference.To{
+				List:    &SubnetList{},
+				Managed: &Subnet{},
+			},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "mg.Spec.ForProvider.Items[*].SubnetID")
+		}
+		mg.Spec.ForProvider.Items[i3].SubnetID = reference.ToPtrValue(rsp.ResolvedValue)
+		resolved[fmt.Sprintf("Spec.ForProvider.Items[%d].SubnetID", i3)] = rsp.ResolvedValue
+		mg.Spec.ForProvider.Items[i3].SubnetIDRef = rsp.ResolvedReference
+
+	}
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.SecurityGroupIDs,
+		Extract:       reference.ExternalName(),
+		References:    mg.Spec.ForProvider.SecurityGroupIDsRefs,
+		Selector:      mg.Spec.ForProvider.SecurityGroupIDsSelector,
+		To: reference.To{
+			List:    &SecurityGroupList{},
+			Managed: &SecurityGroup{},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "mg.Spec.ForProvider.SecurityGroupIDs")
+	}
+	mg.Spec.ForProvider.SecurityGroupIDs = mrsp.ResolvedValues
+	for i, v := range mrsp.ResolvedValues {
+		resolved[fmt.Sprintf("Spec.ForProvider.SecurityGroupIDs[%d]", i)] = v
+	}
+	mg.Spec.ForProvider.SecurityGroupIDsRefs = mrsp.ResolvedReferences
+
+	return resolved, nil
+}
+`
+	if diff := cmp.Diff(want, resolveReferences(t, source, WithResolvedValues())); diff != "" {
+		t.Errorf("NewResolveReferences(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestGetValueFormat(t *testing.T) {
+	type want struct {
+		vf  *ValueFormat
+		err bool
+	}
+	cases := map[string]struct {
+		reason   string
+		template string
+		isList   bool
+		want     want
+	}{
+		"Embedded": {
+			reason:   "The literal parts around the placeholder should be returned.",
+			template: "arn:{name}:suffix",
+			want:     want{vf: &ValueFormat{Prefix: "arn:", Suffix: ":suffix"}},
+		},
+		"NoPlaceholder": {
+			reason:   "A template without a placeholder should return an error.",
+			template: "arn:aws",
+			want:     want{err: true},
+		},
+		"TwoPlaceholders": {
+			reason:   "A template with more than one placeholder should return an error.",
+			template: "{name}/{name}",
+			want:     want{err: true},
+		},
+		"Slice": {
+			reason:   "Formatted slice fields should return an error.",
+			template: "arn:{name}",
+			isList:   true,
+			want:     want{err: true},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			vf, err := getValueFormat(tc.template, tc.isList)
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\ngetValueFormat(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.vf, vf); diff != "" {
+				t.Errorf("\n%s\ngetValueFormat(...): -want, +got\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestClean(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		field  string
+		want   string
+	}{
+		"Plain": {
+			reason: "A field without a prefix should be unchanged.",
+			field:  "SubnetID",
+			want:   "SubnetID",
+		},
+		"Pointer": {
+			reason: "The pointer prefix should be stripped.",
+			field:  "*Network",
+			want:   "Network",
+		},
+		"SliceOfPointers": {
+			reason: "The slice and pointer prefixes should be stripped.",
+			field:  "[]*Rules",
+			want:   "Rules",
+		},
+		"Implementation": {
+			reason: "The implementation prefix should be stripped.",
+			field:  "(Custom)Config",
+			want:   "Config",
+		},
+		"Index": {
+			reason: "The index of a field rewritten by encapsulate should survive cleaning.",
+			field:  "Rules[i3]",
+			want:   "Rules[i3]",
+		},
+		"Assertion": {
+			reason: "The type assertion of a field rewritten by encapsulate should survive cleaning.",
+			field:  "Config.(*Custom)",
+			want:   "Config.(*Custom)",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, clean(tc.field)); diff != "" {
+				t.Errorf("\n%s\nclean(...): -want, +got\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestEncapsulate(t *testing.T) {
+	type want struct {
+		fields []string
+		code   string
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields []string
+		want   want
+	}{
+		"ValueElements": {
+			reason: "Elements of slices of values should be indexed directly, so that what is resolved into them is written back.",
+			fields: []string{"mg", "Spec", "ForProvider", "[]Rules", "*Network", "SubnetID"},
+			want: want{
+				fields: []string{"mg", "Spec", "ForProvider", "Rules[i3]", "Network", "SubnetID"},
+				code: `for i3 := range mg.Spec.ForProvider.Rules {
+	if mg.Spec.ForProvider.Rules[i3].Network != nil {
+		resolve(mg.Spec.ForProvider.Rules[i3].Network.SubnetID)
+	}
+}`,
+			},
+		},
+		"PointerElements": {
+			reason: "Elements of slices of pointers should be hoisted into locals that nested expressions start from.",
+			fields: []string{"mg", "Spec", "ForProvider", "[]Rules", "[]*Targets", "*Network", "SubnetID"},
+			want: want{
+				fields: []string{"mg", "Spec", "ForProvider", "Rules[i3]", "Targets[i4]@e4", "Network", "SubnetID"},
+				code: `for i3 := range mg.Spec.ForProvider.Rules {
+	for i4 := range mg.Spec.ForProvider.Rules[i3].Targets {
+		e4 := mg.Spec.ForProvider.Rules[i3].Targets[i4]
+		if e4 != nil {
+			if e4.Network != nil {
+				resolve(e4.Network.SubnetID)
+			}
+		}
+	}
+}`,
+			},
+		},
+		"NestedPointerElements": {
+			reason: "Elements of slices of pointers within hoisted elements should be hoisted from the enclosing local.",
+			fields: []string{"mg", "Spec", "ForProvider", "[]*Rules", "[]*Targets", "SubnetID"},
+			want: want{
+				fields: []string{"mg", "Spec", "ForProvider", "Rules[i3]@e3", "Targets[i4]@e4", "SubnetID"},
+				code: `for i3 := range mg.Spec.ForProvider.Rules {
+	e3 := mg.Spec.ForProvider.Rules[i3]
+	if e3 != nil {
+		for i4 := range e3.Targets {
+			e4 := e3.Targets[i4]
+			if e4 != nil {
+				resolve(e4.SubnetID)
+			}
+		}
+	}
+}`,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			s := encapsulate(0, func(fields ...string) *jen.Statement {
+				got = append([]string{}, fields...)
+				return jen.Id("resolve").Call(goExpr(fields...))
+			}, tc.fields...)
+
+			if diff := cmp.Diff(tc.want.fields, got); diff != "" {
+				t.Errorf("\n%s\nencapsulate(...): -want fields, +got fields\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.code, fmt.Sprintf("%#v", s)); diff != "" {
+				t.Errorf("\n%s\nencapsulate(...): -want code, +got code\n%s", tc.reason, diff)
+			}
+		})
 	}
 }
@@ -0,0 +1,83 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package method
+
+import (
+	"go/types"
+
+	"github.com/dave/jennifer/jen"
+
+	xptypes "github.com/crossplane/crossplane-tools/internal/types"
+)
+
+// NewResolversIndex returns a function that writes a ResolveReferences function
+// that resolves the references of any of the supplied managed resources that
+// has a ResolveReferences method, either because it has references that one is
+// generated for or because it is already defined. It calls the method of the
+// concrete type of the managed resource it is supplied, and returns a
+// ReferencesNotSupportedError for any other type. Managed resources are
+// switched over in the order they are supplied.
+func NewResolversIndex(traverser *xptypes.Traverser, runtimePackagePath, clientPath, resourcePath string) func(f *jen.File, objects []types.Object) {
+	return func(f *jen.File, objects []types.Object) {
+		cases := make([]jen.Code, 0, len(objects))
+		for _, o := range objects {
+			n, ok := o.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			if m, _, _ := types.LookupFieldOrMethod(types.NewPointer(n), true, n.Obj().Pkg(), "ResolveReferences"); m == nil {
+				refs, err := References(traverser, runtimePackagePath, n)
+				if err != nil {
+					panic(err)
+				}
+				if len(refs) == 0 {
+					continue
+				}
+			}
+			cases = append(cases, jen.Case(jen.Op("*").Id(o.Name())).Block(
+				jen.Return(jen.Id("mg").Dot("ResolveReferences").Call(jen.Id("ctx"), jen.Id("c"))),
+			))
+		}
+		if len(cases) == 0 {
+			return
+		}
+
+		f.Comment("A ReferencesNotSupportedError is returned by ResolveReferences for a managed")
+		f.Comment("resource that isn't of a kind of this package with references.")
+		f.Type().Id("ReferencesNotSupportedError").Struct(
+			jen.Id("Managed").Qual(resourcePath, "Managed"),
+		)
+		f.Line()
+		f.Func().Params(jen.Id("e").Op("*").Id("ReferencesNotSupportedError")).Id("Error").Params().String().Block(
+			jen.Return(jen.Qual("fmt", "Sprintf").Call(jen.Lit("cannot resolve references of %T"), jen.Id("e").Dot("Managed"))),
+		)
+		f.Line()
+		f.Comment("ResolveReferences resolves the references of the supplied managed resource,")
+		f.Comment("which must be of a kind of this package with references.")
+		f.Func().Id("ResolveReferences").Params(
+			jen.Id("ctx").Qual("context", "Context"),
+			jen.Id("c").Qual(clientPath, "Reader"),
+			jen.Id("mg").Qual(resourcePath, "Managed"),
+		).Error().Block(
+			jen.Switch(jen.Id("mg").Op(":=").Id("mg").Assert(jen.Type())).Block(append(cases,
+				jen.Default().Block(
+					jen.Return(jen.Op("&").Id("ReferencesNotSupportedError").Values(jen.Dict{jen.Id("Managed"): jen.Id("mg")})),
+				),
+			)...),
+		)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package method
+
+import (
+	"fmt"
+	"go/types"
+	"testing"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/google/go-cmp/cmp"
+
+	xptypes "github.com/crossplane/crossplane-tools/internal/types"
+	"github.com/crossplane/crossplane-tools/pkg/comments"
+)
+
+func TestNewResolversIndex(t *testing.T) {
+	source := `
+package v1alpha1
+
+type Reference struct {}
+
+type Selector struct {}
+
+type ModelParameters struct {
+	// +crossplane:generate:reference:type=Subnet
+	SubnetID *string
+
+	SubnetIDRef *Reference
+
+	SubnetIDSelector *Selector
+}
+
+type ModelSpec struct {
+	ForProvider ModelParameters
+}
+
+type Model struct {
+	Spec ModelSpec
+}
+
+type Manual struct {
+	Spec string
+}
+
+func (mg *Manual) ResolveReferences(ctx interface{}, c interface{}) error { return nil }
+
+type NoReferences struct {
+	Spec string
+}
+`
+	cases := map[string]struct {
+		reason  string
+		objects []string
+		want    string
+	}{
+		"Index": {
+			reason:  "Managed resources with references or a ResolveReferences method should be switched over in the order supplied.",
+			objects: []string{"Manual", "Model", "NoReferences"},
+			want: `package v1alpha1
+
+import (
+	"context"
+	client "example.org/client"
+	resource "example.org/resource"
+	"fmt"
+)
+
+// A ReferencesNotSupportedError is returned by ResolveReferences for a managed
+// resource that isn't of a kind of this package with references.
+type ReferencesNotSupportedError struct {
+	Managed resource.Managed
+}
+
+func (e *ReferencesNotSupportedError) Error() string {
+	return fmt.Sprintf("cannot resolve references of %T", e.Managed)
+}
+
+// ResolveReferences resolves the references of the supplied managed resource,
+// which must be of a kind of this package with references.
+func ResolveReferences(ctx context.Context, c client.Reader, mg resource.Managed) error {
+	switch mg := mg.(type) {
+	case *Manual:
+		return mg.ResolveReferences(ctx, c)
+	case *Model:
+		return mg.ResolveReferences(ctx, c)
+	default:
+		return &ReferencesNotSupportedError{Managed: mg}
+	}
+}
+`,
+		},
+		"NoReferences": {
+			reason:  "Nothing should be written if no managed resource has references.",
+			objects: []string{"NoReferences"},
+			want:    "package v1alpha1\n",
+		},
+	}
+
+	p := loadPackage(t, source)
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := jen.NewFilePath("golang.org/fake/v1alpha1")
+			objects := make([]types.Object, len(tc.objects))
+			for i, n := range tc.objects {
+				objects[i] = p.Types.Scope().Lookup(n)
+			}
+			NewResolversIndex(xptypes.NewTraverser(comments.In(p)), "", "example.org/client", "example.org/resource")(f, objects)
+			if diff := cmp.Diff(tc.want, fmt.Sprintf("%#v", f)); diff != "" {
+				t.Errorf("\n%s\nNewResolversIndex(...): -want, +got\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -17,13 +17,27 @@ limitations under the License.
 package types
 
 import (
+	"go/token"
 	"go/types"
+	"strings"
 
 	"github.com/pkg/errors"
 
-	"github.com/crossplane/crossplane-tools/internal/comments"
+	"github.com/crossplane/crossplane-tools/pkg/comments"
 )
 
+// ImplementationsMarker lists the concrete types that may be set for a field
+// whose type is an interface, for example
+// +crossplane:generate:implementations=S3Source,GCSSource. The types must be
+// defined in the same package as the field, and their pointers must implement
+// the interface. Each is traversed as if it were the type of the field.
+const ImplementationsMarker = "crossplane:generate:implementations"
+
+// TraverseMarker makes a Traverser traverse the type of a field although it is
+// one of its skipped types, for example +crossplane:generate:traverse on an
+// embedded xpv1.ResourceSpec that declares references.
+const TraverseMarker = "crossplane:generate:traverse"
+
 // NamedProcessorChain runs multiple NamedProcessors in order.
 type NamedProcessorChain []NamedProcessor
 
@@ -44,6 +58,14 @@ type NamedProcessor interface {
 	Process(n *types.Named, comment string) error
 }
 
+// A NamedProcessorFn is a function that satisfies NamedProcessor.
+type NamedProcessorFn func(n *types.Named, comment string) error
+
+// Process calls the NamedProcessorFn.
+func (fn NamedProcessorFn) Process(n *types.Named, comment string) error {
+	return fn(n, comment)
+}
+
 // FieldProcessorChain runs multiple FieldProcessor in order.
 type FieldProcessorChain []FieldProcessor
 
@@ -62,24 +84,97 @@ type FieldProcessor interface {
 	Process(n *types.Named, f *types.Var, tag string, comment string, parentFields ...string) error
 }
 
+// A FieldProcessorFn is a function that satisfies FieldProcessor.
+type FieldProcessorFn func(n *types.Named, f *types.Var, tag, comment string, parentFields ...string) error
+
+// Process calls the FieldProcessorFn.
+func (fn FieldProcessorFn) Process(n *types.Named, f *types.Var, tag, comment string, parentFields ...string) error {
+	return fn(n, f, tag, comment, parentFields...)
+}
+
 // ProcessorConfig lets you configure what processors will be run in given traversal.
 type ProcessorConfig struct {
 	Named NamedProcessor
 	Field FieldProcessor
 }
 
+// A DepthExceededFn is called with each type that a Traverser doesn't traverse
+// because it is deeper than the maximum depth, and the parent fields at which
+// it appears.
+type DepthExceededFn func(n *types.Named, parentFields ...string)
+
+// An OpaqueFieldFn is called with each field of an opaque type that has
+// markers, which a Traverser doesn't process, and the parent fields at which it
+// appears.
+type OpaqueFieldFn func(n *types.Named, f *types.Var, parentFields ...string)
+
+// A TraverserOption configures a Traverser.
+type TraverserOption func(*Traverser)
+
+// WithMaxDepth stops a Traverser from descending into struct types that are more
+// than the supplied number of fields deep. Their fields are not processed, and the
+// supplied function, if any, is called with each of them. There is no limit if
+// depth is zero.
+func WithMaxDepth(depth int, fn DepthExceededFn) TraverserOption {
+	return func(t *Traverser) {
+		t.maxDepth = depth
+		t.depthExceeded = fn
+	}
+}
+
+// WithOpaqueFields calls the supplied function with each field of an opaque
+// type that has markers. Fields of opaque types are never processed, so their
+// markers are ignored.
+func WithOpaqueFields(fn OpaqueFieldFn) TraverserOption {
+	return func(t *Traverser) {
+		t.opaqueField = fn
+	}
+}
+
+// WithSkippedTypes stops a Traverser from descending into the supplied types,
+// named by their package path and name, for example
+// github.com/crossplane/crossplane-runtime/apis/common/v1.ResourceSpec, unless
+// the field of the type has the TraverseMarker. The fields of types that every
+// managed resource embeds need not be traversed to find references that they
+// don't declare. Fields of the types themselves are still processed.
+func WithSkippedTypes(names ...string) TraverserOption {
+	return func(t *Traverser) {
+		for _, n := range names {
+			t.skipped[n] = true
+		}
+	}
+}
+
 // NewTraverser returns a new Traverser.
-func NewTraverser(c comments.Comments) *Traverser {
-	return &Traverser{
+func NewTraverser(c comments.Comments, o ...TraverserOption) *Traverser {
+	t := &Traverser{
 		comments: c,
+		visiting: map[*types.Named]bool{},
+		skipped:  map[string]bool{},
 	}
+	for _, fn := range o {
+		fn(t)
+	}
+	return t
 }
 
 // Traverser goes through all fields of given type recursively. It runs the field
 // processor for every field and named processor for every type it encounters
-// during its depth-first traversal.
+// during its depth-first traversal. Types that refer to themselves, like those
+// generated from recursive protobuf messages, are traversed only once along
+// each path. Anonymous struct types declared inline as the types of fields are
+// traversed like named ones. Types of unexported fields, like the internal state of protobuf
+// messages, are not traversed. Neither are types deeper than the maximum depth,
+// if one is configured, nor skipped types, unless their fields are marked.
+// Fields of opaque types, like unsafe.Pointer, uintptr, and the C types of cgo,
+// are neither processed nor traversed.
 type Traverser struct {
-	comments comments.Comments
+	comments      comments.Comments
+	visiting      map[*types.Named]bool
+	maxDepth      int
+	depthExceeded DepthExceededFn
+	skipped       map[string]bool
+	opaqueField   OpaqueFieldFn
 }
 
 // NOTE(muvaf): We return an error but currently there isn't really anything
@@ -88,6 +183,17 @@ type Traverser struct {
 // Traverse traverser given type recursively and runs given processors.
 func (t *Traverser) Traverse(n *types.Named, cfg *ProcessorConfig, parentFields ...string) error { // nolint:gocyclo
 	// NOTE(muvaf): gocyclo is disabled due to repeated type checks.
+	if t.visiting[n] {
+		return nil
+	}
+	if _, ok := n.Underlying().(*types.Struct); ok && t.maxDepth > 0 && len(parentFields) > t.maxDepth {
+		if t.depthExceeded != nil {
+			t.depthExceeded(n, parentFields...)
+		}
+		return nil
+	}
+	t.visiting[n] = true
+	defer delete(t.visiting, n)
 	if err := cfg.Named.Process(n, t.comments.For(n.Obj())); err != nil {
 		return errors.Wrapf(err, "type processors failed to run for type %s", n.Obj().Name())
 	}
@@ -98,28 +204,47 @@ func (t *Traverser) Traverse(n *types.Named, cfg *ProcessorConfig, parentFields
 	for i := 0; i < st.NumFields(); i++ {
 		field := st.Field(i)
 		tag := st.Tag(i)
+		if opaque(field.Type()) {
+			if t.opaqueField != nil && len(comments.ParseMarkers(t.comments.For(field))) > 0 {
+				t.opaqueField(n, field, parentFields...)
+			}
+			continue
+		}
 		if err := cfg.Field.Process(n, field, tag, t.comments.For(field), parentFields...); err != nil {
 			return errors.Wrapf(err, "field processors failed to run for field %s of type %s", field.Name(), n.Obj().Name())
 		}
+		if !field.Exported() || t.skip(field) {
+			continue
+		}
 		switch ft := field.Type().(type) {
 		case *types.Named:
+			if _, ok := ft.Underlying().(*types.Interface); ok {
+				if err := t.traverseImplementations(n, field, cfg, parentFields...); err != nil {
+					return errors.Wrapf(err, "failed to traverse implementations of field %s", field.Name())
+				}
+				continue
+			}
 			if err := t.Traverse(ft, cfg, append(parentFields, field.Name())...); err != nil {
 				return errors.Wrapf(err, "failed to traverse type of field %s", field.Name())
 			}
+		case *types.Struct:
+			if err := t.Traverse(anonymous(n, field, ft), cfg, append(parentFields, field.Name())...); err != nil {
+				return errors.Wrapf(err, "failed to traverse type of field %s", field.Name())
+			}
 		case *types.Pointer:
-			if elemType, ok := ft.Elem().(*types.Named); ok {
+			if elemType := structType(n, field, ft.Elem()); elemType != nil {
 				if err := t.Traverse(elemType, cfg, append(parentFields, "*"+field.Name())...); err != nil {
 					return errors.Wrapf(err, "failed to traverse type of field %s", field.Name())
 				}
 			}
 		case *types.Slice:
-			switch elemType := ft.Elem().(type) {
-			case *types.Named:
+			if elemType := structType(n, field, ft.Elem()); elemType != nil {
 				if err := t.Traverse(elemType, cfg, append(parentFields, "[]"+field.Name())...); err != nil {
 					return errors.Wrapf(err, "failed to traverse type of field %s", field.Name())
 				}
-			case *types.Pointer:
-				if elemElemType, ok := elemType.Elem().(*types.Named); ok {
+			}
+			if elemType, ok := ft.Elem().(*types.Pointer); ok {
+				if elemElemType := structType(n, field, elemType.Elem()); elemElemType != nil {
 					if err := t.Traverse(elemElemType, cfg, append(parentFields, "[]"+"*"+field.Name())...); err != nil {
 						return errors.Wrapf(err, "failed to traverse type of field %s", field.Name())
 					}
@@ -129,3 +254,117 @@ func (t *Traverser) Traverse(n *types.Named, cfg *ProcessorConfig, parentFields
 	}
 	return nil
 }
+
+// structType returns the supplied type of the elements of the supplied field of
+// the supplied type if it is a named type, a named type for it if it is an
+// anonymous struct type, or nil.
+func structType(n *types.Named, field *types.Var, t types.Type) *types.Named {
+	switch et := t.(type) {
+	case *types.Named:
+		return et
+	case *types.Struct:
+		return anonymous(n, field, et)
+	}
+	return nil
+}
+
+// anonymous returns a named type for the supplied anonymous struct type, which
+// is declared inline as the type of the supplied field of the supplied type,
+// so that processors may treat it like any other struct. It is named
+// <type>.<field> in the package of the supplied type, and has no comment.
+// Anonymous struct types can't refer to themselves, so the Traverser need not
+// recognise them when it visits them again.
+func anonymous(n *types.Named, field *types.Var, st *types.Struct) *types.Named {
+	return types.NewNamed(types.NewTypeName(token.NoPos, n.Obj().Pkg(), n.Obj().Name()+"."+field.Name(), nil), st, nil)
+}
+
+// skip returns true if the type of the supplied field, or of its elements, is
+// skipped and the field is not marked to be traversed anyway.
+func (t *Traverser) skip(field *types.Var) bool {
+	n, ok := elem(field.Type()).(*types.Named)
+	if !ok || n.Obj().Pkg() == nil || !t.skipped[n.Obj().Pkg().Path()+"."+n.Obj().Name()] {
+		return false
+	}
+	_, marked := comments.ParseMarkers(t.comments.For(field))[TraverseMarker]
+	return !marked
+}
+
+// opaque returns true if the supplied type, or the type of its elements, has no
+// fields or values that generated code may use: unsafe.Pointer, uintptr, a C
+// type of cgo, or a type that could not be type checked, like a C type when
+// cgo is disabled.
+func opaque(t types.Type) bool {
+	switch et := elem(t).(type) {
+	case *types.Basic:
+		return et.Kind() == types.UnsafePointer || et.Kind() == types.Uintptr || et.Kind() == types.Invalid
+	case *types.Named:
+		// The files that cgo generates name C types _Ctype_<type>.
+		return strings.HasPrefix(et.Obj().Name(), "_Ctype_") || (et.Obj().Pkg() != nil && et.Obj().Pkg().Path() == "C")
+	}
+	return false
+}
+
+// elem returns the type of the elements of the supplied pointer or slice type,
+// through any number of pointers and slices, or the supplied type.
+func elem(t types.Type) types.Type {
+	for {
+		switch et := t.(type) {
+		case *types.Pointer:
+			t = et.Elem()
+		case *types.Slice:
+			t = et.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+// traverseImplementations traverses each concrete type listed by the
+// ImplementationsMarker of the supplied interface field. The field is added to
+// the parent fields of each as (<type>)<field>.
+func (t *Traverser) traverseImplementations(n *types.Named, field *types.Var, cfg *ProcessorConfig, parentFields ...string) error {
+	iface := field.Type().Underlying().(*types.Interface)
+	for _, v := range comments.ParseMarkers(t.comments.For(field))[ImplementationsMarker] {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			o, ok := n.Obj().Pkg().Scope().Lookup(name).(*types.TypeName)
+			if !ok {
+				return errors.Errorf("cannot find type %s in package %s", name, n.Obj().Pkg().Path())
+			}
+			impl, ok := o.Type().(*types.Named)
+			if !ok {
+				return errors.Errorf("type %s is not a named type", name)
+			}
+			if !types.Implements(types.NewPointer(impl), iface) {
+				return errors.Errorf("*%s does not implement %s", name, types.TypeString(field.Type(), types.RelativeTo(n.Obj().Pkg())))
+			}
+			if err := t.Traverse(impl, cfg, append(parentFields, "("+name+")"+field.Name())...); err != nil {
+				return errors.Wrapf(err, "failed to traverse type %s", name)
+			}
+		}
+	}
+	return nil
+}
+
+// FindPackage returns the package with the supplied path if it is either the
+// supplied package or one of its direct or transitive imports. It returns nil
+// if no such package is found.
+func FindPackage(p *types.Package, path string) *types.Package {
+	return findPackage(p, path, map[*types.Package]bool{})
+}
+
+func findPackage(p *types.Package, path string, seen map[*types.Package]bool) *types.Package {
+	if p == nil || seen[p] {
+		return nil
+	}
+	seen[p] = true
+	if p.Path() == path {
+		return p
+	}
+	for _, i := range p.Imports() {
+		if found := findPackage(i, path, seen); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	gotypes "go/types"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// ParseAllowlist returns the entries of the supplied types allowlist, which has
+// an entry of the form example.org/provider/apis/ec2/v1beta1.VPC per line.
+// Either part of an entry may be a glob, as matched by path.Match, for example
+// example.org/provider/apis/*/v1beta1.*. Blank lines, and comments from a #
+// to the end of a line, are ignored. An error is returned if an entry is not
+// a package path and a type name, or is not a valid glob.
+func ParseAllowlist(data []byte) ([]string, error) {
+	entries := make([]string, 0)
+	for i, line := range strings.Split(string(data), "\n") {
+		if c := strings.Index(line, "#"); c >= 0 {
+			line = line[:c]
+		}
+		entry := strings.TrimSpace(line)
+		if entry == "" {
+			continue
+		}
+		pkg, typ, ok := splitAllowlistEntry(entry)
+		if !ok {
+			return nil, errors.Errorf("line %d: allowlist entry %q must be a package path and a type name, for example example.org/provider/apis/ec2/v1beta1.VPC", i+1, entry)
+		}
+		for _, pattern := range []string{pkg, typ} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, errors.Wrapf(err, "line %d: allowlist entry %q", i+1, entry)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// splitAllowlistEntry returns the package path and type name of the supplied
+// types allowlist entry. The type name follows the last dot, since package
+// paths but not type names may contain dots.
+func splitAllowlistEntry(entry string) (pkg, typ string, ok bool) {
+	dot := strings.LastIndex(entry, ".")
+	if dot <= 0 || dot == len(entry)-1 || strings.Contains(entry[dot:], "/") {
+		return "", "", false
+	}
+	return entry[:dot], entry[dot+1:], true
+}
+
+// allowlisted returns true if the supplied types allowlist entry matches the
+// type with the supplied name of the package with the supplied path.
+func allowlisted(entry, pkgPath, name string) bool {
+	pkg, typ, ok := splitAllowlistEntry(entry)
+	if !ok {
+		return false
+	}
+	pm, _ := path.Match(pkg, pkgPath)
+	tm, _ := path.Match(typ, name)
+	return pm && tm
+}
+
+// isAllowlisted returns true if the supplied object is a type that any entry of
+// the configured types allowlist matches.
+func (c Config) isAllowlisted(o gotypes.Object) bool {
+	if o.Pkg() == nil {
+		return false
+	}
+	for _, entry := range c.Allowlist {
+		if allowlisted(entry, o.Pkg().Path(), o.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAllowlist returns an error listing the entries of the configured
+// types allowlist that match no type of the supplied packages, so that an
+// allowlist maintained by another tool doesn't silently fall out of date.
+func validateAllowlist(cfg Config, pkgs []*packages.Package) error {
+	unmatched := make([]string, 0)
+	for _, entry := range cfg.Allowlist {
+		if !allowlistMatches(entry, pkgs) {
+			unmatched = append(unmatched, entry)
+		}
+	}
+	if len(unmatched) > 0 {
+		return errors.Errorf("allowlist entries match no type of the loaded packages: %s", strings.Join(unmatched, ", "))
+	}
+	return nil
+}
+
+// allowlistMatches returns true if the supplied types allowlist entry matches
+// any type of the supplied packages.
+func allowlistMatches(entry string, pkgs []*packages.Package) bool {
+	for _, p := range pkgs {
+		if p.Types == nil {
+			continue
+		}
+		for _, n := range p.Types.Scope().Names() {
+			if _, ok := p.Types.Scope().Lookup(n).(*gotypes.TypeName); ok && allowlisted(entry, p.PkgPath, n) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+func TestParseAllowlist(t *testing.T) {
+	type want struct {
+		entries []string
+		err     error
+	}
+	cases := map[string]struct {
+		reason string
+		data   string
+		want   want
+	}{
+		"Valid": {
+			reason: "Entries should be parsed, ignoring blank lines and comments.",
+			data: `# Generated by upjet.
+example.org/provider/apis/ec2/v1beta1.VPC
+
+example.org/provider/apis/*/v1beta1.Subnet # Every group.
+example.org/provider/apis/rds/v1beta1.*
+gopkg.in/example.v1.Type
+`,
+			want: want{
+				entries: []string{
+					"example.org/provider/apis/ec2/v1beta1.VPC",
+					"example.org/provider/apis/*/v1beta1.Subnet",
+					"example.org/provider/apis/rds/v1beta1.*",
+					"gopkg.in/example.v1.Type",
+				},
+			},
+		},
+		"Empty": {
+			reason: "An allowlist without entries should allow no types, rather than every type.",
+			data:   "# Nothing yet.\n",
+			want:   want{entries: []string{}},
+		},
+		"NoTypeName": {
+			reason: "An entry without a type name should be an error.",
+			data:   "example.org/provider/apis/ec2/v1beta1.VPC\nexample.org/provider/apis/ec2/v1beta1\n",
+			want: want{
+				err: errors.New(`line 2: allowlist entry "example.org/provider/apis/ec2/v1beta1" must be a package path and a type name, for example example.org/provider/apis/ec2/v1beta1.VPC`),
+			},
+		},
+		"BadGlob": {
+			reason: "An entry that is not a valid glob should be an error.",
+			data:   "example.org/provider/apis/ec2/v1beta1.[VPC\n",
+			want: want{
+				err: errors.Wrap(errors.New("syntax error in pattern"), `line 1: allowlist entry "example.org/provider/apis/ec2/v1beta1.[VPC"`),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			entries, err := ParseAllowlist([]byte(tc.data))
+			if diff := cmp.Diff(tc.want.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nParseAllowlist(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.entries, entries); diff != "" {
+				t.Errorf("\n%s\nParseAllowlist(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAllowlisted(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		entry  string
+		path   string
+		name   string
+		want   bool
+	}{
+		"Exact": {
+			reason: "An entry should match the type it names.",
+			entry:  "example.org/provider/apis/ec2/v1beta1.VPC",
+			path:   "example.org/provider/apis/ec2/v1beta1",
+			name:   "VPC",
+			want:   true,
+		},
+		"OtherType": {
+			reason: "An entry should not match other types of the package it names.",
+			entry:  "example.org/provider/apis/ec2/v1beta1.VPC",
+			path:   "example.org/provider/apis/ec2/v1beta1",
+			name:   "Subnet",
+			want:   false,
+		},
+		"PackageGlob": {
+			reason: "A glob should match any package element.",
+			entry:  "example.org/provider/apis/*/v1beta1.VPC",
+			path:   "example.org/provider/apis/ec2/v1beta1",
+			name:   "VPC",
+			want:   true,
+		},
+		"PackageGlobSeparator": {
+			reason: "A glob should not match a path separator.",
+			entry:  "example.org/provider/apis/*.VPC",
+			path:   "example.org/provider/apis/ec2/v1beta1",
+			name:   "VPC",
+			want:   false,
+		},
+		"TypeGlob": {
+			reason: "A glob should match any type name.",
+			entry:  "example.org/provider/apis/ec2/v1beta1.*",
+			path:   "example.org/provider/apis/ec2/v1beta1",
+			name:   "Subnet",
+			want:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := allowlisted(tc.entry, tc.path, tc.name)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nallowlisted(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
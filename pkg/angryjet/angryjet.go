@@ -0,0 +1,1649 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package angryjet generates Crossplane API type methods.
+package angryjet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	gotypes "go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/crossplane/crossplane-tools/internal/generate"
+	"github.com/crossplane/crossplane-tools/internal/match"
+	"github.com/crossplane/crossplane-tools/internal/method"
+	"github.com/crossplane/crossplane-tools/internal/types"
+	"github.com/crossplane/crossplane-tools/pkg/comments"
+)
+
+const (
+	// LoadMode used to load all packages.
+	LoadMode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedSyntax | packages.NeedModule
+
+	// DisableMarker used to disable generation of managed resource methods for
+	// a type that otherwise appears to be a managed resource that is missing a
+	// subnet of its methods.
+	DisableMarker = "crossplane:generate:methods"
+
+	// SelectorsMarker used to disable selector based reference resolution for
+	// a managed resource, using the value "false".
+	SelectorsMarker = "crossplane:generate:reference:selectors"
+
+	// CacheMarker used to cache the resolutions of the references of a
+	// managed resource across reconciles. Its value is the name of a field of
+	// type map[string]string of the status of the managed resource.
+	CacheMarker = "crossplane:generate:reference:cache"
+
+	// DeprecatedMarker used in the doc comment of a package to deprecate the
+	// version of an API that it contains. Its value, if any, is the path of the
+	// package that replaces it.
+	DeprecatedMarker = "crossplane:deprecated"
+
+	// SuppressMarker used on a field, a managed resource, or in the doc
+	// comment of a package to suppress the lint findings with the IDs of its
+	// comma separated value.
+	SuppressMarker = "crossplane:generate:suppress"
+)
+
+// LoadEnv returns the supplied environment in which to load packages, or that
+// of the current process if it is nil, with cgo disabled unless it sets
+// CGO_ENABLED. Loading packages with cgo enabled runs cgo, and so requires a C
+// compiler, to type check files that import "C". With cgo disabled those files
+// are excluded, and packages such as net use their pure Go implementations.
+func LoadEnv(env []string) []string {
+	if env == nil {
+		env = os.Environ()
+	}
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "CGO_ENABLED=") {
+			return env
+		}
+	}
+	return append(append([]string{}, env...), "CGO_ENABLED=0")
+}
+
+// Imports used in generated code.
+const (
+	CoreAlias  = "corev1"
+	CoreImport = "k8s.io/api/core/v1"
+
+	MetaV1Alias  = "metav1"
+	MetaV1Import = "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ClientAlias  = "client"
+	ClientImport = "sigs.k8s.io/controller-runtime/pkg/client"
+
+	RuntimeAlias  = "xpv1"
+	RuntimeImport = DefaultRuntimeModule + RuntimePackage
+
+	ResourceAlias  = "resource"
+	ResourceImport = DefaultRuntimeModule + ResourcePackage
+
+	ReferenceAlias  = "reference"
+	ReferenceImport = DefaultRuntimeModule + ReferencePackage
+
+	MetaAlias  = "meta"
+	MetaImport = DefaultRuntimeModule + MetaPackage
+
+	FieldPathAlias  = "fieldpath"
+	FieldPathImport = DefaultRuntimeModule + FieldPathPackage
+)
+
+// DefaultRuntimeModule is the path of the crossplane-runtime module.
+const DefaultRuntimeModule = "github.com/crossplane/crossplane-runtime"
+
+// DefaultFailureConditionReason is the reason of the condition that reference
+// resolvers set when they fail, if no other reason is configured.
+const DefaultFailureConditionReason = "ReferenceResolutionFailed"
+
+// Paths of the crossplane-runtime packages used in generated code, relative to
+// the crossplane-runtime module.
+const (
+	RuntimePackage   = "/apis/common/v1"
+	ResourcePackage  = "/pkg/resource"
+	ReferencePackage = "/pkg/reference"
+	MetaPackage      = "/pkg/meta"
+	FieldPathPackage = "/pkg/fieldpath"
+)
+
+// Default filenames of generated files.
+const (
+	DefaultFilenameManaged          = "zz_generated.managed.go"
+	DefaultFilenameManagedList      = "zz_generated.managedlist.go"
+	DefaultFilenamePC               = "zz_generated.pc.go"
+	DefaultFilenamePCU              = "zz_generated.pcu.go"
+	DefaultFilenamePCUList          = "zz_generated.pculist.go"
+	DefaultFilenameResolvers        = "zz_generated.resolvers.go"
+	DefaultFilenameResolvableFields = "zz_generated.resolvablefields.go"
+	DefaultFilenameResolversIndex   = "zz_generated.resolvers_index.go"
+	DefaultFilenameReferenceError   = "zz_generated.reference_error.go"
+	DefaultFilenameFixtures         = "zz_generated.fixtures_test.go"
+)
+
+// A Config configures method set generation. The zero value generates all
+// method sets using the default filenames.
+type Config struct {
+	// Patterns are the packages for which Run generates methods, for example
+	// github.com/crossplane/provider-aws/apis/... They are not used by
+	// Generate, which is supplied a loaded package.
+	Patterns []string
+
+	// Dir is the directory in which Run loads packages. The current directory
+	// is used if it is empty.
+	Dir string
+
+	// Env is the environment in which Run loads packages. The environment of
+	// the current process is used if it is nil. Cgo is disabled unless the
+	// environment sets CGO_ENABLED; see LoadEnv.
+	Env []string
+
+	// Overlay maps the absolute paths of files to the contents that Run
+	// loads them with, rather than those on disk, as with the Overlay of a
+	// packages.Config. Editors may use it to generate methods from the
+	// unsaved contents of their buffers.
+	Overlay map[string][]byte
+
+	// Descriptions describe references of managed resources whose fields
+	// have no reference markers, as if they had the described markers. A
+	// reference resolver is generated from them as from markers, and Run
+	// returns an error if a described package isn't loaded or a described
+	// type or field doesn't exist.
+	Descriptions []Description
+
+	// CRDReferences describe the references of managed resources from the
+	// annotations of their CustomResourceDefinitions, as returned by
+	// ReadCRDReferences. A reference resolver is generated from them as from
+	// Descriptions for each managed resource whose name is the kind of a
+	// definition, in a package whose groupName marker is the group of the
+	// definition and whose path ends in one of its versions. Fields that
+	// have reference markers, or are described by Descriptions, keep them.
+	CRDReferences []CRDReferences
+
+	// Header is added to the top of all generated files.
+	Header string
+
+	// OutputDir is the root of a directory tree, mirroring the packages of
+	// their module, that generated files are written to if it is set.
+	// Generated files are otherwise written alongside the package they are
+	// generated for. Either way they belong to, and import packages as, that
+	// package, so a build system that separates generated code must overlay
+	// them onto its source. Packages that aren't part of a module are
+	// mirrored by their full import path.
+	OutputDir string
+
+	// FilenameManaged is the filename of generated managed resource files.
+	FilenameManaged string
+
+	// FilenameManagedList is the filename of generated managed resource list
+	// files.
+	FilenameManagedList string
+
+	// FilenamePC is the filename of generated provider config files.
+	FilenamePC string
+
+	// FilenamePCU is the filename of generated provider config usage files.
+	FilenamePCU string
+
+	// FilenamePCUList is the filename of generated provider config usage list
+	// files.
+	FilenamePCUList string
+
+	// FilenameResolvers is the filename of generated reference resolver files.
+	FilenameResolvers string
+
+	// FilenameResolvableFields is the filename of generated resolvable field
+	// table files.
+	FilenameResolvableFields string
+
+	// ResolvableFields generates a table of the JSON paths of the fields of
+	// each managed resource that may be resolved from a reference or a
+	// selector, for use in CEL validation rules.
+	ResolvableFields bool
+
+	// FilenameResolversIndex is the filename of generated reference resolver
+	// index files.
+	FilenameResolversIndex string
+
+	// ResolversIndex generates a ResolveReferences function for each package
+	// that resolves the references of any of its managed resources that have
+	// them, and returns a ReferencesNotSupportedError for any other.
+	ResolversIndex bool
+
+	// FilenameFixtures is the filename of generated fixture files. It should
+	// end with _test.go, so that fixtures are only built by tests.
+	FilenameFixtures string
+
+	// Fixtures generates a New<Kind>Fixture function for each managed
+	// resource that returns one whose required spec fields are set to valid
+	// values, and an option for each field of its spec.forProvider, for use
+	// in tests. A managed resource with a required field that can't be set to
+	// a valid value is reported as an error.
+	Fixtures bool
+
+	// FixturesExternal writes fixtures to the external test package of each
+	// package, for example v1alpha1_test, rather than to the package itself.
+	FixturesExternal bool
+
+	// DeprecationRecorder is a function, supplied as <package path>.<name>,
+	// that generated reference resolvers call when a deprecated reference is
+	// used.
+	DeprecationRecorder string
+
+	// ProviderConfigValidator is a function, supplied as <package
+	// path>.<name>, that generated reference resolvers call to check that a
+	// referenced resource uses the same provider config as the referencing
+	// resource, if the reference requires it.
+	ProviderConfigValidator string
+
+	// Tenant is a function, supplied as <package path>.<name>, that generated
+	// reference resolvers call to get the namespace of the tenant from their
+	// context. It must have the signature func(context.Context) string.
+	// References that are not to cluster scoped types are resolved in the
+	// namespace it returns. Run returns an error if it has a different
+	// signature.
+	Tenant string
+
+	// Resolver is a function, supplied as <package path>.<name>, that
+	// generated reference resolvers call to construct the resolver that
+	// they resolve references with, rather than NewAPIResolver of the
+	// crossplane-runtime reference package, for example to resolve them
+	// with an external service. It must have the signature
+	// func(client.Reader, resource.Managed) R, where R is a type of the same
+	// package with the Resolve and ResolveMultiple methods of APIResolver.
+	// Run returns an error if it has a different signature.
+	Resolver string
+
+	// ResolvedValues generates a ResolveReferencesWithValues method for each
+	// managed resource with references, in addition to ResolveReferences. It
+	// returns a map of the path of each resolved field to its resolved value.
+	ResolvedValues bool
+
+	// UpdateResolvers generates only reference resolvers, and replaces only
+	// the ResolveReferences methods, and ResolveReferencesWithValues methods
+	// if ResolvedValues is set, of reference resolver files that already
+	// exist. Other declarations of the files, including other generated
+	// methods if the files are shared with other method sets, are kept.
+	UpdateResolvers bool
+
+	// Banners groups the generated declarations of each type in a file, and
+	// precedes them by a banner comment such as // ===== VPC =====, so that
+	// the changes to each type are localized. This includes methods that
+	// are updated in a file that is shared with other method sets.
+	Banners bool
+
+	// Checksums records a checksum of the contents of each generated file in
+	// a comment on the line after its generated code header, and refuses to
+	// overwrite a generated file whose contents no longer match its recorded
+	// checksum because it was edited. Run reports such files in the
+	// Modified field of its Report, while Generate and its siblings return
+	// an error. Reference resolver files updated by UpdateResolvers are
+	// always overwritten. Unless it is set, generated files that were
+	// edited are overwritten like any other.
+	Checksums bool
+
+	// Force overwrites generated files that Checksums finds were edited.
+	Force bool
+
+	// HubOnly limits generated reference resolvers, and reference resolver
+	// indexes, to managed resources of the storage version of their kind,
+	// marked +kubebuilder:storageversion. This is the hub version that
+	// conversion webhooks convert other versions to, so that they resolve
+	// their references using the resolver of the hub after conversion.
+	HubOnly bool
+
+	// DisableSelectors limits generated reference resolvers of all managed
+	// resources to resolution by name. A selector that is set causes an error.
+	DisableSelectors bool
+
+	// Include limits generation to types whose names match it, if it is set.
+	Include *regexp.Regexp
+
+	// Exclude prevents generation for types whose names match it, if it is
+	// set.
+	Exclude *regexp.Regexp
+
+	// Allowlist limits generation to types that its entries match, if it is
+	// not nil. Entries are of the form
+	// example.org/provider/apis/ec2/v1beta1.VPC, and either part may be a
+	// glob, as returned by ParseAllowlist. Run returns an error if an entry
+	// matches no type of the loaded packages.
+	Allowlist []string
+
+	// MethodSets are the names of the method sets, for example managed and
+	// resolvers, that Generate writes for packages whose paths match each
+	// pattern, for example example.org/provider/apis/ec2/... A pattern ending
+	// in /... matches the package it names and every package below it. If
+	// several patterns match a package the longest wins. Packages that no
+	// pattern matches get every method set, except resolvable field tables
+	// unless ResolvableFields is set and reference resolver indexes unless
+	// ResolversIndex is set. MethodSets returns the known names.
+	MethodSets map[string][]string
+
+	// Only limits the method sets that Generate writes for every package to
+	// those named, if it is set.
+	Only []string
+
+	// Skip names method sets that Generate never writes.
+	Skip []string
+
+	// AccessorVariants names standard accessors of managed resources, for
+	// example GetDeletionPolicy, next to which GenerateManaged writes an
+	// alternate variant for migration periods, for example
+	// GetDeletionPolicyPtr. Variants of accessors that take or return a value
+	// take or return a pointer, and are suffixed Ptr. Those of accessors that
+	// take or return a pointer take or return a value, and are suffixed Value.
+	// A variant is not written for a managed resource whose spec has no field
+	// of the accessed type or a pointer to it, and the standard accessor is
+	// not written once the field has the shape of its variant. Drop an
+	// accessor and regenerate to end its migration period. AccessorVariants
+	// returns the accessors that may be named.
+	AccessorVariants []string
+
+	// ClearSelectors generates reference resolvers that clear the selector of
+	// a reference that was resolved by name.
+	ClearSelectors bool
+
+	// SkipUnchanged is an annotation that generated ResolveReferences methods
+	// store a hash of the references and selectors of a managed resource in,
+	// if it is set. They skip resolution while the hash is unchanged.
+	SkipUnchanged string
+
+	// DependencyAnnotation is an annotation that generated reference
+	// resolvers record the kind and name of each resource they resolved a
+	// reference to in, as a JSON array, if it is set. Controllers may use it
+	// to tell what a managed resource depends on when it is deleted.
+	DependencyAnnotation string
+
+	// WrapWithMessage generates reference resolvers that add the path of a
+	// field to errors returned while resolving it using errors.WithMessage,
+	// rather than errors.Wrap, so that errors that already have a stack
+	// trace don't get another.
+	WrapWithMessage bool
+
+	// ErrorWrapper is a function, for example
+	// example.org/pkg/errors.Reference, that generated reference resolvers
+	// wrap errors returned while resolving a field with its path by, rather
+	// than errors.Wrap. It must have the signature
+	// func(err error, field string) error, and takes precedence over
+	// WrapWithMessage.
+	ErrorWrapper string
+
+	// ReferenceError generates reference resolvers that return errors
+	// returned while resolving a field as a ReferenceError of its path and
+	// the kind it references, so that controllers may tell them apart using
+	// errors.As. The ReferenceError type is generated once for each package,
+	// and takes precedence over ErrorWrapper and WrapWithMessage.
+	ReferenceError bool
+
+	// FilenameReferenceError is the filename of generated ReferenceError
+	// type files.
+	FilenameReferenceError string
+
+	// SkipEmpty generates reference resolvers that skip fields whose
+	// reference and selector are both unset, rather than resolving them to
+	// their current values.
+	SkipEmpty bool
+
+	// FailureConditionType is the type of a condition, for example
+	// ReferencesResolved, that generated reference resolvers set on a
+	// managed resource with a status of False before they return an error,
+	// if it is set. The message of the condition is the error.
+	FailureConditionType string
+
+	// FailureConditionReason is the reason of the condition set by
+	// FailureConditionType. It defaults to DefaultFailureConditionReason.
+	FailureConditionReason string
+
+	// Clock is a package-level value, for example
+	// example.org/pkg/clock.Clock, whose Now method generated reference
+	// resolvers call to tell the time, rather than the time package, if it
+	// is set. Its Now method must have the signature func() time.Time. Tests
+	// may replace it to control timeouts and failure conditions.
+	Clock string
+
+	// Transactional generates reference resolvers that resolve references
+	// in a deep copy of a managed resource, and replace the managed resource
+	// with the copy only if every reference is resolved, so that none of
+	// its fields are resolved if any reference cannot be. Managed resources
+	// must have a DeepCopy method, as generated by controller-gen.
+	Transactional bool
+
+	// Retry is the path of a package, for example example.org/pkg/retry,
+	// whose OnError function generated reference resolvers wrap each
+	// resolution in, so that resolutions that fail transiently are retried,
+	// if it is set. See method.WithRetry for its signature. Resolutions are
+	// retried with the package's DefaultBackoff, unless any of RetryDuration,
+	// RetryFactor, or RetrySteps is set.
+	Retry string
+
+	// RetryDuration is how long the first retry of a resolution waits.
+	RetryDuration time.Duration
+
+	// RetryFactor multiplies how long each retry after the first waits.
+	RetryFactor float64
+
+	// RetrySteps is how many times a resolution is tried at most.
+	RetrySteps int
+
+	// ResolverLogging is the path of a package, for example
+	// example.org/pkg/logging, whose FromContext function generated reference
+	// resolvers call to get a logger from their context. They log each field
+	// they resolve at debug level. FromContext must have the signature
+	// func(context.Context) L, where L has the method
+	// Debug(msg string, keysAndValues ...interface{}).
+	ResolverLogging string
+
+	// Provenance is the path of a package, for example
+	// example.org/pkg/provenance, whose Record and RecordMultiple functions
+	// generated reference resolvers call after resolving each field, so
+	// that where its value came from may be recorded in an annotation of
+	// the managed resource. See method.WithProvenance for their signatures.
+	Provenance string
+
+	// ResolverAssertions precedes each generated ResolveReferences method by
+	// an assertion that its receiver implements the Managed interface of
+	// crossplane-runtime, so that drift between the interface and the
+	// managed resource fails to compile where its resolver is generated.
+	ResolverAssertions bool
+
+	// ControllerRuntime generates reference resolvers that get and list the
+	// referenced resources using their controller-runtime client directly,
+	// rather than using the crossplane-runtime reference package. They
+	// support references of single values and slices only, and cannot be
+	// combined with ResolvedValues, ResolverLogging, or Provenance.
+	ControllerRuntime bool
+
+	// Verbose makes Run report warnings that are only informational, such as
+	// fields with references that are not resolved because they are shadowed
+	// by less deeply embedded fields with the same promoted path.
+	Verbose bool
+
+	// MaxDepth is the maximum number of fields deep that the types of managed
+	// resources are traversed to find references, if it is not zero. Fields
+	// of deeper types are not resolved, and Run reports a warning for each.
+	MaxDepth int
+
+	// RuntimeLevel is the crossplane-runtime API level that generated code
+	// targets: RuntimeLevelLatest, RuntimeLevelAuto, or a version such as
+	// v0.19. Methods and resolver features that the level lacks are not
+	// generated. The latest level is targeted if it is empty.
+	RuntimeLevel string
+
+	// RuntimeModule is the path of the crossplane-runtime module, for example
+	// example.org/provider/internal/thirdparty/crossplane-runtime for a
+	// provider that forks it, whose packages generated code imports and whose
+	// types are checked for, if it is set. Otherwise it is discovered for
+	// each package from the ResourceSpec, ResourceStatus, or provider config
+	// types that its spec and status types embed, and is
+	// DefaultRuntimeModule if they embed none. DetectRuntime and the check
+	// of the configured resolver function, which are not done for a
+	// package, use DefaultRuntimeModule if it is not set.
+	RuntimeModule string
+
+	// Logf is called to log what was decided while generating, for example
+	// the detected runtime level, if it is set.
+	Logf func(format string, args ...interface{})
+
+	// Transform is called with the rendered contents of each generated file,
+	// and returns the contents to be written instead.
+	Transform func(filename string, data []byte) ([]byte, error)
+
+	// Write is called to write each generated file. Files are written to disk
+	// if it is nil.
+	Write func(filename string, data []byte) error
+
+	// Remove is called to remove each previously generated file that would
+	// now be empty. Files are removed from disk if it is nil.
+	Remove func(filename string) error
+
+	// ctx, recover, modified, and generated are set by Run, methodSet by
+	// Generate, and runtime by DetectRuntime.
+	ctx       context.Context
+	recover   func(filename string, o gotypes.Object, recovered interface{}, stack []byte)
+	modified  func(filename string)
+	generated func(methodSet string, o gotypes.Object)
+	methodSet string
+	runtime   *runtimeFeatures
+}
+
+// cacheField returns the field of the status of the supplied managed resource
+// that its CacheMarker names, if any, read from the supplied comments.
+func cacheField(comm comments.Comments, o gotypes.Object) string {
+	for _, c := range []string{comm.For(o), comm.Before(o)} {
+		if v := comments.ParseMarkers(c)[CacheMarker]; len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// commentsIn returns the comments of the supplied package that may hold the
+// markers read by the generators. The comments of files without a crossplane
+// or kubebuilder marker are skipped, which saves recording the positions of
+// comments of the many files of a large package that have none.
+func commentsIn(p *packages.Package) comments.Comments {
+	return comments.In(p, comments.WithFilter(
+		comments.DefaultMarkerPrefix+"crossplane:generate",
+		comments.DefaultMarkerPrefix+"kubebuilder:",
+	))
+}
+
+// matcher returns a Matcher that matches the supplied kind of type, unless it
+// is excluded by this Config or by DisableMarker.
+func (c Config) matcher(p *packages.Package, kind match.Matcher) match.Matcher {
+	m := []match.Matcher{kind, match.DoesNotHaveMarker(commentsIn(p), DisableMarker, "false")}
+	if c.Include != nil {
+		m = append(m, match.NameMatches(c.Include))
+	}
+	if c.Exclude != nil {
+		m = append(m, match.Not(match.NameMatches(c.Exclude)))
+	}
+	if c.Allowlist != nil {
+		m = append(m, match.Func("allowlisted type", c.isAllowlisted))
+	}
+	return match.And(m...)
+}
+
+// resolversMatcher returns a Matcher of the managed resources of the supplied
+// package that reference resolvers are generated for, whose markers are read
+// from the supplied comments.
+func (c Config) resolversMatcher(p *packages.Package, comm comments.Comments) match.Matcher {
+	m := c.matcher(p, match.Managed())
+	if c.HubOnly {
+		return match.And(m, match.StorageVersion(comm))
+	}
+	return m
+}
+
+// traverser returns a Traverser of the types of the supplied package, with the
+// supplied comments, that stops at the configured maximum depth, and skips the
+// ResourceSpec and ResourceStatus that managed resources embed unless they're
+// marked. It is further configured by the supplied options.
+func (c Config) traverser(p *packages.Package, comm comments.Comments, fn types.DepthExceededFn, o ...types.TraverserOption) *types.Traverser {
+	rt := c.runtimeImports(p).Runtime
+	return types.NewTraverser(comm, append([]types.TraverserOption{types.WithMaxDepth(c.MaxDepth, fn), types.WithSkippedTypes(rt+".ResourceSpec", rt+".ResourceStatus")}, o...)...)
+}
+
+func (c Config) withDefaults() Config {
+	defaults := map[*string]string{
+		&c.FilenameManaged:          DefaultFilenameManaged,
+		&c.FilenameManagedList:      DefaultFilenameManagedList,
+		&c.FilenamePC:               DefaultFilenamePC,
+		&c.FilenamePCU:              DefaultFilenamePCU,
+		&c.FilenamePCUList:          DefaultFilenamePCUList,
+		&c.FilenameResolvers:        DefaultFilenameResolvers,
+		&c.FilenameResolvableFields: DefaultFilenameResolvableFields,
+		&c.FilenameResolversIndex:   DefaultFilenameResolversIndex,
+		&c.FilenameReferenceError:   DefaultFilenameReferenceError,
+		&c.FilenameFixtures:         DefaultFilenameFixtures,
+	}
+	for field, d := range defaults {
+		if *field == "" {
+			*field = d
+		}
+	}
+	return c
+}
+
+// filename returns the path of the generated file with the supplied name for
+// the supplied package.
+func (c Config) filename(p *packages.Package, name string) string {
+	if c.OutputDir == "" {
+		return filepath.Join(filepath.Dir(p.GoFiles[0]), name)
+	}
+	rel := p.PkgPath
+	if p.Module != nil {
+		rel = strings.TrimPrefix(strings.TrimPrefix(p.PkgPath, p.Module.Path), "/")
+	}
+	return filepath.Join(c.OutputDir, filepath.FromSlash(rel), name)
+}
+
+func (c Config) writeOptions() []generate.WriteOption {
+	wo := []generate.WriteOption{generate.WithHeaders(c.Header)}
+	if c.Transform != nil {
+		wo = append(wo, generate.WithTransform(c.Transform))
+	}
+	if c.Write != nil {
+		wo = append(wo, generate.WithWriter(c.Write))
+	}
+	if c.Remove != nil {
+		wo = append(wo, generate.WithRemover(c.Remove))
+	}
+	if c.ctx != nil {
+		wo = append(wo, generate.WithContext(c.ctx))
+	}
+	if c.recover != nil {
+		wo = append(wo, generate.WithRecover(c.recover))
+	}
+	if c.Banners {
+		wo = append(wo, generate.WithBanners())
+	}
+	if c.Checksums {
+		wo = append(wo, generate.WithChecksums())
+	}
+	if c.Force {
+		wo = append(wo, generate.WithOverwriteModified())
+	}
+	if c.modified != nil {
+		wo = append(wo, generate.WithModified(c.modified))
+	}
+	if c.generated != nil {
+		wo = append(wo, generate.WithGenerated(func(_ string, o gotypes.Object) { c.generated(c.methodSet, o) }))
+	}
+	return wo
+}
+
+// A Report describes the methods generated by Run.
+type Report struct {
+	// Packages are the paths of the packages for which methods were
+	// generated.
+	Packages []string
+
+	// Errors are the types for which methods could not be generated. Methods
+	// were generated for all other types.
+	Errors []TypeError
+
+	// Warnings are the types for which methods were generated that may not
+	// be usable as intended.
+	Warnings []TypeWarning
+
+	// MethodSets are the names of the method sets that were generated for
+	// each package, by package path.
+	MethodSets map[string][]string
+
+	// Types are the method sets that were generated for each type, which
+	// may be fewer than those of its package, because a method set only
+	// applies to some kinds of type, or because the type was not selected
+	// for it.
+	Types []TypeMethodSets
+
+	// Modified are the generated files that were not overwritten because
+	// they were edited after they were generated, if Checksums is set.
+	Modified []string
+}
+
+// A TypeMethodSets describes the method sets that were generated for a type.
+type TypeMethodSets struct {
+	// Package is the path of the package that defines the type.
+	Package string
+
+	// Type is the name of the type.
+	Type string
+
+	// MethodSets are the names of the method sets that were generated for
+	// the type, in the order they were generated.
+	MethodSets []string
+}
+
+// A TypeWarning describes a type for which methods were generated that may not
+// be usable as intended.
+type TypeWarning struct {
+	// Package is the path of the package that defines the type.
+	Package string
+
+	// Type is the name of the type.
+	Type string
+
+	// Message describes the problem.
+	Message string
+}
+
+func (w TypeWarning) String() string {
+	return fmt.Sprintf("type %s in package %s: %s", w.Type, w.Package, w.Message)
+}
+
+// warnings returns a warning for each managed resource in the supplied package
+// that is not exported. Its generated methods are exported, so that it
+// satisfies resource.Managed, but other packages can only call them through
+// that interface. It also returns a warning for each field of a managed
+// resource that may be resolved from a reference but is not serialized to
+// JSON, because the JSON path of the field will use its Go name, and for each
+// type of a managed resource that is deeper than the maximum depth, and for
+// each field of an opaque type, like unsafe.Pointer, whose markers are ignored.
+// If verbose, it also returns a warning for each field with a reference that
+// is shadowed.
+func warnings(p *packages.Package, cfg Config) []TypeWarning {
+	w := make([]TypeWarning, 0)
+	m := cfg.matcher(p, match.Managed())
+	var o gotypes.Object
+	t := cfg.traverser(p, commentsIn(p), func(n *gotypes.Named, parentFields ...string) {
+		w = append(w, TypeWarning{
+			Package: p.PkgPath,
+			Type:    o.Name(),
+			Message: fmt.Sprintf("type %s of field %s is deeper than the maximum depth of %d, so references of its fields are not resolved", n.Obj().Name(), method.GoPath(parentFields...), cfg.MaxDepth),
+		})
+	}, types.WithOpaqueFields(func(_ *gotypes.Named, f *gotypes.Var, parentFields ...string) {
+		w = append(w, TypeWarning{
+			Package: p.PkgPath,
+			Type:    o.Name(),
+			Message: fmt.Sprintf("field %s is of opaque type %s, so its markers are ignored", method.GoPath(append(parentFields, f.Name())...), gotypes.TypeString(f.Type(), gotypes.RelativeTo(p.Types))),
+		})
+	}))
+	for _, n := range p.Types.Scope().Names() {
+		o = p.Types.Scope().Lookup(n)
+		if !m.Match(o) {
+			continue
+		}
+		if !o.Exported() {
+			w = append(w, TypeWarning{
+				Package: p.PkgPath,
+				Type:    o.Name(),
+				Message: "managed resource is not exported, so its generated methods can only be called from other packages through interfaces such as resource.Managed",
+			})
+		}
+		named, ok := o.Type().(*gotypes.Named)
+		if !ok {
+			continue
+		}
+		// Errors traversing the type are reported when its methods are
+		// generated.
+		fields, _ := method.UnserializedFields(t, cfg.runtimeImports(p).Runtime, named)
+		for _, f := range fields {
+			w = append(w, TypeWarning{
+				Package: p.PkgPath,
+				Type:    o.Name(),
+				Message: fmt.Sprintf("field %s is used to resolve a reference but is tagged json:\"-\", so its JSON path uses its Go name", f),
+			})
+		}
+		if !cfg.Verbose {
+			continue
+		}
+		// Types deeper than the maximum depth were already warned about.
+		dups, _ := method.Duplicates(cfg.traverser(p, commentsIn(p), nil), cfg.runtimeImports(p).Runtime, named)
+		for _, d := range dups {
+			w = append(w, TypeWarning{
+				Package: p.PkgPath,
+				Type:    o.Name(),
+				Message: fmt.Sprintf("field %s is not resolved because it is shadowed by field %s, which is embedded less deeply", d.Field, d.ShadowedBy),
+			})
+		}
+	}
+	return w
+}
+
+// A TypeError describes a panic that occurred while generating a file for a
+// type.
+type TypeError struct {
+	// Package is the path of the package that defines the type.
+	Package string
+
+	// Type is the name of the type.
+	Type string
+
+	// Filename is the name of the file that was being generated.
+	Filename string
+
+	// Message describes the panic.
+	Message string
+
+	// Stack is the stack trace of the panic. It is empty if the panic was an
+	// error with which a method generator reported that the type is invalid,
+	// for example because of a mistake in its markers, rather than a bug.
+	Stack string
+}
+
+func (e TypeError) Error() string {
+	return fmt.Sprintf("cannot generate %s for type %s in package %s: %s", e.Filename, e.Type, e.Package, e.Message)
+}
+
+// Run loads the packages matching the configured patterns and writes all
+// method sets for each of them. It stops, returning an error, if the supplied
+// context is cancelled. A panic that occurs while generating methods for a
+// type is recorded in the returned Report, and methods for all other types are
+// still generated. References to types in packages that are deprecated are
+// warned of in the Report, as Lint finds them.
+func Run(ctx context.Context, cfg Config) (Report, error) {
+	r := Report{}
+
+	cfg, err := DetectRuntime(ctx, cfg)
+	if err != nil {
+		return r, err
+	}
+	if err := validateRuntime(cfg); err != nil {
+		return r, err
+	}
+	if err := validateTenant(ctx, cfg); err != nil {
+		return r, err
+	}
+	if err := validateResolver(ctx, cfg); err != nil {
+		return r, err
+	}
+	if err := validateMethodSets(cfg); err != nil {
+		return r, err
+	}
+	if err := validateAccessorVariants(cfg); err != nil {
+		return r, err
+	}
+
+	pkgs, err := packages.Load(&packages.Config{Context: ctx, Mode: LoadMode, Dir: cfg.Dir, Env: LoadEnv(cfg.Env), Overlay: cfg.Overlay}, cfg.Patterns...)
+	if err != nil {
+		return r, errors.Wrapf(err, "cannot load packages %v", cfg.Patterns)
+	}
+	if err := validateDescriptions(cfg, pkgs); err != nil {
+		return r, err
+	}
+	if err := validateAllowlist(cfg, pkgs); err != nil {
+		return r, err
+	}
+	if err := validateFieldSelectors(ctx, cfg, pkgs); err != nil {
+		return r, err
+	}
+	deprecated, err := deprecations(ctx, cfg, pkgs)
+	if err != nil {
+		return r, err
+	}
+
+	for _, p := range pkgs {
+		if err := ctx.Err(); err != nil {
+			return r, errors.Wrap(err, "generation was cancelled")
+		}
+		if len(p.Errors) > 0 {
+			return r, errors.Wrapf(p.Errors[0], "cannot load package %s", p.PkgPath)
+		}
+
+		r.Warnings = append(r.Warnings, warnings(p, cfg)...)
+		r.Warnings = append(r.Warnings, deprecated[p.PkgPath]...)
+
+		c := cfg
+		c.ctx = ctx
+		c.recover = func(filename string, o gotypes.Object, recovered interface{}, stack []byte) {
+			r.Errors = append(r.Errors, TypeError{
+				Package:  p.PkgPath,
+				Type:     o.Name(),
+				Filename: filepath.Base(filename),
+				Message:  fmt.Sprint(recovered),
+				Stack:    bugStack(recovered, stack),
+			})
+		}
+		c.modified = func(filename string) {
+			r.Modified = append(r.Modified, filename)
+		}
+		types := map[string][]string{}
+		c.generated = func(methodSet string, o gotypes.Object) {
+			sets := types[o.Name()]
+			if len(sets) == 0 || sets[len(sets)-1] != methodSet {
+				types[o.Name()] = append(sets, methodSet)
+			}
+		}
+		if err := generateRecovered(p, c); err != nil {
+			return r, err
+		}
+		names := make([]string, 0, len(types))
+		for n := range types {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			r.Types = append(r.Types, TypeMethodSets{Package: p.PkgPath, Type: n, MethodSets: types[n]})
+		}
+		r.Packages = append(r.Packages, p.PkgPath)
+		if r.MethodSets == nil {
+			r.MethodSets = map[string][]string{}
+		}
+		r.MethodSets[p.PkgPath] = cfg.methodSetsFor(p.PkgPath)
+	}
+
+	return r, nil
+}
+
+// validateTenant returns an error if the configured tenant function cannot be
+// found, or does not have the signature func(context.Context) string. The
+// signature is checked syntactically, so that the package of the function need
+// not be type-checked.
+func validateTenant(ctx context.Context, cfg Config) error {
+	if cfg.Tenant == "" {
+		return nil
+	}
+	fn, err := findFunc(ctx, cfg, "tenant function", cfg.Tenant)
+	if err != nil {
+		return err
+	}
+	if !isTenantSignature(fn.file, fn.decl.Type) {
+		return errors.Errorf("tenant function %s must have the signature func(context.Context) string, not %s", cfg.Tenant, fn.signature())
+	}
+	return nil
+}
+
+// validateResolver returns an error if the configured resolver function cannot
+// be found, or does not have the signature func(client.Reader,
+// resource.Managed) R, where R is a type of the same package whose Resolve
+// and ResolveMultiple methods have the signatures of those of APIResolver.
+// Like the tenant function, it is checked syntactically.
+func validateResolver(ctx context.Context, cfg Config) error {
+	if cfg.Resolver == "" {
+		return nil
+	}
+	fn, err := findFunc(ctx, cfg, "resolver function", cfg.Resolver)
+	if err != nil {
+		return err
+	}
+	params, results := fieldTypes(fn.decl.Type.Params), fieldTypes(fn.decl.Type.Results)
+	if len(params) != 2 || len(results) != 1 ||
+		!isQualified(fn.file, params[0], ClientImport, "Reader") ||
+		!isQualified(fn.file, params[1], runtimeImportsOf(cfg.runtimeModule()).Resource, "Managed") {
+		return errors.Errorf("resolver function %s must have the signature func(client.Reader, resource.Managed) R, not %s", cfg.Resolver, fn.signature())
+	}
+	r := results[0]
+	if star, ok := r.(*ast.StarExpr); ok {
+		r = star.X
+	}
+	id, ok := r.(*ast.Ident)
+	if !ok {
+		return errors.Errorf("resolver function %s must return a type declared in package %s, not %s", cfg.Resolver, fn.path, fn.signature())
+	}
+	methods := methodsOf(fn.files, id.Name)
+	for _, m := range []struct{ name, request, response string }{
+		{name: "Resolve", request: "ResolutionRequest", response: "ResolutionResponse"},
+		{name: "ResolveMultiple", request: "MultiResolutionRequest", response: "MultiResolutionResponse"},
+	} {
+		sig, ok := methods[m.name]
+		if !ok {
+			return errors.Errorf("resolver %s returned by %s has no %s method", id.Name, cfg.Resolver, m.name)
+		}
+		if !isResolveSignature(sig.file, sig.typ, runtimeImportsOf(cfg.runtimeModule()).Reference, m.request, m.response) {
+			return errors.Errorf("method %s of resolver %s must have the signature func(context.Context, reference.%s) (reference.%s, error)", m.name, id.Name, m.request, m.response)
+		}
+	}
+	return nil
+}
+
+// validateFieldSelectors returns an error if a reference of a managed resource
+// of the supplied packages has a field selector, but the resolution requests of
+// the crossplane-runtime reference package that its resolver uses have no
+// FieldSelector field. Like the resolver, the reference package is checked
+// syntactically. Errors loading the supplied packages are reported by Run.
+func validateFieldSelectors(ctx context.Context, cfg Config, pkgs []*packages.Package) error {
+	// The first field with a field selector, by the reference package that
+	// resolves it.
+	fields := map[string]string{}
+	for _, p := range pkgs {
+		resolvers := false
+		for _, n := range cfg.methodSetsFor(p.PkgPath) {
+			resolvers = resolvers || n == MethodSetResolvers
+		}
+		if len(p.Errors) > 0 || !resolvers {
+			continue
+		}
+		// Errors describing references are reported when reference
+		// resolvers are generated.
+		comm, err := cfg.comments(p)
+		if err != nil {
+			continue
+		}
+		rt := cfg.runtimeImports(p)
+		m := cfg.matcher(p, match.Managed())
+		t := cfg.traverser(p, comm, nil)
+		for _, n := range p.Types.Scope().Names() {
+			o := p.Types.Scope().Lookup(n)
+			named, ok := o.Type().(*gotypes.Named)
+			if !ok || !m.Match(o) {
+				continue
+			}
+			// Errors traversing the type are reported when its methods
+			// are generated.
+			refs, _ := method.References(t, rt.Runtime, named)
+			for _, ref := range refs {
+				if _, ok := fields[rt.Reference]; !ok && ref.FieldSelector != "" {
+					fields[rt.Reference] = o.Name() + "." + method.GoPath(ref.GoValueFieldPath[1:]...)
+				}
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	loaded, err := packages.Load(&packages.Config{Context: ctx, Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax, Dir: cfg.Dir, Env: LoadEnv(cfg.Env), Overlay: cfg.Overlay}, paths...)
+	if err != nil {
+		return errors.Wrapf(err, "cannot load reference packages %v to check field selectors", paths)
+	}
+	for _, p := range loaded {
+		if len(p.Errors) > 0 {
+			return errors.Wrapf(p.Errors[0], "cannot load reference package %s to check field selectors", p.PkgPath)
+		}
+		for _, typ := range []string{"ResolutionRequest", "MultiResolutionRequest"} {
+			if !hasField(p.Syntax, typ, "FieldSelector") {
+				return errors.Errorf("%s has a field selector, but %s.%s has no FieldSelector field", fields[p.PkgPath], p.PkgPath, typ)
+			}
+		}
+	}
+	return nil
+}
+
+// A foundFunc is a function found by findFunc.
+type foundFunc struct {
+	fset  *token.FileSet
+	path  string
+	files []*ast.File
+	file  *ast.File
+	decl  *ast.FuncDecl
+}
+
+// signature returns the type of the function as it is declared.
+func (f foundFunc) signature() string {
+	sig := &bytes.Buffer{}
+	_ = printer.Fprint(sig, f.fset, f.decl.Type)
+	return sig.String()
+}
+
+// findFunc parses the package of the supplied function, supplied as <package
+// path>.<name>, and returns its declaration. The supplied description of the
+// function is used in errors. The package is not type-checked.
+func findFunc(ctx context.Context, cfg Config, what, fn string) (foundFunc, error) {
+	i := strings.LastIndex(fn, ".")
+	if i < 0 {
+		return foundFunc{}, errors.Errorf("%s %s is not supplied as <package path>.<name>", what, fn)
+	}
+	path, name := fn[:i], fn[i+1:]
+	fset := token.NewFileSet()
+	pkgs, err := packages.Load(&packages.Config{Context: ctx, Fset: fset, Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax, Dir: cfg.Dir, Env: LoadEnv(cfg.Env), Overlay: cfg.Overlay}, path)
+	if err != nil {
+		return foundFunc{}, errors.Wrapf(err, "cannot load package %s of %s", path, what)
+	}
+	if len(pkgs) != 1 || len(pkgs[0].Errors) > 0 {
+		return foundFunc{}, errors.Errorf("cannot load package %s of %s", path, what)
+	}
+	for _, f := range pkgs[0].Syntax {
+		for _, d := range f.Decls {
+			fd, ok := d.(*ast.FuncDecl)
+			if !ok || fd.Recv != nil || fd.Name.Name != name {
+				continue
+			}
+			return foundFunc{fset: fset, path: path, files: pkgs[0].Syntax, file: f, decl: fd}, nil
+		}
+	}
+	return foundFunc{}, errors.Errorf("cannot find %s %s in package %s", what, name, path)
+}
+
+// A methodType is the type of a method, and the file that declares it.
+type methodType struct {
+	file *ast.File
+	typ  *ast.FuncType
+}
+
+// methodsOf returns the methods of the named type declared by the supplied
+// files, by name. The methods of an interface type are those it declares.
+func methodsOf(files []*ast.File, typ string) map[string]methodType {
+	methods := map[string]methodType{}
+	for _, f := range files {
+		for _, d := range f.Decls {
+			switch d := d.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil || len(d.Recv.List) != 1 {
+					continue
+				}
+				recv := d.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if id, ok := recv.(*ast.Ident); ok && id.Name == typ {
+					methods[d.Name.Name] = methodType{file: f, typ: d.Type}
+				}
+			case *ast.GenDecl:
+				for _, s := range d.Specs {
+					ts, ok := s.(*ast.TypeSpec)
+					if !ok || ts.Name.Name != typ {
+						continue
+					}
+					it, ok := ts.Type.(*ast.InterfaceType)
+					if !ok {
+						continue
+					}
+					for _, m := range it.Methods.List {
+						ft, ok := m.Type.(*ast.FuncType)
+						if !ok || len(m.Names) != 1 {
+							continue
+						}
+						methods[m.Names[0].Name] = methodType{file: f, typ: ft}
+					}
+				}
+			}
+		}
+	}
+	return methods
+}
+
+// isResolveSignature returns true if the supplied function type, declared in
+// the supplied file, is func(context.Context, reference.<request>)
+// (reference.<response>, error), where reference is the package with the
+// supplied path.
+func isResolveSignature(f *ast.File, ft *ast.FuncType, reference, request, response string) bool {
+	params, results := fieldTypes(ft.Params), fieldTypes(ft.Results)
+	if len(params) != 2 || len(results) != 2 {
+		return false
+	}
+	errorType, ok := results[1].(*ast.Ident)
+	return isQualified(f, params[0], "context", "Context") &&
+		isQualified(f, params[1], reference, request) &&
+		isQualified(f, results[0], reference, response) &&
+		ok && errorType.Name == "error"
+}
+
+// fieldTypes returns the type of each of the supplied fields, repeated for
+// each of their names.
+func fieldTypes(fl *ast.FieldList) []ast.Expr {
+	if fl == nil {
+		return nil
+	}
+	exprs := make([]ast.Expr, 0, fl.NumFields())
+	for _, f := range fl.List {
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			exprs = append(exprs, f.Type)
+		}
+	}
+	return exprs
+}
+
+// isQualified returns true if the supplied expression, in the supplied file,
+// refers to the named declaration of the package with the supplied path.
+func isQualified(f *ast.File, x ast.Expr, path, name string) bool {
+	sel, ok := x.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	id, ok := sel.X.(*ast.Ident)
+	return ok && id.Name == importName(f, path)
+}
+
+// isTenantSignature returns true if the supplied function type, declared in
+// the supplied file, is func(context.Context) string.
+func isTenantSignature(f *ast.File, ft *ast.FuncType) bool {
+	if ft.Params.NumFields() != 1 || ft.Results.NumFields() != 1 {
+		return false
+	}
+	if !isQualified(f, ft.Params.List[0].Type, "context", "Context") {
+		return false
+	}
+	result, ok := ft.Results.List[0].Type.(*ast.Ident)
+	return ok && result.Name == "string"
+}
+
+// importName returns the name that the supplied file refers to the supplied
+// import path by, or an empty string if the file does not import it.
+func importName(f *ast.File, path string) string {
+	for _, i := range f.Imports {
+		if strings.Trim(i.Path.Value, `"`) != path {
+			continue
+		}
+		if i.Name != nil {
+			return i.Name.Name
+		}
+		return filepath.Base(path)
+	}
+	return ""
+}
+
+// bugStack returns the supplied stack trace of a recovered panic, unless the
+// panic was an error with which a method generator reported an invalid type.
+// Runtime errors, such as nil pointer dereferences, are bugs.
+func bugStack(recovered interface{}, stack []byte) string {
+	if err, ok := recovered.(error); ok {
+		if _, bug := err.(runtime.Error); !bug {
+			return ""
+		}
+	}
+	return string(stack)
+}
+
+// generateRecovered calls Generate, returning an error if it panics outside
+// the generation of any one type.
+func generateRecovered(p *packages.Package, cfg Config) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = errors.Errorf("cannot generate methods for package %s: %v\n%s", p.PkgPath, recovered, debug.Stack())
+		}
+	}()
+	return Generate(p, cfg)
+}
+
+// Generate writes the method sets that the supplied Config selects for the
+// supplied package; by default all of them.
+func Generate(p *packages.Package, cfg Config) error {
+	cfg = cfg.withDefaults()
+	if err := validateMethodSets(cfg); err != nil {
+		return err
+	}
+	selected := map[string]bool{}
+	for _, n := range cfg.methodSetsFor(p.PkgPath) {
+		selected[n] = true
+	}
+	for _, ms := range methodSets {
+		if !selected[ms.name] {
+			continue
+		}
+		cfg.methodSet = ms.name
+		if err := ms.generate(p, cfg); err != nil {
+			return errors.Wrapf(err, "cannot write %s for package %s", ms.what, p.PkgPath)
+		}
+	}
+	return nil
+}
+
+// GenerateManaged generates the resource.Managed method set.
+func GenerateManaged(p *packages.Package, cfg Config) error {
+	cfg = cfg.withDefaults()
+	rt := cfg.runtimeImports(p)
+	receiver := "mg"
+
+	methods := method.Set{
+		"SetConditions":                       method.NewSetConditions(receiver, rt.Runtime),
+		"GetCondition":                        method.NewGetCondition(receiver, rt.Runtime),
+		"GetProviderReference":                method.NewGetProviderReference(receiver, rt.Runtime),
+		"SetProviderReference":                method.NewSetProviderReference(receiver, rt.Runtime),
+		"GetProviderConfigReference":          method.NewGetProviderConfigReference(receiver, rt.Runtime),
+		"SetProviderConfigReference":          method.NewSetProviderConfigReference(receiver, rt.Runtime),
+		"SetWriteConnectionSecretToReference": method.NewSetWriteConnectionSecretToReference(receiver, rt.Runtime),
+		"GetWriteConnectionSecretToReference": method.NewGetWriteConnectionSecretToReference(receiver, rt.Runtime),
+		"SetPublishConnectionDetailsTo":       method.NewSetPublishConnectionDetailsTo(receiver, rt.Runtime),
+		"GetPublishConnectionDetailsTo":       method.NewGetPublishConnectionDetailsTo(receiver, rt.Runtime),
+		"SetDeletionPolicy":                   method.NewSetDeletionPolicy(receiver, rt.Runtime),
+		"GetDeletionPolicy":                   method.NewGetDeletionPolicy(receiver, rt.Runtime),
+	}
+	if err := validateAccessorVariants(cfg); err != nil {
+		return err
+	}
+	variants := map[string]bool{}
+	for _, n := range cfg.AccessorVariants {
+		variants[n] = true
+	}
+	for _, a := range accessors {
+		if variants[a.Name] {
+			methods[a.Name] = method.NewAccessor(methods[a.Name], rt.Runtime, a)
+			methods[a.Variant()] = method.NewAccessorVariant(receiver, rt.Runtime, a)
+		}
+	}
+	if !cfg.features().PublishConnectionDetailsTo {
+		delete(methods, "SetPublishConnectionDetailsTo")
+		delete(methods, "GetPublishConnectionDetailsTo")
+		delete(methods, "SetPublishConnectionDetailsToValue")
+		delete(methods, "GetPublishConnectionDetailsToValue")
+	}
+
+	err := generate.WriteMethods(p, methods, cfg.filename(p, cfg.FilenameManaged),
+		append(cfg.writeOptions(),
+			generate.WithImportAliases(map[string]string{
+				CoreImport:    CoreAlias,
+				rt.Runtime: RuntimeAlias,
+			}),
+			generate.WithMatcher(cfg.matcher(p, match.Managed())),
+		)...,
+	)
+
+	return errors.Wrap(err, "cannot write managed resource methods")
+}
+
+// accessors are the standard accessors of managed resources that alternate
+// variants may be written for.
+var accessors = []method.Accessor{
+	{Name: "GetDeletionPolicy", Field: "DeletionPolicy", Type: "DeletionPolicy"},
+	{Name: "SetDeletionPolicy", Field: "DeletionPolicy", Type: "DeletionPolicy", Set: true},
+	{Name: "GetProviderConfigReference", Field: "ProviderConfigReference", Type: "Reference", Pointer: true},
+	{Name: "SetProviderConfigReference", Field: "ProviderConfigReference", Type: "Reference", Pointer: true, Set: true},
+	{Name: "GetProviderReference", Field: "ProviderReference", Type: "Reference", Pointer: true},
+	{Name: "SetProviderReference", Field: "ProviderReference", Type: "Reference", Pointer: true, Set: true},
+	{Name: "GetPublishConnectionDetailsTo", Field: "PublishConnectionDetailsTo", Type: "PublishConnectionDetailsTo", Pointer: true},
+	{Name: "SetPublishConnectionDetailsTo", Field: "PublishConnectionDetailsTo", Type: "PublishConnectionDetailsTo", Pointer: true, Set: true},
+	{Name: "GetWriteConnectionSecretToReference", Field: "WriteConnectionSecretToReference", Type: "SecretReference", Pointer: true},
+	{Name: "SetWriteConnectionSecretToReference", Field: "WriteConnectionSecretToReference", Type: "SecretReference", Pointer: true, Set: true},
+}
+
+// AccessorVariants returns the names of the standard accessors of managed
+// resources that the AccessorVariants field of a Config may name.
+func AccessorVariants() []string {
+	names := make([]string, len(accessors))
+	for i, a := range accessors {
+		names[i] = a.Name
+	}
+	return names
+}
+
+// validateAccessorVariants returns an error if the supplied Config names an
+// accessor that has no alternate variant.
+func validateAccessorVariants(cfg Config) error {
+	known := map[string]bool{}
+	for _, a := range accessors {
+		known[a.Name] = true
+	}
+	for _, n := range cfg.AccessorVariants {
+		if !known[n] {
+			return errors.Errorf("accessor %q has no variant; accessors with variants are %s", n, strings.Join(AccessorVariants(), ", "))
+		}
+	}
+	return nil
+}
+
+// GenerateManagedList generates the resource.ManagedList method set, for lists
+// that embed type metadata and for lists marked as object roots.
+func GenerateManagedList(p *packages.Package, cfg Config) error {
+	cfg = cfg.withDefaults()
+	rt := cfg.runtimeImports(p)
+	receiver := "l"
+
+	methods := method.Set{
+		"GetItems": method.NewManagedGetItems(receiver, rt.Resource),
+	}
+
+	err := generate.WriteMethods(p, methods, cfg.filename(p, cfg.FilenameManagedList),
+		append(cfg.writeOptions(),
+			generate.WithImportAliases(map[string]string{
+				rt.Resource: ResourceAlias,
+			}),
+			generate.WithMatcher(cfg.matcher(p, match.Or(match.ManagedList(), match.RootManagedList(commentsIn(p))))),
+		)...,
+	)
+
+	return errors.Wrap(err, "cannot write managed resource list methods")
+}
+
+// GenerateProviderConfig generates the resource.ProviderConfig method set.
+func GenerateProviderConfig(p *packages.Package, cfg Config) error {
+	cfg = cfg.withDefaults()
+	rt := cfg.runtimeImports(p)
+	receiver := "p"
+
+	methods := method.Set{
+		"SetUsers":      method.NewSetUsers(receiver),
+		"GetUsers":      method.NewGetUsers(receiver),
+		"SetConditions": method.NewSetConditions(receiver, rt.Runtime),
+		"GetCondition":  method.NewGetCondition(receiver, rt.Runtime),
+	}
+
+	err := generate.WriteMethods(p, methods, cfg.filename(p, cfg.FilenamePC),
+		append(cfg.writeOptions(),
+			generate.WithImportAliases(map[string]string{rt.Runtime: RuntimeAlias}),
+			generate.WithMatcher(cfg.matcher(p, match.ProviderConfig())),
+		)...,
+	)
+
+	return errors.Wrap(err, "cannot write provider config methods")
+}
+
+// GenerateProviderConfigUsage generates the resource.ProviderConfigUsage method set.
+func GenerateProviderConfigUsage(p *packages.Package, cfg Config) error {
+	cfg = cfg.withDefaults()
+	rt := cfg.runtimeImports(p)
+	receiver := "p"
+
+	methods := method.Set{
+		"SetProviderConfigReference": method.NewSetRootProviderConfigReference(receiver, rt.Runtime),
+		"GetProviderConfigReference": method.NewGetRootProviderConfigReference(receiver, rt.Runtime),
+		"SetResourceReference":       method.NewSetRootResourceReference(receiver, rt.Runtime),
+		"GetResourceReference":       method.NewGetRootResourceReference(receiver, rt.Runtime),
+	}
+
+	err := generate.WriteMethods(p, methods, cfg.filename(p, cfg.FilenamePCU),
+		append(cfg.writeOptions(),
+			generate.WithImportAliases(map[string]string{rt.Runtime: RuntimeAlias}),
+			generate.WithMatcher(cfg.matcher(p, match.ProviderConfigUsage())),
+		)...,
+	)
+
+	return errors.Wrap(err, "cannot write provider config usage methods")
+}
+
+// GenerateProviderConfigUsageList generates the
+// resource.ProviderConfigUsageList method set.
+func GenerateProviderConfigUsageList(p *packages.Package, cfg Config) error {
+	cfg = cfg.withDefaults()
+	rt := cfg.runtimeImports(p)
+	receiver := "p"
+
+	methods := method.Set{
+		"GetItems": method.NewProviderConfigUsageGetItems(receiver, rt.Resource),
+	}
+
+	err := generate.WriteMethods(p, methods, cfg.filename(p, cfg.FilenamePCUList),
+		append(cfg.writeOptions(),
+			generate.WithImportAliases(map[string]string{rt.Runtime: RuntimeAlias}),
+			generate.WithMatcher(cfg.matcher(p, match.ProviderConfigUsageList())),
+		)...,
+	)
+
+	return errors.Wrap(err, "cannot write provider config usage list methods")
+}
+
+// GenerateReferences generates reference resolver calls.
+func GenerateReferences(p *packages.Package, cfg Config) error {
+	cfg = cfg.withDefaults()
+	rt := cfg.runtimeImports(p)
+	receiver := "mg"
+	comm, err := cfg.comments(p)
+	if err != nil {
+		return err
+	}
+
+	if err := validateRuntime(cfg); err != nil {
+		return err
+	}
+
+	namespaced := match.Namespaced(comm).Match
+	if !cfg.features().ResolutionNamespace {
+		namespaced = func(o gotypes.Object) bool {
+			if match.Namespaced(comm).Match(o) {
+				panic(errNamespaced(o.Name()))
+			}
+			return false
+		}
+	}
+
+	opts := []method.ResolveReferencesOption{
+		method.WithRuntime(rt.Runtime),
+		method.WithResource(rt.Resource),
+		method.WithFieldPath(rt.FieldPath),
+		method.WithMeta(rt.Meta),
+		method.WithNamespaced(namespaced),
+		method.WithSelectorsDisabled(match.Or(
+			match.Func("selectors disabled", func(_ gotypes.Object) bool { return cfg.DisableSelectors }),
+			match.HasMarker(comm, SelectorsMarker, "false"),
+		).Match),
+		method.WithCache(func(o gotypes.Object) string { return cacheField(comm, o) }),
+	}
+	if cfg.DeprecationRecorder != "" {
+		opts = append(opts, method.WithDeprecationRecorder(cfg.DeprecationRecorder))
+	}
+	if cfg.ProviderConfigValidator != "" {
+		opts = append(opts, method.WithProviderConfigValidator(cfg.ProviderConfigValidator))
+	}
+	if cfg.Tenant != "" {
+		opts = append(opts, method.WithTenant(cfg.Tenant))
+	}
+	if cfg.Resolver != "" {
+		opts = append(opts, method.WithResolver(cfg.Resolver))
+	}
+	if cfg.ClearSelectors {
+		opts = append(opts, method.WithClearSelectors())
+	}
+	if cfg.WrapWithMessage {
+		opts = append(opts, method.WithWrapWithMessage())
+	}
+	if cfg.ErrorWrapper != "" {
+		opts = append(opts, method.WithErrorWrapper(cfg.ErrorWrapper))
+	}
+	if cfg.ReferenceError {
+		opts = append(opts, method.WithReferenceError())
+	}
+	if cfg.SkipEmpty {
+		opts = append(opts, method.WithSkipEmpty())
+	}
+	if cfg.FailureConditionType != "" {
+		reason := cfg.FailureConditionReason
+		if reason == "" {
+			reason = DefaultFailureConditionReason
+		}
+		opts = append(opts, method.WithFailureCondition(cfg.FailureConditionType, reason))
+	}
+	if cfg.Clock != "" {
+		opts = append(opts, method.WithClock(cfg.Clock))
+	}
+	if cfg.Transactional {
+		opts = append(opts, method.WithTransactional())
+	}
+	if cfg.Retry != "" {
+		var b *method.Backoff
+		if cfg.RetryDuration != 0 || cfg.RetryFactor != 0 || cfg.RetrySteps != 0 {
+			b = &method.Backoff{Duration: cfg.RetryDuration, Factor: cfg.RetryFactor, Steps: cfg.RetrySteps}
+		}
+		opts = append(opts, method.WithRetry(cfg.Retry, b))
+	}
+	if cfg.SkipUnchanged != "" {
+		opts = append(opts, method.WithSkipUnchanged(cfg.SkipUnchanged))
+	}
+	if cfg.DependencyAnnotation != "" {
+		opts = append(opts, method.WithDependencyAnnotation(cfg.DependencyAnnotation))
+	}
+	if cfg.ResolverLogging != "" {
+		opts = append(opts, method.WithLogging(cfg.ResolverLogging))
+	}
+	if cfg.Provenance != "" {
+		opts = append(opts, method.WithProvenance(cfg.Provenance))
+	}
+	if cfg.ResolverAssertions {
+		opts = append(opts, method.WithAssertions())
+	}
+	if cfg.ControllerRuntime {
+		opts = append(opts, method.WithControllerRuntime(rt.Meta))
+	}
+	methods := method.Set{
+		"ResolveReferences": method.NewResolveReferences(cfg.traverser(p, comm, nil), receiver, ClientImport, rt.Reference, opts...),
+	}
+	if cfg.ResolvedValues {
+		methods["ResolveReferencesWithValues"] = method.NewResolveReferences(cfg.traverser(p, comm, nil), receiver, ClientImport, rt.Reference, append(opts, method.WithResolvedValues())...)
+	}
+
+	wo := append(cfg.writeOptions(),
+		generate.WithImportAliases(map[string]string{
+			ClientImport:    ClientAlias,
+			CoreImport:      CoreAlias,
+			MetaV1Import:    MetaV1Alias,
+			rt.Runtime:   RuntimeAlias,
+			rt.Reference: ReferenceAlias,
+			rt.Resource:  ResourceAlias,
+			rt.Meta:      MetaAlias,
+			rt.FieldPath: FieldPathAlias,
+		}),
+		generate.WithMatcher(cfg.resolversMatcher(p, comm)),
+	)
+	if cfg.UpdateResolvers {
+		names := make([]string, 0, len(methods))
+		for n := range methods {
+			names = append(names, n)
+		}
+		wo = append(wo, generate.WithUpdateMethods(names...))
+	}
+	if err := generate.WriteMethods(p, methods, cfg.filename(p, cfg.FilenameResolvers), wo...); err != nil {
+		return errors.Wrap(err, "cannot write reference resolver methods")
+	}
+	if !cfg.ReferenceError {
+		return nil
+	}
+
+	err = generate.WriteFile(p, cfg.filename(p, cfg.FilenameReferenceError),
+		method.NewReferenceError(cfg.traverser(p, comm, nil), rt.Runtime),
+		append(cfg.writeOptions(),
+			generate.WithMatcher(cfg.resolversMatcher(p, comm)),
+		)...,
+	)
+
+	return errors.Wrap(err, "cannot write reference error type")
+}
+
+// GenerateResolvableFields generates tables of the fields of managed resources
+// that may be resolved from a reference or a selector.
+func GenerateResolvableFields(p *packages.Package, cfg Config) error {
+	cfg = cfg.withDefaults()
+	rt := cfg.runtimeImports(p)
+	comm, err := cfg.comments(p)
+	if err != nil {
+		return err
+	}
+
+	err = generate.WriteFile(p, cfg.filename(p, cfg.FilenameResolvableFields),
+		method.NewResolvableFields(cfg.traverser(p, comm, nil), rt.Runtime),
+		append(cfg.writeOptions(),
+			generate.WithMatcher(cfg.matcher(p, match.Managed())),
+		)...,
+	)
+
+	return errors.Wrap(err, "cannot write resolvable fields")
+}
+
+// GenerateResolversIndex generates a function for each package that resolves
+// the references of any of its managed resources.
+func GenerateResolversIndex(p *packages.Package, cfg Config) error {
+	cfg = cfg.withDefaults()
+	rt := cfg.runtimeImports(p)
+	comm, err := cfg.comments(p)
+	if err != nil {
+		return err
+	}
+
+	err = generate.WriteFile(p, cfg.filename(p, cfg.FilenameResolversIndex),
+		method.NewResolversIndex(cfg.traverser(p, comm, nil), rt.Runtime, ClientImport, rt.Resource),
+		append(cfg.writeOptions(),
+			generate.WithImportAliases(map[string]string{
+				ClientImport:   ClientAlias,
+				rt.Resource: ResourceAlias,
+			}),
+			generate.WithMatcher(cfg.resolversMatcher(p, comm)),
+		)...,
+	)
+
+	return errors.Wrap(err, "cannot write reference resolvers index")
+}
+
+// GenerateFixtures generates a function for each managed resource that returns
+// one whose required spec fields are set to valid values, for use in tests.
+func GenerateFixtures(p *packages.Package, cfg Config) error {
+	cfg = cfg.withDefaults()
+	comm, err := cfg.comments(p)
+	if err != nil {
+		return err
+	}
+
+	var fo []method.FixturesOption
+	wo := append(cfg.writeOptions(), generate.WithMatcher(cfg.matcher(p, match.Managed())))
+	if cfg.FixturesExternal {
+		fo = append(fo, method.WithExternalFixtures())
+		wo = append(wo, generate.WithExternalTestPackage())
+	}
+	err = generate.WriteFile(p, cfg.filename(p, cfg.FilenameFixtures), method.NewFixtures(cfg.traverser(p, comm, nil), fo...), wo...)
+
+	return errors.Wrap(err, "cannot write fixtures")
+}
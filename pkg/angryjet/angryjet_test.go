@@ -0,0 +1,1201 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-tools/internal/generate"
+)
+
+// The provider module used to test generatortest replaces all of its
+// dependencies with minimal stand-ins, so it can be loaded without network
+// access.
+var (
+	provider = filepath.Join("..", "generatortest", "testdata", "provider")
+	env      = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+)
+
+func TestRun(t *testing.T) {
+	defaults := []string{MethodSetManaged, MethodSetManagedList, MethodSetPC, MethodSetPCU, MethodSetPCUList, MethodSetResolvers}
+
+	type want struct {
+		report Report
+		files  []string
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason     string
+		patterns   []string
+		tenant     string
+		resolver   string
+		hubOnly    bool
+		level      string
+		maxDepth   int
+		verbose    bool
+		methodSets map[string][]string
+		skip       []string
+		variants   []string
+		cancel     bool
+		want       want
+	}{
+		"Successful": {
+			reason:   "Methods should be generated for every package.",
+			patterns: []string{"./apis/v1alpha1"},
+			want: want{
+				report: Report{
+					Packages:   []string{"example.org/provider/apis/v1alpha1"},
+					MethodSets: map[string][]string{"example.org/provider/apis/v1alpha1": defaults},
+				},
+				files: []string{
+					DefaultFilenameManaged,
+					DefaultFilenameManagedList,
+					DefaultFilenamePC,
+					DefaultFilenamePCU,
+					DefaultFilenamePCUList,
+					DefaultFilenameResolvers,
+				},
+			},
+		},
+		"Tenant": {
+			reason:   "Methods should be generated if the tenant function has the correct signature.",
+			patterns: []string{"./apis/v1alpha1"},
+			tenant:   "example.org/provider/tenancy.Namespace",
+			want: want{
+				report: Report{
+					Packages:   []string{"example.org/provider/apis/v1alpha1"},
+					MethodSets: map[string][]string{"example.org/provider/apis/v1alpha1": defaults},
+				},
+				files: []string{
+					DefaultFilenameManaged,
+					DefaultFilenameManagedList,
+					DefaultFilenamePC,
+					DefaultFilenamePCU,
+					DefaultFilenamePCUList,
+					DefaultFilenameResolvers,
+				},
+			},
+		},
+		"TenantWrongSignature": {
+			reason:   "Nothing should be generated if the tenant function has the wrong signature.",
+			patterns: []string{"./apis/v1alpha1"},
+			tenant:   "example.org/provider/tenancy.NamespaceOrError",
+			want: want{
+				report: Report{},
+				files:  []string{},
+				err:    errors.New("tenant function example.org/provider/tenancy.NamespaceOrError must have the signature func(context.Context) string, not func(ctx context.Context) (string, error)"),
+			},
+		},
+		"TenantNotFound": {
+			reason:   "Nothing should be generated if the tenant function does not exist.",
+			patterns: []string{"./apis/v1alpha1"},
+			tenant:   "example.org/provider/tenancy.Nope",
+			want: want{
+				report: Report{},
+				files:  []string{},
+				err:    errors.New("cannot find tenant function Nope in package example.org/provider/tenancy"),
+			},
+		},
+		"TenantOldRuntimeLevel": {
+			reason:   "Nothing should be generated if the tenant function requires a feature the runtime level lacks.",
+			patterns: []string{"./apis/v1alpha1"},
+			tenant:   "example.org/provider/tenancy.Namespace",
+			level:    "v0.19",
+			want: want{
+				report: Report{},
+				files:  []string{},
+				err:    errors.New("tenant function example.org/provider/tenancy.Namespace requires resolution requests with a Namespace field, from crossplane-runtime v0.20"),
+			},
+		},
+		"Resolver": {
+			reason:   "Methods should be generated if the resolver function and its resolver have the correct signatures.",
+			patterns: []string{"./apis/v1alpha1"},
+			resolver: "example.org/provider/webhook.NewResolver",
+			want: want{
+				report: Report{
+					Packages:   []string{"example.org/provider/apis/v1alpha1"},
+					MethodSets: map[string][]string{"example.org/provider/apis/v1alpha1": defaults},
+				},
+				files: []string{
+					DefaultFilenameManaged,
+					DefaultFilenameManagedList,
+					DefaultFilenamePC,
+					DefaultFilenamePCU,
+					DefaultFilenamePCUList,
+					DefaultFilenameResolvers,
+				},
+			},
+		},
+		"ResolverWrongSignature": {
+			reason:   "Nothing should be generated if the resolver function has the wrong signature.",
+			patterns: []string{"./apis/v1alpha1"},
+			resolver: "example.org/provider/webhook.NewResolverOfManaged",
+			want: want{
+				report: Report{},
+				files:  []string{},
+				err:    errors.New("resolver function example.org/provider/webhook.NewResolverOfManaged must have the signature func(client.Reader, resource.Managed) R, not func(from resource.Managed) *Resolver"),
+			},
+		},
+		"ResolverMissingMethod": {
+			reason:   "Nothing should be generated if the resolver lacks a method of the API resolver.",
+			patterns: []string{"./apis/v1alpha1"},
+			resolver: "example.org/provider/webhook.NewSingleResolver",
+			want: want{
+				report: Report{},
+				files:  []string{},
+				err:    errors.New("resolver SingleResolver returned by example.org/provider/webhook.NewSingleResolver has no ResolveMultiple method"),
+			},
+		},
+		"ResolverNotFound": {
+			reason:   "Nothing should be generated if the resolver function does not exist.",
+			patterns: []string{"./apis/v1alpha1"},
+			resolver: "example.org/provider/webhook.Nope",
+			want: want{
+				report: Report{},
+				files:  []string{},
+				err:    errors.New("cannot find resolver function Nope in package example.org/provider/webhook"),
+			},
+		},
+		"UnexportedType": {
+			reason:   "Methods should be generated for unexported managed resources, with a warning.",
+			patterns: []string{"./apis/unexported"},
+			want: want{
+				report: Report{
+					Packages:   []string{"example.org/provider/apis/unexported"},
+					MethodSets: map[string][]string{"example.org/provider/apis/unexported": defaults},
+					Warnings: []TypeWarning{
+						{
+							Package: "example.org/provider/apis/unexported",
+							Type:    "gizmo",
+							Message: "managed resource is not exported, so its generated methods can only be called from other packages through interfaces such as resource.Managed",
+						},
+						{
+							Package: "example.org/provider/apis/unexported",
+							Type:    "widget",
+							Message: "managed resource is not exported, so its generated methods can only be called from other packages through interfaces such as resource.Managed",
+						},
+					},
+				},
+				files: []string{
+					DefaultFilenameManaged,
+					DefaultFilenameManagedList,
+					DefaultFilenameResolvers,
+				},
+			},
+		},
+		"MaxDepth": {
+			reason:   "References deeper than the maximum depth should not be resolved, with a warning.",
+			patterns: []string{"./apis/deep"},
+			maxDepth: 4,
+			want: want{
+				report: Report{
+					Packages:   []string{"example.org/provider/apis/deep"},
+					MethodSets: map[string][]string{"example.org/provider/apis/deep": defaults},
+					Warnings: []TypeWarning{{
+						Package: "example.org/provider/apis/deep",
+						Type:    "Widget",
+						Message: "type Inner of field Spec.ForProvider.Outer.Middle.Inner is deeper than the maximum depth of 4, so references of its fields are not resolved",
+					}},
+				},
+				files: []string{
+					DefaultFilenameManaged,
+					DefaultFilenameManagedList,
+				},
+			},
+		},
+		"Deprecated": {
+			reason:   "References to kinds in other packages that are deprecated should be warned of, naming the replacement package if there is one.",
+			patterns: []string{"./apis/deprecated/v1beta1"},
+			want: want{
+				report: Report{
+					Packages:   []string{"example.org/provider/apis/deprecated/v1beta1"},
+					MethodSets: map[string][]string{"example.org/provider/apis/deprecated/v1beta1": defaults},
+					Warnings: []TypeWarning{
+						{
+							Package: "example.org/provider/apis/deprecated/v1beta1",
+							Type:    "Widget",
+							Message: "field Spec.ForProvider.RetiredGizmoID: referenced type example.org/provider/apis/deprecated/v1alpha1.Gizmo is in deprecated package example.org/provider/apis/deprecated/v1alpha1",
+						},
+						{
+							Package: "example.org/provider/apis/deprecated/v1beta1",
+							Type:    "Widget",
+							Message: "field Spec.ForProvider.LegacyGizmoID: referenced type example.org/provider/apis/deprecated/v1alpha2.Gizmo is in deprecated package example.org/provider/apis/deprecated/v1alpha2; reference the type in example.org/provider/apis/deprecated/v1beta1 instead",
+						},
+					},
+				},
+				files: []string{
+					DefaultFilenameManaged,
+					DefaultFilenameManagedList,
+					DefaultFilenameResolvers,
+				},
+			},
+		},
+		"Unsafe": {
+			reason:   "Fields of opaque types should not be traversed, with a warning for each that has markers.",
+			patterns: []string{"./apis/unsafe"},
+			want: want{
+				report: Report{
+					Packages:   []string{"example.org/provider/apis/unsafe"},
+					MethodSets: map[string][]string{"example.org/provider/apis/unsafe": defaults},
+					Warnings: []TypeWarning{{
+						Package: "example.org/provider/apis/unsafe",
+						Type:    "Widget",
+						Message: "field Spec.ForProvider.Handle is of opaque type uintptr, so its markers are ignored",
+					}},
+				},
+				files: []string{
+					DefaultFilenameManaged,
+					DefaultFilenameManagedList,
+					DefaultFilenameResolvers,
+				},
+			},
+		},
+		"EmbeddedTwice": {
+			reason:   "A reference reachable through two routes of embedded structs should only be resolved through the shorter, with a warning if verbose.",
+			patterns: []string{"./apis/embedding"},
+			verbose:  true,
+			want: want{
+				report: Report{
+					Packages:   []string{"example.org/provider/apis/embedding"},
+					MethodSets: map[string][]string{"example.org/provider/apis/embedding": defaults},
+					Warnings: []TypeWarning{{
+						Package: "example.org/provider/apis/embedding",
+						Type:    "Widget",
+						Message: "field Spec.ForProvider.Common.Network.SubnetID is not resolved because it is shadowed by field Spec.ForProvider.Network.SubnetID, which is embedded less deeply",
+					}},
+				},
+				files: []string{
+					DefaultFilenameManaged,
+					DefaultFilenameManagedList,
+					DefaultFilenameResolvers,
+				},
+			},
+		},
+		"GeneratorPanicked": {
+			reason:   "A panic while generating methods for a type should be reported, and methods should be generated for all other types.",
+			patterns: []string{"./apis/unsupported"},
+			want: want{
+				report: Report{
+					Packages:   []string{"example.org/provider/apis/unsupported"},
+					MethodSets: map[string][]string{"example.org/provider/apis/unsupported": defaults},
+					Errors: []TypeError{{
+						Package:  "example.org/provider/apis/unsupported",
+						Type:     "Widget",
+						Filename: DefaultFilenameResolvers,
+						Message:  "Spec.ForProvider.GizmoID requires the same provider config as Widget, but no provider config validator is configured",
+					}},
+				},
+				files: []string{
+					DefaultFilenameManaged,
+					DefaultFilenameManagedList,
+				},
+			},
+		},
+		"UnfixableType": {
+			reason:     "A managed resource with a required field a fixture can't set should be reported, while fixtures are written for the others.",
+			patterns:   []string{"./apis/fixtures"},
+			methodSets: map[string][]string{"example.org/provider/apis/fixtures": {MethodSetManaged, MethodSetFixtures}},
+			want: want{
+				report: Report{
+					Packages:   []string{"example.org/provider/apis/fixtures"},
+					MethodSets: map[string][]string{"example.org/provider/apis/fixtures": {MethodSetManaged, MethodSetFixtures}},
+					Errors: []TypeError{{
+						Package:  "example.org/provider/apis/fixtures",
+						Type:     "Gadget",
+						Filename: DefaultFilenameFixtures,
+						Message:  "cannot generate a fixture of Gadget: cannot set required field Spec.ForProvider.Source of type interface{} to a valid value",
+					}},
+				},
+				files: []string{
+					DefaultFilenameManaged,
+					DefaultFilenameFixtures,
+				},
+			},
+		},
+		"MethodSets": {
+			reason:     "Only the method sets of the longest matching pattern should be generated, without those skipped.",
+			patterns:   []string{"./apis/v1alpha1"},
+			methodSets: map[string][]string{"example.org/provider/apis/...": {MethodSetPC}, "example.org/provider/apis/v1alpha1": {MethodSetManaged, MethodSetResolvers}},
+			skip:       []string{MethodSetResolvers},
+			want: want{
+				report: Report{
+					Packages:   []string{"example.org/provider/apis/v1alpha1"},
+					MethodSets: map[string][]string{"example.org/provider/apis/v1alpha1": {MethodSetManaged}},
+				},
+				files: []string{DefaultFilenameManaged},
+			},
+		},
+		"HubOnly": {
+			reason:     "Reference resolvers and their index should be generated for the storage version of a kind.",
+			patterns:   []string{"./apis/hub/v1"},
+			hubOnly:    true,
+			methodSets: map[string][]string{"example.org/provider/apis/hub/...": {MethodSetResolvers, MethodSetResolversIndex}},
+			want: want{
+				report: Report{
+					Packages:   []string{"example.org/provider/apis/hub/v1"},
+					MethodSets: map[string][]string{"example.org/provider/apis/hub/v1": {MethodSetResolvers, MethodSetResolversIndex}},
+				},
+				files: []string{DefaultFilenameResolvers, DefaultFilenameResolversIndex},
+			},
+		},
+		"HubOnlySpoke": {
+			reason:     "Neither reference resolvers nor their index should be generated for a version of a kind that is converted to its storage version.",
+			patterns:   []string{"./apis/hub/v1beta1"},
+			hubOnly:    true,
+			methodSets: map[string][]string{"example.org/provider/apis/hub/...": {MethodSetResolvers, MethodSetResolversIndex}},
+			want: want{
+				report: Report{
+					Packages:   []string{"example.org/provider/apis/hub/v1beta1"},
+					MethodSets: map[string][]string{"example.org/provider/apis/hub/v1beta1": {MethodSetResolvers, MethodSetResolversIndex}},
+				},
+				files: []string{},
+			},
+		},
+		"UnknownMethodSet": {
+			reason:     "Nothing should be generated if an unknown method set is named.",
+			patterns:   []string{"./apis/v1alpha1"},
+			methodSets: map[string][]string{"example.org/provider/apis/...": {"conditions"}},
+			want: want{
+				report: Report{},
+				files:  []string{},
+				err:    errors.New(`method sets of package pattern example.org/provider/apis/... names unknown method set "conditions"; method sets are managed, managedlist, pc, pcu, pculist, resolvers, resolvablefields, resolversindex, fixtures`),
+			},
+		},
+		"UnknownAccessorVariant": {
+			reason:   "Nothing should be generated if an accessor without a variant is named.",
+			patterns: []string{"./apis/v1alpha1"},
+			variants: []string{"GetCondition"},
+			want: want{
+				report: Report{},
+				files:  []string{},
+				err:    errors.New(`accessor "GetCondition" has no variant; accessors with variants are GetDeletionPolicy, SetDeletionPolicy, GetProviderConfigReference, SetProviderConfigReference, GetProviderReference, SetProviderReference, GetPublishConnectionDetailsTo, SetPublishConnectionDetailsTo, GetWriteConnectionSecretToReference, SetWriteConnectionSecretToReference`),
+			},
+		},
+		"Cancelled": {
+			reason:   "Generation should stop when the context is cancelled.",
+			patterns: []string{"./apis/v1alpha1"},
+			cancel:   true,
+			want: want{
+				report: Report{},
+				files:  []string{DefaultFilenameManaged},
+				err:    errors.New("cannot write managed resource list method set for package example.org/provider/apis/v1alpha1: cannot write managed resource list methods: generation was cancelled: context canceled"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			files := []string{}
+			cfg := Config{
+				Patterns:         tc.patterns,
+				Dir:              provider,
+				Env:              env,
+				Tenant:           tc.tenant,
+				Resolver:         tc.resolver,
+				HubOnly:          tc.hubOnly,
+				RuntimeLevel:     tc.level,
+				MaxDepth:         tc.maxDepth,
+				Verbose:          tc.verbose,
+				MethodSets:       tc.methodSets,
+				Skip:             tc.skip,
+				AccessorVariants: tc.variants,
+				Write: func(filename string, _ []byte) error {
+					files = append(files, filepath.Base(filename))
+					if tc.cancel {
+						cancel()
+					}
+					return nil
+				},
+			}
+
+			r, err := Run(ctx, cfg)
+			if diff := cmp.Diff(tc.want.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			for _, e := range r.Errors {
+				if e.Stack != "" {
+					t.Errorf("\n%s\nRun(...): error reported for type %s has a stack trace", tc.reason, e.Type)
+				}
+			}
+			if diff := cmp.Diff(tc.want.report, r, cmpopts.IgnoreFields(TypeError{}, "Stack"), cmpopts.IgnoreFields(Report{}, "Types")); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want report, +got report:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.files, files, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want files, +got files:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		env    []string
+		want   []string
+	}{
+		"CgoUnset": {
+			reason: "Cgo should be disabled if the environment doesn't set CGO_ENABLED.",
+			env:    []string{"GOFLAGS=-mod=mod"},
+			want:   []string{"GOFLAGS=-mod=mod", "CGO_ENABLED=0"},
+		},
+		"CgoSet": {
+			reason: "The environment should be unchanged if it sets CGO_ENABLED.",
+			env:    []string{"CGO_ENABLED=1", "GOFLAGS=-mod=mod"},
+			want:   []string{"CGO_ENABLED=1", "GOFLAGS=-mod=mod"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := LoadEnv(tc.env)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nLoadEnv(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBugStack(t *testing.T) {
+	var runtimeError interface{}
+	func() {
+		defer func() { runtimeError = recover() }()
+		var m map[string]bool
+		m["boom"] = true
+	}()
+
+	cases := map[string]struct {
+		reason    string
+		recovered interface{}
+		want      string
+	}{
+		"ReportedError": {
+			reason:    "An error with which a generator reported an invalid type should have no stack trace.",
+			recovered: errors.New("boom"),
+			want:      "",
+		},
+		"RuntimeError": {
+			reason:    "A runtime error should have a stack trace.",
+			recovered: runtimeError,
+			want:      "stack",
+		},
+		"NotAnError": {
+			reason:    "A panic with a value that isn't an error should have a stack trace.",
+			recovered: "boom",
+			want:      "stack",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := bugStack(tc.recovered, []byte("stack"))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nbugStack(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// cmpErrors compares errors by their messages, regardless of their types.
+func cmpErrors() cmp.Option {
+	return cmp.FilterValues(func(a, b interface{}) bool {
+		_, aok := a.(error)
+		_, bok := b.(error)
+		return aok && bok
+	}, cmp.Comparer(func(a, b interface{}) bool {
+		return a.(error).Error() == b.(error).Error()
+	}))
+}
+
+func TestRunOutputDir(t *testing.T) {
+	out := t.TempDir()
+	cfg := Config{
+		Patterns:  []string{"./apis/v1alpha1"},
+		Dir:       provider,
+		Env:       env,
+		OutputDir: out,
+	}
+	if _, err := Run(context.Background(), cfg); err != nil {
+		t.Fatalf("Run(...): %v", err)
+	}
+
+	files := []string{}
+	err := filepath.WalkDir(out, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(out, path)
+		files = append(files, filepath.ToSlash(rel))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("cannot walk output directory: %v", err)
+	}
+	want := []string{
+		"apis/v1alpha1/" + DefaultFilenameManaged,
+		"apis/v1alpha1/" + DefaultFilenameManagedList,
+		"apis/v1alpha1/" + DefaultFilenamePC,
+		"apis/v1alpha1/" + DefaultFilenamePCU,
+		"apis/v1alpha1/" + DefaultFilenamePCUList,
+		"apis/v1alpha1/" + DefaultFilenameResolvers,
+	}
+	if diff := cmp.Diff(want, files, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("Run(...): -want files, +got files:\n%s", diff)
+	}
+
+	// Generated files belong to the package they were generated for, so they
+	// must refer to its types without importing it.
+	f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(out, "apis", "v1alpha1", DefaultFilenameResolvers), nil, parser.ImportsOnly)
+	if err != nil {
+		t.Fatalf("cannot parse generated file: %v", err)
+	}
+	if f.Name.Name != "v1alpha1" {
+		t.Errorf("Run(...): generated file is in package %s, want v1alpha1", f.Name.Name)
+	}
+	for _, i := range f.Imports {
+		if i.Path.Value == `"example.org/provider/apis/v1alpha1"` {
+			t.Errorf("Run(...): generated file imports the package it belongs to")
+		}
+	}
+	if _, err := os.Stat(filepath.Join(provider, "apis", "v1alpha1", DefaultFilenameManaged)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Run(...): generated file was written alongside its package")
+	}
+}
+
+func TestRunGeneratedHeader(t *testing.T) {
+	// Tooling recognises generated files by a comment matching this regular
+	// expression before their package clause. See https://go.dev/s/generatedcode.
+	generated := regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+	files := map[string][]byte{}
+	cfg := Config{
+		Patterns:         []string{"./apis/v1alpha1", "./apis/paved", "./apis/embedding"},
+		Dir:              provider,
+		Env:              env,
+		Header:           "/*\nCopyright 2026 The Crossplane Authors.\n*/",
+		ResolvableFields: true,
+		ResolversIndex:   true,
+		Banners:          true,
+		Write: func(filename string, data []byte) error {
+			files[filename] = data
+			return nil
+		},
+	}
+	if _, err := Run(context.Background(), cfg); err != nil {
+		t.Fatalf("Run(...): %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatal("Run(...): no files were generated")
+	}
+
+	for filename, data := range files {
+		f, err := parser.ParseFile(token.NewFileSet(), filename, data, parser.PackageClauseOnly)
+		if err != nil {
+			t.Errorf("Run(...): cannot parse generated file %s: %v", filename, err)
+			continue
+		}
+		if !generated.Match(data[:f.Package-1]) {
+			t.Errorf("Run(...): generated file %s should have a %q comment before its package clause:\n%s", filepath.Base(filename), generate.HeaderGenerated, data)
+		}
+	}
+}
+
+func TestRunOverlay(t *testing.T) {
+	types, err := filepath.Abs(filepath.Join(provider, "apis", "v1alpha1", "types.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile(types)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "fromAnnotation=example.org/key-arn") {
+		t.Fatalf("%s should have a fromAnnotation marker to overlay", types)
+	}
+
+	// The overlay changes a marker of the file without writing it.
+	overlaid := strings.Replace(string(src), "fromAnnotation=example.org/key-arn", "fromAnnotation=example.org/overlaid-arn", 1)
+	resolvers := ""
+	cfg := Config{
+		Patterns: []string{"./apis/v1alpha1"},
+		Dir:      provider,
+		Env:      env,
+		Overlay:  map[string][]byte{types: []byte(overlaid)},
+		Only:     []string{MethodSetResolvers},
+		Write: func(filename string, data []byte) error {
+			if filepath.Base(filename) == DefaultFilenameResolvers {
+				resolvers = string(data)
+			}
+			return nil
+		},
+	}
+	if _, err := Run(context.Background(), cfg); err != nil {
+		t.Fatalf("Run(...): %v", err)
+	}
+	if !strings.Contains(resolvers, `"example.org/overlaid-arn"`) || strings.Contains(resolvers, `"example.org/key-arn"`) {
+		t.Errorf("Run(...): reference resolvers should be generated from the overlaid file:\n%s", resolvers)
+	}
+}
+
+func TestRunFieldSelectors(t *testing.T) {
+	// The v0.19 crossplane-runtime stand-in's reference package, like that of
+	// crossplane-runtime, has no FieldSelector field.
+	envV019 := append(os.Environ(), "GOFLAGS=-mod=mod -modfile=go.v0.19.mod", "GOPROXY=off", "GOWORK=off")
+
+	type want struct {
+		contains []string
+		err      error
+	}
+	cases := map[string]struct {
+		reason string
+		env    []string
+		skip   []string
+		want   want
+	}{
+		"Supported": {
+			reason: "Field selectors should be added to the resolution requests of a reference package that supports them.",
+			env:    env,
+			want: want{
+				contains: []string{
+					`FieldSelector: "spec.forProvider.region=us-east-1"`,
+					`FieldSelector: "status.atProvider.state!=deleting"`,
+				},
+			},
+		},
+		"Unsupported": {
+			reason: "Nothing should be generated if the reference package does not support field selectors.",
+			env:    envV019,
+			want: want{
+				err: errors.New("Widget.Spec.ForProvider.GizmoID has a field selector, but github.com/crossplane/crossplane-runtime/pkg/reference.ResolutionRequest has no FieldSelector field"),
+			},
+		},
+		"UnsupportedWithoutResolvers": {
+			reason: "Field selectors should not be checked if reference resolvers are not generated.",
+			env:    envV019,
+			skip:   []string{MethodSetResolvers},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resolvers := ""
+			cfg := Config{
+				Patterns: []string{"./apis/fieldselector"},
+				Dir:      provider,
+				Env:      tc.env,
+				Skip:     tc.skip,
+				Write: func(filename string, data []byte) error {
+					if filepath.Base(filename) == DefaultFilenameResolvers {
+						resolvers = string(data)
+					}
+					return nil
+				},
+			}
+			_, err := Run(context.Background(), cfg)
+			if diff := cmp.Diff(tc.want.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			for _, want := range tc.want.contains {
+				if !strings.Contains(resolvers, want) {
+					t.Errorf("\n%s\nRun(...): reference resolvers should contain %s:\n%s", tc.reason, want, resolvers)
+				}
+			}
+		})
+	}
+}
+
+func TestRunIdempotent(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		persist bool
+		cfg     Config
+	}{
+		"Regenerated": {
+			reason: "Generating methods for a package that already contains the generated files should not change them.",
+			// Generated files are loaded with their package when it is
+			// generated again. Resolvers import the context package, which
+			// packages.Load can't type check with the sizes of recent
+			// toolchains, so they're not generated.
+			persist: true,
+			cfg: Config{
+				Patterns:         []string{"./apis/v1alpha1"},
+				ResolvableFields: true,
+				Skip:             []string{MethodSetResolvers},
+			},
+		},
+		"RegeneratedWithBanners": {
+			reason:  "Generating methods with banners for a package that already contains the generated files should not change them.",
+			persist: true,
+			cfg: Config{
+				Patterns:         []string{"./apis/v1alpha1"},
+				ResolvableFields: true,
+				Banners:          true,
+				Skip:             []string{MethodSetResolvers},
+			},
+		},
+		"Deterministic": {
+			reason: "Generating methods for the same package twice should produce the same files.",
+			cfg: Config{
+				Patterns:         []string{"./apis/v1alpha1", "./apis/paved", "./apis/embedding"},
+				ResolvableFields: true,
+				ResolvedValues:   true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Copy the provider module and the stand-ins it replaces its
+			// dependencies with, so generated files can be written to it.
+			testdata := t.TempDir()
+			if err := copyDir(filepath.Dir(provider), testdata); err != nil {
+				t.Fatalf("cannot copy test data: %v", err)
+			}
+
+			generated := func() map[string][]byte {
+				files := map[string][]byte{}
+				cfg := tc.cfg
+				cfg.Dir = filepath.Join(testdata, filepath.Base(provider))
+				cfg.Env = env
+				cfg.Write = func(filename string, data []byte) error {
+					rel, err := filepath.Rel(cfg.Dir, filename)
+					files[filepath.ToSlash(rel)] = data
+					if err != nil || !tc.persist {
+						return err
+					}
+					return os.WriteFile(filename, data, 0o600)
+				}
+				if _, err := Run(context.Background(), cfg); err != nil {
+					t.Fatalf("\n%s\nRun(...): %v", tc.reason, err)
+				}
+				return files
+			}
+
+			first := generated()
+			if len(first) == 0 {
+				t.Fatalf("\n%s\nRun(...): no files were generated", tc.reason)
+			}
+			if diff := cmp.Diff(first, generated()); diff != "" {
+				t.Errorf("\n%s\nRun(...): -first run, +second run:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRunExecuted(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		pattern string
+		cfg     Config
+	}{
+		"Loops": {
+			reason:  "Resolvers that grow slices they resolve the elements of should resolve each element once.",
+			pattern: "./apis/loops",
+		},
+		"Transactional": {
+			reason:  "Transactional resolvers should not write any resolved field if a reference cannot be resolved.",
+			pattern: "./apis/transactional",
+			cfg:     Config{Transactional: true},
+		},
+		"Retry": {
+			reason:  "Resolvers that retry resolutions should try them until retries are exhausted, then identify the field that cannot be resolved.",
+			pattern: "./apis/retried",
+			cfg:     Config{Retry: "example.org/provider/retry", RetryDuration: time.Millisecond, RetryFactor: 2, RetrySteps: 3},
+		},
+		"ReferenceError": {
+			reason:  "Resolvers that return a ReferenceError should identify the field and kind that cannot be resolved to errors.As.",
+			pattern: "./apis/referenceerror",
+			cfg:     Config{ReferenceError: true, ErrorWrapper: "example.org/provider/providererrors.Reference"},
+		},
+		"Cached": {
+			reason:  "Resolutions should be cached in the status field named by the cache marker, and fields resolved again only when their reference, selector, or value changes.",
+			pattern: "./apis/cached",
+		},
+		"RootList": {
+			reason:  "A list marked as an object root that embeds its type metadata in a shared struct should get GetItems, so that resolvers referencing its items compile.",
+			pattern: "./apis/rootlist",
+		},
+		"Fixtures": {
+			reason:  "Fixtures should set the required fields of a managed resource to valid values.",
+			pattern: "./apis/fixtures",
+			cfg:     Config{Fixtures: true, Only: []string{MethodSetManaged, MethodSetFixtures}},
+		},
+		"FixturesExternal": {
+			reason:  "Fixtures written to the external test package should set the required fields of a managed resource to valid values.",
+			pattern: "./apis/fixturesexternal",
+			cfg:     Config{Fixtures: true, FixturesExternal: true, Only: []string{MethodSetManaged, MethodSetFixtures}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Copy the provider module and the stand-ins it replaces its
+			// dependencies with, so generated files can be written to it.
+			testdata := t.TempDir()
+			if err := copyDir(filepath.Dir(provider), testdata); err != nil {
+				t.Fatalf("cannot copy test data: %v", err)
+			}
+			dir := filepath.Join(testdata, filepath.Base(provider))
+
+			cfg := tc.cfg
+			cfg.Patterns, cfg.Dir, cfg.Env = []string{tc.pattern}, dir, env
+			if _, err := Run(context.Background(), cfg); err != nil {
+				t.Fatalf("\n%s\nRun(...): %v", tc.reason, err)
+			}
+
+			// The package's own test executes its generated methods.
+			cmd := exec.Command("go", "test", "-count=1", tc.pattern)
+			cmd.Dir = dir
+			cmd.Env = env
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Errorf("\n%s\ngo test %s: %v\n%s", tc.reason, tc.pattern, err, out)
+			}
+		})
+	}
+}
+
+func TestRunUpdateResolvers(t *testing.T) {
+	testdata := t.TempDir()
+	if err := copyDir(filepath.Dir(provider), testdata); err != nil {
+		t.Fatalf("cannot copy test data: %v", err)
+	}
+	dir := filepath.Join(testdata, filepath.Base(provider))
+	file := filepath.Join(dir, "apis", "v1alpha1", DefaultFilenameManaged)
+	run := func(t *testing.T, cfg Config) string {
+		t.Helper()
+		cfg.Patterns, cfg.Dir, cfg.Env = []string{"./apis/v1alpha1"}, dir, env
+		if _, err := Run(context.Background(), cfg); err != nil {
+			t.Fatalf("Run(...): %v", err)
+		}
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	// Reference resolvers share a file with the managed resource method set,
+	// which they must not overwrite.
+	managed := run(t, Config{Only: []string{MethodSetManaged, MethodSetManagedList}})
+	updated := run(t, Config{FilenameResolvers: DefaultFilenameManaged, UpdateResolvers: true})
+	if !strings.Contains(updated, "ResolveReferences(") || !strings.HasPrefix(updated, managed[:strings.Index(managed, "import")]) {
+		t.Errorf("Run(...): reference resolvers should be added to the existing file:\n%s", updated)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(file), DefaultFilenamePC)); !os.IsNotExist(err) {
+		t.Errorf("Run(...): only reference resolvers should be generated when they are updated: %v", err)
+	}
+	for _, m := range []string{"GetCondition", "SetConditions", "GetProviderConfigReference"} {
+		if strings.Count(updated, ") "+m+"(") != strings.Count(managed, ") "+m+"(") {
+			t.Errorf("Run(...): the %s methods of the existing file should be kept:\n%s", m, updated)
+		}
+	}
+	cmd := exec.Command("go", "vet", "./apis/v1alpha1")
+	cmd.Dir = dir
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("go vet ./apis/v1alpha1: %v\n%s", err, out)
+	}
+}
+
+func TestRunBanners(t *testing.T) {
+	testdata := t.TempDir()
+	if err := copyDir(filepath.Dir(provider), testdata); err != nil {
+		t.Fatalf("cannot copy test data: %v", err)
+	}
+	dir := filepath.Join(testdata, filepath.Base(provider))
+	cfg := Config{Patterns: []string{"./apis/v1alpha1"}, Dir: dir, Env: env, Banners: true}
+
+	// Reference resolvers that are added to a file shared with the managed
+	// resource method set should be grouped with the other methods of their
+	// types, rather than added to the end of the file.
+	cfg.Only = []string{MethodSetManaged, MethodSetManagedList}
+	if _, err := Run(context.Background(), cfg); err != nil {
+		t.Fatalf("Run(...): %v", err)
+	}
+	cfg.Only, cfg.FilenameResolvers, cfg.UpdateResolvers = nil, DefaultFilenameManaged, true
+	if _, err := Run(context.Background(), cfg); err != nil {
+		t.Fatalf("Run(...): %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "apis", "v1alpha1", DefaultFilenameManaged))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	for _, want := range []string{"// ===== Bucket =====", "func (mg *Bucket) ResolveReferences(", "// ===== Key ====="} {
+		i := strings.Index(got, want)
+		if i < 0 {
+			t.Fatalf("Run(...): the generated file should contain %q:\n%s", want, got)
+		}
+		got = got[i:]
+	}
+}
+
+func TestRunChecksums(t *testing.T) {
+	testdata := t.TempDir()
+	if err := copyDir(filepath.Dir(provider), testdata); err != nil {
+		t.Fatalf("cannot copy test data: %v", err)
+	}
+	dir := filepath.Join(testdata, filepath.Base(provider))
+	file := filepath.Join(dir, "apis", "v1alpha1", DefaultFilenameManaged)
+	cfg := Config{Patterns: []string{"./apis/v1alpha1"}, Dir: dir, Env: env, Only: []string{MethodSetManaged}, Checksums: true}
+	read := func(t *testing.T) string {
+		t.Helper()
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	if _, err := Run(context.Background(), cfg); err != nil {
+		t.Fatalf("Run(...): %v", err)
+	}
+	if !strings.Contains(read(t), "// "+generate.HeaderGenerated+"\n// "+generate.ChecksumPrefix) {
+		t.Fatalf("Run(...): the checksum of the generated file should follow its header:\n%s", read(t))
+	}
+
+	// Generated files that were edited are reported, and not overwritten.
+	edited := read(t) + "\n// Edited by hand.\n"
+	if err := os.WriteFile(file, []byte(edited), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run(...): %v", err)
+	}
+	if diff := cmp.Diff([]string{file}, r.Modified); diff != "" {
+		t.Errorf("Run(...): edited files should be reported as modified: -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(edited, read(t)); diff != "" {
+		t.Errorf("Run(...): edited files should not be overwritten: -want, +got:\n%s", diff)
+	}
+
+	// Generated files that were edited are overwritten if forced.
+	cfg.Force = true
+	if r, err = Run(context.Background(), cfg); err != nil {
+		t.Fatalf("Run(...): %v", err)
+	}
+	if len(r.Modified) != 0 || strings.Contains(read(t), "Edited by hand.") {
+		t.Errorf("Run(...): edited files should be overwritten if forced, not reported as %v:\n%s", r.Modified, read(t))
+	}
+}
+
+func TestRunOrphaned(t *testing.T) {
+	types, err := filepath.Abs(filepath.Join(provider, "apis", "v1alpha1", "types.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile(types)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+	index := filepath.Join(out, "apis", "v1alpha1", DefaultFilenameResolversIndex)
+	cfg := Config{
+		Patterns:       []string{"./apis/v1alpha1"},
+		Dir:            provider,
+		Env:            env,
+		OutputDir:      out,
+		Only:           []string{MethodSetResolvers, MethodSetResolversIndex},
+		ResolversIndex: true,
+	}
+
+	if _, err := Run(context.Background(), cfg); err != nil {
+		t.Fatalf("Run(...): %v", err)
+	}
+	if _, err := os.Stat(index); err != nil {
+		t.Fatalf("Run(...): the reference resolvers index should be generated: %v", err)
+	}
+
+	// The index of a package that no longer has any references would refer
+	// to reference resolvers that are no longer generated, so it is removed.
+	cfg.Overlay = map[string][]byte{types: []byte(strings.ReplaceAll(string(src), "+crossplane:generate:reference", ""))}
+	if _, err := Run(context.Background(), cfg); err != nil {
+		t.Fatalf("Run(...): %v", err)
+	}
+	if _, err := os.Stat(index); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Run(...): the reference resolvers index of a package without references should be removed: %v", err)
+	}
+}
+
+func TestRunTypeMethodSets(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		cfg    Config
+		want   []TypeMethodSets
+	}{
+		"Defaults": {
+			reason: "Each type should be reported with the method sets it received, which excludes reference resolvers of managed resources without references.",
+			cfg:    Config{Patterns: []string{"./apis/v1alpha1"}},
+			want: []TypeMethodSets{
+				{Package: "example.org/provider/apis/v1alpha1", Type: "Bucket", MethodSets: []string{MethodSetManaged, MethodSetResolvers}},
+				{Package: "example.org/provider/apis/v1alpha1", Type: "BucketList", MethodSets: []string{MethodSetManagedList}},
+				{Package: "example.org/provider/apis/v1alpha1", Type: "Key", MethodSets: []string{MethodSetManaged}},
+				{Package: "example.org/provider/apis/v1alpha1", Type: "KeyList", MethodSets: []string{MethodSetManagedList}},
+				{Package: "example.org/provider/apis/v1alpha1", Type: "ProviderConfig", MethodSets: []string{MethodSetPC}},
+				{Package: "example.org/provider/apis/v1alpha1", Type: "ProviderConfigUsage", MethodSets: []string{MethodSetPCU}},
+				{Package: "example.org/provider/apis/v1alpha1", Type: "ProviderConfigUsageList", MethodSets: []string{MethodSetPCUList}},
+			},
+		},
+		"Skip": {
+			reason: "Method sets that are skipped should not be reported.",
+			cfg:    Config{Patterns: []string{"./apis/v1alpha1"}, Skip: []string{MethodSetResolvers, MethodSetPC, MethodSetPCU, MethodSetPCUList}},
+			want: []TypeMethodSets{
+				{Package: "example.org/provider/apis/v1alpha1", Type: "Bucket", MethodSets: []string{MethodSetManaged}},
+				{Package: "example.org/provider/apis/v1alpha1", Type: "BucketList", MethodSets: []string{MethodSetManagedList}},
+				{Package: "example.org/provider/apis/v1alpha1", Type: "Key", MethodSets: []string{MethodSetManaged}},
+				{Package: "example.org/provider/apis/v1alpha1", Type: "KeyList", MethodSets: []string{MethodSetManagedList}},
+			},
+		},
+		"Allowlist": {
+			reason: "Types that are not allowlisted should not be reported.",
+			cfg:    Config{Patterns: []string{"./apis/v1alpha1"}, Allowlist: []string{"example.org/provider/apis/v1alpha1.Bucket"}},
+			want: []TypeMethodSets{
+				{Package: "example.org/provider/apis/v1alpha1", Type: "Bucket", MethodSets: []string{MethodSetManaged, MethodSetResolvers}},
+			},
+		},
+		"HubOnly": {
+			reason: "Reference resolvers should only be reported for the storage versions of kinds.",
+			cfg:    Config{Patterns: []string{"./apis/hub/..."}, Only: []string{MethodSetManaged, MethodSetResolvers}, HubOnly: true},
+			want: []TypeMethodSets{
+				{Package: "example.org/provider/apis/hub/v1", Type: "Gizmo", MethodSets: []string{MethodSetManaged}},
+				{Package: "example.org/provider/apis/hub/v1", Type: "Widget", MethodSets: []string{MethodSetManaged, MethodSetResolvers}},
+				{Package: "example.org/provider/apis/hub/v1beta1", Type: "Gizmo", MethodSets: []string{MethodSetManaged}},
+				{Package: "example.org/provider/apis/hub/v1beta1", Type: "Widget", MethodSets: []string{MethodSetManaged}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cfg := tc.cfg
+			cfg.Dir, cfg.Env = provider, env
+			cfg.Write = func(_ string, _ []byte) error { return nil }
+			r, err := Run(context.Background(), cfg)
+			if err != nil {
+				t.Fatalf("\n%s\nRun(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, r.Types); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want types, +got types:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRunAllowlist(t *testing.T) {
+	type want struct {
+		contains    []string
+		notContains []string
+		err         error
+	}
+	cases := map[string]struct {
+		reason    string
+		allowlist []string
+		want      want
+	}{
+		"Allowlisted": {
+			reason:    "Methods should only be generated for allowlisted types.",
+			allowlist: []string{"example.org/provider/apis/v1alpha1.Bucket"},
+			want: want{
+				contains:    []string{"func (mg *Bucket) GetCondition("},
+				notContains: []string{"func (mg *Key) GetCondition("},
+			},
+		},
+		"Glob": {
+			reason:    "Methods should be generated for types that a glob matches.",
+			allowlist: []string{"example.org/provider/apis/v1alpha1.*"},
+			want: want{
+				contains: []string{"func (mg *Bucket) GetCondition(", "func (mg *Key) GetCondition("},
+			},
+		},
+		"Unmatched": {
+			reason:    "Entries that match no type should be an error.",
+			allowlist: []string{"example.org/provider/apis/v1alpha1.Bucket", "example.org/provider/apis/v1alpha1.Removed", "example.org/provider/apis/gone.*"},
+			want: want{
+				err: errors.New("allowlist entries match no type of the loaded packages: example.org/provider/apis/v1alpha1.Removed, example.org/provider/apis/gone.*"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			managed := ""
+			cfg := Config{
+				Patterns:  []string{"./apis/v1alpha1"},
+				Dir:       provider,
+				Env:       env,
+				Only:      []string{MethodSetManaged},
+				Allowlist: tc.allowlist,
+				Write: func(_ string, data []byte) error {
+					managed = string(data)
+					return nil
+				},
+			}
+			_, err := Run(context.Background(), cfg)
+			if diff := cmp.Diff(tc.want.err, err, cmpErrors()); diff != "" {
+				t.Fatalf("\n%s\nRun(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			for _, want := range tc.want.contains {
+				if !strings.Contains(managed, want) {
+					t.Errorf("\n%s\nRun(...): generated file should contain %q:\n%s", tc.reason, want, managed)
+				}
+			}
+			for _, want := range tc.want.notContains {
+				if strings.Contains(managed, want) {
+					t.Errorf("\n%s\nRun(...): generated file should not contain %q:\n%s", tc.reason, want, managed)
+				}
+			}
+		})
+	}
+}
+
+// copyDir copies the files of the supplied source directory tree to the
+// supplied destination directory.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0o750)
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), b, 0o600)
+	})
+}
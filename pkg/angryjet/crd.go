@@ -0,0 +1,129 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/crossplane/crossplane-tools/pkg/comments"
+)
+
+// CRDReferencesAnnotation is the annotation of a CustomResourceDefinition that
+// describes the references of its kind, as a JSON array of DescribedReferences
+// in the form printed by Describe.
+const CRDReferencesAnnotation = "crossplane.io/references"
+
+// groupNameMarker is the marker of a package of API types that names their
+// API group, as read by controller-gen.
+const groupNameMarker = "groupName"
+
+// CRDReferences describe the references of the kind of a
+// CustomResourceDefinition, as read from its CRDReferencesAnnotation.
+type CRDReferences struct {
+	// Group is the API group of the kind, for example
+	// ec2.aws.crossplane.io.
+	Group string
+
+	// Kind is the kind, for example VPC.
+	Kind string
+
+	// Versions are the API versions of the kind, for example v1beta1.
+	Versions []string
+
+	// References are the fields of the kind that are resolved from
+	// references, in every version.
+	References []DescribedReference
+}
+
+// ReadCRDReferences returns the CRDReferences of the CustomResourceDefinitions
+// in the supplied YAML or JSON, which may hold several documents. Definitions
+// without a CRDReferencesAnnotation, and documents of other kinds, are skipped.
+func ReadCRDReferences(data []byte) ([]CRDReferences, error) {
+	refs := make([]CRDReferences, 0)
+	d := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		crd := &extv1.CustomResourceDefinition{}
+		err := d.Decode(crd)
+		if err == io.EOF {
+			return refs, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot decode CustomResourceDefinition")
+		}
+		a, ok := crd.GetAnnotations()[CRDReferencesAnnotation]
+		if crd.Kind != "CustomResourceDefinition" || !ok {
+			continue
+		}
+		r := CRDReferences{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}
+		for _, v := range crd.Spec.Versions {
+			r.Versions = append(r.Versions, v.Name)
+		}
+		if err := json.Unmarshal([]byte(a), &r.References); err != nil {
+			return nil, errors.Wrapf(err, "cannot unmarshal %s annotation of CustomResourceDefinition %s", CRDReferencesAnnotation, crd.GetName())
+		}
+		refs = append(refs, r)
+	}
+}
+
+// crdDescriptions returns Descriptions of the types of the supplied package
+// whose references are described by the configured CRDReferences: those whose
+// name is the kind of a definition, in a package whose groupName marker is the
+// group of the definition, and whose path ends in one of its versions.
+func (c Config) crdDescriptions(p *packages.Package) []Description {
+	if len(c.CRDReferences) == 0 {
+		return nil
+	}
+	group := groupName(p)
+	descriptions := make([]Description, 0)
+	for _, r := range c.CRDReferences {
+		if r.Group != group || p.Types.Scope().Lookup(r.Kind) == nil {
+			continue
+		}
+		for _, v := range r.Versions {
+			if v == path.Base(p.PkgPath) {
+				descriptions = append(descriptions, Description{Package: p.PkgPath, Type: r.Kind, References: r.References})
+				break
+			}
+		}
+	}
+	return descriptions
+}
+
+// groupName returns the value of the groupName marker of the supplied package,
+// from the comments before the package clause of any of its files, or an empty
+// string if it has none.
+func groupName(p *packages.Package) string {
+	for _, f := range p.Syntax {
+		for _, cg := range f.Comments {
+			if cg.End() > f.Package {
+				break
+			}
+			if v := comments.ParseMarkers(cg.Text())[groupNameMarker]; len(v) > 0 {
+				return v[0]
+			}
+		}
+	}
+	return ""
+}
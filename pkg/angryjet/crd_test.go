@@ -0,0 +1,188 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+// crd returns a CustomResourceDefinition of the supplied kind of group
+// example.org, whose references are described by the supplied annotation.
+func crd(kind, annotation string, versions ...string) string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: %ss.example.org
+`, strings.ToLower(kind))
+	if annotation != "" {
+		fmt.Fprintf(b, "  annotations:\n    %s: '%s'\n", CRDReferencesAnnotation, annotation)
+	}
+	fmt.Fprintf(b, "spec:\n  group: example.org\n  names:\n    kind: %s\n  scope: Cluster\n  versions:\n", kind)
+	for _, v := range versions {
+		fmt.Fprintf(b, "  - name: %s\n    served: true\n    storage: true\n", v)
+	}
+	return b.String()
+}
+
+func TestReadCRDReferences(t *testing.T) {
+	type want struct {
+		refs []CRDReferences
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		data   string
+		want   want
+	}{
+		"Annotated": {
+			reason: "The references described by the annotations of definitions should be read from each document.",
+			data: crd("Widget", `[{"field":"Spec.ForProvider.GizmoID","to":"Gizmo"}]`, "v1alpha1", "v1beta1") + "---\n" +
+				crd("Gadget", "", "v1alpha1") + "---\n" +
+				"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n",
+			want: want{
+				refs: []CRDReferences{{
+					Group:      "example.org",
+					Kind:       "Widget",
+					Versions:   []string{"v1alpha1", "v1beta1"},
+					References: []DescribedReference{{Field: "Spec.ForProvider.GizmoID", To: "Gizmo"}},
+				}},
+			},
+		},
+		"InvalidAnnotation": {
+			reason: "An annotation that isn't a JSON array of described references should be an error.",
+			data:   crd("Widget", `{"field":"Spec.ForProvider.GizmoID"}`, "v1alpha1"),
+			want: want{
+				err: errors.Wrap(errors.New("json: cannot unmarshal object into Go value of type []angryjet.DescribedReference"), "cannot unmarshal crossplane.io/references annotation of CustomResourceDefinition widgets.example.org"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			refs, err := ReadCRDReferences([]byte(tc.data))
+			if diff := cmp.Diff(tc.want.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nReadCRDReferences(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.refs, refs); diff != "" {
+				t.Errorf("\n%s\nReadCRDReferences(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRunCRDReferences(t *testing.T) {
+	resolvers := func(t *testing.T, pattern string, refs []CRDReferences) (string, error) {
+		t.Helper()
+		var got string
+		_, err := Run(context.Background(), Config{
+			Patterns:      []string{pattern},
+			Dir:           provider,
+			Env:           env,
+			CRDReferences: refs,
+			Only:          []string{MethodSetResolvers},
+			Write: func(filename string, data []byte) error {
+				if filepath.Base(filename) == DefaultFilenameResolvers {
+					got = string(data)
+				}
+				return nil
+			},
+		})
+		return got, err
+	}
+	// The references of package marked are described by an annotation of the
+	// definition of its Widget.
+	annotated := func(t *testing.T, version string, references []DescribedReference) []CRDReferences {
+		t.Helper()
+		a, err := json.Marshal(references)
+		if err != nil {
+			t.Fatal(err)
+		}
+		refs, err := ReadCRDReferences([]byte(crd("Widget", string(a), version)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return refs
+	}
+
+	want, err := resolvers(t, "./apis/marked", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		reason  string
+		pattern string
+		refs    []CRDReferences
+		want    string
+		err     error
+	}{
+		"RoundTrip": {
+			reason:  "The reference resolver generated from the annotation of a definition should be the one generated from the markers it describes.",
+			pattern: "./apis/described",
+			refs:    annotated(t, "described", marked("example.org/provider/apis/described")[0].References),
+			want:    strings.Replace(want, "package marked", "package described", 1),
+		},
+		"MarkersWin": {
+			reason:  "Fields that have reference markers should keep them, rather than those described by the annotation of a definition.",
+			pattern: "./apis/marked",
+			refs:    annotated(t, "marked", []DescribedReference{{Field: "Spec.ForProvider.GizmoID", To: "Gadget"}}),
+			want:    want,
+		},
+		"OtherVersion": {
+			reason:  "The annotation of a definition should not describe the references of packages of other versions.",
+			pattern: "./apis/described",
+			refs:    annotated(t, "v1beta1", marked("example.org/provider/apis/described")[0].References),
+		},
+		"OtherGroup": {
+			reason:  "The annotation of a definition should not describe the references of packages of other groups.",
+			pattern: "./apis/described",
+			refs: []CRDReferences{{
+				Group:      "other.example.org",
+				Kind:       "Widget",
+				Versions:   []string{"described"},
+				References: marked("example.org/provider/apis/described")[0].References,
+			}},
+		},
+		"FieldDoesNotExist": {
+			reason:  "The annotation of a definition that describes a field that doesn't exist should return an error.",
+			pattern: "./apis/described",
+			refs:    annotated(t, "described", []DescribedReference{{Field: "Spec.ForProvider.GizmoName", To: "Gizmo"}}),
+			err:     errors.New("cannot write reference resolvers for package example.org/provider/apis/described: cannot describe references of Widget from its CustomResourceDefinition: described field Spec.ForProvider.GizmoName of Widget does not exist"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := resolvers(t, tc.pattern, tc.refs)
+			if diff := cmp.Diff(tc.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want resolvers, +got resolvers:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
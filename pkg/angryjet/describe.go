@@ -0,0 +1,276 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	gotypes "go/types"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/crossplane/crossplane-tools/internal/match"
+	"github.com/crossplane/crossplane-tools/internal/method"
+	"github.com/crossplane/crossplane-tools/internal/types"
+	"github.com/crossplane/crossplane-tools/pkg/comments"
+)
+
+// referenceMarkerPrefix is the prefix of the markers of the fields of managed
+// resources that are resolved from references.
+const referenceMarkerPrefix = "crossplane:generate:reference:"
+
+// A Description describes the references of a managed resource, so that its
+// reference resolver may be generated from it rather than from markers in its
+// source. Its package, type and fields are named as they are by a Finding.
+type Description struct {
+	// Package is the path of the package that defines the managed resource.
+	Package string `json:"package"`
+
+	// Type is the name of the managed resource.
+	Type string `json:"type"`
+
+	// References are the fields of the managed resource that are resolved
+	// from references.
+	References []DescribedReference `json:"references"`
+}
+
+// A DescribedReference describes a field of a managed resource that is resolved
+// from a reference.
+type DescribedReference struct {
+	// Field is the Go path of the field, for example Spec.ForProvider.SubnetID
+	// or Spec.ForProvider.Rules[*].SubnetID.
+	Field string `json:"field"`
+
+	// To is the referenced type, as the value of the type marker, for example
+	// Subnet or example.org/ec2/v1beta1.Subnet.
+	To string `json:"to,omitempty"`
+
+	// Extractor is the extractor function of the referenced type, as the
+	// value of the extractor marker, if it has one.
+	Extractor string `json:"extractor,omitempty"`
+
+	// Markers are the values of any other reference markers of the field, by
+	// name without the crossplane:generate:reference: prefix, for example
+	// listType or refFieldName.
+	Markers map[string][]string `json:"markers,omitempty"`
+}
+
+// empty returns true if the field has no reference markers.
+func (r DescribedReference) empty() bool {
+	return r.To == "" && r.Extractor == "" && len(r.Markers) == 0
+}
+
+// markers returns the reference markers of the supplied field as a comment.
+func (r DescribedReference) markers() string {
+	m := make(map[string][]string, len(r.Markers)+2)
+	for k, v := range r.Markers {
+		m[referenceMarkerPrefix+k] = v
+	}
+	if r.To != "" {
+		m[method.ReferenceTypeMarker] = append([]string{r.To}, m[method.ReferenceTypeMarker]...)
+	}
+	if r.Extractor != "" {
+		m[method.ReferenceExtractorMarker] = append([]string{r.Extractor}, m[method.ReferenceExtractorMarker]...)
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	b := &strings.Builder{}
+	for _, k := range keys {
+		for _, v := range m[k] {
+			b.WriteString(comments.DefaultMarkerPrefix + k)
+			if v != "" {
+				b.WriteString("=" + v)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// describe returns a DescribedReference of the field at the supplied path that
+// has the supplied reference markers.
+func describe(field string, markers comments.Markers) DescribedReference {
+	r := DescribedReference{Field: field}
+	for k, v := range markers {
+		switch {
+		case !strings.HasPrefix(k, referenceMarkerPrefix):
+			continue
+		case k == method.ReferenceTypeMarker && len(v) == 1:
+			r.To = v[0]
+		case k == method.ReferenceExtractorMarker && len(v) == 1:
+			r.Extractor = v[0]
+		default:
+			if r.Markers == nil {
+				r.Markers = map[string][]string{}
+			}
+			r.Markers[strings.TrimPrefix(k, referenceMarkerPrefix)] = v
+		}
+	}
+	return r
+}
+
+// fieldsByPath returns the fields of the supplied managed resource by Go path,
+// and calls the supplied function with each of them, if it is not nil.
+func fieldsByPath(t *types.Traverser, n *gotypes.Named, fn func(path string, f *gotypes.Var, comment string)) (map[string]*gotypes.Var, error) {
+	vars := map[string]*gotypes.Var{}
+	err := t.Traverse(n, &types.ProcessorConfig{
+		Named: types.NamedProcessorChain{},
+		Field: types.FieldProcessorFn(func(_ *gotypes.Named, f *gotypes.Var, _, comment string, parentFields ...string) error {
+			path := method.GoPath(append(append([]string{}, parentFields...), f.Name())...)
+			if _, ok := vars[path]; ok {
+				return nil
+			}
+			vars[path] = f
+			if fn != nil {
+				fn(path, f, comment)
+			}
+			return nil
+		}),
+	})
+	return vars, err
+}
+
+// Describe loads the packages matching the configured patterns and returns a
+// Description of the references of each of their managed resources that has
+// any, from the reference markers of its fields. Markers of types, such as the
+// oneOf marker of a union struct, are not described.
+func Describe(ctx context.Context, cfg Config) ([]Description, error) {
+	pkgs, err := packages.Load(&packages.Config{Context: ctx, Mode: LoadMode, Dir: cfg.Dir, Env: LoadEnv(cfg.Env), Overlay: cfg.Overlay}, cfg.Patterns...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot load packages %v", cfg.Patterns)
+	}
+
+	descriptions := make([]Description, 0)
+	for _, p := range pkgs {
+		if len(p.Errors) > 0 {
+			return nil, errors.Wrapf(p.Errors[0], "cannot load package %s", p.PkgPath)
+		}
+		m := cfg.matcher(p, match.Managed())
+		t := cfg.traverser(p, commentsIn(p), nil)
+		for _, n := range p.Types.Scope().Names() {
+			o := p.Types.Scope().Lookup(n)
+			named, ok := o.Type().(*gotypes.Named)
+			if !ok || !m.Match(o) {
+				continue
+			}
+			d := Description{Package: p.PkgPath, Type: o.Name(), References: []DescribedReference{}}
+			_, err := fieldsByPath(t, named, func(path string, _ *gotypes.Var, comment string) {
+				if r := describe(path, comments.ParseMarkers(comment)); !r.empty() {
+					d.References = append(d.References, r)
+				}
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot describe references of %s", o.Name())
+			}
+			if len(d.References) > 0 {
+				descriptions = append(descriptions, d)
+			}
+		}
+	}
+	return descriptions, nil
+}
+
+// comments returns the comments of the supplied package, with the markers of
+// the configured descriptions of its managed resources added to the comments
+// of their fields. It returns an error if a described type or field doesn't
+// exist, or if a described field already has reference markers. The markers
+// of references described by CustomResourceDefinitions are then added to the
+// comments of fields that have no reference markers; those that have keep
+// their own.
+func (c Config) comments(p *packages.Package) (comments.Comments, error) {
+	comm := commentsIn(p)
+	described := map[*gotypes.Var]string{}
+	var err error
+	for _, d := range c.Descriptions {
+		if d.Package != p.PkgPath {
+			continue
+		}
+		if comm, err = c.describeFields(p, comm, described, d, false); err != nil {
+			return comm, err
+		}
+	}
+	for _, d := range c.crdDescriptions(p) {
+		if comm, err = c.describeFields(p, comm, described, d, true); err != nil {
+			return comm, errors.Wrapf(err, "cannot describe references of %s from its CustomResourceDefinition", d.Type)
+		}
+	}
+	return comm, nil
+}
+
+// describeFields returns the supplied comments with the markers of the
+// supplied description added to the comments of the fields it describes, which
+// are recorded in the supplied map. Fields that already have reference markers
+// are skipped if skipMarked is true, or are otherwise an error.
+func (c Config) describeFields(p *packages.Package, comm comments.Comments, described map[*gotypes.Var]string, d Description, skipMarked bool) (comments.Comments, error) {
+	o := p.Types.Scope().Lookup(d.Type)
+	if o == nil {
+		return comm, errors.Errorf("described type %s does not exist in package %s", d.Type, d.Package)
+	}
+	named, ok := o.Type().(*gotypes.Named)
+	if !ok {
+		return comm, errors.Errorf("described type %s is not a named type", d.Type)
+	}
+	vars, err := fieldsByPath(c.traverser(p, commentsIn(p), nil), named, nil)
+	if err != nil {
+		return comm, errors.Wrapf(err, "cannot find fields of described type %s", d.Type)
+	}
+	for _, r := range d.References {
+		f, ok := vars[r.Field]
+		if !ok {
+			return comm, errors.Errorf("described field %s of %s does not exist", r.Field, d.Type)
+		}
+		markers := r.markers()
+		if m, ok := described[f]; ok {
+			// The fields of a struct that appears in several places
+			// are described at each of them.
+			if m != markers && !skipMarked {
+				return comm, errors.Errorf("described field %s of %s is described differently elsewhere", r.Field, d.Type)
+			}
+			continue
+		}
+		if !describe(r.Field, comments.ParseMarkers(comm.For(f))).empty() {
+			if skipMarked {
+				continue
+			}
+			return comm, errors.Errorf("described field %s of %s already has reference markers", r.Field, d.Type)
+		}
+		described[f] = markers
+		comm = comm.With(f, markers)
+	}
+	return comm, nil
+}
+
+// validateDescriptions returns an error if the package of a configured
+// description is not one of the supplied loaded packages.
+func validateDescriptions(cfg Config, pkgs []*packages.Package) error {
+	loaded := make(map[string]bool, len(pkgs))
+	for _, p := range pkgs {
+		loaded[p.PkgPath] = true
+	}
+	for _, d := range cfg.Descriptions {
+		if !loaded[d.Package] {
+			return errors.Errorf("package %s of described type %s is not loaded", d.Package, d.Type)
+		}
+	}
+	return nil
+}
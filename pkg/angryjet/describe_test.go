@@ -0,0 +1,170 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+// marked describes the references of the Widget of package marked, as if they
+// were those of the Widget of the supplied package.
+func marked(pkg string) []Description {
+	return []Description{{
+		Package: pkg,
+		Type:    "Widget",
+		References: []DescribedReference{
+			{
+				Field:     "Spec.ForProvider.GizmoID",
+				To:        "Gizmo",
+				Extractor: "github.com/crossplane/crossplane-runtime/pkg/reference.ExternalName()",
+			},
+			{
+				Field:   "Spec.ForProvider.GadgetIDs",
+				To:      "Gadget",
+				Markers: map[string][]string{"listType": {"Gadgets"}},
+			},
+			{
+				Field: "Spec.ForProvider.Rules[*].GizmoID",
+				To:    "Gizmo",
+			},
+		},
+	}}
+}
+
+func TestDescribe(t *testing.T) {
+	type want struct {
+		descriptions []Description
+		err          error
+	}
+
+	cases := map[string]struct {
+		reason   string
+		patterns []string
+		want     want
+	}{
+		"Marked": {
+			reason:   "The reference markers of the fields of a managed resource should be described.",
+			patterns: []string{"./apis/marked"},
+			want: want{
+				descriptions: marked("example.org/provider/apis/marked"),
+			},
+		},
+		"Unmarked": {
+			reason:   "Managed resources without reference markers should not be described.",
+			patterns: []string{"./apis/described"},
+			want: want{
+				descriptions: []Description{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Describe(context.Background(), Config{Patterns: tc.patterns, Dir: provider, Env: env})
+			if diff := cmp.Diff(tc.want.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nDescribe(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.descriptions, got); diff != "" {
+				t.Errorf("\n%s\nDescribe(...): -want descriptions, +got descriptions:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRunDescribed(t *testing.T) {
+	resolvers := func(t *testing.T, pattern string, descriptions []Description) (string, error) {
+		t.Helper()
+		var got string
+		_, err := Run(context.Background(), Config{
+			Patterns:     []string{pattern},
+			Dir:          provider,
+			Env:          env,
+			Descriptions: descriptions,
+			Only:         []string{MethodSetResolvers},
+			Write: func(filename string, data []byte) error {
+				if filepath.Base(filename) == DefaultFilenameResolvers {
+					got = string(data)
+				}
+				return nil
+			},
+		})
+		return got, err
+	}
+
+	want, err := resolvers(t, "./apis/marked", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = strings.Replace(want, "package marked", "package described", 1)
+
+	cases := map[string]struct {
+		reason       string
+		pattern      string
+		descriptions []Description
+		want         string
+		err          error
+	}{
+		"RoundTrip": {
+			reason:       "The reference resolver generated from the description of marked references should be the one generated from the markers.",
+			pattern:      "./apis/described",
+			descriptions: marked("example.org/provider/apis/described"),
+			want:         want,
+		},
+		"AlreadyMarked": {
+			reason:       "Describing a field that already has reference markers should return an error.",
+			pattern:      "./apis/marked",
+			descriptions: marked("example.org/provider/apis/marked"),
+			err:          errors.New("cannot write reference resolvers for package example.org/provider/apis/marked: described field Spec.ForProvider.GizmoID of Widget already has reference markers"),
+		},
+		"FieldDoesNotExist": {
+			reason:       "Describing a field that doesn't exist should return an error.",
+			pattern:      "./apis/described",
+			descriptions: []Description{{Package: "example.org/provider/apis/described", Type: "Widget", References: []DescribedReference{{Field: "Spec.ForProvider.GizmoName", To: "Gizmo"}}}},
+			err:          errors.New("cannot write reference resolvers for package example.org/provider/apis/described: described field Spec.ForProvider.GizmoName of Widget does not exist"),
+		},
+		"TypeDoesNotExist": {
+			reason:       "Describing a type that doesn't exist should return an error.",
+			pattern:      "./apis/described",
+			descriptions: []Description{{Package: "example.org/provider/apis/described", Type: "Doohickey"}},
+			err:          errors.New("cannot write reference resolvers for package example.org/provider/apis/described: described type Doohickey does not exist in package example.org/provider/apis/described"),
+		},
+		"PackageNotLoaded": {
+			reason:       "Describing a type of a package that isn't loaded should return an error.",
+			pattern:      "./apis/described",
+			descriptions: marked("example.org/provider/apis/marked"),
+			err:          errors.New("package example.org/provider/apis/marked of described type Widget is not loaded"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := resolvers(t, tc.pattern, tc.descriptions)
+			if diff := cmp.Diff(tc.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want resolvers, +got resolvers:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
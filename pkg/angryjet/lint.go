@@ -0,0 +1,383 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	gotypes "go/types"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/crossplane/crossplane-tools/internal/match"
+	"github.com/crossplane/crossplane-tools/internal/method"
+	"github.com/crossplane/crossplane-tools/pkg/comments"
+)
+
+// IDs of the findings that Lint returns, which the SuppressMarker suppresses.
+const (
+	FindingMissingType       = "missing-type"
+	FindingMissingListType   = "missing-list-type"
+	FindingListItems         = "list-items"
+	FindingDeprecatedPackage = "deprecated-package"
+
+	// FindingUnknownSuppression is the ID of the warning that a SuppressMarker
+	// names an ID that is not one of the above. It cannot be suppressed.
+	FindingUnknownSuppression = "unknown-suppression"
+)
+
+// findingIDs are the IDs of the findings that the SuppressMarker suppresses.
+var findingIDs = []string{FindingMissingType, FindingMissingListType, FindingListItems, FindingDeprecatedPackage}
+
+// A Finding is a problem with a reference of a managed resource that would
+// otherwise only be found when its generated resolver is compiled.
+type Finding struct {
+	// ID identifies the kind of problem, for example missing-type.
+	ID string `json:"id"`
+
+	// Package is the path of the package that defines the managed resource.
+	Package string `json:"package"`
+
+	// Type is the name of the managed resource. It is empty for a warning of
+	// a package.
+	Type string `json:"type"`
+
+	// Field is the Go path of the field that is resolved from the reference,
+	// for example Spec.ForProvider.SubnetID. It is empty for a warning of a
+	// package or managed resource.
+	Field string `json:"field"`
+
+	// Position is the position of the field, as file:line:column.
+	Position string `json:"position"`
+
+	// Message describes the problem.
+	Message string `json:"message"`
+
+	// Deprecated is true if the problem is that the referenced type is in a
+	// package that is deprecated by DeprecatedMarker. The reference works,
+	// but should be migrated to another version of the referenced type.
+	Deprecated bool `json:"deprecated,omitempty"`
+
+	// Suppressed is true if the problem is suppressed by a SuppressMarker of
+	// the field, the managed resource, or its package. It is reported so that
+	// suppressions can be audited, but is not a problem.
+	Suppressed bool `json:"suppressed,omitempty"`
+
+	// Warning is true if the finding is not a problem with a reference, but
+	// with a SuppressMarker.
+	Warning bool `json:"warning,omitempty"`
+}
+
+func (f Finding) String() string {
+	what := f.Type
+	if f.Field != "" {
+		what += "." + f.Field
+	}
+	if what == "" {
+		return fmt.Sprintf("%s: %s [%s]", f.Position, f.Message, f.ID)
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", f.Position, what, f.Message, f.ID)
+}
+
+// Lint loads the packages matching the configured patterns and returns a
+// Finding for each reference of their managed resources whose referenced type
+// doesn't exist, whose list type doesn't exist, or whose list type has no Items
+// field of the referenced type. A Finding is also returned for each reference
+// to a type in another package that is deprecated by DeprecatedMarker. The
+// packages of referenced types are parsed, but not type checked. Findings
+// whose IDs are named by a SuppressMarker of their field, managed resource, or
+// package are returned as Suppressed, and a Warning is returned for each ID
+// that a SuppressMarker names that is not the ID of a finding.
+func Lint(ctx context.Context, cfg Config) ([]Finding, error) {
+	pkgs, err := packages.Load(&packages.Config{Context: ctx, Mode: LoadMode, Dir: cfg.Dir, Env: LoadEnv(cfg.Env), Overlay: cfg.Overlay}, cfg.Patterns...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot load packages %v", cfg.Patterns)
+	}
+	return lint(ctx, cfg, pkgs)
+}
+
+// deprecations returns a warning for each reference of a managed resource in
+// the supplied packages to a type in another package that is deprecated by
+// DeprecatedMarker, as Lint finds them, by the path of the package of the
+// managed resource. Suppressed findings are not warned of.
+func deprecations(ctx context.Context, cfg Config, pkgs []*packages.Package) (map[string][]TypeWarning, error) {
+	findings, err := lint(ctx, cfg, pkgs)
+	if err != nil {
+		return nil, err
+	}
+	w := map[string][]TypeWarning{}
+	for _, f := range findings {
+		if !f.Deprecated || f.Suppressed {
+			continue
+		}
+		w[f.Package] = append(w[f.Package], TypeWarning{
+			Package: f.Package,
+			Type:    f.Type,
+			Message: fmt.Sprintf("field %s: %s", f.Field, f.Message),
+		})
+	}
+	return w, nil
+}
+
+// lint returns the findings of Lint for the supplied packages, as loaded with
+// LoadMode.
+func lint(ctx context.Context, cfg Config, pkgs []*packages.Package) ([]Finding, error) {
+	type reference struct {
+		p        *packages.Package
+		typ      string
+		ref      method.Reference
+		suppress map[string]bool
+	}
+	refs := make([]reference, 0)
+	warnings := make([]Finding, 0)
+	warn := func(p *packages.Package, pos token.Pos, typ, field string, unknown []string) {
+		for _, id := range unknown {
+			warnings = append(warnings, Finding{
+				ID:       FindingUnknownSuppression,
+				Package:  p.PkgPath,
+				Type:     typ,
+				Field:    field,
+				Position: p.Fset.Position(pos).String(),
+				Message:  fmt.Sprintf("unknown finding ID %q in %s marker; finding IDs are %s", id, SuppressMarker, strings.Join(findingIDs, ", ")),
+				Warning:  true,
+			})
+		}
+	}
+	files := map[string][]*ast.File{}
+	for _, p := range pkgs {
+		if len(p.Errors) > 0 {
+			return nil, errors.Wrapf(p.Errors[0], "cannot load package %s", p.PkgPath)
+		}
+		files[p.PkgPath] = p.Syntax
+		pkgSuppress := map[string]bool{}
+		for _, f := range p.Syntax {
+			if f.Doc == nil {
+				continue
+			}
+			unknown := suppressions(f.Doc.Text(), pkgSuppress)
+			warn(p, f.Package, "", "", unknown)
+		}
+
+		comm := commentsIn(p)
+		m := cfg.matcher(p, match.Managed())
+		t := cfg.traverser(p, comm, nil)
+		for _, n := range p.Types.Scope().Names() {
+			o := p.Types.Scope().Lookup(n)
+			named, ok := o.Type().(*gotypes.Named)
+			if !ok || !m.Match(o) {
+				continue
+			}
+			typeSuppress := cloneIDs(pkgSuppress)
+			warn(p, o.Pos(), o.Name(), "", suppressions(comm.For(o)+comm.Before(o), typeSuppress))
+
+			// Errors traversing the type are reported when its methods
+			// are generated.
+			rs, _ := method.References(t, cfg.runtimeImports(p).Runtime, named)
+			warned := map[token.Pos]bool{}
+			for _, r := range rs {
+				suppress := cloneIDs(typeSuppress)
+				unknown := suppressions(comm.At(r.Pos), suppress)
+				if !warned[r.Pos] {
+					warned[r.Pos] = true
+					warn(p, r.Pos, o.Name(), method.GoPath(r.GoValueFieldPath[1:]...), unknown)
+				}
+				refs = append(refs, reference{p: p, typ: o.Name(), ref: r, suppress: suppress})
+			}
+		}
+	}
+
+	missing := make([]string, 0)
+	for _, r := range refs {
+		for _, path := range []string{r.ref.RemoteTypePath, r.ref.RemoteListTypePath} {
+			pkg, _ := splitTypePath(path, r.p.PkgPath)
+			if _, ok := files[pkg]; !ok {
+				files[pkg] = nil
+				missing = append(missing, pkg)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		targets, err := packages.Load(&packages.Config{Context: ctx, Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax, Dir: cfg.Dir, Env: LoadEnv(cfg.Env), Overlay: cfg.Overlay}, missing...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot load packages of referenced types %v", missing)
+		}
+		for _, p := range targets {
+			if len(p.Errors) == 0 {
+				files[p.PkgPath] = p.Syntax
+			}
+		}
+	}
+
+	findings := make([]Finding, 0)
+	for _, r := range refs {
+		f := Finding{
+			Package:  r.p.PkgPath,
+			Type:     r.typ,
+			Field:    method.GoPath(r.ref.GoValueFieldPath[1:]...),
+			Position: r.p.Fset.Position(r.ref.Pos).String(),
+		}
+		if id, msg := lintReference(files, r.p.PkgPath, r.ref); msg != "" {
+			f.ID = id
+			f.Message = msg
+			f.Suppressed = r.suppress[id]
+			findings = append(findings, f)
+		}
+		if msg := lintDeprecation(files, r.p.PkgPath, r.ref); msg != "" {
+			f.ID = FindingDeprecatedPackage
+			f.Message = msg
+			f.Deprecated = true
+			f.Suppressed = r.suppress[FindingDeprecatedPackage]
+			findings = append(findings, f)
+		}
+	}
+	return append(findings, warnings...), nil
+}
+
+// suppressions adds the finding IDs that the SuppressMarker markers of the
+// supplied comment suppress to the supplied set, and returns those that are
+// not the IDs of findings.
+func suppressions(comment string, suppress map[string]bool) []string {
+	known := map[string]bool{}
+	for _, id := range findingIDs {
+		known[id] = true
+	}
+	var unknown []string
+	for _, v := range comments.ParseMarkers(comment)[SuppressMarker] {
+		for _, id := range strings.Split(v, ",") {
+			id = strings.TrimSpace(id)
+			if !known[id] {
+				unknown = append(unknown, id)
+				continue
+			}
+			suppress[id] = true
+		}
+	}
+	return unknown
+}
+
+// cloneIDs returns a copy of the supplied set of finding IDs.
+func cloneIDs(ids map[string]bool) map[string]bool {
+	out := make(map[string]bool, len(ids))
+	for id := range ids {
+		out[id] = true
+	}
+	return out
+}
+
+// lintDeprecation returns a message describing the deprecation of the package
+// of the referenced type of the supplied reference of a managed resource in
+// the supplied package, or an empty string if it is not deprecated. References
+// within a package are not reported; they are migrated with the package.
+func lintDeprecation(files map[string][]*ast.File, pkgPath string, ref method.Reference) string {
+	kindPkg, _ := splitTypePath(ref.RemoteTypePath, pkgPath)
+	if kindPkg == pkgPath {
+		return ""
+	}
+	for _, f := range files[kindPkg] {
+		if f.Doc == nil {
+			continue
+		}
+		v, ok := comments.ParseMarkers(f.Doc.Text())[DeprecatedMarker]
+		if !ok {
+			continue
+		}
+		if v[0] == "" {
+			return fmt.Sprintf("referenced type %s is in deprecated package %s", ref.RemoteTypePath, kindPkg)
+		}
+		return fmt.Sprintf("referenced type %s is in deprecated package %s; reference the type in %s instead", ref.RemoteTypePath, kindPkg, v[0])
+	}
+	return ""
+}
+
+// lintReference returns the ID of and a message describing the problem with the
+// referenced type or list type of the supplied reference of a managed resource
+// in the supplied package, or an empty message if there is none.
+func lintReference(files map[string][]*ast.File, pkgPath string, ref method.Reference) (string, string) {
+	kindPkg, kind := splitTypePath(ref.RemoteTypePath, pkgPath)
+	if _, _, ok := findStruct(files[kindPkg], kind); !ok {
+		return FindingMissingType, fmt.Sprintf("referenced type %s does not exist", ref.RemoteTypePath)
+	}
+
+	listPkg, list := splitTypePath(ref.RemoteListTypePath, pkgPath)
+	st, f, ok := findStruct(files[listPkg], list)
+	if !ok {
+		return FindingMissingListType, fmt.Sprintf("list type %s of referenced type %s does not exist; set the %s marker to its list type", ref.RemoteListTypePath, ref.RemoteTypePath, method.ReferenceListTypeMarker)
+	}
+	for _, field := range st.Fields.List {
+		for _, name := range field.Names {
+			if name.Name == "Items" && isSliceOf(field.Type, f, listPkg, kindPkg, kind) {
+				return "", ""
+			}
+		}
+	}
+	return FindingListItems, fmt.Sprintf("list type %s must have an Items field of type []%s; set the %s marker to the list type of %s", ref.RemoteListTypePath, ref.RemoteTypePath, method.ReferenceListTypeMarker, ref.RemoteTypePath)
+}
+
+// splitTypePath returns the package path and name of the supplied type path,
+// which is either <package path>.<name> or a bare name in the supplied
+// package.
+func splitTypePath(path, pkgPath string) (string, string) {
+	i := strings.LastIndex(path, ".")
+	if i < 0 {
+		return pkgPath, path
+	}
+	return path[:i], path[i+1:]
+}
+
+// findStruct returns the struct type with the supplied name declared by the
+// supplied files, and the file that declares it.
+func findStruct(files []*ast.File, name string) (*ast.StructType, *ast.File, bool) {
+	for _, f := range files {
+		for _, d := range f.Decls {
+			gd, ok := d.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, s := range gd.Specs {
+				ts, ok := s.(*ast.TypeSpec)
+				if !ok || ts.Name.Name != name {
+					continue
+				}
+				st, ok := ts.Type.(*ast.StructType)
+				return st, f, ok
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// isSliceOf returns true if the supplied expression, which appears in the
+// supplied file of the supplied package, is a slice of the named type of the
+// supplied package.
+func isSliceOf(e ast.Expr, f *ast.File, pkgPath, elemPkgPath, elem string) bool {
+	at, ok := e.(*ast.ArrayType)
+	if !ok || at.Len != nil {
+		return false
+	}
+	switch t := at.Elt.(type) {
+	case *ast.Ident:
+		return pkgPath == elemPkgPath && t.Name == elem
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		return ok && t.Sel.Name == elem && x.Name == importName(f, elemPkgPath)
+	}
+	return false
+}
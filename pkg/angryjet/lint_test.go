@@ -0,0 +1,193 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLint(t *testing.T) {
+	type want struct {
+		findings []Finding
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason   string
+		patterns []string
+		want     want
+	}{
+		"NoFindings": {
+			reason:   "References to kinds with a list type of the kind should not be reported.",
+			patterns: []string{"./apis/v1alpha1"},
+			want: want{
+				findings: []Finding{},
+			},
+		},
+		"NoListType": {
+			reason:   "A reference to a kind without a list type should be reported.",
+			patterns: []string{"./apis/nolist"},
+			want: want{
+				findings: []Finding{{
+					ID:       FindingMissingListType,
+					Package:  "example.org/provider/apis/nolist",
+					Type:     "Gadget",
+					Field:    "Spec.ForProvider.GizmoID",
+					Position: "apis/nolist/types.go:14:2",
+					Message:  "list type GizmoList of referenced type Gizmo does not exist; set the crossplane:generate:reference:listType marker to its list type",
+				}},
+			},
+		},
+		"Mixed": {
+			reason:   "References to kinds that don't exist or whose list type has no Items of the kind should be reported, honoring the list type marker.",
+			patterns: []string{"./apis/lint"},
+			want: want{
+				findings: []Finding{
+					{
+						ID:       FindingListItems,
+						Package:  "example.org/provider/apis/lint",
+						Type:     "Widget",
+						Field:    "Spec.ForProvider.GizmoID",
+						Position: "apis/lint/types.go:16:2",
+						Message:  "list type GizmoList must have an Items field of type []Gizmo; set the crossplane:generate:reference:listType marker to the list type of Gizmo",
+					},
+					{
+						ID:       FindingMissingType,
+						Package:  "example.org/provider/apis/lint",
+						Type:     "Widget",
+						Field:    "Spec.ForProvider.DoohickeyID",
+						Position: "apis/lint/types.go:35:2",
+						Message:  "referenced type Doohickey does not exist",
+					},
+				},
+			},
+		},
+		"Deprecated": {
+			reason:   "References to kinds in other packages that are deprecated should be reported, naming the replacement package if there is one.",
+			patterns: []string{"./apis/deprecated/v1beta1"},
+			want: want{
+				findings: []Finding{
+					{
+						ID:         FindingDeprecatedPackage,
+						Package:    "example.org/provider/apis/deprecated/v1beta1",
+						Type:       "Widget",
+						Field:      "Spec.ForProvider.RetiredGizmoID",
+						Position:   "apis/deprecated/v1beta1/types.go:14:2",
+						Message:    "referenced type example.org/provider/apis/deprecated/v1alpha1.Gizmo is in deprecated package example.org/provider/apis/deprecated/v1alpha1",
+						Deprecated: true,
+					},
+					{
+						ID:         FindingDeprecatedPackage,
+						Package:    "example.org/provider/apis/deprecated/v1beta1",
+						Type:       "Widget",
+						Field:      "Spec.ForProvider.LegacyGizmoID",
+						Position:   "apis/deprecated/v1beta1/types.go:20:2",
+						Message:    "referenced type example.org/provider/apis/deprecated/v1alpha2.Gizmo is in deprecated package example.org/provider/apis/deprecated/v1alpha2; reference the type in example.org/provider/apis/deprecated/v1beta1 instead",
+						Deprecated: true,
+					},
+				},
+			},
+		},
+		"Suppressed": {
+			reason:   "Findings suppressed by markers of their field, managed resource, or package should be reported as suppressed, and unknown finding IDs should be warned of.",
+			patterns: []string{"./apis/suppressed"},
+			want: want{
+				findings: []Finding{
+					{
+						ID:         FindingListItems,
+						Package:    "example.org/provider/apis/suppressed",
+						Type:       "Widget",
+						Field:      "Spec.ForProvider.GizmoID",
+						Position:   "apis/suppressed/types.go:15:2",
+						Message:    "list type GizmoList must have an Items field of type []Gizmo; set the crossplane:generate:reference:listType marker to the list type of Gizmo",
+						Suppressed: true,
+					},
+					{
+						ID:         FindingMissingListType,
+						Package:    "example.org/provider/apis/suppressed",
+						Type:       "Widget",
+						Field:      "Spec.ForProvider.GadgetID",
+						Position:   "apis/suppressed/types.go:21:2",
+						Message:    "list type GadgetList of referenced type Gadget does not exist; set the crossplane:generate:reference:listType marker to its list type",
+						Suppressed: true,
+					},
+					{
+						ID:         FindingMissingType,
+						Package:    "example.org/provider/apis/suppressed",
+						Type:       "Widget",
+						Field:      "Spec.ForProvider.DoohickeyID",
+						Position:   "apis/suppressed/types.go:28:2",
+						Message:    "referenced type Doohickey does not exist",
+						Suppressed: true,
+					},
+					{
+						ID:       FindingMissingType,
+						Package:  "example.org/provider/apis/suppressed",
+						Type:     "Widget",
+						Field:    "Spec.ForProvider.WhatsitID",
+						Position: "apis/suppressed/types.go:35:2",
+						Message:  "referenced type Whatsit does not exist",
+					},
+					{
+						ID:       FindingUnknownSuppression,
+						Package:  "example.org/provider/apis/suppressed",
+						Type:     "",
+						Field:    "",
+						Position: "apis/suppressed/types.go:4:1",
+						Message:  `unknown finding ID "everything" in crossplane:generate:suppress marker; finding IDs are missing-type, missing-list-type, list-items, deprecated-package`,
+						Warning:  true,
+					},
+					{
+						ID:       FindingUnknownSuppression,
+						Package:  "example.org/provider/apis/suppressed",
+						Type:     "Widget",
+						Field:    "",
+						Position: "apis/suppressed/types.go:54:6",
+						Message:  `unknown finding ID "list_items" in crossplane:generate:suppress marker; finding IDs are missing-type, missing-list-type, list-items, deprecated-package`,
+						Warning:  true,
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, err := filepath.Abs(provider)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Lint(context.Background(), Config{Patterns: tc.patterns, Dir: provider, Env: env})
+			if diff := cmp.Diff(tc.want.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nLint(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			for i := range got {
+				if rel, err := filepath.Rel(dir, got[i].Position); err == nil {
+					got[i].Position = filepath.ToSlash(rel)
+				}
+			}
+			if diff := cmp.Diff(tc.want.findings, got); diff != "" {
+				t.Errorf("\n%s\nLint(...): -want findings, +got findings:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// Names of the method sets that Generate writes, which the MethodSets, Only,
+// and Skip fields of a Config select from.
+const (
+	MethodSetManaged          = "managed"
+	MethodSetManagedList      = "managedlist"
+	MethodSetPC               = "pc"
+	MethodSetPCU              = "pcu"
+	MethodSetPCUList          = "pculist"
+	MethodSetResolvers        = "resolvers"
+	MethodSetResolvableFields = "resolvablefields"
+	MethodSetResolversIndex   = "resolversindex"
+	MethodSetFixtures         = "fixtures"
+)
+
+// A methodSet is a method set that Generate writes.
+type methodSet struct {
+	name     string
+	generate func(p *packages.Package, cfg Config) error
+
+	// what describes the method set in errors.
+	what string
+}
+
+// methodSets are the method sets that Generate writes, in the order it writes
+// them.
+var methodSets = []methodSet{
+	{name: MethodSetManaged, generate: GenerateManaged, what: "managed resource method set"},
+	{name: MethodSetManagedList, generate: GenerateManagedList, what: "managed resource list method set"},
+	{name: MethodSetPC, generate: GenerateProviderConfig, what: "provider config method set"},
+	{name: MethodSetPCU, generate: GenerateProviderConfigUsage, what: "provider config usage method set"},
+	{name: MethodSetPCUList, generate: GenerateProviderConfigUsageList, what: "provider config usage list method set"},
+	{name: MethodSetResolvers, generate: GenerateReferences, what: "reference resolvers"},
+	{name: MethodSetResolvableFields, generate: GenerateResolvableFields, what: "resolvable fields"},
+	{name: MethodSetResolversIndex, generate: GenerateResolversIndex, what: "reference resolvers index"},
+	{name: MethodSetFixtures, generate: GenerateFixtures, what: "fixtures"},
+}
+
+// MethodSets returns the names of the method sets that Generate may write, in
+// the order it writes them.
+func MethodSets() []string {
+	names := make([]string, len(methodSets))
+	for i, ms := range methodSets {
+		names[i] = ms.name
+	}
+	return names
+}
+
+// validateMethodSets returns an error if the supplied Config names a method
+// set that Generate does not write.
+func validateMethodSets(cfg Config) error {
+	known := map[string]bool{}
+	for _, ms := range methodSets {
+		known[ms.name] = true
+	}
+	check := func(names []string, what string) error {
+		for _, n := range names {
+			if !known[n] {
+				return errors.Errorf("%s names unknown method set %q; method sets are %s", what, n, strings.Join(MethodSets(), ", "))
+			}
+		}
+		return nil
+	}
+
+	patterns := make([]string, 0, len(cfg.MethodSets))
+	for pattern := range cfg.MethodSets {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if err := check(cfg.MethodSets[pattern], "method sets of package pattern "+pattern); err != nil {
+			return err
+		}
+	}
+	if err := check(cfg.Only, "only"); err != nil {
+		return err
+	}
+	return check(cfg.Skip, "skip")
+}
+
+// methodSetsFor returns the names of the method sets that Generate writes for
+// the package with the supplied path, in the order it writes them. They are
+// those of the longest pattern of MethodSets that matches the package, or the
+// default method sets if none does, limited to Only, and without Skip. Only
+// reference resolvers are written if UpdateResolvers is set.
+func (c Config) methodSetsFor(path string) []string {
+	selected := map[string]bool{}
+	for _, ms := range methodSets {
+		switch ms.name {
+		case MethodSetResolvableFields:
+			selected[ms.name] = c.ResolvableFields
+		case MethodSetResolversIndex:
+			selected[ms.name] = c.ResolversIndex
+		case MethodSetFixtures:
+			selected[ms.name] = c.Fixtures
+		default:
+			selected[ms.name] = true
+		}
+	}
+	longest := -1
+	for pattern, names := range c.MethodSets {
+		if !matchPackage(pattern, path) || len(pattern) <= longest {
+			continue
+		}
+		longest = len(pattern)
+		selected = map[string]bool{}
+		for _, n := range names {
+			selected[n] = true
+		}
+	}
+	if len(c.Only) > 0 {
+		only := map[string]bool{}
+		for _, n := range c.Only {
+			only[n] = true
+		}
+		for n := range selected {
+			selected[n] = selected[n] && only[n]
+		}
+	}
+	for _, n := range c.Skip {
+		selected[n] = false
+	}
+	if c.UpdateResolvers {
+		for n := range selected {
+			selected[n] = selected[n] && n == MethodSetResolvers
+		}
+	}
+
+	names := make([]string, 0, len(methodSets))
+	for _, ms := range methodSets {
+		if selected[ms.name] {
+			names = append(names, ms.name)
+		}
+	}
+	return names
+}
+
+// matchPackage returns true if the supplied package path matches the supplied
+// pattern. A pattern ending in /... matches the package it names and every
+// package below it; any other pattern matches only the package it names.
+func matchPackage(pattern, path string) bool {
+	if pattern == "..." {
+		return true
+	}
+	if root := strings.TrimSuffix(pattern, "/..."); root != pattern {
+		return path == root || strings.HasPrefix(path, root+"/")
+	}
+	return path == pattern
+}
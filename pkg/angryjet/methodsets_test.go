@@ -0,0 +1,134 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+func TestMethodSetsFor(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		cfg    Config
+		path   string
+		want   []string
+	}{
+		"Default": {
+			reason: "Every method set but resolvable fields, reference resolver indexes, and fixtures should be generated by default.",
+			path:   "example.org/provider/apis/ec2/v1beta1",
+			want:   []string{MethodSetManaged, MethodSetManagedList, MethodSetPC, MethodSetPCU, MethodSetPCUList, MethodSetResolvers},
+		},
+		"ResolvableFields": {
+			reason: "Resolvable fields should be generated by default if they are enabled.",
+			cfg:    Config{ResolvableFields: true},
+			path:   "example.org/provider/apis/ec2/v1beta1",
+			want:   []string{MethodSetManaged, MethodSetManagedList, MethodSetPC, MethodSetPCU, MethodSetPCUList, MethodSetResolvers, MethodSetResolvableFields},
+		},
+		"ResolversIndex": {
+			reason: "Reference resolver indexes should be generated by default if they are enabled.",
+			cfg:    Config{ResolversIndex: true},
+			path:   "example.org/provider/apis/ec2/v1beta1",
+			want:   []string{MethodSetManaged, MethodSetManagedList, MethodSetPC, MethodSetPCU, MethodSetPCUList, MethodSetResolvers, MethodSetResolversIndex},
+		},
+		"Fixtures": {
+			reason: "Fixtures should be generated by default if they are enabled.",
+			cfg:    Config{Fixtures: true},
+			path:   "example.org/provider/apis/ec2/v1beta1",
+			want:   []string{MethodSetManaged, MethodSetManagedList, MethodSetPC, MethodSetPCU, MethodSetPCUList, MethodSetResolvers, MethodSetFixtures},
+		},
+		"UpdateResolvers": {
+			reason: "Only reference resolvers should be generated when they are updated.",
+			cfg:    Config{UpdateResolvers: true, ResolvableFields: true},
+			path:   "example.org/provider/apis/ec2/v1beta1",
+			want:   []string{MethodSetResolvers},
+		},
+		"NoMatchingPattern": {
+			reason: "Packages that no pattern matches should get the default method sets.",
+			cfg:    Config{MethodSets: map[string][]string{"example.org/provider/apis/legacy/...": {MethodSetManaged}}},
+			path:   "example.org/provider/apis/legacyext",
+			want:   []string{MethodSetManaged, MethodSetManagedList, MethodSetPC, MethodSetPCU, MethodSetPCUList, MethodSetResolvers},
+		},
+		"LongestPattern": {
+			reason: "The method sets of the longest matching pattern should be generated, in the order Generate writes them.",
+			cfg: Config{MethodSets: map[string][]string{
+				"...":                               {MethodSetPC},
+				"example.org/provider/apis/ec2/...": {MethodSetResolvers, MethodSetManaged},
+			}},
+			path: "example.org/provider/apis/ec2/v1beta1",
+			want: []string{MethodSetManaged, MethodSetResolvers},
+		},
+		"ExactPattern": {
+			reason: "A pattern without a wildcard should match the package it names.",
+			cfg:    Config{MethodSets: map[string][]string{"example.org/provider/apis/ec2": {MethodSetResolvableFields}}},
+			path:   "example.org/provider/apis/ec2",
+			want:   []string{MethodSetResolvableFields},
+		},
+		"OnlyAndSkip": {
+			reason: "Only and Skip should limit the method sets of a matching pattern.",
+			cfg: Config{
+				MethodSets: map[string][]string{"example.org/provider/apis/...": {MethodSetManaged, MethodSetManagedList, MethodSetResolvers}},
+				Only:       []string{MethodSetManaged, MethodSetResolvers, MethodSetPC},
+				Skip:       []string{MethodSetResolvers},
+			},
+			path: "example.org/provider/apis/ec2",
+			want: []string{MethodSetManaged},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.cfg.methodSetsFor(tc.path)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nmethodSetsFor(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateMethodSets(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		cfg    Config
+		want   error
+	}{
+		"Valid": {
+			reason: "Known method sets should be valid.",
+			cfg: Config{
+				MethodSets: map[string][]string{"example.org/provider/apis/...": {MethodSetManaged}},
+				Only:       []string{MethodSetResolvers},
+				Skip:       []string{MethodSetPC},
+			},
+		},
+		"UnknownOnly": {
+			reason: "An unknown method set should be an error.",
+			cfg:    Config{Only: []string{"diff"}},
+			want:   errors.New(`only names unknown method set "diff"; method sets are managed, managedlist, pc, pcu, pculist, resolvers, resolvablefields, resolversindex, fixtures`),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validateMethodSets(tc.cfg)
+			if diff := cmp.Diff(tc.want, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nvalidateMethodSets(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// A MarkerRule rewrites a line of a comment that uses a legacy spelling of a
+// reference marker to its canonical spelling.
+type MarkerRule struct {
+	// Pattern must match all of a line of a comment, after the comment
+	// markers and any indentation, for the line to be rewritten.
+	Pattern *regexp.Regexp
+
+	// Replacement replaces the matched line, after expanding the groups of
+	// Pattern as regexp.Expand does. A group named type that is qualified by
+	// the name of a package the file imports, for example ec2.VPC, is
+	// expanded to the import path of the package, for example
+	// github.com/crossplane/provider-aws/apis/ec2/v1beta1.VPC.
+	Replacement string
+}
+
+// DefaultMarkerRules rewrite the legacy spellings of reference markers that
+// are known to be used by providers. Rules are tried in order, and the first
+// that matches a line rewrites it.
+var DefaultMarkerRules = []MarkerRule{
+	{
+		// Ad hoc comments, for example Ref: ec2.VPC.
+		Pattern:     regexp.MustCompile(`^Ref:\s*(?P<type>[\w./-]+)\s*$`),
+		Replacement: "+crossplane:generate:reference:type=${type}",
+	},
+	{
+		// Ad hoc comments, for example Extractor: ExternalARN().
+		Pattern:     regexp.MustCompile(`^Extractor:\s*(?P<extractor>\S+)\s*$`),
+		Replacement: "+crossplane:generate:reference:extractor=${extractor}",
+	},
+	{
+		// Markers without the generate segment, for example
+		// +crossplane:reference:type=VPC.
+		Pattern:     regexp.MustCompile(`^\+crossplane:reference:(?P<marker>\S+)$`),
+		Replacement: "+crossplane:generate:reference:${marker}",
+	},
+	{
+		// Markers with an abbreviated reference segment, for example
+		// +crossplane:generate:ref:type=VPC.
+		Pattern:     regexp.MustCompile(`^\+crossplane:generate:ref:(?P<marker>\S+)$`),
+		Replacement: "+crossplane:generate:reference:${marker}",
+	},
+}
+
+var (
+	lineCommentIndent  = regexp.MustCompile(`^[ \t]*`)
+	blockCommentIndent = regexp.MustCompile(`^[ \t]*(\*[ \t]*)?`)
+)
+
+// A Migration of the legacy reference markers of a file.
+type Migration struct {
+	// Filename is the name of the migrated file.
+	Filename string
+
+	// Original is the content of the file before its markers were migrated.
+	Original []byte
+
+	// Migrated is the content of the file after its markers were migrated.
+	Migrated []byte
+}
+
+// Changed returns true if any marker of the file was migrated.
+func (m Migration) Changed() bool {
+	return !bytes.Equal(m.Original, m.Migrated)
+}
+
+// Diff returns the lines changed by the migration as a unified diff without
+// context. Migrations rewrite lines in place, so lines are compared by number.
+func (m Migration) Diff() string {
+	if !m.Changed() {
+		return ""
+	}
+	original := strings.Split(string(m.Original), "\n")
+	migrated := strings.Split(string(m.Migrated), "\n")
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "--- a/%s\n+++ b/%s\n", m.Filename, m.Filename)
+	for i := 0; i < len(original); i++ {
+		if original[i] == migrated[i] {
+			continue
+		}
+		j := i
+		for j < len(original) && original[j] != migrated[j] {
+			j++
+		}
+		fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(i, j), hunkRange(i, j))
+		for _, l := range original[i:j] {
+			fmt.Fprintf(b, "-%s\n", l)
+		}
+		for _, l := range migrated[i:j] {
+			fmt.Fprintf(b, "+%s\n", l)
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// hunkRange returns the range of a hunk of the zero indexed lines from start
+// until end, as it is written in a unified diff.
+func hunkRange(start, end int) string {
+	if end-start == 1 {
+		return strconv.Itoa(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, end-start)
+}
+
+// MigrateMarkers loads the files of the packages matching the configured
+// patterns and returns a Migration for each file whose comments use a legacy
+// spelling of a reference marker. Files are read, from the configured overlay
+// if it has them, but not written. An error is returned, and no migrations, if
+// any file cannot be parsed.
+func MigrateMarkers(ctx context.Context, cfg Config, rules []MarkerRule) ([]Migration, error) {
+	pkgs, err := packages.Load(&packages.Config{Context: ctx, Mode: packages.NeedName | packages.NeedFiles, Dir: cfg.Dir, Env: LoadEnv(cfg.Env), Overlay: cfg.Overlay}, cfg.Patterns...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot load packages %v", cfg.Patterns)
+	}
+
+	migrations := make([]Migration, 0)
+	seen := map[string]bool{}
+	for _, p := range pkgs {
+		for _, filename := range append(p.GoFiles, p.IgnoredFiles...) {
+			if seen[filename] || !strings.HasSuffix(filename, ".go") {
+				continue
+			}
+			seen[filename] = true
+			src, ok := cfg.Overlay[filename]
+			if !ok {
+				var err error
+				if src, err = ioutil.ReadFile(filename); err != nil {
+					return nil, errors.Wrapf(err, "cannot read %s", filename)
+				}
+			}
+			m, err := MigrateSource(filename, src, rules)
+			if err != nil {
+				return nil, err
+			}
+			if m.Changed() {
+				migrations = append(migrations, m)
+			}
+		}
+	}
+	return migrations, nil
+}
+
+// MigrateSource returns the Migration of the legacy reference markers of the
+// supplied Go source. Only lines of comments that a rule matches are
+// rewritten; all other lines, including the prose of the same comments, are
+// left exactly as they were.
+func MigrateSource(filename string, src []byte, rules []MarkerRule) (Migration, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return Migration{}, errors.Wrapf(err, "cannot parse %s; refusing to migrate its markers", filename)
+	}
+
+	imports := map[string]string{}
+	for _, is := range f.Imports {
+		p, err := strconv.Unquote(is.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(p)
+		if is.Name != nil {
+			name = is.Name.Name
+		}
+		imports[name] = p
+	}
+
+	migrated := make([]byte, 0, len(src))
+	last := 0
+	for _, g := range f.Comments {
+		for _, c := range g.List {
+			// The scanner removes carriage returns from the text of
+			// comments, so lines are found in the source instead.
+			start := fset.Position(c.Slash).Offset
+			text := string(src[start:fset.Position(c.End()).Offset])
+			lead := lineCommentIndent
+			if strings.HasPrefix(text, "/*") {
+				text = strings.TrimSuffix(text, "*/")
+				lead = blockCommentIndent
+			}
+			// Comments are split into lines after their comment markers,
+			// keeping each line's offset within the source.
+			offset := start + 2
+			for _, line := range strings.Split(text[2:], "\n") {
+				content := strings.TrimSuffix(line, "\r")
+				indent := lead.FindString(content)
+				if rewritten, ok := rewrite(content[len(indent):], rules, imports); ok {
+					migrated = append(migrated, src[last:offset+len(indent)]...)
+					migrated = append(migrated, rewritten...)
+					last = offset + len(content)
+				}
+				offset += len(line) + 1
+			}
+		}
+	}
+	migrated = append(migrated, src[last:]...)
+	return Migration{Filename: filename, Original: src, Migrated: migrated}, nil
+}
+
+// rewrite returns the supplied line of a comment as it is rewritten by the
+// first rule that matches it, and true if any rule matches it.
+func rewrite(line string, rules []MarkerRule, imports map[string]string) (string, bool) {
+	for _, r := range rules {
+		m := r.Pattern.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		if i := r.Pattern.SubexpIndex("type"); i > 0 && m[2*i] >= 0 {
+			t := line[m[2*i]:m[2*i+1]]
+			if dot := strings.LastIndex(t, "."); dot > 0 {
+				if p, ok := imports[t[:dot]]; ok {
+					// Expand the qualified type in place, so that it is
+					// expanded as if the line had used the import path.
+					line = line[:m[2*i]] + p + t[dot:] + line[m[2*i+1]:]
+					m = r.Pattern.FindStringSubmatchIndex(line)
+				}
+			}
+		}
+		return string(r.Pattern.ExpandString(nil, r.Replacement, line, m)), true
+	}
+	return "", false
+}
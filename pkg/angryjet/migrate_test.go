@@ -0,0 +1,153 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+func TestMigrateSource(t *testing.T) {
+	type want struct {
+		migrated string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		src    string
+		rules  []MarkerRule
+		want   want
+	}{
+		"AdHocComments": {
+			reason: "Ad hoc comments should be rewritten to markers, expanding types qualified by an imported package.",
+			src: `package v1
+
+import ec2 "example.org/provider/apis/ec2/v1beta1"
+
+type Parameters struct {
+	// VPCID is the ID of the VPC.
+	// Ref: ec2.VPC
+	//   Extractor: ec2.VPCARN()
+	VPCID string
+}
+`,
+			rules: DefaultMarkerRules,
+			want: want{
+				migrated: `package v1
+
+import ec2 "example.org/provider/apis/ec2/v1beta1"
+
+type Parameters struct {
+	// VPCID is the ID of the VPC.
+	// +crossplane:generate:reference:type=example.org/provider/apis/ec2/v1beta1.VPC
+	//   +crossplane:generate:reference:extractor=ec2.VPCARN()
+	VPCID string
+}
+`,
+			},
+		},
+		"LegacyMarkers": {
+			reason: "Legacy spellings of markers should be rewritten, leaving unqualified types and prose alone.",
+			src:    "package v1\r\n\r\ntype Parameters struct {\r\n\t/*\r\n\t * The subnet. Ref: not a marker.\r\n\t * +crossplane:reference:type=Subnet\r\n\t */\r\n\tSubnetID string\r\n\r\n\t// +crossplane:generate:ref:type=Role\r\n\tRoleARN string\r\n}\r\n",
+			rules:  DefaultMarkerRules,
+			want: want{
+				migrated: "package v1\r\n\r\ntype Parameters struct {\r\n\t/*\r\n\t * The subnet. Ref: not a marker.\r\n\t * +crossplane:generate:reference:type=Subnet\r\n\t */\r\n\tSubnetID string\r\n\r\n\t// +crossplane:generate:reference:type=Role\r\n\tRoleARN string\r\n}\r\n",
+			},
+		},
+		"CustomRules": {
+			reason: "Only the supplied rules should be applied.",
+			src: `package v1
+
+// Ref: VPC
+// RefersTo VPC
+type Parameters struct{}
+`,
+			rules: []MarkerRule{{
+				Pattern:     regexp.MustCompile(`^RefersTo (?P<type>\w+)$`),
+				Replacement: "+crossplane:generate:reference:type=${type}",
+			}},
+			want: want{
+				migrated: `package v1
+
+// Ref: VPC
+// +crossplane:generate:reference:type=VPC
+type Parameters struct{}
+`,
+			},
+		},
+		"ParseError": {
+			reason: "Files that cannot be parsed should not be migrated.",
+			src: `package v1
+
+// Ref: VPC
+type Parameters struct {
+`,
+			rules: DefaultMarkerRules,
+			want: want{
+				err: errors.New("cannot parse types.go; refusing to migrate its markers: types.go:4:26: expected '}', found 'EOF'"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := MigrateSource("types.go", []byte(tc.src), tc.rules)
+			if diff := cmp.Diff(tc.want.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nMigrateSource(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.migrated, string(got.Migrated)); diff != "" {
+				t.Errorf("\n%s\nMigrateSource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMigrateMarkers(t *testing.T) {
+	dir, err := filepath.Abs(provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := MigrateMarkers(context.Background(), Config{Patterns: []string{"./apis/..."}, Dir: provider, Env: env}, DefaultMarkerRules)
+	if err != nil {
+		t.Fatalf("MigrateMarkers(...): %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("MigrateMarkers(...): want 1 migration, got %d", len(got))
+	}
+
+	filename := filepath.Join(dir, "apis", "legacy", "types.go")
+	want := "--- a/" + filename + "\n+++ b/" + filename + `
+@@ -12 +12 @@
+-	// Ref: example.org/provider/apis/v1alpha1.Bucket
++	// +crossplane:generate:reference:type=example.org/provider/apis/v1alpha1.Bucket
+@@ -19,2 +19,2 @@
+-	// +crossplane:reference:type=example.org/provider/apis/v1alpha1.Key
+-	// +crossplane:generate:ref:extractor=github.com/crossplane/crossplane-runtime/pkg/reference.ExternalName()
++	// +crossplane:generate:reference:type=example.org/provider/apis/v1alpha1.Key
++	// +crossplane:generate:reference:extractor=github.com/crossplane/crossplane-runtime/pkg/reference.ExternalName()
+`
+	if diff := cmp.Diff(want, got[0].Diff()); diff != "" {
+		t.Errorf("MigrateMarkers(...): -want diff, +got diff:\n%s", diff)
+	}
+}
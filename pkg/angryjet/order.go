@@ -0,0 +1,84 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"context"
+	gotypes "go/types"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/crossplane/crossplane-tools/internal/match"
+	"github.com/crossplane/crossplane-tools/internal/method"
+)
+
+// A ResolutionOrder is the order in which the generated reference resolver of
+// a managed resource resolves its fields.
+type ResolutionOrder struct {
+	// Package is the path of the package that defines the managed resource.
+	Package string `json:"package"`
+
+	// Type is the name of the managed resource.
+	Type string `json:"type"`
+
+	// Fields are the Go paths of the fields that are resolved from
+	// references, for example Spec.ForProvider.SubnetID, in the order they
+	// are resolved. This is the order they are declared in, depth first.
+	// Fields that are shadowed by less deeply embedded fields are not
+	// resolved.
+	Fields []string `json:"fields"`
+}
+
+// ResolutionOrders loads the packages matching the configured patterns and
+// returns the ResolutionOrder of each of their managed resources that has
+// references.
+func ResolutionOrders(ctx context.Context, cfg Config) ([]ResolutionOrder, error) {
+	pkgs, err := packages.Load(&packages.Config{Context: ctx, Mode: LoadMode, Dir: cfg.Dir, Env: LoadEnv(cfg.Env), Overlay: cfg.Overlay}, cfg.Patterns...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot load packages %v", cfg.Patterns)
+	}
+
+	orders := make([]ResolutionOrder, 0)
+	for _, p := range pkgs {
+		if len(p.Errors) > 0 {
+			return nil, errors.Wrapf(p.Errors[0], "cannot load package %s", p.PkgPath)
+		}
+		m := cfg.matcher(p, match.Managed())
+		t := cfg.traverser(p, commentsIn(p), nil)
+		for _, n := range p.Types.Scope().Names() {
+			o := p.Types.Scope().Lookup(n)
+			named, ok := o.Type().(*gotypes.Named)
+			if !ok || !m.Match(o) {
+				continue
+			}
+			refs, err := method.References(t, cfg.runtimeImports(p).Runtime, named)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot find references of %s", o.Name())
+			}
+			if len(refs) == 0 {
+				continue
+			}
+			ro := ResolutionOrder{Package: p.PkgPath, Type: o.Name()}
+			for _, r := range refs {
+				ro.Fields = append(ro.Fields, method.GoPath(r.GoValueFieldPath[1:]...))
+			}
+			orders = append(orders, ro)
+		}
+	}
+	return orders, nil
+}
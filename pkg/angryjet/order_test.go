@@ -0,0 +1,72 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolutionOrders(t *testing.T) {
+	type want struct {
+		orders []ResolutionOrder
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason   string
+		patterns []string
+		want     want
+	}{
+		"DeclarationOrder": {
+			reason:   "Fields should be resolved in the order they are declared.",
+			patterns: []string{"./apis/v1alpha1"},
+			want: want{
+				orders: []ResolutionOrder{{
+					Package: "example.org/provider/apis/v1alpha1",
+					Type:    "Bucket",
+					Fields:  []string{"Spec.ForProvider.KeyID", "Spec.ForProvider.PolicyIDs", "Spec.ForProvider.KeyARN"},
+				}},
+			},
+		},
+		"Shadowed": {
+			reason:   "Fields whose references are shadowed by less deeply embedded fields should not be resolved.",
+			patterns: []string{"./apis/embedding"},
+			want: want{
+				orders: []ResolutionOrder{{
+					Package: "example.org/provider/apis/embedding",
+					Type:    "Widget",
+					Fields:  []string{"Spec.ForProvider.Network.SubnetID"},
+				}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ResolutionOrders(context.Background(), Config{Patterns: tc.patterns, Dir: provider, Env: env})
+			if diff := cmp.Diff(tc.want.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nResolutionOrders(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.orders, got); diff != "" {
+				t.Errorf("\n%s\nResolutionOrders(...): -want orders, +got orders:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"context"
+	"go/ast"
+	gotypes "go/types"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/crossplane/crossplane-tools/internal/fields"
+)
+
+// crossplane-runtime API levels that generated code may target, in addition to
+// a version such as v0.19.
+const (
+	// RuntimeLevelLatest targets the latest crossplane-runtime API.
+	RuntimeLevelLatest = "latest"
+
+	// RuntimeLevelAuto targets the crossplane-runtime API of the loaded
+	// crossplane-runtime packages, which is detected by Run.
+	RuntimeLevelAuto = "auto"
+)
+
+// RuntimeFeaturesSince is the crossplane-runtime version that introduced the
+// PublishConnectionDetailsTo methods of managed resources, and the namespace of
+// reference resolution requests. They're not generated for earlier versions.
+const RuntimeFeaturesSince = "v0.20"
+
+// A runtimeFeatures describes the features of the crossplane-runtime API that
+// generated code may use.
+type runtimeFeatures struct {
+	// PublishConnectionDetailsTo is the field of ResourceSpec that the
+	// Get and SetPublishConnectionDetailsTo methods of a managed resource
+	// access.
+	PublishConnectionDetailsTo bool
+
+	// ResolutionNamespace is the Namespace field of reference resolution
+	// requests, which is required to resolve references of namespace scoped
+	// managed resources or in the namespace of a tenant.
+	ResolutionNamespace bool
+}
+
+// latestRuntime has every feature of the crossplane-runtime API.
+var latestRuntime = runtimeFeatures{PublishConnectionDetailsTo: true, ResolutionNamespace: true}
+
+var version = regexp.MustCompile(`^v?(\d+)\.(\d+)(\.\d+)?$`)
+
+// parseRuntimeLevel returns the features of the supplied crossplane-runtime
+// API level. The auto level has every feature until it is detected.
+func parseRuntimeLevel(level string) (runtimeFeatures, error) {
+	if level == "" || level == RuntimeLevelLatest || level == RuntimeLevelAuto {
+		return latestRuntime, nil
+	}
+	v := version.FindStringSubmatch(level)
+	if v == nil {
+		return runtimeFeatures{}, errors.Errorf("runtime level %s must be %s, %s, or a version such as v0.19", level, RuntimeLevelLatest, RuntimeLevelAuto)
+	}
+	since := version.FindStringSubmatch(RuntimeFeaturesSince)
+	if less(v, since) {
+		return runtimeFeatures{}, nil
+	}
+	return latestRuntime, nil
+}
+
+// less returns true if version a, as matched by the version regular
+// expression, is before version b.
+func less(a, b []string) bool {
+	for i := 1; i <= 2; i++ {
+		x, _ := strconv.Atoi(a[i])
+		y, _ := strconv.Atoi(b[i])
+		if x != y {
+			return x < y
+		}
+	}
+	return false
+}
+
+// DetectRuntime returns the supplied Config with the features of the
+// crossplane-runtime API that its generated code may use resolved from its
+// RuntimeLevel. If it is RuntimeLevelAuto they are detected by parsing the
+// crossplane-runtime packages as loaded in its Dir and Env, and what was
+// decided and why is logged. Run calls DetectRuntime; Generate and its
+// siblings target the latest level if the auto level was not detected.
+func DetectRuntime(ctx context.Context, cfg Config) (Config, error) {
+	f, err := parseRuntimeLevel(cfg.RuntimeLevel)
+	if err != nil {
+		return cfg, err
+	}
+	if cfg.RuntimeLevel == RuntimeLevelAuto {
+		if f, err = detectRuntime(ctx, cfg); err != nil {
+			return cfg, err
+		}
+	}
+	cfg.runtime = &f
+	return cfg, nil
+}
+
+// runtimeImports are the import paths of the crossplane-runtime packages that
+// generated code uses.
+type runtimeImports struct {
+	Runtime   string
+	Resource  string
+	Reference string
+	Meta      string
+	FieldPath string
+}
+
+// runtimeImportsOf returns the import paths of the packages of the supplied
+// crossplane-runtime module.
+func runtimeImportsOf(module string) runtimeImports {
+	return runtimeImports{
+		Runtime:   module + RuntimePackage,
+		Resource:  module + ResourcePackage,
+		Reference: module + ReferencePackage,
+		Meta:      module + MetaPackage,
+		FieldPath: module + FieldPathPackage,
+	}
+}
+
+// runtimeModule returns the configured crossplane-runtime module, or
+// DefaultRuntimeModule.
+func (c Config) runtimeModule() string {
+	if c.RuntimeModule != "" {
+		return c.RuntimeModule
+	}
+	return DefaultRuntimeModule
+}
+
+// RuntimeModuleOf returns the path of the crossplane-runtime module whose
+// packages code generated for the supplied package imports: the configured
+// RuntimeModule, or else the module that the package imports them from, or else
+// DefaultRuntimeModule.
+func (c Config) RuntimeModuleOf(p *packages.Package) string {
+	if c.RuntimeModule == "" && p.Types != nil {
+		if m := discoverRuntimeModule(p.Types); m != "" {
+			return m
+		}
+	}
+	return c.runtimeModule()
+}
+
+// runtimeImports returns the import paths of the crossplane-runtime packages
+// that code generated for the supplied package uses.
+func (c Config) runtimeImports(p *packages.Package) runtimeImports {
+	return runtimeImportsOf(c.RuntimeModuleOf(p))
+}
+
+// embeddedRuntimeTypes are the crossplane-runtime types that the spec and
+// status types of managed resources, provider configs, and provider config
+// usages embed.
+var embeddedRuntimeTypes = map[string]bool{
+	fields.NameResourceSpec:         true,
+	fields.NameResourceStatus:       true,
+	fields.NameProviderConfigSpec:   true,
+	fields.NameProviderConfigStatus: true,
+	fields.NameProviderConfigUsage:  true,
+}
+
+// discoverRuntimeModule returns the path of the crossplane-runtime module that
+// the supplied package imports, found by looking for a struct type of the
+// package that embeds one of the embeddedRuntimeTypes. A package that forks
+// crossplane-runtime declares them in the apis/common/v1 package of its fork.
+// It returns an empty string if no type of the package embeds them.
+func discoverRuntimeModule(p *gotypes.Package) string {
+	for _, name := range p.Scope().Names() {
+		tn, ok := p.Scope().Lookup(name).(*gotypes.TypeName)
+		if !ok {
+			continue
+		}
+		s, ok := tn.Type().Underlying().(*gotypes.Struct)
+		if !ok {
+			continue
+		}
+		for i := 0; i < s.NumFields(); i++ {
+			f := s.Field(i)
+			if !f.Embedded() {
+				continue
+			}
+			t := f.Type()
+			if ptr, ok := t.(*gotypes.Pointer); ok {
+				t = ptr.Elem()
+			}
+			n, ok := t.(*gotypes.Named)
+			if !ok || n.Obj().Pkg() == nil || !embeddedRuntimeTypes[n.Obj().Name()] {
+				continue
+			}
+			if path := n.Obj().Pkg().Path(); strings.HasSuffix(path, RuntimePackage) {
+				return strings.TrimSuffix(path, RuntimePackage)
+			}
+		}
+	}
+	return ""
+}
+
+// detectRuntime returns the features of the loaded crossplane-runtime packages.
+// The packages are parsed, but not type checked.
+func detectRuntime(ctx context.Context, cfg Config) (runtimeFeatures, error) {
+	rt := runtimeImportsOf(cfg.runtimeModule())
+	pkgs, err := packages.Load(&packages.Config{Context: ctx, Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax, Dir: cfg.Dir, Env: LoadEnv(cfg.Env), Overlay: cfg.Overlay}, rt.Runtime, rt.Reference)
+	if err != nil {
+		return runtimeFeatures{}, errors.Wrap(err, "cannot load crossplane-runtime packages to detect runtime level")
+	}
+	files := map[string][]*ast.File{}
+	for _, p := range pkgs {
+		if len(p.Errors) > 0 {
+			return runtimeFeatures{}, errors.Wrapf(p.Errors[0], "cannot load package %s to detect runtime level", p.PkgPath)
+		}
+		files[p.PkgPath] = p.Syntax
+	}
+
+	f := runtimeFeatures{
+		PublishConnectionDetailsTo: hasField(files[rt.Runtime], "ResourceSpec", "PublishConnectionDetailsTo"),
+		ResolutionNamespace:        hasField(files[rt.Reference], "ResolutionRequest", "Namespace"),
+	}
+	if f.PublishConnectionDetailsTo {
+		cfg.logf("detected runtime level: %s.ResourceSpec has a PublishConnectionDetailsTo field, so Get and SetPublishConnectionDetailsTo are generated", rt.Runtime)
+	} else {
+		cfg.logf("detected runtime level: %s.ResourceSpec has no PublishConnectionDetailsTo field, so Get and SetPublishConnectionDetailsTo are not generated", rt.Runtime)
+	}
+	if f.ResolutionNamespace {
+		cfg.logf("detected runtime level: %s.ResolutionRequest has a Namespace field, so references may be resolved in a namespace", rt.Reference)
+	} else {
+		cfg.logf("detected runtime level: %s.ResolutionRequest has no Namespace field, so references cannot be resolved in a namespace", rt.Reference)
+	}
+	return f, nil
+}
+
+// hasField returns true if the struct type with the supplied name, declared by
+// the supplied files, has a field with the supplied name.
+func hasField(files []*ast.File, typ, field string) bool {
+	st, _, ok := findStruct(files, typ)
+	if !ok {
+		return false
+	}
+	for _, f := range st.Fields.List {
+		for _, n := range f.Names {
+			if n.Name == field {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateRuntime returns an error if the configured tenant function requires
+// a feature of the crossplane-runtime API that the configured level lacks, or
+// if resolvers that use the controller-runtime client directly are configured
+// to return resolved values, to log resolution, to record provenance, or to use
+// a resolver.
+func validateRuntime(cfg Config) error {
+	if cfg.Tenant != "" && !cfg.features().ResolutionNamespace {
+		return errors.Errorf("tenant function %s requires resolution requests with a Namespace field, from crossplane-runtime %s", cfg.Tenant, RuntimeFeaturesSince)
+	}
+	if cfg.ControllerRuntime && (cfg.ResolvedValues || cfg.ResolverLogging != "" || cfg.Provenance != "") {
+		return errors.New("reference resolvers that use the controller-runtime client cannot return resolved values, log resolution, or record provenance")
+	}
+	if cfg.ControllerRuntime && cfg.Resolver != "" {
+		return errors.Errorf("reference resolvers that use the controller-runtime client cannot use resolver %s", cfg.Resolver)
+	}
+	return nil
+}
+
+// features returns the features of the crossplane-runtime API that code
+// generated with this Config may use.
+func (c Config) features() runtimeFeatures {
+	if c.runtime != nil {
+		return *c.runtime
+	}
+	// An invalid level is reported by DetectRuntime.
+	f, _ := parseRuntimeLevel(c.RuntimeLevel)
+	return f
+}
+
+// logf logs the supplied message, if this Config has a Logf function.
+func (c Config) logf(format string, args ...interface{}) {
+	if c.Logf != nil {
+		c.Logf(format, args...)
+	}
+}
+
+// errNamespaced returns the panic value of a namespace scoped managed resource
+// whose references cannot be resolved in its namespace.
+func errNamespaced(name string) error {
+	return errors.Errorf("%s is namespace scoped, but resolution requests have no Namespace field before crossplane-runtime %s", name, RuntimeFeaturesSince)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package angryjet
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+func TestDetectRuntime(t *testing.T) {
+	envV019 := append(os.Environ(), "GOFLAGS=-mod=mod -modfile=go.v0.19.mod", "GOPROXY=off", "GOWORK=off")
+
+	type want struct {
+		features runtimeFeatures
+		logs     []string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		level  string
+		env    []string
+		want   want
+	}{
+		"Default": {
+			reason: "The latest level should be targeted by default.",
+			env:    env,
+			want: want{
+				features: latestRuntime,
+				logs:     []string{},
+			},
+		},
+		"Version": {
+			reason: "A version before the features were introduced should have none of them.",
+			level:  "v0.19",
+			env:    env,
+			want: want{
+				features: runtimeFeatures{},
+				logs:     []string{},
+			},
+		},
+		"LaterVersion": {
+			reason: "A version after the features were introduced should have all of them.",
+			level:  "v1.2.3",
+			env:    env,
+			want: want{
+				features: latestRuntime,
+				logs:     []string{},
+			},
+		},
+		"InvalidLevel": {
+			reason: "A level that is not a version should be an error.",
+			level:  "newest",
+			env:    env,
+			want: want{
+				logs: []string{},
+				err:  errors.New("runtime level newest must be latest, auto, or a version such as v0.19"),
+			},
+		},
+		"AutoLatest": {
+			reason: "The features of the latest runtime should be detected and logged.",
+			level:  RuntimeLevelAuto,
+			env:    env,
+			want: want{
+				features: latestRuntime,
+				logs: []string{
+					"detected runtime level: github.com/crossplane/crossplane-runtime/apis/common/v1.ResourceSpec has a PublishConnectionDetailsTo field, so Get and SetPublishConnectionDetailsTo are generated",
+					"detected runtime level: github.com/crossplane/crossplane-runtime/pkg/reference.ResolutionRequest has a Namespace field, so references may be resolved in a namespace",
+				},
+			},
+		},
+		"AutoV019": {
+			reason: "The lack of features of the v0.19 runtime should be detected and logged.",
+			level:  RuntimeLevelAuto,
+			env:    envV019,
+			want: want{
+				features: runtimeFeatures{},
+				logs: []string{
+					"detected runtime level: github.com/crossplane/crossplane-runtime/apis/common/v1.ResourceSpec has no PublishConnectionDetailsTo field, so Get and SetPublishConnectionDetailsTo are not generated",
+					"detected runtime level: github.com/crossplane/crossplane-runtime/pkg/reference.ResolutionRequest has no Namespace field, so references cannot be resolved in a namespace",
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			logs := []string{}
+			cfg := Config{
+				Dir:          provider,
+				Env:          tc.env,
+				RuntimeLevel: tc.level,
+				Logf: func(format string, args ...interface{}) {
+					logs = append(logs, fmt.Sprintf(format, args...))
+				},
+			}
+			got, err := DetectRuntime(context.Background(), cfg)
+			if diff := cmp.Diff(tc.want.err, err, cmpErrors()); diff != "" {
+				t.Errorf("\n%s\nDetectRuntime(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err == nil {
+				if diff := cmp.Diff(tc.want.features, got.features()); diff != "" {
+					t.Errorf("\n%s\nDetectRuntime(...): -want features, +got features:\n%s", tc.reason, diff)
+				}
+			}
+			if diff := cmp.Diff(tc.want.logs, logs); diff != "" {
+				t.Errorf("\n%s\nDetectRuntime(...): -want logs, +got logs:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comments
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// A Cache caches the comments of loaded packages by file, so that the markers
+// of their types and fields may be queried without generating any methods,
+// for example by tools that report them. Files that change after they were
+// loaded may be invalidated, for example by a watcher, and are read again the
+// next time their comments are queried. A Cache is safe for concurrent use.
+type Cache struct {
+	opts options
+	fset *token.FileSet
+
+	// syntax is the syntax of each file as it was loaded, by which objects
+	// are found in the file once it is read again.
+	syntax map[string]*ast.File
+
+	mu    sync.RWMutex
+	files map[string]lines
+	stale map[string]bool
+
+	// moved is the line each object of a file that was read again is now
+	// declared on, by the position it was loaded at.
+	moved map[string]map[token.Pos]int
+}
+
+// NewCache returns a Cache of the comments of the supplied packages, as
+// returned by packages.Load with syntax, and of the packages of their modules
+// that they import, as with In. The packages must share a FileSet.
+func NewCache(pkgs []*packages.Package, o ...Option) *Cache {
+	c := &Cache{
+		syntax: map[string]*ast.File{},
+		files:  map[string]lines{},
+		stale:  map[string]bool{},
+		moved:  map[string]map[token.Pos]int{},
+	}
+	for _, fn := range o {
+		fn(&c.opts)
+	}
+	seen := map[*packages.Package]bool{}
+	for _, p := range pkgs {
+		c.fset = p.Fset
+		for _, ip := range inModule(p, seen) {
+			for _, f := range ip.Syntax {
+				filename := p.Fset.File(f.Pos()).Name()
+				c.syntax[filename] = f
+				if c.opts.filter != nil && !contains(f, c.opts.filter) {
+					continue
+				}
+				c.files[filename] = index(p.Fset, f)
+			}
+		}
+	}
+	return c
+}
+
+// For returns the comments for the supplied Object, if any, as
+// Comments.For does. The Object must be of one of the cached packages.
+func (c *Cache) For(o types.Object) string {
+	if c.fset == nil {
+		return ""
+	}
+	l, line := c.line(o)
+	return l.For(line)
+}
+
+// Before returns the comments before the supplied Object, if any, as
+// Comments.Before does. The Object must be of one of the cached packages.
+func (c *Cache) Before(o types.Object) string {
+	if c.fset == nil {
+		return ""
+	}
+	l, line := c.line(o)
+	return l.Before(line)
+}
+
+// Markers returns the markers in the comments for the supplied Object, parsed
+// using the DefaultMarkerPrefix.
+func (c *Cache) Markers(o types.Object) Markers {
+	return ParseMarkers(c.For(o))
+}
+
+// Invalidate the cached comments of the supplied files, which are read again
+// the next time their comments are queried. Objects are then found in the file
+// by the names they are declared with, so their comments are found even if
+// lines were added or removed above them. Objects that are no longer declared,
+// for example because they were renamed, have no comments; packages whose
+// declarations changed should be loaded again, and cached by a new Cache.
+func (c *Cache) Invalidate(filenames ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, f := range filenames {
+		c.stale[f] = true
+	}
+}
+
+// line returns the comments of the file the supplied Object is declared in,
+// and the line it is declared on. It returns no comments if the file was read
+// again and the Object is no longer declared in it.
+func (c *Cache) line(o types.Object) (lines, int) {
+	p := c.fset.PositionFor(o.Pos(), false)
+	l, moved := c.file(p.Filename)
+	if moved == nil {
+		return l, p.Line
+	}
+	line, ok := moved[o.Pos()]
+	if !ok {
+		return nil, 0
+	}
+	return l, line
+}
+
+// file returns the comments of the supplied file, reading them again if the
+// file was invalidated, and the lines its objects are now declared on if it
+// was read again.
+func (c *Cache) file(filename string) (lines, map[token.Pos]int) {
+	c.mu.RLock()
+	l, moved, stale := c.files[filename], c.moved[filename], c.stale[filename]
+	c.mu.RUnlock()
+	if !stale {
+		return l, moved
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stale[filename] {
+		c.files[filename], c.moved[filename] = c.read(filename)
+		delete(c.stale, filename)
+	}
+	return c.files[filename], c.moved[filename]
+}
+
+// read returns the comments of the supplied file as it is on disk, and the
+// line each object of the file as it was loaded is now declared on, by the
+// position it was loaded at. A file that cannot be read, for example because
+// it was deleted, has no comments. A file that cannot be parsed, for example
+// because it is being edited, has those comments that could be parsed.
+func (c *Cache) read(filename string) (lines, map[token.Pos]int) {
+	moved := map[token.Pos]int{}
+	fset := token.NewFileSet()
+	f, _ := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+	if f == nil {
+		return nil, moved
+	}
+	if c.opts.filter != nil && !contains(f, c.opts.filter) {
+		return nil, moved
+	}
+	if loaded, ok := c.syntax[filename]; ok {
+		now := declarations(f)
+		for name, id := range declarations(loaded) {
+			if nid, ok := now[name]; ok {
+				moved[id.Pos()] = fset.PositionFor(nid.Pos(), false).Line
+			}
+		}
+	}
+	return index(fset, f), moved
+}
+
+// declarations returns the identifiers that declare the package level objects
+// of the supplied file, and the fields of its struct types, by their names.
+// Fields are named after the types they belong to, for example Model.Spec, and
+// methods after their receivers, for example Model.GetCondition.
+func declarations(f *ast.File) map[string]*ast.Ident {
+	d := map[string]*ast.Ident{}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, s := range decl.Specs {
+				switch s := s.(type) {
+				case *ast.TypeSpec:
+					d[s.Name.Name] = s.Name
+					fields(d, s.Name.Name, s.Type)
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						d[n.Name] = n
+					}
+				}
+			}
+		case *ast.FuncDecl:
+			name := decl.Name.Name
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				if r := embedded(decl.Recv.List[0].Type); r != nil {
+					name = r.Name + "." + name
+				}
+			}
+			d[name] = decl.Name
+		}
+	}
+	return d
+}
+
+// fields adds the identifiers that declare the fields of the supplied type, if
+// it is or contains a struct type declared inline, to the supplied
+// declarations, named after the supplied prefix.
+func fields(d map[string]*ast.Ident, prefix string, t ast.Expr) {
+	switch t := t.(type) {
+	case *ast.StarExpr:
+		fields(d, prefix, t.X)
+	case *ast.ArrayType:
+		fields(d, prefix, t.Elt)
+	case *ast.MapType:
+		fields(d, prefix, t.Value)
+	case *ast.StructType:
+		for _, f := range t.Fields.List {
+			names := f.Names
+			if len(names) == 0 {
+				if id := embedded(f.Type); id != nil {
+					names = []*ast.Ident{id}
+				}
+			}
+			for _, n := range names {
+				d[prefix+"."+n.Name] = n
+				fields(d, prefix+"."+n.Name, f.Type)
+			}
+		}
+	}
+}
+
+// embedded returns the identifier that names the supplied type of an embedded
+// field or a receiver, which is also where go/types declares an embedded field.
+func embedded(t ast.Expr) *ast.Ident {
+	switch t := t.(type) {
+	case *ast.Ident:
+		return t
+	case *ast.StarExpr:
+		return embedded(t.X)
+	case *ast.ParenExpr:
+		return embedded(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel
+	case *ast.IndexExpr:
+		return embedded(t.X)
+	case *ast.IndexListExpr:
+		return embedded(t.X)
+	}
+	return nil
+}
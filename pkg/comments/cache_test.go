@@ -0,0 +1,283 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comments
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+)
+
+// load writes the supplied files to a temporary directory and loads them as a
+// package, returning the package and the paths of the files by their names.
+func load(t *testing.T, files map[string]string) (*packages.Package, map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	fset := token.NewFileSet()
+	paths := map[string]string{}
+	var syntax []*ast.File
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths[name] = path
+		syntax = append(syntax, f)
+	}
+	tp, err := (&types.Config{}).Check("example.org/v1", fset, syntax, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &packages.Package{Fset: fset, Syntax: syntax, Types: tp}, paths
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	source := "package v1\n\n// +crossplane:generate:key=before\n\n// A Model.\n// +key=value1\ntype Model struct {\n\t// +key=field1\n\tName string\n}\n"
+	edited := "package v1\n\n// +crossplane:generate:key=after\n\n// A Model.\n// +key=value2\ntype Model struct {\n\t// +key=field2\n\tName string\n}\n"
+
+	type want struct {
+		model  Markers
+		field  Markers
+		before Markers
+		widget Markers
+	}
+	cases := map[string]struct {
+		reason     string
+		source     string
+		filter     []string
+		edit       func(t *testing.T, paths map[string]string)
+		invalidate []string
+		want       want
+	}{
+		"Unchanged": {
+			reason: "The comments of files that are unchanged should be returned.",
+			want: want{
+				model:  Markers{"key": {"value1"}},
+				field:  Markers{"key": {"field1"}},
+				before: Markers{"crossplane:generate:key": {"before"}},
+				widget: Markers{"crossplane:generate:key": {"widget"}},
+			},
+		},
+		"Cached": {
+			reason: "The cached comments of a file that changed should be returned until it is invalidated.",
+			edit:   write(edited),
+			want: want{
+				model:  Markers{"key": {"value1"}},
+				field:  Markers{"key": {"field1"}},
+				before: Markers{"crossplane:generate:key": {"before"}},
+				widget: Markers{"crossplane:generate:key": {"widget"}},
+			},
+		},
+		"Invalidated": {
+			reason:     "The comments of a file that changed should be read again once it is invalidated.",
+			edit:       write(edited),
+			invalidate: []string{"model.go"},
+			want: want{
+				model:  Markers{"key": {"value2"}},
+				field:  Markers{"key": {"field2"}},
+				before: Markers{"crossplane:generate:key": {"after"}},
+				widget: Markers{"crossplane:generate:key": {"widget"}},
+			},
+		},
+		"Moved": {
+			reason:     "The comments of objects that moved within a file that changed should be found by their names once it is invalidated.",
+			edit:       write("package v1\n\n// +crossplane:generate:key=before\n\n// A Model.\n// +other=inserted\n// +key=value1\ntype Model struct {\n\t// +other=inserted\n\t// +key=field1\n\tName string\n}\n"),
+			invalidate: []string{"model.go"},
+			want: want{
+				model:  Markers{"key": {"value1"}, "other": {"inserted"}},
+				field:  Markers{"key": {"field1"}, "other": {"inserted"}},
+				before: Markers{"crossplane:generate:key": {"before"}},
+				widget: Markers{"crossplane:generate:key": {"widget"}},
+			},
+		},
+		"MovedBelowOther": {
+			reason:     "The comments of a field should be found by its name, rather than those of another field that moved to where it was loaded at.",
+			source:     "package v1\n\n// A Model.\ntype Model struct {\n\t// +key=field1\n\tName string\n\n\t// +key=other\n\tOther string\n}\n",
+			edit:       write("package v1\n\n// A Model.\ntype Model struct {\n\t// +key=other\n\tOther string\n\n\t// +key=field1\n\tName string\n}\n"),
+			invalidate: []string{"model.go"},
+			want: want{
+				model:  Markers{},
+				field:  Markers{"key": {"field1"}},
+				before: Markers{},
+				widget: Markers{"crossplane:generate:key": {"widget"}},
+			},
+		},
+		"Renamed": {
+			reason:     "An object that is no longer declared in a file that changed should have no comments once it is invalidated.",
+			edit:       write("package v1\n\n// +crossplane:generate:key=before\n\n// A Model.\n// +key=value1\ntype Model struct {\n\t// +key=field1\n\tFullName string\n}\n"),
+			invalidate: []string{"model.go"},
+			want: want{
+				model:  Markers{"key": {"value1"}},
+				field:  Markers{},
+				before: Markers{"crossplane:generate:key": {"before"}},
+				widget: Markers{"crossplane:generate:key": {"widget"}},
+			},
+		},
+		"OtherInvalidated": {
+			reason:     "The comments of a file that changed should be cached while only other files are invalidated.",
+			edit:       write(edited),
+			invalidate: []string{"widget.go"},
+			want: want{
+				model:  Markers{"key": {"value1"}},
+				field:  Markers{"key": {"field1"}},
+				before: Markers{"crossplane:generate:key": {"before"}},
+				widget: Markers{"crossplane:generate:key": {"widget"}},
+			},
+		},
+		"Deleted": {
+			reason: "A file that was deleted should have no comments once it is invalidated.",
+			edit: func(t *testing.T, paths map[string]string) {
+				if err := os.Remove(paths["model.go"]); err != nil {
+					t.Fatal(err)
+				}
+			},
+			invalidate: []string{"model.go"},
+			want: want{
+				model:  Markers{},
+				field:  Markers{},
+				before: Markers{},
+				widget: Markers{"crossplane:generate:key": {"widget"}},
+			},
+		},
+		"FilteredOut": {
+			reason:     "A file that no longer has a comment that contains the filter should have no comments once it is invalidated.",
+			filter:     []string{"+crossplane:generate"},
+			edit:       write("package v1\n\n// A Model.\n// +key=value2\ntype Model struct {\n\t// +key=field2\n\tName string\n}\n"),
+			invalidate: []string{"model.go"},
+			want: want{
+				model:  Markers{},
+				field:  Markers{},
+				before: Markers{},
+				widget: Markers{"crossplane:generate:key": {"widget"}},
+			},
+		},
+		"FilteredIn": {
+			reason:     "A file that gains a comment that contains the filter should have comments once it is invalidated.",
+			source:     "package v1\n\n// A comment.\n\n// A Model.\n// +key=value1\ntype Model struct {\n\t// +key=field1\n\tName string\n}\n",
+			filter:     []string{"+crossplane:generate"},
+			edit:       write(edited),
+			invalidate: []string{"model.go"},
+			want: want{
+				model:  Markers{"key": {"value2"}},
+				field:  Markers{"key": {"field2"}},
+				before: Markers{"crossplane:generate:key": {"after"}},
+				widget: Markers{"crossplane:generate:key": {"widget"}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			src := source
+			if tc.source != "" {
+				src = tc.source
+			}
+			p, paths := load(t, map[string]string{
+				"model.go":  src,
+				"widget.go": "package v1\n\n// A Widget.\n// +crossplane:generate:key=widget\ntype Widget struct{}\n",
+			})
+			var o []Option
+			if tc.filter != nil {
+				o = append(o, WithFilter(tc.filter...))
+			}
+			c := NewCache([]*packages.Package{p}, o...)
+
+			// Query every file once, so that they are cached before they
+			// are edited.
+			model := p.Types.Scope().Lookup("Model")
+			field := model.Type().Underlying().(*types.Struct).Field(0)
+			widget := p.Types.Scope().Lookup("Widget")
+			c.For(model)
+			c.For(widget)
+
+			if tc.edit != nil {
+				tc.edit(t, paths)
+			}
+			for _, f := range tc.invalidate {
+				c.Invalidate(paths[f])
+			}
+
+			got := want{
+				model:  c.Markers(model),
+				field:  c.Markers(field),
+				before: ParseMarkers(c.Before(model)),
+				widget: c.Markers(widget),
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nc.Markers(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// write returns a function that overwrites model.go with the supplied source.
+func write(src string) func(t *testing.T, paths map[string]string) {
+	return func(t *testing.T, paths map[string]string) {
+		t.Helper()
+		if err := os.WriteFile(paths["model.go"], []byte(src), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	// Comments are queried while the file they're in is edited and
+	// invalidated, so each query should return the comments of one version of
+	// the file or the other.
+	p, paths := load(t, map[string]string{"model.go": "package v1\n\n// +key=value1\ntype Model struct{}\n"})
+	c := NewCache([]*packages.Package{p})
+	model := p.Types.Scope().Lookup("Model")
+	if err := os.WriteFile(paths["model.go"], []byte("package v1\n\n// +key=value2\ntype Model struct{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	wg := &sync.WaitGroup{}
+	got := make([]string, 50)
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%5 == 0 {
+				c.Invalidate(paths["model.go"])
+			}
+			got[i] = c.Markers(model)["key"][0]
+		}(i)
+	}
+	wg.Wait()
+
+	for i, v := range got {
+		if v != "value1" && v != "value2" {
+			t.Errorf("c.Markers(...): query %d: want value1 or value2, got %q", i, v)
+		}
+	}
+	c.Invalidate(paths["model.go"])
+	if diff := cmp.Diff(Markers{"key": {"value2"}}, c.Markers(model)); diff != "" {
+		t.Errorf("\nOnce invalidated after queries, the edited comments should be returned.\nc.Markers(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2019 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package comments extracts and parses comments from a package, and the
+// markers in them. In returns the comments of a loaded package once, while a
+// Cache may be queried concurrently by tools that read markers without
+// generating methods, and invalidated file by file as they change.
+package comments
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// DefaultMarkerPrefix that is commonly used by comment markers.
+const DefaultMarkerPrefix = "+"
+
+// A group of comments, which ends on the line it is indexed by.
+type group struct {
+	*ast.CommentGroup
+
+	// start is the line the group starts on.
+	start int
+}
+
+// lines are the comment groups of a file, by the line they end on.
+type lines map[int]group
+
+// Comments for a particular package.
+type Comments struct {
+	files map[string]lines
+	fset  *token.FileSet
+	extra map[token.Pos]string
+}
+
+// An Option configures which comments are returned by In, or cached by a
+// Cache.
+type Option func(o *options)
+
+type options struct {
+	filter []string
+}
+
+// WithFilter limits the comments returned by In, or cached by a Cache, to those of files with a
+// comment that contains any of the supplied strings, for example the prefixes
+// of the markers that are relevant to the caller. Files without one are
+// skipped cheaply, without recording the positions of their comments. All
+// comments of the other files are returned, so comments are found before an
+// object as they would be without a filter.
+func WithFilter(contains ...string) Option {
+	return func(o *options) {
+		o.filter = contains
+	}
+}
+
+// In returns all comments in a particular package, and in the packages of its
+// module that it imports directly or transitively, so that the markers of
+// types it embeds from those packages are found. Imported packages are only
+// included if their syntax was loaded.
+func In(p *packages.Package, o ...Option) Comments {
+	opts := &options{}
+	for _, fn := range o {
+		fn(opts)
+	}
+	files := map[string]lines{}
+	for _, ip := range inModule(p, map[*packages.Package]bool{}) {
+		for _, f := range ip.Syntax {
+			if opts.filter != nil && !contains(f, opts.filter) {
+				continue
+			}
+			files[p.Fset.File(f.Pos()).Name()] = index(p.Fset, f)
+		}
+	}
+	return Comments{files: files, fset: p.Fset}
+}
+
+// index returns the comment groups of the supplied file by the line they end
+// on. Lines are not adjusted by line directives, so that comments are indexed
+// by the file and line they are written on.
+func index(fset *token.FileSet, f *ast.File) lines {
+	l := make(lines, len(f.Comments))
+	for _, g := range f.Comments {
+		// The scanner removes carriage returns from comments, so the end
+		// of a block comment in a file with CRLF line endings may appear
+		// to be on an earlier line than it is. We count the lines of the
+		// last comment from where it starts instead.
+		last := g.List[len(g.List)-1]
+		end := fset.PositionFor(last.Slash, false).Line + strings.Count(last.Text, "\n")
+		l[end] = group{CommentGroup: g, start: fset.PositionFor(g.List[0].Slash, false).Line}
+	}
+	return l
+}
+
+// contains returns true if any comment of the supplied file contains any of the
+// supplied strings. The text of comments is searched as it is in the source,
+// including the comment markers of line and block comments.
+func contains(f *ast.File, s []string) bool {
+	for _, g := range f.Comments {
+		for _, c := range g.List {
+			for _, ss := range s {
+				if strings.Contains(c.Text, ss) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// inModule returns the supplied package and the packages of its module that it
+// imports, directly or transitively. Imports are only followed if the package
+// belongs to a module.
+func inModule(p *packages.Package, seen map[*packages.Package]bool) []*packages.Package {
+	seen[p] = true
+	pkgs := []*packages.Package{p}
+	if p.Module == nil {
+		return pkgs
+	}
+	for _, ip := range p.Imports {
+		if seen[ip] || ip.Module == nil || ip.Module.Path != p.Module.Path || ip.Fset != p.Fset {
+			continue
+		}
+		pkgs = append(pkgs, inModule(ip, seen)...)
+	}
+	return pkgs
+}
+
+// For returns the comments for the supplied Object, if any.
+func (c Comments) For(o types.Object) string {
+	return c.At(o.Pos())
+}
+
+// At returns the comments for the Object declared at the supplied position, if
+// any, for callers that know where an Object is declared but not the Object.
+func (c Comments) At(pos token.Pos) string {
+	if len(c.files) == 0 {
+		return c.extra[pos]
+	}
+	p := c.fset.PositionFor(pos, false)
+	return c.files[p.Filename].For(p.Line) + c.extra[pos]
+}
+
+// With returns a copy of these comments in which the supplied comment follows
+// the comments for the supplied Object, as if it had been written in its
+// source. The comment should end with a newline.
+func (c Comments) With(o types.Object, comment string) Comments {
+	extra := make(map[token.Pos]string, len(c.extra)+1)
+	for pos, e := range c.extra {
+		extra[pos] = e
+	}
+	extra[o.Pos()] += comment
+	c.extra = extra
+	return c
+}
+
+// Before returns the comments before the supplied Object, if any. A comment is
+// deemed to be 'before' (rather than 'for') an Object if it ends exactly one
+// blank line above where the Object (including its comment, if any) begins.
+func (c Comments) Before(o types.Object) string {
+	if len(c.files) == 0 {
+		return ""
+	}
+	p := c.fset.PositionFor(o.Pos(), false)
+	return c.files[p.Filename].Before(p.Line)
+}
+
+// For returns the comments that end on the line before the supplied line.
+func (l lines) For(line int) string {
+	return l[line-1].Text()
+}
+
+// Before returns the comments that end two lines before the supplied line, or
+// two lines before the comments that end on the line before it, if any.
+func (l lines) Before(line int) string {
+	g, ok := l[line-1]
+	if !ok {
+		// No comment group ends immediately before this line. Check
+		// for one ending two lines back.
+		return l[line-2].Text()
+	}
+
+	// A comment group ends immediately before this line. Check for
+	// another one ending two lines back from where it starts.
+	return l[g.start-2].Text()
+}
+
+// Markers are comments that begin with a special character (typically
+// DefaultMarkerPrefix). Comment markers that contain '=' are considered to be
+// key=value pairs, represented as one map key with a slice of multiple values.
+type Markers map[string][]string
+
+// ParseMarkers parses comment markers from the supplied comment using the
+// DefaultMarkerPrefix.
+func ParseMarkers(comment string) Markers {
+	return ParseMarkersWithPrefix(DefaultMarkerPrefix, comment)
+}
+
+// ParseMarkersWithPrefix parses comment markers from the supplied comment. Any
+// line that begins with the supplied prefix is considered a comment marker. For
+// example using marker prefix '+' the following comments:
+//
+// +key:value1
+// +key:value2
+//
+// Would be parsed as Markers{"key": []string{"value1", "value2"}}
+//
+// Lines may end with either \n or \r\n, and may be indented using spaces,
+// tabs, or the '*' continuation characters of a block comment. A line may
+// contain several markers separated by white space, for example:
+//
+// +key=value1 +other=value2
+//
+// A marker begins wherever the prefix follows white space and precedes a
+// letter, except within a quoted value, so values may otherwise contain both.
+func ParseMarkersWithPrefix(prefix, comment string) Markers {
+	m := map[string][]string{}
+	if !strings.Contains(comment, prefix) {
+		return m
+	}
+
+	for _, line := range strings.Split(normalizeNewlines(comment), "\n") {
+		line = trimLine(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		for _, marker := range splitMarkers(prefix, line) {
+			kv := strings.SplitN(marker[len(prefix):], "=", 2)
+			k, v := kv[0], ""
+			if len(kv) > 1 {
+				v = kv[1]
+			}
+			m[k] = append(m[k], v)
+		}
+	}
+
+	return m
+}
+
+// splitMarkers splits the supplied line, which begins with the supplied
+// prefix, into the markers it contains. A marker begins wherever the prefix
+// follows white space and precedes a letter, unless it is within a value
+// quoted with double quotes or backticks.
+func splitMarkers(prefix, line string) []string {
+	var markers []string
+	start := 0
+	var quote byte
+	for i := len(prefix); i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`':
+			quote = c
+		case unicode.IsSpace(rune(line[i-1])) && isMarker(prefix, line[i:]):
+			markers = append(markers, strings.TrimSpace(line[start:i]))
+			start = i
+		}
+	}
+	return append(markers, line[start:])
+}
+
+// isMarker returns true if the supplied text begins with a marker using the
+// supplied prefix.
+func isMarker(prefix, text string) bool {
+	if !strings.HasPrefix(text, prefix) || len(text) == len(prefix) {
+		return false
+	}
+	return unicode.IsLetter(rune(text[len(prefix)]))
+}
+
+func normalizeNewlines(s string) string {
+	return strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(s)
+}
+
+// trimLine removes leading and trailing white space from the supplied line of
+// a comment, as well as any leading '*' block comment continuation characters.
+func trimLine(line string) string {
+	return strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "*"))
+}
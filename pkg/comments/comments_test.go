@@ -0,0 +1,224 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comments
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestParseMarkers(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		comment string
+		want    Markers
+	}{
+		"LF": {
+			reason:  "Markers on lines ending with LF should be parsed.",
+			comment: "A comment.\n+key=value1\n+key=value2\n",
+			want:    Markers{"key": {"value1", "value2"}},
+		},
+		"CRLF": {
+			reason:  "Markers on lines ending with CRLF should be parsed, without a trailing CR in their values.",
+			comment: "A comment.\r\n+key=value1\r\n+key=value2\r\n",
+			want:    Markers{"key": {"value1", "value2"}},
+		},
+		"Tabs": {
+			reason:  "Markers indented with tabs should be parsed.",
+			comment: "A comment.\n\t+key=value1\n \t +key=value2\t\n",
+			want:    Markers{"key": {"value1", "value2"}},
+		},
+		"BlockCommentContinuation": {
+			reason:  "Markers preceded by block comment continuation characters should be parsed.",
+			comment: "\n * A comment.\n * +key=value1\n\t*+key=value2\n ** +other\n",
+			want:    Markers{"key": {"value1", "value2"}, "other": {""}},
+		},
+		"SeveralOnOneLine": {
+			reason:  "Several markers on one line should each be parsed, without the others in their values.",
+			comment: "A comment.\n+key=value1 +other=value2\t+key=value3\n",
+			want:    Markers{"key": {"value1", "value3"}, "other": {"value2"}},
+		},
+		"SeveralWithoutValuesOnOneLine": {
+			reason:  "Several markers without values on one line should each be parsed.",
+			comment: "+key +other=value\n",
+			want:    Markers{"key": {""}, "other": {"value"}},
+		},
+		"PrefixInValue": {
+			reason:  "A prefix that doesn't follow white space and precede a letter should be part of the value of a marker.",
+			comment: "+key=a+b +1 + c\n",
+			want:    Markers{"key": {"a+b +1 + c"}},
+		},
+		"QuotedValue": {
+			reason:  "A marker within a quoted value should be part of the value, not another marker.",
+			comment: "+key=\"a \\\" +b\" +other=`c +d`\n",
+			want:    Markers{"key": {"\"a \\\" +b\""}, "other": {"`c +d`"}},
+		},
+		"NotAMarker": {
+			reason:  "Lines that don't begin with the marker prefix should be ignored.",
+			comment: "A comment mentioning +key=value.\n* A list item.\n",
+			want:    Markers{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ParseMarkers(tc.comment)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nParseMarkers(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCommentsFor(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		source string
+		filter []string
+		with   string
+		want   Markers
+	}{
+		"LineComments": {
+			reason: "Markers in line comments should be parsed.",
+			source: "package v1\n\n// A Model.\n// +key=value\ntype Model struct{}\n",
+			want:   Markers{"key": {"value"}},
+		},
+		"SeveralMarkersOnOneLine": {
+			reason: "Several markers on one line of a line comment should each be parsed.",
+			source: "package v1\n\n// A Model.\n// +crossplane:generate:reference:type=Subnet +crossplane:generate:reference:extractor=ARN()\ntype Model struct{}\n",
+			want:   Markers{"crossplane:generate:reference:type": {"Subnet"}, "crossplane:generate:reference:extractor": {"ARN()"}},
+		},
+		"CRLFLineComments": {
+			reason: "Markers in line comments of a file with CRLF line endings should be parsed.",
+			source: "package v1\r\n\r\n// A Model.\r\n//\t+key=value\r\ntype Model struct{}\r\n",
+			want:   Markers{"key": {"value"}},
+		},
+		"BlockComment": {
+			reason: "Markers in block comments should be parsed.",
+			source: "package v1\n\n/*\n * A Model.\n * +key=value\n */\ntype Model struct{}\n",
+			want:   Markers{"key": {"value"}},
+		},
+		"CRLFBlockComment": {
+			reason: "Markers in block comments of a file with CRLF line endings should be parsed.",
+			source: "package v1\r\n\r\n/*\r\n\t* A Model.\r\n\t* +key=value\r\n*/\r\ntype Model struct{}\r\n",
+			want:   Markers{"key": {"value"}},
+		},
+		"FilteredBlockComment": {
+			reason: "Markers should be parsed from a file whose only comment that contains the filter is a block comment.",
+			source: "package v1\n\n// A Widget.\ntype Widget struct{}\n\n/*\n * A Model.\n * +crossplane:generate:key=value\n */\ntype Model struct{}\n",
+			filter: []string{"+crossplane:generate"},
+			want:   Markers{"crossplane:generate:key": {"value"}},
+		},
+		"FilteredLineComments": {
+			reason: "Markers should be parsed from a file with a comment that contains the filter, even if they don't contain it.",
+			source: "package v1\n\n// A Model.\n// +key=value\ntype Model struct{}\n\n// +crossplane:generate:methods=false\ntype Widget struct{}\n",
+			filter: []string{"+crossplane:generate"},
+			want:   Markers{"key": {"value"}},
+		},
+		"FilteredOut": {
+			reason: "Markers should not be parsed from a file without a comment that contains the filter.",
+			source: "package v1\n\n// A Model.\n// +key=value\ntype Model struct{}\n",
+			filter: []string{"+crossplane:generate", "+kubebuilder:"},
+			want:   Markers{},
+		},
+		"With": {
+			reason: "Markers supplied for an object should follow those in its comments.",
+			source: "package v1\n\n// A Model.\n// +key=value1\ntype Model struct{}\n",
+			with:   "+key=value2\n+other\n",
+			want:   Markers{"key": {"value1", "value2"}, "other": {""}},
+		},
+		"WithoutComments": {
+			reason: "Markers supplied for an object without comments should be parsed.",
+			source: "package v1\n\ntype Model struct{}\n",
+			with:   "+key=value\n",
+			want:   Markers{"key": {"value"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "model.go", tc.source, parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tp, err := (&types.Config{}).Check("example.org/v1", fset, []*ast.File{f}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var o []Option
+			if tc.filter != nil {
+				o = append(o, WithFilter(tc.filter...))
+			}
+			c := In(&packages.Package{Fset: fset, Syntax: []*ast.File{f}}, o...)
+			if tc.with != "" {
+				c = c.With(tp.Scope().Lookup("Model"), tc.with)
+			}
+			m := tp.Scope().Lookup("Model")
+			got := ParseMarkers(c.For(m))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nParseMarkers(c.For(...)): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(c.For(m), c.At(m.Pos())); diff != "" {
+				t.Errorf("\n%s\nc.At(...): -want comments for the object at the position, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// BenchmarkIn finds the comments of a package of 200 documented types, none of
+// which have markers.
+func BenchmarkIn(b *testing.B) {
+	src := &strings.Builder{}
+	src.WriteString("package v1\n")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(src, "\n// Model%d is a model.\ntype Model%d struct {\n\t// Name of the model.\n\tName string\n}\n", i, i)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "models.go", src.String(), parser.ParseComments)
+	if err != nil {
+		b.Fatal(err)
+	}
+	tp, err := (&types.Config{}).Check("example.org/v1", fset, []*ast.File{f}, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	p := &packages.Package{Fset: fset, Syntax: []*ast.File{f}}
+
+	cases := map[string][]Option{
+		"Unfiltered": nil,
+		"Filtered":   {WithFilter("+crossplane:generate")},
+	}
+	for name, o := range cases {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				c := In(p, o...)
+				for _, n := range tp.Scope().Names() {
+					ParseMarkers(c.For(tp.Scope().Lookup(n)))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package generatortest verifies that methods generated by angryjet compile and
+// satisfy the crossplane-runtime interfaces. It is intended to be used by
+// provider repositories in their own tests, without committing golden files
+// for every API type.
+package generatortest
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/copylock"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/analysis/passes/stdmethods"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/crossplane/crossplane-tools/internal/match"
+	"github.com/crossplane/crossplane-tools/pkg/angryjet"
+	"github.com/crossplane/crossplane-tools/pkg/comments"
+)
+
+// A Failure describes a problem with the methods generated for a package.
+type Failure struct {
+	// Package is the path of the package the failure occurred in.
+	Package string
+
+	// Type is the name of the type the failure relates to, if any.
+	Type string
+
+	// Message describes the failure.
+	Message string
+}
+
+func (f Failure) String() string {
+	if f.Type == "" {
+		return fmt.Sprintf("%s: %s", f.Package, f.Message)
+	}
+	return fmt.Sprintf("%s.%s: %s", f.Package, f.Type, f.Message)
+}
+
+type options struct {
+	Dir    string
+	Env    []string
+	Config angryjet.Config
+}
+
+// An Option configures Check.
+type Option func(o *options)
+
+// WithDir returns an Option that sets the directory in which packages are
+// loaded. The current directory is used by default.
+func WithDir(dir string) Option {
+	return func(o *options) {
+		o.Dir = dir
+	}
+}
+
+// WithEnv returns an Option that sets the environment used when packages are
+// loaded, for example to disable network access with GOPROXY=off. The current
+// environment is used by default.
+func WithEnv(env []string) Option {
+	return func(o *options) {
+		o.Env = env
+	}
+}
+
+// WithConfig returns an Option that sets the configuration used to generate
+// methods. Files are never written to disk, regardless of the configuration.
+func WithConfig(cfg angryjet.Config) Option {
+	return func(o *options) {
+		o.Config = cfg
+	}
+}
+
+// An implementation is a crossplane-runtime interface that types matched by
+// Kind must implement, if methods were generated for them.
+type implementation struct {
+	Interface string
+	Kind      match.Matcher
+}
+
+var implementations = []implementation{
+	{Interface: "Managed", Kind: match.Managed()},
+	{Interface: "ManagedList", Kind: match.ManagedList()},
+	{Interface: "ProviderConfig", Kind: match.ProviderConfig()},
+	{Interface: "ProviderConfigUsage", Kind: match.ProviderConfigUsage()},
+	{Interface: "ProviderConfigUsageList", Kind: match.ProviderConfigUsageList()},
+}
+
+// generated returns a Matcher that matches the types of the supplied kind for
+// which the supplied Config generates methods.
+func generated(p *packages.Package, cfg angryjet.Config, kind match.Matcher) match.Matcher {
+	m := []match.Matcher{kind, match.DoesNotHaveMarker(comments.In(p, comments.WithFilter(comments.DefaultMarkerPrefix+angryjet.DisableMarker)), angryjet.DisableMarker, "false")}
+	if cfg.Include != nil {
+		m = append(m, match.NameMatches(cfg.Include))
+	}
+	if cfg.Exclude != nil {
+		m = append(m, match.Not(match.NameMatches(cfg.Exclude)))
+	}
+	return match.And(m...)
+}
+
+// analyzers are the go vet analyzers that generated files must pass. They
+// report problems such as copied locks that would fail the build of a provider
+// that vets its generated code.
+var analyzers = []*analysis.Analyzer{copylock.Analyzer, stdmethods.Analyzer}
+
+// Check generates methods for the packages matching the supplied patterns,
+// type-checks and vets the result, and asserts that each generated type
+// implements the crossplane-runtime interface it was generated for. Generated files are
+// overlaid on the loaded packages; nothing is written to disk. An error is
+// returned if the packages cannot be loaded. Problems with the generated
+// methods are returned as failures. The auto runtime level is detected in the
+// directory and environment in which packages are loaded.
+func Check(patterns []string, o ...Option) ([]Failure, error) {
+	opts := &options{}
+	for _, fn := range o {
+		fn(opts)
+	}
+
+	pkgs, err := packages.Load(&packages.Config{Mode: angryjet.LoadMode, Dir: opts.Dir, Env: angryjet.LoadEnv(opts.Env)}, patterns...)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot load packages")
+	}
+
+	cfg := opts.Config
+	cfg.Dir, cfg.Env = opts.Dir, opts.Env
+	cfg, err = angryjet.DetectRuntime(context.Background(), cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot detect runtime level")
+	}
+
+	overlay := map[string][]byte{}
+	cfg.Write = func(filename string, data []byte) error {
+		overlay[filename] = data
+		return nil
+	}
+
+	failures := make([]Failure, 0)
+	resources := map[string]string{}
+	for _, p := range pkgs {
+		if len(p.Errors) > 0 {
+			return nil, errors.Errorf("cannot load package %s: %s", p.PkgPath, p.Errors[0])
+		}
+		if err := generate(p, cfg); err != nil {
+			failures = append(failures, Failure{Package: p.PkgPath, Message: err.Error()})
+		}
+		resources[p.PkgPath] = cfg.RuntimeModuleOf(p) + angryjet.ResourcePackage
+	}
+	resourcePatterns := make([]string, 0, len(resources))
+	for _, r := range resources {
+		if !contains(resourcePatterns, r) {
+			resourcePatterns = append(resourcePatterns, r)
+		}
+	}
+	sort.Strings(resourcePatterns)
+
+	// Generated packages are type-checked by typeCheck rather than by
+	// packages.Load, so that type errors are reported for each package and
+	// the sizes of the current toolchain are used. The resource packages of
+	// the crossplane-runtime modules that the packages use are loaded too,
+	// so that we can check for implementations of their interfaces by types
+	// that don't import them.
+	fset := token.NewFileSet()
+	loaded, err := packages.Load(&packages.Config{
+		Fset:    fset,
+		Mode:    packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax,
+		Dir:     opts.Dir,
+		Env:     angryjet.LoadEnv(opts.Env),
+		Overlay: overlay,
+	}, append(patterns, resourcePatterns...)...)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot load generated packages")
+	}
+
+	checked := map[string]*types.Package{"unsafe": types.Unsafe}
+	rps := map[string]*types.Package{}
+	for _, p := range loaded {
+		if contains(resourcePatterns, p.PkgPath) && len(p.Errors) == 0 {
+			rps[p.PkgPath], _ = typeCheck(fset, p, checked, nil)
+		}
+	}
+
+	for _, p := range pkgs {
+		for _, lp := range loaded {
+			if lp.PkgPath != p.PkgPath {
+				continue
+			}
+			info := &types.Info{
+				Types:      map[ast.Expr]types.TypeAndValue{},
+				Defs:       map[*ast.Ident]types.Object{},
+				Uses:       map[*ast.Ident]types.Object{},
+				Implicits:  map[ast.Node]types.Object{},
+				Selections: map[*ast.SelectorExpr]*types.Selection{},
+				Scopes:     map[ast.Node]*types.Scope{},
+			}
+			tp, errs := typeCheck(fset, lp, checked, info)
+			for _, err := range errs {
+				failures = append(failures, Failure{Package: p.PkgPath, Message: err.Error()})
+			}
+			if len(errs) > 0 {
+				continue
+			}
+			lp.Fset, lp.Types, lp.TypesInfo = fset, tp, info
+			failures = append(failures, vet(lp, overlay)...)
+			if rp := rps[resources[p.PkgPath]]; rp != nil {
+				failures = append(failures, checkImplementations(lp, rp, cfg)...)
+			}
+		}
+	}
+	return failures, nil
+}
+
+// typeCheck the supplied package and its imports, which are recorded in the
+// supplied map of type-checked packages. Type information of the supplied
+// package is recorded in the supplied info, if it is not nil. Errors are
+// returned only for the supplied package; imported packages are assumed to be
+// valid.
+func typeCheck(fset *token.FileSet, p *packages.Package, checked map[string]*types.Package, info *types.Info) (*types.Package, []error) {
+	imports := map[string]*types.Package{}
+	for path, ip := range p.Imports {
+		if tp, ok := checked[ip.PkgPath]; ok {
+			imports[path] = tp
+			continue
+		}
+		tp, _ := typeCheck(fset, ip, checked, nil)
+		imports[path] = tp
+	}
+
+	errs := make([]error, 0)
+	cfg := &types.Config{
+		Importer: importer(imports),
+		Sizes:    types.SizesFor("gc", runtime.GOARCH),
+		Error:    func(err error) { errs = append(errs, err) },
+	}
+	tp, _ := cfg.Check(p.PkgPath, fset, p.Syntax, info)
+	checked[p.PkgPath] = tp
+	return tp, errs
+}
+
+// An importer imports already type-checked packages by import path.
+type importer map[string]*types.Package
+
+func (i importer) Import(path string) (*types.Package, error) {
+	if tp, ok := i[path]; ok {
+		return tp, nil
+	}
+	return nil, errors.Errorf("package %s was not loaded", path)
+}
+
+// vet runs the analyzers over the supplied type-checked package, and returns a
+// failure for each problem they report in one of the supplied generated files.
+// Problems in other files are not the generator's to report.
+func vet(p *packages.Package, generated map[string][]byte) []Failure {
+	failures := make([]Failure, 0)
+	run := func(a *analysis.Analyzer, resultOf map[*analysis.Analyzer]interface{}) (interface{}, error) {
+		return a.Run(&analysis.Pass{
+			Analyzer:   a,
+			Fset:       p.Fset,
+			Files:      p.Syntax,
+			Pkg:        p.Types,
+			TypesInfo:  p.TypesInfo,
+			TypesSizes: types.SizesFor("gc", runtime.GOARCH),
+			ResultOf:   resultOf,
+			Report: func(d analysis.Diagnostic) {
+				pos := p.Fset.Position(d.Pos)
+				if _, ok := generated[pos.Filename]; !ok {
+					return
+				}
+				failures = append(failures, Failure{
+					Package: p.PkgPath,
+					Message: fmt.Sprintf("%s:%d:%d: %s: %s", filepath.Base(pos.Filename), pos.Line, pos.Column, a.Name, d.Message),
+				})
+			},
+		})
+	}
+
+	insp, err := run(inspect.Analyzer, nil)
+	if err != nil {
+		return append(failures, Failure{Package: p.PkgPath, Message: errors.Wrap(err, "cannot inspect generated package").Error()})
+	}
+	for _, a := range analyzers {
+		if _, err := run(a, map[*analysis.Analyzer]interface{}{inspect.Analyzer: insp}); err != nil {
+			failures = append(failures, Failure{Package: p.PkgPath, Message: errors.Wrapf(err, "cannot run %s", a.Name).Error()})
+		}
+	}
+	return failures
+}
+
+// generate methods for the supplied package, returning any panic as an error.
+func generate(p *packages.Package, cfg angryjet.Config) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic while generating methods: %v", r)
+		}
+	}()
+	return angryjet.Generate(p, cfg)
+}
+
+// checkImplementations returns a failure for each type in the supplied package
+// that does not implement the interface of the supplied resource package that
+// its methods were generated for.
+func checkImplementations(p *packages.Package, rp *types.Package, cfg angryjet.Config) []Failure {
+	failures := make([]Failure, 0)
+	names := p.Types.Scope().Names()
+	sort.Strings(names)
+	for _, impl := range implementations {
+		io := rp.Scope().Lookup(impl.Interface)
+		if io == nil {
+			continue
+		}
+		iface, ok := io.Type().Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+		m := generated(p, cfg, impl.Kind)
+		for _, n := range names {
+			o := p.Types.Scope().Lookup(n)
+			if !m.Match(o) {
+				continue
+			}
+			if m, wrongType := types.MissingMethod(types.NewPointer(o.Type()), iface, true); m != nil {
+				reason := "missing method"
+				if wrongType {
+					reason = "wrong type for method"
+				}
+				failures = append(failures, Failure{
+					Package: p.PkgPath,
+					Type:    o.Name(),
+					Message: fmt.Sprintf("*%s does not implement %s.%s (%s %s)", o.Name(), rp.Name(), impl.Interface, reason, m.Name()),
+				})
+			}
+		}
+	}
+	return failures
+}
+
+// contains returns true if the supplied strings contain the supplied string.
+func contains(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,506 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generatortest
+
+import (
+	"go/ast"
+	goimporter "go/importer"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/crossplane/crossplane-tools/pkg/angryjet"
+)
+
+// The provider module replaces all of its dependencies with minimal stand-ins
+// under testdata, so it can be loaded without network access. The forked module
+// does too, except crossplane-runtime, which it forks under an internal path.
+var (
+	provider = filepath.Join("testdata", "provider")
+	forked   = filepath.Join("testdata", "forked")
+	env      = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+)
+
+func TestCheck(t *testing.T) {
+	type want struct {
+		failures []Failure
+		err      bool
+	}
+
+	cases := map[string]struct {
+		reason   string
+		dir      string
+		patterns []string
+		config   angryjet.Config
+		want     want
+	}{
+		"Valid": {
+			reason:   "Methods generated for well formed API types should compile and satisfy the runtime interfaces.",
+			patterns: []string{"./apis/v1alpha1"},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithResolvableFields": {
+			reason:   "Resolvable field tables generated for well formed API types should compile.",
+			patterns: []string{"./apis/v1alpha1"},
+			config:   angryjet.Config{ResolvableFields: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithPointerElements": {
+			reason:   "Reference resolvers that hoist elements of slices of pointers into locals should compile, alongside recording resolved values and provenance.",
+			patterns: []string{"./apis/elements"},
+			config:   angryjet.Config{ResolvedValues: true, Provenance: "example.org/provider/provenance", SkipUnchanged: "example.org/resolved-inputs"},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithTenant": {
+			reason:   "Reference resolvers generated with a tenant function should compile.",
+			patterns: []string{"./apis/v1alpha1"},
+			config:   angryjet.Config{Tenant: "example.org/provider/tenancy.Namespace"},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithSkipUnchanged": {
+			reason:   "Reference resolvers generated to skip unchanged references should compile.",
+			patterns: []string{"./apis/v1alpha1"},
+			config:   angryjet.Config{SkipUnchanged: "example.org/resolved-inputs"},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithDependencyAnnotation": {
+			reason:   "Reference resolvers generated to record resolved dependencies should compile, alongside skipping unchanged references.",
+			patterns: []string{"./apis/v1alpha1"},
+			config:   angryjet.Config{DependencyAnnotation: "example.org/dependencies", SkipUnchanged: "example.org/resolved-inputs", ResolvedValues: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithWrapWithMessage": {
+			reason:   "Reference resolvers generated to wrap errors with a message should compile.",
+			patterns: []string{"./apis/v1alpha1"},
+			config:   angryjet.Config{WrapWithMessage: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithErrorWrapper": {
+			reason:   "Reference resolvers generated to wrap errors with a function of the provider should compile.",
+			patterns: []string{"./apis/v1alpha1", "./apis/paved"},
+			config:   angryjet.Config{ErrorWrapper: "example.org/provider/providererrors.Reference"},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithFailureCondition": {
+			reason:   "Reference resolvers generated to set a condition when they fail should compile, alongside recording resolved values.",
+			patterns: []string{"./apis/v1alpha1", "./apis/paved"},
+			config:   angryjet.Config{FailureConditionType: "ReferencesResolved", ResolvedValues: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithClock": {
+			reason:   "Reference resolvers generated to tell the time using a clock should compile, alongside timeouts and failure conditions.",
+			patterns: []string{"./apis/v1alpha1"},
+			config:   angryjet.Config{Clock: "example.org/provider/clock.Clock", FailureConditionType: "ReferencesResolved"},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithResolversIndex": {
+			reason:   "A reference resolvers index should compile alongside the reference resolvers it calls.",
+			patterns: []string{"./apis/v1alpha1", "./apis/paved"},
+			config:   angryjet.Config{ResolversIndex: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithSkipEmpty": {
+			reason:   "Reference resolvers generated to skip fields whose reference and selector are unset should compile, alongside recording resolved values.",
+			patterns: []string{"./apis/v1alpha1", "./apis/paved"},
+			config:   angryjet.Config{SkipEmpty: true, ResolvedValues: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithLogging": {
+			reason:   "Reference resolvers generated to log resolution should compile.",
+			patterns: []string{"./apis/v1alpha1"},
+			config:   angryjet.Config{ResolverLogging: "example.org/provider/logging"},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithProvenance": {
+			reason:   "Reference resolvers generated to record provenance should compile.",
+			patterns: []string{"./apis/v1alpha1", "./apis/paved"},
+			config:   angryjet.Config{Provenance: "example.org/provider/provenance", ResolvedValues: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithAssertions": {
+			reason:   "Reference resolvers generated with assertions that their receivers are managed resources should compile.",
+			patterns: []string{"./apis/v1alpha1", "./apis/paved"},
+			config:   angryjet.Config{ResolverAssertions: true, ResolvedValues: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithAccessorVariants": {
+			reason:   "Managed resource methods generated with the variants of every accessor should compile.",
+			patterns: []string{"./apis/v1alpha1"},
+			config:   angryjet.Config{AccessorVariants: angryjet.AccessorVariants()},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithControllerRuntime": {
+			reason:   "Reference resolvers generated to use the controller-runtime client directly should compile.",
+			patterns: []string{"./apis/v1alpha1"},
+			config:   angryjet.Config{ControllerRuntime: true, DependencyAnnotation: "example.org/dependencies", SkipUnchanged: "example.org/resolved-inputs"},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithResolver": {
+			reason:   "Reference resolvers that resolve references with a resolver other than the API resolver should compile.",
+			patterns: []string{"./apis/v1alpha1"},
+			config:   angryjet.Config{Resolver: "example.org/provider/webhook.NewResolver", ResolvedValues: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidPaved": {
+			reason:   "Reference resolvers of keys of free-form maps should compile.",
+			patterns: []string{"./apis/paved"},
+			config:   angryjet.Config{ClearSelectors: true, SkipUnchanged: "example.org/resolved-inputs", ResolvedValues: true, ResolvableFields: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithDescribedPavedPaths": {
+			reason:   "Reference resolvers of paths within nested free-form maps, described rather than marked, should compile.",
+			patterns: []string{"./apis/paved"},
+			config: angryjet.Config{
+				Descriptions: []angryjet.Description{{
+					Package: "example.org/provider/apis/paved",
+					Type:    "Widget",
+					References: []angryjet.DescribedReference{{
+						Field:   "Spec.ForProvider.Properties",
+						Markers: map[string][]string{"pavedPaths": {"network.gizmoId=Gizmo;network.routes[0].gizmoId=Gizmo"}},
+					}},
+				}},
+				SkipUnchanged:    "example.org/resolved-inputs",
+				ResolvedValues:   true,
+				ResolvableFields: true,
+			},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithMirrors": {
+			reason:   "Reference resolvers that also write resolved values to other fields and to keys of maps should compile.",
+			patterns: []string{"./apis/mirrors"},
+			config:   angryjet.Config{ResolvedValues: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithFieldSelectors": {
+			reason:   "Reference resolvers that select resources by field selectors as well as labels should compile.",
+			patterns: []string{"./apis/fieldselector"},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithNoRefWriteBack": {
+			reason:   "Reference resolvers that don't write resolved references back to reference fields, some of which don't exist, should compile.",
+			patterns: []string{"./apis/writeback"},
+			config:   angryjet.Config{ClearSelectors: true, SkipUnchanged: "example.org/resolved-inputs", ResolvedValues: true, ResolvableFields: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithReservedNames": {
+			reason:   "Reference resolvers of fields and extractors named like the variables of the generated method should compile.",
+			patterns: []string{"./apis/names"},
+			config:   angryjet.Config{SkipUnchanged: "example.org/resolved-inputs", ResolvableFields: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidEmbeddedTwice": {
+			reason:   "Reference resolvers of fields reachable through two routes of embedded structs should compile.",
+			patterns: []string{"./apis/embedding"},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithEmbeddedReferences": {
+			reason:   "Reference resolvers of fields whose reference and selector fields are promoted from an embedded struct should compile.",
+			patterns: []string{"./apis/embeddedrefs"},
+			config:   angryjet.Config{ResolvableFields: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithLocks": {
+			reason:   "Methods generated for API types that contain locks should pass go vet's copylocks check.",
+			patterns: []string{"./apis/locks"},
+			config:   angryjet.Config{ResolvedValues: true, ResolvableFields: true, SkipUnchanged: "example.org/resolved-inputs"},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithUnsafe": {
+			reason:   "Methods generated for API types with fields of unsafe types should compile.",
+			patterns: []string{"./apis/unsafe"},
+			config:   angryjet.Config{ResolvedValues: true, ResolvableFields: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithIndirectReferences": {
+			reason:   "Reference resolvers generated for reference fields that hold references by value, or slices of them by pointer, should compile.",
+			patterns: []string{"./apis/indirect"},
+			config:   angryjet.Config{ResolvedValues: true, ResolvableFields: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithLocksAndControllerRuntime": {
+			reason:   "Resolvers that use the controller-runtime client for API types that contain locks should pass go vet's copylocks check.",
+			patterns: []string{"./apis/locks"},
+			config:   angryjet.Config{ControllerRuntime: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidUnexported": {
+			reason:   "Methods generated for unexported API types should compile and satisfy the runtime interfaces.",
+			patterns: []string{"./apis/unexported"},
+			config:   angryjet.Config{ResolvableFields: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"InterfaceNotSatisfied": {
+			reason:   "A type whose hand written method prevents the correct one from being generated should not satisfy resource.Managed.",
+			patterns: []string{"./apis/mismatch"},
+			want: want{
+				failures: []Failure{{
+					Package: "example.org/provider/apis/mismatch",
+					Type:    "Widget",
+					Message: "*Widget does not implement resource.Managed (wrong type for method GetCondition)",
+				}},
+			},
+		},
+		"ValidWithForkedRuntime": {
+			reason:   "Methods generated for API types that embed the types of a fork of crossplane-runtime should import the fork, compile, and satisfy its interfaces.",
+			dir:      forked,
+			patterns: []string{"./apis/v1alpha1"},
+			config:   angryjet.Config{ResolvableFields: true, ResolversIndex: true},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"ValidWithRuntimeModule": {
+			reason:   "Methods generated with a configured crossplane-runtime module should import it.",
+			dir:      forked,
+			patterns: []string{"./apis/v1alpha1"},
+			config:   angryjet.Config{RuntimeModule: "example.org/forked/internal/thirdparty/crossplane-runtime"},
+			want: want{
+				failures: []Failure{},
+			},
+		},
+		"DoesNotCompile": {
+			reason:   "A reference to a type without a list type should produce a type error.",
+			patterns: []string{"./apis/nolist"},
+			want: want{
+				failures: []Failure{{
+					Package: "example.org/provider/apis/nolist",
+					Message: "undefined: GizmoList",
+				}},
+			},
+		},
+		"NoSuchPackage": {
+			reason:   "An error should be returned if the packages cannot be loaded.",
+			patterns: []string{"./apis/nope"},
+			want: want{
+				err: true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := provider
+			if tc.dir != "" {
+				dir = tc.dir
+			}
+			got, err := Check(tc.patterns, WithDir(dir), WithEnv(env), WithConfig(tc.config))
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Fatalf("\n%s\nCheck(...): -want error, +got error:\n%s\n%v", tc.reason, diff, err)
+			}
+			// Type errors are prefixed with their position, which we don't
+			// want to depend upon.
+			for i := range got {
+				if idx := strings.Index(got[i].Message, ": "); got[i].Type == "" && idx >= 0 {
+					got[i].Message = got[i].Message[idx+2:]
+				}
+			}
+			if diff := cmp.Diff(tc.want.failures, got); diff != "" {
+				t.Errorf("\n%s\nCheck(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// The provider module is loaded with an alternative module file to replace the
+// crossplane-runtime stand-in with one that has the v0.19 API.
+var envV019 = append(os.Environ(), "GOFLAGS=-mod=mod -modfile=go.v0.19.mod", "GOPROXY=off", "GOWORK=off")
+
+func TestCheckRuntimeLevels(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		env    []string
+		level  string
+		fails  bool
+	}{
+		"LatestRuntimeLatestLevel": {
+			reason: "Methods generated for the latest level should compile against the latest runtime.",
+			env:    env,
+			level:  angryjet.RuntimeLevelLatest,
+		},
+		"LatestRuntimeAutoLevel": {
+			reason: "Methods generated for the detected level should compile against the latest runtime.",
+			env:    env,
+			level:  angryjet.RuntimeLevelAuto,
+		},
+		"LatestRuntimeOldLevel": {
+			reason: "Methods generated for v0.19 should not satisfy the latest runtime's resource.Managed.",
+			env:    env,
+			level:  "v0.19",
+			fails:  true,
+		},
+		"OldRuntimeLatestLevel": {
+			reason: "Methods generated for the latest level should not compile against the v0.19 runtime.",
+			env:    envV019,
+			level:  angryjet.RuntimeLevelLatest,
+			fails:  true,
+		},
+		"OldRuntimeAutoLevel": {
+			reason: "Methods generated for the detected level should compile against the v0.19 runtime.",
+			env:    envV019,
+			level:  angryjet.RuntimeLevelAuto,
+		},
+		"OldRuntimeOldLevel": {
+			reason: "Methods generated for v0.19 should compile against the v0.19 runtime.",
+			env:    envV019,
+			level:  "v0.19.2",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Check([]string{"./apis/v1alpha1"}, WithDir(provider), WithEnv(tc.env), WithConfig(angryjet.Config{RuntimeLevel: tc.level}))
+			if err != nil {
+				t.Fatalf("\n%s\nCheck(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.fails, len(got) > 0); diff != "" {
+				t.Errorf("\n%s\nCheck(...): -want failures, +got failures:\n%s\n%v", tc.reason, diff, got)
+			}
+		})
+	}
+}
+
+func TestVet(t *testing.T) {
+	source := `
+package v1alpha1
+
+import "sync"
+
+type Widget struct {
+	mu sync.Mutex
+}
+
+type WidgetList struct {
+	Items []Widget
+}
+
+func (w Widget) Copy() Widget { return w }
+`
+	generated := `
+package v1alpha1
+
+func (l *WidgetList) GetItems() []*Widget {
+	items := make([]*Widget, 0, len(l.Items))
+	for _, i := range l.Items {
+		items = append(items, &i)
+	}
+	return items
+}
+
+func (mg *Widget) ReadByte() error { return nil }
+`
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, 2)
+	for name, src := range map[string]string{"/apis/types.go": source, "/apis/zz_generated.go": generated} {
+		f, err := parser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	info := &gotypes.Info{
+		Types:      map[ast.Expr]gotypes.TypeAndValue{},
+		Defs:       map[*ast.Ident]gotypes.Object{},
+		Uses:       map[*ast.Ident]gotypes.Object{},
+		Implicits:  map[ast.Node]gotypes.Object{},
+		Selections: map[*ast.SelectorExpr]*gotypes.Selection{},
+		Scopes:     map[ast.Node]*gotypes.Scope{},
+	}
+	cfg := &gotypes.Config{Importer: goimporter.ForCompiler(fset, "source", nil)}
+	tp, err := cfg.Check("example.org/apis", fset, files, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &packages.Package{PkgPath: "example.org/apis", Fset: fset, Syntax: files, Types: tp, TypesInfo: info}
+	want := []Failure{
+		{Package: "example.org/apis", Message: "zz_generated.go:6:9: copylocks: range var i copies lock: example.org/apis.Widget contains sync.Mutex"},
+		{Package: "example.org/apis", Message: "zz_generated.go:12:19: stdmethods: method ReadByte() error should have signature ReadByte() (byte, error)"},
+	}
+	got := vet(p, map[string][]byte{"/apis/zz_generated.go": nil})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("vet(...): -want, +got:\n%s", diff)
+	}
+}
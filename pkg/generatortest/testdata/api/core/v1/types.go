@@ -0,0 +1,12 @@
+// Package v1 is a minimal stand-in for the Kubernetes core/v1 API types.
+package v1
+
+// A ConditionStatus is the status of a condition.
+type ConditionStatus string
+
+// These are valid condition statuses.
+const (
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown"
+)
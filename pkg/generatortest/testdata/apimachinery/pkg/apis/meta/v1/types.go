@@ -0,0 +1,46 @@
+// Package v1 is a minimal stand-in for the Kubernetes meta/v1 API types.
+package v1
+
+// An Object is a Kubernetes object with metadata.
+type Object interface {
+	GetName() string
+	GetAnnotations() map[string]string
+}
+
+// TypeMeta describes an individual object.
+type TypeMeta struct {
+	Kind       string
+	APIVersion string
+}
+
+// ObjectMeta is metadata that all persisted resources must have.
+type ObjectMeta struct {
+	Name        string
+	Namespace   string
+	Annotations map[string]string
+}
+
+// GetName returns the name of the object.
+func (m *ObjectMeta) GetName() string { return m.Name }
+
+// GetAnnotations returns the annotations of the object.
+func (m *ObjectMeta) GetAnnotations() map[string]string { return m.Annotations }
+
+// SetAnnotations sets the annotations of the object.
+func (m *ObjectMeta) SetAnnotations(a map[string]string) { m.Annotations = a }
+
+// ListMeta describes metadata that synthetic resources must have.
+type ListMeta struct {
+	ResourceVersion string
+}
+
+// Time is a wrapper around time.Time.
+type Time struct{}
+
+// Now returns the current local time.
+func Now() Time { return Time{} }
+
+// NewTime returns a wrapped instance of the provided time. It takes anything
+// with a Unix method, such as a time.Time, so that this stand-in needn't
+// import the time package.
+func NewTime(t interface{ Unix() int64 }) Time { return Time{} }
@@ -0,0 +1,33 @@
+// Package client is a minimal stand-in for the controller-runtime client.
+package client
+
+import "context"
+
+// An Object is a Kubernetes object.
+type Object interface {
+	GetName() string
+}
+
+// An ObjectList is a list of Kubernetes objects.
+type ObjectList interface{}
+
+// An ObjectKey identifies a Kubernetes object.
+type ObjectKey struct {
+	Namespace string
+	Name      string
+}
+
+// A ListOption configures a list request.
+type ListOption interface{}
+
+// MatchingLabels filters a list request by labels.
+type MatchingLabels map[string]string
+
+// InNamespace restricts a list request to a namespace.
+type InNamespace string
+
+// A Reader reads Kubernetes objects.
+type Reader interface {
+	Get(ctx context.Context, key ObjectKey, obj Object) error
+	List(ctx context.Context, list ObjectList, opts ...ListOption) error
+}
@@ -0,0 +1,32 @@
+// Package errors is a minimal stand-in for github.com/pkg/errors.
+package errors
+
+// Wrap annotates the supplied error with a message.
+func Wrap(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return wrapped{cause: err, message: message}
+}
+
+type wrapped struct {
+	cause   error
+	message string
+}
+
+func (w wrapped) Error() string { return w.message + ": " + w.cause.Error() }
+
+// WithMessage annotates the supplied error with a message, without recording
+// a stack trace.
+func WithMessage(err error, message string) error {
+	return Wrap(err, message)
+}
+
+// New returns an error with the supplied message.
+func New(message string) error {
+	return fundamental(message)
+}
+
+type fundamental string
+
+func (f fundamental) Error() string { return string(f) }
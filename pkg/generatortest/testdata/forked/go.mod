@@ -0,0 +1,15 @@
+module example.org/forked
+
+go 1.18
+
+require (
+	github.com/pkg/errors v0.0.0
+	k8s.io/apimachinery v0.0.0
+	sigs.k8s.io/controller-runtime v0.0.0
+)
+
+replace (
+	github.com/pkg/errors => ../errors
+	k8s.io/apimachinery => ../apimachinery
+	sigs.k8s.io/controller-runtime => ../controller-runtime
+)
@@ -0,0 +1,57 @@
+// Package fieldpath is a minimal stand-in for the crossplane-runtime fieldpath
+// package.
+package fieldpath
+
+// A Paved is an unstructured object that may be read and written by field
+// path.
+type Paved struct {
+	object map[string]interface{}
+}
+
+// Pave the supplied object.
+func Pave(object map[string]interface{}) *Paved {
+	return &Paved{object: object}
+}
+
+// UnstructuredContent returns the object.
+func (p *Paved) UnstructuredContent() map[string]interface{} {
+	return p.object
+}
+
+// GetString returns the string at the supplied path.
+func (p *Paved) GetString(path string) (string, error) {
+	s, _ := p.object[path].(string)
+	return s, nil
+}
+
+// GetValue returns the value at the supplied path.
+func (p *Paved) GetValue(path string) (interface{}, error) {
+	return p.object[path], nil
+}
+
+// GetValueInto reads the value at the supplied path into the supplied
+// pointer.
+func (p *Paved) GetValueInto(path string, out interface{}) error {
+	return nil
+}
+
+// SetValue sets the value at the supplied path.
+func (p *Paved) SetValue(path string, value interface{}) error {
+	if p.object == nil {
+		p.object = map[string]interface{}{}
+	}
+	p.object[path] = value
+	return nil
+}
+
+// DeleteField deletes the field at the supplied path.
+func (p *Paved) DeleteField(path string) error {
+	delete(p.object, path)
+	return nil
+}
+
+// IsNotFound returns true if the supplied error indicates that a field was not
+// found.
+func IsNotFound(err error) bool {
+	return false
+}
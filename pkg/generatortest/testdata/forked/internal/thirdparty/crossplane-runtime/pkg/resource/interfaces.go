@@ -0,0 +1,58 @@
+// Package resource is a minimal stand-in for the crossplane-runtime resource
+// interfaces. Each interface includes only the methods angryjet generates, and
+// those that generated methods call.
+package resource
+
+import (
+	xpv1 "example.org/forked/internal/thirdparty/crossplane-runtime/apis/common/v1"
+)
+
+// A Conditioned may have conditions set or retrieved.
+type Conditioned interface {
+	SetConditions(c ...xpv1.Condition)
+	GetCondition(xpv1.ConditionType) xpv1.Condition
+}
+
+// A Managed is a Kubernetes object representing a concrete managed resource.
+type Managed interface {
+	Conditioned
+
+	GetAnnotations() map[string]string
+
+	SetProviderReference(p *xpv1.Reference)
+	GetProviderReference() *xpv1.Reference
+	SetProviderConfigReference(p *xpv1.Reference)
+	GetProviderConfigReference() *xpv1.Reference
+	SetWriteConnectionSecretToReference(r *xpv1.SecretReference)
+	GetWriteConnectionSecretToReference() *xpv1.SecretReference
+	SetPublishConnectionDetailsTo(r *xpv1.PublishConnectionDetailsTo)
+	GetPublishConnectionDetailsTo() *xpv1.PublishConnectionDetailsTo
+	SetDeletionPolicy(p xpv1.DeletionPolicy)
+	GetDeletionPolicy() xpv1.DeletionPolicy
+}
+
+// A ManagedList is a list of managed resources.
+type ManagedList interface {
+	GetItems() []Managed
+}
+
+// A ProviderConfig configures a provider.
+type ProviderConfig interface {
+	Conditioned
+
+	SetUsers(i int64)
+	GetUsers() int64
+}
+
+// A ProviderConfigUsage indicates a usage of a provider config.
+type ProviderConfigUsage interface {
+	SetProviderConfigReference(r xpv1.Reference)
+	GetProviderConfigReference() xpv1.Reference
+	SetResourceReference(r xpv1.TypedReference)
+	GetResourceReference() xpv1.TypedReference
+}
+
+// A ProviderConfigUsageList is a list of provider config usages.
+type ProviderConfigUsageList interface {
+	GetItems() []ProviderConfigUsage
+}
@@ -0,0 +1,68 @@
+package cached
+
+import (
+	"context"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reference"
+)
+
+// TestResolveReferences is run against generated resolvers by the tests of
+// package angryjet, which cache resolutions in the status of a Widget.
+func TestResolveReferences(t *testing.T) {
+	mg := &Widget{Spec: WidgetSpec{ForProvider: WidgetParameters{
+		GizmoIDRef:   &xpv1.Reference{Name: "a"},
+		GizmoIDsRefs: []xpv1.Reference{{Name: "b"}},
+	}}}
+
+	// Nothing is cached at first, so every field is resolved.
+	reference.Resolutions = 0
+	if err := mg.ResolveReferences(context.Background(), nil); err != nil {
+		t.Fatalf("ResolveReferences(...): %v", err)
+	}
+	if reference.Resolutions != 2 {
+		t.Errorf("ResolveReferences(...): want 2 resolutions without a cache, got %d", reference.Resolutions)
+	}
+	if mg.Spec.ForProvider.GizmoID == nil || *mg.Spec.ForProvider.GizmoID != "a" {
+		t.Errorf("ResolveReferences(...): want gizmo a, got %v", mg.Spec.ForProvider.GizmoID)
+	}
+	if len(mg.Status.ResolvedReferences) != 2 {
+		t.Errorf("ResolveReferences(...): want 2 cached resolutions, got %v", mg.Status.ResolvedReferences)
+	}
+
+	// Nothing has changed on the next reconcile, so nothing is resolved.
+	reference.Resolutions = 0
+	if err := mg.ResolveReferences(context.Background(), nil); err != nil {
+		t.Fatalf("ResolveReferences(...): %v", err)
+	}
+	if reference.Resolutions != 0 {
+		t.Errorf("ResolveReferences(...): want no resolutions while cached, got %d", reference.Resolutions)
+	}
+
+	// Only the field whose reference changed is resolved again.
+	mg.Spec.ForProvider.GizmoIDRef = &xpv1.Reference{Name: "c"}
+	reference.Resolutions = 0
+	if err := mg.ResolveReferences(context.Background(), nil); err != nil {
+		t.Fatalf("ResolveReferences(...): %v", err)
+	}
+	if reference.Resolutions != 1 {
+		t.Errorf("ResolveReferences(...): want 1 resolution of the changed reference, got %d", reference.Resolutions)
+	}
+	if mg.Spec.ForProvider.GizmoID == nil || *mg.Spec.ForProvider.GizmoID != "c" {
+		t.Errorf("ResolveReferences(...): want gizmo c, got %v", mg.Spec.ForProvider.GizmoID)
+	}
+
+	// A value that was changed since it was resolved is resolved again.
+	mg.Spec.ForProvider.GizmoIDs = []string{"d"}
+	reference.Resolutions = 0
+	if err := mg.ResolveReferences(context.Background(), nil); err != nil {
+		t.Fatalf("ResolveReferences(...): %v", err)
+	}
+	if reference.Resolutions != 1 {
+		t.Errorf("ResolveReferences(...): want 1 resolution of the changed value, got %d", reference.Resolutions)
+	}
+	if len(mg.Spec.ForProvider.GizmoIDs) != 1 || mg.Spec.ForProvider.GizmoIDs[0] != "b" {
+		t.Errorf("ResolveReferences(...): want gizmos [b], got %v", mg.Spec.ForProvider.GizmoIDs)
+	}
+}
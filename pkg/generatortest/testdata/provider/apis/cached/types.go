@@ -0,0 +1,75 @@
+// Package cached contains a managed resource whose generated reference
+// resolvers cache resolutions in its status, which its test executes.
+package cached
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	// +crossplane:generate:reference:type=Gizmo
+	GizmoID *string
+
+	GizmoIDRef      *xpv1.Reference
+	GizmoIDSelector *xpv1.Selector
+
+	// +crossplane:generate:reference:type=Gizmo
+	GizmoIDs []string
+
+	GizmoIDsRefs     []xpv1.Reference
+	GizmoIDsSelector *xpv1.Selector
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec
+	ForProvider WidgetParameters
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus
+
+	// ResolvedReferences caches the resolutions of the references of a
+	// Widget.
+	ResolvedReferences map[string]string
+}
+
+// A Widget is a managed resource.
+// +crossplane:generate:reference:cache=ResolvedReferences
+type Widget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   WidgetSpec
+	Status WidgetStatus
+}
+
+// A GizmoSpec defines the desired state of a Gizmo.
+type GizmoSpec struct {
+	xpv1.ResourceSpec
+}
+
+// A GizmoStatus represents the observed state of a Gizmo.
+type GizmoStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Gizmo is a managed resource.
+type Gizmo struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   GizmoSpec
+	Status GizmoStatus
+}
+
+// GizmoList contains a list of Gizmo.
+type GizmoList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []Gizmo
+}
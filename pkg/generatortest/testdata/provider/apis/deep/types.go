@@ -0,0 +1,80 @@
+// Package deep contains a managed resource with a reference that is deeper
+// than the maximum depth.
+package deep
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Inner is the innermost configuration of a Widget.
+type Inner struct {
+	// +crossplane:generate:reference:type=Gizmo
+	GizmoID string
+
+	GizmoIDRef      *xpv1.Reference
+	GizmoIDSelector *xpv1.Selector
+}
+
+// Middle configuration of a Widget.
+type Middle struct {
+	Inner *Inner
+}
+
+// Outer configuration of a Widget.
+type Outer struct {
+	Middle Middle
+}
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	Outer Outer
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec
+	ForProvider WidgetParameters
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Widget is a managed resource that references a Gizmo from a field that is
+// five fields deep.
+type Widget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   WidgetSpec
+	Status WidgetStatus
+}
+
+// A GizmoSpec defines the desired state of a Gizmo.
+type GizmoSpec struct {
+	xpv1.ResourceSpec
+}
+
+// A GizmoStatus represents the observed state of a Gizmo.
+type GizmoStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Gizmo is a managed resource.
+type Gizmo struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   GizmoSpec
+	Status GizmoStatus
+}
+
+// A GizmoList is a list of Gizmos.
+type GizmoList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []Gizmo
+}
@@ -0,0 +1,35 @@
+// Package v1alpha2 contains a deprecated version of the Gizmo API.
+// +crossplane:deprecated=example.org/provider/apis/deprecated/v1beta1
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A GizmoSpec defines the desired state of a Gizmo.
+type GizmoSpec struct {
+	xpv1.ResourceSpec
+}
+
+// A GizmoStatus represents the observed state of a Gizmo.
+type GizmoStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Gizmo is a managed resource.
+type Gizmo struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   GizmoSpec
+	Status GizmoStatus
+}
+
+// A GizmoList is a list of Gizmos.
+type GizmoList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []Gizmo
+}
@@ -0,0 +1,76 @@
+// Package v1beta1 contains a managed resource that references kinds in
+// deprecated versions of their APIs.
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	// +crossplane:generate:reference:type=example.org/provider/apis/deprecated/v1alpha1.Gizmo
+	RetiredGizmoID string
+
+	RetiredGizmoIDRef      *xpv1.Reference
+	RetiredGizmoIDSelector *xpv1.Selector
+
+	// +crossplane:generate:reference:type=example.org/provider/apis/deprecated/v1alpha2.Gizmo
+	LegacyGizmoID string
+
+	LegacyGizmoIDRef      *xpv1.Reference
+	LegacyGizmoIDSelector *xpv1.Selector
+
+	// +crossplane:generate:reference:type=Gizmo
+	GizmoID string
+
+	GizmoIDRef      *xpv1.Reference
+	GizmoIDSelector *xpv1.Selector
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec
+	ForProvider WidgetParameters
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Widget is a managed resource.
+type Widget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   WidgetSpec
+	Status WidgetStatus
+}
+
+// A GizmoSpec defines the desired state of a Gizmo.
+type GizmoSpec struct {
+	xpv1.ResourceSpec
+}
+
+// A GizmoStatus represents the observed state of a Gizmo.
+type GizmoStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Gizmo is a managed resource.
+type Gizmo struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   GizmoSpec
+	Status GizmoStatus
+}
+
+// A GizmoList is a list of Gizmos.
+type GizmoList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []Gizmo
+}
@@ -0,0 +1,87 @@
+// Package elements contains a managed resource with references in elements of
+// slices of pointers, nested within each other and within slices of values.
+package elements
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A Target of a Rule.
+type Target struct {
+	// +crossplane:generate:reference:type=Gizmo
+	GizmoID *string
+
+	GizmoIDRef      *xpv1.Reference
+	GizmoIDSelector *xpv1.Selector
+}
+
+// A Rule of a Widget.
+type Rule struct {
+	// +crossplane:generate:reference:type=Gizmo
+	GizmoID string
+
+	GizmoIDRef      *xpv1.Reference
+	GizmoIDSelector *xpv1.Selector
+
+	Targets []*Target
+}
+
+// A Group of Rules.
+type Group struct {
+	Rules []*Rule
+}
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	Rules  []*Rule
+	Groups []Group
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec
+	ForProvider WidgetParameters
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Widget is a managed resource that references Gizmos from elements of
+// slices of pointers.
+type Widget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   WidgetSpec
+	Status WidgetStatus
+}
+
+// A GizmoSpec defines the desired state of a Gizmo.
+type GizmoSpec struct {
+	xpv1.ResourceSpec
+}
+
+// A GizmoStatus represents the observed state of a Gizmo.
+type GizmoStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Gizmo is a managed resource.
+type Gizmo struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   GizmoSpec
+	Status GizmoStatus
+}
+
+// A GizmoList is a list of Gizmos.
+type GizmoList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []Gizmo
+}
@@ -0,0 +1,77 @@
+// Package embeddedrefs contains a managed resource whose parameters embed a
+// struct that carries the reference and selector fields of their references.
+package embeddedrefs
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// GizmoReferences hold the reference and selector fields of the Gizmo
+// references of WidgetParameters.
+type GizmoReferences struct {
+	GizmoIDRef      *xpv1.Reference `json:"gizmoIdRef,omitempty"`
+	GizmoIDSelector *xpv1.Selector  `json:"gizmoIdSelector,omitempty"`
+
+	GizmoIDsRefs     []xpv1.Reference `json:"gizmoIdsRefs,omitempty"`
+	GizmoIDsSelector *xpv1.Selector   `json:"gizmoIdsSelector,omitempty"`
+}
+
+// WidgetParameters are the configurable fields of a Widget. The reference and
+// selector fields of its references are promoted from GizmoReferences.
+type WidgetParameters struct {
+	// +crossplane:generate:reference:type=Gizmo
+	GizmoID *string `json:"gizmoId,omitempty"`
+
+	// +crossplane:generate:reference:type=Gizmo
+	GizmoIDs []string `json:"gizmoIds,omitempty"`
+
+	GizmoReferences `json:",inline"`
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       WidgetParameters `json:"forProvider"`
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+}
+
+// A Widget is a managed resource that references Gizmos.
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+// A GizmoSpec defines the desired state of a Gizmo.
+type GizmoSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+}
+
+// A GizmoStatus represents the observed state of a Gizmo.
+type GizmoStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+}
+
+// A Gizmo is a managed resource.
+type Gizmo struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GizmoSpec   `json:"spec"`
+	Status GizmoStatus `json:"status,omitempty"`
+}
+
+// A GizmoList is a list of Gizmos.
+type GizmoList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Gizmo `json:"items"`
+}
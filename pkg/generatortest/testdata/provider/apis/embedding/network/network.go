@@ -0,0 +1,16 @@
+// Package network contains network configuration that was split out of the
+// embedding package, so that the shared package can embed it.
+package network
+
+import (
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Network configuration of a managed resource.
+type Network struct {
+	// +crossplane:generate:reference:type=example.org/provider/apis/embedding.Gizmo
+	SubnetID string `json:"subnetId,omitempty"`
+
+	SubnetIDRef      *xpv1.Reference `json:"subnetIdRef,omitempty"`
+	SubnetIDSelector *xpv1.Selector  `json:"subnetIdSelector,omitempty"`
+}
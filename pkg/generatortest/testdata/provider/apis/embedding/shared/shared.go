@@ -0,0 +1,14 @@
+// Package shared contains configuration that is shared by managed resources.
+package shared
+
+import (
+	"example.org/provider/apis/embedding/network"
+)
+
+// Common configuration of a managed resource, which embeds its network
+// configuration.
+type Common struct {
+	network.Network `json:",inline"`
+
+	Region string `json:"region"`
+}
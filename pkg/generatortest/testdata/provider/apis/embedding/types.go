@@ -0,0 +1,66 @@
+// Package embedding contains a managed resource whose parameters embed a
+// struct through two routes: directly, and through a struct of another package
+// that embeds it too.
+package embedding
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"example.org/provider/apis/embedding/network"
+	"example.org/provider/apis/embedding/shared"
+)
+
+// WidgetParameters are the configurable fields of a Widget. SubnetID is
+// promoted from Network, which shadows Common.Network.
+type WidgetParameters struct {
+	network.Network `json:",inline"`
+	shared.Common   `json:",inline"`
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       WidgetParameters `json:"forProvider"`
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+}
+
+// A Widget is a managed resource that references a Gizmo.
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+// A GizmoSpec defines the desired state of a Gizmo.
+type GizmoSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+}
+
+// A GizmoStatus represents the observed state of a Gizmo.
+type GizmoStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+}
+
+// A Gizmo is a managed resource.
+type Gizmo struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GizmoSpec   `json:"spec"`
+	Status GizmoStatus `json:"status,omitempty"`
+}
+
+// A GizmoList is a list of Gizmos.
+type GizmoList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Gizmo `json:"items"`
+}
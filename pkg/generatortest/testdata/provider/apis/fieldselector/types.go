@@ -0,0 +1,73 @@
+// Package fieldselector contains a managed resource whose references select
+// resources that match a field selector.
+package fieldselector
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	// +crossplane:generate:reference:type=Gizmo
+	// +crossplane:generate:reference:fieldSelector=spec.forProvider.region=us-east-1
+	GizmoID *string
+
+	GizmoIDRef      *xpv1.Reference
+	GizmoIDSelector *xpv1.Selector
+
+	// +crossplane:generate:reference:type=Gizmo
+	// +crossplane:generate:reference:fieldSelector=status.atProvider.state!=deleting
+	GizmoIDs []string
+
+	GizmoIDsRefs     []xpv1.Reference
+	GizmoIDsSelector *xpv1.Selector
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec
+	ForProvider WidgetParameters
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Widget is a managed resource that selects the Gizmos it references by
+// their fields as well as their labels.
+type Widget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   WidgetSpec
+	Status WidgetStatus
+}
+
+// A GizmoSpec defines the desired state of a Gizmo.
+type GizmoSpec struct {
+	xpv1.ResourceSpec
+}
+
+// A GizmoStatus represents the observed state of a Gizmo.
+type GizmoStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Gizmo is a managed resource.
+type Gizmo struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   GizmoSpec
+	Status GizmoStatus
+}
+
+// A GizmoList is a list of Gizmos.
+type GizmoList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []Gizmo
+}
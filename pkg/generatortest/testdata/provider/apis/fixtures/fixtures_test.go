@@ -0,0 +1,34 @@
+package fixtures_test
+
+import (
+	"testing"
+
+	"example.org/provider/apis/fixtures"
+)
+
+// TestNewWidgetFixture is run against fixtures that the tests of package
+// angryjet generate to package fixtures.
+func TestNewWidgetFixture(t *testing.T) {
+	mg := fixtures.NewWidgetFixture()
+	p := mg.Spec.ForProvider
+	if p.Region == nil {
+		t.Error("NewWidgetFixture(): want required region set, got nil")
+	}
+	if p.Mode != "Fast" {
+		t.Errorf("NewWidgetFixture(): want mode Fast, the first of its enum, got %q", p.Mode)
+	}
+	if p.Replicas != 1 {
+		t.Errorf("NewWidgetFixture(): want 1 replica, its minimum, got %d", p.Replicas)
+	}
+	if len(p.Rules) != 1 || p.Rules[0].Name == "" {
+		t.Errorf("NewWidgetFixture(): want one rule with a name, got %v", p.Rules)
+	}
+	if p.Description != nil {
+		t.Errorf("NewWidgetFixture(): want optional description unset, got %q", *p.Description)
+	}
+
+	mode := fixtures.Mode("Slow")
+	if got := fixtures.NewWidgetFixture(fixtures.WithWidgetMode(mode)).Spec.ForProvider.Mode; got != mode {
+		t.Errorf("NewWidgetFixture(WithWidgetMode(...)): want mode %q, got %q", mode, got)
+	}
+}
@@ -0,0 +1,56 @@
+// Package fixturesexternal contains a managed resource whose generated
+// fixtures its external test package executes.
+package fixturesexternal
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A Mode of a Widget.
+// +kubebuilder:validation:Enum=Fast;Slow
+type Mode string
+
+// A Rule of a Widget.
+type Rule struct {
+	// +kubebuilder:validation:Required
+	Name string `json:"name,omitempty"`
+
+	Port int `json:"port,omitempty"`
+}
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	// +kubebuilder:validation:Required
+	Region *string `json:"region,omitempty"`
+
+	Mode Mode `json:"mode"`
+
+	// +kubebuilder:validation:Minimum=1
+	Replicas int32 `json:"replicas"`
+
+	Rules []Rule `json:"rules"`
+
+	Description *string `json:"description,omitempty"`
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       WidgetParameters `json:"forProvider"`
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+}
+
+// A Widget is a managed resource.
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
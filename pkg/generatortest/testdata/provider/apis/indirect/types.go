@@ -0,0 +1,66 @@
+// Package indirect contains managed resources whose reference fields hold
+// their references other than as the reference package takes them.
+package indirect
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A RuleGroup targets other Widgets.
+type RuleGroup struct {
+	// +crossplane:generate:reference:type=Widget
+	TargetID *string `json:"targetId,omitempty"`
+
+	TargetIDRef xpv1.Reference `json:"targetIdRef,omitempty"`
+
+	TargetIDSelector *xpv1.Selector `json:"targetIdSelector,omitempty"`
+
+	// +crossplane:generate:reference:type=Widget
+	TargetName string `json:"targetName,omitempty"`
+
+	TargetNameRef xpv1.Reference `json:"targetNameRef,omitempty"`
+
+	TargetNameSelector *xpv1.Selector `json:"targetNameSelector,omitempty"`
+
+	// +crossplane:generate:reference:type=Widget
+	TargetIDs []*string `json:"targetIds,omitempty"`
+
+	TargetIDsRefs []*xpv1.Reference `json:"targetIdsRefs,omitempty"`
+
+	TargetIDsSelector *xpv1.Selector `json:"targetIdsSelector,omitempty"`
+}
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	RuleGroups []RuleGroup `json:"ruleGroups,omitempty"`
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       WidgetParameters `json:"forProvider"`
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+}
+
+// A Widget is a managed resource whose rule groups may reference other
+// Widgets.
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+// A WidgetList is a list of Widgets.
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Widget `json:"items"`
+}
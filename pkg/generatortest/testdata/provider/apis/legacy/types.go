@@ -0,0 +1,28 @@
+// Package legacy contains parameters whose references use legacy spellings of
+// reference markers.
+package legacy
+
+import (
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ArchiveParameters are the configurable fields of an archive.
+type ArchiveParameters struct {
+	// BucketName is the name of the bucket the archive is written to.
+	// Ref: example.org/provider/apis/v1alpha1.Bucket
+	BucketName *string
+
+	BucketNameRef      *xpv1.Reference
+	BucketNameSelector *xpv1.Selector
+
+	// KeyID is the ID of the key the archive is encrypted with.
+	// +crossplane:reference:type=example.org/provider/apis/v1alpha1.Key
+	// +crossplane:generate:ref:extractor=github.com/crossplane/crossplane-runtime/pkg/reference.ExternalName()
+	KeyID string
+
+	KeyIDRef      *xpv1.Reference
+	KeyIDSelector *xpv1.Selector
+
+	// Retention is not a reference. Ref: counts are not markers.
+	Retention int
+}
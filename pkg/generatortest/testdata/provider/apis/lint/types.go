@@ -0,0 +1,85 @@
+// Package lint contains a managed resource whose references resolve to kinds
+// with a missing or malformed list type.
+package lint
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"example.org/provider/apis/v1alpha1"
+)
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	// +crossplane:generate:reference:type=Gizmo
+	GizmoID string
+
+	GizmoIDRef      *xpv1.Reference
+	GizmoIDSelector *xpv1.Selector
+
+	// +crossplane:generate:reference:type=Gadget
+	// +crossplane:generate:reference:listType=Gadgets
+	GadgetID string
+
+	GadgetIDRef      *xpv1.Reference
+	GadgetIDSelector *xpv1.Selector
+
+	// +crossplane:generate:reference:type=example.org/provider/apis/v1alpha1.Key
+	KeyID string
+
+	KeyIDRef      *xpv1.Reference
+	KeyIDSelector *xpv1.Selector
+
+	// +crossplane:generate:reference:type=Doohickey
+	DoohickeyID string
+
+	DoohickeyIDRef      *xpv1.Reference
+	DoohickeyIDSelector *xpv1.Selector
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec
+	ForProvider WidgetParameters
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Widget is a managed resource.
+type Widget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   WidgetSpec
+	Status WidgetStatus
+}
+
+// A Gizmo is referenced by a Widget.
+type Gizmo struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+}
+
+// GizmoList contains a list of Key, not Gizmo.
+type GizmoList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []v1alpha1.Key
+}
+
+// A Gadget is referenced by a Widget.
+type Gadget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+}
+
+// Gadgets contains a list of Gadget.
+type Gadgets struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []Gadget
+}
@@ -0,0 +1,61 @@
+// Package locks contains managed resources with a lock in their status, which
+// generated methods must not copy.
+package locks
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	// +crossplane:generate:reference:type=Widget
+	ParentID *string `json:"parentId,omitempty"`
+
+	ParentIDRef *xpv1.Reference `json:"parentIdRef,omitempty"`
+
+	ParentIDSelector *xpv1.Selector `json:"parentIdSelector,omitempty"`
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       WidgetParameters `json:"forProvider"`
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+
+	mu mutex
+}
+
+// A mutex is a lock, as far as go vet is concerned. The provider module cannot
+// load the sync package, whose types do not check with the sizes that
+// packages.Load uses.
+type mutex struct {
+	locked bool
+}
+
+// Lock the mutex.
+func (m *mutex) Lock() { m.locked = true }
+
+// Unlock the mutex.
+func (m *mutex) Unlock() { m.locked = false }
+
+// A Widget is a managed resource that may reference another Widget.
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+// A WidgetList is a list of Widgets.
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Widget `json:"items"`
+}
@@ -0,0 +1,59 @@
+package loops
+
+import (
+	"context"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reference"
+)
+
+// TestResolveReferences is run against generated resolvers by the tests of
+// package angryjet.
+func TestResolveReferences(t *testing.T) {
+	mg := &Widget{Spec: WidgetSpec{ForProvider: WidgetParameters{Rules: []Rule{
+		{
+			TargetsRefs: []xpv1.Reference{{Name: "a"}, {Name: "b"}},
+		},
+		{
+			Targets:     []Target{{ZoneIDRef: &xpv1.Reference{Name: "z"}}},
+			TargetsRefs: []xpv1.Reference{{Name: "c"}},
+		},
+	}}}}
+
+	reference.Resolutions = 0
+	if err := mg.ResolveReferences(context.Background(), nil); err != nil {
+		t.Fatalf("ResolveReferences(...): %v", err)
+	}
+
+	// The targets of each rule are resolved once, and then the zone of each
+	// of the three targets, including those appended by resolving targets.
+	if reference.Resolutions != 5 {
+		t.Errorf("ResolveReferences(...): resolved %d times, want 5", reference.Resolutions)
+	}
+
+	want := [][]string{{"a/", "b/"}, {"c/z"}}
+	for i, r := range mg.Spec.ForProvider.Rules {
+		got := make([]string, 0, len(r.Targets))
+		for _, tg := range r.Targets {
+			got = append(got, value(tg.GizmoID)+"/"+value(tg.ZoneID))
+		}
+		if len(got) != len(want[i]) {
+			t.Errorf("ResolveReferences(...): rule %d has targets %v, want %v", i, got, want[i])
+			continue
+		}
+		for j := range got {
+			if got[j] != want[i][j] {
+				t.Errorf("ResolveReferences(...): rule %d has targets %v, want %v", i, got, want[i])
+				break
+			}
+		}
+	}
+}
+
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,81 @@
+// Package loops contains a managed resource whose generated reference resolver
+// grows slices that it resolves the elements of, which its test executes.
+package loops
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A Target of a Rule.
+type Target struct {
+	GizmoID *string
+
+	// +crossplane:generate:reference:type=Gizmo
+	ZoneID *string
+
+	ZoneIDRef      *xpv1.Reference
+	ZoneIDSelector *xpv1.Selector
+}
+
+// A Rule of a Widget.
+type Rule struct {
+	// +crossplane:generate:reference:type=Gizmo
+	// +crossplane:generate:reference:spreadInto=GizmoID
+	Targets []Target
+
+	TargetsRefs     []xpv1.Reference
+	TargetsSelector *xpv1.Selector
+}
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	Rules []Rule
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec
+	ForProvider WidgetParameters
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Widget is a managed resource.
+type Widget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   WidgetSpec
+	Status WidgetStatus
+}
+
+// A GizmoSpec defines the desired state of a Gizmo.
+type GizmoSpec struct {
+	xpv1.ResourceSpec
+}
+
+// A GizmoStatus represents the observed state of a Gizmo.
+type GizmoStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Gizmo is a managed resource.
+type Gizmo struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   GizmoSpec
+	Status GizmoStatus
+}
+
+// GizmoList contains a list of Gizmo.
+type GizmoList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []Gizmo
+}
@@ -0,0 +1,85 @@
+// Package marked contains a managed resource whose references are marked in
+// its source, like those of package described.
+//
+// +groupName=example.org
+package marked
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A Rule of a Widget.
+type Rule struct {
+	// +crossplane:generate:reference:type=Gizmo
+	GizmoID *string
+
+	GizmoIDRef      *xpv1.Reference
+	GizmoIDSelector *xpv1.Selector
+}
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	// +crossplane:generate:reference:type=Gizmo
+	// +crossplane:generate:reference:extractor=github.com/crossplane/crossplane-runtime/pkg/reference.ExternalName()
+	GizmoID string
+
+	GizmoIDRef      *xpv1.Reference
+	GizmoIDSelector *xpv1.Selector
+
+	// +crossplane:generate:reference:type=Gadget
+	// +crossplane:generate:reference:listType=Gadgets
+	GadgetIDs []string
+
+	GadgetIDsRefs     []xpv1.Reference
+	GadgetIDsSelector *xpv1.Selector
+
+	Rules []Rule
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec
+	ForProvider WidgetParameters
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Widget is a managed resource.
+type Widget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   WidgetSpec
+	Status WidgetStatus
+}
+
+// A Gizmo is referenced by a Widget.
+type Gizmo struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+}
+
+// GizmoList contains a list of Gizmo.
+type GizmoList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []Gizmo
+}
+
+// A Gadget is referenced by a Widget.
+type Gadget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+}
+
+// Gadgets contains a list of Gadget.
+type Gadgets struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []Gadget
+}
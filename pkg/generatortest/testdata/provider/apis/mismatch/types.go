@@ -0,0 +1,34 @@
+// Package mismatch contains a managed resource that does not satisfy the
+// crossplane-runtime interfaces once methods are generated.
+package mismatch
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Widget is a managed resource whose hand written GetCondition method
+// prevents the correct one from being generated.
+type Widget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   WidgetSpec
+	Status WidgetStatus
+}
+
+// GetCondition returns the first condition of the Widget.
+func (mg *Widget) GetCondition() xpv1.Condition {
+	return xpv1.Condition{}
+}
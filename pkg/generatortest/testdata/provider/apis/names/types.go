@@ -0,0 +1,103 @@
+// Package names contains a managed resource whose fields, and the extractors of
+// their references, are named like the variables of its generated resolver.
+package names
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// R are the parameters of a Widget that are named R.
+type R struct {
+	// +crossplane:generate:reference:type=Gizmo
+	// +crossplane:generate:reference:extractor=rsp()
+	Err *string
+
+	ErrRef *xpv1.Reference
+
+	ErrSelector *xpv1.Selector
+}
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	// +crossplane:generate:reference:type=Gizmo
+	// +crossplane:generate:reference:extractor=hash()
+	Err *string
+
+	ErrRef *xpv1.Reference
+
+	ErrSelector *xpv1.Selector
+
+	// +crossplane:generate:reference:type=Gizmo
+	// +crossplane:generate:reference:extractor=mrsp()
+	Rsp []string
+
+	RspRefs []xpv1.Reference
+
+	RspSelector *xpv1.Selector
+
+	R *R
+}
+
+// rsp extracts the deletion policy of a Gizmo.
+func rsp() func(resource.Managed) string {
+	return func(mg resource.Managed) string { return string(mg.GetDeletionPolicy()) }
+}
+
+// hash extracts the deletion policy of a Gizmo.
+func hash() func(resource.Managed) string {
+	return func(mg resource.Managed) string { return string(mg.GetDeletionPolicy()) }
+}
+
+// mrsp extracts the deletion policy of a Gizmo.
+func mrsp() func(resource.Managed) string {
+	return func(mg resource.Managed) string { return string(mg.GetDeletionPolicy()) }
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec
+	ForProvider WidgetParameters
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Widget is a managed resource that references Gizmos.
+type Widget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   WidgetSpec
+	Status WidgetStatus
+}
+
+// A GizmoSpec defines the desired state of a Gizmo.
+type GizmoSpec struct {
+	xpv1.ResourceSpec
+}
+
+// A GizmoStatus represents the observed state of a Gizmo.
+type GizmoStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Gizmo is a managed resource.
+type Gizmo struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   GizmoSpec
+	Status GizmoStatus
+}
+
+// A GizmoList is a list of Gizmos.
+type GizmoList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []Gizmo
+}
@@ -0,0 +1,58 @@
+// Package nolist contains a managed resource whose generated reference
+// resolver does not compile.
+package nolist
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// GadgetParameters are the configurable fields of a Gadget.
+type GadgetParameters struct {
+	// +crossplane:generate:reference:type=Gizmo
+	GizmoID string
+
+	GizmoIDRef      *xpv1.Reference
+	GizmoIDSelector *xpv1.Selector
+}
+
+// A GadgetSpec defines the desired state of a Gadget.
+type GadgetSpec struct {
+	xpv1.ResourceSpec
+	ForProvider GadgetParameters
+}
+
+// A GadgetStatus represents the observed state of a Gadget.
+type GadgetStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Gadget is a managed resource that references a Gizmo, which has no list
+// type.
+type Gadget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   GadgetSpec
+	Status GadgetStatus
+}
+
+// A GizmoSpec defines the desired state of a Gizmo.
+type GizmoSpec struct {
+	xpv1.ResourceSpec
+}
+
+// A GizmoStatus represents the observed state of a Gizmo.
+type GizmoStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Gizmo is a managed resource.
+type Gizmo struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   GizmoSpec
+	Status GizmoStatus
+}
@@ -0,0 +1,48 @@
+package referenceerror
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reference"
+)
+
+// TestResolveReferences is run against generated resolvers by the tests of
+// package angryjet, which generate them to return a ReferenceError.
+func TestResolveReferences(t *testing.T) {
+	cases := map[string]struct {
+		mg   *Widget
+		want ReferenceError
+	}{
+		"Single": {
+			mg: &Widget{Spec: WidgetSpec{ForProvider: WidgetParameters{
+				GizmoIDRef: &xpv1.Reference{Name: reference.Missing},
+			}}},
+			want: ReferenceError{FieldPath: "mg.Spec.ForProvider.GizmoID", TargetKind: "Gizmo"},
+		},
+		"Multiple": {
+			mg: &Widget{Spec: WidgetSpec{ForProvider: WidgetParameters{
+				ZoneIDsRefs: []xpv1.Reference{{Name: "a"}, {Name: reference.Missing}},
+			}}},
+			want: ReferenceError{FieldPath: "mg.Spec.ForProvider.ZoneIDs", TargetKind: "Gizmo"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.mg.ResolveReferences(context.Background(), nil)
+			var re *ReferenceError
+			if !errors.As(err, &re) {
+				t.Fatalf("ResolveReferences(...): want a ReferenceError, got %v", err)
+			}
+			if re.FieldPath != tc.want.FieldPath || re.TargetKind != tc.want.TargetKind {
+				t.Errorf("ResolveReferences(...): want field %s of kind %s, got field %s of kind %s", tc.want.FieldPath, tc.want.TargetKind, re.FieldPath, re.TargetKind)
+			}
+			if errors.Unwrap(err) == nil {
+				t.Errorf("ResolveReferences(...): want a ReferenceError that unwraps to the error it was returned with")
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package retried
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reference"
+
+	"example.org/provider/retry"
+)
+
+// TestResolveReferences is run against generated resolvers by the tests of
+// package angryjet, which generate them to try each resolution three times.
+func TestResolveReferences(t *testing.T) {
+	mg := &Widget{Spec: WidgetSpec{ForProvider: WidgetParameters{
+		GizmoIDRef: &xpv1.Reference{Name: reference.Missing},
+	}}}
+
+	// A reference that cannot be resolved is tried until retries are
+	// exhausted, and the error still identifies its field.
+	retry.Tries = 0
+	err := mg.ResolveReferences(context.Background(), nil)
+	if err == nil {
+		t.Fatal("ResolveReferences(...): want error resolving a missing reference, got nil")
+	}
+	if !strings.Contains(err.Error(), "GizmoID") {
+		t.Errorf("ResolveReferences(...): want error identifying field GizmoID, got %q", err)
+	}
+	if retry.Tries != 3 {
+		t.Errorf("ResolveReferences(...): want 3 tries, got %d", retry.Tries)
+	}
+
+	// A reference that can be resolved is tried once.
+	retry.Tries = 0
+	mg.Spec.ForProvider.GizmoIDRef.Name = "a"
+	if err := mg.ResolveReferences(context.Background(), nil); err != nil {
+		t.Fatalf("ResolveReferences(...): %v", err)
+	}
+	if retry.Tries != 1 {
+		t.Errorf("ResolveReferences(...): want 1 try, got %d", retry.Tries)
+	}
+	if mg.Spec.ForProvider.GizmoID == nil || *mg.Spec.ForProvider.GizmoID != "a" {
+		t.Errorf("ResolveReferences(...): want gizmo a, got %v", mg.Spec.ForProvider.GizmoID)
+	}
+}
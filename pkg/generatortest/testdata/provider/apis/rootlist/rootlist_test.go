@@ -0,0 +1,32 @@
+package rootlist
+
+import (
+	"context"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+var _ resource.ManagedList = &GizmoList{}
+
+// TestResolveReferences is run against generated methods by the tests of
+// package angryjet, which must generate GetItems for GizmoList so that Widget's
+// resolvers can list Gizmos.
+func TestResolveReferences(t *testing.T) {
+	mg := &Widget{Spec: WidgetSpec{ForProvider: WidgetParameters{
+		GizmoIDRef: &xpv1.Reference{Name: "a"},
+	}}}
+	if err := mg.ResolveReferences(context.Background(), nil); err != nil {
+		t.Fatalf("ResolveReferences(...): %v", err)
+	}
+	if mg.Spec.ForProvider.GizmoID == nil || *mg.Spec.ForProvider.GizmoID != "a" {
+		t.Errorf("ResolveReferences(...): want gizmo a, got %v", mg.Spec.ForProvider.GizmoID)
+	}
+
+	l := &GizmoList{Items: []Gizmo{{}, {}}}
+	items := l.GetItems()
+	if len(items) != 2 || items[1] != &l.Items[1] {
+		t.Errorf("GetItems(): want the addresses of both items, got %v", items)
+	}
+}
@@ -0,0 +1,79 @@
+// Package suppressed contains a managed resource whose references have
+// problems, some of which are suppressed.
+// +crossplane:generate:suppress=list-items,everything
+package suppressed
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	// +crossplane:generate:reference:type=Gizmo
+	GizmoID string
+
+	GizmoIDRef      *xpv1.Reference
+	GizmoIDSelector *xpv1.Selector
+
+	// +crossplane:generate:reference:type=Gadget
+	GadgetID string
+
+	GadgetIDRef      *xpv1.Reference
+	GadgetIDSelector *xpv1.Selector
+
+	// +crossplane:generate:reference:type=Doohickey
+	// +crossplane:generate:suppress=missing-type
+	DoohickeyID string
+
+	DoohickeyIDRef      *xpv1.Reference
+	DoohickeyIDSelector *xpv1.Selector
+
+	// +crossplane:generate:reference:type=Whatsit
+	// +crossplane:generate:suppress=missing-list-type
+	WhatsitID string
+
+	WhatsitIDRef      *xpv1.Reference
+	WhatsitIDSelector *xpv1.Selector
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec
+	ForProvider WidgetParameters
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Widget is a managed resource.
+// +crossplane:generate:suppress=missing-list-type, list_items
+type Widget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   WidgetSpec
+	Status WidgetStatus
+}
+
+// A Gizmo is referenced by a Widget.
+type Gizmo struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+}
+
+// GizmoList contains a list of Gadget, not Gizmo.
+type GizmoList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []Gadget
+}
+
+// A Gadget is referenced by a Widget, but has no list type.
+type Gadget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+}
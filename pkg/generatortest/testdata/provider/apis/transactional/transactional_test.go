@@ -0,0 +1,46 @@
+package transactional
+
+import (
+	"context"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reference"
+)
+
+// TestResolveReferences is run against generated resolvers by the tests of
+// package angryjet.
+func TestResolveReferences(t *testing.T) {
+	mg := &Widget{Spec: WidgetSpec{ForProvider: WidgetParameters{
+		GizmoIDRef:  &xpv1.Reference{Name: "a"},
+		ZoneIDsRefs: []xpv1.Reference{{Name: "b"}, {Name: reference.Missing}},
+	}}}
+
+	// The gizmo is resolved before the zones, one of which is missing, so
+	// nothing is written.
+	if err := mg.ResolveReferences(context.Background(), nil); err == nil {
+		t.Fatal("ResolveReferences(...): want error resolving a missing reference, got nil")
+	}
+	if mg.Spec.ForProvider.GizmoID != nil || mg.Spec.ForProvider.ZoneIDs != nil {
+		t.Errorf("ResolveReferences(...): want no resolved fields after an error, got gizmo %q and zones %v", value(mg.Spec.ForProvider.GizmoID), mg.Spec.ForProvider.ZoneIDs)
+	}
+
+	// Once the missing reference is fixed every field is written.
+	mg.Spec.ForProvider.ZoneIDsRefs[1].Name = "c"
+	if err := mg.ResolveReferences(context.Background(), nil); err != nil {
+		t.Fatalf("ResolveReferences(...): %v", err)
+	}
+	if got := value(mg.Spec.ForProvider.GizmoID); got != "a" {
+		t.Errorf("ResolveReferences(...): want gizmo a, got %q", got)
+	}
+	if got := mg.Spec.ForProvider.ZoneIDs; len(got) != 2 || got[0] != "b" || got[1] != "c" {
+		t.Errorf("ResolveReferences(...): want zones [b c], got %v", got)
+	}
+}
+
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
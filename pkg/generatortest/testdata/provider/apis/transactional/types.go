@@ -0,0 +1,88 @@
+// Package transactional contains a managed resource whose generated reference
+// resolver resolves its references transactionally, which its test executes.
+package transactional
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	// +crossplane:generate:reference:type=Gizmo
+	GizmoID *string
+
+	GizmoIDRef      *xpv1.Reference
+	GizmoIDSelector *xpv1.Selector
+
+	// +crossplane:generate:reference:type=Gizmo
+	ZoneIDs []string
+
+	ZoneIDsRefs     []xpv1.Reference
+	ZoneIDsSelector *xpv1.Selector
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec
+	ForProvider WidgetParameters
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Widget is a managed resource.
+type Widget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   WidgetSpec
+	Status WidgetStatus
+}
+
+// DeepCopy returns a deep copy of the Widget's references and the fields they
+// resolve, which is all that its generated resolver changes.
+func (in *Widget) DeepCopy() *Widget {
+	out := *in
+	p := &out.Spec.ForProvider
+	if in.Spec.ForProvider.GizmoID != nil {
+		id := *in.Spec.ForProvider.GizmoID
+		p.GizmoID = &id
+	}
+	if in.Spec.ForProvider.GizmoIDRef != nil {
+		ref := *in.Spec.ForProvider.GizmoIDRef
+		p.GizmoIDRef = &ref
+	}
+	p.ZoneIDs = append([]string(nil), in.Spec.ForProvider.ZoneIDs...)
+	p.ZoneIDsRefs = append([]xpv1.Reference(nil), in.Spec.ForProvider.ZoneIDsRefs...)
+	return &out
+}
+
+// A GizmoSpec defines the desired state of a Gizmo.
+type GizmoSpec struct {
+	xpv1.ResourceSpec
+}
+
+// A GizmoStatus represents the observed state of a Gizmo.
+type GizmoStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Gizmo is a managed resource.
+type Gizmo struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   GizmoSpec
+	Status GizmoStatus
+}
+
+// GizmoList contains a list of Gizmo.
+type GizmoList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []Gizmo
+}
@@ -0,0 +1,63 @@
+// Package unexported contains managed resources whose types are not exported.
+package unexported
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// widgetParameters are the configurable fields of a widget.
+type widgetParameters struct {
+	// +crossplane:generate:reference:type=gizmo
+	GizmoID string
+
+	GizmoIDRef      *xpv1.Reference
+	GizmoIDSelector *xpv1.Selector
+}
+
+// A widgetSpec defines the desired state of a widget.
+type widgetSpec struct {
+	xpv1.ResourceSpec
+	ForProvider widgetParameters
+}
+
+// A widgetStatus represents the observed state of a widget.
+type widgetStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A widget is an unexported managed resource that references another.
+type widget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   widgetSpec
+	Status widgetStatus
+}
+
+// A gizmoSpec defines the desired state of a gizmo.
+type gizmoSpec struct {
+	xpv1.ResourceSpec
+}
+
+// A gizmoStatus represents the observed state of a gizmo.
+type gizmoStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A gizmo is an unexported managed resource.
+type gizmo struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   gizmoSpec
+	Status gizmoStatus
+}
+
+// A gizmoList is a list of gizmos.
+type gizmoList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []gizmo
+}
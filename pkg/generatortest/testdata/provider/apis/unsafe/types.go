@@ -0,0 +1,55 @@
+// Package unsafe contains managed resources with fields of unsafe types, like
+// the internal state of protobuf generated structs.
+package unsafe
+
+import (
+	"unsafe"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	// +crossplane:generate:reference:type=Widget
+	ParentID *string `json:"parentId,omitempty"`
+
+	ParentIDRef *xpv1.Reference `json:"parentIdRef,omitempty"`
+
+	ParentIDSelector *xpv1.Selector `json:"parentIdSelector,omitempty"`
+
+	// State is opaque.
+	State unsafe.Pointer `json:"-"`
+
+	// Handle is opaque too, so its marker is ignored, with a warning.
+	// +crossplane:generate:reference:type=Widget
+	Handle uintptr `json:"-"`
+}
+
+// A WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       WidgetParameters `json:"forProvider"`
+}
+
+// A WidgetStatus represents the observed state of a Widget.
+type WidgetStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+}
+
+// A Widget is a managed resource that may reference another Widget.
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+// A WidgetList is a list of Widgets.
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Widget `json:"items"`
+}
@@ -0,0 +1,115 @@
+// Package v1alpha1 contains API types for which generated methods compile and
+// satisfy the crossplane-runtime interfaces.
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// BucketParameters are the configurable fields of a Bucket.
+type BucketParameters struct {
+	// +crossplane:generate:reference:type=Key
+	// +crossplane:generate:reference:immutable
+	KeyID *string
+
+	KeyIDRef      *xpv1.Reference
+	KeyIDSelector *xpv1.Selector
+
+	// +crossplane:generate:reference:type=Key
+	// +crossplane:generate:reference:timeout=30s
+	PolicyIDs []string
+
+	PolicyIDsRefs     []xpv1.Reference
+	PolicyIDsSelector *xpv1.Selector
+
+	// +crossplane:generate:reference:type=Key
+	// +crossplane:generate:reference:fromAnnotation=example.org/key-arn
+	KeyARN *string
+
+	KeyARNRef      *xpv1.Reference
+	KeyARNSelector *xpv1.Selector
+}
+
+// A BucketSpec defines the desired state of a Bucket.
+type BucketSpec struct {
+	xpv1.ResourceSpec
+	ForProvider BucketParameters
+}
+
+// A BucketStatus represents the observed state of a Bucket.
+type BucketStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Bucket is a managed resource.
+type Bucket struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   BucketSpec
+	Status BucketStatus
+}
+
+// BucketList contains a list of Bucket.
+type BucketList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []Bucket
+}
+
+// A KeySpec defines the desired state of a Key.
+type KeySpec struct {
+	xpv1.ResourceSpec
+}
+
+// A KeyStatus represents the observed state of a Key.
+type KeyStatus struct {
+	xpv1.ResourceStatus
+}
+
+// A Key is a managed resource.
+type Key struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   KeySpec
+	Status KeyStatus
+}
+
+// KeyList contains a list of Key.
+type KeyList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []Key
+}
+
+// A ProviderConfigStatus represents the status of a ProviderConfig.
+type ProviderConfigStatus struct {
+	xpv1.ProviderConfigStatus
+}
+
+// A ProviderConfig configures the provider.
+type ProviderConfig struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   xpv1.ProviderConfigSpec
+	Status ProviderConfigStatus
+}
+
+// A ProviderConfigUsage indicates that a resource is using a ProviderConfig.
+type ProviderConfigUsage struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	xpv1.ProviderConfigUsage
+}
+
+// ProviderConfigUsageList contains a list of ProviderConfigUsage.
+type ProviderConfigUsageList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []ProviderConfigUsage
+}
@@ -0,0 +1,17 @@
+// Package clock tells the time to generated reference resolvers, so that tests
+// may replace it.
+package clock
+
+import "time"
+
+// An Interface tells the time.
+type Interface interface {
+	Now() time.Time
+}
+
+type system struct{}
+
+func (system) Now() time.Time { return time.Now() }
+
+// Clock tells generated reference resolvers the time.
+var Clock Interface = system{}
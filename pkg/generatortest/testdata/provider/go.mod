@@ -0,0 +1,19 @@
+module example.org/provider
+
+go 1.18
+
+require (
+	github.com/crossplane/crossplane-runtime v0.0.0
+	github.com/pkg/errors v0.0.0
+	k8s.io/api v0.0.0
+	k8s.io/apimachinery v0.0.0
+	sigs.k8s.io/controller-runtime v0.0.0
+)
+
+replace (
+	github.com/crossplane/crossplane-runtime => ../runtime
+	github.com/pkg/errors => ../errors
+	k8s.io/api => ../api
+	k8s.io/apimachinery => ../apimachinery
+	sigs.k8s.io/controller-runtime => ../controller-runtime
+)
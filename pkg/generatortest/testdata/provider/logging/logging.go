@@ -0,0 +1,24 @@
+// Package logging contains a shim that gets a logger from a context.
+package logging
+
+import "context"
+
+// A Logger logs debug messages.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+}
+
+type nop struct{}
+
+func (nop) Debug(string, ...interface{}) {}
+
+type key struct{}
+
+// FromContext returns the logger of the supplied context, or a logger that
+// discards its messages if it has none.
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(key{}).(Logger); ok {
+		return l
+	}
+	return nop{}
+}
@@ -0,0 +1,45 @@
+// Package provenance contains a shim that records where the values of resolved
+// fields came from in annotations of a managed resource.
+package provenance
+
+import (
+	"context"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// AnnotationKeyPrefix prefixes the annotation that records the provenance of a
+// field.
+const AnnotationKeyPrefix = "references.example.org/"
+
+// Record the provenance of the supplied field, or clear it if the field was not
+// resolved from a reference.
+func Record(ctx context.Context, c client.Reader, mg resource.Managed, key string, to resource.Managed, namespace string, ref *xpv1.Reference) error {
+	if ref == nil {
+		return RecordMultiple(ctx, c, mg, key, to, namespace, nil)
+	}
+	return RecordMultiple(ctx, c, mg, key, to, namespace, []xpv1.Reference{*ref})
+}
+
+// RecordMultiple records the provenance of the supplied field, or clears it if
+// the field was not resolved from references.
+func RecordMultiple(_ context.Context, _ client.Reader, mg resource.Managed, key string, _ resource.Managed, namespace string, refs []xpv1.Reference) error {
+	a := mg.GetAnnotations()
+	if a == nil {
+		return nil
+	}
+	if len(refs) == 0 {
+		delete(a, AnnotationKeyPrefix+key)
+		return nil
+	}
+	names := make([]string, len(refs))
+	for i, r := range refs {
+		names[i] = namespace + "/" + r.Name
+	}
+	a[AnnotationKeyPrefix+key] = strings.Join(names, ",")
+	return nil
+}
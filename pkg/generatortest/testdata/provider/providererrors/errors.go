@@ -0,0 +1,22 @@
+// Package providererrors contains the error types of the provider.
+package providererrors
+
+// A ReferenceError is returned when a reference of a field can't be resolved.
+type ReferenceError struct {
+	Field string
+	Err   error
+}
+
+func (e *ReferenceError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error that caused the reference not to be resolved.
+func (e *ReferenceError) Unwrap() error {
+	return e.Err
+}
+
+// Reference returns a ReferenceError of the supplied field.
+func Reference(err error, field string) error {
+	return &ReferenceError{Field: field, Err: err}
+}
@@ -0,0 +1,43 @@
+// Package retry contains a shim that retries functions while they fail.
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// A Backoff configures how a function is retried.
+type Backoff struct {
+	Duration time.Duration
+	Factor   float64
+	Steps    int
+}
+
+// DefaultBackoff tries a function three times.
+var DefaultBackoff = Backoff{Duration: time.Millisecond, Factor: 2, Steps: 3}
+
+// Tries counts the calls of functions by OnError, so that tests may tell how
+// many times a function was tried.
+var Tries int
+
+// OnError calls the supplied function until it returns nil, at most the
+// supplied number of steps, and returns the last error it returned.
+func OnError(ctx context.Context, b Backoff, fn func() error) error {
+	var err error
+	d := b.Duration
+	for i := 0; i < b.Steps; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(d):
+			}
+			d = time.Duration(float64(d) * b.Factor)
+		}
+		Tries++
+		if err = fn(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,18 @@
+// Package tenancy contains functions that get the tenant of a context.
+package tenancy
+
+import "context"
+
+type key struct{}
+
+// Namespace returns the namespace of the tenant of the supplied context.
+func Namespace(ctx context.Context) string {
+	ns, _ := ctx.Value(key{}).(string)
+	return ns
+}
+
+// NamespaceOrError returns the namespace of the tenant of the supplied
+// context, or an error if it has none.
+func NamespaceOrError(ctx context.Context) (string, error) {
+	return Namespace(ctx), nil
+}
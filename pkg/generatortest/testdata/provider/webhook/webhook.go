@@ -0,0 +1,77 @@
+// Package webhook contains a resolver that resolves references with an
+// external service rather than the Kubernetes API.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reference"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Endpoint is the URL of the service that resolves references.
+var Endpoint = "http://resolver.example.org"
+
+// A Resolver posts resolution requests to the service at Endpoint.
+type Resolver struct {
+	from resource.Managed
+}
+
+// NewResolver returns a Resolver of the references of the supplied managed
+// resource. It does not use the supplied client.
+func NewResolver(_ client.Reader, from resource.Managed) *Resolver {
+	return &Resolver{from: from}
+}
+
+// Resolve the supplied ResolutionRequest.
+func (r *Resolver) Resolve(ctx context.Context, req reference.ResolutionRequest) (reference.ResolutionResponse, error) {
+	rsp := reference.ResolutionResponse{}
+	err := r.post(ctx, "/resolve", req, &rsp)
+	return rsp, err
+}
+
+// ResolveMultiple resolves the supplied MultiResolutionRequest.
+func (r *Resolver) ResolveMultiple(ctx context.Context, req reference.MultiResolutionRequest) (reference.MultiResolutionResponse, error) {
+	rsp := reference.MultiResolutionResponse{}
+	err := r.post(ctx, "/resolve-multiple", req, &rsp)
+	return rsp, err
+}
+
+func (r *Resolver) post(ctx context.Context, path string, req, rsp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return errors.Wrap(err, "cannot marshal resolution request")
+	}
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, Endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "cannot create resolution request")
+	}
+	hrsp, err := http.DefaultClient.Do(hreq)
+	if err != nil {
+		return errors.Wrap(err, "cannot post resolution request")
+	}
+	defer hrsp.Body.Close()
+	return errors.Wrap(json.NewDecoder(hrsp.Body).Decode(rsp), "cannot decode resolution response")
+}
+
+// NewResolverOfManaged returns a Resolver of the references of the supplied
+// managed resource.
+func NewResolverOfManaged(from resource.Managed) *Resolver {
+	return &Resolver{from: from}
+}
+
+// A SingleResolver resolves only single references.
+type SingleResolver interface {
+	Resolve(ctx context.Context, req reference.ResolutionRequest) (reference.ResolutionResponse, error)
+}
+
+// NewSingleResolver returns a SingleResolver of the references of the supplied
+// managed resource.
+func NewSingleResolver(c client.Reader, from resource.Managed) SingleResolver {
+	return NewResolver(c, from)
+}
@@ -0,0 +1,14 @@
+// Package meta is a minimal stand-in for the crossplane-runtime v0.19 meta
+// package.
+package meta
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// AnnotationKeyExternalName is the key of the annotation that holds the
+// external name of a resource.
+const AnnotationKeyExternalName = "crossplane.io/external-name"
+
+// GetExternalName returns the external name annotation of the object.
+func GetExternalName(o metav1.Object) string {
+	return o.GetAnnotations()[AnnotationKeyExternalName]
+}
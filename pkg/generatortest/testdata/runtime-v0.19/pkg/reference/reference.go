@@ -0,0 +1,118 @@
+// Package reference is a minimal stand-in for the crossplane-runtime v0.19
+// reference resolver, whose resolution requests have no namespace.
+package reference
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// An ExtractValueFn specifies how to extract a value from the resolved managed
+// resource.
+type ExtractValueFn func(resource.Managed) string
+
+// ExternalName extracts the resolved managed resource's external name.
+func ExternalName() ExtractValueFn {
+	return func(resource.Managed) string { return "" }
+}
+
+// FromPtrValue adapts a string pointer field for use as a CurrentValue.
+func FromPtrValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+// ToPtrValue adapts a ResolvedValue for use as a string pointer field.
+func ToPtrValue(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+// FromPtrValues adapts a slice of string pointer fields for use as CurrentValues.
+func FromPtrValues(v []*string) []string {
+	res := make([]string, len(v))
+	for i := range v {
+		res[i] = FromPtrValue(v[i])
+	}
+	return res
+}
+
+// ToPtrValues adapts ResolvedValues for use as a slice of string pointer fields.
+func ToPtrValues(v []string) []*string {
+	res := make([]*string, len(v))
+	for i := range v {
+		res[i] = ToPtrValue(v[i])
+	}
+	return res
+}
+
+// To indicates the kind of managed resource a reference is to.
+type To struct {
+	Managed resource.Managed
+	List    resource.ManagedList
+}
+
+// A ResolutionRequest requests that a reference to a particular kind of
+// managed resource be resolved.
+type ResolutionRequest struct {
+	CurrentValue string
+	Reference    *xpv1.Reference
+	Selector     *xpv1.Selector
+	To           To
+	Extract      ExtractValueFn
+}
+
+// A ResolutionResponse returns the result of a reference resolution.
+type ResolutionResponse struct {
+	ResolvedValue     string
+	ResolvedReference *xpv1.Reference
+}
+
+// A MultiResolutionRequest requests that several references to a particular
+// kind of managed resource be resolved.
+type MultiResolutionRequest struct {
+	CurrentValues []string
+	References    []xpv1.Reference
+	Selector      *xpv1.Selector
+	To            To
+	Extract       ExtractValueFn
+}
+
+// A MultiResolutionResponse returns the result of several reference
+// resolutions.
+type MultiResolutionResponse struct {
+	ResolvedValues     []string
+	ResolvedReferences []xpv1.Reference
+}
+
+// An APIResolver selects and resolves references to managed resources in the
+// Kubernetes API server.
+type APIResolver struct {
+	client client.Reader
+	from   resource.Managed
+}
+
+// NewAPIResolver returns a Resolver that selects and resolves references from
+// the supplied managed resource to other managed resources in the Kubernetes
+// API server.
+func NewAPIResolver(c client.Reader, from resource.Managed) *APIResolver {
+	return &APIResolver{client: c, from: from}
+}
+
+// Resolve the supplied ResolutionRequest.
+func (r *APIResolver) Resolve(ctx context.Context, req ResolutionRequest) (ResolutionResponse, error) {
+	return ResolutionResponse{ResolvedValue: req.CurrentValue, ResolvedReference: req.Reference}, nil
+}
+
+// ResolveMultiple resolves the supplied MultiResolutionRequest.
+func (r *APIResolver) ResolveMultiple(ctx context.Context, req MultiResolutionRequest) (MultiResolutionResponse, error) {
+	return MultiResolutionResponse{ResolvedValues: req.CurrentValues, ResolvedReferences: req.References}, nil
+}
@@ -0,0 +1,115 @@
+// Package v1 is a minimal stand-in for the crossplane-runtime common API types.
+// It defines only what is needed to compile and check generated methods.
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// A ConditionType represents a condition a resource could be in.
+type ConditionType string
+
+// A ConditionReason represents the reason a resource is in a condition.
+type ConditionReason string
+
+// A Condition that may apply to a resource.
+type Condition struct {
+	Type               ConditionType
+	Status             corev1.ConditionStatus
+	LastTransitionTime metav1.Time
+	Reason             ConditionReason
+	Message            string
+}
+
+// A ConditionedStatus reflects the observed status of a resource.
+type ConditionedStatus struct {
+	Conditions []Condition
+}
+
+// SetConditions sets the supplied conditions.
+func (s *ConditionedStatus) SetConditions(c ...Condition) {
+	s.Conditions = append(s.Conditions, c...)
+}
+
+// GetCondition returns the condition for the given ConditionType if exists,
+// otherwise returns an empty condition.
+func (s *ConditionedStatus) GetCondition(ct ConditionType) Condition {
+	for _, c := range s.Conditions {
+		if c.Type == ct {
+			return c
+		}
+	}
+	return Condition{Type: ct}
+}
+
+// A DeletionPolicy determines what should happen to the underlying external
+// resource when a managed resource is deleted.
+type DeletionPolicy string
+
+// A Reference to a named object.
+type Reference struct {
+	Name string
+}
+
+// A TypedReference refers to an object by Name, Kind, and APIVersion.
+type TypedReference struct {
+	APIVersion string
+	Kind       string
+	Name       string
+}
+
+// A Selector selects an object.
+type Selector struct {
+	MatchLabels        map[string]string
+	MatchControllerRef *bool
+}
+
+// A SecretReference is a reference to a secret in an arbitrary namespace.
+type SecretReference struct {
+	Name      string
+	Namespace string
+}
+
+// A LocalSecretReference is a reference to a secret in the same namespace as
+// the referencer.
+type LocalSecretReference struct {
+	Name string
+}
+
+// PublishConnectionDetailsTo represents configuration of a connection secret.
+type PublishConnectionDetailsTo struct {
+	Name string
+}
+
+// ResourceSpec defines the desired state of a managed resource.
+type ResourceSpec struct {
+	WriteConnectionSecretToReference *SecretReference
+	PublishConnectionDetailsTo       *PublishConnectionDetailsTo
+	ProviderReference                *Reference
+	ProviderConfigReference          *Reference
+	DeletionPolicy                   DeletionPolicy
+}
+
+// ResourceStatus represents the observed state of a managed resource.
+type ResourceStatus struct {
+	ConditionedStatus
+}
+
+// A ProviderConfigSpec defines the desired state of a provider config.
+type ProviderConfigSpec struct {
+	Source string
+}
+
+// A ProviderConfigStatus represents the status of a provider config.
+type ProviderConfigStatus struct {
+	ConditionedStatus
+	Users int64
+}
+
+// A ProviderConfigUsage is a record that a particular managed resource is using
+// a particular provider configuration.
+type ProviderConfigUsage struct {
+	ProviderConfigReference Reference
+	ResourceReference       TypedReference
+}
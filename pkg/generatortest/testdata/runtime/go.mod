@@ -0,0 +1,9 @@
+module github.com/crossplane/crossplane-runtime
+
+go 1.18
+
+require (
+	k8s.io/api v0.0.0
+	k8s.io/apimachinery v0.0.0
+	sigs.k8s.io/controller-runtime v0.0.0
+)
@@ -0,0 +1,217 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package golden compares generated code to golden files. It is intended to be
+// used by the tests of generators, including generators that are built on top
+// of angryjet outside this repository.
+package golden
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// EnvUpdate is the environment variable that, when set to a non-empty value,
+// makes Assert write golden files rather than compare to them.
+const EnvUpdate = "UPDATE_GOLDEN"
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+// header matches a generated code header that includes the version of the
+// generator, for example "// Code generated by angryjet v1.2.3. DO NOT EDIT."
+var header = regexp.MustCompile(`(?m)^(// Code generated by \S+?) \(?v[0-9]\S*?\)?(\. DO NOT EDIT\.)$`)
+
+// Assert fails the supplied test if the supplied generated code differs from
+// the golden file at the supplied path, printing a unified diff of the two. If
+// the UPDATE_GOLDEN environment variable is set the golden file is written
+// instead, creating its directory if necessary. Both are normalized before
+// they are compared or written.
+func Assert(t testing.TB, got []byte, goldenPath string) {
+	t.Helper()
+	got = Normalize(got)
+
+	if os.Getenv(EnvUpdate) != "" {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o750); err != nil {
+			t.Fatalf("cannot create directory of golden file %s: %v", goldenPath, err)
+		}
+		if err := os.WriteFile(goldenPath, got, 0o600); err != nil {
+			t.Fatalf("cannot write golden file %s: %v", goldenPath, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(filepath.Clean(goldenPath))
+	if err != nil {
+		t.Fatalf("cannot read golden file %s: %v (set %s=1 to create it)", goldenPath, err, EnvUpdate)
+	}
+	want = Normalize(want)
+	if bytes.Equal(want, got) {
+		return
+	}
+	t.Errorf("%s does not match the generated code (set %s=1 to update it):\n%s", goldenPath, EnvUpdate, Diff(goldenPath, "generated", want, got))
+}
+
+// Normalize returns the supplied generated code with its line endings converted
+// to \n, and with the version of the generator, if any, removed from its
+// generated code header. Golden files then don't need to be updated for every
+// release of a generator that stamps its version into the code it generates.
+func Normalize(code []byte) []byte {
+	code = bytes.ReplaceAll(code, []byte("\r\n"), []byte("\n"))
+	return header.ReplaceAll(code, []byte("$1$2"))
+}
+
+// Diff returns a unified diff of the supplied lines of text, or an empty string
+// if they are equal. The supplied names label the old and new text.
+func Diff(oldName, newName string, o, n []byte) string {
+	if bytes.Equal(o, n) {
+		return ""
+	}
+	a, b := lines(o), lines(n)
+	ops := edits(a, b)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(ops); {
+		// Skip unchanged lines to the next change.
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// A hunk starts with the unchanged lines before the change, and ends
+		// at the first run of unchanged lines that is too long to be shown
+		// in full between two changes.
+		first := start - contextLines
+		if first < 0 {
+			first = 0
+		}
+		last, unchanged := start, 0
+		for end := start; end < len(ops) && unchanged <= 2*contextLines; end++ {
+			if ops[end].kind == ' ' {
+				unchanged++
+				continue
+			}
+			unchanged = 0
+			last = end
+		}
+		end := last + contextLines + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		hunk := ops[first:end]
+		oldStart, newStart := hunk[0].a+1, hunk[0].b+1
+		oldLines, newLines := 0, 0
+		for _, op := range hunk {
+			if op.kind != '+' {
+				oldLines++
+			}
+			if op.kind != '-' {
+				newLines++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", span(oldStart, oldLines), span(newStart, newLines))
+		for _, op := range hunk {
+			sb.WriteByte(op.kind)
+			if op.kind == '+' {
+				sb.WriteString(b[op.b])
+			} else {
+				sb.WriteString(a[op.a])
+			}
+			sb.WriteByte('\n')
+		}
+		start = end
+	}
+	return sb.String()
+}
+
+// span returns the range of a hunk header, omitting its length if it is one
+// line long. An empty range starts before its first line, as in GNU diff.
+func span(start, length int) string {
+	switch length {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprintf("%d", start)
+	default:
+		return fmt.Sprintf("%d,%d", start, length)
+	}
+}
+
+// lines returns the lines of the supplied text, without line endings.
+func lines(text []byte) []string {
+	if len(text) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(text), "\n"), "\n")
+}
+
+// An edit is a line that is kept (' '), removed ('-'), or added ('+'). a and b
+// are the indices of the line in the old and new text. Removed lines keep the
+// index in the new text at which they were removed, and vice versa.
+type edit struct {
+	kind byte
+	a, b int
+}
+
+// edits returns the shortest edit script that turns a into b, computed from
+// their longest common subsequence of lines.
+func edits(a, b []string) []edit {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]edit, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, edit{kind: ' ', a: i, b: j})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, edit{kind: '-', a: i, b: j})
+			i++
+		default:
+			ops = append(ops, edit{kind: '+', a: i, b: j})
+			j++
+		}
+	}
+	return ops
+}
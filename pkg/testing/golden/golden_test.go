@@ -0,0 +1,198 @@
+/*
+Copyright 2026 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golden
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		code   string
+		want   string
+	}{
+		"Version": {
+			reason: "The version of the generator should be removed from the generated code header.",
+			code:   "// Code generated by angryjet v1.2.3-rc.1. DO NOT EDIT.\n\npackage v1\n",
+			want:   "// Code generated by angryjet. DO NOT EDIT.\n\npackage v1\n",
+		},
+		"ParenthesizedVersion": {
+			reason: "A parenthesized version should also be removed.",
+			code:   "// Code generated by angryjet (v1.2.3). DO NOT EDIT.\n",
+			want:   "// Code generated by angryjet. DO NOT EDIT.\n",
+		},
+		"NoVersion": {
+			reason: "A generated code header without a version should be unchanged.",
+			code:   "// Code generated by angryjet. DO NOT EDIT.\n",
+			want:   "// Code generated by angryjet. DO NOT EDIT.\n",
+		},
+		"LineEndings": {
+			reason: "Windows line endings should be converted.",
+			code:   "package v1\r\n\r\ntype T struct{}\r\n",
+			want:   "package v1\n\ntype T struct{}\n",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := string(Normalize([]byte(tc.code)))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nNormalize(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		old    string
+		new    string
+		want   string
+	}{
+		"Equal": {
+			reason: "Equal text should have no diff.",
+			old:    "a\nb\n",
+			new:    "a\nb\n",
+			want:   "",
+		},
+		"Changed": {
+			reason: "A changed line should be shown with the unchanged lines around it.",
+			old:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			new:    "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: `--- old
++++ new
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+		"SeparateHunks": {
+			reason: "Changes that are far apart should be shown in separate hunks.",
+			old:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			new:    "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\neleven\n",
+			want: `--- old
++++ new
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -9,3 +9,4 @@
+ 9
+ 10
+ 11
++eleven
+`,
+		},
+		"Added": {
+			reason: "Lines added to empty text should start at line zero of the old text.",
+			old:    "",
+			new:    "a\n",
+			want: `--- old
++++ new
+@@ -0,0 +1 @@
++a
+`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Diff("old", "new", []byte(tc.old), []byte(tc.new))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDiff(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// recorder records the failures of an assertion.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssert(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "testdata", "model.golden")
+
+	cases := map[string]struct {
+		reason   string
+		update   string
+		got      string
+		want     string
+		failures int
+	}{
+		"Update": {
+			reason: "The golden file should be written, normalized, if UPDATE_GOLDEN is set.",
+			update: "1",
+			got:    "// Code generated by angryjet v1.0.0. DO NOT EDIT.\n\npackage v1\n",
+			want:   "// Code generated by angryjet. DO NOT EDIT.\n\npackage v1\n",
+		},
+		"Match": {
+			reason: "Generated code should match a golden file that differs only in the version of its generator.",
+			got:    "// Code generated by angryjet v2.0.0. DO NOT EDIT.\n\npackage v1\n",
+			want:   "// Code generated by angryjet. DO NOT EDIT.\n\npackage v1\n",
+		},
+		"Mismatch": {
+			reason:   "Generated code that differs from the golden file should fail the test, and leave the golden file unchanged.",
+			got:      "// Code generated by angryjet. DO NOT EDIT.\n\npackage v2\n",
+			want:     "// Code generated by angryjet. DO NOT EDIT.\n\npackage v1\n",
+			failures: 1,
+		},
+	}
+	for _, name := range []string{"Update", "Match", "Mismatch"} {
+		tc := cases[name]
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(EnvUpdate, tc.update)
+			r := &recorder{TB: t}
+			Assert(r, []byte(tc.got), path)
+			if len(r.failures) != tc.failures {
+				t.Errorf("\n%s\nAssert(...): want %d failures, got %v", tc.reason, tc.failures, r.failures)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("\n%s\nAssert(...): -want golden file, +got golden file:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}